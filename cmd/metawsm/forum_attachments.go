@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"metawsm/internal/model"
+)
+
+// parseForumAttachmentSpecs turns --attach values into attachments. A spec is
+// KIND:PATH, with an optional :START-END line range for snippets; PATH "-"
+// reads stdin. Snippets record the git repo and repo-relative path of the file
+// when it sits inside a checkout. command_output attachments take their
+// command line from command.
+func parseForumAttachmentSpecs(specs []string, command string, stdin io.Reader) ([]model.ForumAttachment, error) {
+	out := make([]model.ForumAttachment, 0, len(specs))
+	for _, spec := range specs {
+		kindValue, rest, ok := strings.Cut(strings.TrimSpace(spec), ":")
+		if !ok || strings.TrimSpace(rest) == "" {
+			return nil, fmt.Errorf("invalid --attach %q (expected KIND:PATH)", spec)
+		}
+		kind := model.ForumAttachmentKind(strings.TrimSpace(strings.ToLower(kindValue)))
+		if kind == "output" {
+			kind = model.ForumAttachmentCommandOutput
+		}
+		attachment := model.ForumAttachment{Kind: kind}
+		path := strings.TrimSpace(rest)
+		switch kind {
+		case model.ForumAttachmentSnippet:
+			if index := strings.LastIndex(path, ":"); index > 0 {
+				start, end, err := parseForumLineRange(path[index+1:])
+				if err != nil {
+					return nil, fmt.Errorf("invalid --attach %q: %w", spec, err)
+				}
+				path = path[:index]
+				attachment.StartLine, attachment.EndLine = start, end
+			}
+		case model.ForumAttachmentDiff, model.ForumAttachmentLog:
+		case model.ForumAttachmentCommandOutput:
+			attachment.Command = strings.TrimSpace(command)
+			if attachment.Command == "" {
+				return nil, fmt.Errorf("--attach %q requires --attach-command", spec)
+			}
+		default:
+			return nil, fmt.Errorf("invalid --attach kind %q (expected snippet|diff|log|output)", kindValue)
+		}
+
+		var content []byte
+		var err error
+		if path == "-" {
+			content, err = io.ReadAll(stdin)
+		} else {
+			content, err = os.ReadFile(path)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read attachment %s: %w", path, err)
+		}
+		attachment.Content = string(content)
+		if attachment.StartLine > 0 {
+			attachment.Content, err = sliceForumLines(attachment.Content, attachment.StartLine, attachment.EndLine)
+			if err != nil {
+				return nil, fmt.Errorf("attachment %s: %w", path, err)
+			}
+		}
+		if path != "-" {
+			attachment.Title = filepath.Base(path)
+			if kind == model.ForumAttachmentSnippet {
+				attachment.Repo, attachment.Path = forumAttachmentRepoPath(path)
+			}
+		}
+		out = append(out, attachment)
+	}
+	return out, nil
+}
+
+func parseForumLineRange(value string) (int, int, error) {
+	startValue, endValue, hasEnd := strings.Cut(strings.TrimSpace(value), "-")
+	start, err := strconv.Atoi(startValue)
+	if err != nil || start <= 0 {
+		return 0, 0, fmt.Errorf("line range %q must be START or START-END", value)
+	}
+	end := start
+	if hasEnd {
+		end, err = strconv.Atoi(endValue)
+		if err != nil || end < start {
+			return 0, 0, fmt.Errorf("line range %q must be START or START-END", value)
+		}
+	}
+	return start, end, nil
+}
+
+func sliceForumLines(content string, start int, end int) (string, error) {
+	lines := strings.SplitAfter(content, "\n")
+	if start > len(lines) {
+		return "", fmt.Errorf("line %d is past the end of the file (%d lines)", start, len(lines))
+	}
+	if end > len(lines) {
+		end = len(lines)
+	}
+	return strings.Join(lines[start-1:end], ""), nil
+}
+
+// forumAttachmentRepoPath returns the git checkout name and repo-relative
+// path for file, or no repo and the path as given outside a checkout.
+func forumAttachmentRepoPath(file string) (string, string) {
+	absolute, err := filepath.Abs(file)
+	if err != nil {
+		return "", file
+	}
+	if resolved, err := filepath.EvalSymlinks(absolute); err == nil {
+		absolute = resolved
+	}
+	output, err := exec.Command("git", "-C", filepath.Dir(absolute), "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return "", file
+	}
+	root := strings.TrimSpace(string(output))
+	relative, err := filepath.Rel(root, absolute)
+	if err != nil || strings.HasPrefix(relative, "..") {
+		return "", file
+	}
+	return filepath.Base(root), filepath.ToSlash(relative)
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
+	"metawsm/internal/model"
 	"metawsm/internal/orchestrator"
 	"metawsm/internal/policy"
 
@@ -133,6 +135,190 @@ func (c *authCheckGlazedCommand) Run(ctx context.Context, parsedLayers *layers.P
 
 var _ cmds.BareCommand = &authCheckGlazedCommand{}
 
+type authTokenCreateGlazedCommand struct {
+	*cmds.CommandDescription
+}
+
+type authTokenCreateSettings struct {
+	DBPath    string `glazed.parameter:"db"`
+	Role      string `glazed.parameter:"role"`
+	ActorName string `glazed.parameter:"actor"`
+	TTL       string `glazed.parameter:"ttl"`
+}
+
+func newAuthTokenCreateGlazedCommand() (*authTokenCreateGlazedCommand, error) {
+	desc := cmds.NewCommandDescription(
+		"create",
+		cmds.WithShort("Create an API token for metawsm serve"),
+		cmds.WithLong("Create a bearer token bound to an actor and role. The secret is printed once; only its hash is stored."),
+		cmds.WithFlags(
+			parameters.NewParameterDefinition(
+				"db",
+				parameters.ParameterTypeString,
+				parameters.WithHelp("Path to SQLite DB"),
+				parameters.WithDefault(".metawsm/metawsm.db"),
+			),
+			parameters.NewParameterDefinition(
+				"role",
+				parameters.ParameterTypeString,
+				parameters.WithHelp("Token role: viewer|human|operator|agent"),
+				parameters.WithDefault(""),
+			),
+			parameters.NewParameterDefinition(
+				"actor",
+				parameters.ParameterTypeString,
+				parameters.WithHelp("Actor name recorded on forum writes made with this token"),
+				parameters.WithDefault(""),
+			),
+			parameters.NewParameterDefinition(
+				"ttl",
+				parameters.ParameterTypeString,
+				parameters.WithHelp("Token lifetime, e.g. 720h (0 never expires)"),
+				parameters.WithDefault("0"),
+			),
+		),
+	)
+	return &authTokenCreateGlazedCommand{CommandDescription: desc}, nil
+}
+
+func (c *authTokenCreateGlazedCommand) Run(ctx context.Context, parsedLayers *layers.ParsedLayers) error {
+	settings := &authTokenCreateSettings{}
+	if err := parsedLayers.InitializeStruct(layers.DefaultSlug, settings); err != nil {
+		return err
+	}
+	ttl, err := parseDurationSetting("ttl", settings.TTL)
+	if err != nil {
+		return err
+	}
+	secret, token, err := orchestrator.CreateAPIToken(settings.DBPath, orchestrator.APITokenOptions{
+		Role:      model.APIRole(settings.Role),
+		ActorName: settings.ActorName,
+		TTL:       ttl,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Token ID: %s\n", token.TokenID)
+	fmt.Printf("Role: %s actor=%s\n", token.Role, token.ActorName)
+	if token.ExpiresAt != nil {
+		fmt.Printf("Expires: %s\n", token.ExpiresAt.Format(time.RFC3339))
+	}
+	fmt.Printf("Token: %s\n", secret)
+	fmt.Println("Store this token now; it cannot be shown again. Remote commands read it from METAWSM_API_TOKEN.")
+	return nil
+}
+
+var _ cmds.BareCommand = &authTokenCreateGlazedCommand{}
+
+type authTokenListGlazedCommand struct {
+	*cmds.CommandDescription
+}
+
+type authTokenListSettings struct {
+	DBPath string `glazed.parameter:"db"`
+}
+
+func newAuthTokenListGlazedCommand() (*authTokenListGlazedCommand, error) {
+	desc := cmds.NewCommandDescription(
+		"list",
+		cmds.WithShort("List API tokens"),
+		cmds.WithLong("List API tokens with their role, actor, and expiry or revocation time."),
+		cmds.WithFlags(
+			parameters.NewParameterDefinition(
+				"db",
+				parameters.ParameterTypeString,
+				parameters.WithHelp("Path to SQLite DB"),
+				parameters.WithDefault(".metawsm/metawsm.db"),
+			),
+		),
+	)
+	return &authTokenListGlazedCommand{CommandDescription: desc}, nil
+}
+
+func (c *authTokenListGlazedCommand) Run(ctx context.Context, parsedLayers *layers.ParsedLayers) error {
+	settings := &authTokenListSettings{}
+	if err := parsedLayers.InitializeStruct(layers.DefaultSlug, settings); err != nil {
+		return err
+	}
+	tokens, err := orchestrator.ListAPITokens(settings.DBPath)
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 0 {
+		fmt.Println("No API tokens.")
+		return nil
+	}
+	now := time.Now()
+	for _, token := range tokens {
+		state := "active"
+		switch {
+		case token.RevokedAt != nil:
+			state = "revoked " + token.RevokedAt.Format(time.RFC3339)
+		case !token.Active(now):
+			state = "expired"
+		}
+		expires := "never"
+		if token.ExpiresAt != nil {
+			expires = token.ExpiresAt.Format(time.RFC3339)
+		}
+		scope := "-"
+		if token.RunID != "" {
+			scope = token.RunID + "/" + token.AgentName + "@" + token.WorkspaceName
+		}
+		fmt.Printf("  - %s role=%s actor=%s scope=%s created=%s expires=%s state=%s\n",
+			token.TokenID, token.Role, token.ActorName, scope, token.CreatedAt.Format(time.RFC3339), expires, state)
+	}
+	return nil
+}
+
+var _ cmds.BareCommand = &authTokenListGlazedCommand{}
+
+type authTokenRevokeGlazedCommand struct {
+	*cmds.CommandDescription
+}
+
+type authTokenRevokeSettings struct {
+	DBPath  string `glazed.parameter:"db"`
+	TokenID string `glazed.parameter:"id"`
+}
+
+func newAuthTokenRevokeGlazedCommand() (*authTokenRevokeGlazedCommand, error) {
+	desc := cmds.NewCommandDescription(
+		"revoke",
+		cmds.WithShort("Revoke an API token"),
+		cmds.WithLong("Revoke an API token so metawsm serve rejects it immediately."),
+		cmds.WithFlags(
+			parameters.NewParameterDefinition(
+				"db",
+				parameters.ParameterTypeString,
+				parameters.WithHelp("Path to SQLite DB"),
+				parameters.WithDefault(".metawsm/metawsm.db"),
+			),
+			parameters.NewParameterDefinition(
+				"id",
+				parameters.ParameterTypeString,
+				parameters.WithHelp("Token ID to revoke"),
+				parameters.WithDefault(""),
+			),
+		),
+	)
+	return &authTokenRevokeGlazedCommand{CommandDescription: desc}, nil
+}
+
+func (c *authTokenRevokeGlazedCommand) Run(ctx context.Context, parsedLayers *layers.ParsedLayers) error {
+	settings := &authTokenRevokeSettings{}
+	if err := parsedLayers.InitializeStruct(layers.DefaultSlug, settings); err != nil {
+		return err
+	}
+	if err := orchestrator.RevokeAPIToken(settings.DBPath, settings.TokenID); err != nil {
+		return err
+	}
+	fmt.Printf("Revoked token %s.\n", strings.TrimSpace(settings.TokenID))
+	return nil
+}
+
+var _ cmds.BareCommand = &authTokenRevokeGlazedCommand{}
+
 type reviewSyncGlazedCommand struct {
 	*cmds.CommandDescription
 }
@@ -248,6 +434,141 @@ func (c *reviewSyncGlazedCommand) Run(ctx context.Context, parsedLayers *layers.
 
 var _ cmds.BareCommand = &reviewSyncGlazedCommand{}
 
+type dbMigrateGlazedCommand struct {
+	*cmds.CommandDescription
+}
+
+type dbMigrateSettings struct {
+	DBPath    string `glazed.parameter:"db"`
+	ToVersion int    `glazed.parameter:"to"`
+	DryRun    bool   `glazed.parameter:"dry-run"`
+}
+
+func newDBMigrateGlazedCommand() (*dbMigrateGlazedCommand, error) {
+	desc := cmds.NewCommandDescription(
+		"migrate",
+		cmds.WithShort("Apply pending schema migrations"),
+		cmds.WithLong("Apply numbered schema migrations to the metawsm database, optionally stopping at a target version."),
+		cmds.WithFlags(
+			parameters.NewParameterDefinition(
+				"db",
+				parameters.ParameterTypeString,
+				parameters.WithHelp("Path to SQLite DB"),
+				parameters.WithDefault(".metawsm/metawsm.db"),
+			),
+			parameters.NewParameterDefinition(
+				"to",
+				parameters.ParameterTypeInteger,
+				parameters.WithHelp("Target schema version (defaults to the latest version this binary knows)"),
+				parameters.WithDefault(0),
+			),
+			parameters.NewParameterDefinition(
+				"dry-run",
+				parameters.ParameterTypeBool,
+				parameters.WithHelp("List migrations that would be applied without changing the database"),
+				parameters.WithDefault(false),
+			),
+		),
+	)
+	return &dbMigrateGlazedCommand{CommandDescription: desc}, nil
+}
+
+func (c *dbMigrateGlazedCommand) Run(ctx context.Context, parsedLayers *layers.ParsedLayers) error {
+	settings := &dbMigrateSettings{}
+	if err := parsedLayers.InitializeStruct(layers.DefaultSlug, settings); err != nil {
+		return err
+	}
+	if settings.ToVersion < 0 {
+		return fmt.Errorf("--to must be >= 0")
+	}
+
+	result, err := orchestrator.MigrateDatabase(settings.DBPath, orchestrator.MigrateOptions{
+		ToVersion: settings.ToVersion,
+		DryRun:    settings.DryRun,
+	})
+	if err != nil {
+		return err
+	}
+	if len(result.Migrations) == 0 {
+		fmt.Printf("Database %s is at schema version %d; nothing to migrate.\n", settings.DBPath, result.FromVersion)
+		return nil
+	}
+	if settings.DryRun {
+		fmt.Printf("Dry run: database %s would migrate from schema version %d to %d:\n", settings.DBPath, result.FromVersion, result.ToVersion)
+	} else {
+		fmt.Printf("Migrated database %s from schema version %d to %d:\n", settings.DBPath, result.FromVersion, result.ToVersion)
+	}
+	for _, migration := range result.Migrations {
+		fmt.Printf("  - %d %s\n", migration.Version, migration.Name)
+	}
+	return nil
+}
+
+var _ cmds.BareCommand = &dbMigrateGlazedCommand{}
+
+type dbStatusGlazedCommand struct {
+	*cmds.CommandDescription
+}
+
+type dbStatusSettings struct {
+	DBPath string `glazed.parameter:"db"`
+}
+
+func newDBStatusGlazedCommand() (*dbStatusGlazedCommand, error) {
+	desc := cmds.NewCommandDescription(
+		"status",
+		cmds.WithShort("Show schema migration status"),
+		cmds.WithLong("Show the database schema version and applied/pending migrations."),
+		cmds.WithFlags(
+			parameters.NewParameterDefinition(
+				"db",
+				parameters.ParameterTypeString,
+				parameters.WithHelp("Path to SQLite DB"),
+				parameters.WithDefault(".metawsm/metawsm.db"),
+			),
+		),
+	)
+	return &dbStatusGlazedCommand{CommandDescription: desc}, nil
+}
+
+func (c *dbStatusGlazedCommand) Run(ctx context.Context, parsedLayers *layers.ParsedLayers) error {
+	settings := &dbStatusSettings{}
+	if err := parsedLayers.InitializeStruct(layers.DefaultSlug, settings); err != nil {
+		return err
+	}
+
+	status, err := orchestrator.DatabaseSchemaStatus(settings.DBPath)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Database: %s\n", status.DBPath)
+	fmt.Printf("Schema version: %d (binary supports %d)\n", status.CurrentVersion, status.LatestVersion)
+	if status.CurrentVersion > status.LatestVersion {
+		fmt.Println("Warning: database is newer than this binary; upgrade metawsm before running serve.")
+	}
+	fmt.Println("Applied:")
+	if len(status.Applied) == 0 {
+		fmt.Println("  - none")
+	}
+	for _, migration := range status.Applied {
+		appliedAt := "unknown"
+		if migration.AppliedAt != nil {
+			appliedAt = migration.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Printf("  - %d %s applied_at=%s\n", migration.Version, emptyValue(migration.Name, "(unknown to this binary)"), appliedAt)
+	}
+	fmt.Println("Pending:")
+	if len(status.Pending) == 0 {
+		fmt.Println("  - none")
+	}
+	for _, migration := range status.Pending {
+		fmt.Printf("  - %d %s\n", migration.Version, migration.Name)
+	}
+	return nil
+}
+
+var _ cmds.BareCommand = &dbStatusGlazedCommand{}
+
 func addGroupedCommandTrees(rootCmd *cobra.Command) error {
 	authRoot := &cobra.Command{
 		Use:   "auth",
@@ -265,6 +586,31 @@ func addGroupedCommandTrees(rootCmd *cobra.Command) error {
 		return err
 	}
 	authRoot.AddCommand(authCheckCobraCmd)
+
+	tokenRoot := &cobra.Command{
+		Use:   "token",
+		Short: "API token subcommands",
+	}
+	tokenCreateCmd, err := newAuthTokenCreateGlazedCommand()
+	if err != nil {
+		return err
+	}
+	tokenListCmd, err := newAuthTokenListGlazedCommand()
+	if err != nil {
+		return err
+	}
+	tokenRevokeCmd, err := newAuthTokenRevokeGlazedCommand()
+	if err != nil {
+		return err
+	}
+	for _, command := range []cmds.Command{tokenCreateCmd, tokenListCmd, tokenRevokeCmd} {
+		cobraCmd, err := buildGlazedCobraCommand(command)
+		if err != nil {
+			return err
+		}
+		tokenRoot.AddCommand(cobraCmd)
+	}
+	authRoot.AddCommand(tokenRoot)
 	rootCmd.AddCommand(authRoot)
 
 	reviewRoot := &cobra.Command{
@@ -285,6 +631,32 @@ func addGroupedCommandTrees(rootCmd *cobra.Command) error {
 	reviewRoot.AddCommand(reviewSyncCobraCmd)
 	rootCmd.AddCommand(reviewRoot)
 
+	dbRoot := &cobra.Command{
+		Use:   "db",
+		Short: "Database schema subcommands",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return fmt.Errorf("usage: metawsm db <migrate|status> [--db PATH] [--to N] [--dry-run]")
+		},
+	}
+	dbMigrateCmd, err := newDBMigrateGlazedCommand()
+	if err != nil {
+		return err
+	}
+	dbMigrateCobraCmd, err := buildGlazedCobraCommand(dbMigrateCmd)
+	if err != nil {
+		return err
+	}
+	dbStatusCmd, err := newDBStatusGlazedCommand()
+	if err != nil {
+		return err
+	}
+	dbStatusCobraCmd, err := buildGlazedCobraCommand(dbStatusCmd)
+	if err != nil {
+		return err
+	}
+	dbRoot.AddCommand(dbMigrateCobraCmd, dbStatusCobraCmd)
+	rootCmd.AddCommand(dbRoot)
+
 	forumRoot := &cobra.Command{
 		Use:   "forum",
 		Short: "Forum subcommands",
@@ -308,6 +680,10 @@ func addGroupedCommandTrees(rootCmd *cobra.Command) error {
 		{name: "watch", short: "Watch thread activity"},
 		{name: "signal", short: "Signal run status to forum"},
 		{name: "debug", short: "Forum debug helpers"},
+		{name: "outbox", short: "Inspect, retry, and purge outbox messages"},
+		{name: "rebuild-projections", short: "Rebuild forum projections from the event log"},
+		{name: "escalate", short: "Run a forum SLA escalation pass now"},
+		{name: "guidance", short: "Manage the guidance answer library"},
 	}
 	for _, sub := range forumSubcommands {
 		subName := sub.name
@@ -323,5 +699,59 @@ func addGroupedCommandTrees(rootCmd *cobra.Command) error {
 	}
 	rootCmd.AddCommand(forumRoot)
 
+	templateRoot := &cobra.Command{
+		Use:   "template",
+		Short: "Run template subcommands",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return templateCommand(args)
+		},
+	}
+	templateSubcommands := []struct {
+		name  string
+		short string
+	}{
+		{name: "list", short: "List run templates"},
+		{name: "show", short: "Print one run template"},
+		{name: "validate", short: "Validate run templates"},
+	}
+	for _, sub := range templateSubcommands {
+		subName := sub.name
+		templateRoot.AddCommand(&cobra.Command{
+			Use:                subName,
+			Short:              sub.short,
+			DisableFlagParsing: true,
+			Args:               cobra.ArbitraryArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return templateCommand(append([]string{subName}, args...))
+			},
+		})
+	}
+	rootCmd.AddCommand(templateRoot)
+
+	operatorRoot, _, err := rootCmd.Find([]string{"operator"})
+	if err != nil || operatorRoot == rootCmd {
+		return fmt.Errorf("operator command not registered")
+	}
+	operatorSubcommands := []struct {
+		name  string
+		short string
+	}{
+		{name: "history", short: "List audited operator decisions"},
+		{name: "replay", short: "Re-evaluate recorded operator decisions against current policy"},
+		{name: "rules", short: "Test policy operator rules against a live or recorded snapshot"},
+	}
+	for _, sub := range operatorSubcommands {
+		subName := sub.name
+		operatorRoot.AddCommand(&cobra.Command{
+			Use:                subName,
+			Short:              sub.short,
+			DisableFlagParsing: true,
+			Args:               cobra.ArbitraryArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return operatorSubcommand(append([]string{subName}, args...))
+			},
+		})
+	}
+
 	return nil
 }
@@ -217,12 +217,19 @@ type serveGlazedCommand struct {
 }
 
 type serveSettings struct {
-	Addr            string `glazed.parameter:"addr"`
-	DBPath          string `glazed.parameter:"db"`
-	WorkerInterval  string `glazed.parameter:"worker-interval"`
-	WorkerBatchSize int    `glazed.parameter:"worker-batch-size"`
-	WorkerLogPeriod string `glazed.parameter:"worker-log-period"`
-	ShutdownTimeout string `glazed.parameter:"shutdown-timeout"`
+	Addr                string `glazed.parameter:"addr"`
+	DBPath              string `glazed.parameter:"db"`
+	WorkerInterval      string `glazed.parameter:"worker-interval"`
+	WorkerBatchSize     int    `glazed.parameter:"worker-batch-size"`
+	WorkerLogPeriod     string `glazed.parameter:"worker-log-period"`
+	EscalationInterval  string `glazed.parameter:"escalation-interval"`
+	WebhookPollInterval string `glazed.parameter:"webhook-poll-interval"`
+	OperatorInterval    string `glazed.parameter:"operator-interval"`
+	OperatorPolicy      string `glazed.parameter:"operator-policy"`
+	OperatorLLMMode     string `glazed.parameter:"operator-llm-mode"`
+	OperatorDryRun      bool   `glazed.parameter:"operator-dry-run"`
+	OperatorPaused      bool   `glazed.parameter:"operator-paused"`
+	ShutdownTimeout     string `glazed.parameter:"shutdown-timeout"`
 }
 
 func newServeGlazedCommand() (*serveGlazedCommand, error) {
@@ -230,7 +237,7 @@ func newServeGlazedCommand() (*serveGlazedCommand, error) {
 		CommandDescription: cmds.NewCommandDescription(
 			"serve",
 			cmds.WithShort("Run forum/API server"),
-			cmds.WithLong("Start the metawsm API server and forum worker loop."),
+			cmds.WithLong("Start the metawsm API server, forum worker loop, and operator supervision loop."),
 			cmds.WithFlags(
 				parameters.NewParameterDefinition(
 					"addr",
@@ -262,6 +269,48 @@ func newServeGlazedCommand() (*serveGlazedCommand, error) {
 					parameters.WithHelp("Forum worker summary log period"),
 					parameters.WithDefault("15s"),
 				),
+				parameters.NewParameterDefinition(
+					"escalation-interval",
+					parameters.ParameterTypeString,
+					parameters.WithHelp("Forum SLA escalation check interval"),
+					parameters.WithDefault("1m"),
+				),
+				parameters.NewParameterDefinition(
+					"webhook-poll-interval",
+					parameters.ParameterTypeString,
+					parameters.WithHelp("Run status poll interval for webhook notifications"),
+					parameters.WithDefault("15s"),
+				),
+				parameters.NewParameterDefinition(
+					"operator-interval",
+					parameters.ParameterTypeString,
+					parameters.WithHelp("Operator supervision pass interval"),
+					parameters.WithDefault("15s"),
+				),
+				parameters.NewParameterDefinition(
+					"operator-policy",
+					parameters.ParameterTypeString,
+					parameters.WithHelp("Path to policy file for the operator (defaults to .metawsm/policy.json)"),
+					parameters.WithDefault(""),
+				),
+				parameters.NewParameterDefinition(
+					"operator-llm-mode",
+					parameters.ParameterTypeString,
+					parameters.WithHelp("Operator LLM mode override (off|assist|auto)"),
+					parameters.WithDefault(""),
+				),
+				parameters.NewParameterDefinition(
+					"operator-dry-run",
+					parameters.ParameterTypeBool,
+					parameters.WithHelp("Record operator decisions without executing actions"),
+					parameters.WithDefault(false),
+				),
+				parameters.NewParameterDefinition(
+					"operator-paused",
+					parameters.ParameterTypeBool,
+					parameters.WithHelp("Start with the operator paused until resumed via the API"),
+					parameters.WithDefault(false),
+				),
 				parameters.NewParameterDefinition(
 					"shutdown-timeout",
 					parameters.ParameterTypeString,
@@ -295,18 +344,47 @@ func (c *serveGlazedCommand) Run(ctx context.Context, parsedLayers *layers.Parse
 	if err != nil {
 		return err
 	}
+	escalationInterval, err := parseDurationSetting("escalation-interval", settings.EscalationInterval)
+	if err != nil {
+		return err
+	}
+	webhookPollInterval, err := parseDurationSetting("webhook-poll-interval", settings.WebhookPollInterval)
+	if err != nil {
+		return err
+	}
+	operatorInterval, err := parseDurationSetting("operator-interval", settings.OperatorInterval)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(settings.OperatorLLMMode) != "" {
+		if _, err := orchestrator.ResolveOperatorLLMMode(settings.OperatorLLMMode, ""); err != nil {
+			return err
+		}
+	}
 	shutdownTimeout, err := parseDurationSetting("shutdown-timeout", settings.ShutdownTimeout)
 	if err != nil {
 		return err
 	}
 
+	authMode, err := serveAuthMode()
+	if err != nil {
+		return err
+	}
 	runtime, err := server.NewRuntime(server.Options{
-		Addr:            settings.Addr,
-		DBPath:          settings.DBPath,
-		WorkerInterval:  workerInterval,
-		WorkerBatchSize: settings.WorkerBatchSize,
-		WorkerLogPeriod: workerLogPeriod,
-		ShutdownTimeout: shutdownTimeout,
+		Addr:                settings.Addr,
+		DBPath:              settings.DBPath,
+		WorkerInterval:      workerInterval,
+		WorkerBatchSize:     settings.WorkerBatchSize,
+		WorkerLogPeriod:     workerLogPeriod,
+		EscalationInterval:  escalationInterval,
+		WebhookPollInterval: webhookPollInterval,
+		OperatorInterval:    operatorInterval,
+		OperatorPolicyPath:  settings.OperatorPolicy,
+		OperatorLLMMode:     settings.OperatorLLMMode,
+		OperatorDryRun:      settings.OperatorDryRun,
+		OperatorPaused:      settings.OperatorPaused,
+		ShutdownTimeout:     shutdownTimeout,
+		AuthMode:            authMode,
 	})
 	if err != nil {
 		return err
@@ -4,9 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
+	"os/signal"
 	"strings"
+	"syscall"
+	"time"
 
 	"metawsm/internal/orchestrator"
+	"metawsm/internal/serviceapi"
 
 	"github.com/go-go-golems/glazed/pkg/cmds"
 	"github.com/go-go-golems/glazed/pkg/cmds/layers"
@@ -75,6 +80,50 @@ func initializeRunSelector(parsedLayers *layers.ParsedLayers) (*runSelectorSetti
 	return settings, nil
 }
 
+// remoteRunActionTimeout bounds one lifecycle call against a daemon; commit, pr
+// and merge run git and gh on the server side and can take a while.
+const remoteRunActionTimeout = 10 * time.Minute
+
+type runCoreSettings struct {
+	Server string `glazed.parameter:"server"`
+}
+
+func newServerParameter() *parameters.ParameterDefinition {
+	return parameters.NewParameterDefinition(
+		"server",
+		parameters.ParameterTypeString,
+		parameters.WithHelp("metawsm serve base URL; when set, the action runs on that daemon instead of the local DB"),
+		parameters.WithDefault(""),
+	)
+}
+
+// resolveRunSelectorToCore returns the local core, or a remote one when --server
+// is set, together with the selected run id.
+func resolveRunSelectorToCore(ctx context.Context, parsedLayers *layers.ParsedLayers, selector *runSelectorSettings) (serviceapi.Core, string, error) {
+	settings := &runCoreSettings{}
+	if err := parsedLayers.InitializeStruct(layers.DefaultSlug, settings); err != nil {
+		return nil, "", err
+	}
+	runID, ticket, err := requireRunSelector(selector.RunID, selector.Ticket)
+	if err != nil {
+		return nil, "", err
+	}
+	var core serviceapi.Core
+	if server := strings.TrimSpace(settings.Server); server != "" {
+		core = newRemoteCore(server, remoteRunActionTimeout)
+	} else {
+		core, err = serviceapi.NewLocalCore(selector.DBPath)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	runID, err = core.ResolveRunID(ctx, runID, ticket)
+	if err != nil {
+		return nil, "", err
+	}
+	return core, runID, nil
+}
+
 func resolveRunSelectorToRunID(selector *runSelectorSettings) (*orchestrator.Service, string, error) {
 	runID, ticket, err := requireRunSelector(selector.RunID, selector.Ticket)
 	if err != nil {
@@ -95,11 +144,23 @@ type statusGlazedCommand struct {
 	*cmds.CommandDescription
 }
 
+type statusSettings struct {
+	Output string `glazed.parameter:"output"`
+}
+
 func newStatusGlazedCommand() (*statusGlazedCommand, error) {
 	desc, err := newRunSelectorCommandDescription(
 		"status",
 		"Print run status",
 		"Show status for the selected run.",
+		parameters.NewParameterDefinition(
+			"output",
+			parameters.ParameterTypeChoice,
+			parameters.WithHelp("Output format"),
+			parameters.WithChoices("text", "json", "yaml"),
+			parameters.WithDefault("text"),
+		),
+		newServerParameter(),
 	)
 	if err != nil {
 		return nil, err
@@ -112,16 +173,19 @@ func (c *statusGlazedCommand) Run(ctx context.Context, parsedLayers *layers.Pars
 	if err != nil {
 		return err
 	}
-	service, runID, err := resolveRunSelectorToRunID(selector)
+	settings := &statusSettings{}
+	if err := parsedLayers.InitializeStruct(layers.DefaultSlug, settings); err != nil {
+		return err
+	}
+	core, runID, err := resolveRunSelectorToCore(ctx, parsedLayers, selector)
 	if err != nil {
 		return err
 	}
-	status, err := service.Status(ctx, runID)
+	report, err := core.RunStatusReport(ctx, runID)
 	if err != nil {
 		return err
 	}
-	fmt.Print(status)
-	return nil
+	return printRunStatusReport(os.Stdout, report, settings.Output)
 }
 
 var _ cmds.BareCommand = &statusGlazedCommand{}
@@ -135,6 +199,7 @@ func newResumeGlazedCommand() (*resumeGlazedCommand, error) {
 		"resume",
 		"Resume a paused run",
 		"Resume the selected run.",
+		newServerParameter(),
 	)
 	if err != nil {
 		return nil, err
@@ -147,11 +212,11 @@ func (c *resumeGlazedCommand) Run(ctx context.Context, parsedLayers *layers.Pars
 	if err != nil {
 		return err
 	}
-	service, runID, err := resolveRunSelectorToRunID(selector)
+	core, runID, err := resolveRunSelectorToCore(ctx, parsedLayers, selector)
 	if err != nil {
 		return err
 	}
-	if err := service.Resume(ctx, runID); err != nil {
+	if err := core.ResumeRun(ctx, runID); err != nil {
 		return err
 	}
 	fmt.Printf("Run %s resumed.\n", runID)
@@ -169,6 +234,7 @@ func newStopGlazedCommand() (*stopGlazedCommand, error) {
 		"stop",
 		"Stop an active run",
 		"Stop the selected run.",
+		newServerParameter(),
 	)
 	if err != nil {
 		return nil, err
@@ -181,11 +247,11 @@ func (c *stopGlazedCommand) Run(ctx context.Context, parsedLayers *layers.Parsed
 	if err != nil {
 		return err
 	}
-	service, runID, err := resolveRunSelectorToRunID(selector)
+	core, runID, err := resolveRunSelectorToCore(ctx, parsedLayers, selector)
 	if err != nil {
 		return err
 	}
-	if err := service.Stop(ctx, runID); err != nil {
+	if err := core.StopRun(ctx, runID); err != nil {
 		return err
 	}
 	fmt.Printf("Run %s stopped.\n", runID)
@@ -213,6 +279,7 @@ func newRestartGlazedCommand() (*restartGlazedCommand, error) {
 			parameters.WithHelp("Preview restart actions without executing them"),
 			parameters.WithDefault(false),
 		),
+		newServerParameter(),
 	)
 	if err != nil {
 		return nil, err
@@ -230,17 +297,12 @@ func (c *restartGlazedCommand) Run(ctx context.Context, parsedLayers *layers.Par
 		return err
 	}
 
-	runID, ticket, err := requireRunSelector(selector.RunID, selector.Ticket)
+	core, runID, err := resolveRunSelectorToCore(ctx, parsedLayers, selector)
 	if err != nil {
 		return err
 	}
-	service, err := orchestrator.NewService(selector.DBPath)
-	if err != nil {
-		return err
-	}
-	result, err := service.Restart(ctx, orchestrator.RestartOptions{
+	result, err := core.RestartRun(ctx, serviceapi.RestartOptions{
 		RunID:  runID,
-		Ticket: ticket,
 		DryRun: restart.DryRun,
 	})
 	if err != nil {
@@ -286,6 +348,7 @@ func newCleanupGlazedCommand() (*cleanupGlazedCommand, error) {
 			parameters.WithHelp("Keep workspaces; only stop agent sessions"),
 			parameters.WithDefault(false),
 		),
+		newServerParameter(),
 	)
 	if err != nil {
 		return nil, err
@@ -303,17 +366,12 @@ func (c *cleanupGlazedCommand) Run(ctx context.Context, parsedLayers *layers.Par
 		return err
 	}
 
-	runID, ticket, err := requireRunSelector(selector.RunID, selector.Ticket)
-	if err != nil {
-		return err
-	}
-	service, err := orchestrator.NewService(selector.DBPath)
+	core, runID, err := resolveRunSelectorToCore(ctx, parsedLayers, selector)
 	if err != nil {
 		return err
 	}
-	result, err := service.Cleanup(ctx, orchestrator.CleanupOptions{
+	result, err := core.CleanupRun(ctx, serviceapi.CleanupOptions{
 		RunID:            runID,
-		Ticket:           ticket,
 		DryRun:           cleanup.DryRun,
 		DeleteWorkspaces: !cleanup.KeepWorkspaces,
 	})
@@ -360,6 +418,7 @@ func newMergeGlazedCommand() (*mergeGlazedCommand, error) {
 			parameters.WithHelp("Required acknowledgement for human-initiated merge execution"),
 			parameters.WithDefault(false),
 		),
+		newServerParameter(),
 	)
 	if err != nil {
 		return nil, err
@@ -380,17 +439,12 @@ func (c *mergeGlazedCommand) Run(ctx context.Context, parsedLayers *layers.Parse
 		return fmt.Errorf("merge requires --human acknowledgement; automated merge is disabled")
 	}
 
-	runID, ticket, err := requireRunSelector(selector.RunID, selector.Ticket)
+	core, runID, err := resolveRunSelectorToCore(ctx, parsedLayers, selector)
 	if err != nil {
 		return err
 	}
-	service, err := orchestrator.NewService(selector.DBPath)
-	if err != nil {
-		return err
-	}
-	result, err := service.Merge(ctx, orchestrator.MergeOptions{
+	result, err := core.MergeRun(ctx, serviceapi.MergeOptions{
 		RunID:  runID,
-		Ticket: ticket,
 		DryRun: merge.DryRun,
 	})
 	if err != nil {
@@ -447,6 +501,7 @@ func newCommitGlazedCommand() (*commitGlazedCommand, error) {
 			parameters.WithHelp("Preview commit actions without executing them"),
 			parameters.WithDefault(false),
 		),
+		newServerParameter(),
 	)
 	if err != nil {
 		return nil, err
@@ -464,23 +519,18 @@ func (c *commitGlazedCommand) Run(ctx context.Context, parsedLayers *layers.Pars
 		return err
 	}
 
-	runID, ticket, err := requireRunSelector(selector.RunID, selector.Ticket)
+	core, runID, err := resolveRunSelectorToCore(ctx, parsedLayers, selector)
 	if err != nil {
 		return err
 	}
-	service, err := orchestrator.NewService(selector.DBPath)
-	if err != nil {
-		return err
-	}
-	result, err := service.Commit(ctx, orchestrator.CommitOptions{
+	result, err := core.CommitRun(ctx, serviceapi.CommitOptions{
 		RunID:   runID,
-		Ticket:  ticket,
 		Message: commit.Message,
 		Actor:   commit.Actor,
 		DryRun:  commit.DryRun,
 	})
 	if err != nil {
-		var inProgress *orchestrator.RunMutationInProgressError
+		var inProgress *serviceapi.RunMutationInProgressError
 		if errors.As(err, &inProgress) {
 			return fmt.Errorf("%w; retry after the active %s operation completes", err, inProgress.Operation)
 		}
@@ -561,6 +611,7 @@ func newPRGlazedCommand() (*prGlazedCommand, error) {
 			parameters.WithHelp("Preview pull request actions without executing them"),
 			parameters.WithDefault(false),
 		),
+		newServerParameter(),
 	)
 	if err != nil {
 		return nil, err
@@ -578,24 +629,19 @@ func (c *prGlazedCommand) Run(ctx context.Context, parsedLayers *layers.ParsedLa
 		return err
 	}
 
-	runID, ticket, err := requireRunSelector(selector.RunID, selector.Ticket)
-	if err != nil {
-		return err
-	}
-	service, err := orchestrator.NewService(selector.DBPath)
+	core, runID, err := resolveRunSelectorToCore(ctx, parsedLayers, selector)
 	if err != nil {
 		return err
 	}
-	result, err := service.OpenPullRequests(ctx, orchestrator.PullRequestOptions{
+	result, err := core.OpenPullRequests(ctx, serviceapi.PullRequestOptions{
 		RunID:  runID,
-		Ticket: ticket,
 		Title:  pr.Title,
 		Body:   pr.Body,
 		Actor:  pr.Actor,
 		DryRun: pr.DryRun,
 	})
 	if err != nil {
-		var inProgress *orchestrator.RunMutationInProgressError
+		var inProgress *serviceapi.RunMutationInProgressError
 		if errors.As(err, &inProgress) {
 			return fmt.Errorf("%w; retry after the active %s operation completes", err, inProgress.Operation)
 		}
@@ -662,6 +708,7 @@ func newIterateGlazedCommand() (*iterateGlazedCommand, error) {
 			parameters.WithHelp("Preview iterate actions without executing them"),
 			parameters.WithDefault(false),
 		),
+		newServerParameter(),
 	)
 	if err != nil {
 		return nil, err
@@ -682,17 +729,12 @@ func (c *iterateGlazedCommand) Run(ctx context.Context, parsedLayers *layers.Par
 		return fmt.Errorf("--feedback is required")
 	}
 
-	runID, ticket, err := requireRunSelector(selector.RunID, selector.Ticket)
+	core, runID, err := resolveRunSelectorToCore(ctx, parsedLayers, selector)
 	if err != nil {
 		return err
 	}
-	service, err := orchestrator.NewService(selector.DBPath)
-	if err != nil {
-		return err
-	}
-	result, err := service.Iterate(ctx, orchestrator.IterateOptions{
+	result, err := core.IterateRun(ctx, serviceapi.IterateOptions{
 		RunID:    runID,
-		Ticket:   ticket,
 		Feedback: iterate.Feedback,
 		DryRun:   iterate.DryRun,
 	})
@@ -739,6 +781,7 @@ func newCloseGlazedCommand() (*closeGlazedCommand, error) {
 			parameters.WithHelp("Changelog entry for docmgr ticket close"),
 			parameters.WithDefault(""),
 		),
+		newServerParameter(),
 	)
 	if err != nil {
 		return nil, err
@@ -756,11 +799,11 @@ func (c *closeGlazedCommand) Run(ctx context.Context, parsedLayers *layers.Parse
 		return err
 	}
 
-	service, runID, err := resolveRunSelectorToRunID(selector)
+	core, runID, err := resolveRunSelectorToCore(ctx, parsedLayers, selector)
 	if err != nil {
 		return err
 	}
-	if err := service.Close(ctx, orchestrator.CloseOptions{
+	if err := core.CloseRun(ctx, serviceapi.CloseOptions{
 		RunID:          runID,
 		DryRun:         closeSettings.DryRun,
 		ChangelogEntry: closeSettings.ChangelogEntry,
@@ -776,3 +819,145 @@ func (c *closeGlazedCommand) Run(ctx context.Context, parsedLayers *layers.Parse
 }
 
 var _ cmds.BareCommand = &closeGlazedCommand{}
+
+type logsGlazedCommand struct {
+	*cmds.CommandDescription
+}
+
+type logsSettings struct {
+	Agent     string `glazed.parameter:"agent"`
+	Workspace string `glazed.parameter:"workspace"`
+	Follow    bool   `glazed.parameter:"follow"`
+	Lines     int    `glazed.parameter:"lines"`
+}
+
+func newLogsGlazedCommand() (*logsGlazedCommand, error) {
+	desc, err := newRunSelectorCommandDescription(
+		"logs",
+		"Print an agent transcript",
+		"Print the captured terminal transcript for one agent of the selected run.",
+		parameters.NewParameterDefinition(
+			"agent",
+			parameters.ParameterTypeString,
+			parameters.WithHelp("Agent name"),
+			parameters.WithDefault(""),
+		),
+		parameters.NewParameterDefinition(
+			"workspace",
+			parameters.ParameterTypeString,
+			parameters.WithHelp("Workspace name (required when the agent runs in several workspaces)"),
+			parameters.WithDefault(""),
+		),
+		parameters.NewParameterDefinition(
+			"follow",
+			parameters.ParameterTypeBool,
+			parameters.WithHelp("Keep printing new transcript output until interrupted"),
+			parameters.WithDefault(false),
+		),
+		parameters.NewParameterDefinition(
+			"lines",
+			parameters.ParameterTypeInteger,
+			parameters.WithHelp("Number of trailing lines to print first (0 prints the whole transcript)"),
+			parameters.WithDefault(200),
+		),
+		newServerParameter(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &logsGlazedCommand{CommandDescription: desc}, nil
+}
+
+func (c *logsGlazedCommand) Run(ctx context.Context, parsedLayers *layers.ParsedLayers) error {
+	selector, err := initializeRunSelector(parsedLayers)
+	if err != nil {
+		return err
+	}
+	logs := &logsSettings{}
+	if err := parsedLayers.InitializeStruct(layers.DefaultSlug, logs); err != nil {
+		return err
+	}
+	if strings.TrimSpace(logs.Agent) == "" {
+		return fmt.Errorf("--agent is required")
+	}
+	core, runID, err := resolveRunSelectorToCore(ctx, parsedLayers, selector)
+	if err != nil {
+		return err
+	}
+
+	options := serviceapi.AgentTranscriptReadOptions{
+		RunID:         runID,
+		AgentName:     logs.Agent,
+		WorkspaceName: logs.Workspace,
+		Offset:        -1,
+		Generation:    -1,
+	}
+	if logs.Lines <= 0 {
+		options.Offset = 0
+	}
+	chunk, err := core.ReadAgentTranscript(ctx, options)
+	if err != nil {
+		return err
+	}
+	// Whole-transcript reads keep going until the current end of file.
+	for logs.Lines <= 0 && chunk.Data != "" && chunk.NextOffset < chunk.Size {
+		fmt.Print(chunk.Data)
+		options.Offset = chunk.NextOffset
+		options.Generation = chunk.Generation
+		if chunk, err = core.ReadAgentTranscript(ctx, options); err != nil {
+			return err
+		}
+	}
+	if logs.Lines > 0 {
+		fmt.Print(lastTranscriptLines(chunk.Data, logs.Lines))
+	} else {
+		fmt.Print(chunk.Data)
+	}
+	if !logs.Follow {
+		return nil
+	}
+
+	ctx, cancel := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	options.Offset = chunk.NextOffset
+	options.Generation = chunk.Generation
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		chunk, err := core.ReadAgentTranscript(ctx, options)
+		if err != nil {
+			return err
+		}
+		if chunk.Reset {
+			fmt.Fprintf(os.Stderr, "-- transcript %s rotated --\n", chunk.Path)
+		}
+		fmt.Print(chunk.Data)
+		options.Offset = chunk.NextOffset
+		options.Generation = chunk.Generation
+	}
+}
+
+// lastTranscriptLines keeps the final n lines of data, dropping a leading
+// partial line left over from a byte-offset tail read.
+func lastTranscriptLines(data string, n int) string {
+	if n <= 0 || data == "" {
+		return data
+	}
+	trimmed := strings.TrimSuffix(data, "\n")
+	lines := strings.Split(trimmed, "\n")
+	if len(lines) <= n {
+		return data
+	}
+	out := strings.Join(lines[len(lines)-n:], "\n")
+	if strings.HasSuffix(data, "\n") {
+		out += "\n"
+	}
+	return out
+}
+
+var _ cmds.BareCommand = &logsGlazedCommand{}
@@ -14,7 +14,6 @@ import (
 	"path/filepath"
 	"regexp"
 	"sort"
-	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -25,6 +24,8 @@ import (
 	"metawsm/internal/policy"
 	"metawsm/internal/server"
 	"metawsm/internal/serviceapi"
+
+	"gopkg.in/yaml.v3"
 )
 
 type multiValueFlag []string
@@ -63,6 +64,9 @@ func runCommand(args []string) error {
 	var baseBranch string
 	var policyPath string
 	var dbPath string
+	var serverURL string
+	var template string
+	var templateVars multiValueFlag
 	var dryRun bool
 
 	fs.Var(&tickets, "ticket", "Ticket identifier (repeatable, or comma-separated)")
@@ -77,16 +81,19 @@ func runCommand(args []string) error {
 	fs.StringVar(&baseBranch, "base-branch", "", "Branch to use as workspace start point (default from policy, usually main)")
 	fs.StringVar(&policyPath, "policy", "", "Path to policy file (defaults to .metawsm/policy.json)")
 	fs.StringVar(&dbPath, "db", ".metawsm/metawsm.db", "Path to SQLite DB")
+	fs.StringVar(&serverURL, "server", "", "metawsm serve base URL; submit the run to that daemon instead of executing locally")
+	fs.StringVar(&template, "template", "", "Run template from policy; its values fill in flags left unset")
+	fs.Var(&templateVars, "var", "Run template variable as name=value (repeatable)")
 	fs.BoolVar(&dryRun, "dry-run", false, "Plan only; do not execute steps")
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
-
-	service, err := orchestrator.NewService(dbPath)
+	vars, err := parseTemplateVars(templateVars)
 	if err != nil {
 		return err
 	}
-	result, err := service.Run(context.Background(), orchestrator.RunOptions{
+
+	options := orchestrator.RunOptions{
 		RunID:             runID,
 		Tickets:           tickets,
 		Repos:             repos,
@@ -99,7 +106,10 @@ func runCommand(args []string) error {
 		WorkspaceStrategy: model.WorkspaceStrategy(strings.TrimSpace(strategy)),
 		PolicyPath:        policyPath,
 		DryRun:            dryRun,
-	})
+		Template:          strings.TrimSpace(template),
+		TemplateVars:      vars,
+	}
+	result, err := startRun(serverURL, dbPath, options)
 	if err != nil {
 		return err
 	}
@@ -113,10 +123,29 @@ func runCommand(args []string) error {
 	}
 	if dryRun {
 		fmt.Println("Run planned in dry-run mode.")
+	} else if strings.TrimSpace(serverURL) != "" {
+		fmt.Printf("Run submitted to %s; the daemon executes it in the background.\n", strings.TrimSpace(serverURL))
 	}
 	return nil
 }
 
+// startRun executes a run locally, or submits it to a daemon when serverURL is
+// set. Remote runs return once the plan is stored.
+func startRun(serverURL string, dbPath string, options orchestrator.RunOptions) (orchestrator.RunResult, error) {
+	if serverURL = strings.TrimSpace(serverURL); serverURL != "" {
+		if strings.TrimSpace(options.PolicyPath) != "" {
+			return orchestrator.RunResult{}, fmt.Errorf("--policy cannot be combined with --server; the daemon uses its own policy")
+		}
+		core := newRemoteCore(serverURL, remoteRunActionTimeout)
+		return core.StartRun(context.Background(), options)
+	}
+	service, err := orchestrator.NewService(dbPath)
+	if err != nil {
+		return orchestrator.RunResult{}, err
+	}
+	return service.Run(context.Background(), options)
+}
+
 func bootstrapCommand(args []string) error {
 	fs := flag.NewFlagSet("bootstrap", flag.ContinueOnError)
 	var ticket string
@@ -131,6 +160,7 @@ func bootstrapCommand(args []string) error {
 	var baseBranch string
 	var policyPath string
 	var dbPath string
+	var serverURL string
 	var dryRun bool
 	var goal string
 	var scope string
@@ -150,6 +180,7 @@ func bootstrapCommand(args []string) error {
 	fs.StringVar(&baseBranch, "base-branch", "", "Branch to use as workspace start point (default from policy, usually main)")
 	fs.StringVar(&policyPath, "policy", "", "Path to policy file (defaults to .metawsm/policy.json)")
 	fs.StringVar(&dbPath, "db", ".metawsm/metawsm.db", "Path to SQLite DB")
+	fs.StringVar(&serverURL, "server", "", "metawsm serve base URL; submit the bootstrap run to that daemon instead of executing locally")
 	fs.BoolVar(&dryRun, "dry-run", false, "Plan only; do not execute setup steps")
 	fs.StringVar(&goal, "goal", "", "Goal for what should be built")
 	fs.StringVar(&scope, "scope", "", "Scope (areas/files expected to change)")
@@ -181,15 +212,16 @@ func bootstrapCommand(args []string) error {
 	if err != nil {
 		return err
 	}
-	if err := ensureTicketExists(context.Background(), ticket, brief.Goal); err != nil {
-		return err
+	// Ticket docs live on the daemon host in server mode; its plan verifies the
+	// ticket and the brief is stored with the run instead of a local brief doc.
+	remote := strings.TrimSpace(serverURL) != ""
+	if !remote {
+		if err := ensureTicketExists(context.Background(), ticket, brief.Goal); err != nil {
+			return err
+		}
 	}
 
-	service, err := orchestrator.NewService(dbPath)
-	if err != nil {
-		return err
-	}
-	result, err := service.Run(context.Background(), orchestrator.RunOptions{
+	result, err := startRun(serverURL, dbPath, orchestrator.RunOptions{
 		RunID:             runID,
 		Tickets:           []string{ticket},
 		Repos:             repoTokens,
@@ -208,8 +240,10 @@ func bootstrapCommand(args []string) error {
 	if err != nil {
 		return err
 	}
-	if err := createBootstrapBriefDoc(context.Background(), ticket, result.RunID, brief); err != nil {
-		return err
+	if !remote {
+		if err := createBootstrapBriefDoc(context.Background(), ticket, result.RunID, brief); err != nil {
+			return err
+		}
 	}
 
 	fmt.Printf("Bootstrap Run ID: %s\n", result.RunID)
@@ -223,6 +257,8 @@ func bootstrapCommand(args []string) error {
 	}
 	if dryRun {
 		fmt.Println("Bootstrap planned in dry-run mode.")
+	} else if remote {
+		fmt.Printf("Bootstrap submitted to %s; the daemon runs setup in the background.\n", strings.TrimSpace(serverURL))
 	} else {
 		fmt.Println("Bootstrap setup complete. Use `metawsm status --run-id` to monitor guidance/completion.")
 	}
@@ -231,7 +267,7 @@ func bootstrapCommand(args []string) error {
 
 func forumCommand(args []string) error {
 	if len(args) == 0 {
-		return fmt.Errorf("usage: metawsm forum <ask|answer|assign|state|priority|close|list|thread|watch|signal|debug> [...]")
+		return fmt.Errorf("usage: metawsm forum <ask|answer|assign|state|priority|close|list|thread|watch|signal|debug|outbox|rebuild-projections|escalate|guidance> [...]")
 	}
 	subcommand := strings.TrimSpace(strings.ToLower(args[0]))
 	rest := args[1:]
@@ -258,6 +294,14 @@ func forumCommand(args []string) error {
 		return forumSignalCommand(rest)
 	case "debug":
 		return forumDebugCommand(rest)
+	case "outbox":
+		return forumOutboxCommand(rest)
+	case "rebuild-projections":
+		return forumRebuildProjectionsCommand(rest)
+	case "escalate":
+		return forumEscalateCommand(rest)
+	case "guidance":
+		return forumGuidanceCommand(rest)
 	default:
 		return fmt.Errorf("unknown forum subcommand %q", subcommand)
 	}
@@ -268,7 +312,29 @@ func newForumCore(serverURL string) (serviceapi.Core, error) {
 	if serverURL == "" {
 		serverURL = "http://127.0.0.1:3001"
 	}
-	return serviceapi.NewRemoteCore(serverURL, 15*time.Second), nil
+	return newRemoteCore(serverURL, 15*time.Second), nil
+}
+
+// apiTokenEnv holds the bearer token remote commands send to `metawsm serve`.
+// Agent sessions also get their run and agent name so `forum signal` works
+// without extra flags.
+const (
+	apiTokenEnv  = "METAWSM_API_TOKEN"
+	agentRunEnv  = "METAWSM_RUN_ID"
+	agentNameEnv = "METAWSM_AGENT_NAME"
+)
+
+func newRemoteCore(serverURL string, timeout time.Duration) *serviceapi.RemoteCore {
+	return serviceapi.NewRemoteCore(serverURL, timeout).WithToken(os.Getenv(apiTokenEnv))
+}
+
+// serveAuthMode reads server.auth.mode from the default policy file.
+func serveAuthMode() (string, error) {
+	cfg, _, err := policy.Load("")
+	if err != nil {
+		return "", err
+	}
+	return cfg.Server.Auth.Mode, nil
 }
 
 func forumAskCommand(args []string) error {
@@ -282,6 +348,8 @@ func forumAskCommand(args []string) error {
 	var priority string
 	var actorType string
 	var actorName string
+	var attachSpecs multiValueFlag
+	var attachCommand string
 	fs.StringVar(&serverURL, "server", "http://127.0.0.1:3001", "metawsm serve base URL")
 	fs.StringVar(&ticket, "ticket", "", "Ticket identifier")
 	fs.StringVar(&runID, "run-id", "", "Run identifier (optional)")
@@ -291,23 +359,30 @@ func forumAskCommand(args []string) error {
 	fs.StringVar(&priority, "priority", string(model.ForumPriorityNormal), "Thread priority: low|normal|high|urgent")
 	fs.StringVar(&actorType, "actor-type", string(model.ForumActorAgent), "Actor type: agent|operator|human|system")
 	fs.StringVar(&actorName, "actor-name", "", "Actor display name")
+	fs.Var(&attachSpecs, "attach", "Attachment KIND:PATH (snippet|diff|log|output; snippet accepts :START-END; PATH - reads stdin; repeatable)")
+	fs.StringVar(&attachCommand, "attach-command", "", "Command line recorded on output attachments")
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
+	attachments, err := parseForumAttachmentSpecs(attachSpecs, attachCommand, os.Stdin)
+	if err != nil {
+		return err
+	}
 	core, err := newForumCore(serverURL)
 	if err != nil {
 		return err
 	}
 	defer core.Shutdown()
 	thread, err := core.ForumOpenThread(context.Background(), serviceapi.ForumOpenThreadOptions{
-		Ticket:    strings.TrimSpace(ticket),
-		RunID:     strings.TrimSpace(runID),
-		AgentName: strings.TrimSpace(agentName),
-		Title:     strings.TrimSpace(title),
-		Body:      strings.TrimSpace(body),
-		Priority:  model.ForumPriority(strings.TrimSpace(priority)),
-		ActorType: model.ForumActorType(strings.TrimSpace(actorType)),
-		ActorName: strings.TrimSpace(actorName),
+		Ticket:      strings.TrimSpace(ticket),
+		RunID:       strings.TrimSpace(runID),
+		AgentName:   strings.TrimSpace(agentName),
+		Title:       strings.TrimSpace(title),
+		Body:        strings.TrimSpace(body),
+		Priority:    model.ForumPriority(strings.TrimSpace(priority)),
+		Attachments: attachments,
+		ActorType:   model.ForumActorType(strings.TrimSpace(actorType)),
+		ActorName:   strings.TrimSpace(actorName),
 	})
 	if err != nil {
 		return err
@@ -328,19 +403,28 @@ func forumAnswerCommand(args []string) error {
 	fs.StringVar(&body, "body", "", "Answer text")
 	fs.StringVar(&actorType, "actor-type", string(model.ForumActorOperator), "Actor type: agent|operator|human|system")
 	fs.StringVar(&actorName, "actor-name", "", "Actor display name")
+	var attachSpecs multiValueFlag
+	var attachCommand string
+	fs.Var(&attachSpecs, "attach", "Attachment KIND:PATH (snippet|diff|log|output; snippet accepts :START-END; PATH - reads stdin; repeatable)")
+	fs.StringVar(&attachCommand, "attach-command", "", "Command line recorded on output attachments")
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
+	attachments, err := parseForumAttachmentSpecs(attachSpecs, attachCommand, os.Stdin)
+	if err != nil {
+		return err
+	}
 	core, err := newForumCore(serverURL)
 	if err != nil {
 		return err
 	}
 	defer core.Shutdown()
 	thread, err := core.ForumAnswerThread(context.Background(), serviceapi.ForumAddPostOptions{
-		ThreadID:  strings.TrimSpace(threadID),
-		Body:      strings.TrimSpace(body),
-		ActorType: model.ForumActorType(strings.TrimSpace(actorType)),
-		ActorName: strings.TrimSpace(actorName),
+		ThreadID:    strings.TrimSpace(threadID),
+		Body:        strings.TrimSpace(body),
+		Attachments: attachments,
+		ActorType:   model.ForumActorType(strings.TrimSpace(actorType)),
+		ActorName:   strings.TrimSpace(actorName),
 	})
 	if err != nil {
 		return err
@@ -492,6 +576,9 @@ func forumListCommand(args []string) error {
 	var state string
 	var priority string
 	var assignee string
+	var query string
+	var actorType string
+	var actorName string
 	var limit int
 	fs.StringVar(&serverURL, "server", "http://127.0.0.1:3001", "metawsm serve base URL")
 	fs.StringVar(&ticket, "ticket", "", "Ticket filter")
@@ -499,6 +586,9 @@ func forumListCommand(args []string) error {
 	fs.StringVar(&state, "state", "", "State filter")
 	fs.StringVar(&priority, "priority", "", "Priority filter")
 	fs.StringVar(&assignee, "assignee", "", "Assignee name filter")
+	fs.StringVar(&query, "query", "", "Full-text query over titles, posts, and control questions/answers (\"phrase\", prefix*, OR)")
+	fs.StringVar(&actorType, "actor-type", "", "Only match text written by this actor type (agent|operator|human|system)")
+	fs.StringVar(&actorName, "actor", "", "Only match text written by this actor name")
 	fs.IntVar(&limit, "limit", 50, "Maximum results")
 	if err := fs.Parse(args); err != nil {
 		return err
@@ -508,14 +598,29 @@ func forumListCommand(args []string) error {
 		return err
 	}
 	defer core.Shutdown()
-	threads, err := core.ForumListThreads(model.ForumThreadFilter{
-		Ticket:   strings.TrimSpace(ticket),
-		RunID:    strings.TrimSpace(runID),
-		State:    model.ForumThreadState(strings.TrimSpace(state)),
-		Priority: model.ForumPriority(strings.TrimSpace(priority)),
-		Assignee: strings.TrimSpace(assignee),
-		Limit:    limit,
-	})
+	var threads []model.ForumThreadView
+	if strings.TrimSpace(query) != "" || strings.TrimSpace(actorType) != "" || strings.TrimSpace(actorName) != "" {
+		threads, err = core.ForumSearchThreads(serviceapi.ForumSearchThreadsOptions{
+			Query:     strings.TrimSpace(query),
+			Ticket:    strings.TrimSpace(ticket),
+			RunID:     strings.TrimSpace(runID),
+			State:     model.ForumThreadState(strings.TrimSpace(state)),
+			Priority:  model.ForumPriority(strings.TrimSpace(priority)),
+			Assignee:  strings.TrimSpace(assignee),
+			ActorType: model.ForumActorType(strings.TrimSpace(actorType)),
+			ActorName: strings.TrimSpace(actorName),
+			Limit:     limit,
+		})
+	} else {
+		threads, err = core.ForumListThreads(model.ForumThreadFilter{
+			Ticket:   strings.TrimSpace(ticket),
+			RunID:    strings.TrimSpace(runID),
+			State:    model.ForumThreadState(strings.TrimSpace(state)),
+			Priority: model.ForumPriority(strings.TrimSpace(priority)),
+			Assignee: strings.TrimSpace(assignee),
+			Limit:    limit,
+		})
+	}
 	if err != nil {
 		return err
 	}
@@ -525,10 +630,25 @@ func forumListCommand(args []string) error {
 	}
 	for _, thread := range threads {
 		printForumThreadSummary(thread)
+		if thread.Match != nil {
+			fmt.Printf("  match=%s by %s/%s hits=%d: %s\n",
+				thread.Match.DocKind,
+				emptyValue(string(thread.Match.ActorType), "-"),
+				emptyValue(thread.Match.ActorName, "-"),
+				thread.Match.Hits,
+				formatForumSearchSnippet(thread.Match.Snippet),
+			)
+		}
 	}
 	return nil
 }
 
+// formatForumSearchSnippet renders highlighted terms as [term] on one line.
+func formatForumSearchSnippet(snippet string) string {
+	snippet = strings.NewReplacer("<mark>", "[", "</mark>", "]", "\r", " ", "\n", " ").Replace(snippet)
+	return strings.Join(strings.Fields(snippet), " ")
+}
+
 func forumThreadCommand(args []string) error {
 	fs := flag.NewFlagSet("forum thread", flag.ContinueOnError)
 	var serverURL string
@@ -561,11 +681,53 @@ func forumThreadCommand(args []string) error {
 				emptyValue(post.AuthorName, "-"),
 				post.Body,
 			)
+			for _, attachment := range post.Attachments {
+				printForumAttachment(attachment)
+			}
+		}
+	}
+	if len(detail.Escalations) > 0 {
+		fmt.Println("Escalations:")
+		for _, escalation := range detail.Escalations {
+			printForumEscalation(escalation)
 		}
 	}
 	return nil
 }
 
+func printForumAttachment(attachment model.ForumAttachment) {
+	label := emptyValue(attachment.Title, "-")
+	switch attachment.Kind {
+	case model.ForumAttachmentSnippet:
+		label = attachment.Path
+		if attachment.Repo != "" {
+			label = attachment.Repo + "/" + label
+		}
+		if attachment.StartLine > 0 {
+			label += fmt.Sprintf(":%d-%d", attachment.StartLine, attachment.EndLine)
+		}
+	case model.ForumAttachmentCommandOutput:
+		label = "$ " + attachment.Command
+		if attachment.ExitCode != nil {
+			label += fmt.Sprintf(" (exit %d)", *attachment.ExitCode)
+		}
+	}
+	fmt.Printf("      [%s] %s %d bytes %s\n", attachment.Kind, label, attachment.Size, attachment.Digest)
+}
+
+func printForumEscalation(escalation model.ForumEscalation) {
+	fmt.Printf("  - %s thread=%s level=%d priority=%s->%s assignee=%s->%s reason=%q\n",
+		escalation.EscalatedAt.Format(time.RFC3339),
+		escalation.ThreadID,
+		escalation.Level,
+		escalation.FromPriority,
+		escalation.ToPriority,
+		emptyValue(escalation.FromAssigneeName, "-"),
+		emptyValue(escalation.ToAssigneeName, "-"),
+		escalation.Reason,
+	)
+}
+
 func forumWatchCommand(args []string) error {
 	fs := flag.NewFlagSet("forum watch", flag.ContinueOnError)
 	var serverURL string
@@ -649,7 +811,8 @@ func forumDebugCommand(args []string) error {
 		emptyValue(snapshot.Ticket, "-"),
 		emptyValue(snapshot.RunID, "-"),
 	)
-	fmt.Printf("bus running=%t healthy=%t stream=%s group=%s consumer=%s redis=%s\n",
+	fmt.Printf("bus transport=%s running=%t healthy=%t stream=%s group=%s consumer=%s redis=%s\n",
+		emptyValue(snapshot.Bus.Transport, "-"),
 		snapshot.Bus.Running,
 		snapshot.Bus.Healthy,
 		emptyValue(snapshot.Bus.StreamName, "-"),
@@ -660,10 +823,11 @@ func forumDebugCommand(args []string) error {
 	if strings.TrimSpace(snapshot.Bus.HealthError) != "" {
 		fmt.Printf("  health_error=%s\n", snapshot.Bus.HealthError)
 	}
-	fmt.Printf("outbox pending=%d processing=%d failed=%d oldest_pending_age=%ds\n",
+	fmt.Printf("outbox pending=%d processing=%d failed=%d dead_letter=%d oldest_pending_age=%ds\n",
 		snapshot.Outbox.PendingCount,
 		snapshot.Outbox.ProcessingCount,
 		snapshot.Outbox.FailedCount,
+		snapshot.Outbox.DeadLetterCount,
 		snapshot.Outbox.OldestPendingAgeSec,
 	)
 
@@ -730,6 +894,461 @@ func forumDebugCommand(args []string) error {
 	return nil
 }
 
+func forumOutboxCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: metawsm forum outbox <list|retry|purge> [...]")
+	}
+	subcommand := strings.TrimSpace(strings.ToLower(args[0]))
+	rest := args[1:]
+	switch subcommand {
+	case "list":
+		return forumOutboxListCommand(rest)
+	case "retry":
+		return forumOutboxRetryCommand(rest)
+	case "purge":
+		return forumOutboxPurgeCommand(rest)
+	default:
+		return fmt.Errorf("unknown forum outbox subcommand %q", subcommand)
+	}
+}
+
+func forumOutboxListCommand(args []string) error {
+	fs := flag.NewFlagSet("forum outbox list", flag.ContinueOnError)
+	var serverURL string
+	var status string
+	var limit int
+	var asJSON bool
+	fs.StringVar(&serverURL, "server", "http://127.0.0.1:3001", "metawsm serve base URL")
+	fs.StringVar(&status, "status", "", "Status filter (pending|processing|sent|failed|dead_letter)")
+	fs.IntVar(&limit, "limit", 50, "Maximum messages")
+	fs.BoolVar(&asJSON, "json", false, "Print messages as JSON, including payloads")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	core, err := newForumCore(serverURL)
+	if err != nil {
+		return err
+	}
+	defer core.Shutdown()
+	messages, err := core.ForumListOutbox(context.Background(), serviceapi.ForumOutboxListOptions{
+		Status: model.ForumOutboxStatus(strings.TrimSpace(status)),
+		Limit:  limit,
+	})
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(map[string]any{"messages": messages})
+	}
+	if len(messages) == 0 {
+		fmt.Println("No outbox messages found.")
+		return nil
+	}
+	for _, message := range messages {
+		nextAttempt := "-"
+		if message.NextAttemptAt != nil {
+			nextAttempt = message.NextAttemptAt.Format(time.RFC3339)
+		}
+		fmt.Printf("message_id=%s status=%s topic=%s attempts=%d next_attempt_at=%s updated_at=%s\n",
+			message.MessageID,
+			message.Status,
+			message.Topic,
+			message.AttemptCount,
+			nextAttempt,
+			message.UpdatedAt.Format(time.RFC3339),
+		)
+		if strings.TrimSpace(message.LastError) != "" {
+			fmt.Printf("  error=%s\n", message.LastError)
+		}
+	}
+	return nil
+}
+
+func forumOutboxRetryCommand(args []string) error {
+	fs := flag.NewFlagSet("forum outbox retry", flag.ContinueOnError)
+	var serverURL string
+	var messageIDs multiValueFlag
+	var all bool
+	fs.StringVar(&serverURL, "server", "http://127.0.0.1:3001", "metawsm serve base URL")
+	fs.Var(&messageIDs, "message-id", "Outbox message ID to requeue (repeatable)")
+	fs.BoolVar(&all, "all", false, "Requeue every dead-lettered message")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if len(messageIDs) == 0 && !all {
+		return fmt.Errorf("--message-id or --all is required")
+	}
+	core, err := newForumCore(serverURL)
+	if err != nil {
+		return err
+	}
+	defer core.Shutdown()
+	requeued, err := core.ForumRetryOutbox(context.Background(), serviceapi.ForumOutboxRetryOptions{
+		MessageIDs: messageIDs,
+		All:        all,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Requeued %d outbox message(s).\n", requeued)
+	return nil
+}
+
+func forumOutboxPurgeCommand(args []string) error {
+	fs := flag.NewFlagSet("forum outbox purge", flag.ContinueOnError)
+	var serverURL string
+	var status string
+	var olderThan time.Duration
+	fs.StringVar(&serverURL, "server", "http://127.0.0.1:3001", "metawsm serve base URL")
+	fs.StringVar(&status, "status", string(model.ForumOutboxStatusSent), "Status to purge (sent|dead_letter)")
+	fs.DurationVar(&olderThan, "older-than", 24*time.Hour, "Only purge messages last updated longer ago than this (0 purges all)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	core, err := newForumCore(serverURL)
+	if err != nil {
+		return err
+	}
+	defer core.Shutdown()
+	purged, err := core.ForumPurgeOutbox(context.Background(), serviceapi.ForumOutboxPurgeOptions{
+		Status:    model.ForumOutboxStatus(strings.TrimSpace(status)),
+		OlderThan: olderThan,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Purged %d %s outbox message(s).\n", purged, strings.TrimSpace(status))
+	return nil
+}
+
+func forumRebuildProjectionsCommand(args []string) error {
+	fs := flag.NewFlagSet("forum rebuild-projections", flag.ContinueOnError)
+	var serverURL string
+	var ticket string
+	var projections multiValueFlag
+	var asJSON bool
+	fs.StringVar(&serverURL, "server", "http://127.0.0.1:3001", "metawsm serve base URL")
+	fs.StringVar(&ticket, "ticket", "", "Only rebuild projection rows for this ticket")
+	fs.Var(&projections, "projection", "Projection to rebuild (forum_thread_views|forum_thread_queue_view|forum_thread_stats|forum_search_documents; repeatable, default all)")
+	fs.BoolVar(&asJSON, "json", false, "Print the rebuild report as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	core, err := newForumCore(serverURL)
+	if err != nil {
+		return err
+	}
+	defer core.Shutdown()
+	report, err := core.ForumRebuildProjections(context.Background(), serviceapi.ForumRebuildProjectionsOptions{
+		Ticket:      strings.TrimSpace(ticket),
+		Projections: projections,
+	})
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(map[string]any{"report": report})
+	}
+
+	fmt.Printf("ticket=%s projections=%s events_replayed=%d\n",
+		emptyValue(report.Ticket, "-"),
+		strings.Join(report.Projections, ","),
+		report.EventsReplayed,
+	)
+	for _, count := range report.Counts {
+		fmt.Printf("  - projection=%s live_rows=%d rebuilt_rows=%d\n", count.Projection, count.LiveRows, count.RebuiltRows)
+	}
+	if len(report.Diffs) == 0 {
+		fmt.Println("Rebuilt projections match the live tables.")
+		return nil
+	}
+	fmt.Printf("differences count=%d\n", len(report.Diffs))
+	for _, diff := range report.Diffs {
+		fmt.Printf("  - projection=%s key=%s kind=%s\n", diff.Projection, diff.Key, diff.Kind)
+		for _, field := range diff.Fields {
+			fmt.Printf("    %s: live=%q rebuilt=%q\n", field.Field, field.Live, field.Rebuilt)
+		}
+	}
+	return nil
+}
+
+func forumEscalateCommand(args []string) error {
+	fs := flag.NewFlagSet("forum escalate", flag.ContinueOnError)
+	var serverURL string
+	var asJSON bool
+	fs.StringVar(&serverURL, "server", "http://127.0.0.1:3001", "metawsm serve base URL")
+	fs.BoolVar(&asJSON, "json", false, "Print the escalation pass result as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	core, err := newForumCore(serverURL)
+	if err != nil {
+		return err
+	}
+	defer core.Shutdown()
+	result, err := core.ForumEscalateOverdueThreads(context.Background(), serviceapi.ForumEscalationPassOptions{})
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(map[string]any{"result": result})
+	}
+	fmt.Printf("checked=%d escalated=%d\n", result.Checked, len(result.Escalated))
+	for _, escalation := range result.Escalated {
+		printForumEscalation(escalation)
+	}
+	for _, notifyErr := range result.NotifyErrors {
+		fmt.Printf("  notify failed: %s\n", notifyErr)
+	}
+	return nil
+}
+
+func forumGuidanceCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: metawsm forum guidance <list|promote|remove|sync|suggest> [...]")
+	}
+	subcommand := strings.TrimSpace(strings.ToLower(args[0]))
+	rest := args[1:]
+	switch subcommand {
+	case "list":
+		return forumGuidanceListCommand(rest)
+	case "promote":
+		return forumGuidancePromoteCommand(rest)
+	case "remove":
+		return forumGuidanceRemoveCommand(rest)
+	case "sync":
+		return forumGuidanceSyncCommand(rest)
+	case "suggest":
+		return forumGuidanceSuggestCommand(rest)
+	default:
+		return fmt.Errorf("unknown forum guidance subcommand %q", subcommand)
+	}
+}
+
+func forumGuidanceListCommand(args []string) error {
+	fs := flag.NewFlagSet("forum guidance list", flag.ContinueOnError)
+	var serverURL string
+	var scopeType string
+	var scope string
+	var ticket string
+	var limit int
+	var asJSON bool
+	fs.StringVar(&serverURL, "server", "http://127.0.0.1:3001", "metawsm serve base URL")
+	fs.StringVar(&scopeType, "scope-type", "", "Scope filter (history|ticket|repo)")
+	fs.StringVar(&scope, "scope", "", "Ticket or repo the canned answer is scoped to")
+	fs.StringVar(&ticket, "ticket", "", "Ticket the answer was given on")
+	fs.IntVar(&limit, "limit", 100, "Maximum answers")
+	fs.BoolVar(&asJSON, "json", false, "Print answers as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	core, err := newForumCore(serverURL)
+	if err != nil {
+		return err
+	}
+	defer core.Shutdown()
+	answers, err := core.GuidanceLibraryList(model.GuidanceAnswerFilter{
+		ScopeType: model.GuidanceAnswerScope(strings.TrimSpace(scopeType)),
+		Scope:     strings.TrimSpace(scope),
+		Ticket:    strings.TrimSpace(ticket),
+		Limit:     limit,
+	})
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(map[string]any{"answers": answers})
+	}
+	if len(answers) == 0 {
+		fmt.Println("No guidance answers found.")
+		return nil
+	}
+	for _, answer := range answers {
+		fmt.Printf("answer_id=%s scope=%s%s ticket=%s uses=%d updated_at=%s\n",
+			answer.AnswerID,
+			answer.ScopeType,
+			formatGuidanceScopeSuffix(answer.Scope),
+			answer.Ticket,
+			answer.UseCount,
+			answer.UpdatedAt.Format(time.RFC3339),
+		)
+		fmt.Printf("  Q: %s\n  A: %s\n", answer.Question, answer.Answer)
+	}
+	return nil
+}
+
+func forumGuidancePromoteCommand(args []string) error {
+	fs := flag.NewFlagSet("forum guidance promote", flag.ContinueOnError)
+	var serverURL string
+	var answerID string
+	var ticket string
+	var repo string
+	var question string
+	var contextText string
+	var answer string
+	var actorName string
+	fs.StringVar(&serverURL, "server", "http://127.0.0.1:3001", "metawsm serve base URL")
+	fs.StringVar(&answerID, "answer-id", "", "Library answer to promote")
+	fs.StringVar(&ticket, "ticket", "", "Scope the canned answer to this ticket")
+	fs.StringVar(&repo, "repo", "", "Scope the canned answer to this repo")
+	fs.StringVar(&question, "question", "", "Question text (overrides the promoted answer's)")
+	fs.StringVar(&contextText, "context", "", "Question context (overrides the promoted answer's)")
+	fs.StringVar(&answer, "answer", "", "Answer text (overrides the promoted answer's)")
+	fs.StringVar(&actorName, "actor-name", "", "Actor display name")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	options := serviceapi.GuidanceLibraryPromoteOptions{
+		AnswerID:  strings.TrimSpace(answerID),
+		Question:  strings.TrimSpace(question),
+		Context:   strings.TrimSpace(contextText),
+		Answer:    strings.TrimSpace(answer),
+		ActorName: strings.TrimSpace(actorName),
+	}
+	switch {
+	case strings.TrimSpace(ticket) != "" && strings.TrimSpace(repo) != "":
+		return fmt.Errorf("use only one of --ticket or --repo")
+	case strings.TrimSpace(ticket) != "":
+		options.ScopeType, options.Scope = model.GuidanceAnswerScopeTicket, strings.TrimSpace(ticket)
+	case strings.TrimSpace(repo) != "":
+		options.ScopeType, options.Scope = model.GuidanceAnswerScopeRepo, strings.TrimSpace(repo)
+	default:
+		return fmt.Errorf("--ticket or --repo is required")
+	}
+	if options.AnswerID == "" && (options.Question == "" || options.Answer == "") {
+		return fmt.Errorf("--answer-id or both --question and --answer are required")
+	}
+	core, err := newForumCore(serverURL)
+	if err != nil {
+		return err
+	}
+	defer core.Shutdown()
+	promoted, err := core.GuidanceLibraryPromote(context.Background(), options)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Promoted guidance %s to %s %s.\n", promoted.AnswerID, promoted.ScopeType, promoted.Scope)
+	return nil
+}
+
+func forumGuidanceRemoveCommand(args []string) error {
+	fs := flag.NewFlagSet("forum guidance remove", flag.ContinueOnError)
+	var serverURL string
+	var answerID string
+	fs.StringVar(&serverURL, "server", "http://127.0.0.1:3001", "metawsm serve base URL")
+	fs.StringVar(&answerID, "answer-id", "", "Library answer to remove")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if strings.TrimSpace(answerID) == "" {
+		return fmt.Errorf("--answer-id is required")
+	}
+	core, err := newForumCore(serverURL)
+	if err != nil {
+		return err
+	}
+	defer core.Shutdown()
+	if err := core.GuidanceLibraryRemove(context.Background(), strings.TrimSpace(answerID)); err != nil {
+		return err
+	}
+	fmt.Printf("Removed guidance %s.\n", strings.TrimSpace(answerID))
+	return nil
+}
+
+func forumGuidanceSyncCommand(args []string) error {
+	fs := flag.NewFlagSet("forum guidance sync", flag.ContinueOnError)
+	var serverURL string
+	fs.StringVar(&serverURL, "server", "http://127.0.0.1:3001", "metawsm serve base URL")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	core, err := newForumCore(serverURL)
+	if err != nil {
+		return err
+	}
+	defer core.Shutdown()
+	added, err := core.GuidanceLibrarySync(context.Background())
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Harvested %d new guidance answer(s).\n", added)
+	return nil
+}
+
+func forumGuidanceSuggestCommand(args []string) error {
+	fs := flag.NewFlagSet("forum guidance suggest", flag.ContinueOnError)
+	var serverURL string
+	var runID string
+	var agentName string
+	var ticket string
+	var repos multiValueFlag
+	var question string
+	var limit int
+	var asJSON bool
+	fs.StringVar(&serverURL, "server", "http://127.0.0.1:3001", "metawsm serve base URL")
+	fs.StringVar(&runID, "run-id", "", "Run whose ticket, repos and pending question to use")
+	fs.StringVar(&agentName, "agent", "", "Agent whose pending question to use")
+	fs.StringVar(&ticket, "ticket", "", "Ticket for ticket-scoped canned answers")
+	fs.Var(&repos, "repo", "Repo for repo-scoped canned answers (repeatable)")
+	fs.StringVar(&question, "question", "", "Question to match")
+	fs.IntVar(&limit, "limit", 0, "Maximum suggestions (default from policy)")
+	fs.BoolVar(&asJSON, "json", false, "Print suggestions as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	core, err := newForumCore(serverURL)
+	if err != nil {
+		return err
+	}
+	defer core.Shutdown()
+	suggestions, err := core.GuidanceSuggest(serviceapi.GuidanceSuggestOptions{
+		RunID:     strings.TrimSpace(runID),
+		AgentName: strings.TrimSpace(agentName),
+		Ticket:    strings.TrimSpace(ticket),
+		Repos:     repos,
+		Question:  strings.TrimSpace(question),
+		Limit:     limit,
+	})
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(map[string]any{"suggestions": suggestions})
+	}
+	if len(suggestions) == 0 {
+		fmt.Println("No matching guidance answers.")
+		return nil
+	}
+	for _, suggestion := range suggestions {
+		fmt.Printf("answer_id=%s scope=%s%s score=%.2f exact=%t\n",
+			suggestion.Answer.AnswerID,
+			suggestion.Answer.ScopeType,
+			formatGuidanceScopeSuffix(suggestion.Answer.Scope),
+			suggestion.Score,
+			suggestion.Exact,
+		)
+		fmt.Printf("  Q: %s\n  A: %s\n", suggestion.Answer.Question, suggestion.Answer.Answer)
+	}
+	return nil
+}
+
+func formatGuidanceScopeSuffix(scope string) string {
+	if strings.TrimSpace(scope) == "" {
+		return ""
+	}
+	return ":" + scope
+}
+
 func forumSignalCommand(args []string) error {
 	fs := flag.NewFlagSet("forum signal", flag.ContinueOnError)
 	var serverURL string
@@ -746,9 +1365,9 @@ func forumSignalCommand(args []string) error {
 	var actorType string
 	var actorName string
 	fs.StringVar(&serverURL, "server", "http://127.0.0.1:3001", "metawsm serve base URL")
-	fs.StringVar(&runID, "run-id", "", "Run identifier")
+	fs.StringVar(&runID, "run-id", os.Getenv(agentRunEnv), "Run identifier (defaults to $"+agentRunEnv+")")
 	fs.StringVar(&ticket, "ticket", "", "Ticket identifier")
-	fs.StringVar(&agentName, "agent-name", "", "Agent name")
+	fs.StringVar(&agentName, "agent-name", os.Getenv(agentNameEnv), "Agent name (defaults to $"+agentNameEnv+")")
 	fs.StringVar(&signalType, "type", "", "Signal type: guidance_request|guidance_answer|completion|validation")
 	fs.StringVar(&question, "question", "", "Guidance question body")
 	fs.StringVar(&contextText, "context", "", "Optional question context")
@@ -831,9 +1450,11 @@ func statusCommand(args []string) error {
 	var runID string
 	var ticket string
 	var dbPath string
+	var output string
 	fs.StringVar(&runID, "run-id", "", "Run identifier")
 	fs.StringVar(&ticket, "ticket", "", "Ticket identifier (status latest run for this ticket)")
 	fs.StringVar(&dbPath, "db", ".metawsm/metawsm.db", "Path to SQLite DB")
+	fs.StringVar(&output, "output", "text", "Output format: text|json|yaml")
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
@@ -850,12 +1471,42 @@ func statusCommand(args []string) error {
 	if err != nil {
 		return err
 	}
-	status, err := service.Status(context.Background(), runID)
+	report, err := service.StatusReport(context.Background(), runID)
 	if err != nil {
 		return err
 	}
-	fmt.Print(status)
-	return nil
+	return printRunStatusReport(os.Stdout, report, output)
+}
+
+// printRunStatusReport writes report as status text, or as JSON/YAML using
+// the report's JSON field names.
+func printRunStatusReport(w io.Writer, report orchestrator.RunStatusReport, output string) error {
+	switch strings.TrimSpace(strings.ToLower(output)) {
+	case "", "text":
+		_, err := io.WriteString(w, orchestrator.FormatRunStatus(report))
+		return err
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	case "yaml":
+		b, err := json.Marshal(report)
+		if err != nil {
+			return err
+		}
+		var doc any
+		if err := json.Unmarshal(b, &doc); err != nil {
+			return err
+		}
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(doc); err != nil {
+			return err
+		}
+		return encoder.Close()
+	default:
+		return fmt.Errorf("--output must be text|json|yaml")
+	}
 }
 
 type authRepoCheck struct {
@@ -1214,19 +1865,6 @@ type watchAgentIssue struct {
 	Reason       string
 }
 
-type operatorSessionEvidence struct {
-	Session      string
-	HasSession   bool
-	LastActivity *time.Time
-	ExitCode     *int
-}
-
-type operatorSessionProbe func(ctx context.Context, session string) (operatorSessionEvidence, error)
-
-var operatorAgentExitRegex = regexp.MustCompile(`\[metawsm\] agent command exited with status ([0-9]+)`)
-var operatorDocmgrDocsRootRegex = regexp.MustCompile("Docs root:\\s+`([^`]+)`")
-var operatorDocmgrTicketPathRegex = regexp.MustCompile("Path:\\s+`([^`]+)`")
-
 type watchMode int
 
 const (
@@ -1412,28 +2050,15 @@ func operatorCommand(args []string) error {
 	if err != nil {
 		return err
 	}
-	effectiveLLMMode, err := resolveOperatorLLMMode(llmMode, cfg.Operator.LLM.Mode)
+	effectiveLLMMode, err := orchestrator.ResolveOperatorLLMMode(llmMode, cfg.Operator.LLM.Mode)
 	if err != nil {
 		return err
 	}
-	staleAge := time.Duration(cfg.Operator.StaleRunAgeSeconds) * time.Second
-	runtimeRecentWindow := time.Duration(cfg.Health.ActivityStalledSeconds) * time.Second
-	restartCooldown := time.Duration(cfg.Operator.RestartCooldownSeconds) * time.Second
 
 	service, err := orchestrator.NewService(dbPath)
 	if err != nil {
 		return err
 	}
-	var llmAdapter operatorLLMAdapter
-	if effectiveLLMMode != "off" {
-		llmAdapter = newCodexCLIAdapter(
-			cfg.Operator.LLM.Command,
-			cfg.Operator.LLM.Model,
-			cfg.Operator.LLM.MaxTokens,
-			time.Duration(cfg.Operator.LLM.TimeoutSeconds)*time.Second,
-			nil,
-		)
-	}
 
 	selectedRunID := ""
 	selectedTicket := strings.TrimSpace(ticket)
@@ -1452,6 +2077,14 @@ func operatorCommand(args []string) error {
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
+	interval := time.Duration(intervalSeconds) * time.Second
+	holder := operatorCLIHolder()
+	defer func() {
+		if err := service.ReleaseOperatorLeases(holder); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: release operator leases: %v\n", err)
+		}
+	}()
+
 	if mode == watchModeAllActiveRuns {
 		fmt.Printf("Operator supervising all active runs (interval=%ds llm_mode=%s dry_run=%t).\n", intervalSeconds, effectiveLLMMode, dryRun)
 	} else {
@@ -1459,55 +2092,36 @@ func operatorCommand(args []string) error {
 	}
 	fmt.Println("Operator signals: guidance-needed, stale-candidate-verified, stale-candidate-rejected, commit-ready, pr-ready, review-feedback-ready.")
 
-	ticker := time.NewTicker(time.Duration(intervalSeconds) * time.Second)
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
-	lastAlertByRun := map[string]string{}
-	trackedRuns := map[string]struct{}{}
-	consecutiveUnhealthyByRun := map[string]int{}
-	if mode == watchModeSingleRun {
-		trackedRuns[selectedRunID] = struct{}{}
-	}
-
 	for {
-		activeRuns, err := service.ActiveRuns()
+		result, err := service.OperatorPass(ctx, orchestrator.OperatorPassOptions{
+			RunID:      selectedRunID,
+			PolicyPath: policyPath,
+			LLMMode:    llmMode,
+			DryRun:     dryRun,
+			Holder:     holder,
+			LeaseTTL:   3 * interval,
+		})
 		if err != nil {
+			if isWatchStopError(ctx, err) {
+				fmt.Println("\nOperator stopped.")
+				return nil
+			}
 			return err
 		}
-		activeByRunID := map[string]model.RunRecord{}
-		for _, run := range activeRuns {
-			activeByRunID[run.RunID] = run
-		}
-
-		snapshots := []watchSnapshot{}
-		if mode == watchModeSingleRun {
-			snapshot, err := loadWatchSnapshot(ctx, service, selectedRunID, selectedTicket)
-			if err != nil {
-				if isWatchStopError(ctx, err) {
-					fmt.Println("\nOperator stopped.")
-					return nil
-				}
-				return err
-			}
-			snapshots = append(snapshots, snapshot)
-		} else {
-			snapshots, err = loadWatchSnapshotsAll(ctx, service, trackedRuns)
-			if err != nil {
-				if isWatchStopError(ctx, err) {
-					fmt.Println("\nOperator stopped.")
-					return nil
-				}
-				return err
-			}
+		for _, warning := range result.Warnings {
+			fmt.Fprintf(os.Stderr, "warning: %s\n", warning)
 		}
-		sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].RunID < snapshots[j].RunID })
 
 		now := time.Now()
-		if len(snapshots) == 0 {
+		if len(result.Runs) == 0 {
 			fmt.Printf("[%s] operator heartbeat active_runs=0\n", now.Format(time.RFC3339))
 		}
 
-		for _, snapshot := range snapshots {
+		for _, run := range result.Runs {
+			snapshot := watchSnapshotFromRunSnapshot(run.Snapshot, selectedRunID, selectedTicket)
 			fmt.Printf("[%s] operator heartbeat run=%s status=%s guidance=%t unhealthy_agents=%t\n",
 				now.Format(time.RFC3339),
 				snapshot.RunID,
@@ -1515,113 +2129,30 @@ func operatorCommand(args []string) error {
 				snapshot.HasGuidance,
 				snapshot.HasUnhealthyAgents,
 			)
-
-			runRecord, ok := activeByRunID[snapshot.RunID]
-			if !ok {
-				delete(lastAlertByRun, snapshot.RunID)
-				delete(consecutiveUnhealthyByRun, snapshot.RunID)
+			if run.LeaseHolder != "" {
+				fmt.Printf("  supervised by %s\n", run.LeaseHolder)
 				continue
 			}
-
-			if snapshot.HasUnhealthyAgents && strings.EqualFold(snapshot.RunStatus, string(model.RunStatusRunning)) {
-				consecutiveUnhealthyByRun[snapshot.RunID]++
-			} else {
-				consecutiveUnhealthyByRun[snapshot.RunID] = 0
-			}
-
-			ruleDecision, err := buildOperatorRuleDecision(
-				ctx,
-				service,
-				snapshot,
-				runRecord,
-				now,
-				staleAge,
-				runtimeRecentWindow,
-				cfg.Operator.UnhealthyConfirmations,
-				cfg.Operator.RestartBudget,
-				consecutiveUnhealthyByRun[snapshot.RunID],
-				probeOperatorSessionEvidence,
-				cfg.GitPR.Mode,
-				cfg.GitPR.ReviewFeedback.Enabled,
-				cfg.GitPR.ReviewFeedback.Mode,
-			)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "warning: operator rule evaluation failed for run %s: %v\n", snapshot.RunID, err)
+			decision := run.Decision
+			if decision == nil {
 				continue
 			}
 
-			var llmReply *operatorLLMResponse
-			if llmAdapter != nil {
-				reply, err := llmAdapter.Propose(ctx, operatorLLMRequest{
-					RunID:           snapshot.RunID,
-					RunStatus:       snapshot.RunStatus,
-					Tickets:         snapshot.Tickets,
-					HasGuidance:     snapshot.HasGuidance,
-					HasUnhealthy:    snapshot.HasUnhealthyAgents,
-					RuleIntent:      ruleDecision.Intent,
-					RuleReason:      ruleDecision.Reason,
-					UnhealthyAgents: snapshot.UnhealthyAgents,
-				})
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "warning: llm proposal failed for run %s: %v\n", snapshot.RunID, err)
-				} else {
-					llmReply = &reply
-				}
-			}
-
-			merged := mergeOperatorDecisions(effectiveLLMMode, ruleDecision, llmReply)
-			if merged.Intent == operatorIntentNoop {
-				delete(lastAlertByRun, snapshot.RunID)
-				continue
+			fmt.Printf("[%s] ALERT %s: %s\n", now.Format(time.RFC3339), decision.Event, decision.Reason)
+			fmt.Printf("  decision_source=%s llm_mode=%s intent=%s\n", decision.Source, result.LLMMode, decision.Intent)
+			if decision.LLMReply != nil {
+				fmt.Printf("  llm intent=%s confidence=%.2f reason=%s\n", decision.LLMReply.Intent, decision.LLMReply.Confidence, decision.LLMReply.Reason)
 			}
-			event, message := operatorEventMessage(snapshot, merged)
-			if lastAlertByRun[snapshot.RunID] == event {
-				continue
-			}
-			lastAlertByRun[snapshot.RunID] = event
-			fmt.Printf("[%s] ALERT %s: %s\n", now.Format(time.RFC3339), event, message)
-			fmt.Printf("  decision_source=%s llm_mode=%s intent=%s\n", merged.Source, effectiveLLMMode, merged.Intent)
-			if llmReply != nil {
-				fmt.Printf("  llm intent=%s confidence=%.2f reason=%s\n", llmReply.Intent, llmReply.Confidence, llmReply.Reason)
+			if decision.ActionError != "" {
+				fmt.Fprintf(os.Stderr, "warning: operator action failed for run %s intent=%s: %s\n", decision.RunID, decision.Intent, decision.ActionError)
+			} else if !decision.Executed && decision.Actionable() {
+				fmt.Printf("  action not executed (dry_run=%t llm_mode=%s)\n", dryRun, result.LLMMode)
 			}
 
-			shouldExecute := merged.Execute && !dryRun
-			if shouldExecute {
-				if err := executeOperatorAction(ctx, service, snapshot.RunID, merged.Intent, cfg.GitPR.ReviewFeedback.AutoDispatchCapPerInterval); err != nil {
-					fmt.Fprintf(os.Stderr, "warning: operator action failed for run %s intent=%s: %v\n", snapshot.RunID, merged.Intent, err)
-				} else if merged.Intent == operatorIntentAutoRestart {
-					current, err := service.GetOperatorRunState(snapshot.RunID)
-					if err != nil {
-						fmt.Fprintf(os.Stderr, "warning: read operator restart state for run %s failed: %v\n", snapshot.RunID, err)
-					} else {
-						state := model.OperatorRunState{RunID: snapshot.RunID}
-						if current != nil {
-							state = *current
-						}
-						nowCopy := now
-						cooldownUntil := nowCopy.Add(restartCooldown)
-						state.RestartAttempts++
-						state.LastRestartAt = &nowCopy
-						state.CooldownUntil = &cooldownUntil
-						state.UpdatedAt = nowCopy
-						if err := service.UpsertOperatorRunState(state); err != nil {
-							fmt.Fprintf(os.Stderr, "warning: persist operator restart state for run %s failed: %v\n", snapshot.RunID, err)
-						}
-					}
-				}
-			} else if merged.Intent == operatorIntentAutoStopStale || merged.Intent == operatorIntentAutoRestart || merged.Intent == operatorIntentCommitReady || merged.Intent == operatorIntentPRReady || merged.Intent == operatorIntentReviewFeedbackReady {
-				fmt.Printf("  action not executed (dry_run=%t llm_mode=%s)\n", dryRun, effectiveLLMMode)
-			}
-
-			if merged.Intent == operatorIntentEscalateGuidance || merged.Intent == operatorIntentEscalateBlocked {
-				if err := appendOperatorEscalationSummary(ctx, service, snapshot.RunID, merged.Intent, message); err != nil {
-					fmt.Fprintf(os.Stderr, "warning: escalation summary write failed for run %s: %v\n", snapshot.RunID, err)
-				}
-			}
 			if bell {
 				fmt.Print("\a")
 			}
-			if err := runWatchNotifyCommand(ctx, notifyCmd, event, message, snapshot); err != nil {
+			if err := runWatchNotifyCommand(ctx, notifyCmd, decision.Event, decision.Reason, snapshot); err != nil {
 				fmt.Fprintf(os.Stderr, "warning: notify command failed: %v\n", err)
 			}
 		}
@@ -1635,220 +2166,277 @@ func operatorCommand(args []string) error {
 	}
 }
 
-func buildOperatorRuleDecision(
-	ctx context.Context,
-	service *orchestrator.Service,
-	snapshot watchSnapshot,
-	runRecord model.RunRecord,
-	now time.Time,
-	staleAge time.Duration,
-	runtimeRecentWindow time.Duration,
-	unhealthyConfirmations int,
-	restartBudget int,
-	consecutiveUnhealthy int,
-	probe operatorSessionProbe,
-	gitPRMode string,
-	reviewFeedbackEnabled bool,
-	reviewFeedbackMode string,
-) (operatorRuleDecision, error) {
-	if snapshot.HasGuidance {
-		return operatorRuleDecision{
-			Intent:  operatorIntentEscalateGuidance,
-			Reason:  "operator input is required",
-			Execute: false,
-		}, nil
+// operatorCLIHolder names this process in operator leases so a serve daemon
+// and a terminal operator never act on the same run.
+func operatorCLIHolder() string {
+	host, err := os.Hostname()
+	if err != nil || strings.TrimSpace(host) == "" {
+		host = "unknown"
 	}
+	return fmt.Sprintf("cli:%s:%d", host, os.Getpid())
+}
 
-	isStale, staleReason := classifyStaleRunCandidate(snapshot, runRecord, now, staleAge)
-	if isStale {
-		verified, verifyReason, err := verifyStaleRuntimeEvidence(ctx, snapshot, now, runtimeRecentWindow, probe)
-		if err != nil {
-			return operatorRuleDecision{}, err
-		}
-		if verified {
-			return operatorRuleDecision{
-				Intent:  operatorIntentAutoStopStale,
-				Reason:  staleReason + "; " + verifyReason,
-				Execute: true,
-			}, nil
-		}
-		return operatorRuleDecision{
-			Intent:  operatorIntentNoop,
-			Reason:  staleReason + "; " + verifyReason,
-			Execute: false,
-		}, nil
-	}
-
-	if snapshot.HasUnhealthyAgents && strings.EqualFold(snapshot.RunStatus, string(model.RunStatusRunning)) {
-		if consecutiveUnhealthy < unhealthyConfirmations {
-			return operatorRuleDecision{
-				Intent:  operatorIntentNoop,
-				Reason:  fmt.Sprintf("awaiting corroboration (%d/%d unhealthy intervals)", consecutiveUnhealthy, unhealthyConfirmations),
-				Execute: false,
-			}, nil
-		}
-		state, err := service.GetOperatorRunState(snapshot.RunID)
-		if err != nil {
-			return operatorRuleDecision{}, err
-		}
-		if state != nil && state.RestartAttempts >= restartBudget {
-			return operatorRuleDecision{
-				Intent:  operatorIntentEscalateBlocked,
-				Reason:  fmt.Sprintf("restart budget exhausted (%d/%d)", state.RestartAttempts, restartBudget),
-				Execute: false,
-			}, nil
-		}
-		if state != nil && state.CooldownUntil != nil && now.Before(*state.CooldownUntil) {
-			return operatorRuleDecision{
-				Intent:  operatorIntentNoop,
-				Reason:  fmt.Sprintf("restart cooldown active until %s", state.CooldownUntil.Format(time.RFC3339)),
-				Execute: false,
-			}, nil
-		}
-		return operatorRuleDecision{
-			Intent:  operatorIntentAutoRestart,
-			Reason:  "unhealthy state corroborated and restart budget available",
-			Execute: true,
-		}, nil
-	}
-
-	mode := strings.TrimSpace(strings.ToLower(gitPRMode))
-	if mode == "" {
-		mode = "assist"
-	}
-	if strings.EqualFold(snapshot.RunStatus, string(model.RunStatusComplete)) && mode != "off" {
-		if snapshot.HasDirtyDiffs {
-			return operatorRuleDecision{
-				Intent:  operatorIntentCommitReady,
-				Reason:  "run completed with dirty repository diffs; commit workflow is ready",
-				Execute: mode == "auto",
-			}, nil
-		}
-		if snapshot.DraftPullRequests > 0 {
-			return operatorRuleDecision{
-				Intent:  operatorIntentPRReady,
-				Reason:  fmt.Sprintf("run has %d draft pull request record(s); PR creation is ready", snapshot.DraftPullRequests),
-				Execute: mode == "auto",
-			}, nil
-		}
-	}
-	if strings.EqualFold(snapshot.RunStatus, string(model.RunStatusComplete)) && reviewFeedbackEnabled && snapshot.QueuedReviewFeedback > 0 {
-		reviewMode := strings.TrimSpace(strings.ToLower(reviewFeedbackMode))
-		if reviewMode == "" {
-			reviewMode = "assist"
-		}
-		return operatorRuleDecision{
-			Intent:  operatorIntentReviewFeedbackReady,
-			Reason:  fmt.Sprintf("run has %d queued review feedback item(s); review dispatch is ready", snapshot.QueuedReviewFeedback),
-			Execute: reviewMode == "auto",
-		}, nil
-	}
-
-	return operatorRuleDecision{
-		Intent:  operatorIntentNoop,
-		Reason:  "no deterministic action required",
-		Execute: false,
-	}, nil
-}
-
-func operatorEventMessage(snapshot watchSnapshot, decision operatorMergedDecision) (string, string) {
-	switch decision.Intent {
-	case operatorIntentEscalateGuidance:
-		return "guidance_needed", decision.Reason
-	case operatorIntentAutoStopStale:
-		return "stale_candidate_verified", decision.Reason
-	case operatorIntentAutoRestart:
-		return "auto_restart_candidate", decision.Reason
-	case operatorIntentEscalateBlocked:
-		return "escalation_blocked", decision.Reason
-	case operatorIntentCommitReady:
-		return "commit_ready", decision.Reason
-	case operatorIntentPRReady:
-		return "pr_ready", decision.Reason
-	case operatorIntentReviewFeedbackReady:
-		return "review_feedback_ready", decision.Reason
+func operatorSubcommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: metawsm operator <history|replay|rules> [...]")
+	}
+	subcommand := strings.TrimSpace(strings.ToLower(args[0]))
+	rest := args[1:]
+	switch subcommand {
+	case "history":
+		return operatorHistoryCommand(rest)
+	case "replay":
+		return operatorReplayCommand(rest)
+	case "rules":
+		return operatorRulesCommand(rest)
 	default:
-		return "operator_noop", decision.Reason
+		return fmt.Errorf("unknown operator subcommand %q", subcommand)
 	}
 }
 
-func executeOperatorAction(ctx context.Context, service *orchestrator.Service, runID string, intent operatorIntent, reviewDispatchCap int) error {
-	switch intent {
-	case operatorIntentAutoRestart:
-		_, err := service.Restart(ctx, orchestrator.RestartOptions{RunID: runID, DryRun: false})
+func operatorRulesCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: metawsm operator rules test [...]")
+	}
+	subcommand := strings.TrimSpace(strings.ToLower(args[0]))
+	switch subcommand {
+	case "test":
+		return operatorRulesTestCommand(args[1:])
+	default:
+		return fmt.Errorf("unknown operator rules subcommand %q", subcommand)
+	}
+}
+
+func operatorRulesTestCommand(args []string) error {
+	fs := flag.NewFlagSet("operator rules test", flag.ContinueOnError)
+	var runID string
+	var ticket string
+	var dbPath string
+	var policyPath string
+	var decisionID int64
+	var asJSON bool
+	fs.StringVar(&runID, "run-id", "", "Run identifier (evaluate its live snapshot)")
+	fs.StringVar(&ticket, "ticket", "", "Ticket identifier (evaluate latest run for this ticket)")
+	fs.StringVar(&dbPath, "db", ".metawsm/metawsm.db", "Path to SQLite DB")
+	fs.StringVar(&policyPath, "policy", "", "Path to policy file to test (defaults to .metawsm/policy.json)")
+	fs.Int64Var(&decisionID, "decision-id", 0, "Evaluate the snapshot recorded with an audited decision (see operator history)")
+	fs.BoolVar(&asJSON, "json", false, "Print the evaluation as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	service, err := orchestrator.NewService(dbPath)
+	if err != nil {
+		return err
+	}
+	selectedRunID := ""
+	if decisionID <= 0 {
+		runID, ticket, err = requireRunSelector(runID, ticket)
+		if err != nil {
+			return err
+		}
+		selectedRunID, err = service.ResolveRunID(runID, ticket)
+		if err != nil {
+			return err
+		}
+	}
+	result, err := service.OperatorRulesTest(context.Background(), orchestrator.OperatorRulesTestOptions{
+		RunID:      selectedRunID,
+		DecisionID: decisionID,
+		PolicyPath: policyPath,
+	})
+	if err != nil {
 		return err
-	case operatorIntentAutoStopStale:
-		return service.Stop(ctx, runID)
-	case operatorIntentCommitReady:
-		_, err := service.Commit(ctx, orchestrator.CommitOptions{RunID: runID, Actor: "operator"})
+	}
+
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	}
+	source := "live snapshot"
+	if result.DecisionID > 0 {
+		source = fmt.Sprintf("decision #%d", result.DecisionID)
+	}
+	fmt.Printf("run=%s source=%s at=%s policy=%s\n", result.RunID, source, result.EvaluatedAt.Format(time.RFC3339), result.PolicyPath)
+	if len(result.Rules) == 0 {
+		fmt.Println("No operator rules defined; built-in rules apply.")
+	}
+	for i, rule := range result.Rules {
+		status := "no match"
+		switch {
+		case rule.RateLimited:
+			status = fmt.Sprintf("matched, rate limited (%d fired in window)", rule.Firings)
+		case rule.Matched:
+			status = "matched"
+		}
+		fmt.Printf("%d. %s -> %s: %s\n", i+1, rule.Name, rule.Intent, status)
+		for _, unmet := range rule.Unmet {
+			fmt.Printf("     - %s\n", unmet)
+		}
+	}
+	decidedBy := "built-in rules"
+	if result.MatchedRule != "" {
+		decidedBy = "rule " + result.MatchedRule
+	}
+	fmt.Printf("Decision (%s): intent=%s execute=%t reason=%s\n", decidedBy, result.Intent, result.Execute, result.Reason)
+	return nil
+}
+
+func operatorHistoryCommand(args []string) error {
+	fs := flag.NewFlagSet("operator history", flag.ContinueOnError)
+	var runID string
+	var ticket string
+	var dbPath string
+	var limit int
+	var asJSON bool
+	fs.StringVar(&runID, "run-id", "", "Run identifier (defaults to all runs)")
+	fs.StringVar(&ticket, "ticket", "", "Ticket identifier (history for latest run of this ticket)")
+	fs.StringVar(&dbPath, "db", ".metawsm/metawsm.db", "Path to SQLite DB")
+	fs.IntVar(&limit, "limit", 20, "Maximum decisions to list")
+	fs.BoolVar(&asJSON, "json", false, "Print decisions as JSON, including recorded inputs")
+	if err := fs.Parse(args); err != nil {
 		return err
-	case operatorIntentPRReady:
-		_, err := service.OpenPullRequests(ctx, orchestrator.PullRequestOptions{RunID: runID, Actor: "operator"})
+	}
+	if limit <= 0 {
+		return fmt.Errorf("--limit must be > 0")
+	}
+
+	service, err := orchestrator.NewService(dbPath)
+	if err != nil {
 		return err
-	case operatorIntentReviewFeedbackReady:
-		_, err := service.SyncReviewFeedback(ctx, orchestrator.ReviewFeedbackSyncOptions{
-			RunID:    runID,
-			MaxItems: reviewDispatchCap,
-			DryRun:   false,
-		})
+	}
+	selectedRunID := ""
+	if strings.TrimSpace(runID) != "" || strings.TrimSpace(ticket) != "" {
+		selectedRunID, err = service.ResolveRunID(runID, ticket)
 		if err != nil {
 			return err
 		}
-		_, err = service.DispatchQueuedReviewFeedback(ctx, orchestrator.ReviewFeedbackDispatchOptions{
-			RunID:    runID,
-			MaxItems: reviewDispatchCap,
-			DryRun:   false,
-		})
-		return err
-	default:
-		return nil
 	}
-}
-
-func appendOperatorEscalationSummary(ctx context.Context, service *orchestrator.Service, runID string, intent operatorIntent, summary string) error {
-	runCtx, err := service.OperatorRunContext(runID)
+	records, err := service.OperatorHistory(selectedRunID, limit)
 	if err != nil {
 		return err
 	}
-	if len(runCtx.Tickets) == 0 {
+
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(map[string]any{"decisions": records})
+	}
+	if len(records) == 0 {
+		fmt.Println("No operator decisions recorded.")
 		return nil
 	}
-	workspaceSet := map[string]struct{}{}
-	for _, agent := range runCtx.Agents {
-		workspaceName := strings.TrimSpace(agent.WorkspaceName)
-		if workspaceName == "" {
-			continue
+	for _, record := range records {
+		fmt.Printf("#%d %s run=%s event=%s intent=%s source=%s executed=%t\n",
+			record.ID,
+			record.CreatedAt.Format(time.RFC3339),
+			record.RunID,
+			emptyValue(record.Event, "-"),
+			record.Intent,
+			record.Source,
+			record.Executed,
+		)
+		fmt.Printf("  holder=%s llm_mode=%s dry_run=%t\n", emptyValue(record.Holder, "-"), emptyValue(record.LLMMode, "-"), record.DryRun)
+		if record.RepeatedCount > 0 {
+			fmt.Printf("  repeated=%d last_seen=%s\n", record.RepeatedCount, record.LastSeenAt.Format(time.RFC3339))
+		}
+		fmt.Printf("  rule intent=%s execute=%t reason=%s\n", record.RuleIntent, record.RuleExecute, record.RuleReason)
+		if record.LLMError != "" {
+			fmt.Printf("  llm error=%s\n", record.LLMError)
+		} else if len(record.LLMReply) > 0 {
+			fmt.Printf("  llm reply=%s\n", string(record.LLMReply))
+		}
+		fmt.Printf("  reason=%s\n", record.Reason)
+		if record.ActionError != "" {
+			fmt.Printf("  action_error=%s\n", record.ActionError)
 		}
-		workspaceSet[workspaceName] = struct{}{}
 	}
-	if len(workspaceSet) == 0 {
-		return nil
+	return nil
+}
+
+func operatorReplayCommand(args []string) error {
+	fs := flag.NewFlagSet("operator replay", flag.ContinueOnError)
+	var runID string
+	var ticket string
+	var dbPath string
+	var policyPath string
+	var decisionID int64
+	var limit int
+	var asJSON bool
+	var failOnChange bool
+	fs.StringVar(&runID, "run-id", "", "Run identifier (defaults to all runs)")
+	fs.StringVar(&ticket, "ticket", "", "Ticket identifier (replay latest run of this ticket)")
+	fs.StringVar(&dbPath, "db", ".metawsm/metawsm.db", "Path to SQLite DB")
+	fs.StringVar(&policyPath, "policy", "", "Path to policy file to replay against (defaults to .metawsm/policy.json)")
+	fs.Int64Var(&decisionID, "id", 0, "Replay a single recorded decision")
+	fs.IntVar(&limit, "limit", 50, "Maximum recent decisions to replay")
+	fs.BoolVar(&asJSON, "json", false, "Print the replay report as JSON")
+	fs.BoolVar(&failOnChange, "fail-on-change", false, "Exit non-zero when any replayed decision differs or fails")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if limit <= 0 {
+		return fmt.Errorf("--limit must be > 0")
 	}
 
-	for workspaceName := range workspaceSet {
-		workspacePath, err := operatorResolveWorkspacePath(workspaceName)
+	service, err := orchestrator.NewService(dbPath)
+	if err != nil {
+		return err
+	}
+	selectedRunID := ""
+	if decisionID <= 0 && (strings.TrimSpace(runID) != "" || strings.TrimSpace(ticket) != "") {
+		selectedRunID, err = service.ResolveRunID(runID, ticket)
 		if err != nil {
 			return err
 		}
-		docRepoPath, err := operatorResolveDocRepoPath(workspacePath, runCtx.DocHomeRepo, runCtx.Repos)
-		if err != nil {
+	}
+	result, err := service.OperatorReplay(context.Background(), orchestrator.OperatorReplayOptions{
+		RunID:      selectedRunID,
+		DecisionID: decisionID,
+		Limit:      limit,
+		PolicyPath: policyPath,
+	})
+	if err != nil {
+		return err
+	}
+
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(result); err != nil {
 			return err
 		}
-		for _, ticket := range runCtx.Tickets {
-			relativePath, err := operatorResolveTicketRelativePath(ctx, ticket)
-			if err != nil {
-				return err
+	} else {
+		for _, entry := range result.Entries {
+			status := "same"
+			switch {
+			case entry.Error != "":
+				status = "FAILED"
+			case entry.Changed:
+				status = "CHANGED"
 			}
-			changelogPath := filepath.Join(docRepoPath, "ttmp", relativePath, "changelog.md")
-			entry := "\n\n## " + time.Now().Format(time.RFC3339) + "\n\n" +
-				"- Operator escalation for run `" + runID + "`\n" +
-				"- Intent: `" + string(intent) + "`\n" +
-				"- Summary: " + summary + "\n" +
-				"- Requested decision: review `metawsm status --run-id " + runID + "` and provide guidance.\n"
-			if err := appendTextFile(changelogPath, entry); err != nil {
-				return err
+			fmt.Printf("#%d %s run=%s %s recorded=%s(execute=%t)",
+				entry.DecisionID,
+				entry.CreatedAt.Format(time.RFC3339),
+				entry.RunID,
+				status,
+				entry.RecordedIntent,
+				entry.RecordedExecute,
+			)
+			if entry.Error != "" {
+				fmt.Printf(" error=%s\n", entry.Error)
+				continue
+			}
+			fmt.Printf(" replayed=%s(execute=%t) source=%s\n", entry.Intent, entry.Execute, entry.Source)
+			if entry.Changed {
+				fmt.Printf("  reason=%s\n", entry.Reason)
 			}
 		}
+		fmt.Printf("Replayed %d decision(s) against %s: changed=%d failed=%d\n", result.Total, result.PolicyPath, result.Changed, result.Failed)
+	}
+	if failOnChange && (result.Changed > 0 || result.Failed > 0) {
+		return fmt.Errorf("operator replay: %d changed, %d failed", result.Changed, result.Failed)
 	}
 	return nil
 }
@@ -1875,26 +2463,6 @@ func operatorResolveWorkspacePath(workspaceName string) (string, error) {
 	return payload.Path, nil
 }
 
-func operatorResolveDocRepoPath(workspacePath string, docHomeRepo string, repos []string) (string, error) {
-	docHomeRepo = strings.TrimSpace(docHomeRepo)
-	if docHomeRepo == "" {
-		if len(repos) > 0 {
-			docHomeRepo = strings.TrimSpace(repos[0])
-		}
-	}
-	if docHomeRepo == "" {
-		return workspacePath, nil
-	}
-	candidate := filepath.Join(workspacePath, docHomeRepo)
-	if info, err := os.Stat(candidate); err == nil && info.IsDir() {
-		return candidate, nil
-	}
-	if len(repos) == 1 && operatorIsGitRepo(workspacePath) {
-		return workspacePath, nil
-	}
-	return "", fmt.Errorf("doc repo path not found in workspace: %s", candidate)
-}
-
 func operatorIsGitRepo(path string) bool {
 	info, err := os.Stat(filepath.Join(path, ".git"))
 	if err != nil {
@@ -1903,175 +2471,6 @@ func operatorIsGitRepo(path string) bool {
 	return info.IsDir() || info.Mode().IsRegular()
 }
 
-func operatorResolveTicketRelativePath(ctx context.Context, ticket string) (string, error) {
-	cmd := exec.CommandContext(ctx, "docmgr", "ticket", "list", "--ticket", ticket)
-	out, err := cmd.CombinedOutput()
-	if err != nil {
-		return "", fmt.Errorf("docmgr ticket list --ticket %s failed: %w: %s", ticket, err, strings.TrimSpace(string(out)))
-	}
-	text := string(out)
-	docsRootMatch := operatorDocmgrDocsRootRegex.FindStringSubmatch(text)
-	ticketPathMatch := operatorDocmgrTicketPathRegex.FindStringSubmatch(text)
-	if len(docsRootMatch) < 2 || len(ticketPathMatch) < 2 {
-		return "", fmt.Errorf("unable to parse ticket path from docmgr output")
-	}
-	docsRoot := filepath.Clean(strings.TrimSpace(docsRootMatch[1]))
-	relativePath := filepath.Clean(filepath.FromSlash(strings.TrimSpace(ticketPathMatch[1])))
-	if relativePath == "." || relativePath == "" || relativePath == ".." || strings.HasPrefix(relativePath, ".."+string(os.PathSeparator)) {
-		return "", fmt.Errorf("unsafe ticket relative path parsed from docmgr output: %s", relativePath)
-	}
-	if _, err := os.Stat(filepath.Join(docsRoot, relativePath)); err != nil {
-		return "", fmt.Errorf("ticket path %s: %w", filepath.Join(docsRoot, relativePath), err)
-	}
-	return relativePath, nil
-}
-
-func appendTextFile(path string, content string) error {
-	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
-		return err
-	}
-	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-	_, err = f.WriteString(content)
-	return err
-}
-
-func resolveOperatorLLMMode(flagValue string, policyValue string) (string, error) {
-	mode := strings.TrimSpace(strings.ToLower(flagValue))
-	if mode == "" {
-		mode = strings.TrimSpace(strings.ToLower(policyValue))
-	}
-	if mode == "" {
-		mode = "assist"
-	}
-	switch mode {
-	case "off", "assist", "auto":
-		return mode, nil
-	default:
-		return "", fmt.Errorf("invalid --llm-mode %q (expected off|assist|auto)", flagValue)
-	}
-}
-
-func classifyStaleRunCandidate(snapshot watchSnapshot, run model.RunRecord, now time.Time, staleAge time.Duration) (bool, string) {
-	if staleAge <= 0 {
-		return false, ""
-	}
-	if snapshot.HasGuidance {
-		return false, ""
-	}
-	if !snapshot.HasUnhealthyAgents {
-		return false, ""
-	}
-	age := now.Sub(run.UpdatedAt)
-	if age < staleAge {
-		return false, ""
-	}
-	return true, fmt.Sprintf("run appears stale (updated %s ago, threshold=%s)", age.Truncate(time.Second), staleAge)
-}
-
-func verifyStaleRuntimeEvidence(ctx context.Context, snapshot watchSnapshot, now time.Time, recentWindow time.Duration, probe operatorSessionProbe) (bool, string, error) {
-	if probe == nil {
-		return false, "runtime probe is unavailable", nil
-	}
-	if recentWindow <= 0 {
-		recentWindow = 5 * time.Minute
-	}
-	if len(snapshot.UnhealthyAgents) == 0 {
-		return false, "no unhealthy agents available to verify", nil
-	}
-
-	evidenceCount := 0
-	for _, issue := range snapshot.UnhealthyAgents {
-		session := strings.TrimSpace(issue.Session)
-		if session == "" {
-			continue
-		}
-		evidenceCount++
-		evidence, err := probe(ctx, session)
-		if err != nil {
-			return false, "", err
-		}
-		if evidence.HasSession {
-			if evidence.LastActivity != nil && now.Sub(*evidence.LastActivity) <= recentWindow {
-				return false, fmt.Sprintf("session %s has recent activity within %s", session, recentWindow), nil
-			}
-			if evidence.ExitCode == nil || *evidence.ExitCode == 0 {
-				return false, fmt.Sprintf("session %s still appears running", session), nil
-			}
-		}
-	}
-	if evidenceCount == 0 {
-		return false, "no agent sessions available for runtime verification", nil
-	}
-	return true, "no active tmux sessions or recent activity detected", nil
-}
-
-func probeOperatorSessionEvidence(ctx context.Context, session string) (operatorSessionEvidence, error) {
-	evidence := operatorSessionEvidence{Session: session}
-	if !operatorTmuxHasSession(ctx, session) {
-		return evidence, nil
-	}
-	evidence.HasSession = true
-	if epoch := operatorFetchSessionActivity(ctx, session); epoch > 0 {
-		t := time.Unix(epoch, 0)
-		evidence.LastActivity = &t
-	}
-	if exitCode, ok := operatorReadAgentExitCode(ctx, session); ok {
-		exitCopy := exitCode
-		evidence.ExitCode = &exitCopy
-	}
-	return evidence, nil
-}
-
-func operatorTmuxHasSession(ctx context.Context, session string) bool {
-	cmd := exec.CommandContext(ctx, "zsh", "-lc", fmt.Sprintf("tmux has-session -t %s", cmdShellQuote(session)))
-	var stderr strings.Builder
-	cmd.Stderr = &stderr
-	return cmd.Run() == nil
-}
-
-func operatorFetchSessionActivity(ctx context.Context, session string) int64 {
-	cmd := exec.CommandContext(ctx, "zsh", "-lc", fmt.Sprintf("tmux display-message -p -t %s '#{session_activity}'", cmdShellQuote(session)))
-	out, err := cmd.Output()
-	if err != nil {
-		return 0
-	}
-	value := strings.TrimSpace(string(out))
-	epoch, err := strconv.ParseInt(value, 10, 64)
-	if err != nil {
-		return 0
-	}
-	return epoch
-}
-
-func operatorReadAgentExitCode(ctx context.Context, session string) (int, bool) {
-	cmd := exec.CommandContext(ctx, "zsh", "-lc", fmt.Sprintf("tmux capture-pane -p -t %s:0 | tail -n 200", cmdShellQuote(session)))
-	out, err := cmd.Output()
-	if err != nil {
-		return 0, false
-	}
-	return operatorParseAgentExitCode(string(out))
-}
-
-func operatorParseAgentExitCode(output string) (int, bool) {
-	matches := operatorAgentExitRegex.FindAllStringSubmatch(output, -1)
-	if len(matches) == 0 {
-		return 0, false
-	}
-	last := matches[len(matches)-1]
-	if len(last) < 2 {
-		return 0, false
-	}
-	code, err := strconv.Atoi(strings.TrimSpace(last[1]))
-	if err != nil {
-		return 0, false
-	}
-	return code, true
-}
-
 func resolveWatchMode(runID string, ticket string, all bool) (watchMode, error) {
 	hasSelector := strings.TrimSpace(runID) != "" || strings.TrimSpace(ticket) != ""
 	if all && hasSelector {
@@ -2084,21 +2483,31 @@ func resolveWatchMode(runID string, ticket string, all bool) (watchMode, error)
 }
 
 func loadWatchSnapshot(ctx context.Context, service *orchestrator.Service, runID string, ticketFallback string) (watchSnapshot, error) {
-	snapshotData, err := service.RunSnapshot(ctx, runID)
+	report, err := service.StatusReport(ctx, runID)
 	if err != nil {
 		return watchSnapshot{}, err
 	}
+	return watchSnapshotFromReport(report, runID, ticketFallback), nil
+}
+
+// watchSnapshotFromReport reduces a status report to what watch and operator
+// decide on.
+func watchSnapshotFromReport(report orchestrator.RunStatusReport, runID string, ticketFallback string) watchSnapshot {
+	return watchSnapshotFromRunSnapshot(report.Snapshot(), runID, ticketFallback)
+}
+
+func watchSnapshotFromRunSnapshot(condensed orchestrator.RunSnapshot, runID string, ticketFallback string) watchSnapshot {
 	snapshot := watchSnapshot{
-		RunID:                strings.TrimSpace(snapshotData.RunID),
-		RunStatus:            string(snapshotData.Status),
-		Tickets:              strings.Join(snapshotData.Tickets, ", "),
-		HasGuidance:          len(snapshotData.PendingGuidance) > 0,
-		HasUnhealthyAgents:   len(snapshotData.UnhealthyAgents) > 0,
-		HasDirtyDiffs:        snapshotData.HasDirtyDiffs,
-		DraftPullRequests:    snapshotData.DraftPullRequests,
-		OpenPullRequests:     snapshotData.OpenPullRequests,
-		QueuedReviewFeedback: snapshotData.QueuedReviewFeedback,
-		NewReviewFeedback:    snapshotData.NewReviewFeedback,
+		RunID:                strings.TrimSpace(condensed.RunID),
+		RunStatus:            string(condensed.Status),
+		Tickets:              strings.Join(condensed.Tickets, ", "),
+		HasGuidance:          len(condensed.PendingGuidance) > 0 || condensed.Status == model.RunStatusAwaitingGuidance,
+		HasUnhealthyAgents:   len(condensed.UnhealthyAgents) > 0,
+		HasDirtyDiffs:        condensed.HasDirtyDiffs,
+		DraftPullRequests:    condensed.DraftPullRequests,
+		OpenPullRequests:     condensed.OpenPullRequests,
+		QueuedReviewFeedback: condensed.QueuedReviewFeedback,
+		NewReviewFeedback:    condensed.NewReviewFeedback,
 	}
 	if snapshot.RunID == "" {
 		snapshot.RunID = strings.TrimSpace(runID)
@@ -2106,7 +2515,7 @@ func loadWatchSnapshot(ctx context.Context, service *orchestrator.Service, runID
 	if snapshot.Tickets == "" {
 		snapshot.Tickets = strings.TrimSpace(ticketFallback)
 	}
-	for _, item := range snapshotData.PendingGuidance {
+	for _, item := range condensed.PendingGuidance {
 		snapshot.GuidanceItems = append(snapshot.GuidanceItems, fmt.Sprintf(
 			"forum control thread=%s agent=%s workspace=%s question=%s",
 			item.ThreadID,
@@ -2115,7 +2524,7 @@ func loadWatchSnapshot(ctx context.Context, service *orchestrator.Service, runID
 			item.Question,
 		))
 	}
-	for _, agent := range snapshotData.UnhealthyAgents {
+	for _, agent := range condensed.UnhealthyAgents {
 		issue := watchAgentIssue{
 			Agent:        strings.TrimSpace(agent.AgentName) + "@" + strings.TrimSpace(agent.WorkspaceName),
 			Session:      strings.TrimSpace(agent.SessionName),
@@ -2129,7 +2538,7 @@ func loadWatchSnapshot(ctx context.Context, service *orchestrator.Service, runID
 		issue.Reason = describeUnhealthyReason(issue)
 		snapshot.UnhealthyAgents = append(snapshot.UnhealthyAgents, issue)
 	}
-	return snapshot, nil
+	return snapshot
 }
 
 func loadWatchSnapshotsAll(ctx context.Context, service *orchestrator.Service, trackedRuns map[string]struct{}) ([]watchSnapshot, error) {
@@ -2171,152 +2580,6 @@ func loadWatchSnapshotsAll(ctx context.Context, service *orchestrator.Service, t
 	return snapshots, nil
 }
 
-func parseWatchSnapshot(statusText string) watchSnapshot {
-	snapshot := watchSnapshot{}
-	inAgents := false
-	inGuidance := false
-	inDiffs := false
-	inPullRequests := false
-	inReviewFeedback := false
-	scanner := bufio.NewScanner(strings.NewReader(statusText))
-	for scanner.Scan() {
-		line := scanner.Text()
-		switch {
-		case strings.HasPrefix(line, "Run:"):
-			snapshot.RunID = strings.TrimSpace(strings.TrimPrefix(line, "Run:"))
-			inAgents = false
-			inGuidance = false
-			inDiffs = false
-			inPullRequests = false
-			inReviewFeedback = false
-		case strings.HasPrefix(line, "Status:"):
-			snapshot.RunStatus = strings.TrimSpace(strings.TrimPrefix(line, "Status:"))
-			inAgents = false
-			inGuidance = false
-			inDiffs = false
-			inPullRequests = false
-			inReviewFeedback = false
-		case strings.HasPrefix(line, "Tickets:"):
-			snapshot.Tickets = strings.TrimSpace(strings.TrimPrefix(line, "Tickets:"))
-			inAgents = false
-			inGuidance = false
-			inDiffs = false
-			inPullRequests = false
-			inReviewFeedback = false
-		case strings.HasPrefix(line, "Guidance:"):
-			snapshot.HasGuidance = true
-			inAgents = false
-			inGuidance = true
-			inDiffs = false
-			inPullRequests = false
-			inReviewFeedback = false
-		case strings.HasPrefix(line, "Diffs:"):
-			inAgents = false
-			inGuidance = false
-			inDiffs = true
-			inPullRequests = false
-			inReviewFeedback = false
-		case strings.HasPrefix(line, "Pull Requests:"):
-			inAgents = false
-			inGuidance = false
-			inDiffs = false
-			inPullRequests = true
-			inReviewFeedback = false
-		case strings.HasPrefix(line, "Review Feedback:"):
-			inAgents = false
-			inGuidance = false
-			inDiffs = false
-			inPullRequests = false
-			inReviewFeedback = true
-		case strings.HasPrefix(line, "Agents:"):
-			inAgents = true
-			inGuidance = false
-			inDiffs = false
-			inPullRequests = false
-			inReviewFeedback = false
-		default:
-			if inGuidance {
-				if !strings.HasPrefix(line, "  ") {
-					inGuidance = false
-				} else {
-					item := strings.TrimSpace(strings.TrimPrefix(line, "- "))
-					if item != "" {
-						snapshot.GuidanceItems = append(snapshot.GuidanceItems, item)
-					}
-					continue
-				}
-			}
-			if inDiffs {
-				if !strings.HasPrefix(line, "  ") {
-					inDiffs = false
-				} else {
-					if strings.Contains(strings.TrimSpace(line), " dirty files=") {
-						snapshot.HasDirtyDiffs = true
-					}
-					continue
-				}
-			}
-			if inPullRequests {
-				if !strings.HasPrefix(line, "  ") {
-					inPullRequests = false
-				} else {
-					state := strings.TrimSpace(strings.ToLower(parseWatchField(line, "state")))
-					switch state {
-					case "draft":
-						snapshot.DraftPullRequests++
-					case "open":
-						snapshot.OpenPullRequests++
-					}
-					continue
-				}
-			}
-			if inReviewFeedback {
-				if !strings.HasPrefix(line, "  ") {
-					inReviewFeedback = false
-				} else {
-					status := strings.TrimSpace(strings.ToLower(parseWatchField(line, "status")))
-					countText := strings.TrimSpace(parseWatchField(line, "count"))
-					count, _ := strconv.Atoi(countText)
-					switch status {
-					case "queued":
-						snapshot.QueuedReviewFeedback += count
-					case "new":
-						snapshot.NewReviewFeedback += count
-					}
-					continue
-				}
-			}
-			if !inAgents {
-				continue
-			}
-			if !strings.HasPrefix(line, "  ") {
-				inAgents = false
-				continue
-			}
-			agentIssue, unhealthy := parseWatchAgentLine(line)
-			if unhealthy {
-				snapshot.HasUnhealthyAgents = true
-				snapshot.UnhealthyAgents = append(snapshot.UnhealthyAgents, agentIssue)
-			}
-		}
-	}
-	if strings.EqualFold(snapshot.RunStatus, string(model.RunStatusAwaitingGuidance)) {
-		snapshot.HasGuidance = true
-	}
-	return snapshot
-}
-
-func parseWatchField(line string, key string) string {
-	fields := strings.Fields(strings.TrimSpace(line))
-	prefix := strings.TrimSpace(key) + "="
-	for _, field := range fields {
-		if strings.HasPrefix(field, prefix) {
-			return strings.TrimPrefix(field, prefix)
-		}
-	}
-	return ""
-}
-
 func classifyWatchEvent(snapshot watchSnapshot) (event string, message string, terminal bool) {
 	status := strings.TrimSpace(snapshot.RunStatus)
 	switch status {
@@ -2376,36 +2639,6 @@ func isWatchStopError(ctx context.Context, err error) bool {
 	return strings.Contains(lower, "signal: interrupt") || strings.Contains(lower, "context canceled")
 }
 
-func parseWatchAgentLine(line string) (watchAgentIssue, bool) {
-	issue := watchAgentIssue{Agent: strings.TrimSpace(line)}
-	trimmed := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "- "))
-	if trimmed == "" {
-		return issue, false
-	}
-	fields := strings.Fields(trimmed)
-	if len(fields) > 0 {
-		issue.Agent = fields[0]
-	}
-	values := map[string]string{}
-	for _, field := range fields[1:] {
-		parts := strings.SplitN(field, "=", 2)
-		if len(parts) != 2 {
-			continue
-		}
-		values[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
-	}
-	issue.Status = values["status"]
-	issue.Health = values["health"]
-	issue.Session = values["session"]
-	issue.LastActivity = values["last_activity"]
-	issue.LastProgress = values["last_progress"]
-	issue.ActivityAge = values["activity_age"]
-	issue.ProgressAge = values["progress_age"]
-	issue.Reason = describeUnhealthyReason(issue)
-	unhealthy := issue.Health == "dead" || issue.Health == "stalled" || issue.Status == "failed" || issue.Status == "dead"
-	return issue, unhealthy
-}
-
 func describeUnhealthyReason(issue watchAgentIssue) string {
 	switch {
 	case issue.Status == "failed":
@@ -3146,24 +3379,35 @@ func serveCommand(args []string) error {
 	var workerInterval time.Duration
 	var workerBatchSize int
 	var workerLogPeriod time.Duration
+	var escalationInterval time.Duration
+	var webhookPollInterval time.Duration
 	var shutdownTimeout time.Duration
 	fs.StringVar(&addr, "addr", ":3001", "HTTP listen address")
 	fs.StringVar(&dbPath, "db", ".metawsm/metawsm.db", "Path to SQLite DB")
 	fs.DurationVar(&workerInterval, "worker-interval", 500*time.Millisecond, "Forum worker loop interval")
 	fs.IntVar(&workerBatchSize, "worker-batch-size", 100, "Forum worker ProcessOnce batch size")
 	fs.DurationVar(&workerLogPeriod, "worker-log-period", 15*time.Second, "Forum worker summary log period")
+	fs.DurationVar(&escalationInterval, "escalation-interval", time.Minute, "Forum SLA escalation check interval")
+	fs.DurationVar(&webhookPollInterval, "webhook-poll-interval", 15*time.Second, "Run status poll interval for webhook notifications")
 	fs.DurationVar(&shutdownTimeout, "shutdown-timeout", 5*time.Second, "Graceful shutdown timeout")
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
 
+	authMode, err := serveAuthMode()
+	if err != nil {
+		return err
+	}
 	runtime, err := server.NewRuntime(server.Options{
-		Addr:            addr,
-		DBPath:          dbPath,
-		WorkerInterval:  workerInterval,
-		WorkerBatchSize: workerBatchSize,
-		WorkerLogPeriod: workerLogPeriod,
-		ShutdownTimeout: shutdownTimeout,
+		Addr:                addr,
+		DBPath:              dbPath,
+		WorkerInterval:      workerInterval,
+		WorkerBatchSize:     workerBatchSize,
+		WorkerLogPeriod:     workerLogPeriod,
+		EscalationInterval:  escalationInterval,
+		WebhookPollInterval: webhookPollInterval,
+		ShutdownTimeout:     shutdownTimeout,
+		AuthMode:            authMode,
 	})
 	if err != nil {
 		return err
@@ -3410,28 +3654,171 @@ func cmdShellQuote(value string) string {
 	return "'" + strings.ReplaceAll(value, "'", "'\"'\"'") + "'"
 }
 
+func templateCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: metawsm template <list|show|validate> [...]")
+	}
+	subcommand := strings.TrimSpace(strings.ToLower(args[0]))
+	rest := args[1:]
+	switch subcommand {
+	case "list":
+		return templateListCommand(rest)
+	case "show":
+		return templateShowCommand(rest)
+	case "validate":
+		return templateValidateCommand(rest)
+	default:
+		return fmt.Errorf("unknown template subcommand %q", subcommand)
+	}
+}
+
+func templateListCommand(args []string) error {
+	fs := flag.NewFlagSet("template list", flag.ContinueOnError)
+	var policyPath string
+	fs.StringVar(&policyPath, "policy", "", "Path to policy file (defaults to .metawsm/policy.json)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	cfg, _, err := policy.Load(policyPath)
+	if err != nil {
+		return err
+	}
+	if len(cfg.RunTemplates) == 0 {
+		fmt.Println("No run templates defined.")
+		return nil
+	}
+	for _, template := range cfg.RunTemplates {
+		source := "policy"
+		if template.Source != "" {
+			source = template.Source
+		}
+		fmt.Printf("%s source=%s\n", template.Name, source)
+		if strings.TrimSpace(template.Description) != "" {
+			fmt.Printf("  %s\n", template.Description)
+		}
+		if variables := policy.RunTemplateVariables(template); len(variables) > 0 {
+			fmt.Printf("  variables: %s\n", strings.Join(variables, ","))
+		}
+	}
+	return nil
+}
+
+func templateShowCommand(args []string) error {
+	fs := flag.NewFlagSet("template show", flag.ContinueOnError)
+	var policyPath string
+	var name string
+	fs.StringVar(&policyPath, "policy", "", "Path to policy file (defaults to .metawsm/policy.json)")
+	fs.StringVar(&name, "name", "", "Run template name")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if strings.TrimSpace(name) == "" && fs.NArg() > 0 {
+		name = fs.Arg(0)
+	}
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("--name is required")
+	}
+	cfg, _, err := policy.Load(policyPath)
+	if err != nil {
+		return err
+	}
+	template, ok := policy.FindRunTemplate(cfg, name)
+	if !ok {
+		return fmt.Errorf("run template %q not found", strings.TrimSpace(name))
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(template)
+}
+
+// templateValidateCommand loads the policy, which validates every run
+// template. With --name it also expands that template for --ticket and the
+// given variables, so missing required variables are reported too.
+func templateValidateCommand(args []string) error {
+	fs := flag.NewFlagSet("template validate", flag.ContinueOnError)
+	var policyPath string
+	var name string
+	var tickets multiValueFlag
+	var templateVars multiValueFlag
+	fs.StringVar(&policyPath, "policy", "", "Path to policy file (defaults to .metawsm/policy.json)")
+	fs.StringVar(&name, "name", "", "Run template to expand (optional)")
+	fs.Var(&tickets, "ticket", "Ticket used for expansion (repeatable, or comma-separated)")
+	fs.Var(&templateVars, "var", "Run template variable as name=value (repeatable)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	cfg, finalPath, err := policy.Load(policyPath)
+	if err != nil {
+		return err
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		fmt.Printf("%d run template(s) valid in %s\n", len(cfg.RunTemplates), finalPath)
+		return nil
+	}
+	template, ok := policy.FindRunTemplate(cfg, name)
+	if !ok {
+		return fmt.Errorf("run template %q not found", name)
+	}
+	vars, err := parseTemplateVars(templateVars)
+	if err != nil {
+		return err
+	}
+	ticketList := normalizeInputTokens(tickets)
+	if len(ticketList) == 0 {
+		ticketList = []string{"TICKET"}
+	}
+	vars[policy.RunTemplateVarTicket] = ticketList[0]
+	vars[policy.RunTemplateVarTickets] = strings.Join(ticketList, ",")
+	vars[policy.RunTemplateVarRunID] = "run-template-validate"
+	if _, err := policy.ExpandRunTemplate(template, vars); err != nil {
+		return err
+	}
+	fmt.Printf("Run template %s is valid.\n", name)
+	return nil
+}
+
+// parseTemplateVars turns repeated --var name=value flags into a map.
+func parseTemplateVars(values []string) (map[string]string, error) {
+	vars := map[string]string{}
+	for _, value := range values {
+		parts := strings.SplitN(value, "=", 2)
+		name := strings.TrimSpace(parts[0])
+		if len(parts) != 2 || name == "" {
+			return nil, fmt.Errorf("--var must be name=value, got %q", value)
+		}
+		vars[name] = parts[1]
+	}
+	return vars, nil
+}
+
 var usageCommandLines = []string{
-	"metawsm run --ticket T1 --ticket T2 --repos repo1,repo2 [--doc-home-repo repo1] [--doc-authority-mode workspace_active] [--doc-seed-mode copy_from_repo_on_start] [--agent planner --agent coder] [--base-branch main]",
-	"metawsm bootstrap --ticket T1 --repos repo1,repo2 [--doc-home-repo repo1] [--doc-authority-mode workspace_active] [--doc-seed-mode copy_from_repo_on_start] [--agent planner] [--base-branch main]",
-	"metawsm status [--run-id RUN_ID | --ticket T1]",
+	"metawsm run --ticket T1 --ticket T2 --repos repo1,repo2 [--doc-home-repo repo1] [--doc-authority-mode workspace_active] [--doc-seed-mode copy_from_repo_on_start] [--agent planner --agent coder] [--base-branch main] [--template NAME --var k=v] [--server URL]",
+	"metawsm bootstrap --ticket T1 --repos repo1,repo2 [--doc-home-repo repo1] [--doc-authority-mode workspace_active] [--doc-seed-mode copy_from_repo_on_start] [--agent planner] [--base-branch main] [--server URL]",
+	"metawsm status [--run-id RUN_ID | --ticket T1] [--output text|json|yaml] [--server URL]",
+	"metawsm logs [--run-id RUN_ID | --ticket T1] --agent NAME [--workspace WS] [--lines 200] [--follow] [--server URL]",
 	"metawsm auth check [--run-id RUN_ID | --ticket T1] [--policy PATH]",
+	"metawsm auth token <create --role viewer|human|operator|agent --actor NAME [--ttl 720h]|list|revoke --id TOKEN_ID> [--db .metawsm/metawsm.db]",
 	"metawsm review sync [--run-id RUN_ID | --ticket T1] [--max-items N] [--dispatch] [--dry-run]",
 	"metawsm watch [--run-id RUN_ID | --ticket T1 | --all] [--interval 15] [--notify-cmd \"...\"] [--bell=true]",
 	"metawsm operator [--run-id RUN_ID | --ticket T1 | --all] [--interval 15] [--llm-mode off|assist|auto] [--dry-run]",
+	"metawsm operator <history|replay|rules test> [--run-id RUN_ID | --ticket T1] [--id N | --decision-id N] [--limit N] [--policy PATH] [--json]",
 	"metawsm forum <ask|answer|assign|state|priority|close|list|thread|watch|signal|debug> [--server http://127.0.0.1:3001] [...]",
-	"metawsm resume [--run-id RUN_ID | --ticket T1]",
-	"metawsm stop [--run-id RUN_ID | --ticket T1]",
-	"metawsm restart [--run-id RUN_ID | --ticket T1] [--dry-run]",
-	"metawsm cleanup [--run-id RUN_ID | --ticket T1] [--keep-workspaces] [--dry-run]",
-	"metawsm commit [--run-id RUN_ID | --ticket T1] [--message \"...\"] [--actor USER] [--dry-run]",
-	"metawsm pr [--run-id RUN_ID | --ticket T1] [--title \"...\"] [--body \"...\"] [--actor USER] [--dry-run]",
-	"metawsm merge [--run-id RUN_ID | --ticket T1] [--dry-run] [--human]",
-	"metawsm iterate [--run-id RUN_ID | --ticket T1] --feedback \"...\" [--dry-run]",
-	"metawsm close [--run-id RUN_ID | --ticket T1] [--dry-run]",
+	"metawsm resume [--run-id RUN_ID | --ticket T1] [--server URL]",
+	"metawsm stop [--run-id RUN_ID | --ticket T1] [--server URL]",
+	"metawsm restart [--run-id RUN_ID | --ticket T1] [--dry-run] [--server URL]",
+	"metawsm cleanup [--run-id RUN_ID | --ticket T1] [--keep-workspaces] [--dry-run] [--server URL]",
+	"metawsm commit [--run-id RUN_ID | --ticket T1] [--message \"...\"] [--actor USER] [--dry-run] [--server URL]",
+	"metawsm pr [--run-id RUN_ID | --ticket T1] [--title \"...\"] [--body \"...\"] [--actor USER] [--dry-run] [--server URL]",
+	"metawsm merge [--run-id RUN_ID | --ticket T1] [--dry-run] [--human] [--server URL]",
+	"metawsm iterate [--run-id RUN_ID | --ticket T1] --feedback \"...\" [--dry-run] [--server URL]",
+	"metawsm close [--run-id RUN_ID | --ticket T1] [--dry-run] [--server URL]",
 	"metawsm policy-init",
+	"metawsm template <list|show --name NAME|validate [--name NAME --ticket T1 --var k=v]> [--policy PATH]",
 	"metawsm tui [--run-id RUN_ID | --ticket T1] [--interval 2]",
 	"metawsm docs [--policy PATH] [--refresh] [--endpoint NAME] [--ticket T1]",
-	"metawsm serve [--addr :3001] [--db .metawsm/metawsm.db] [--worker-interval 500ms]",
+	"metawsm serve [--addr :3001] [--db .metawsm/metawsm.db] [--worker-interval 500ms] [--escalation-interval 1m] [--webhook-poll-interval 15s]",
+	"metawsm db <migrate [--to N] [--dry-run]|status> [--db .metawsm/metawsm.db]",
 }
 
 func usageText() string {
@@ -2,7 +2,6 @@ package main
 
 import (
 	"bytes"
-	"context"
 	"encoding/json"
 	"os"
 	"path/filepath"
@@ -12,6 +11,7 @@ import (
 
 	"metawsm/internal/docfederation"
 	"metawsm/internal/model"
+	"metawsm/internal/orchestrator"
 	"metawsm/internal/policy"
 )
 
@@ -166,28 +166,28 @@ func TestSelectFederationEndpointsByName(t *testing.T) {
 	}
 }
 
-func TestParseWatchSnapshot(t *testing.T) {
-	status := `Run: run-123
-Status: awaiting_guidance
-Mode: bootstrap
-Tickets: METAWSM-006
-Guidance:
-  - id=7 agent@ws question=Need operator decision
-Diffs:
-  - ws-a path=/tmp/ws-a
-    * metawsm dirty files=2
-Pull Requests:
-  - METAWSM-006/metawsm state=draft head=feature base=main number=0 url=- actor=agent
-  - METAWSM-006/metawsm-docs state=open head=feature base=main number=12 url=https://github.com/example/repo/pull/12 actor=agent
-Review Feedback:
-  - status=queued count=3
-  - status=new count=1
-  - status=addressed count=5
-  - status=ignored count=0
-Agents:
-  - agent@ws session=s1 status=stalled health=stalled last_activity=2026-02-08T00:00:00Z activity_age=1h last_progress=2026-02-08T00:00:00Z progress_age=1h
-`
-	snapshot := parseWatchSnapshot(status)
+func TestWatchSnapshotFromReport(t *testing.T) {
+	activity := time.Date(2026, 2, 8, 0, 0, 0, 0, time.UTC)
+	report := orchestrator.RunStatusReport{
+		Run:     model.RunRecord{RunID: "run-123", Status: model.RunStatusAwaitingGuidance},
+		Tickets: []string{"METAWSM-006"},
+		PendingGuidance: []orchestrator.RunStatusGuidance{
+			{ThreadID: "fthr-7", AgentName: "agent", WorkspaceName: "ws", Question: "Need operator decision"},
+		},
+		Diffs: []orchestrator.RunStatusWorkspaceDiff{
+			{WorkspaceName: "ws-a", Repos: []orchestrator.RunStatusRepoDiff{{Repo: "metawsm", Changes: []string{" M a.go", "?? b.go"}}}},
+		},
+		PullRequests: []model.RunPullRequest{
+			{Ticket: "METAWSM-006", Repo: "metawsm", PRState: model.PullRequestStateDraft},
+			{Ticket: "METAWSM-006", Repo: "metawsm-docs", PRState: model.PullRequestStateOpen, PRNumber: 12},
+		},
+		ReviewFeedback: orchestrator.RunStatusReviewFeedback{Queued: 3, New: 1, Addressed: 5},
+		Agents: []orchestrator.RunStatusAgent{
+			{Name: "agent", WorkspaceName: "ws", SessionName: "s1", Status: model.AgentStatusStalled, Health: model.HealthStateStalled, LastActivityAt: &activity, LastProgressAt: &activity, ActivityAge: "1h", ProgressAge: "1h", Unhealthy: true},
+			{Name: "helper", WorkspaceName: "ws", SessionName: "s2", Status: model.AgentStatusRunning, Health: model.HealthStateHealthy, ActivityAge: "-", ProgressAge: "-"},
+		},
+	}
+	snapshot := watchSnapshotFromReport(report, "run-123", "")
 	if snapshot.RunID != "run-123" {
 		t.Fatalf("expected run id run-123, got %q", snapshot.RunID)
 	}
@@ -212,8 +212,8 @@ Agents:
 	if !strings.Contains(snapshot.UnhealthyAgents[0].Reason, "no recent activity/progress") {
 		t.Fatalf("expected stalled reason, got %q", snapshot.UnhealthyAgents[0].Reason)
 	}
-	if snapshot.UnhealthyAgents[0].Session != "s1" {
-		t.Fatalf("expected unhealthy session s1, got %q", snapshot.UnhealthyAgents[0].Session)
+	if snapshot.UnhealthyAgents[0].Session != "s1" || snapshot.UnhealthyAgents[0].LastActivity != "2026-02-08T00:00:00Z" {
+		t.Fatalf("unexpected unhealthy agent %+v", snapshot.UnhealthyAgents[0])
 	}
 	if !snapshot.HasDirtyDiffs {
 		t.Fatalf("expected dirty diff detection")
@@ -230,6 +230,17 @@ Agents:
 	if snapshot.NewReviewFeedback != 1 {
 		t.Fatalf("expected new review feedback=1, got %d", snapshot.NewReviewFeedback)
 	}
+
+	var out strings.Builder
+	if err := printRunStatusReport(&out, report, "yaml"); err != nil {
+		t.Fatalf("print yaml: %v", err)
+	}
+	if !strings.Contains(out.String(), "run_id: run-123") || !strings.Contains(out.String(), "queued: 3") {
+		t.Fatalf("expected yaml to use report field names, got:\n%s", out.String())
+	}
+	if err := printRunStatusReport(&out, report, "xml"); err == nil {
+		t.Fatalf("expected unsupported output format to fail")
+	}
 }
 
 func TestClassifyWatchEventPrioritizesGuidance(t *testing.T) {
@@ -344,117 +355,6 @@ func TestBuildWatchDirectionHintsRunDoneIncludesHumanMerge(t *testing.T) {
 	}
 }
 
-func TestBuildOperatorRuleDecisionCommitReadyAssist(t *testing.T) {
-	now := time.Now()
-	decision, err := buildOperatorRuleDecision(
-		context.Background(),
-		nil,
-		watchSnapshot{
-			RunID:         "run-commit-ready",
-			RunStatus:     string(model.RunStatusComplete),
-			HasDirtyDiffs: true,
-		},
-		model.RunRecord{
-			RunID:     "run-commit-ready",
-			Status:    model.RunStatusComplete,
-			UpdatedAt: now,
-		},
-		now,
-		time.Hour,
-		time.Minute,
-		2,
-		3,
-		0,
-		nil,
-		"assist",
-		false,
-		"assist",
-	)
-	if err != nil {
-		t.Fatalf("build operator rule decision: %v", err)
-	}
-	if decision.Intent != operatorIntentCommitReady {
-		t.Fatalf("expected commit_ready intent, got %q", decision.Intent)
-	}
-	if decision.Execute {
-		t.Fatalf("expected assist mode commit readiness to not auto-execute")
-	}
-}
-
-func TestBuildOperatorRuleDecisionPRReadyAuto(t *testing.T) {
-	now := time.Now()
-	decision, err := buildOperatorRuleDecision(
-		context.Background(),
-		nil,
-		watchSnapshot{
-			RunID:             "run-pr-ready",
-			RunStatus:         string(model.RunStatusComplete),
-			DraftPullRequests: 2,
-		},
-		model.RunRecord{
-			RunID:     "run-pr-ready",
-			Status:    model.RunStatusComplete,
-			UpdatedAt: now,
-		},
-		now,
-		time.Hour,
-		time.Minute,
-		2,
-		3,
-		0,
-		nil,
-		"auto",
-		false,
-		"assist",
-	)
-	if err != nil {
-		t.Fatalf("build operator rule decision: %v", err)
-	}
-	if decision.Intent != operatorIntentPRReady {
-		t.Fatalf("expected pr_ready intent, got %q", decision.Intent)
-	}
-	if !decision.Execute {
-		t.Fatalf("expected auto mode PR readiness to auto-execute")
-	}
-}
-
-func TestBuildOperatorRuleDecisionReviewFeedbackReadyAuto(t *testing.T) {
-	now := time.Now()
-	decision, err := buildOperatorRuleDecision(
-		context.Background(),
-		nil,
-		watchSnapshot{
-			RunID:                "run-review-ready",
-			RunStatus:            string(model.RunStatusComplete),
-			QueuedReviewFeedback: 2,
-		},
-		model.RunRecord{
-			RunID:     "run-review-ready",
-			Status:    model.RunStatusComplete,
-			UpdatedAt: now,
-		},
-		now,
-		time.Hour,
-		time.Minute,
-		2,
-		3,
-		0,
-		nil,
-		"assist",
-		true,
-		"auto",
-	)
-	if err != nil {
-		t.Fatalf("build operator rule decision: %v", err)
-	}
-	if decision.Intent != operatorIntentReviewFeedbackReady {
-		t.Fatalf("expected review_feedback_ready intent, got %q", decision.Intent)
-	}
-	if !decision.Execute {
-		t.Fatalf("expected auto review feedback mode to auto-execute")
-	}
-}
-
 func TestResolveWatchMode(t *testing.T) {
 	mode, err := resolveWatchMode("", "", false)
 	if err != nil {
@@ -512,28 +412,6 @@ func TestIsTerminalRunStatus(t *testing.T) {
 	}
 }
 
-func TestResolveOperatorLLMMode(t *testing.T) {
-	mode, err := resolveOperatorLLMMode("", "assist")
-	if err != nil {
-		t.Fatalf("resolve from policy: %v", err)
-	}
-	if mode != "assist" {
-		t.Fatalf("expected assist mode, got %q", mode)
-	}
-
-	mode, err = resolveOperatorLLMMode("AUTO", "assist")
-	if err != nil {
-		t.Fatalf("resolve from flag: %v", err)
-	}
-	if mode != "auto" {
-		t.Fatalf("expected auto mode, got %q", mode)
-	}
-
-	if _, err := resolveOperatorLLMMode("invalid", "assist"); err == nil {
-		t.Fatalf("expected invalid mode error")
-	}
-}
-
 func TestAuthCommandRequiresCheckSubcommand(t *testing.T) {
 	err := authCommand([]string{})
 	if err == nil {
@@ -656,87 +534,9 @@ func TestResolveWorkspaceRepoPathErrorsWhenRepoMissing(t *testing.T) {
 	}
 }
 
-func TestClassifyStaleRunCandidate(t *testing.T) {
-	now := time.Now()
-	run := model.RunRecord{
-		RunID:     "run-1",
-		Status:    model.RunStatusRunning,
-		UpdatedAt: now.Add(-2 * time.Hour),
-	}
-	snapshot := watchSnapshot{
-		RunID:              "run-1",
-		RunStatus:          string(model.RunStatusRunning),
-		HasUnhealthyAgents: true,
-	}
-
-	stale, reason := classifyStaleRunCandidate(snapshot, run, now, time.Hour)
-	if !stale {
-		t.Fatalf("expected stale candidate")
-	}
-	if !strings.Contains(reason, "run appears stale") {
-		t.Fatalf("expected stale reason, got %q", reason)
-	}
-}
-
-func TestVerifyStaleRuntimeEvidenceRejectsActiveSession(t *testing.T) {
-	now := time.Now()
-	snapshot := watchSnapshot{
-		UnhealthyAgents: []watchAgentIssue{
-			{Agent: "agent@ws", Session: "session-a"},
-		},
-	}
-	probe := func(ctx context.Context, session string) (operatorSessionEvidence, error) {
-		lastActivity := now.Add(-30 * time.Second)
-		return operatorSessionEvidence{
-			Session:      session,
-			HasSession:   true,
-			LastActivity: &lastActivity,
-		}, nil
-	}
-
-	verified, reason, err := verifyStaleRuntimeEvidence(context.Background(), snapshot, now, 2*time.Minute, probe)
-	if err != nil {
-		t.Fatalf("verify stale runtime evidence: %v", err)
-	}
-	if verified {
-		t.Fatalf("expected stale verification rejection for active session")
-	}
-	if !strings.Contains(reason, "recent activity") {
-		t.Fatalf("expected recent activity reason, got %q", reason)
-	}
-}
-
-func TestVerifyStaleRuntimeEvidenceAcceptsExitedSessions(t *testing.T) {
-	now := time.Now()
-	snapshot := watchSnapshot{
-		UnhealthyAgents: []watchAgentIssue{
-			{Agent: "agent@ws", Session: "session-a"},
-		},
-	}
-	probe := func(ctx context.Context, session string) (operatorSessionEvidence, error) {
-		code := 1
-		return operatorSessionEvidence{
-			Session:    session,
-			HasSession: true,
-			ExitCode:   &code,
-		}, nil
-	}
-
-	verified, reason, err := verifyStaleRuntimeEvidence(context.Background(), snapshot, now, 2*time.Minute, probe)
-	if err != nil {
-		t.Fatalf("verify stale runtime evidence: %v", err)
-	}
-	if !verified {
-		t.Fatalf("expected stale verification success")
-	}
-	if !strings.Contains(reason, "no active tmux sessions") {
-		t.Fatalf("expected no-active-session reason, got %q", reason)
-	}
-}
-
 func TestUsageTextIncludesExpectedCommandMatrix(t *testing.T) {
-	if len(usageCommandLines) != 21 {
-		t.Fatalf("expected 21 usage command lines, got %d", len(usageCommandLines))
+	if len(usageCommandLines) != 26 {
+		t.Fatalf("expected 26 usage command lines, got %d", len(usageCommandLines))
 	}
 
 	usage := usageText()
@@ -744,10 +544,15 @@ func TestUsageTextIncludesExpectedCommandMatrix(t *testing.T) {
 		"metawsm run --ticket",
 		"metawsm bootstrap --ticket",
 		"metawsm auth check",
+		"metawsm auth token <create",
 		"metawsm review sync",
+		"metawsm logs [--run-id RUN_ID | --ticket T1] --agent NAME",
 		"metawsm forum <ask|answer|assign|state|priority|close|list|thread|watch|signal|debug>",
+		"metawsm operator <history|replay|rules test>",
 		"metawsm policy-init",
+		"metawsm template <list|show",
 		"metawsm serve [--addr :3001]",
+		"metawsm db <migrate",
 	}
 	for _, fragment := range expected {
 		if !strings.Contains(usage, fragment) {
@@ -766,6 +571,7 @@ func TestNewRootCommandRegistersExpectedTopLevelCommands(t *testing.T) {
 		"run",
 		"bootstrap",
 		"status",
+		"logs",
 		"auth",
 		"review",
 		"watch",
@@ -784,6 +590,8 @@ func TestNewRootCommandRegistersExpectedTopLevelCommands(t *testing.T) {
 		"tui",
 		"docs",
 		"serve",
+		"db",
+		"template",
 	}
 	for _, name := range expected {
 		cmd, _, findErr := rootCmd.Find([]string{name})
@@ -795,3 +603,46 @@ func TestNewRootCommandRegistersExpectedTopLevelCommands(t *testing.T) {
 		}
 	}
 }
+
+func TestLastTranscriptLinesKeepsTrailingLines(t *testing.T) {
+	got := lastTranscriptLines("partial\nline one\nline two\n", 2)
+	if got != "line one\nline two\n" {
+		t.Fatalf("unexpected tail: %q", got)
+	}
+	if got := lastTranscriptLines("only\n", 5); got != "only\n" {
+		t.Fatalf("expected short transcript unchanged, got %q", got)
+	}
+}
+
+func TestParseForumAttachmentSpecsSlicesSnippetLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, []byte("one\ntwo\nthree\nfour\n"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	attachments, err := parseForumAttachmentSpecs(
+		[]string{"snippet:" + path + ":2-3", "output:-"},
+		"make test",
+		strings.NewReader("ok\n"),
+	)
+	if err != nil {
+		t.Fatalf("parse attachment specs: %v", err)
+	}
+	if len(attachments) != 2 {
+		t.Fatalf("expected two attachments, got %d", len(attachments))
+	}
+	snippet := attachments[0]
+	if snippet.Kind != model.ForumAttachmentSnippet || snippet.Content != "two\nthree\n" || snippet.StartLine != 2 || snippet.EndLine != 3 {
+		t.Fatalf("unexpected snippet attachment: %+v", snippet)
+	}
+	output := attachments[1]
+	if output.Kind != model.ForumAttachmentCommandOutput || output.Command != "make test" || output.Content != "ok\n" {
+		t.Fatalf("unexpected command output attachment: %+v", output)
+	}
+
+	if _, err := parseForumAttachmentSpecs([]string{"output:-"}, "", strings.NewReader("")); err == nil {
+		t.Fatalf("expected output attachment without --attach-command to fail")
+	}
+	if _, err := parseForumAttachmentSpecs([]string{"snippet:" + path + ":9"}, "", nil); err == nil {
+		t.Fatalf("expected out-of-range snippet to fail")
+	}
+}
@@ -107,6 +107,12 @@ func newRootCommand() (*cobra.Command, error) {
 	}
 	migrated = append(migrated, closeCmd)
 
+	logsCmd, err := newLogsGlazedCommand()
+	if err != nil {
+		return nil, err
+	}
+	migrated = append(migrated, logsCmd)
+
 	watchCmd, err := newWatchGlazedCommand()
 	if err != nil {
 		return nil, err
@@ -0,0 +1,79 @@
+package forumbus
+
+import (
+	"context"
+	"fmt"
+
+	"metawsm/internal/model"
+	"metawsm/internal/store"
+)
+
+// embeddedTransport delivers claimed outbox rows straight to handlers in
+// process. A row is marked sent only after its handler succeeds; handler
+// errors mark it failed so the store retries it with backoff, and a claim
+// abandoned by a crashed worker is reclaimed once its lease expires, so
+// delivery stays at-least-once like the Redis transport.
+type embeddedTransport struct {
+	store *store.SQLiteStore
+}
+
+func newEmbeddedTransport(sqliteStore *store.SQLiteStore) *embeddedTransport {
+	return &embeddedTransport{store: sqliteStore}
+}
+
+func (t *embeddedTransport) name() string {
+	return TransportEmbedded
+}
+
+func (t *embeddedTransport) start([]string) error {
+	return nil
+}
+
+func (t *embeddedTransport) stop() {}
+
+func (t *embeddedTransport) healthy() error {
+	return nil
+}
+
+func (t *embeddedTransport) subscribe(string) error {
+	return nil
+}
+
+func (t *embeddedTransport) process(ctx context.Context, limit int, handlers map[string]MessageHandler) (int, error) {
+	batch, err := t.store.ClaimForumOutboxPending(limit)
+	if err != nil {
+		return 0, err
+	}
+	processed := 0
+	for i, msg := range batch {
+		if err := ctx.Err(); err != nil {
+			// Release the rest of the claim so the next pass delivers it.
+			for _, remaining := range batch[i:] {
+				_ = t.store.ReleaseForumOutboxClaim(remaining.MessageID)
+			}
+			return processed, err
+		}
+		handler := handlers[msg.Topic]
+		if handler == nil {
+			_ = t.store.MarkForumOutboxFailed(msg.MessageID, fmt.Sprintf("no handler for topic %s", msg.Topic))
+			continue
+		}
+		processed++
+		if err := handler(ctx, msg); err != nil {
+			_ = t.store.MarkForumOutboxFailed(msg.MessageID, err.Error())
+			continue
+		}
+		if err := t.store.MarkForumOutboxSent(msg.MessageID); err != nil {
+			return processed, err
+		}
+	}
+	return processed, nil
+}
+
+// describe reports every handler topic as subscribed: the embedded transport
+// has no separate consumer registration.
+func (t *embeddedTransport) describe(debug *model.ForumBusDebug) {
+	debug.SubscriptionTopics = append([]string{}, debug.HandlerTopics...)
+}
+
+func (t *embeddedTransport) describeTopic(context.Context, *model.ForumBusTopicDebug) {}
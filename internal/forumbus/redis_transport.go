@@ -0,0 +1,456 @@
+package forumbus
+
+import (
+	"context"
+	"crypto/sha1"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill-redisstream/pkg/redisstream"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/redis/go-redis/v9"
+
+	"metawsm/internal/model"
+	"metawsm/internal/policy"
+	"metawsm/internal/store"
+)
+
+// redisTransport publishes claimed outbox rows to Redis streams and consumes
+// them back through a consumer group. A row is marked sent once Redis has it;
+// a failing handler marks it failed so the next claim republishes it.
+type redisTransport struct {
+	store *store.SQLiteStore
+	cfg   policy.Config
+
+	mu            sync.RWMutex
+	redisClient   redis.UniversalClient
+	publisher     *redisstream.Publisher
+	subscriber    *redisstream.Subscriber
+	subscribeCtx  context.Context
+	subscribeStop context.CancelFunc
+	subscriptions map[string]<-chan *message.Message
+	streamName    string
+	groupName     string
+	consumerName  string
+}
+
+func newRedisTransport(sqliteStore *store.SQLiteStore, cfg policy.Config) *redisTransport {
+	streamName, groupName, consumerName := deriveStreamNamespace(
+		strings.TrimSpace(cfg.Forum.Redis.Stream),
+		strings.TrimSpace(cfg.Forum.Redis.Group),
+		strings.TrimSpace(cfg.Forum.Redis.Consumer),
+		strings.TrimSpace(sqliteStore.DBPath),
+	)
+	return &redisTransport{
+		store:         sqliteStore,
+		cfg:           cfg,
+		subscriptions: make(map[string]<-chan *message.Message),
+		streamName:    streamName,
+		groupName:     groupName,
+		consumerName:  consumerName,
+	}
+}
+
+func (t *redisTransport) name() string {
+	return TransportRedis
+}
+
+func (t *redisTransport) start(topics []string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	redisURL := strings.TrimSpace(t.cfg.Forum.Redis.URL)
+	if redisURL == "" {
+		return fmt.Errorf("forum redis url is empty")
+	}
+	options, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return fmt.Errorf("parse redis url: %w", err)
+	}
+	client := redis.NewClient(options)
+	logger := watermill.NopLogger{}
+	publisher, err := redisstream.NewPublisher(redisstream.PublisherConfig{
+		Client:     client,
+		Marshaller: redisstream.DefaultMarshallerUnmarshaller{},
+	}, logger)
+	if err != nil {
+		return fmt.Errorf("create redisstream publisher: %w", err)
+	}
+	subscriber, err := redisstream.NewSubscriber(redisstream.SubscriberConfig{
+		Client:        client,
+		Unmarshaller:  redisstream.DefaultMarshallerUnmarshaller{},
+		ConsumerGroup: t.groupName,
+		Consumer:      t.consumerName,
+	}, logger)
+	if err != nil {
+		_ = publisher.Close()
+		return fmt.Errorf("create redisstream subscriber: %w", err)
+	}
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		_ = subscriber.Close()
+		_ = publisher.Close()
+		return fmt.Errorf("redis ping failed: %w", err)
+	}
+	subCtx, cancel := context.WithCancel(context.Background())
+	t.redisClient = client
+	t.publisher = publisher
+	t.subscriber = subscriber
+	t.subscribeCtx = subCtx
+	t.subscribeStop = cancel
+	t.subscriptions = make(map[string]<-chan *message.Message)
+	for _, topic := range topics {
+		if err := t.subscribeTopicLocked(topic); err != nil {
+			t.closeLocked()
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *redisTransport) stop() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closeLocked()
+}
+
+func (t *redisTransport) closeLocked() {
+	if t.subscribeStop != nil {
+		t.subscribeStop()
+	}
+	if t.subscriber != nil {
+		_ = t.subscriber.Close()
+	}
+	if t.publisher != nil {
+		_ = t.publisher.Close()
+	}
+	if t.redisClient != nil {
+		_ = t.redisClient.Close()
+	}
+	t.redisClient = nil
+	t.publisher = nil
+	t.subscriber = nil
+	t.subscribeCtx = nil
+	t.subscribeStop = nil
+	t.subscriptions = make(map[string]<-chan *message.Message)
+}
+
+func (t *redisTransport) healthy() error {
+	t.mu.RLock()
+	client := t.redisClient
+	t.mu.RUnlock()
+	if strings.TrimSpace(t.cfg.Forum.Redis.URL) == "" {
+		return fmt.Errorf("forum redis url is empty")
+	}
+	if client == nil {
+		return fmt.Errorf("forum redis client is not configured")
+	}
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		return fmt.Errorf("forum redis ping failed: %w", err)
+	}
+	return nil
+}
+
+func (t *redisTransport) subscribe(topic string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.subscribeTopicLocked(topic)
+}
+
+func (t *redisTransport) process(ctx context.Context, limit int, handlers map[string]MessageHandler) (int, error) {
+	flushed, err := t.flushOutboxToRedis(limit, handlers)
+	if err != nil {
+		return flushed, err
+	}
+	consumed, err := t.consumeRedisMessages(ctx, limit, handlers)
+	if err != nil {
+		return flushed, err
+	}
+	return flushed + consumed, nil
+}
+
+func (t *redisTransport) describe(debug *model.ForumBusDebug) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	debug.RedisURL = sanitizeRedisURL(strings.TrimSpace(t.cfg.Forum.Redis.URL))
+	debug.StreamName = t.streamName
+	debug.ConsumerGroup = t.groupName
+	debug.ConsumerName = t.consumerName
+	debug.SubscriptionTopics = sortedMapKeys(t.subscriptions)
+}
+
+func (t *redisTransport) describeTopic(ctx context.Context, item *model.ForumBusTopicDebug) {
+	t.mu.RLock()
+	client := t.redisClient
+	groupName := t.groupName
+	item.Stream = streamTopicForPrefix(t.streamName, item.Topic)
+	t.mu.RUnlock()
+	if client == nil {
+		return
+	}
+
+	streamInfo, err := client.XInfoStream(ctx, item.Stream).Result()
+	if err != nil {
+		if !isRedisNoStreamError(err) {
+			item.TopicError = appendTopicError(item.TopicError, err.Error())
+		}
+	} else {
+		item.StreamExists = true
+		item.StreamLength = streamInfo.Length
+		item.LastGeneratedID = strings.TrimSpace(streamInfo.LastGeneratedID)
+	}
+
+	groups, err := client.XInfoGroups(ctx, item.Stream).Result()
+	if err != nil {
+		if !isRedisNoStreamError(err) {
+			item.TopicError = appendTopicError(item.TopicError, err.Error())
+		}
+		return
+	}
+	for _, group := range groups {
+		if strings.TrimSpace(group.Name) != groupName {
+			continue
+		}
+		item.ConsumerGroupPresent = true
+		item.ConsumerGroupPending = group.Pending
+		item.ConsumerGroupLag = group.Lag
+		break
+	}
+}
+
+func (t *redisTransport) flushOutboxToRedis(limit int, handlers map[string]MessageHandler) (int, error) {
+	batch, err := t.store.ClaimForumOutboxPending(limit)
+	if err != nil {
+		return 0, err
+	}
+	if len(batch) == 0 {
+		return 0, nil
+	}
+	t.mu.RLock()
+	publisher := t.publisher
+	t.mu.RUnlock()
+	if publisher == nil {
+		return 0, fmt.Errorf("forum redis publisher is not configured")
+	}
+	sent := 0
+	for _, msg := range batch {
+		if handlers[msg.Topic] == nil {
+			_ = t.store.MarkForumOutboxFailed(msg.MessageID, fmt.Sprintf("no handler for topic %s", msg.Topic))
+			continue
+		}
+		wm := message.NewMessage(msg.MessageID, []byte(msg.PayloadJSON))
+		wm.Metadata.Set("message_id", msg.MessageID)
+		wm.Metadata.Set("topic", msg.Topic)
+		wm.Metadata.Set("message_key", msg.MessageKey)
+		err := publisher.Publish(t.streamTopic(msg.Topic), wm)
+		if err != nil {
+			_ = t.store.MarkForumOutboxFailed(msg.MessageID, err.Error())
+			continue
+		}
+		if err := t.store.MarkForumOutboxSent(msg.MessageID); err != nil {
+			return sent, err
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+func (t *redisTransport) consumeRedisMessages(ctx context.Context, limit int, handlers map[string]MessageHandler) (int, error) {
+	if limit <= 0 {
+		return 0, nil
+	}
+	if err := t.ensureSubscriptions(handlers); err != nil {
+		return 0, err
+	}
+	t.mu.RLock()
+	topics := make([]string, 0, len(t.subscriptions))
+	for topic := range t.subscriptions {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+	channels := make(map[string]<-chan *message.Message, len(t.subscriptions))
+	for topic, ch := range t.subscriptions {
+		channels[topic] = ch
+	}
+	t.mu.RUnlock()
+
+	processed := 0
+	deadline := time.Now().Add(300 * time.Millisecond)
+	for processed < limit {
+		progressed := false
+		for _, topic := range topics {
+			ch := channels[topic]
+			if ch == nil {
+				continue
+			}
+			select {
+			case <-ctx.Done():
+				return processed, ctx.Err()
+			case msg, ok := <-ch:
+				if !ok || msg == nil {
+					continue
+				}
+				progressed = true
+				forumMsg := model.ForumOutboxMessage{
+					MessageID:   strings.TrimSpace(msg.Metadata.Get("message_id")),
+					Topic:       topic,
+					MessageKey:  strings.TrimSpace(msg.Metadata.Get("message_key")),
+					PayloadJSON: string(msg.Payload),
+				}
+				if forumMsg.MessageID == "" {
+					forumMsg.MessageID = strings.TrimSpace(msg.UUID)
+				}
+				handler := handlers[topic]
+				if handler == nil {
+					msg.Ack()
+				} else if err := handler(ctx, forumMsg); err != nil {
+					_ = t.store.MarkForumOutboxFailed(forumMsg.MessageID, err.Error())
+					msg.Nack()
+				} else {
+					msg.Ack()
+				}
+				processed++
+				if processed >= limit {
+					return processed, nil
+				}
+			default:
+			}
+		}
+		if !progressed {
+			if time.Now().After(deadline) {
+				break
+			}
+			select {
+			case <-ctx.Done():
+				return processed, ctx.Err()
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+	}
+	return processed, nil
+}
+
+func (t *redisTransport) ensureSubscriptions(handlers map[string]MessageHandler) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for topic := range handlers {
+		if err := t.subscribeTopicLocked(topic); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *redisTransport) subscribeTopicLocked(topic string) error {
+	topic = strings.TrimSpace(topic)
+	if topic == "" {
+		return fmt.Errorf("forum bus topic is required")
+	}
+	if _, exists := t.subscriptions[topic]; exists {
+		return nil
+	}
+	if t.subscriber == nil || t.subscribeCtx == nil {
+		return fmt.Errorf("forum redis subscriber is not configured")
+	}
+	ch, err := t.subscriber.Subscribe(t.subscribeCtx, t.streamTopic(topic))
+	if err != nil {
+		return fmt.Errorf("subscribe to redis stream topic %s: %w", topic, err)
+	}
+	t.subscriptions[topic] = ch
+	return nil
+}
+
+func (t *redisTransport) streamTopic(topic string) string {
+	topic = strings.TrimSpace(topic)
+	if topic == "" {
+		return topic
+	}
+	prefix := strings.TrimSpace(t.streamName)
+	if prefix == "" {
+		return topic
+	}
+	return prefix + "." + topic
+}
+
+func deriveStreamNamespace(stream string, group string, consumer string, dbPath string) (string, string, string) {
+	stream = strings.TrimSpace(stream)
+	group = strings.TrimSpace(group)
+	consumer = strings.TrimSpace(consumer)
+	dbPath = strings.TrimSpace(dbPath)
+	if stream == "" {
+		stream = "metawsm-forum"
+	}
+	if group == "" {
+		group = "metawsm-forum"
+	}
+	if consumer == "" {
+		consumer = "operator"
+	}
+	if dbPath == "" {
+		return stream, group, consumer
+	}
+	hash := sha1.Sum([]byte(dbPath))
+	suffix := fmt.Sprintf("%x", hash[:4])
+	return stream + "." + suffix, group + "." + suffix, consumer + "-" + suffix
+}
+
+func sanitizeRedisURL(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	if parsed.User != nil {
+		if username := parsed.User.Username(); username != "" {
+			parsed.User = url.UserPassword(username, "***")
+		} else {
+			parsed.User = url.User("***")
+		}
+	}
+	return parsed.String()
+}
+
+func isRedisNoStreamError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, redis.Nil) {
+		return true
+	}
+	value := strings.ToLower(strings.TrimSpace(err.Error()))
+	return strings.Contains(value, "no such key")
+}
+
+func streamTopicForPrefix(prefix string, topic string) string {
+	prefix = strings.TrimSpace(prefix)
+	topic = strings.TrimSpace(topic)
+	if prefix == "" {
+		return topic
+	}
+	if topic == "" {
+		return prefix
+	}
+	return prefix + "." + topic
+}
+
+func appendTopicError(existing string, next string) string {
+	existing = strings.TrimSpace(existing)
+	next = strings.TrimSpace(next)
+	if next == "" {
+		return existing
+	}
+	if existing == "" {
+		return next
+	}
+	if strings.Contains(existing, next) {
+		return existing
+	}
+	return existing + "; " + next
+}
@@ -2,6 +2,7 @@ package forumbus
 
 import (
 	"context"
+	"errors"
 	"os/exec"
 	"path/filepath"
 	"strings"
@@ -28,6 +29,7 @@ func startTestRedis(t *testing.T) *miniredis.Miniredis {
 
 func testPolicyWithRedis(server *miniredis.Miniredis) policy.Config {
 	cfg := policy.Default()
+	cfg.Forum.Transport = TransportRedis
 	cfg.Forum.Redis.URL = "redis://" + server.Addr() + "/0"
 	cfg.Forum.Redis.Stream = "metawsm-forum-test"
 	cfg.Forum.Redis.Group = "metawsm-forum-test"
@@ -100,6 +102,7 @@ func TestRuntimeFailsWhenRedisUnavailable(t *testing.T) {
 	}
 
 	cfg := policy.Default()
+	cfg.Forum.Transport = TransportRedis
 	cfg.Forum.Redis.URL = ""
 	rt := NewRuntime(sqliteStore, cfg)
 	if err := rt.Start(context.Background()); err != nil {
@@ -136,6 +139,8 @@ func TestRuntimeReplaysFailedOutboxMessageAfterHandlerRegistration(t *testing.T)
 		t.Fatalf("init store: %v", err)
 	}
 
+	sqliteStore.OutboxBackoffBaseMS = 1
+
 	redisServer := startTestRedis(t)
 	rt := NewRuntime(sqliteStore, testPolicyWithRedis(redisServer))
 	if err := rt.Start(context.Background()); err != nil {
@@ -169,6 +174,7 @@ func TestRuntimeReplaysFailedOutboxMessageAfterHandlerRegistration(t *testing.T)
 	}); err != nil {
 		t.Fatalf("register replay handler: %v", err)
 	}
+	time.Sleep(10 * time.Millisecond)
 	if _, err := rt.ProcessOnce(context.Background(), 10); err != nil {
 		t.Fatalf("process replay message: %v", err)
 	}
@@ -295,3 +301,182 @@ func TestRuntimeObserverReceivesMatchingTopics(t *testing.T) {
 		t.Fatalf("timed out waiting for observer notification")
 	}
 }
+
+func newEmbeddedTestRuntime(t *testing.T) (*Runtime, *store.SQLiteStore) {
+	t.Helper()
+	sqliteStore := store.NewSQLiteStore(filepath.Join(t.TempDir(), "metawsm.db"))
+	if err := sqliteStore.Init(); err != nil {
+		t.Fatalf("init store: %v", err)
+	}
+	sqliteStore.OutboxBackoffBaseMS = 1
+	t.Cleanup(func() { _ = sqliteStore.Close() })
+	rt := NewRuntime(sqliteStore, policy.Default())
+	if err := rt.Start(context.Background()); err != nil {
+		t.Fatalf("start embedded runtime: %v", err)
+	}
+	t.Cleanup(rt.Stop)
+	return rt, sqliteStore
+}
+
+func TestEmbeddedRuntimeDeliversWithoutRedis(t *testing.T) {
+	rt, sqliteStore := newEmbeddedTestRuntime(t)
+	if rt.TransportName() != TransportEmbedded {
+		t.Fatalf("expected embedded transport by default, got %q", rt.TransportName())
+	}
+	if err := rt.Healthy(); err != nil {
+		t.Fatalf("expected healthy embedded runtime: %v", err)
+	}
+
+	var handled int32
+	if err := rt.RegisterHandler("forum.events.post.added", func(_ context.Context, message model.ForumOutboxMessage) error {
+		if message.MessageKey != "thread-1" {
+			t.Fatalf("unexpected message key %q", message.MessageKey)
+		}
+		atomic.AddInt32(&handled, 1)
+		return nil
+	}); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	observed := make(chan string, 1)
+	unsubscribe, err := rt.RegisterObserver("forum.events.", func(topic string, _ model.ForumOutboxMessage) {
+		observed <- topic
+	})
+	if err != nil {
+		t.Fatalf("register observer: %v", err)
+	}
+	defer unsubscribe()
+
+	if _, err := rt.Publish("forum.events.post.added", "thread-1", map[string]any{"ok": true}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	processed, err := rt.ProcessOnce(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("process once: %v", err)
+	}
+	if processed != 1 || atomic.LoadInt32(&handled) != 1 {
+		t.Fatalf("expected one delivery, processed=%d handled=%d", processed, handled)
+	}
+	select {
+	case topic := <-observed:
+		if topic != "forum.events.post.added" {
+			t.Fatalf("unexpected observed topic %q", topic)
+		}
+	default:
+		t.Fatalf("expected observer notification")
+	}
+	sent, err := sqliteStore.ListForumOutboxByStatus(model.ForumOutboxStatusSent, 10)
+	if err != nil {
+		t.Fatalf("list sent outbox: %v", err)
+	}
+	if len(sent) != 1 {
+		t.Fatalf("expected one sent outbox message, got %d", len(sent))
+	}
+
+	snapshot := rt.DebugSnapshot(context.Background(), nil)
+	if snapshot.Transport != TransportEmbedded || !snapshot.Healthy || !containsString(snapshot.SubscriptionTopics, "forum.events.post.added") {
+		t.Fatalf("unexpected debug snapshot %+v", snapshot)
+	}
+}
+
+func TestEmbeddedRuntimeRetriesFailedHandler(t *testing.T) {
+	rt, sqliteStore := newEmbeddedTestRuntime(t)
+
+	var attempts int32
+	if err := rt.RegisterHandler("forum.commands.open_thread", func(context.Context, model.ForumOutboxMessage) error {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			return errors.New("projection unavailable")
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	if _, err := rt.Publish("forum.commands.open_thread", "thread-retry", map[string]any{"ok": true}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	if _, err := rt.ProcessOnce(context.Background(), 10); err != nil {
+		t.Fatalf("first process: %v", err)
+	}
+	failed, err := sqliteStore.ListForumOutboxByStatus(model.ForumOutboxStatusFailed, 10)
+	if err != nil {
+		t.Fatalf("list failed outbox: %v", err)
+	}
+	if len(failed) != 1 || failed[0].LastError != "projection unavailable" {
+		t.Fatalf("expected failed message with handler error, got %+v", failed)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	if _, err := rt.ProcessOnce(context.Background(), 10); err != nil {
+		t.Fatalf("second process: %v", err)
+	}
+	if atomic.LoadInt32(&attempts) != 2 {
+		t.Fatalf("expected handler retry, got %d attempts", attempts)
+	}
+	sent, err := sqliteStore.ListForumOutboxByStatus(model.ForumOutboxStatusSent, 10)
+	if err != nil {
+		t.Fatalf("list sent outbox: %v", err)
+	}
+	if len(sent) != 1 || sent[0].AttemptCount != 2 {
+		t.Fatalf("expected retried message to be sent after 2 attempts, got %+v", sent)
+	}
+}
+
+func TestEmbeddedRuntimeLeavesUnhandledTopicsForLaterHandlers(t *testing.T) {
+	rt, sqliteStore := newEmbeddedTestRuntime(t)
+
+	if _, err := rt.Publish("forum.commands.late", "thread-late", map[string]any{"ok": true}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if processed, err := rt.ProcessOnce(context.Background(), 10); err != nil || processed != 0 {
+		t.Fatalf("expected nothing processed without handler, processed=%d err=%v", processed, err)
+	}
+	failedCount, err := sqliteStore.CountForumOutboxByStatus(model.ForumOutboxStatusFailed)
+	if err != nil {
+		t.Fatalf("count failed outbox: %v", err)
+	}
+	if failedCount != 1 {
+		t.Fatalf("expected unhandled message to be parked as failed, got %d", failedCount)
+	}
+
+	if err := rt.RegisterHandler("forum.commands.late", func(context.Context, model.ForumOutboxMessage) error {
+		return nil
+	}); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if processed, err := rt.ProcessOnce(context.Background(), 10); err != nil || processed != 1 {
+		t.Fatalf("expected late handler to receive message, processed=%d err=%v", processed, err)
+	}
+}
+
+func TestEmbeddedRuntimeDeadLettersAfterMaxAttempts(t *testing.T) {
+	rt, sqliteStore := newEmbeddedTestRuntime(t)
+	sqliteStore.OutboxMaxAttempts = 2
+
+	var attempts int32
+	if err := rt.RegisterHandler("forum.commands.open_thread", func(context.Context, model.ForumOutboxMessage) error {
+		atomic.AddInt32(&attempts, 1)
+		return errors.New("poison message")
+	}); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	if _, err := rt.Publish("forum.commands.open_thread", "thread-poison", map[string]any{"ok": true}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := rt.ProcessOnce(context.Background(), 10); err != nil {
+			t.Fatalf("process %d: %v", i, err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if atomic.LoadInt32(&attempts) != 2 {
+		t.Fatalf("expected delivery to stop after 2 attempts, got %d", attempts)
+	}
+	dead, err := sqliteStore.ListForumOutboxByStatus(model.ForumOutboxStatusDeadLetter, 10)
+	if err != nil {
+		t.Fatalf("list dead letters: %v", err)
+	}
+	if len(dead) != 1 || dead[0].LastError != "poison message" {
+		t.Fatalf("expected poison message in dead_letter, got %+v", dead)
+	}
+}
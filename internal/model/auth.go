@@ -0,0 +1,58 @@
+package model
+
+import "time"
+
+// APIRole bounds what an authenticated API caller may do.
+type APIRole string
+
+const (
+	APIRoleViewer   APIRole = "viewer"
+	APIRoleHuman    APIRole = "human"
+	APIRoleOperator APIRole = "operator"
+	APIRoleAgent    APIRole = "agent"
+)
+
+func (r APIRole) Valid() bool {
+	switch r {
+	case APIRoleViewer, APIRoleHuman, APIRoleOperator, APIRoleAgent:
+		return true
+	default:
+		return false
+	}
+}
+
+// ActorType is the forum actor type recorded for writes made with this role.
+func (r APIRole) ActorType() ForumActorType {
+	switch r {
+	case APIRoleOperator:
+		return ForumActorOperator
+	case APIRoleAgent:
+		return ForumActorAgent
+	default:
+		return ForumActorHuman
+	}
+}
+
+// APIToken is a stored API credential. Only the SHA-256 hash of the secret is
+// kept. Agent credentials minted at session start carry the run, agent, and
+// workspace they were issued for; tokens created by hand leave them empty.
+type APIToken struct {
+	TokenID       string     `json:"token_id"`
+	TokenHash     string     `json:"-"`
+	Role          APIRole    `json:"role"`
+	ActorName     string     `json:"actor_name"`
+	RunID         string     `json:"run_id,omitempty"`
+	AgentName     string     `json:"agent_name,omitempty"`
+	WorkspaceName string     `json:"workspace_name,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+	RevokedAt     *time.Time `json:"revoked_at,omitempty"`
+}
+
+// Active reports whether the token can still authenticate at now.
+func (t APIToken) Active(now time.Time) bool {
+	if t.RevokedAt != nil {
+		return false
+	}
+	return t.ExpiresAt == nil || now.Before(*t.ExpiresAt)
+}
@@ -87,15 +87,17 @@ func (r ForumTopicRegistry) IntegrationTopic(name string) string {
 }
 
 type ForumOpenThreadCommand struct {
-	Envelope ForumEnvelope `json:"envelope"`
-	Title    string        `json:"title"`
-	Body     string        `json:"body"`
-	Priority ForumPriority `json:"priority"`
+	Envelope    ForumEnvelope     `json:"envelope"`
+	Title       string            `json:"title"`
+	Body        string            `json:"body"`
+	Priority    ForumPriority     `json:"priority"`
+	Attachments []ForumAttachment `json:"attachments,omitempty"`
 }
 
 type ForumAddPostCommand struct {
-	Envelope ForumEnvelope `json:"envelope"`
-	Body     string        `json:"body"`
+	Envelope    ForumEnvelope     `json:"envelope"`
+	Body        string            `json:"body"`
+	Attachments []ForumAttachment `json:"attachments,omitempty"`
 }
 
 type ForumAssignThreadCommand struct {
@@ -119,39 +121,110 @@ type ForumCloseThreadCommand struct {
 	Envelope ForumEnvelope `json:"envelope"`
 }
 
+// ForumEscalateThreadCommand raises a thread that has sat past its SLA. An
+// empty assignee keeps the current one.
+type ForumEscalateThreadCommand struct {
+	Envelope     ForumEnvelope  `json:"envelope"`
+	Level        int            `json:"level"`
+	Priority     ForumPriority  `json:"priority"`
+	AssigneeType ForumActorType `json:"assignee_type,omitempty"`
+	AssigneeName string         `json:"assignee_name,omitempty"`
+	Reason       string         `json:"reason,omitempty"`
+}
+
+// ForumEscalation is one SLA escalation recorded in forum_state_transitions.
+type ForumEscalation struct {
+	ThreadID         string           `json:"thread_id"`
+	EventID          string           `json:"event_id"`
+	State            ForumThreadState `json:"state"`
+	Level            int              `json:"level"`
+	FromPriority     ForumPriority    `json:"from_priority"`
+	ToPriority       ForumPriority    `json:"to_priority"`
+	FromAssigneeType ForumActorType   `json:"from_assignee_type,omitempty"`
+	FromAssigneeName string           `json:"from_assignee_name,omitempty"`
+	ToAssigneeType   ForumActorType   `json:"to_assignee_type,omitempty"`
+	ToAssigneeName   string           `json:"to_assignee_name,omitempty"`
+	Reason           string           `json:"reason,omitempty"`
+	ChangedByType    ForumActorType   `json:"changed_by_type"`
+	ChangedByName    string           `json:"changed_by_name,omitempty"`
+	EscalatedAt      time.Time        `json:"escalated_at"`
+}
+
 type ForumThreadView struct {
-	ThreadID          string           `json:"thread_id"`
-	Ticket            string           `json:"ticket"`
-	RunID             string           `json:"run_id,omitempty"`
-	AgentName         string           `json:"agent_name,omitempty"`
-	Title             string           `json:"title"`
-	State             ForumThreadState `json:"state"`
-	Priority          ForumPriority    `json:"priority"`
-	AssigneeType      ForumActorType   `json:"assignee_type,omitempty"`
-	AssigneeName      string           `json:"assignee_name,omitempty"`
-	OpenedByType      ForumActorType   `json:"opened_by_type"`
-	OpenedByName      string           `json:"opened_by_name,omitempty"`
-	PostsCount        int              `json:"posts_count"`
-	LastPostAt        *time.Time       `json:"last_post_at,omitempty"`
-	LastPostByType    ForumActorType   `json:"last_post_by_type,omitempty"`
-	LastPostByName    string           `json:"last_post_by_name,omitempty"`
-	LastEventSequence int64            `json:"last_event_sequence,omitempty"`
-	LastActorType     ForumActorType   `json:"last_actor_type,omitempty"`
-	IsUnseen          bool             `json:"is_unseen,omitempty"`
-	IsUnanswered      bool             `json:"is_unanswered,omitempty"`
-	OpenedAt          time.Time        `json:"opened_at"`
-	UpdatedAt         time.Time        `json:"updated_at"`
-	ClosedAt          *time.Time       `json:"closed_at,omitempty"`
+	ThreadID          string            `json:"thread_id"`
+	Ticket            string            `json:"ticket"`
+	RunID             string            `json:"run_id,omitempty"`
+	AgentName         string            `json:"agent_name,omitempty"`
+	Title             string            `json:"title"`
+	State             ForumThreadState  `json:"state"`
+	Priority          ForumPriority     `json:"priority"`
+	AssigneeType      ForumActorType    `json:"assignee_type,omitempty"`
+	AssigneeName      string            `json:"assignee_name,omitempty"`
+	OpenedByType      ForumActorType    `json:"opened_by_type"`
+	OpenedByName      string            `json:"opened_by_name,omitempty"`
+	PostsCount        int               `json:"posts_count"`
+	LastPostAt        *time.Time        `json:"last_post_at,omitempty"`
+	LastPostByType    ForumActorType    `json:"last_post_by_type,omitempty"`
+	LastPostByName    string            `json:"last_post_by_name,omitempty"`
+	LastEventSequence int64             `json:"last_event_sequence,omitempty"`
+	LastActorType     ForumActorType    `json:"last_actor_type,omitempty"`
+	IsUnseen          bool              `json:"is_unseen,omitempty"`
+	IsUnanswered      bool              `json:"is_unanswered,omitempty"`
+	OpenedAt          time.Time         `json:"opened_at"`
+	UpdatedAt         time.Time         `json:"updated_at"`
+	ClosedAt          *time.Time        `json:"closed_at,omitempty"`
+	Match             *ForumSearchMatch `json:"match,omitempty"`
+}
+
+// ForumSearchMatch describes why a thread matched a full-text query: its best
+// ranked document (the thread title, a post, or a control payload) with a
+// snippet whose matched terms are wrapped in <mark></mark>. Lower ranks are
+// better matches.
+type ForumSearchMatch struct {
+	Rank      float64        `json:"rank"`
+	Hits      int            `json:"hits"`
+	DocKind   string         `json:"doc_kind"`
+	ActorType ForumActorType `json:"actor_type,omitempty"`
+	ActorName string         `json:"actor_name,omitempty"`
+	Snippet   string         `json:"snippet"`
 }
 
 type ForumPost struct {
-	PostID     string         `json:"post_id"`
-	ThreadID   string         `json:"thread_id"`
-	EventID    string         `json:"event_id"`
-	AuthorType ForumActorType `json:"author_type"`
-	AuthorName string         `json:"author_name,omitempty"`
-	Body       string         `json:"body"`
-	CreatedAt  time.Time      `json:"created_at"`
+	PostID      string            `json:"post_id"`
+	ThreadID    string            `json:"thread_id"`
+	EventID     string            `json:"event_id"`
+	AuthorType  ForumActorType    `json:"author_type"`
+	AuthorName  string            `json:"author_name,omitempty"`
+	Body        string            `json:"body"`
+	Attachments []ForumAttachment `json:"attachments,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+}
+
+type ForumAttachmentKind string
+
+const (
+	ForumAttachmentSnippet       ForumAttachmentKind = "snippet"
+	ForumAttachmentDiff          ForumAttachmentKind = "diff"
+	ForumAttachmentLog           ForumAttachmentKind = "log"
+	ForumAttachmentCommandOutput ForumAttachmentKind = "command_output"
+)
+
+// ForumAttachment is typed content attached to a post. Commands and
+// forum_post_attachments carry only the metadata and the sha256 digest of the
+// content, which lives in the content-addressed attachment directory; Content
+// is set on input and when a thread is read back.
+type ForumAttachment struct {
+	Kind      ForumAttachmentKind `json:"kind"`
+	Digest    string              `json:"digest,omitempty"`
+	Size      int64               `json:"size,omitempty"`
+	Title     string              `json:"title,omitempty"`
+	Repo      string              `json:"repo,omitempty"`
+	Path      string              `json:"path,omitempty"`
+	StartLine int                 `json:"start_line,omitempty"`
+	EndLine   int                 `json:"end_line,omitempty"`
+	Command   string              `json:"command,omitempty"`
+	ExitCode  *int                `json:"exit_code,omitempty"`
+	Content   string              `json:"content,omitempty"`
 }
 
 type ForumThreadStats struct {
@@ -185,6 +258,8 @@ type ForumThreadSearchFilter struct {
 	State      ForumThreadState
 	Priority   ForumPriority
 	Assignee   string
+	ActorType  ForumActorType
+	ActorName  string
 	ViewerType ForumViewerType
 	ViewerID   string
 	Limit      int
@@ -211,3 +286,79 @@ type ForumThreadSeen struct {
 	LastSeenEventSequence int64           `json:"last_seen_event_sequence"`
 	UpdatedAt             time.Time       `json:"updated_at"`
 }
+
+// ForumProjectedEventTypes are the forum event types applied to the
+// projection tables.
+var ForumProjectedEventTypes = []string{
+	"forum.thread.opened",
+	"forum.post.added",
+	"forum.control.signal",
+	"forum.assigned",
+	"forum.state.changed",
+	"forum.priority.changed",
+	"forum.thread.closed",
+	"forum.thread_escalated",
+}
+
+const (
+	ForumProjectionThreadViews = "forum_thread_views"
+	ForumProjectionQueueView   = "forum_thread_queue_view"
+	ForumProjectionThreadStats = "forum_thread_stats"
+	// ForumProjectionSearchDocuments feeds the forum_search_index FTS5 table.
+	ForumProjectionSearchDocuments = "forum_search_documents"
+)
+
+// ForumProjections lists every rebuildable projection in apply order.
+func ForumProjections() []string {
+	return []string{ForumProjectionThreadViews, ForumProjectionQueueView, ForumProjectionThreadStats, ForumProjectionSearchDocuments}
+}
+
+type ForumProjectionDiffKind string
+
+const (
+	ForumProjectionDiffAdded   ForumProjectionDiffKind = "added"
+	ForumProjectionDiffRemoved ForumProjectionDiffKind = "removed"
+	ForumProjectionDiffChanged ForumProjectionDiffKind = "changed"
+)
+
+type ForumProjectionFieldDiff struct {
+	Field   string `json:"field"`
+	Live    string `json:"live"`
+	Rebuilt string `json:"rebuilt"`
+}
+
+// ForumProjectionDiff is one row where the rebuilt projection disagrees with
+// the live table it replaced: added rows were missing from the live table,
+// removed rows had no backing events.
+type ForumProjectionDiff struct {
+	Projection string                     `json:"projection"`
+	Key        string                     `json:"key"`
+	Kind       ForumProjectionDiffKind    `json:"kind"`
+	Fields     []ForumProjectionFieldDiff `json:"fields,omitempty"`
+}
+
+type ForumProjectionCount struct {
+	Projection  string `json:"projection"`
+	LiveRows    int    `json:"live_rows"`
+	RebuiltRows int    `json:"rebuilt_rows"`
+}
+
+type ForumProjectionRebuildReport struct {
+	Ticket         string                 `json:"ticket,omitempty"`
+	Projections    []string               `json:"projections"`
+	EventsReplayed int                    `json:"events_replayed"`
+	Counts         []ForumProjectionCount `json:"counts"`
+	Diffs          []ForumProjectionDiff  `json:"diffs"`
+	RebuiltAt      time.Time              `json:"rebuilt_at"`
+}
+
+// IntegrationRuleReceipt records that one rule of an integration delivery was
+// applied, so a retried delivery skips the rules that already ran.
+type IntegrationRuleReceipt struct {
+	EventID   string           `json:"event_id"`
+	Rule      string           `json:"rule"`
+	Action    string           `json:"action"`
+	ThreadID  string           `json:"thread_id"`
+	State     ForumThreadState `json:"state,omitempty"`
+	AppliedAt time.Time        `json:"applied_at"`
+}
@@ -0,0 +1,67 @@
+package model
+
+import (
+	"strings"
+	"time"
+	"unicode"
+)
+
+// GuidanceAnswerScope says where a library answer applies. History entries
+// are harvested from answered control threads; ticket and repo entries are
+// canned guidance an operator promoted for reuse.
+type GuidanceAnswerScope string
+
+const (
+	GuidanceAnswerScopeHistory GuidanceAnswerScope = "history"
+	GuidanceAnswerScopeTicket  GuidanceAnswerScope = "ticket"
+	GuidanceAnswerScopeRepo    GuidanceAnswerScope = "repo"
+)
+
+// GuidanceAnswerLibraryActor is the system actor that posts suggestions and
+// automatic answers; answers it posts are never harvested back.
+const GuidanceAnswerLibraryActor = "guidance-library"
+
+// GuidanceAnswer is one question/answer pair in the guidance library. Scope is
+// the ticket or repo name for canned entries and empty for history.
+type GuidanceAnswer struct {
+	AnswerID      string              `json:"answer_id"`
+	ScopeType     GuidanceAnswerScope `json:"scope_type"`
+	Scope         string              `json:"scope,omitempty"`
+	Question      string              `json:"question"`
+	Context       string              `json:"context,omitempty"`
+	Answer        string              `json:"answer"`
+	Ticket        string              `json:"ticket,omitempty"`
+	RunID         string              `json:"run_id,omitempty"`
+	AgentName     string              `json:"agent_name,omitempty"`
+	ThreadID      string              `json:"thread_id,omitempty"`
+	SourceEventID string              `json:"source_event_id,omitempty"`
+	PromotedFrom  string              `json:"promoted_from,omitempty"`
+	CreatedBy     string              `json:"created_by,omitempty"`
+	UseCount      int                 `json:"use_count"`
+	CreatedAt     time.Time           `json:"created_at"`
+	UpdatedAt     time.Time           `json:"updated_at"`
+	LastUsedAt    *time.Time          `json:"last_used_at,omitempty"`
+}
+
+type GuidanceAnswerFilter struct {
+	ScopeType GuidanceAnswerScope
+	Scope     string
+	Ticket    string
+	Limit     int
+}
+
+// GuidanceSuggestion is a library answer ranked against a new question. Exact
+// is set when the normalized questions are identical.
+type GuidanceSuggestion struct {
+	Answer GuidanceAnswer `json:"answer"`
+	Score  float64        `json:"score"`
+	Exact  bool           `json:"exact"`
+}
+
+// GuidanceQuestionKey normalizes a question for exact matching: lowercased
+// letters and digits, one space between words, punctuation dropped.
+func GuidanceQuestionKey(question string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(question), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
@@ -1,6 +1,9 @@
 package model
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type WorkspaceStrategy string
 
@@ -108,10 +111,21 @@ type RunSpec struct {
 	WorkspaceStrategy    WorkspaceStrategy `json:"workspace_strategy"`
 	Agents               []AgentSpec       `json:"agents"`
 	PolicyPath           string            `json:"policy_path"`
+	Template             string            `json:"template,omitempty"`
+	ExtraSteps           []RunTemplateStep `json:"extra_steps,omitempty"`
 	DryRun               bool              `json:"dry_run"`
 	CreatedAt            time.Time         `json:"created_at"`
 }
 
+// RunTemplateStep is a shell command a run template adds to the plan. It runs
+// once per ticket in the ticket's workspace, after the workspace is prepared
+// and before agents start. {{ticket}} and {{workspace}} are rendered per
+// ticket; every variable is substituted as a single-quoted shell word.
+type RunTemplateStep struct {
+	Name    string `json:"name"`
+	Command string `json:"command"`
+}
+
 type DocSyncState struct {
 	RunID            string        `json:"run_id"`
 	Ticket           string        `json:"ticket"`
@@ -134,6 +148,7 @@ type PlanStep struct {
 	Ticket        string     `json:"ticket,omitempty"`
 	WorkspaceName string     `json:"workspace_name,omitempty"`
 	Agent         string     `json:"agent,omitempty"`
+	DependsOn     []int      `json:"depends_on,omitempty"`
 	Status        StepStatus `json:"status"`
 }
 
@@ -155,6 +170,7 @@ type StepRecord struct {
 	Ticket        string     `json:"ticket,omitempty"`
 	WorkspaceName string     `json:"workspace_name,omitempty"`
 	Agent         string     `json:"agent,omitempty"`
+	DependsOn     []int      `json:"depends_on,omitempty"`
 	Status        StepStatus `json:"status"`
 	ErrorText     string     `json:"error_text,omitempty"`
 	StartedAt     *time.Time `json:"started_at,omitempty"`
@@ -172,6 +188,19 @@ type AgentRecord struct {
 	LastProgressAt *time.Time  `json:"last_progress_at,omitempty"`
 }
 
+// AgentTranscript indexes the on-disk terminal transcript for one agent session.
+type AgentTranscript struct {
+	RunID         string     `json:"run_id"`
+	AgentName     string     `json:"agent_name"`
+	WorkspaceName string     `json:"workspace_name"`
+	Path          string     `json:"path"`
+	Runtime       string     `json:"runtime"`
+	SizeBytes     int64      `json:"size_bytes"`
+	Rotations     int        `json:"rotations"`
+	StartedAt     *time.Time `json:"started_at,omitempty"`
+	UpdatedAt     *time.Time `json:"updated_at,omitempty"`
+}
+
 type IntakeQA struct {
 	Question string `json:"question"`
 	Answer   string `json:"answer"`
@@ -210,12 +239,72 @@ type GuidanceRequest struct {
 	AnsweredAt    *time.Time     `json:"answered_at,omitempty"`
 }
 
+// OperatorRunState is the operator's memory for one run. UnhealthyIntervals
+// counts consecutive passes that saw unhealthy agents and LastEvent is the last
+// alert raised, so repeated passes do not re-alert.
 type OperatorRunState struct {
-	RunID           string     `json:"run_id"`
-	RestartAttempts int        `json:"restart_attempts"`
-	LastRestartAt   *time.Time `json:"last_restart_at,omitempty"`
-	CooldownUntil   *time.Time `json:"cooldown_until,omitempty"`
-	UpdatedAt       time.Time  `json:"updated_at"`
+	RunID              string     `json:"run_id"`
+	RestartAttempts    int        `json:"restart_attempts"`
+	LastRestartAt      *time.Time `json:"last_restart_at,omitempty"`
+	CooldownUntil      *time.Time `json:"cooldown_until,omitempty"`
+	UnhealthyIntervals int        `json:"unhealthy_intervals"`
+	LastEvent          string     `json:"last_event,omitempty"`
+	// RuleFirings holds, per policy rule name, when the rule last decided
+	// for this run, for rule rate limits.
+	RuleFirings map[string][]time.Time `json:"rule_firings,omitempty"`
+	UpdatedAt   time.Time              `json:"updated_at"`
+}
+
+// OperatorLease grants one operator instance exclusive supervision of a run
+// until ExpiresAt.
+type OperatorLease struct {
+	RunID      string    `json:"run_id"`
+	Holder     string    `json:"holder"`
+	AcquiredAt time.Time `json:"acquired_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// OperatorDecisionRecord is one audited operator decision: what it was made
+// from, what the rules and the LLM proposed, and what happened. Inputs,
+// LLMRequest and LLMReply are stored as the JSON the operator used.
+type OperatorDecisionRecord struct {
+	ID          int64           `json:"id"`
+	RunID       string          `json:"run_id"`
+	Holder      string          `json:"holder"`
+	LLMMode     string          `json:"llm_mode"`
+	DryRun      bool            `json:"dry_run"`
+	Inputs      json.RawMessage `json:"inputs"`
+	RuleIntent  string          `json:"rule_intent"`
+	RuleReason  string          `json:"rule_reason"`
+	RuleExecute bool            `json:"rule_execute"`
+	LLMRequest  json.RawMessage `json:"llm_request,omitempty"`
+	LLMReply    json.RawMessage `json:"llm_reply,omitempty"`
+	LLMError    string          `json:"llm_error,omitempty"`
+	Intent      string          `json:"intent"`
+	Reason      string          `json:"reason"`
+	Source      string          `json:"source"`
+	Execute     bool            `json:"execute"`
+	Event       string          `json:"event"`
+	Executed    bool            `json:"executed"`
+	ActionError string          `json:"action_error,omitempty"`
+	// RepeatedCount counts later passes that reached the same outcome and so
+	// took no action; LastSeenAt is when the latest of them ran.
+	RepeatedCount int       `json:"repeated_count"`
+	LastSeenAt    time.Time `json:"last_seen_at"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// RunEvent is one row of a run's event log.
+type RunEvent struct {
+	ID         int64     `json:"id"`
+	RunID      string    `json:"run_id"`
+	EntityType string    `json:"entity_type"`
+	EntityID   string    `json:"entity_id"`
+	EventType  string    `json:"event_type"`
+	FromState  string    `json:"from_state,omitempty"`
+	ToState    string    `json:"to_state,omitempty"`
+	Message    string    `json:"message,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
 }
 
 type PullRequestState string
@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+// WebhookEventRunStatusChanged is the event type for run status transitions;
+// forum events keep their forum event type.
+const WebhookEventRunStatusChanged = "run.status_changed"
+
+// WebhookEvent is the JSON body POSTed to webhook endpoints.
+type WebhookEvent struct {
+	EventID           string        `json:"event_id"`
+	EventType         string        `json:"event_type"`
+	OccurredAt        time.Time     `json:"occurred_at"`
+	Tickets           []string      `json:"tickets,omitempty"`
+	RunID             string        `json:"run_id,omitempty"`
+	ThreadID          string        `json:"thread_id,omitempty"`
+	Priority          ForumPriority `json:"priority,omitempty"`
+	RunStatus         RunStatus     `json:"run_status,omitempty"`
+	PreviousRunStatus RunStatus     `json:"previous_run_status,omitempty"`
+	Guidance          []string      `json:"guidance,omitempty"`
+	ForumEvent        *ForumEvent   `json:"forum_event,omitempty"`
+}
+
+// WebhookDelivery is the outbox payload for one event bound for one endpoint.
+type WebhookDelivery struct {
+	Endpoint string       `json:"endpoint"`
+	Event    WebhookEvent `json:"event"`
+}
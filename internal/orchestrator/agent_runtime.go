@@ -0,0 +1,522 @@
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"metawsm/internal/policy"
+)
+
+const (
+	agentRuntimeTmux    = "tmux"
+	agentRuntimeProcess = "process"
+
+	agentLogTailBytes = 64 * 1024
+)
+
+// AgentRuntime hosts agent sessions. Sessions are addressed by the rendered
+// session name so records written by one metawsm process can be probed,
+// restarted, or stopped by another.
+type AgentRuntime interface {
+	Name() string
+	Start(ctx context.Context, spec AgentStartSpec) error
+	Stop(ctx context.Context, sessionName string) error
+	Probe(ctx context.Context, sessionName string) agentSessionState
+	LastActivity(ctx context.Context, sessionName string) *time.Time
+	ExitCode(ctx context.Context, sessionName string) (int, bool)
+	CaptureLog(ctx context.Context, sessionName string, lines int) (string, error)
+	DescribeStart(spec AgentStartSpec) string
+	DescribeStop(sessionName string) string
+}
+
+// AgentStartSpec describes one agent session launch. Command is the normalized
+// agent command; each runtime applies its own exit-status wrapper. When
+// TranscriptPath is set, session output is appended to that file. Env holds
+// extra KEY=VALUE entries for the agent process, such as its API credential.
+type AgentStartSpec struct {
+	SessionName    string
+	Workdir        string
+	Command        string
+	TranscriptPath string
+	Env            []string
+}
+
+type agentSessionState int
+
+const (
+	agentSessionUnknown agentSessionState = iota
+	agentSessionPresent
+	agentSessionMissing
+)
+
+// newAgentRuntime selects the runtime named by runtime.kind. Process sessions
+// keep their pid and log files under stateDir.
+func newAgentRuntime(cfg policy.Config, stateDir string) AgentRuntime {
+	switch normalizeAgentRuntimeKind(cfg.Runtime.Kind) {
+	case agentRuntimeProcess:
+		return &processRuntime{stateDir: stateDir}
+	default:
+		return tmuxRuntime{}
+	}
+}
+
+func normalizeAgentRuntimeKind(kind string) string {
+	switch strings.TrimSpace(strings.ToLower(kind)) {
+	case agentRuntimeProcess:
+		return agentRuntimeProcess
+	default:
+		return agentRuntimeTmux
+	}
+}
+
+func (s *Service) runtimeStateDir() string {
+	if s.store == nil || strings.TrimSpace(s.store.DBPath) == "" {
+		return filepath.Join(".metawsm", "runtime")
+	}
+	return filepath.Join(filepath.Dir(s.store.DBPath), "runtime")
+}
+
+func (s *Service) agentRuntime(cfg policy.Config) AgentRuntime {
+	return newAgentRuntime(cfg, s.runtimeStateDir())
+}
+
+// runAgentRuntime resolves the runtime a run was started with. Runs recorded
+// before runtime.kind existed were always hosted in tmux; runs without a stored
+// policy fall back to the current policy file.
+func (s *Service) runAgentRuntime(policyJSON string) AgentRuntime {
+	if strings.TrimSpace(policyJSON) == "" {
+		return s.agentRuntime(loadPolicyOrDefault())
+	}
+	var stored struct {
+		Runtime struct {
+			Kind string `json:"kind"`
+		} `json:"runtime"`
+	}
+	_ = json.Unmarshal([]byte(policyJSON), &stored)
+	cfg := policy.Default()
+	cfg.Runtime.Kind = stored.Runtime.Kind
+	return s.agentRuntime(cfg)
+}
+
+// AgentSessionEvidence is a point-in-time view of one agent session as seen by
+// the run's runtime.
+type AgentSessionEvidence struct {
+	Session      string     `json:"session"`
+	HasSession   bool       `json:"has_session"`
+	LastActivity *time.Time `json:"last_activity,omitempty"`
+	ExitCode     *int       `json:"exit_code,omitempty"`
+}
+
+// ProbeAgentSession reports session evidence through the runtime the run was
+// started with.
+func (s *Service) ProbeAgentSession(ctx context.Context, runID string, sessionName string) (AgentSessionEvidence, error) {
+	_, _, policyJSON, err := s.store.GetRun(runID)
+	if err != nil {
+		return AgentSessionEvidence{}, err
+	}
+	rt := s.runAgentRuntime(policyJSON)
+	evidence := AgentSessionEvidence{Session: sessionName}
+	if rt.Probe(ctx, sessionName) != agentSessionPresent {
+		return evidence, nil
+	}
+	evidence.HasSession = true
+	evidence.LastActivity = rt.LastActivity(ctx, sessionName)
+	if exitCode, ok := rt.ExitCode(ctx, sessionName); ok {
+		evidence.ExitCode = &exitCode
+	}
+	return evidence, nil
+}
+
+// startAgentSession launches a session and waits briefly for it to either
+// settle or report an early exit.
+func startAgentSession(ctx context.Context, rt AgentRuntime, spec AgentStartSpec) error {
+	_ = rt.Stop(ctx, spec.SessionName)
+	if err := rt.Start(ctx, spec); err != nil {
+		return err
+	}
+	time.Sleep(200 * time.Millisecond)
+	if rt.Probe(ctx, spec.SessionName) == agentSessionMissing {
+		return fmt.Errorf("%s session %s exited immediately after start", rt.Name(), spec.SessionName)
+	}
+	return waitForAgentStartup(ctx, rt, spec.SessionName, 4*time.Second)
+}
+
+type tmuxRuntime struct{}
+
+func (tmuxRuntime) Name() string {
+	return agentRuntimeTmux
+}
+
+func (r tmuxRuntime) Start(ctx context.Context, spec AgentStartSpec) error {
+	envFile, err := writeAgentSecretEnvFile(spec.Env)
+	if err != nil {
+		return err
+	}
+	if err := runShell(ctx, tmuxNewSessionCommand(spec, envFile)); err != nil {
+		if envFile != "" {
+			_ = os.Remove(envFile)
+		}
+		return err
+	}
+	if strings.TrimSpace(spec.TranscriptPath) == "" {
+		return nil
+	}
+	pipeCmd := fmt.Sprintf("tmux pipe-pane -t %s %s", shellQuote(spec.SessionName), shellQuote("cat >> "+shellQuote(spec.TranscriptPath)))
+	if err := runShell(ctx, pipeCmd); err != nil {
+		return fmt.Errorf("capture transcript for tmux session %s: %w", spec.SessionName, err)
+	}
+	return nil
+}
+
+func (tmuxRuntime) Stop(ctx context.Context, sessionName string) error {
+	return tmuxKillSession(ctx, sessionName)
+}
+
+func (tmuxRuntime) Probe(ctx context.Context, sessionName string) agentSessionState {
+	return tmuxSessionProbe(ctx, sessionName)
+}
+
+func (tmuxRuntime) LastActivity(ctx context.Context, sessionName string) *time.Time {
+	epoch := fetchSessionActivity(ctx, sessionName)
+	if epoch <= 0 {
+		return nil
+	}
+	t := time.Unix(epoch, 0)
+	return &t
+}
+
+func (tmuxRuntime) ExitCode(ctx context.Context, sessionName string) (int, bool) {
+	return readAgentExitCode(ctx, sessionName)
+}
+
+func (tmuxRuntime) CaptureLog(ctx context.Context, sessionName string, lines int) (string, error) {
+	if lines <= 0 {
+		lines = 200
+	}
+	cmd := exec.CommandContext(ctx, "zsh", "-lc", fmt.Sprintf("tmux capture-pane -p -t %s:0 | tail -n %d", shellQuote(sessionName), lines))
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("capture tmux pane %s: %w", sessionName, err)
+	}
+	return string(out), nil
+}
+
+func (tmuxRuntime) DescribeStart(spec AgentStartSpec) string {
+	envFile := ""
+	if hasAgentSecretEnv(spec.Env) {
+		envFile = "<agent-env-file>"
+	}
+	return tmuxNewSessionCommand(spec, envFile)
+}
+
+// tmuxNewSessionCommand renders the new-session call. Secret env entries never
+// go on the command line, where ps and shell history would expose them; the
+// session sources them from envFile and deletes it before running the agent.
+func tmuxNewSessionCommand(spec AgentStartSpec, envFile string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "tmux new-session -d -s %s -c %s", shellQuote(spec.SessionName), shellQuote(spec.Workdir))
+	for _, entry := range spec.Env {
+		if isAgentSecretEnvEntry(entry) {
+			continue
+		}
+		fmt.Fprintf(&b, " -e %s", shellQuote(entry))
+	}
+	command := strings.TrimSpace(spec.Command)
+	if envFile != "" {
+		if command == "" {
+			command = "bash"
+		}
+		command = fmt.Sprintf(". %s; rm -f %s; %s", shellQuote(envFile), shellQuote(envFile), command)
+	}
+	fmt.Fprintf(&b, " %s", shellQuote(wrapAgentCommandForTmux(command)))
+	return b.String()
+}
+
+func isAgentSecretEnvEntry(entry string) bool {
+	key, _, found := strings.Cut(entry, "=")
+	return found && key == agentTokenEnv
+}
+
+func hasAgentSecretEnv(env []string) bool {
+	for _, entry := range env {
+		if isAgentSecretEnvEntry(entry) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeAgentSecretEnvFile stores the secret env entries as export lines in a
+// private (0600) temp file and returns its path, or "" when there are none.
+func writeAgentSecretEnvFile(env []string) (string, error) {
+	var b strings.Builder
+	for _, entry := range env {
+		if !isAgentSecretEnvEntry(entry) {
+			continue
+		}
+		key, value, _ := strings.Cut(entry, "=")
+		fmt.Fprintf(&b, "export %s=%s\n", key, shellQuote(value))
+	}
+	if b.Len() == 0 {
+		return "", nil
+	}
+	file, err := os.CreateTemp("", "metawsm-agent-env-*")
+	if err != nil {
+		return "", fmt.Errorf("create agent env file: %w", err)
+	}
+	path := file.Name()
+	err = file.Chmod(0o600)
+	if err == nil {
+		_, err = file.WriteString(b.String())
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		return "", fmt.Errorf("write agent env file: %w", err)
+	}
+	return path, nil
+}
+
+func (tmuxRuntime) DescribeStop(sessionName string) string {
+	return fmt.Sprintf("tmux kill-session -t %s", shellQuote(sessionName))
+}
+
+// processRuntime runs each agent as a detached process group for hosts without
+// tmux. Output is recorded through script(1) when available so agents still see
+// a PTY; otherwise stdout and stderr are appended to the log file directly. The
+// session's agent.log links to the transcript file when one is requested.
+type processRuntime struct {
+	stateDir string
+}
+
+var processSessionDirRegex = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+func (r *processRuntime) Name() string {
+	return agentRuntimeProcess
+}
+
+func (r *processRuntime) sessionDir(sessionName string) string {
+	name := processSessionDirRegex.ReplaceAllString(strings.TrimSpace(sessionName), "_")
+	if name == "" {
+		name = "_"
+	}
+	return filepath.Join(r.stateDir, name)
+}
+
+func (r *processRuntime) pidPath(sessionName string) string {
+	return filepath.Join(r.sessionDir(sessionName), "pid")
+}
+
+func (r *processRuntime) logPath(sessionName string) string {
+	return filepath.Join(r.sessionDir(sessionName), "agent.log")
+}
+
+func (r *processRuntime) Start(ctx context.Context, spec AgentStartSpec) error {
+	dir := r.sessionDir(spec.SessionName)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("create runtime dir %s: %w", dir, err)
+	}
+	logPath := r.logPath(spec.SessionName)
+	if transcriptPath := strings.TrimSpace(spec.TranscriptPath); transcriptPath != "" {
+		absTranscript, err := filepath.Abs(transcriptPath)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(absTranscript), 0o755); err != nil {
+			return fmt.Errorf("create transcript dir: %w", err)
+		}
+		if err := os.Remove(logPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("replace agent log %s: %w", logPath, err)
+		}
+		if err := os.Symlink(absTranscript, logPath); err != nil {
+			return fmt.Errorf("link agent log %s: %w", logPath, err)
+		}
+		logPath = absTranscript
+	}
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("open agent log %s: %w", logPath, err)
+	}
+	defer logFile.Close()
+
+	script := wrapAgentCommandForProcess(spec.Command)
+	// The session must outlive the caller, so it is not bound to ctx.
+	var cmd *exec.Cmd
+	if argv, ok := processPTYArgs(script, logPath); ok {
+		cmd = exec.Command(argv[0], argv[1:]...)
+	} else {
+		cmd = exec.Command("bash", "-lc", script)
+		cmd.Stdout = logFile
+		cmd.Stderr = logFile
+	}
+	cmd.Dir = spec.Workdir
+	if len(spec.Env) > 0 {
+		cmd.Env = append(os.Environ(), spec.Env...)
+	}
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("start %s session %s: %w", r.Name(), spec.SessionName, err)
+	}
+	if err := os.WriteFile(r.pidPath(spec.SessionName), []byte(strconv.Itoa(cmd.Process.Pid)+"\n"), 0o644); err != nil {
+		_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+		return fmt.Errorf("record pid for session %s: %w", spec.SessionName, err)
+	}
+	// Reap the child so a long-lived caller does not accumulate zombies that
+	// would otherwise keep answering liveness probes.
+	go func() {
+		_ = cmd.Wait()
+	}()
+	return nil
+}
+
+func processPTYArgs(script string, logPath string) ([]string, bool) {
+	path, err := exec.LookPath("script")
+	if err != nil {
+		return nil, false
+	}
+	command := "bash -lc " + shellQuote(script)
+	switch runtime.GOOS {
+	case "linux":
+		return []string{path, "-q", "-e", "-f", "-a", "-c", command, logPath}, true
+	case "darwin", "freebsd":
+		return []string{path, "-q", "-a", "-F", logPath, "bash", "-lc", script}, true
+	default:
+		return nil, false
+	}
+}
+
+func (r *processRuntime) Stop(ctx context.Context, sessionName string) error {
+	pid, ok := r.readPID(sessionName)
+	if !ok {
+		return nil
+	}
+	if err := syscall.Kill(-pid, syscall.SIGTERM); err != nil && !errors.Is(err, syscall.ESRCH) {
+		return fmt.Errorf("stop %s session %s: %w", r.Name(), sessionName, err)
+	}
+	// script(1) takes about two seconds to wind down its child after SIGTERM.
+	deadline := time.Now().Add(3 * time.Second)
+	for processAlive(pid) && time.Now().Before(deadline) {
+		time.Sleep(50 * time.Millisecond)
+	}
+	if processAlive(pid) {
+		_ = syscall.Kill(-pid, syscall.SIGKILL)
+	}
+	if err := os.Remove(r.pidPath(sessionName)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Probe treats a session whose command already reported an exit status as
+// present, matching tmux sessions that stay open after the agent returns.
+func (r *processRuntime) Probe(ctx context.Context, sessionName string) agentSessionState {
+	pid, ok := r.readPID(sessionName)
+	if !ok {
+		return agentSessionMissing
+	}
+	if processAlive(pid) {
+		return agentSessionPresent
+	}
+	if _, found := r.ExitCode(ctx, sessionName); found {
+		return agentSessionPresent
+	}
+	return agentSessionMissing
+}
+
+func (r *processRuntime) LastActivity(ctx context.Context, sessionName string) *time.Time {
+	info, err := os.Stat(r.logPath(sessionName))
+	if err != nil {
+		return nil
+	}
+	t := info.ModTime()
+	return &t
+}
+
+func (r *processRuntime) ExitCode(ctx context.Context, sessionName string) (int, bool) {
+	out, err := r.CaptureLog(ctx, sessionName, 200)
+	if err != nil {
+		return 0, false
+	}
+	return parseAgentExitCode(out)
+}
+
+func (r *processRuntime) CaptureLog(ctx context.Context, sessionName string, lines int) (string, error) {
+	if lines <= 0 {
+		lines = 200
+	}
+	file, err := os.Open(r.logPath(sessionName))
+	if err != nil {
+		return "", fmt.Errorf("open agent log for %s: %w", sessionName, err)
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return "", err
+	}
+	offset := info.Size() - agentLogTailBytes
+	if offset < 0 {
+		offset = 0
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return "", err
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return "", err
+	}
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	all := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	if len(all) > lines {
+		all = all[len(all)-lines:]
+	}
+	return strings.Join(all, "\n") + "\n", nil
+}
+
+func (r *processRuntime) DescribeStart(spec AgentStartSpec) string {
+	return fmt.Sprintf("process start %s in %s (log %s): %s", shellQuote(spec.SessionName), shellQuote(spec.Workdir), r.logPath(spec.SessionName), spec.Command)
+}
+
+func (r *processRuntime) DescribeStop(sessionName string) string {
+	return fmt.Sprintf("process stop %s", shellQuote(sessionName))
+}
+
+func (r *processRuntime) readPID(sessionName string) (int, bool) {
+	data, err := os.ReadFile(r.pidPath(sessionName))
+	if err != nil {
+		return 0, false
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return 0, false
+	}
+	return pid, true
+}
+
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// wrapAgentCommandForProcess records the same exit marker as the tmux wrapper
+// but lets the process end, since there is no pane to keep open.
+func wrapAgentCommandForProcess(command string) string {
+	command = strings.TrimSpace(command)
+	if command == "" {
+		command = "bash"
+	}
+	return command + "; status=$?; printf '[metawsm] agent command exited with status %s at %s\\n' \"$status\" \"$(date -Iseconds)\"; exit \"$status\""
+}
@@ -0,0 +1,253 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"metawsm/internal/model"
+	"metawsm/internal/policy"
+)
+
+func TestProcessRuntimeCapturesLogAndExitCode(t *testing.T) {
+	rt := &processRuntime{stateDir: t.TempDir()}
+	ctx := context.Background()
+	spec := AgentStartSpec{
+		SessionName:    "agent-ws-exit",
+		Workdir:        t.TempDir(),
+		Command:        "echo hello-from-agent; (exit 3)",
+		TranscriptPath: filepath.Join(t.TempDir(), "runs", "run-1", "agent-ws.log"),
+	}
+	if err := rt.Start(ctx, spec); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	t.Cleanup(func() { _ = rt.Stop(context.Background(), spec.SessionName) })
+
+	exitCode, found := waitForProcessExit(t, rt, spec.SessionName)
+	if !found || exitCode != 3 {
+		t.Fatalf("expected exit code 3, got %d (found=%t)", exitCode, found)
+	}
+	if state := rt.Probe(ctx, spec.SessionName); state != agentSessionPresent {
+		t.Fatalf("expected exited session to remain present until stopped, got %v", state)
+	}
+	logText, err := rt.CaptureLog(ctx, spec.SessionName, 50)
+	if err != nil {
+		t.Fatalf("capture log: %v", err)
+	}
+	if !strings.Contains(logText, "hello-from-agent") {
+		t.Fatalf("expected agent output in log, got %q", logText)
+	}
+	transcript, err := os.ReadFile(spec.TranscriptPath)
+	if err != nil || !strings.Contains(string(transcript), "hello-from-agent") {
+		t.Fatalf("expected agent output in transcript, got %q (%v)", string(transcript), err)
+	}
+	if rt.LastActivity(ctx, spec.SessionName) == nil {
+		t.Fatalf("expected last activity from log mtime")
+	}
+
+	if err := rt.Stop(ctx, spec.SessionName); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	if state := rt.Probe(ctx, spec.SessionName); state != agentSessionMissing {
+		t.Fatalf("expected stopped session to be missing, got %v", state)
+	}
+}
+
+func TestProcessRuntimeStopTerminatesRunningSession(t *testing.T) {
+	rt := &processRuntime{stateDir: t.TempDir()}
+	ctx := context.Background()
+	spec := AgentStartSpec{
+		SessionName: "agent-ws-sleep",
+		Workdir:     t.TempDir(),
+		Command:     "sleep 30",
+	}
+	if err := rt.Start(ctx, spec); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	pid, ok := rt.readPID(spec.SessionName)
+	if !ok {
+		t.Fatalf("expected pid file for running session")
+	}
+	if state := rt.Probe(ctx, spec.SessionName); state != agentSessionPresent {
+		t.Fatalf("expected running session, got %v", state)
+	}
+	if _, found := rt.ExitCode(ctx, spec.SessionName); found {
+		t.Fatalf("did not expect exit code for running session")
+	}
+
+	if err := rt.Stop(ctx, spec.SessionName); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	deadline := time.Now().Add(3 * time.Second)
+	for processAlive(pid) && time.Now().Before(deadline) {
+		time.Sleep(50 * time.Millisecond)
+	}
+	if processAlive(pid) {
+		t.Fatalf("expected process %d to be terminated", pid)
+	}
+	if state := rt.Probe(ctx, spec.SessionName); state != agentSessionMissing {
+		t.Fatalf("expected stopped session to be missing, got %v", state)
+	}
+}
+
+func TestProcessRuntimePassesAgentEnv(t *testing.T) {
+	rt := &processRuntime{stateDir: t.TempDir()}
+	ctx := context.Background()
+	spec := AgentStartSpec{
+		SessionName: "agent-ws-env",
+		Workdir:     t.TempDir(),
+		Command:     `echo "run=$METAWSM_RUN_ID"`,
+		Env:         []string{agentRunIDEnv + "=run-env"},
+	}
+	if err := rt.Start(ctx, spec); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	t.Cleanup(func() { _ = rt.Stop(context.Background(), spec.SessionName) })
+
+	if _, found := waitForProcessExit(t, rt, spec.SessionName); !found {
+		t.Fatalf("expected session to exit")
+	}
+	logText, err := rt.CaptureLog(ctx, spec.SessionName, 50)
+	if err != nil {
+		t.Fatalf("capture log: %v", err)
+	}
+	if !strings.Contains(logText, "run=run-env") {
+		t.Fatalf("expected injected env in agent output, got %q", logText)
+	}
+}
+
+func TestTmuxStartKeepsAgentTokenOffCommandLine(t *testing.T) {
+	spec := AgentStartSpec{
+		SessionName: "agent-ws",
+		Workdir:     "/tmp/ws",
+		Command:     "bash",
+		Env:         []string{agentTokenEnv + "=mwt_secret", agentRunIDEnv + "=run-1"},
+	}
+	described := tmuxRuntime{}.DescribeStart(spec)
+	if strings.Contains(described, "mwt_secret") || !strings.Contains(described, "<agent-env-file>") {
+		t.Fatalf("expected token to be read from an env file, got %q", described)
+	}
+	if !strings.Contains(described, "-e "+shellQuote(agentRunIDEnv+"=run-1")) {
+		t.Fatalf("expected run env in described command, got %q", described)
+	}
+
+	envFile, err := writeAgentSecretEnvFile(spec.Env)
+	if err != nil {
+		t.Fatalf("write env file: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Remove(envFile) })
+	info, err := os.Stat(envFile)
+	if err != nil {
+		t.Fatalf("stat env file: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Fatalf("expected env file mode 0600, got %o", perm)
+	}
+	actual := tmuxNewSessionCommand(spec, envFile)
+	if strings.Contains(actual, "mwt_secret") || strings.Contains(actual, "-e "+shellQuote(agentTokenEnv)) {
+		t.Fatalf("expected start command without the token, got %q", actual)
+	}
+	if !strings.Contains(actual, envFile) {
+		t.Fatalf("expected start command to source %s, got %q", envFile, actual)
+	}
+
+	out, err := exec.Command("bash", "-c", fmt.Sprintf(`. %s; rm -f %s; printf %%s "$%s"`, shellQuote(envFile), shellQuote(envFile), agentTokenEnv)).Output()
+	if err != nil {
+		t.Fatalf("source env file: %v", err)
+	}
+	if string(out) != "mwt_secret" {
+		t.Fatalf("expected sourced token, got %q", out)
+	}
+	if _, err := os.Stat(envFile); !os.IsNotExist(err) {
+		t.Fatalf("expected env file to be removed after sourcing, got %v", err)
+	}
+}
+
+func TestWriteAgentSecretEnvFileSkipsWithoutSecrets(t *testing.T) {
+	envFile, err := writeAgentSecretEnvFile([]string{agentRunIDEnv + "=run-1"})
+	if err != nil || envFile != "" {
+		t.Fatalf("expected no env file without secrets, got %q, %v", envFile, err)
+	}
+}
+
+func TestEvaluateHealthUsesRuntimeEvidence(t *testing.T) {
+	cfg := policy.Default()
+	now := time.Now()
+	agent := model.AgentRecord{Name: "agent", SessionName: "agent-ws", Status: model.AgentStatusRunning, HealthState: model.HealthStateHealthy}
+
+	health, status, _, _ := evaluateHealth(context.Background(), cfg, &fakeAgentRuntime{state: agentSessionMissing}, agent, now)
+	if health != model.HealthStateDead || status != model.AgentStatusDead {
+		t.Fatalf("expected dead agent for missing session, got %s/%s", health, status)
+	}
+
+	health, status, _, _ = evaluateHealth(context.Background(), cfg, &fakeAgentRuntime{state: agentSessionPresent, exitCode: 2, exited: true}, agent, now)
+	if health != model.HealthStateDead || status != model.AgentStatusFailed {
+		t.Fatalf("expected failed agent for non-zero exit, got %s/%s", health, status)
+	}
+
+	recent := now.Add(-10 * time.Second)
+	health, status, lastActivity, _ := evaluateHealth(context.Background(), cfg, &fakeAgentRuntime{state: agentSessionPresent, activity: &recent}, agent, now)
+	if health != model.HealthStateHealthy || status != model.AgentStatusRunning {
+		t.Fatalf("expected healthy agent with recent activity, got %s/%s", health, status)
+	}
+	if lastActivity == nil || !lastActivity.Equal(recent) {
+		t.Fatalf("expected runtime activity timestamp, got %v", lastActivity)
+	}
+}
+
+func TestNewAgentRuntimeSelectsPolicyKind(t *testing.T) {
+	cfg := policy.Default()
+	if name := newAgentRuntime(cfg, t.TempDir()).Name(); name != "tmux" {
+		t.Fatalf("expected tmux default runtime, got %s", name)
+	}
+	cfg.Runtime.Kind = "process"
+	if name := newAgentRuntime(cfg, t.TempDir()).Name(); name != "process" {
+		t.Fatalf("expected process runtime, got %s", name)
+	}
+}
+
+func waitForProcessExit(t *testing.T, rt *processRuntime, sessionName string) (int, bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if code, found := rt.ExitCode(context.Background(), sessionName); found {
+			return code, true
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	return 0, false
+}
+
+type fakeAgentRuntime struct {
+	state    agentSessionState
+	activity *time.Time
+	exitCode int
+	exited   bool
+}
+
+func (f *fakeAgentRuntime) Name() string { return "fake" }
+
+func (f *fakeAgentRuntime) Start(context.Context, AgentStartSpec) error { return nil }
+
+func (f *fakeAgentRuntime) Stop(context.Context, string) error { return nil }
+
+func (f *fakeAgentRuntime) Probe(context.Context, string) agentSessionState { return f.state }
+
+func (f *fakeAgentRuntime) LastActivity(context.Context, string) *time.Time { return f.activity }
+
+func (f *fakeAgentRuntime) ExitCode(context.Context, string) (int, bool) {
+	return f.exitCode, f.exited
+}
+
+func (f *fakeAgentRuntime) CaptureLog(context.Context, string, int) (string, error) { return "", nil }
+
+func (f *fakeAgentRuntime) DescribeStart(spec AgentStartSpec) string {
+	return "start " + spec.SessionName
+}
+
+func (f *fakeAgentRuntime) DescribeStop(sessionName string) string { return "stop " + sessionName }
@@ -0,0 +1,357 @@
+package orchestrator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+)
+
+// OperatorIntent is an action the operator can propose for a run.
+type OperatorIntent string
+
+const (
+	OperatorIntentNoop                OperatorIntent = "noop"
+	OperatorIntentEscalateGuidance    OperatorIntent = "escalate_guidance"
+	OperatorIntentEscalateBlocked     OperatorIntent = "escalate_blocked"
+	OperatorIntentAutoRestart         OperatorIntent = "auto_restart"
+	OperatorIntentAutoStopStale       OperatorIntent = "auto_stop_stale"
+	OperatorIntentCommitReady         OperatorIntent = "commit_ready"
+	OperatorIntentPRReady             OperatorIntent = "pr_ready"
+	OperatorIntentReviewFeedbackReady OperatorIntent = "review_feedback_ready"
+)
+
+type operatorRuleDecision struct {
+	Intent  OperatorIntent
+	Reason  string
+	Execute bool
+	// Rule names the policy rule that decided; empty for built-in rules.
+	Rule string
+}
+
+type operatorMergedDecision struct {
+	Intent   OperatorIntent
+	Reason   string
+	Source   string
+	Execute  bool
+	LLMReply *OperatorLLMResponse
+}
+
+type operatorLLMRequest struct {
+	RunID           string                      `json:"run_id"`
+	RunStatus       string                      `json:"run_status"`
+	Tickets         string                      `json:"tickets"`
+	HasGuidance     bool                        `json:"has_guidance"`
+	HasUnhealthy    bool                        `json:"has_unhealthy"`
+	RuleIntent      OperatorIntent              `json:"rule_intent"`
+	RuleReason      string                      `json:"rule_reason"`
+	UnhealthyAgents []RunUnhealthyAgentSnapshot `json:"unhealthy_agents"`
+}
+
+// OperatorLLMResponse is the proposal an LLM adapter returns for one run.
+type OperatorLLMResponse struct {
+	Intent     OperatorIntent `json:"intent"`
+	TargetRun  string         `json:"target_run"`
+	Reason     string         `json:"reason"`
+	Confidence float64        `json:"confidence"`
+	NeedsHuman bool           `json:"needs_human"`
+}
+
+type operatorLLMAdapter interface {
+	Propose(ctx context.Context, req operatorLLMRequest) (OperatorLLMResponse, error)
+}
+
+type operatorCommandRunner func(ctx context.Context, name string, args []string, stdin string) (stdout string, stderr string, err error)
+
+type codexCLIAdapter struct {
+	command   string
+	model     string
+	maxTokens int
+	timeout   time.Duration
+	runner    operatorCommandRunner
+}
+
+func newCodexCLIAdapter(command string, model string, maxTokens int, timeout time.Duration, runner operatorCommandRunner) operatorLLMAdapter {
+	if runner == nil {
+		runner = runOperatorCommand
+	}
+	return &codexCLIAdapter{
+		command:   strings.TrimSpace(command),
+		model:     strings.TrimSpace(model),
+		maxTokens: maxTokens,
+		timeout:   timeout,
+		runner:    runner,
+	}
+}
+
+func (c *codexCLIAdapter) Propose(ctx context.Context, req operatorLLMRequest) (OperatorLLMResponse, error) {
+	if c.command == "" {
+		return OperatorLLMResponse{}, fmt.Errorf("codex command is empty")
+	}
+	if c.timeout <= 0 {
+		c.timeout = 30 * time.Second
+	}
+
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return OperatorLLMResponse{}, fmt.Errorf("marshal operator llm request: %w", err)
+	}
+	prompt := strings.Join([]string{
+		operatorLLMInstructions(),
+		"Context JSON:",
+		string(payload),
+	}, "\n")
+
+	args := []string{"exec"}
+	if c.model != "" {
+		args = append(args, "--model", c.model)
+	}
+	args = append(args, prompt)
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	stdout, stderr, err := c.runner(timeoutCtx, c.command, args, "")
+	if err != nil {
+		return OperatorLLMResponse{}, fmt.Errorf("codex exec failed: %w: %s", err, strings.TrimSpace(stderr))
+	}
+
+	return decodeOperatorLLMResponse(stdout, req.RunID)
+}
+
+func runOperatorCommand(ctx context.Context, name string, args []string, stdin string) (string, string, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	if strings.TrimSpace(stdin) != "" {
+		cmd.Stdin = strings.NewReader(stdin)
+	}
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	return stdout.String(), stderr.String(), err
+}
+
+// operatorIntents is every intent an adapter may propose.
+var operatorIntents = []OperatorIntent{
+	OperatorIntentNoop,
+	OperatorIntentEscalateGuidance,
+	OperatorIntentEscalateBlocked,
+	OperatorIntentAutoRestart,
+	OperatorIntentAutoStopStale,
+	OperatorIntentCommitReady,
+	OperatorIntentPRReady,
+	OperatorIntentReviewFeedbackReady,
+}
+
+// operatorLLMResponseSchema is the JSON schema every adapter's output is
+// validated against. HTTP providers receive it as a structured-output format;
+// the codex prompt quotes it.
+var operatorLLMResponseSchema = map[string]any{
+	"type":                 "object",
+	"additionalProperties": false,
+	"required":             []any{"intent", "reason"},
+	"properties": map[string]any{
+		"intent":      map[string]any{"type": "string", "enum": operatorIntentEnum()},
+		"target_run":  map[string]any{"type": "string"},
+		"reason":      map[string]any{"type": "string"},
+		"confidence":  map[string]any{"type": "number", "minimum": 0.0, "maximum": 1.0},
+		"needs_human": map[string]any{"type": "boolean"},
+	},
+}
+
+func operatorIntentEnum() []any {
+	values := make([]any, 0, len(operatorIntents))
+	for _, intent := range operatorIntents {
+		values = append(values, string(intent))
+	}
+	return values
+}
+
+// operatorLLMInstructions is the system prompt shared by all adapters.
+func operatorLLMInstructions() string {
+	schema, _ := json.Marshal(operatorLLMResponseSchema)
+	return strings.Join([]string{
+		"You are an operator assistant for metawsm.",
+		"Return a single JSON object matching this JSON schema:",
+		string(schema),
+		"Do not include markdown.",
+	}, "\n")
+}
+
+// decodeOperatorLLMResponse extracts the JSON object from adapter output and
+// validates it against operatorLLMResponseSchema. A missing target_run
+// defaults to runID; a different one is rejected.
+func decodeOperatorLLMResponse(output string, runID string) (OperatorLLMResponse, error) {
+	jsonObject, ok := extractJSONObject([]byte(output))
+	if !ok {
+		return OperatorLLMResponse{}, fmt.Errorf("no JSON object found in llm output")
+	}
+	var raw any
+	if err := json.Unmarshal(jsonObject, &raw); err != nil {
+		return OperatorLLMResponse{}, fmt.Errorf("parse llm response json: %w", err)
+	}
+	if err := validateJSONSchema(operatorLLMResponseSchema, raw, "$"); err != nil {
+		return OperatorLLMResponse{}, fmt.Errorf("llm response does not match schema: %w", err)
+	}
+	var response OperatorLLMResponse
+	if err := json.Unmarshal(jsonObject, &response); err != nil {
+		return OperatorLLMResponse{}, fmt.Errorf("parse llm response json: %w", err)
+	}
+	runID = strings.TrimSpace(runID)
+	if response.TargetRun == "" {
+		response.TargetRun = runID
+	}
+	if runID != "" && response.TargetRun != runID {
+		return OperatorLLMResponse{}, fmt.Errorf("llm response targets run %s, expected %s", response.TargetRun, runID)
+	}
+	return response, nil
+}
+
+// validateJSONSchema checks value against the subset of JSON schema the
+// operator uses: type, enum, required, properties, additionalProperties=false,
+// minimum and maximum.
+func validateJSONSchema(schema map[string]any, value any, path string) error {
+	if expected, ok := schema["type"].(string); ok && !jsonSchemaTypeMatches(expected, value) {
+		return fmt.Errorf("%s must be %s", path, expected)
+	}
+	if enum, ok := schema["enum"].([]any); ok {
+		found := false
+		for _, allowed := range enum {
+			if allowed == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%s value %v is not allowed", path, value)
+		}
+	}
+	if number, ok := value.(float64); ok {
+		if minimum, ok := schema["minimum"].(float64); ok && number < minimum {
+			return fmt.Errorf("%s must be >= %v", path, minimum)
+		}
+		if maximum, ok := schema["maximum"].(float64); ok && number > maximum {
+			return fmt.Errorf("%s must be <= %v", path, maximum)
+		}
+	}
+	object, ok := value.(map[string]any)
+	if !ok {
+		return nil
+	}
+	if required, ok := schema["required"].([]any); ok {
+		for _, key := range required {
+			if _, present := object[fmt.Sprint(key)]; !present {
+				return fmt.Errorf("%s.%v is required", path, key)
+			}
+		}
+	}
+	properties, _ := schema["properties"].(map[string]any)
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		property, known := properties[key].(map[string]any)
+		if !known {
+			if additional, ok := schema["additionalProperties"].(bool); ok && !additional {
+				return fmt.Errorf("%s.%s is not allowed", path, key)
+			}
+			continue
+		}
+		if err := validateJSONSchema(property, object[key], path+"."+key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func jsonSchemaTypeMatches(expected string, value any) bool {
+	switch expected {
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		number, ok := value.(float64)
+		return ok && number == float64(int64(number))
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "null":
+		return value == nil
+	default:
+		return true
+	}
+}
+
+func mergeOperatorDecisions(mode string, rule operatorRuleDecision, llm *OperatorLLMResponse) operatorMergedDecision {
+	decision := operatorMergedDecision{
+		Intent:  rule.Intent,
+		Reason:  rule.Reason,
+		Source:  "rule",
+		Execute: rule.Execute,
+	}
+
+	if strings.EqualFold(mode, "assist") {
+		decision.Execute = false
+		if llm != nil && llm.Intent != "" && llm.Intent != rule.Intent {
+			decision.Reason = strings.TrimSpace(decision.Reason + "; llm_suggested=" + string(llm.Intent) + " reason=" + llm.Reason)
+			decision.LLMReply = llm
+		}
+		return decision
+	}
+
+	if strings.EqualFold(mode, "off") || llm == nil || llm.Intent == "" {
+		return decision
+	}
+
+	decision.LLMReply = llm
+	if rule.Intent == OperatorIntentEscalateGuidance {
+		decision.Execute = false
+		return decision
+	}
+
+	if strings.EqualFold(mode, "auto") {
+		if rule.Intent == OperatorIntentNoop && llm.Intent == OperatorIntentEscalateBlocked {
+			decision.Intent = llm.Intent
+			decision.Reason = strings.TrimSpace(llm.Reason)
+			decision.Source = "llm"
+			decision.Execute = false
+			return decision
+		}
+		if llm.Intent != rule.Intent {
+			decision.Reason = strings.TrimSpace(decision.Reason + "; llm proposal rejected by policy gate")
+		}
+	}
+	return decision
+}
+
+func extractJSONObject(output []byte) ([]byte, bool) {
+	text := string(output)
+	start := strings.Index(text, "{")
+	end := strings.LastIndex(text, "}")
+	if start == -1 || end == -1 || end < start {
+		return nil, false
+	}
+	for i := end; i > start; i-- {
+		candidate := strings.TrimSpace(text[start : i+1])
+		var tmp map[string]any
+		if err := json.Unmarshal([]byte(candidate), &tmp); err == nil {
+			return []byte(candidate), true
+		}
+	}
+	return nil, false
+}
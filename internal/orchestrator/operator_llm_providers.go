@@ -0,0 +1,260 @@
+package orchestrator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"metawsm/internal/policy"
+)
+
+type operatorLLMFactory func(cfg policy.Config) (operatorLLMAdapter, error)
+
+// operatorLLMProviders maps operator.llm.provider to the adapter it builds.
+var operatorLLMProviders = map[string]operatorLLMFactory{
+	"codex": func(cfg policy.Config) (operatorLLMAdapter, error) {
+		return newCodexCLIAdapter(
+			cfg.Operator.LLM.Command,
+			cfg.Operator.LLM.Model,
+			cfg.Operator.LLM.MaxTokens,
+			operatorLLMTimeout(cfg),
+			nil,
+		), nil
+	},
+	"http":     newHTTPChatAdapterFromPolicy,
+	"scripted": newScriptedLLMAdapterFromPolicy,
+}
+
+func newOperatorLLMAdapter(cfg policy.Config) (operatorLLMAdapter, error) {
+	provider := strings.TrimSpace(strings.ToLower(cfg.Operator.LLM.Provider))
+	if provider == "" {
+		provider = "codex"
+	}
+	factory, ok := operatorLLMProviders[provider]
+	if !ok {
+		return nil, fmt.Errorf("unknown operator llm provider %q", provider)
+	}
+	return factory(cfg)
+}
+
+// operatorLLMAdapterFor returns the adapter cached from an earlier pass, or
+// builds a new one when operator.llm changed. Keeping the adapter keeps
+// provider state, such as which scripted entries were used, across passes.
+func (s *Service) operatorLLMAdapterFor(cfg policy.Config) (operatorLLMAdapter, error) {
+	key, err := json.Marshal(cfg.Operator.LLM)
+	if err != nil {
+		return nil, fmt.Errorf("encode operator llm policy: %w", err)
+	}
+	s.operatorLLMMu.Lock()
+	defer s.operatorLLMMu.Unlock()
+	if s.operatorLLM != nil && s.operatorLLMKey == string(key) {
+		return s.operatorLLM, nil
+	}
+	adapter, err := newOperatorLLMAdapter(cfg)
+	if err != nil {
+		return nil, err
+	}
+	s.operatorLLM = adapter
+	s.operatorLLMKey = string(key)
+	return adapter, nil
+}
+
+func operatorLLMTimeout(cfg policy.Config) time.Duration {
+	timeout := time.Duration(cfg.Operator.LLM.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		return 30 * time.Second
+	}
+	return timeout
+}
+
+// httpChatAdapter asks an OpenAI-compatible chat completions endpoint for a
+// proposal, requesting operatorLLMResponseSchema as the response format.
+type httpChatAdapter struct {
+	url       string
+	model     string
+	apiKey    string
+	maxTokens int
+	client    *http.Client
+}
+
+func newHTTPChatAdapterFromPolicy(cfg policy.Config) (operatorLLMAdapter, error) {
+	apiKey := ""
+	if envName := strings.TrimSpace(cfg.Operator.LLM.APIKeyEnv); envName != "" {
+		apiKey = strings.TrimSpace(os.Getenv(envName))
+		if apiKey == "" {
+			return nil, fmt.Errorf("operator llm api key env %s is not set", envName)
+		}
+	}
+	return newHTTPChatAdapter(
+		cfg.Operator.LLM.URL,
+		cfg.Operator.LLM.Model,
+		apiKey,
+		cfg.Operator.LLM.MaxTokens,
+		&http.Client{Timeout: operatorLLMTimeout(cfg)},
+	), nil
+}
+
+func newHTTPChatAdapter(url string, model string, apiKey string, maxTokens int, client *http.Client) *httpChatAdapter {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &httpChatAdapter{
+		url:       strings.TrimSpace(url),
+		model:     strings.TrimSpace(model),
+		apiKey:    apiKey,
+		maxTokens: maxTokens,
+		client:    client,
+	}
+}
+
+type httpChatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type httpChatRequest struct {
+	Model          string            `json:"model,omitempty"`
+	Messages       []httpChatMessage `json:"messages"`
+	MaxTokens      int               `json:"max_tokens,omitempty"`
+	Temperature    float64           `json:"temperature"`
+	ResponseFormat map[string]any    `json:"response_format"`
+}
+
+type httpChatResponse struct {
+	Choices []struct {
+		Message httpChatMessage `json:"message"`
+	} `json:"choices"`
+}
+
+func (a *httpChatAdapter) Propose(ctx context.Context, req operatorLLMRequest) (OperatorLLMResponse, error) {
+	if a.url == "" {
+		return OperatorLLMResponse{}, fmt.Errorf("operator llm url is empty")
+	}
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return OperatorLLMResponse{}, fmt.Errorf("marshal operator llm request: %w", err)
+	}
+	body, err := json.Marshal(httpChatRequest{
+		Model: a.model,
+		Messages: []httpChatMessage{
+			{Role: "system", Content: operatorLLMInstructions()},
+			{Role: "user", Content: string(payload)},
+		},
+		MaxTokens: a.maxTokens,
+		ResponseFormat: map[string]any{
+			"type": "json_schema",
+			"json_schema": map[string]any{
+				"name":   "operator_decision",
+				"schema": operatorLLMResponseSchema,
+			},
+		},
+	})
+	if err != nil {
+		return OperatorLLMResponse{}, fmt.Errorf("marshal chat request: %w", err)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, a.url, bytes.NewReader(body))
+	if err != nil {
+		return OperatorLLMResponse{}, fmt.Errorf("build chat request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("User-Agent", "metawsm-operator")
+	if a.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+a.apiKey)
+	}
+	resp, err := a.client.Do(httpReq)
+	if err != nil {
+		return OperatorLLMResponse{}, fmt.Errorf("post chat request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return OperatorLLMResponse{}, fmt.Errorf("chat endpoint returned %d: %s", resp.StatusCode, strings.TrimSpace(string(snippet)))
+	}
+	var chat httpChatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&chat); err != nil {
+		return OperatorLLMResponse{}, fmt.Errorf("decode chat response: %w", err)
+	}
+	if len(chat.Choices) == 0 {
+		return OperatorLLMResponse{}, fmt.Errorf("chat response has no choices")
+	}
+	return decodeOperatorLLMResponse(chat.Choices[0].Message.Content, req.RunID)
+}
+
+// operatorLLMScriptEntry is one recorded proposal. RunID and RuleIntent, when
+// set, restrict which requests it answers; Error replays a failed call.
+type operatorLLMScriptEntry struct {
+	RunID      string          `json:"run_id,omitempty"`
+	RuleIntent OperatorIntent  `json:"rule_intent,omitempty"`
+	Response   json.RawMessage `json:"response,omitempty"`
+	Error      string          `json:"error,omitempty"`
+}
+
+// scriptedLLMAdapter answers each request with the first unused matching
+// entry, so replays are deterministic. Responses still go through schema
+// validation.
+type scriptedLLMAdapter struct {
+	mu      sync.Mutex
+	entries []operatorLLMScriptEntry
+	used    []bool
+}
+
+func newScriptedLLMAdapterFromPolicy(cfg policy.Config) (operatorLLMAdapter, error) {
+	entries, err := loadOperatorLLMScript(cfg.Operator.LLM.ScriptPath)
+	if err != nil {
+		return nil, err
+	}
+	return newScriptedLLMAdapter(entries), nil
+}
+
+func newScriptedLLMAdapter(entries []operatorLLMScriptEntry) *scriptedLLMAdapter {
+	return &scriptedLLMAdapter{
+		entries: entries,
+		used:    make([]bool, len(entries)),
+	}
+}
+
+// loadOperatorLLMScript reads a JSON array of script entries.
+func loadOperatorLLMScript(path string) ([]operatorLLMScriptEntry, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return nil, fmt.Errorf("operator llm script path is empty")
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read operator llm script %s: %w", path, err)
+	}
+	var entries []operatorLLMScriptEntry
+	if err := json.Unmarshal(b, &entries); err != nil {
+		return nil, fmt.Errorf("parse operator llm script %s: %w", path, err)
+	}
+	return entries, nil
+}
+
+func (a *scriptedLLMAdapter) Propose(_ context.Context, req operatorLLMRequest) (OperatorLLMResponse, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for i, entry := range a.entries {
+		if a.used[i] {
+			continue
+		}
+		if entry.RunID != "" && entry.RunID != req.RunID {
+			continue
+		}
+		if entry.RuleIntent != "" && entry.RuleIntent != req.RuleIntent {
+			continue
+		}
+		a.used[i] = true
+		if entry.Error != "" {
+			return OperatorLLMResponse{}, fmt.Errorf("scripted llm error: %s", entry.Error)
+		}
+		return decodeOperatorLLMResponse(string(entry.Response), req.RunID)
+	}
+	return OperatorLLMResponse{}, fmt.Errorf("scripted llm has no response for run %s (rule intent %s)", req.RunID, req.RuleIntent)
+}
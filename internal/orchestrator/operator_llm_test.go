@@ -0,0 +1,258 @@
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"metawsm/internal/policy"
+)
+
+func TestDecodeOperatorLLMResponse(t *testing.T) {
+	output := "debug line\n{\"intent\":\"auto_restart\",\"target_run\":\"run-1\",\"reason\":\"stalled\",\"confidence\":0.9,\"needs_human\":false}\n"
+	resp, err := decodeOperatorLLMResponse(output, "run-1")
+	if err != nil {
+		t.Fatalf("decode operator response: %v", err)
+	}
+	if resp.Intent != OperatorIntentAutoRestart {
+		t.Fatalf("expected auto_restart intent, got %q", resp.Intent)
+	}
+	if resp.TargetRun != "run-1" {
+		t.Fatalf("expected target run run-1, got %q", resp.TargetRun)
+	}
+}
+
+func TestDecodeOperatorLLMResponseRejectsSchemaViolations(t *testing.T) {
+	cases := map[string]string{
+		"missing reason":     `{"intent":"noop"}`,
+		"unknown intent":     `{"intent":"delete_everything","reason":"x"}`,
+		"confidence range":   `{"intent":"noop","reason":"x","confidence":1.5}`,
+		"wrong type":         `{"intent":"noop","reason":"x","needs_human":"yes"}`,
+		"extra property":     `{"intent":"noop","reason":"x","shell":"rm -rf"}`,
+		"other run targeted": `{"intent":"noop","reason":"x","target_run":"run-2"}`,
+	}
+	for name, output := range cases {
+		if _, err := decodeOperatorLLMResponse(output, "run-1"); err == nil {
+			t.Fatalf("%s: expected decode error for %s", name, output)
+		}
+	}
+}
+
+func TestMergeOperatorDecisionsAssistNeverExecutes(t *testing.T) {
+	rule := operatorRuleDecision{Intent: OperatorIntentAutoRestart, Reason: "rule restart", Execute: true}
+	llm := &OperatorLLMResponse{Intent: OperatorIntentEscalateBlocked, Reason: "needs human"}
+	merged := mergeOperatorDecisions("assist", rule, llm)
+	if merged.Execute {
+		t.Fatalf("expected assist mode not to execute")
+	}
+	if merged.Intent != OperatorIntentAutoRestart {
+		t.Fatalf("expected rule intent to remain, got %q", merged.Intent)
+	}
+	if !strings.Contains(merged.Reason, "llm_suggested") {
+		t.Fatalf("expected llm suggestion annotation, got %q", merged.Reason)
+	}
+}
+
+func TestMergeOperatorDecisionsAutoPreservesRuleExecution(t *testing.T) {
+	rule := operatorRuleDecision{Intent: OperatorIntentCommitReady, Reason: "ready to commit", Execute: true}
+	merged := mergeOperatorDecisions("auto", rule, nil)
+	if merged.Intent != OperatorIntentCommitReady {
+		t.Fatalf("expected commit_ready intent, got %q", merged.Intent)
+	}
+	if !merged.Execute {
+		t.Fatalf("expected auto mode to preserve rule execute flag")
+	}
+}
+
+func TestMergeOperatorDecisionsAutoAllowsLLMEscalateWhenRuleNoop(t *testing.T) {
+	rule := operatorRuleDecision{Intent: OperatorIntentNoop, Reason: "no rule action"}
+	llm := &OperatorLLMResponse{Intent: OperatorIntentEscalateBlocked, Reason: "missing credential"}
+	merged := mergeOperatorDecisions("auto", rule, llm)
+	if merged.Intent != OperatorIntentEscalateBlocked {
+		t.Fatalf("expected llm escalate blocked, got %q", merged.Intent)
+	}
+	if merged.Source != "llm" {
+		t.Fatalf("expected llm source, got %q", merged.Source)
+	}
+	if merged.Execute {
+		t.Fatalf("expected escalation not to execute actions")
+	}
+}
+
+func TestCodexCLIAdapterValidateAndFallback(t *testing.T) {
+	var capturedArgs []string
+	adapter := newCodexCLIAdapter("codex", "", 200, time.Second, func(ctx context.Context, name string, args []string, stdin string) (string, string, error) {
+		capturedArgs = append([]string(nil), args...)
+		return "{\"intent\":\"noop\",\"reason\":\"ok\"}", "", nil
+	})
+	resp, err := adapter.Propose(context.Background(), operatorLLMRequest{RunID: "run-1"})
+	if err != nil {
+		t.Fatalf("adapter propose: %v", err)
+	}
+	if resp.Intent != OperatorIntentNoop {
+		t.Fatalf("expected noop intent, got %q", resp.Intent)
+	}
+	if resp.TargetRun != "run-1" {
+		t.Fatalf("expected target run fallback to request run id, got %q", resp.TargetRun)
+	}
+	for i := range capturedArgs {
+		if capturedArgs[i] == "--max-tokens" {
+			t.Fatalf("did not expect unsupported --max-tokens arg: %v", capturedArgs)
+		}
+	}
+}
+
+func TestCodexCLIAdapterPropagatesRunnerError(t *testing.T) {
+	adapter := newCodexCLIAdapter("codex", "", 200, time.Second, func(ctx context.Context, name string, args []string, stdin string) (string, string, error) {
+		return "", "boom", errors.New("exit 1")
+	})
+	_, err := adapter.Propose(context.Background(), operatorLLMRequest{RunID: "run-1"})
+	if err == nil {
+		t.Fatalf("expected propose error")
+	}
+	if !strings.Contains(err.Error(), "codex exec failed") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestHTTPChatAdapterUsesStructuredOutput(t *testing.T) {
+	var captured httpChatRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret-key" {
+			t.Errorf("expected bearer api key, got %q", r.Header.Get("Authorization"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&captured); err != nil {
+			t.Errorf("decode chat request: %v", err)
+		}
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"{\"intent\":\"escalate_blocked\",\"reason\":\"credentials missing\",\"confidence\":0.8}"}}]}`))
+	}))
+	defer server.Close()
+
+	t.Setenv("METAWSM_TEST_LLM_KEY", "secret-key")
+	cfg := policy.Default()
+	cfg.Operator.LLM.Provider = "http"
+	cfg.Operator.LLM.URL = server.URL + "/v1/chat/completions"
+	cfg.Operator.LLM.APIKeyEnv = "METAWSM_TEST_LLM_KEY"
+	cfg.Operator.LLM.Model = "local-model"
+	adapter, err := newOperatorLLMAdapter(cfg)
+	if err != nil {
+		t.Fatalf("new operator llm adapter: %v", err)
+	}
+	resp, err := adapter.Propose(context.Background(), operatorLLMRequest{RunID: "run-1", RuleIntent: OperatorIntentNoop})
+	if err != nil {
+		t.Fatalf("propose: %v", err)
+	}
+	if resp.Intent != OperatorIntentEscalateBlocked || resp.TargetRun != "run-1" {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+	if captured.Model != "local-model" || len(captured.Messages) != 2 || captured.ResponseFormat["type"] != "json_schema" {
+		t.Fatalf("unexpected chat request %+v", captured)
+	}
+	if !strings.Contains(captured.Messages[1].Content, `"run_id":"run-1"`) {
+		t.Fatalf("expected run context in user message, got %q", captured.Messages[1].Content)
+	}
+}
+
+func TestHTTPChatAdapterRejectsInvalidOutput(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"{\"intent\":\"reboot_host\",\"reason\":\"why not\"}"}}]}`))
+	}))
+	defer server.Close()
+
+	adapter := newHTTPChatAdapter(server.URL, "", "", 100, server.Client())
+	if _, err := adapter.Propose(context.Background(), operatorLLMRequest{RunID: "run-1"}); err == nil || !strings.Contains(err.Error(), "schema") {
+		t.Fatalf("expected schema validation error, got %v", err)
+	}
+
+	cfg := policy.Default()
+	cfg.Operator.LLM.Provider = "http"
+	cfg.Operator.LLM.URL = server.URL
+	cfg.Operator.LLM.APIKeyEnv = "METAWSM_TEST_LLM_KEY_UNSET"
+	if _, err := newOperatorLLMAdapter(cfg); err == nil {
+		t.Fatalf("expected missing api key env error")
+	}
+}
+
+func TestScriptedLLMAdapterReplaysInOrder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "script.json")
+	script := `[
+  {"run_id":"run-1","response":{"intent":"noop","reason":"first"}},
+  {"rule_intent":"auto_restart","error":"timeout"},
+  {"run_id":"run-1","response":{"intent":"escalate_blocked","reason":"second"}}
+]`
+	if err := os.WriteFile(path, []byte(script), 0o644); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+	cfg := policy.Default()
+	cfg.Operator.LLM.Provider = "scripted"
+	cfg.Operator.LLM.ScriptPath = path
+	adapter, err := newOperatorLLMAdapter(cfg)
+	if err != nil {
+		t.Fatalf("new scripted adapter: %v", err)
+	}
+
+	resp, err := adapter.Propose(context.Background(), operatorLLMRequest{RunID: "run-1", RuleIntent: OperatorIntentNoop})
+	if err != nil || resp.Reason != "first" {
+		t.Fatalf("expected first scripted response, got %+v (%v)", resp, err)
+	}
+	if _, err := adapter.Propose(context.Background(), operatorLLMRequest{RunID: "run-2", RuleIntent: OperatorIntentAutoRestart}); err == nil || !strings.Contains(err.Error(), "timeout") {
+		t.Fatalf("expected scripted error, got %v", err)
+	}
+	resp, err = adapter.Propose(context.Background(), operatorLLMRequest{RunID: "run-1", RuleIntent: OperatorIntentNoop})
+	if err != nil || resp.Intent != OperatorIntentEscalateBlocked {
+		t.Fatalf("expected second scripted response, got %+v (%v)", resp, err)
+	}
+	if _, err := adapter.Propose(context.Background(), operatorLLMRequest{RunID: "run-1"}); err == nil {
+		t.Fatalf("expected exhausted script error")
+	}
+}
+
+func TestOperatorLLMAdapterForReusesAdapterUntilPolicyChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "script.json")
+	if err := os.WriteFile(path, []byte(`[{"run_id":"run-1","response":{"intent":"noop","reason":"once"}}]`), 0o644); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+	cfg := policy.Default()
+	cfg.Operator.LLM.Provider = "scripted"
+	cfg.Operator.LLM.ScriptPath = path
+	svc := &Service{}
+
+	first, err := svc.operatorLLMAdapterFor(cfg)
+	if err != nil {
+		t.Fatalf("build adapter: %v", err)
+	}
+	if _, err := first.Propose(context.Background(), operatorLLMRequest{RunID: "run-1"}); err != nil {
+		t.Fatalf("first proposal: %v", err)
+	}
+	again, err := svc.operatorLLMAdapterFor(cfg)
+	if err != nil {
+		t.Fatalf("reuse adapter: %v", err)
+	}
+	if _, err := again.Propose(context.Background(), operatorLLMRequest{RunID: "run-1"}); err == nil {
+		t.Fatalf("expected the cached adapter to remember the used script entry")
+	}
+
+	cfg.Operator.LLM.TimeoutSeconds++
+	rebuilt, err := svc.operatorLLMAdapterFor(cfg)
+	if err != nil {
+		t.Fatalf("rebuild adapter: %v", err)
+	}
+	if _, err := rebuilt.Propose(context.Background(), operatorLLMRequest{RunID: "run-1"}); err != nil {
+		t.Fatalf("expected a policy change to rebuild the adapter, got %v", err)
+	}
+}
+
+func TestNewOperatorLLMAdapterRejectsUnknownProvider(t *testing.T) {
+	cfg := policy.Default()
+	cfg.Operator.LLM.Provider = "carrier-pigeon"
+	if _, err := newOperatorLLMAdapter(cfg); err == nil {
+		t.Fatalf("expected unknown provider error")
+	}
+}
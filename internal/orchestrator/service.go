@@ -14,6 +14,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"metawsm/internal/forumbus"
@@ -23,11 +24,29 @@ import (
 	"metawsm/internal/store"
 )
 
+// ErrRunNotFound is returned for a run id that is not stored.
+var ErrRunNotFound = store.ErrRunNotFound
+
 type Service struct {
 	store           *store.SQLiteStore
 	forumBus        *forumbus.Runtime
 	forumDispatcher forumCommandDispatcher
 	forumTopics     model.ForumTopicRegistry
+
+	backgroundMu     sync.Mutex
+	backgroundCtx    context.Context
+	backgroundCancel context.CancelFunc
+	backgroundRuns   sync.WaitGroup
+
+	// transcriptMu serialises transcript rotation between status reads and
+	// the serve worker, so one oversized file is never rotated twice.
+	transcriptMu sync.Mutex
+
+	// operatorLLM is reused across operator passes while operatorLLMKey, the
+	// encoded operator.llm policy it was built from, stays the same.
+	operatorLLMMu  sync.Mutex
+	operatorLLM    operatorLLMAdapter
+	operatorLLMKey string
 }
 
 type RunMutationInProgressError struct {
@@ -49,14 +68,11 @@ func (e *RunMutationInProgressError) Error() string {
 }
 
 func NewService(dbPath string) (*Service, error) {
-	sqliteStore := store.NewSQLiteStore(dbPath)
+	cfg := loadPolicyOrDefault()
+	sqliteStore := newStore(dbPath, cfg)
 	if err := sqliteStore.Init(); err != nil {
 		return nil, err
 	}
-	cfg, _, err := policy.Load("")
-	if err != nil {
-		cfg = policy.Default()
-	}
 	busRuntime := forumbus.NewRuntime(sqliteStore, cfg)
 	if err := busRuntime.Start(context.Background()); err != nil {
 		return nil, err
@@ -82,10 +98,21 @@ func NewService(dbPath string) (*Service, error) {
 }
 
 func (s *Service) Shutdown() {
-	if s == nil || s.forumBus == nil {
+	if s == nil {
 		return
 	}
-	s.forumBus.Stop()
+	s.backgroundMu.Lock()
+	if s.backgroundCancel != nil {
+		s.backgroundCancel()
+	}
+	s.backgroundMu.Unlock()
+	s.backgroundRuns.Wait()
+	if s.forumBus != nil {
+		s.forumBus.Stop()
+	}
+	if s.store != nil {
+		_ = s.store.Close()
+	}
 }
 
 type RunOptions struct {
@@ -103,6 +130,10 @@ type RunOptions struct {
 	DryRun            bool
 	Mode              model.RunMode
 	RunBrief          *model.RunBrief
+	// Template names a policy run template whose values fill in options left
+	// empty; TemplateVars supplies its variables.
+	Template     string
+	TemplateVars map[string]string
 }
 
 type CloseOptions struct {
@@ -289,25 +320,85 @@ type OperatorRunContext struct {
 }
 
 func (s *Service) Run(ctx context.Context, options RunOptions) (RunResult, error) {
-	cfg, policyPath, err := policy.Load(options.PolicyPath)
+	spec, cfg, steps, err := s.planRun(options)
+	if err != nil {
+		return RunResult{}, err
+	}
+	if spec.DryRun {
+		return RunResult{RunID: spec.RunID, Steps: steps}, nil
+	}
+	if err := s.executePlannedRun(ctx, spec, cfg, steps); err != nil {
+		return RunResult{}, err
+	}
+	return RunResult{RunID: spec.RunID, Steps: steps}, nil
+}
+
+// StartRun plans a run and returns as soon as the plan is stored; execution
+// continues in the background until it finishes or the service shuts down.
+func (s *Service) StartRun(options RunOptions) (RunResult, error) {
+	spec, cfg, steps, err := s.planRun(options)
 	if err != nil {
 		return RunResult{}, err
 	}
+	if spec.DryRun {
+		return RunResult{RunID: spec.RunID, Steps: steps}, nil
+	}
+	ctx := s.backgroundContext()
+	s.backgroundRuns.Add(1)
+	go func() {
+		defer s.backgroundRuns.Done()
+		// Failures are recorded on the run itself by executePlannedRun.
+		_ = s.executePlannedRun(ctx, spec, cfg, steps)
+	}()
+	return RunResult{RunID: spec.RunID, Steps: steps}, nil
+}
+
+// shuttingDown reports whether Shutdown has cancelled background execution.
+func (s *Service) shuttingDown() bool {
+	s.backgroundMu.Lock()
+	defer s.backgroundMu.Unlock()
+	return s.backgroundCtx != nil && s.backgroundCtx.Err() != nil
+}
+
+func (s *Service) backgroundContext() context.Context {
+	s.backgroundMu.Lock()
+	defer s.backgroundMu.Unlock()
+	if s.backgroundCtx == nil {
+		s.backgroundCtx, s.backgroundCancel = context.WithCancel(context.Background())
+	}
+	return s.backgroundCtx
+}
+
+// planRun validates options, stores the run spec and brief, and saves the plan.
+// Dry-run plans are left paused.
+func (s *Service) planRun(options RunOptions) (model.RunSpec, policy.Config, []model.PlanStep, error) {
+	cfg, policyPath, err := policy.Load(options.PolicyPath)
+	if err != nil {
+		return model.RunSpec{}, policy.Config{}, nil, err
+	}
 
 	tickets := normalizeTokens(options.Tickets)
 	if len(tickets) == 0 {
-		return RunResult{}, fmt.Errorf("at least one --ticket is required")
+		return model.RunSpec{}, policy.Config{}, nil, fmt.Errorf("at least one --ticket is required")
+	}
+	runID := strings.TrimSpace(options.RunID)
+	if runID == "" {
+		runID = generateRunID()
+	}
+	template, err := applyRunTemplate(&cfg, &options, tickets, runID)
+	if err != nil {
+		return model.RunSpec{}, policy.Config{}, nil, err
 	}
 	repos := normalizeTokens(options.Repos)
 	if len(repos) == 0 && options.WorkspaceStrategy != model.WorkspaceStrategyReuse {
-		return RunResult{}, fmt.Errorf("at least one --repos entry is required for create/fork")
+		return model.RunSpec{}, policy.Config{}, nil, fmt.Errorf("at least one --repos entry is required for create/fork")
 	}
 	docHomeRepo, err := resolveDocHomeRepo(options.DocHomeRepo, options.DocRepo, repos)
 	if err != nil {
-		return RunResult{}, err
+		return model.RunSpec{}, policy.Config{}, nil, err
 	}
 	if len(repos) > 0 && strings.TrimSpace(docHomeRepo) != "" && !containsToken(repos, docHomeRepo) {
-		return RunResult{}, fmt.Errorf("doc home repo %q must be one of --repos (%s)", docHomeRepo, strings.Join(repos, ","))
+		return model.RunSpec{}, policy.Config{}, nil, fmt.Errorf("doc home repo %q must be one of --repos (%s)", docHomeRepo, strings.Join(repos, ","))
 	}
 	docAuthorityMode := normalizeDocAuthorityMode(options.DocAuthorityMode)
 	if docAuthorityMode == "" {
@@ -317,7 +408,7 @@ func (s *Service) Run(ctx context.Context, options RunOptions) (RunResult, error
 		docAuthorityMode = model.DocAuthorityModeWorkspaceActive
 	}
 	if !isValidDocAuthorityMode(docAuthorityMode) {
-		return RunResult{}, fmt.Errorf("doc authority mode %q is invalid", docAuthorityMode)
+		return model.RunSpec{}, policy.Config{}, nil, fmt.Errorf("doc authority mode %q is invalid", docAuthorityMode)
 	}
 	docSeedMode := normalizeDocSeedMode(options.DocSeedMode)
 	if docSeedMode == "" {
@@ -327,7 +418,7 @@ func (s *Service) Run(ctx context.Context, options RunOptions) (RunResult, error
 		docSeedMode = model.DocSeedModeCopyFromRepoOnStart
 	}
 	if !isValidDocSeedMode(docSeedMode) {
-		return RunResult{}, fmt.Errorf("doc seed mode %q is invalid", docSeedMode)
+		return model.RunSpec{}, policy.Config{}, nil, fmt.Errorf("doc seed mode %q is invalid", docSeedMode)
 	}
 
 	strategy := options.WorkspaceStrategy
@@ -344,12 +435,7 @@ func (s *Service) Run(ctx context.Context, options RunOptions) (RunResult, error
 
 	agents, err := policy.ResolveAgents(cfg, normalizeTokens(options.AgentNames), policyPath)
 	if err != nil {
-		return RunResult{}, err
-	}
-
-	runID := strings.TrimSpace(options.RunID)
-	if runID == "" {
-		runID = generateRunID()
+		return model.RunSpec{}, policy.Config{}, nil, err
 	}
 	mode := options.Mode
 	if mode == "" {
@@ -373,13 +459,17 @@ func (s *Service) Run(ctx context.Context, options RunOptions) (RunResult, error
 		DryRun:               options.DryRun,
 		CreatedAt:            time.Now(),
 	}
+	if template != nil {
+		spec.Template = template.Name
+		spec.ExtraSteps = template.Steps
+	}
 
 	policyJSON, err := json.Marshal(cfg)
 	if err != nil {
-		return RunResult{}, fmt.Errorf("marshal policy: %w", err)
+		return model.RunSpec{}, policy.Config{}, nil, fmt.Errorf("marshal policy: %w", err)
 	}
 	if err := s.store.CreateRun(spec, string(policyJSON)); err != nil {
-		return RunResult{}, err
+		return model.RunSpec{}, policy.Config{}, nil, err
 	}
 	if options.RunBrief != nil {
 		brief := *options.RunBrief
@@ -393,72 +483,98 @@ func (s *Service) Run(ctx context.Context, options RunOptions) (RunResult, error
 		}
 		brief.UpdatedAt = now
 		if err := s.store.UpsertRunBrief(brief); err != nil {
-			return RunResult{}, err
+			return model.RunSpec{}, policy.Config{}, nil, err
 		}
 	}
 
 	if err := s.transitionRun(spec.RunID, model.RunStatusCreated, model.RunStatusPlanning, "planning run"); err != nil {
-		return RunResult{}, err
+		return model.RunSpec{}, policy.Config{}, nil, err
 	}
 
 	steps := buildPlan(spec, cfg)
 	if err := s.store.SaveSteps(spec.RunID, steps); err != nil {
-		return RunResult{}, err
+		return model.RunSpec{}, policy.Config{}, nil, err
 	}
 	if err := s.seedAgents(spec.RunID, steps); err != nil {
-		return RunResult{}, err
+		return model.RunSpec{}, policy.Config{}, nil, err
 	}
 
 	if spec.DryRun {
 		if err := s.transitionRun(spec.RunID, model.RunStatusPlanning, model.RunStatusPaused, "dry-run complete"); err != nil {
-			return RunResult{}, err
+			return model.RunSpec{}, policy.Config{}, nil, err
 		}
-		return RunResult{RunID: spec.RunID, Steps: steps}, nil
 	}
+	return spec, cfg, steps, nil
+}
 
+// executePlannedRun runs the stored plan of a freshly planned run to completion.
+func (s *Service) executePlannedRun(ctx context.Context, spec model.RunSpec, cfg policy.Config, steps []model.PlanStep) error {
 	if err := s.transitionRun(spec.RunID, model.RunStatusPlanning, model.RunStatusRunning, "executing plan"); err != nil {
-		return RunResult{}, err
+		return err
 	}
 	if err := s.executeSteps(ctx, spec, cfg, steps); err != nil {
-		_ = s.transitionRun(spec.RunID, model.RunStatusRunning, model.RunStatusFailed, err.Error())
-		return RunResult{}, err
+		s.recordRunExecutionError(ctx, spec.RunID, err)
+		return err
 	}
 	if spec.Mode == model.RunModeBootstrap {
 		_ = s.store.AddEvent(spec.RunID, "run", spec.RunID, "bootstrap", string(model.RunStatusRunning), string(model.RunStatusRunning), "bootstrap setup complete; monitoring for guidance/completion signals")
-		return RunResult{RunID: spec.RunID, Steps: steps}, nil
+		return nil
 	}
-	if err := s.transitionRun(spec.RunID, model.RunStatusRunning, model.RunStatusComplete, "run completed"); err != nil {
-		return RunResult{}, err
+	return s.transitionRun(spec.RunID, model.RunStatusRunning, model.RunStatusComplete, "run completed")
+}
+
+func (s *Service) Resume(ctx context.Context, runID string) error {
+	spec, cfg, planSteps, err := s.prepareResume(runID)
+	if err != nil {
+		return err
 	}
+	return s.executeResumedRun(ctx, spec, cfg, planSteps)
+}
 
-	return RunResult{RunID: spec.RunID, Steps: steps}, nil
+// StartResume moves a run back to running and returns; the remaining steps
+// execute in the background like StartRun's.
+func (s *Service) StartResume(runID string) error {
+	spec, cfg, planSteps, err := s.prepareResume(runID)
+	if err != nil {
+		return err
+	}
+	ctx := s.backgroundContext()
+	s.backgroundRuns.Add(1)
+	go func() {
+		defer s.backgroundRuns.Done()
+		// Failures are recorded on the run itself by executeResumedRun.
+		_ = s.executeResumedRun(ctx, spec, cfg, planSteps)
+	}()
+	return nil
 }
 
-func (s *Service) Resume(ctx context.Context, runID string) error {
+// prepareResume checks that runID may resume, transitions it to running and
+// loads the plan to continue.
+func (s *Service) prepareResume(runID string) (model.RunSpec, policy.Config, []model.PlanStep, error) {
 	record, specJSON, policyJSON, err := s.store.GetRun(runID)
 	if err != nil {
-		return err
+		return model.RunSpec{}, policy.Config{}, nil, err
 	}
 	if !hsm.CanTransitionRun(record.Status, model.RunStatusRunning) {
-		return fmt.Errorf("run %s cannot transition from %s to %s", runID, record.Status, model.RunStatusRunning)
+		return model.RunSpec{}, policy.Config{}, nil, fmt.Errorf("run %s cannot transition from %s to %s", runID, record.Status, model.RunStatusRunning)
 	}
 
 	var spec model.RunSpec
 	if err := json.Unmarshal([]byte(specJSON), &spec); err != nil {
-		return fmt.Errorf("unmarshal run spec: %w", err)
+		return model.RunSpec{}, policy.Config{}, nil, fmt.Errorf("unmarshal run spec: %w", err)
 	}
 	var cfg policy.Config
 	if err := json.Unmarshal([]byte(policyJSON), &cfg); err != nil {
-		return fmt.Errorf("unmarshal policy: %w", err)
+		return model.RunSpec{}, policy.Config{}, nil, fmt.Errorf("unmarshal policy: %w", err)
 	}
 
 	if err := s.transitionRun(runID, record.Status, model.RunStatusRunning, "resume requested"); err != nil {
-		return err
+		return model.RunSpec{}, policy.Config{}, nil, err
 	}
 
 	steps, err := s.store.GetSteps(runID)
 	if err != nil {
-		return err
+		return model.RunSpec{}, policy.Config{}, nil, err
 	}
 	planSteps := make([]model.PlanStep, 0, len(steps))
 	for _, step := range steps {
@@ -471,12 +587,17 @@ func (s *Service) Resume(ctx context.Context, runID string) error {
 			Ticket:        step.Ticket,
 			WorkspaceName: step.WorkspaceName,
 			Agent:         step.Agent,
+			DependsOn:     step.DependsOn,
 			Status:        step.Status,
 		})
 	}
+	return spec, cfg, planSteps, nil
+}
 
+func (s *Service) executeResumedRun(ctx context.Context, spec model.RunSpec, cfg policy.Config, planSteps []model.PlanStep) error {
+	runID := spec.RunID
 	if err := s.executeSteps(ctx, spec, cfg, planSteps); err != nil {
-		_ = s.transitionRun(runID, model.RunStatusRunning, model.RunStatusFailed, err.Error())
+		s.recordRunExecutionError(ctx, runID, err)
 		return err
 	}
 	if spec.Mode == model.RunModeBootstrap {
@@ -486,8 +607,24 @@ func (s *Service) Resume(ctx context.Context, runID string) error {
 	return s.transitionRun(runID, model.RunStatusRunning, model.RunStatusComplete, "resume completed")
 }
 
+// recordRunExecutionError moves a running run out of running after its steps
+// stopped with err. Execution cancelled from outside, such as the daemon
+// shutting down, pauses the run so Resume picks it up again; anything else
+// fails it.
+func (s *Service) recordRunExecutionError(ctx context.Context, runID string, err error) {
+	if errors.Is(err, context.Canceled) && ctx.Err() != nil {
+		message := "execution cancelled; resume to continue"
+		if s.shuttingDown() {
+			message = "daemon shutdown; resume to continue"
+		}
+		_ = s.transitionRun(runID, model.RunStatusRunning, model.RunStatusPaused, message)
+		return
+	}
+	_ = s.transitionRun(runID, model.RunStatusRunning, model.RunStatusFailed, err.Error())
+}
+
 func (s *Service) Stop(ctx context.Context, runID string) error {
-	record, _, _, err := s.store.GetRun(runID)
+	record, _, policyJSON, err := s.store.GetRun(runID)
 	if err != nil {
 		return err
 	}
@@ -502,11 +639,15 @@ func (s *Service) Stop(ctx context.Context, runID string) error {
 	if err != nil {
 		return err
 	}
+	rt := s.runAgentRuntime(policyJSON)
 	now := time.Now()
 	for _, agent := range agents {
-		_ = tmuxKillSession(ctx, agent.SessionName)
+		_ = rt.Stop(ctx, agent.SessionName)
 		_ = s.store.UpdateAgentStatus(runID, agent.Name, agent.WorkspaceName, model.AgentStatusStopped, model.HealthStateDead, &now, agent.LastProgressAt)
 	}
+	if err := s.revokeAgentCredentials(runID); err != nil {
+		return err
+	}
 	return s.transitionRun(runID, model.RunStatusStopping, model.RunStatusStopped, "run stopped")
 }
 
@@ -515,13 +656,14 @@ func (s *Service) Restart(ctx context.Context, options RestartOptions) (RestartR
 	if err != nil {
 		return RestartResult{}, err
 	}
-	record, specJSON, _, err := s.store.GetRun(runID)
+	record, specJSON, policyJSON, err := s.store.GetRun(runID)
 	if err != nil {
 		return RestartResult{}, err
 	}
 	if record.Status == model.RunStatusClosed {
 		return RestartResult{}, fmt.Errorf("run %s is closed and cannot be restarted", runID)
 	}
+	rt := s.runAgentRuntime(policyJSON)
 
 	var spec model.RunSpec
 	if err := json.Unmarshal([]byte(specJSON), &spec); err != nil {
@@ -549,6 +691,7 @@ func (s *Service) Restart(ctx context.Context, options RestartOptions) (RestartR
 	}
 
 	actions := make([]string, 0, len(agents)*2)
+	cfg := loadPolicyOrDefault()
 	now := time.Now()
 	for _, agent := range agents {
 		workspacePath, err := resolveWorkspacePath(agent.WorkspaceName)
@@ -563,29 +706,30 @@ func (s *Service) Restart(ctx context.Context, options RestartOptions) (RestartR
 		if command == "" {
 			command = "bash"
 		}
-		command = normalizeAgentCommand(command)
-		command = wrapAgentCommandForTmux(command)
 		sessionName := strings.TrimSpace(agent.SessionName)
 		if sessionName == "" {
 			sessionName = policy.RenderSessionName("{agent}-{workspace}", agent.Name, agent.WorkspaceName)
 		}
-
-		killCmd := fmt.Sprintf("tmux kill-session -t %s", shellQuote(sessionName))
-		startCmd := fmt.Sprintf("tmux new-session -d -s %s -c %s %s", shellQuote(sessionName), shellQuote(agentWorkdir), shellQuote(command))
-		actions = append(actions, killCmd, startCmd)
+		startSpec := AgentStartSpec{
+			SessionName: sessionName,
+			Workdir:     agentWorkdir,
+			Command:     normalizeAgentCommand(command),
+		}
+		actions = append(actions, rt.DescribeStop(sessionName), rt.DescribeStart(startSpec))
 
 		if options.DryRun {
 			continue
 		}
-		_ = tmuxKillSession(ctx, sessionName)
-		if err := runShell(ctx, startCmd); err != nil {
+		transcriptPath, err := s.prepareAgentTranscript(runID, agent.Name, agent.WorkspaceName, rt)
+		if err != nil {
 			return RestartResult{}, err
 		}
-		time.Sleep(200 * time.Millisecond)
-		if tmuxSessionProbe(ctx, sessionName) == tmuxSessionMissing {
-			return RestartResult{}, fmt.Errorf("tmux session %s exited immediately after restart", sessionName)
+		startSpec.TranscriptPath = transcriptPath
+		startSpec.Env, err = s.mintAgentCredential(runID, agent.Name, agent.WorkspaceName, cfg.Server.Auth.AgentTokenTTLSeconds)
+		if err != nil {
+			return RestartResult{}, err
 		}
-		if err := waitForAgentStartup(ctx, sessionName, 4*time.Second); err != nil {
+		if err := startAgentSession(ctx, rt, startSpec); err != nil {
 			return RestartResult{}, err
 		}
 		if err := s.store.UpdateAgentStatus(runID, agent.Name, agent.WorkspaceName, model.AgentStatusRunning, model.HealthStateHealthy, &now, &now); err != nil {
@@ -604,10 +748,11 @@ func (s *Service) Cleanup(ctx context.Context, options CleanupOptions) (CleanupR
 		return CleanupResult{}, err
 	}
 
-	record, _, _, err := s.store.GetRun(runID)
+	record, _, policyJSON, err := s.store.GetRun(runID)
 	if err != nil {
 		return CleanupResult{}, err
 	}
+	rt := s.runAgentRuntime(policyJSON)
 	agents, err := s.store.GetAgents(runID)
 	if err != nil {
 		return CleanupResult{}, err
@@ -620,7 +765,7 @@ func (s *Service) Cleanup(ctx context.Context, options CleanupOptions) (CleanupR
 		if sessionName == "" {
 			sessionName = policy.RenderSessionName("{agent}-{workspace}", agent.Name, agent.WorkspaceName)
 		}
-		actions = append(actions, fmt.Sprintf("tmux kill-session -t %s", shellQuote(sessionName)))
+		actions = append(actions, rt.DescribeStop(sessionName))
 		workspaceSet[agent.WorkspaceName] = struct{}{}
 	}
 	workspaces := make([]string, 0, len(workspaceSet))
@@ -646,8 +791,13 @@ func (s *Service) Cleanup(ctx context.Context, options CleanupOptions) (CleanupR
 		if sessionName == "" {
 			sessionName = policy.RenderSessionName("{agent}-{workspace}", agent.Name, agent.WorkspaceName)
 		}
-		_ = tmuxKillSession(ctx, sessionName)
+		_ = rt.Stop(ctx, sessionName)
 	}
+	if err := s.revokeAgentCredentials(runID); err != nil {
+		return CleanupResult{}, err
+	}
+	// Transcripts outlive their sessions; record final sizes before workspaces go away.
+	s.refreshAgentTranscripts(runID, loadPolicyOrDefault())
 
 	now := time.Now()
 	for _, agent := range agents {
@@ -1744,6 +1894,12 @@ func (s *Service) resolveRunID(explicitRunID string, ticket string) (string, err
 }
 
 func (s *Service) Guide(ctx context.Context, runID string, answer string) (GuideResult, error) {
+	return s.guideRun(ctx, runID, "", answer)
+}
+
+// guideRun answers the pending guidance request on threadID, or on the first
+// agent with one pending when threadID is empty.
+func (s *Service) guideRun(ctx context.Context, runID string, threadID string, answer string) (GuideResult, error) {
 	answer = strings.TrimSpace(answer)
 	if answer == "" {
 		return GuideResult{}, fmt.Errorf("guidance answer cannot be empty")
@@ -1769,7 +1925,7 @@ func (s *Service) Guide(ctx context.Context, runID string, answer string) (Guide
 	var target *forumControlAgentState
 	for _, agent := range agents {
 		state := states[agent.Name]
-		if state.PendingGuidance {
+		if state.PendingGuidance && (threadID == "" || state.ThreadID == threadID) {
 			state.WorkspaceName = agent.WorkspaceName
 			target = &state
 			break
@@ -1906,345 +2062,96 @@ func (s *Service) Close(ctx context.Context, options CloseOptions) error {
 	return s.transitionRun(options.RunID, model.RunStatusClosing, model.RunStatusClosed, "close completed")
 }
 
-func (s *Service) Status(ctx context.Context, runID string) (string, error) {
-	record, specJSON, _, err := s.store.GetRun(runID)
-	if err != nil {
-		return "", err
-	}
-	var spec model.RunSpec
-	if err := json.Unmarshal([]byte(specJSON), &spec); err != nil {
-		spec = model.RunSpec{}
-	}
-	steps, err := s.store.GetSteps(runID)
-	if err != nil {
-		return "", err
-	}
-	agents, err := s.store.GetAgents(runID)
-	if err != nil {
-		return "", err
-	}
-	tickets, err := s.store.GetTickets(runID)
-	if err != nil {
-		return "", err
-	}
-
-	cfg, _, err := policy.Load("")
-	if err != nil {
-		cfg = policy.Default()
-	}
+// RunStatusBranch summarizes the steps of one ticket branch. State is
+// done|failed|running|blocked|pending; Next names the first runnable step.
+type RunStatusBranch struct {
+	Ticket  string `json:"ticket"`
+	State   string `json:"state"`
+	Total   int    `json:"total"`
+	Done    int    `json:"done"`
+	Running int    `json:"running"`
+	Failed  int    `json:"failed"`
+	Blocked int    `json:"blocked"`
+	Next    string `json:"next,omitempty"`
+}
 
-	now := time.Now()
-	for _, agent := range agents {
-		health, status, lastActivity, lastProgress := evaluateHealth(ctx, cfg, agent, now)
-		_ = s.store.UpdateAgentStatus(runID, agent.Name, agent.WorkspaceName, status, health, lastActivity, lastProgress)
-	}
-	agents, _ = s.store.GetAgents(runID)
-	if spec.Mode == model.RunModeBootstrap {
-		if err := s.syncBootstrapSignals(ctx, runID, record.Status, spec, agents); err != nil {
-			return "", err
-		}
-		record, _, _, _ = s.store.GetRun(runID)
-	}
-	controlStates, _ := s.forumControlStatesForRun(runID, agents)
-	pendingControlGuidance := []forumControlAgentState{}
-	controlThreadIDs := map[string]struct{}{}
-	for _, agent := range agents {
-		state, ok := controlStates[agent.Name]
-		if !ok {
-			continue
-		}
-		state.WorkspaceName = agent.WorkspaceName
-		if strings.TrimSpace(state.ThreadID) != "" {
-			controlThreadIDs[state.ThreadID] = struct{}{}
-		}
-		if state.PendingGuidance {
-			pendingControlGuidance = append(pendingControlGuidance, state)
-		}
-	}
-	forumThreads, _ := s.store.ListForumThreads(model.ForumThreadFilter{RunID: runID, Limit: 200})
-	brief, _ := s.store.GetRunBrief(runID)
-	docSyncStates, _ := s.store.ListDocSyncStates(runID)
-	runPullRequests, _ := s.store.ListRunPullRequests(runID)
-	runReviewFeedback, _ := s.store.ListRunReviewFeedback(runID)
-
-	slaMinutes := cfg.Forum.SLA.EscalationMinutes
-	if slaMinutes <= 0 {
-		slaMinutes = 30
-	}
-	slaThreshold := time.Duration(slaMinutes) * time.Minute
-	forumEscalations := []model.ForumThreadView{}
-	for _, thread := range forumThreads {
-		if _, isControl := controlThreadIDs[thread.ThreadID]; isControl {
-			continue
-		}
-		if thread.State != model.ForumThreadStateNew && thread.State != model.ForumThreadStateWaitingHuman {
-			continue
-		}
-		age := now.Sub(thread.UpdatedAt)
-		if thread.Priority == model.ForumPriorityUrgent || thread.Priority == model.ForumPriorityHigh || age >= slaThreshold {
-			forumEscalations = append(forumEscalations, thread)
-		}
+// summarizeStepBranches groups steps per ticket branch. A pending step is
+// blocked when one of its dependencies failed or is itself blocked; Next is the
+// first pending step whose dependencies are all complete.
+func summarizeStepBranches(steps []model.StepRecord) []RunStatusBranch {
+	planSteps := make([]model.PlanStep, 0, len(steps))
+	for _, step := range steps {
+		planSteps = append(planSteps, model.PlanStep{Index: step.Index, DependsOn: step.DependsOn})
 	}
+	planSteps = normalizePlanDependencies(planSteps)
 
-	var doneCount, failedCount, runningCount, pendingCount int
+	statusByIndex := map[int]model.StepStatus{}
+	complete := map[int]bool{}
 	for _, step := range steps {
-		switch step.Status {
-		case model.StepStatusDone:
-			doneCount++
-		case model.StepStatusFailed:
-			failedCount++
-		case model.StepStatusRunning:
-			runningCount++
-		default:
-			pendingCount++
+		statusByIndex[step.Index] = step.Status
+		if step.Status == model.StepStatusDone || step.Status == model.StepStatusSkipped {
+			complete[step.Index] = true
 		}
 	}
-
-	var b strings.Builder
-	b.WriteString(fmt.Sprintf("Run: %s\n", runID))
-	b.WriteString(fmt.Sprintf("Status: %s\n", record.Status))
-	if spec.Mode != "" {
-		b.WriteString(fmt.Sprintf("Mode: %s\n", spec.Mode))
-	}
-	b.WriteString(fmt.Sprintf("Tickets: %s\n", strings.Join(tickets, ", ")))
-	docHomeRepo := effectiveDocHomeRepo(spec)
-	docAuthorityMode := normalizeDocAuthorityMode(string(spec.DocAuthorityMode))
-	if docAuthorityMode == "" {
-		docAuthorityMode = model.DocAuthorityModeWorkspaceActive
-	}
-	docSeedMode := normalizeDocSeedMode(string(spec.DocSeedMode))
-	if docSeedMode == "" {
-		docSeedMode = model.DocSeedModeCopyFromRepoOnStart
-	}
-	freshnessRevision := strings.TrimSpace(spec.DocFreshnessRevision)
-	if freshnessRevision == "" {
-		freshnessRevision = latestDocFreshnessRevision(docSyncStates)
-	}
-	b.WriteString("Docs:\n")
-	b.WriteString(fmt.Sprintf("  home_repo=%s\n", emptyAsUnknown(docHomeRepo)))
-	b.WriteString(fmt.Sprintf("  authority=%s\n", docAuthorityMode))
-	b.WriteString(fmt.Sprintf("  seed_mode=%s\n", docSeedMode))
-	b.WriteString(fmt.Sprintf("  freshness_revision=%s\n", emptyAsUnknown(freshnessRevision)))
-	if len(docSyncStates) == 0 {
-		b.WriteString("  sync=none\n")
-	} else {
-		for _, state := range docSyncStates {
-			b.WriteString(fmt.Sprintf(
-				"  sync ticket=%s workspace=%s status=%s revision=%s updated_at=%s\n",
-				state.Ticket,
-				state.WorkspaceName,
-				state.Status,
-				emptyAsUnknown(state.Revision),
-				state.UpdatedAt.Format(time.RFC3339),
-			))
-		}
-	}
-	for _, warning := range docFreshnessWarnings(docSyncStates, docSeedMode, cfg.Docs.StaleWarningSeconds, now) {
-		b.WriteString(fmt.Sprintf("  warning=%s (warning-only)\n", warning))
-	}
-	if brief != nil {
-		b.WriteString("Brief:\n")
-		b.WriteString(fmt.Sprintf("  goal=%s\n", brief.Goal))
-		b.WriteString(fmt.Sprintf("  scope=%s\n", brief.Scope))
-		b.WriteString(fmt.Sprintf("  done=%s\n", brief.DoneCriteria))
-		b.WriteString(fmt.Sprintf("  constraints=%s\n", brief.Constraints))
-		b.WriteString(fmt.Sprintf("  merge_intent=%s\n", brief.MergeIntent))
-	}
-	if len(pendingControlGuidance) > 0 || len(forumEscalations) > 0 {
-		b.WriteString("Guidance:\n")
-		for _, item := range pendingControlGuidance {
-			b.WriteString(fmt.Sprintf("  - forum control thread=%s agent=%s workspace=%s question=%s\n", item.ThreadID, item.AgentName, item.WorkspaceName, item.PendingGuidanceQuestion))
-		}
-		for _, thread := range forumEscalations {
-			b.WriteString(fmt.Sprintf("  - forum thread=%s state=%s priority=%s title=%s\n", thread.ThreadID, thread.State, thread.Priority, thread.Title))
-		}
-	}
-	if len(forumThreads) > 0 {
-		total := len(forumThreads)
-		newCount := 0
-		waitingOperatorCount := 0
-		waitingHumanCount := 0
-		answeredCount := 0
-		closedCount := 0
-		for _, thread := range forumThreads {
-			switch thread.State {
-			case model.ForumThreadStateNew:
-				newCount++
-			case model.ForumThreadStateWaitingOperator:
-				waitingOperatorCount++
-			case model.ForumThreadStateWaitingHuman:
-				waitingHumanCount++
-			case model.ForumThreadStateAnswered:
-				answeredCount++
-			case model.ForumThreadStateClosed:
-				closedCount++
-			}
-		}
-		b.WriteString("Forum:\n")
-		b.WriteString(fmt.Sprintf("  - total=%d new=%d waiting_operator=%d waiting_human=%d answered=%d closed=%d escalations=%d\n",
-			total,
-			newCount,
-			waitingOperatorCount,
-			waitingHumanCount,
-			answeredCount,
-			closedCount,
-			len(forumEscalations),
-		))
-		limit := len(forumThreads)
-		if limit > 5 {
-			limit = 5
-		}
-		for i := 0; i < limit; i++ {
-			item := forumThreads[i]
-			b.WriteString(fmt.Sprintf("  - thread=%s state=%s priority=%s assignee=%s/%s posts=%d updated_at=%s title=%s\n",
-				item.ThreadID,
-				item.State,
-				item.Priority,
-				valueOrDefault(string(item.AssigneeType), "-"),
-				valueOrDefault(item.AssigneeName, "-"),
-				item.PostsCount,
-				item.UpdatedAt.Format(time.RFC3339),
-				item.Title,
-			))
-		}
-	}
-
-	workspaceDiffs := collectWorkspaceDiffs(ctx, workspaceNamesFromAgents(agents), spec.Repos)
-	progressByWorkspace := latestProgressFromWorkspaceDiffs(workspaceDiffs)
-	for i := range agents {
-		progressAt, ok := progressByWorkspace[agents[i].WorkspaceName]
-		if !ok {
+	blocked := map[int]bool{}
+	for i, step := range steps {
+		if step.Status == model.StepStatusDone || step.Status == model.StepStatusSkipped || step.Status == model.StepStatusRunning || step.Status == model.StepStatusFailed {
 			continue
 		}
-		if agents[i].LastProgressAt != nil && !progressAt.After(*agents[i].LastProgressAt) {
-			continue
-		}
-		progressCopy := progressAt
-		agents[i].LastProgressAt = &progressCopy
-		_ = s.store.UpdateAgentStatus(
-			runID,
-			agents[i].Name,
-			agents[i].WorkspaceName,
-			agents[i].Status,
-			agents[i].HealthState,
-			agents[i].LastActivityAt,
-			agents[i].LastProgressAt,
-		)
-	}
-	if len(workspaceDiffs) > 0 {
-		b.WriteString("Diffs:\n")
-		for _, diff := range workspaceDiffs {
-			if diff.Error != nil {
-				b.WriteString(fmt.Sprintf("  - %s error=%v\n", diff.WorkspaceName, diff.Error))
-				continue
-			}
-			b.WriteString(fmt.Sprintf("  - %s path=%s\n", diff.WorkspaceName, diff.WorkspacePath))
-			for _, repo := range diff.Repos {
-				repoLabel := repo.RepoLabel
-				if repo.Error != nil {
-					b.WriteString(fmt.Sprintf("    * %s error=%v\n", repoLabel, repo.Error))
-					continue
-				}
-				if len(repo.StatusLines) == 0 {
-					b.WriteString(fmt.Sprintf("    * %s clean\n", repoLabel))
-					continue
-				}
-				b.WriteString(fmt.Sprintf("    * %s dirty files=%d\n", repoLabel, len(repo.StatusLines)))
-				limit := len(repo.StatusLines)
-				if limit > 8 {
-					limit = 8
-				}
-				for i := 0; i < limit; i++ {
-					b.WriteString(fmt.Sprintf("      %s\n", repo.StatusLines[i]))
-				}
-				if len(repo.StatusLines) > limit {
-					b.WriteString(fmt.Sprintf("      ... (%d more)\n", len(repo.StatusLines)-limit))
-				}
-			}
-		}
-	}
-	if len(runPullRequests) > 0 {
-		b.WriteString("Pull Requests:\n")
-		for _, item := range runPullRequests {
-			repoLabel := strings.TrimSpace(item.Repo)
-			if repoLabel == "" {
-				repoLabel = "unknown-repo"
-			}
-			ticketLabel := strings.TrimSpace(item.Ticket)
-			if ticketLabel == "" {
-				ticketLabel = "unknown-ticket"
-			}
-			b.WriteString(fmt.Sprintf("  - %s/%s state=%s head=%s base=%s number=%d url=%s actor=%s\n",
-				ticketLabel,
-				repoLabel,
-				valueOrDefault(string(item.PRState), "unknown"),
-				valueOrDefault(item.HeadBranch, "-"),
-				valueOrDefault(item.BaseBranch, "-"),
-				item.PRNumber,
-				valueOrDefault(item.PRURL, "-"),
-				valueOrDefault(item.Actor, "-"),
-			))
-		}
-	}
-	if len(runReviewFeedback) > 0 {
-		queued := 0
-		newCount := 0
-		addressed := 0
-		ignored := 0
-		for _, item := range runReviewFeedback {
-			switch item.Status {
-			case model.ReviewFeedbackStatusQueued:
-				queued++
-			case model.ReviewFeedbackStatusNew:
-				newCount++
-			case model.ReviewFeedbackStatusAddressed:
-				addressed++
-			case model.ReviewFeedbackStatusIgnored:
-				ignored++
+		for _, dependency := range planSteps[i].DependsOn {
+			if statusByIndex[dependency] == model.StepStatusFailed || blocked[dependency] {
+				blocked[step.Index] = true
+				break
 			}
 		}
-		b.WriteString("Review Feedback:\n")
-		b.WriteString(fmt.Sprintf("  - status=queued count=%d\n", queued))
-		b.WriteString(fmt.Sprintf("  - status=new count=%d\n", newCount))
-		b.WriteString(fmt.Sprintf("  - status=addressed count=%d\n", addressed))
-		b.WriteString(fmt.Sprintf("  - status=ignored count=%d\n", ignored))
 	}
 
-	if record.Status == model.RunStatusComplete {
-		b.WriteString("Next:\n")
-		if len(tickets) == 1 {
-			b.WriteString(fmt.Sprintf("  - metawsm iterate --ticket %s --feedback \"<feedback from diff review>\"\n", tickets[0]))
-			b.WriteString(fmt.Sprintf("  - metawsm merge --ticket %s --dry-run\n", tickets[0]))
-			b.WriteString(fmt.Sprintf("  - metawsm merge --ticket %s\n", tickets[0]))
-			b.WriteString(fmt.Sprintf("  - metawsm close --ticket %s\n", tickets[0]))
-		} else {
-			b.WriteString(fmt.Sprintf("  - metawsm iterate --run-id %s --feedback \"<feedback from diff review>\"\n", runID))
-			b.WriteString(fmt.Sprintf("  - metawsm merge --run-id %s --dry-run\n", runID))
-			b.WriteString(fmt.Sprintf("  - metawsm merge --run-id %s\n", runID))
-			b.WriteString(fmt.Sprintf("  - metawsm close --run-id %s\n", runID))
+	order := []string{}
+	byTicket := map[string]*RunStatusBranch{}
+	for i, step := range steps {
+		ticket := strings.TrimSpace(step.Ticket)
+		if ticket == "" {
+			ticket = "-"
 		}
-	}
-
-	b.WriteString(fmt.Sprintf("Steps: total=%d done=%d running=%d pending=%d failed=%d\n", len(steps), doneCount, runningCount, pendingCount, failedCount))
-	b.WriteString("Agents:\n")
-	if len(agents) == 0 {
-		b.WriteString("  - none\n")
-	} else {
-		for _, agent := range agents {
-			b.WriteString(fmt.Sprintf("  - %s@%s session=%s status=%s health=%s last_activity=%s activity_age=%s last_progress=%s progress_age=%s\n",
-				agent.Name,
-				agent.WorkspaceName,
-				agent.SessionName,
-				agent.Status,
-				agent.HealthState,
-				formatTimeOrDash(agent.LastActivityAt),
-				formatAgeOrDash(now, agent.LastActivityAt),
-				formatTimeOrDash(agent.LastProgressAt),
-				formatAgeOrDash(now, agent.LastProgressAt),
-			))
+		branch, ok := byTicket[ticket]
+		if !ok {
+			branch = &RunStatusBranch{Ticket: ticket}
+			byTicket[ticket] = branch
+			order = append(order, ticket)
+		}
+		branch.Total++
+		switch {
+		case complete[step.Index]:
+			branch.Done++
+		case step.Status == model.StepStatusRunning:
+			branch.Running++
+		case step.Status == model.StepStatusFailed:
+			branch.Failed++
+		case blocked[step.Index]:
+			branch.Blocked++
+		case branch.Next == "" && stepDependenciesComplete(planSteps[i], complete):
+			branch.Next = step.Name
+		}
+	}
+
+	out := make([]RunStatusBranch, 0, len(order))
+	for _, ticket := range order {
+		branch := byTicket[ticket]
+		switch {
+		case branch.Done == branch.Total:
+			branch.State = "done"
+		case branch.Failed > 0:
+			branch.State = "failed"
+		case branch.Running > 0:
+			branch.State = "running"
+		case branch.Blocked > 0:
+			branch.State = "blocked"
+		default:
+			branch.State = "pending"
 		}
+		out = append(out, *branch)
 	}
-	return b.String(), nil
+	return out
 }
 
 func (s *Service) ActiveRuns() ([]model.RunRecord, error) {
@@ -2469,61 +2376,225 @@ func (s *Service) transitionRun(runID string, from model.RunStatus, to model.Run
 	return s.store.AddEvent(runID, "run", runID, "transition", string(from), string(to), message)
 }
 
+// executeSteps runs the plan as a dependency graph: a step starts once every
+// step in DependsOn is done or skipped, with at most execution.max_parallel_steps
+// in flight. After a blocking failure no new steps start; in-flight steps finish
+// so Resume can pick up each branch where it stopped.
 func (s *Service) executeSteps(ctx context.Context, spec model.RunSpec, cfg policy.Config, steps []model.PlanStep) error {
-	stepRetries := cfg.Execution.StepRetries
 	agentCommand := map[string]string{}
 	for _, agent := range spec.Agents {
 		agentCommand[agent.Name] = agent.Command
 	}
+	steps = normalizePlanDependencies(steps)
+	if err := validatePlanDependencies(steps); err != nil {
+		return err
+	}
 
+	currentSteps, err := s.store.GetSteps(spec.RunID)
+	if err != nil {
+		return err
+	}
+	complete := map[int]bool{}
 	for _, step := range steps {
-		if step.Status == model.StepStatusDone {
-			continue
+		if step.Status == model.StepStatusDone || stepDoneOrSkipped(currentSteps, step.Index) {
+			complete[step.Index] = true
 		}
+	}
 
-		currentSteps, err := s.store.GetSteps(spec.RunID)
-		if err != nil {
-			return err
+	type stepOutcome struct {
+		step model.PlanStep
+		err  error
+	}
+	workers := maxParallelSteps(cfg)
+	results := make(chan stepOutcome)
+	started := map[int]bool{}
+	inFlight := 0
+	var firstErr error
+	for {
+		if firstErr == nil && ctx.Err() == nil {
+			for _, step := range steps {
+				if inFlight >= workers {
+					break
+				}
+				if complete[step.Index] || started[step.Index] || !stepDependenciesComplete(step, complete) {
+					continue
+				}
+				started[step.Index] = true
+				inFlight++
+				go func(step model.PlanStep) {
+					results <- stepOutcome{step: step, err: s.executeStepWithRetries(ctx, spec, cfg, step, agentCommand)}
+				}(step)
+			}
+		}
+		if inFlight == 0 {
+			break
 		}
-		if stepDoneOrSkipped(currentSteps, step.Index) {
+		outcome := <-results
+		inFlight--
+		if outcome.err != nil {
+			if firstErr == nil {
+				firstErr = outcome.err
+			}
 			continue
 		}
+		complete[outcome.step.Index] = true
+	}
 
-		attempts := stepRetries + 1
-		var lastErr error
-		for attempt := 1; attempt <= attempts; attempt++ {
-			if err := s.store.UpdateStepStatus(spec.RunID, step.Index, model.StepStatusRunning, "", true, false); err != nil {
-				return err
+	if firstErr != nil {
+		return firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if len(complete) < len(steps) {
+		blocked := []string{}
+		for _, step := range steps {
+			if !complete[step.Index] {
+				blocked = append(blocked, strconv.Itoa(step.Index))
 			}
-			_ = s.store.AddEvent(spec.RunID, "step", fmt.Sprintf("%d", step.Index), "attempt", "", string(model.StepStatusRunning), fmt.Sprintf("attempt %d", attempt))
+		}
+		return fmt.Errorf("plan steps %s have unsatisfiable dependencies", strings.Join(blocked, ","))
+	}
+	return nil
+}
 
-			err := s.executeSingleStep(ctx, spec, cfg, step, agentCommand)
-			if err == nil {
-				if err := s.store.UpdateStepStatus(spec.RunID, step.Index, model.StepStatusDone, "", false, true); err != nil {
-					return err
-				}
-				_ = s.store.AddEvent(spec.RunID, "step", fmt.Sprintf("%d", step.Index), "done", string(model.StepStatusRunning), string(model.StepStatusDone), step.Name)
-				lastErr = nil
-				break
+func (s *Service) executeStepWithRetries(ctx context.Context, spec model.RunSpec, cfg policy.Config, step model.PlanStep, agentCommand map[string]string) error {
+	currentSteps, err := s.store.GetSteps(spec.RunID)
+	if err != nil {
+		return err
+	}
+	if stepDoneOrSkipped(currentSteps, step.Index) {
+		return nil
+	}
+
+	attempts := cfg.Execution.StepRetries + 1
+	var lastErr error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err := s.store.UpdateStepStatus(spec.RunID, step.Index, model.StepStatusRunning, "", true, false); err != nil {
+			return err
+		}
+		_ = s.store.AddEvent(spec.RunID, "step", fmt.Sprintf("%d", step.Index), "attempt", "", string(model.StepStatusRunning), fmt.Sprintf("attempt %d", attempt))
+
+		err := s.executeSingleStep(ctx, spec, cfg, step, agentCommand)
+		if err == nil {
+			if err := s.store.UpdateStepStatus(spec.RunID, step.Index, model.StepStatusDone, "", false, true); err != nil {
+				return err
 			}
-			lastErr = err
-			_ = s.store.UpdateStepStatus(spec.RunID, step.Index, model.StepStatusFailed, err.Error(), false, true)
-			_ = s.store.AddEvent(spec.RunID, "step", fmt.Sprintf("%d", step.Index), "failed", string(model.StepStatusRunning), string(model.StepStatusFailed), err.Error())
+			_ = s.store.AddEvent(spec.RunID, "step", fmt.Sprintf("%d", step.Index), "done", string(model.StepStatusRunning), string(model.StepStatusDone), step.Name)
+			return nil
+		}
+		lastErr = err
+		_ = s.store.UpdateStepStatus(spec.RunID, step.Index, model.StepStatusFailed, err.Error(), false, true)
+		_ = s.store.AddEvent(spec.RunID, "step", fmt.Sprintf("%d", step.Index), "failed", string(model.StepStatusRunning), string(model.StepStatusFailed), err.Error())
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			// Interrupted rather than failed: leave the step failed, not
+			// skipped, so Resume runs it again.
+			return fmt.Errorf("step %d %s interrupted: %w", step.Index, step.Name, ctxErr)
+		}
+
+		if attempt < attempts {
+			time.Sleep(300 * time.Millisecond)
+		}
+	}
+
+	if step.Blocking {
+		return fmt.Errorf("step %d %s failed: %w", step.Index, step.Name, lastErr)
+	}
+	_ = s.store.UpdateStepStatus(spec.RunID, step.Index, model.StepStatusSkipped, lastErr.Error(), false, true)
+	return nil
+}
 
-			if attempt < attempts {
-				time.Sleep(300 * time.Millisecond)
+func maxParallelSteps(cfg policy.Config) int {
+	if cfg.Execution.MaxParallelSteps <= 0 {
+		return 1
+	}
+	return cfg.Execution.MaxParallelSteps
+}
+
+// normalizePlanDependencies treats plans persisted before step dependencies
+// existed (no step declares any) as a strict linear chain.
+func normalizePlanDependencies(steps []model.PlanStep) []model.PlanStep {
+	for _, step := range steps {
+		if len(step.DependsOn) > 0 {
+			return steps
+		}
+	}
+	out := make([]model.PlanStep, len(steps))
+	copy(out, steps)
+	for i := 1; i < len(out); i++ {
+		out[i].DependsOn = []int{out[i-1].Index}
+	}
+	return out
+}
+
+func validatePlanDependencies(steps []model.PlanStep) error {
+	known := map[int]struct{}{}
+	for _, step := range steps {
+		known[step.Index] = struct{}{}
+	}
+	for _, step := range steps {
+		for _, dependency := range step.DependsOn {
+			if dependency == step.Index {
+				return fmt.Errorf("step %d %s depends on itself", step.Index, step.Name)
+			}
+			if _, ok := known[dependency]; !ok {
+				return fmt.Errorf("step %d %s depends on unknown step %d", step.Index, step.Name, dependency)
 			}
 		}
+	}
 
-		if lastErr != nil {
-			if step.Blocking {
-				return fmt.Errorf("step %d %s failed: %w", step.Index, step.Name, lastErr)
+	// Kahn's topological sort: any step never reaching zero pending
+	// dependencies sits on (or behind) a cycle.
+	pending := make(map[int]int, len(steps))
+	dependents := map[int][]int{}
+	for _, step := range steps {
+		seen := map[int]bool{}
+		for _, dependency := range step.DependsOn {
+			if seen[dependency] {
+				continue
 			}
-			_ = s.store.UpdateStepStatus(spec.RunID, step.Index, model.StepStatusSkipped, lastErr.Error(), false, true)
+			seen[dependency] = true
+			pending[step.Index]++
+			dependents[dependency] = append(dependents[dependency], step.Index)
 		}
 	}
+	ready := make([]int, 0, len(steps))
+	for _, step := range steps {
+		if pending[step.Index] == 0 {
+			ready = append(ready, step.Index)
+		}
+	}
+	sorted := 0
+	for len(ready) > 0 {
+		index := ready[0]
+		ready = ready[1:]
+		sorted++
+		for _, dependent := range dependents[index] {
+			pending[dependent]--
+			if pending[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+	if sorted == len(steps) {
+		return nil
+	}
+	blocked := make([]string, 0, len(steps)-sorted)
+	for _, step := range steps {
+		if pending[step.Index] > 0 {
+			blocked = append(blocked, fmt.Sprintf("%d %s", step.Index, step.Name))
+		}
+	}
+	return fmt.Errorf("step dependencies form a cycle involving steps: %s", strings.Join(blocked, ", "))
+}
 
-	return nil
+func stepDependenciesComplete(step model.PlanStep, complete map[int]bool) bool {
+	for _, dependency := range step.DependsOn {
+		if !complete[dependency] {
+			return false
+		}
+	}
+	return true
 }
 
 func (s *Service) executeSingleStep(ctx context.Context, spec model.RunSpec, cfg policy.Config, step model.PlanStep, agentCommands map[string]string) error {
@@ -2554,24 +2625,36 @@ func (s *Service) executeSingleStep(ctx context.Context, spec model.RunSpec, cfg
 		if strings.TrimSpace(command) == "" {
 			command = "bash"
 		}
-		command = normalizeAgentCommand(command)
-		command = wrapAgentCommandForTmux(command)
 		sessionName := policy.RenderSessionName(cfg.Tmux.SessionPattern, step.Agent, step.WorkspaceName)
-
-		_ = tmuxKillSession(ctx, sessionName)
-		tmuxCmd := fmt.Sprintf("tmux new-session -d -s %s -c %s %s", shellQuote(sessionName), shellQuote(agentWorkdir), shellQuote(command))
-		if err := runShell(ctx, tmuxCmd); err != nil {
+		rt := s.agentRuntime(cfg)
+		transcriptPath, err := s.prepareAgentTranscript(spec.RunID, step.Agent, step.WorkspaceName, rt)
+		if err != nil {
 			return err
 		}
-		time.Sleep(200 * time.Millisecond)
-		if tmuxSessionProbe(ctx, sessionName) == tmuxSessionMissing {
-			return fmt.Errorf("tmux session %s exited immediately after start", sessionName)
+		agentEnv, err := s.mintAgentCredential(spec.RunID, step.Agent, step.WorkspaceName, cfg.Server.Auth.AgentTokenTTLSeconds)
+		if err != nil {
+			return err
 		}
-		if err := waitForAgentStartup(ctx, sessionName, 4*time.Second); err != nil {
+		if err := startAgentSession(ctx, rt, AgentStartSpec{
+			SessionName:    sessionName,
+			Workdir:        agentWorkdir,
+			Command:        normalizeAgentCommand(command),
+			TranscriptPath: transcriptPath,
+			Env:            agentEnv,
+		}); err != nil {
 			return err
 		}
 		now := time.Now()
 		return s.store.UpdateAgentStatus(spec.RunID, step.Agent, step.WorkspaceName, model.AgentStatusRunning, model.HealthStateHealthy, &now, &now)
+	case "template_step":
+		if strings.TrimSpace(step.Command) == "" {
+			return fmt.Errorf("empty template step command")
+		}
+		workspacePath, err := resolveWorkspacePath(step.WorkspaceName)
+		if err != nil {
+			return err
+		}
+		return runShell(ctx, fmt.Sprintf("cd %s && %s", shellQuote(workspacePath), step.Command))
 	case "ticket_context_sync":
 		workspacePath, err := resolveWorkspacePath(step.WorkspaceName)
 		if err != nil {
@@ -2668,6 +2751,7 @@ func buildPlan(spec model.RunSpec, cfg policy.Config) []model.PlanStep {
 			Ticket:   ticket,
 			Status:   model.StepStatusPending,
 		})
+		verifyIndex := index
 		index++
 
 		workspaceCommand := ""
@@ -2705,8 +2789,10 @@ func buildPlan(spec model.RunSpec, cfg policy.Config) []model.PlanStep {
 			Blocking:      true,
 			Ticket:        ticket,
 			WorkspaceName: workspaceName,
+			DependsOn:     []int{verifyIndex},
 			Status:        model.StepStatusPending,
 		})
+		agentParent := index
 		index++
 
 		if normalizeDocSeedMode(string(spec.DocSeedMode)) == model.DocSeedModeCopyFromRepoOnStart {
@@ -2718,8 +2804,30 @@ func buildPlan(spec model.RunSpec, cfg policy.Config) []model.PlanStep {
 				Blocking:      true,
 				Ticket:        ticket,
 				WorkspaceName: workspaceName,
+				DependsOn:     []int{agentParent},
+				Status:        model.StepStatusPending,
+			})
+			agentParent = index
+			index++
+		}
+
+		for _, extra := range spec.ExtraSteps {
+			vars := map[string]string{
+				policy.RunTemplateVarTicket:    ticket,
+				policy.RunTemplateVarWorkspace: workspaceName,
+			}
+			steps = append(steps, model.PlanStep{
+				Index:         index,
+				Name:          fmt.Sprintf("%s-%s", extra.Name, workspaceName),
+				Kind:          "template_step",
+				Command:       policy.RenderRunTemplateCommand(extra.Command, vars),
+				Blocking:      true,
+				Ticket:        ticket,
+				WorkspaceName: workspaceName,
+				DependsOn:     []int{agentParent},
 				Status:        model.StepStatusPending,
 			})
+			agentParent = index
 			index++
 		}
 
@@ -2733,6 +2841,7 @@ func buildPlan(spec model.RunSpec, cfg policy.Config) []model.PlanStep {
 				Ticket:        ticket,
 				WorkspaceName: workspaceName,
 				Agent:         agent.Name,
+				DependsOn:     []int{agentParent},
 				Status:        model.StepStatusPending,
 			})
 			index++
@@ -4140,12 +4249,12 @@ func locateTicketDocDirsInWorkspace(docRootPath string, ticket string) ([]string
 	return paths, nil
 }
 
-func evaluateHealth(ctx context.Context, cfg policy.Config, agent model.AgentRecord, now time.Time) (model.HealthState, model.AgentStatus, *time.Time, *time.Time) {
-	sessionState := tmuxSessionProbe(ctx, agent.SessionName)
-	if sessionState == tmuxSessionMissing {
+func evaluateHealth(ctx context.Context, cfg policy.Config, rt AgentRuntime, agent model.AgentRecord, now time.Time) (model.HealthState, model.AgentStatus, *time.Time, *time.Time) {
+	sessionState := rt.Probe(ctx, agent.SessionName)
+	if sessionState == agentSessionMissing {
 		return model.HealthStateDead, model.AgentStatusDead, agent.LastActivityAt, agent.LastProgressAt
 	}
-	if sessionState == tmuxSessionUnknown {
+	if sessionState == agentSessionUnknown {
 		status := agent.Status
 		health := agent.HealthState
 		if status == model.AgentStatusDead || status == model.AgentStatusFailed || status == model.AgentStatusStopped || strings.TrimSpace(string(status)) == "" {
@@ -4156,18 +4265,16 @@ func evaluateHealth(ctx context.Context, cfg policy.Config, agent model.AgentRec
 		}
 		return health, status, agent.LastActivityAt, agent.LastProgressAt
 	}
-	if exitCode, found := readAgentExitCode(ctx, agent.SessionName); found {
+	if exitCode, found := rt.ExitCode(ctx, agent.SessionName); found {
 		if exitCode != 0 {
 			return model.HealthStateDead, model.AgentStatusFailed, agent.LastActivityAt, agent.LastProgressAt
 		}
 		return model.HealthStateIdle, model.AgentStatusIdle, agent.LastActivityAt, agent.LastProgressAt
 	}
 
-	activityEpoch := fetchSessionActivity(ctx, agent.SessionName)
 	lastActivity := agent.LastActivityAt
-	if activityEpoch > 0 {
-		t := time.Unix(activityEpoch, 0)
-		lastActivity = &t
+	if observed := rt.LastActivity(ctx, agent.SessionName); observed != nil {
+		lastActivity = observed
 	}
 	lastProgress := agent.LastProgressAt
 	if lastActivity != nil {
@@ -4214,48 +4321,40 @@ func fetchSessionActivity(ctx context.Context, sessionName string) int64 {
 	return n
 }
 
-type tmuxSessionState int
-
-const (
-	tmuxSessionUnknown tmuxSessionState = iota
-	tmuxSessionPresent
-	tmuxSessionMissing
-)
-
-func tmuxSessionProbe(ctx context.Context, sessionName string) tmuxSessionState {
+func tmuxSessionProbe(ctx context.Context, sessionName string) agentSessionState {
 	cmd := exec.CommandContext(ctx, "zsh", "-lc", fmt.Sprintf("tmux has-session -t %s", shellQuote(sessionName)))
 	var stderr strings.Builder
 	cmd.Stderr = &stderr
 	if err := cmd.Run(); err == nil {
-		return tmuxSessionPresent
+		return agentSessionPresent
 	}
 	if isTmuxSessionMissingMessage(stderr.String()) {
-		return tmuxSessionMissing
+		return agentSessionMissing
 	}
 	if listState, ok := tmuxSessionProbeViaList(ctx, sessionName); ok {
 		return listState
 	}
-	return tmuxSessionUnknown
+	return agentSessionUnknown
 }
 
-func tmuxSessionProbeViaList(ctx context.Context, sessionName string) (tmuxSessionState, bool) {
+func tmuxSessionProbeViaList(ctx context.Context, sessionName string) (agentSessionState, bool) {
 	cmd := exec.CommandContext(ctx, "zsh", "-lc", "tmux ls -F '#S'")
 	var stderr strings.Builder
 	cmd.Stderr = &stderr
 	out, err := cmd.Output()
 	if err != nil {
 		if isTmuxSessionMissingMessage(stderr.String()) {
-			return tmuxSessionMissing, true
+			return agentSessionMissing, true
 		}
-		return tmuxSessionUnknown, false
+		return agentSessionUnknown, false
 	}
 	target := strings.TrimSpace(sessionName)
 	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
 		if strings.TrimSpace(line) == target {
-			return tmuxSessionPresent, true
+			return agentSessionPresent, true
 		}
 	}
-	return tmuxSessionMissing, true
+	return agentSessionMissing, true
 }
 
 func isTmuxSessionMissingMessage(stderr string) bool {
@@ -4276,7 +4375,7 @@ func isTmuxSessionMissingMessage(stderr string) bool {
 }
 
 func tmuxHasSession(ctx context.Context, sessionName string) bool {
-	return tmuxSessionProbe(ctx, sessionName) == tmuxSessionPresent
+	return tmuxSessionProbe(ctx, sessionName) == agentSessionPresent
 }
 
 func tmuxKillSession(ctx context.Context, sessionName string) error {
@@ -4325,13 +4424,13 @@ func parseAgentExitCode(output string) (int, bool) {
 	return code, true
 }
 
-func waitForAgentStartup(ctx context.Context, sessionName string, timeout time.Duration) error {
+func waitForAgentStartup(ctx context.Context, rt AgentRuntime, sessionName string, timeout time.Duration) error {
 	deadline := time.Now().Add(timeout)
 	for {
-		if tmuxSessionProbe(ctx, sessionName) == tmuxSessionMissing {
-			return fmt.Errorf("tmux session %s exited during startup", sessionName)
+		if rt.Probe(ctx, sessionName) == agentSessionMissing {
+			return fmt.Errorf("%s session %s exited during startup", rt.Name(), sessionName)
 		}
-		if exitCode, found := readAgentExitCode(ctx, sessionName); found {
+		if exitCode, found := rt.ExitCode(ctx, sessionName); found {
 			if exitCode != 0 {
 				return fmt.Errorf("agent command in %s exited with status %d", sessionName, exitCode)
 			}
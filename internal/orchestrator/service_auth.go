@@ -0,0 +1,232 @@
+package orchestrator
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"metawsm/internal/model"
+	"metawsm/internal/policy"
+	"metawsm/internal/store"
+)
+
+const apiTokenPrefix = "mwt_"
+
+// Environment variables injected into agent sessions alongside their credential.
+const (
+	agentTokenEnv     = "METAWSM_API_TOKEN"
+	agentRunIDEnv     = "METAWSM_RUN_ID"
+	agentNameEnv      = "METAWSM_AGENT_NAME"
+	agentWorkspaceEnv = "METAWSM_WORKSPACE"
+)
+
+var (
+	// ErrInvalidAPIToken is returned for unknown, revoked, or expired tokens.
+	ErrInvalidAPIToken = errors.New("invalid or expired api token")
+	// ErrControlSignalOutOfScope is returned when an agent credential posts a
+	// control signal for a run or agent other than the one it was minted for.
+	ErrControlSignalOutOfScope = errors.New("control signal is outside the credential scope")
+	// ErrForumWriteOutOfScope is returned when an agent credential opens or
+	// posts to a thread that belongs to another run.
+	ErrForumWriteOutOfScope = errors.New("forum write is outside the credential scope")
+)
+
+type APITokenOptions struct {
+	Role          model.APIRole
+	ActorName     string
+	TTL           time.Duration
+	RunID         string
+	AgentName     string
+	WorkspaceName string
+}
+
+// APIPrincipal is the identity an API request authenticated as. Agent
+// credentials also carry the run and agent they are scoped to.
+type APIPrincipal struct {
+	TokenID       string
+	Role          model.APIRole
+	ActorName     string
+	RunID         string
+	AgentName     string
+	WorkspaceName string
+}
+
+// Scoped reports whether the principal is limited to a single run's agent.
+func (p APIPrincipal) Scoped() bool {
+	return strings.TrimSpace(p.RunID) != ""
+}
+
+// CreateAPIToken stores a new token and returns its plaintext secret. The secret
+// is only available here; the database keeps its SHA-256 hash.
+func CreateAPIToken(dbPath string, options APITokenOptions) (string, model.APIToken, error) {
+	sqliteStore := newStore(dbPath, loadPolicyOrDefault())
+	defer sqliteStore.Close()
+	if err := sqliteStore.Init(); err != nil {
+		return "", model.APIToken{}, err
+	}
+	return createAPIToken(sqliteStore, options, time.Now())
+}
+
+func ListAPITokens(dbPath string) ([]model.APIToken, error) {
+	sqliteStore := newStore(dbPath, loadPolicyOrDefault())
+	defer sqliteStore.Close()
+	if err := sqliteStore.Init(); err != nil {
+		return nil, err
+	}
+	return sqliteStore.ListAPITokens()
+}
+
+func RevokeAPIToken(dbPath string, tokenID string) error {
+	tokenID = strings.TrimSpace(tokenID)
+	if tokenID == "" {
+		return fmt.Errorf("token id is required")
+	}
+	sqliteStore := newStore(dbPath, loadPolicyOrDefault())
+	defer sqliteStore.Close()
+	if err := sqliteStore.Init(); err != nil {
+		return err
+	}
+	return sqliteStore.RevokeAPIToken(tokenID, time.Now())
+}
+
+// AuthenticateAPIToken resolves a bearer token to the principal it was issued for.
+func (s *Service) AuthenticateAPIToken(token string) (APIPrincipal, error) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return APIPrincipal{}, ErrInvalidAPIToken
+	}
+	record, err := s.store.GetAPITokenByHash(hashAPIToken(token))
+	if err != nil {
+		return APIPrincipal{}, err
+	}
+	if record == nil || !record.Active(time.Now()) {
+		return APIPrincipal{}, ErrInvalidAPIToken
+	}
+	return APIPrincipal{
+		TokenID:       record.TokenID,
+		Role:          record.Role,
+		ActorName:     record.ActorName,
+		RunID:         record.RunID,
+		AgentName:     record.AgentName,
+		WorkspaceName: record.WorkspaceName,
+	}, nil
+}
+
+// authorizeForumWrite checks that a scoped agent credential writes only to its
+// own run: threads tied to that run, or run-less threads for one of its tickets.
+// Unscoped and missing credentials pass.
+func (s *Service) authorizeForumWrite(credential *APIPrincipal, runID string, ticket string) error {
+	if credential == nil || !credential.Scoped() {
+		return nil
+	}
+	runID = strings.TrimSpace(runID)
+	if runID != "" {
+		if runID != credential.RunID {
+			return fmt.Errorf("%w: credential is scoped to run %s", ErrForumWriteOutOfScope, credential.RunID)
+		}
+		return nil
+	}
+	tickets, err := s.store.GetTickets(credential.RunID)
+	if err != nil {
+		return err
+	}
+	for _, runTicket := range tickets {
+		if runTicket == strings.TrimSpace(ticket) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: ticket %s is not part of run %s", ErrForumWriteOutOfScope, ticket, credential.RunID)
+}
+
+// mintAgentCredential replaces any credential held by the agent session with a
+// fresh agent-role token scoped to it, and returns the environment the session
+// should start with.
+func (s *Service) mintAgentCredential(runID string, agentName string, workspaceName string, ttlSeconds int) ([]string, error) {
+	now := time.Now()
+	if err := s.store.RevokeAgentAPITokens(runID, agentName, workspaceName, now); err != nil {
+		return nil, err
+	}
+	if ttlSeconds <= 0 {
+		ttlSeconds = policy.Default().Server.Auth.AgentTokenTTLSeconds
+	}
+	plaintext, _, err := createAPIToken(s.store, APITokenOptions{
+		Role:          model.APIRoleAgent,
+		ActorName:     agentName,
+		TTL:           time.Duration(ttlSeconds) * time.Second,
+		RunID:         runID,
+		AgentName:     agentName,
+		WorkspaceName: workspaceName,
+	}, now)
+	if err != nil {
+		return nil, fmt.Errorf("mint credential for agent %s: %w", agentName, err)
+	}
+	return []string{
+		agentTokenEnv + "=" + plaintext,
+		agentRunIDEnv + "=" + runID,
+		agentNameEnv + "=" + agentName,
+		agentWorkspaceEnv + "=" + workspaceName,
+	}, nil
+}
+
+// revokeAgentCredentials revokes every credential minted for the run's agents.
+func (s *Service) revokeAgentCredentials(runID string) error {
+	return s.store.RevokeAgentAPITokens(runID, "", "", time.Now())
+}
+
+func createAPIToken(sqliteStore *store.SQLiteStore, options APITokenOptions, now time.Time) (string, model.APIToken, error) {
+	role := model.APIRole(strings.TrimSpace(strings.ToLower(string(options.Role))))
+	if !role.Valid() {
+		return "", model.APIToken{}, fmt.Errorf("role must be viewer|human|operator|agent")
+	}
+	actorName := strings.TrimSpace(options.ActorName)
+	if actorName == "" {
+		return "", model.APIToken{}, fmt.Errorf("actor name is required")
+	}
+	if options.TTL < 0 {
+		return "", model.APIToken{}, fmt.Errorf("ttl must be >= 0")
+	}
+	secret, err := randomHex(32)
+	if err != nil {
+		return "", model.APIToken{}, err
+	}
+	id, err := randomHex(8)
+	if err != nil {
+		return "", model.APIToken{}, err
+	}
+	plaintext := apiTokenPrefix + secret
+	record := model.APIToken{
+		TokenID:       "tok_" + id,
+		TokenHash:     hashAPIToken(plaintext),
+		Role:          role,
+		ActorName:     actorName,
+		RunID:         strings.TrimSpace(options.RunID),
+		AgentName:     strings.TrimSpace(options.AgentName),
+		WorkspaceName: strings.TrimSpace(options.WorkspaceName),
+		CreatedAt:     now,
+	}
+	if options.TTL > 0 {
+		expiresAt := now.Add(options.TTL)
+		record.ExpiresAt = &expiresAt
+	}
+	if err := sqliteStore.CreateAPIToken(record); err != nil {
+		return "", model.APIToken{}, err
+	}
+	return plaintext, record, nil
+}
+
+func hashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate random token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
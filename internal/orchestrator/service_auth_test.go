@@ -0,0 +1,167 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"metawsm/internal/model"
+)
+
+func TestAuthenticateAPITokenResolvesPrincipalUntilRevoked(t *testing.T) {
+	service := newStoreOnlyService(t)
+	plaintext, record, err := createAPIToken(service.store, APITokenOptions{Role: "Operator", ActorName: "kball"}, time.Now())
+	if err != nil {
+		t.Fatalf("create token: %v", err)
+	}
+	if !strings.HasPrefix(plaintext, apiTokenPrefix) || record.TokenHash == plaintext {
+		t.Fatalf("expected prefixed plaintext and hashed record, got %q / %q", plaintext, record.TokenHash)
+	}
+
+	principal, err := service.AuthenticateAPIToken(plaintext)
+	if err != nil {
+		t.Fatalf("authenticate: %v", err)
+	}
+	if principal.TokenID != record.TokenID || principal.Role != model.APIRoleOperator || principal.ActorName != "kball" {
+		t.Fatalf("unexpected principal: %+v", principal)
+	}
+	if _, err := service.AuthenticateAPIToken(plaintext + "x"); !errors.Is(err, ErrInvalidAPIToken) {
+		t.Fatalf("expected invalid token error for unknown secret, got %v", err)
+	}
+
+	if err := service.store.RevokeAPIToken(record.TokenID, time.Now()); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	if _, err := service.AuthenticateAPIToken(plaintext); !errors.Is(err, ErrInvalidAPIToken) {
+		t.Fatalf("expected revoked token to be rejected, got %v", err)
+	}
+}
+
+func TestAuthenticateAPITokenRejectsExpiredToken(t *testing.T) {
+	service := newStoreOnlyService(t)
+	plaintext, _, err := createAPIToken(service.store, APITokenOptions{Role: model.APIRoleViewer, ActorName: "dash", TTL: time.Minute}, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("create token: %v", err)
+	}
+	if _, err := service.AuthenticateAPIToken(plaintext); !errors.Is(err, ErrInvalidAPIToken) {
+		t.Fatalf("expected expired token to be rejected, got %v", err)
+	}
+	if _, _, err := createAPIToken(service.store, APITokenOptions{Role: "admin", ActorName: "x"}, time.Now()); err == nil {
+		t.Fatalf("expected unknown role to be rejected")
+	}
+}
+
+func TestMintAgentCredentialIsScopedAndRevocable(t *testing.T) {
+	service := newStoreOnlyService(t)
+	first, err := service.mintAgentCredential("run-1", "agent", "ws-1", 60)
+	if err != nil {
+		t.Fatalf("mint: %v", err)
+	}
+	firstToken := agentEnvValue(first, agentTokenEnv)
+	if agentEnvValue(first, agentRunIDEnv) != "run-1" || agentEnvValue(first, agentNameEnv) != "agent" || agentEnvValue(first, agentWorkspaceEnv) != "ws-1" {
+		t.Fatalf("unexpected agent env: %v", first)
+	}
+	principal, err := service.AuthenticateAPIToken(firstToken)
+	if err != nil {
+		t.Fatalf("authenticate: %v", err)
+	}
+	if principal.Role != model.APIRoleAgent || !principal.Scoped() || principal.RunID != "run-1" || principal.AgentName != "agent" || principal.WorkspaceName != "ws-1" {
+		t.Fatalf("unexpected agent principal: %+v", principal)
+	}
+
+	second, err := service.mintAgentCredential("run-1", "agent", "ws-1", 60)
+	if err != nil {
+		t.Fatalf("re-mint: %v", err)
+	}
+	if _, err := service.AuthenticateAPIToken(firstToken); !errors.Is(err, ErrInvalidAPIToken) {
+		t.Fatalf("expected restart to revoke the previous credential, got %v", err)
+	}
+	secondToken := agentEnvValue(second, agentTokenEnv)
+	if _, err := service.AuthenticateAPIToken(secondToken); err != nil {
+		t.Fatalf("authenticate re-minted credential: %v", err)
+	}
+
+	if err := service.revokeAgentCredentials("run-1"); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	if _, err := service.AuthenticateAPIToken(secondToken); !errors.Is(err, ErrInvalidAPIToken) {
+		t.Fatalf("expected stop to revoke the credential, got %v", err)
+	}
+}
+
+func TestForumAppendControlSignalRejectsOutOfScopeCredential(t *testing.T) {
+	service := newStoreOnlyService(t)
+	credential := APIPrincipal{Role: model.APIRoleAgent, ActorName: "agent", RunID: "run-1", AgentName: "agent"}
+	_, err := service.ForumAppendControlSignal(context.Background(), ForumControlSignalOptions{
+		RunID:     "run-2",
+		Ticket:    "METAWSM-1",
+		AgentName: "agent",
+		ActorType: model.ForumActorAgent,
+		ActorName: "agent",
+		Payload: model.ForumControlPayloadV1{
+			SchemaVersion: model.ForumControlSchemaVersion1,
+			ControlType:   model.ForumControlTypeGuidanceRequest,
+			RunID:         "run-2",
+			AgentName:     "agent",
+			Question:      "which run am I?",
+		},
+		Credential: &credential,
+	})
+	if !errors.Is(err, ErrControlSignalOutOfScope) {
+		t.Fatalf("expected out-of-scope error, got %v", err)
+	}
+}
+
+func agentEnvValue(env []string, key string) string {
+	for _, entry := range env {
+		if k, v, ok := strings.Cut(entry, "="); ok && k == key {
+			return v
+		}
+	}
+	return ""
+}
+
+func TestForumWritesRejectOutOfScopeCredential(t *testing.T) {
+	service := newTestService(t)
+	credential := APIPrincipal{Role: model.APIRoleAgent, ActorName: "agent", RunID: "run-1", AgentName: "agent"}
+	open := ForumOpenThreadOptions{
+		Ticket:     "METAWSM-1",
+		RunID:      "run-2",
+		AgentName:  "agent",
+		Title:      "Question",
+		Body:       "Which run am I?",
+		ActorType:  model.ForumActorAgent,
+		ActorName:  "agent",
+		Credential: &credential,
+	}
+	if _, err := service.ForumOpenThread(context.Background(), open); !errors.Is(err, ErrForumWriteOutOfScope) {
+		t.Fatalf("expected out-of-scope error opening another run's thread, got %v", err)
+	}
+	open.RunID = ""
+	if _, err := service.ForumOpenThread(context.Background(), open); !errors.Is(err, ErrForumWriteOutOfScope) {
+		t.Fatalf("expected out-of-scope error opening a thread for another ticket, got %v", err)
+	}
+	open.RunID = "run-1"
+	if _, err := service.ForumOpenThread(context.Background(), open); err != nil {
+		t.Fatalf("open own thread: %v", err)
+	}
+
+	open.RunID = "run-2"
+	open.Credential = nil
+	other, err := service.ForumOpenThread(context.Background(), open)
+	if err != nil {
+		t.Fatalf("open other run thread: %v", err)
+	}
+	_, err = service.ForumAddPost(context.Background(), ForumAddPostOptions{
+		ThreadID:   other.ThreadID,
+		Body:       "me too",
+		ActorType:  model.ForumActorAgent,
+		ActorName:  "agent",
+		Credential: &credential,
+	})
+	if !errors.Is(err, ErrForumWriteOutOfScope) {
+		t.Fatalf("expected out-of-scope error posting to another run's thread, got %v", err)
+	}
+}
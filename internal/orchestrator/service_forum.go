@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
+	"os/exec"
 	"sort"
 	"strings"
 	"time"
@@ -20,19 +22,27 @@ type ForumOpenThreadOptions struct {
 	Title         string
 	Body          string
 	Priority      model.ForumPriority
+	Attachments   []model.ForumAttachment
 	ActorType     model.ForumActorType
 	ActorName     string
 	CorrelationID string
 	CausationID   string
+	// Credential is the authenticated caller, when there is one. Scoped agent
+	// credentials may only open threads for their own run.
+	Credential *APIPrincipal
 }
 
 type ForumAddPostOptions struct {
 	ThreadID      string
 	Body          string
+	Attachments   []model.ForumAttachment
 	ActorType     model.ForumActorType
 	ActorName     string
 	CorrelationID string
 	CausationID   string
+	// Credential is the authenticated caller, when there is one. Scoped agent
+	// credentials may only post to threads for their own run.
+	Credential *APIPrincipal
 }
 
 type ForumAssignThreadOptions struct {
@@ -65,9 +75,10 @@ type ForumSetPriorityOptions struct {
 }
 
 type ForumThreadDetail struct {
-	Thread model.ForumThreadView `json:"thread"`
-	Posts  []model.ForumPost     `json:"posts"`
-	Events []model.ForumEvent    `json:"events"`
+	Thread      model.ForumThreadView   `json:"thread"`
+	Posts       []model.ForumPost       `json:"posts"`
+	Events      []model.ForumEvent      `json:"events"`
+	Escalations []model.ForumEscalation `json:"escalations"`
 }
 
 type ForumSearchThreadsOptions struct {
@@ -77,6 +88,8 @@ type ForumSearchThreadsOptions struct {
 	State      model.ForumThreadState
 	Priority   model.ForumPriority
 	Assignee   string
+	ActorType  model.ForumActorType
+	ActorName  string
 	ViewerType model.ForumViewerType
 	ViewerID   string
 	Limit      int
@@ -112,6 +125,9 @@ type ForumControlSignalOptions struct {
 	CorrelationID string
 	CausationID   string
 	Payload       model.ForumControlPayloadV1
+	// Credential is the authenticated caller, when there is one. Scoped agent
+	// credentials may only signal for their own run and agent.
+	Credential *APIPrincipal
 }
 
 type ForumDebugOptions struct {
@@ -120,6 +136,42 @@ type ForumDebugOptions struct {
 	Limit  int
 }
 
+type ForumOutboxListOptions struct {
+	Status model.ForumOutboxStatus
+	Limit  int
+}
+
+// ForumOutboxRetryOptions selects outbox messages to requeue: the listed
+// message IDs, or every dead letter when All is set.
+type ForumOutboxRetryOptions struct {
+	MessageIDs []string
+	All        bool
+}
+
+type ForumOutboxPurgeOptions struct {
+	Status    model.ForumOutboxStatus
+	OlderThan time.Duration
+}
+
+// ForumRebuildProjectionsOptions scopes a projection rebuild. Empty
+// Projections rebuilds all of them; empty Ticket rebuilds every ticket.
+type ForumRebuildProjectionsOptions struct {
+	Ticket      string
+	Projections []string
+}
+
+// ForumEscalationPassOptions controls one SLA escalation pass. Now defaults to
+// the current time.
+type ForumEscalationPassOptions struct {
+	Now time.Time
+}
+
+type ForumEscalationPassResult struct {
+	Checked      int                     `json:"checked"`
+	Escalated    []model.ForumEscalation `json:"escalated"`
+	NotifyErrors []string                `json:"notify_errors,omitempty"`
+}
+
 func (s *Service) registerForumBusHandlers() error {
 	if s.forumBus == nil {
 		return fmt.Errorf("forum bus runtime not configured")
@@ -207,16 +259,25 @@ func (s *Service) registerForumBusHandlers() error {
 				return s.publishForumEventByID(ctx, cmd.Envelope.EventID)
 			},
 		},
+		{
+			topic: s.forumTopics.CommandTopic("escalate_thread"),
+			handler: func(ctx context.Context, message model.ForumOutboxMessage) error {
+				var cmd model.ForumEscalateThreadCommand
+				if err := json.Unmarshal([]byte(message.PayloadJSON), &cmd); err != nil {
+					return fmt.Errorf("decode escalate_thread command: %w", err)
+				}
+				if _, err := s.store.ForumEscalateThread(cmd); err != nil {
+					return err
+				}
+				return s.publishForumEventByID(ctx, cmd.Envelope.EventID)
+			},
+		},
+		{
+			topic:   s.forumTopics.IntegrationTopic("webhook"),
+			handler: s.deliverWebhook,
+		},
 	}
-	for _, eventType := range []string{
-		"forum.thread.opened",
-		"forum.post.added",
-		"forum.control.signal",
-		"forum.assigned",
-		"forum.state.changed",
-		"forum.priority.changed",
-		"forum.thread.closed",
-	} {
+	for _, eventType := range model.ForumProjectedEventTypes {
 		eventTopic := forumEventTopicForType(s.forumTopics, eventType)
 		if eventTopic == "" {
 			continue
@@ -290,6 +351,19 @@ func (s *Service) ForumOpenThread(ctx context.Context, options ForumOpenThreadOp
 	if err != nil {
 		return model.ForumThreadView{}, err
 	}
+	if credential := options.Credential; credential != nil && credential.Scoped() {
+		if agentName := strings.TrimSpace(options.AgentName); agentName != "" && agentName != credential.AgentName {
+			return model.ForumThreadView{}, fmt.Errorf("%w: credential is scoped to agent %s", ErrForumWriteOutOfScope, credential.AgentName)
+		}
+	}
+	if err := s.authorizeForumWrite(options.Credential, options.RunID, ticket); err != nil {
+		return model.ForumThreadView{}, err
+	}
+
+	attachments, err := s.storeForumAttachments(options.Attachments)
+	if err != nil {
+		return model.ForumThreadView{}, err
+	}
 
 	threadID := strings.TrimSpace(options.ThreadID)
 	if threadID == "" {
@@ -316,9 +390,10 @@ func (s *Service) ForumOpenThread(ctx context.Context, options ForumOpenThreadOp
 			CorrelationID: correlationID,
 			CausationID:   strings.TrimSpace(options.CausationID),
 		},
-		Title:    title,
-		Body:     body,
-		Priority: priority,
+		Title:       title,
+		Body:        body,
+		Priority:    priority,
+		Attachments: attachments,
 	}
 	if err := s.dispatchForumCommand(ctx, s.forumTopics.CommandTopic("open_thread"), threadID, cmd); err != nil {
 		return model.ForumThreadView{}, err
@@ -357,6 +432,13 @@ func (s *Service) ForumAddPost(ctx context.Context, options ForumAddPostOptions)
 	if current.State == model.ForumThreadStateClosed {
 		return model.ForumThreadView{}, fmt.Errorf("forum thread %s is closed", threadID)
 	}
+	if err := s.authorizeForumWrite(options.Credential, current.RunID, current.Ticket); err != nil {
+		return model.ForumThreadView{}, err
+	}
+	attachments, err := s.storeForumAttachments(options.Attachments)
+	if err != nil {
+		return model.ForumThreadView{}, err
+	}
 
 	eventID := generateForumID("fevt")
 	correlationID := strings.TrimSpace(options.CorrelationID)
@@ -378,7 +460,8 @@ func (s *Service) ForumAddPost(ctx context.Context, options ForumAddPostOptions)
 			CorrelationID: correlationID,
 			CausationID:   strings.TrimSpace(options.CausationID),
 		},
-		Body: body,
+		Body:        body,
+		Attachments: attachments,
 	}
 	if err := s.dispatchForumCommand(ctx, s.forumTopics.CommandTopic("add_post"), threadID, cmd); err != nil {
 		return model.ForumThreadView{}, err
@@ -419,6 +502,10 @@ func (s *Service) ForumAnswerThread(ctx context.Context, options ForumAddPostOpt
 	if err != nil {
 		return model.ForumThreadView{}, err
 	}
+	attachments, err := s.storeForumAttachments(options.Attachments)
+	if err != nil {
+		return model.ForumThreadView{}, err
+	}
 	addCommand := model.ForumAddPostCommand{
 		Envelope: model.ForumEnvelope{
 			EventID:       addEventID,
@@ -434,7 +521,8 @@ func (s *Service) ForumAnswerThread(ctx context.Context, options ForumAddPostOpt
 			CorrelationID: correlationID,
 			CausationID:   strings.TrimSpace(options.CausationID),
 		},
-		Body: strings.TrimSpace(options.Body),
+		Body:        strings.TrimSpace(options.Body),
+		Attachments: attachments,
 	}
 	if err := s.dispatchForumCommand(ctx, s.forumTopics.CommandTopic("add_post"), threadID, addCommand); err != nil {
 		return model.ForumThreadView{}, err
@@ -725,11 +813,173 @@ func (s *Service) ForumGetThread(threadID string) (*ForumThreadDetail, error) {
 	if err != nil {
 		return nil, err
 	}
+	s.loadForumAttachmentContent(posts)
 	events, err := s.store.ListForumThreadEvents(thread.ThreadID, 500)
 	if err != nil {
 		return nil, err
 	}
-	return &ForumThreadDetail{Thread: *thread, Posts: posts, Events: events}, nil
+	escalations, err := s.store.ListForumEscalations(thread.ThreadID)
+	if err != nil {
+		return nil, err
+	}
+	return &ForumThreadDetail{Thread: *thread, Posts: posts, Events: events, Escalations: escalations}, nil
+}
+
+// forumEscalationPageSize bounds each page of the escalation sweep.
+const forumEscalationPageSize = 500
+
+// ForumEscalateOverdueThreads escalates every waiting_operator/waiting_human
+// thread that has not moved within forum.sla.escalation_minutes. Each
+// escalation bumps priority one step, hands the thread to the next assignee in
+// the ticket's escalation chain, and runs forum.sla.notify_command. The
+// escalation itself updates the thread, so a thread that stays ignored is
+// escalated again one SLA window later.
+func (s *Service) ForumEscalateOverdueThreads(ctx context.Context, options ForumEscalationPassOptions) (ForumEscalationPassResult, error) {
+	cfg, _, err := policy.Load("")
+	if err != nil {
+		cfg = policy.Default()
+	}
+	now := options.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	slaMinutes := cfg.Forum.SLA.EscalationMinutes
+	if slaMinutes <= 0 {
+		slaMinutes = 30
+	}
+	slaThreshold := time.Duration(slaMinutes) * time.Minute
+
+	controlThreads, err := s.store.ListForumControlThreads("")
+	if err != nil {
+		return ForumEscalationPassResult{}, err
+	}
+	controlThreadIDs := make(map[string]struct{}, len(controlThreads))
+	for _, mapping := range controlThreads {
+		controlThreadIDs[mapping.ThreadID] = struct{}{}
+	}
+
+	result := ForumEscalationPassResult{Escalated: []model.ForumEscalation{}}
+	for _, state := range []model.ForumThreadState{model.ForumThreadStateWaitingOperator, model.ForumThreadStateWaitingHuman} {
+		afterThreadID := ""
+		for {
+			threads, err := s.store.ListForumThreadsAfter(state, afterThreadID, forumEscalationPageSize)
+			if err != nil {
+				return result, err
+			}
+			for _, thread := range threads {
+				if _, isControl := controlThreadIDs[thread.ThreadID]; isControl {
+					continue
+				}
+				result.Checked++
+				waited := now.Sub(thread.UpdatedAt)
+				if waited < slaThreshold {
+					continue
+				}
+				escalation, err := s.forumEscalateThread(ctx, cfg, thread, now, fmt.Sprintf(
+					"%s for %s exceeds %s SLA", thread.State, waited.Round(time.Minute), slaThreshold,
+				))
+				if err != nil {
+					return result, err
+				}
+				result.Escalated = append(result.Escalated, escalation)
+				if err := runForumEscalationNotifyCommand(ctx, cfg.Forum.SLA.NotifyCommand, thread, escalation); err != nil {
+					result.NotifyErrors = append(result.NotifyErrors, fmt.Sprintf("%s: %v", thread.ThreadID, err))
+				}
+			}
+			if len(threads) < forumEscalationPageSize {
+				break
+			}
+			afterThreadID = threads[len(threads)-1].ThreadID
+		}
+	}
+	return result, nil
+}
+
+func (s *Service) forumEscalateThread(ctx context.Context, cfg policy.Config, thread model.ForumThreadView, now time.Time, reason string) (model.ForumEscalation, error) {
+	level, err := s.store.ForumEscalationLevel(thread.ThreadID)
+	if err != nil {
+		return model.ForumEscalation{}, err
+	}
+	level++
+	eventID := generateForumID("fevt")
+	cmd := model.ForumEscalateThreadCommand{
+		Envelope: model.ForumEnvelope{
+			EventID:       eventID,
+			EventType:     "forum.thread_escalated",
+			EventVersion:  1,
+			OccurredAt:    now,
+			ThreadID:      thread.ThreadID,
+			RunID:         thread.RunID,
+			Ticket:        thread.Ticket,
+			AgentName:     thread.AgentName,
+			ActorType:     model.ForumActorSystem,
+			ActorName:     "sla",
+			CorrelationID: eventID,
+		},
+		Level:    level,
+		Priority: nextForumPriority(thread.Priority),
+		Reason:   reason,
+	}
+	if chain := policy.ForumEscalationChainFor(cfg, thread.Ticket); len(chain) > 0 {
+		step := chain[min(level, len(chain))-1]
+		cmd.AssigneeType = model.ForumActorType(strings.TrimSpace(strings.ToLower(step.Type)))
+		cmd.AssigneeName = strings.TrimSpace(step.Name)
+	}
+	if err := s.dispatchForumCommand(ctx, s.forumTopics.CommandTopic("escalate_thread"), thread.ThreadID, cmd); err != nil {
+		return model.ForumEscalation{}, err
+	}
+	escalations, err := s.store.ListForumEscalations(thread.ThreadID)
+	if err != nil {
+		return model.ForumEscalation{}, err
+	}
+	for _, escalation := range escalations {
+		if escalation.EventID == eventID {
+			return escalation, nil
+		}
+	}
+	return model.ForumEscalation{}, fmt.Errorf("forum escalation %s for thread %s was not recorded", eventID, thread.ThreadID)
+}
+
+// nextForumPriority is one step more urgent than priority; urgent stays urgent.
+func nextForumPriority(priority model.ForumPriority) model.ForumPriority {
+	switch priority {
+	case model.ForumPriorityLow:
+		return model.ForumPriorityNormal
+	case model.ForumPriorityNormal:
+		return model.ForumPriorityHigh
+	default:
+		return model.ForumPriorityUrgent
+	}
+}
+
+func runForumEscalationNotifyCommand(ctx context.Context, notifyCmd string, thread model.ForumThreadView, escalation model.ForumEscalation) error {
+	notifyCmd = strings.TrimSpace(notifyCmd)
+	if notifyCmd == "" {
+		return nil
+	}
+	cmd := exec.CommandContext(ctx, "zsh", "-lc", notifyCmd)
+	cmd.Env = append(os.Environ(),
+		"METAWSM_EVENT=forum.thread_escalated",
+		"METAWSM_MESSAGE="+escalation.Reason,
+		"METAWSM_THREAD_ID="+thread.ThreadID,
+		"METAWSM_THREAD_TITLE="+thread.Title,
+		"METAWSM_TICKET="+thread.Ticket,
+		"METAWSM_RUN_ID="+thread.RunID,
+		"METAWSM_THREAD_STATE="+string(escalation.State),
+		"METAWSM_PRIORITY="+string(escalation.ToPriority),
+		"METAWSM_ASSIGNEE_TYPE="+string(escalation.ToAssigneeType),
+		"METAWSM_ASSIGNEE_NAME="+escalation.ToAssigneeName,
+		fmt.Sprintf("METAWSM_ESCALATION_LEVEL=%d", escalation.Level),
+	)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		text := strings.TrimSpace(string(out))
+		if text == "" {
+			return err
+		}
+		return fmt.Errorf("%w: %s", err, text)
+	}
+	return nil
 }
 
 func (s *Service) ForumListStats(ticket string, runID string) ([]model.ForumThreadStats, error) {
@@ -763,6 +1013,12 @@ func (s *Service) ForumWatchEvents(ticket string, cursor int64, limit int) ([]mo
 	return s.store.WatchForumEvents(strings.TrimSpace(ticket), cursor, limit)
 }
 
+// ForumLatestEventSequence returns the newest stored forum event sequence, the
+// point a stream without a resume cursor starts from.
+func (s *Service) ForumLatestEventSequence() (int64, error) {
+	return s.store.LastForumEventSequence()
+}
+
 func (s *Service) ForumSearchThreads(options ForumSearchThreadsOptions) ([]model.ForumThreadView, error) {
 	return s.store.SearchForumThreads(model.ForumThreadSearchFilter{
 		Query:      strings.TrimSpace(options.Query),
@@ -771,6 +1027,8 @@ func (s *Service) ForumSearchThreads(options ForumSearchThreadsOptions) ([]model
 		State:      options.State,
 		Priority:   options.Priority,
 		Assignee:   strings.TrimSpace(options.Assignee),
+		ActorType:  model.ForumActorType(strings.TrimSpace(string(options.ActorType))),
+		ActorName:  strings.TrimSpace(options.ActorName),
 		ViewerType: options.ViewerType,
 		ViewerID:   strings.TrimSpace(options.ViewerID),
 		Limit:      options.Limit,
@@ -837,6 +1095,10 @@ func (s *Service) ForumOutboxStats() (model.ForumOutboxStats, error) {
 	if err != nil {
 		return model.ForumOutboxStats{}, err
 	}
+	deadLetterCount, err := s.store.CountForumOutboxByStatus(model.ForumOutboxStatusDeadLetter)
+	if err != nil {
+		return model.ForumOutboxStats{}, err
+	}
 	oldestPendingAt, err := s.store.OldestForumOutboxCreatedAt(model.ForumOutboxStatusPending)
 	if err != nil {
 		return model.ForumOutboxStats{}, err
@@ -846,6 +1108,7 @@ func (s *Service) ForumOutboxStats() (model.ForumOutboxStats, error) {
 		PendingCount:    pendingCount,
 		ProcessingCount: processingCount,
 		FailedCount:     failedCount,
+		DeadLetterCount: deadLetterCount,
 		OldestPendingAt: oldestPendingAt,
 	}
 	if oldestPendingAt != nil {
@@ -858,6 +1121,69 @@ func (s *Service) ForumOutboxStats() (model.ForumOutboxStats, error) {
 	return stats, nil
 }
 
+func (s *Service) ForumListOutbox(options ForumOutboxListOptions) ([]model.ForumOutboxMessage, error) {
+	limit := options.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 500 {
+		limit = 500
+	}
+	status := model.ForumOutboxStatus(strings.TrimSpace(string(options.Status)))
+	if status == "" {
+		return s.store.ListRecentForumOutbox(limit)
+	}
+	if err := validateForumOutboxStatus(status); err != nil {
+		return nil, err
+	}
+	return s.store.ListForumOutboxByStatus(status, limit)
+}
+
+// ForumRetryOutbox requeues dead-lettered (or failed) outbox messages for a
+// fresh round of delivery attempts and returns how many were requeued.
+func (s *Service) ForumRetryOutbox(options ForumOutboxRetryOptions) (int, error) {
+	messageIDs := make([]string, 0, len(options.MessageIDs))
+	for _, messageID := range options.MessageIDs {
+		if messageID = strings.TrimSpace(messageID); messageID != "" {
+			messageIDs = append(messageIDs, messageID)
+		}
+	}
+	if len(messageIDs) == 0 && !options.All {
+		return 0, fmt.Errorf("message ids are required unless all dead letters are retried")
+	}
+	if len(messageIDs) > 0 && options.All {
+		return 0, fmt.Errorf("message ids and all are mutually exclusive")
+	}
+	return s.store.RetryForumOutbox(messageIDs)
+}
+
+func (s *Service) ForumPurgeOutbox(options ForumOutboxPurgeOptions) (int, error) {
+	status := model.ForumOutboxStatus(strings.TrimSpace(string(options.Status)))
+	if status == "" {
+		status = model.ForumOutboxStatusSent
+	}
+	return s.store.PurgeForumOutbox(status, options.OlderThan)
+}
+
+// ForumRebuildProjections replays forum_events into freshly truncated
+// projection tables and reports where the live tables had drifted.
+func (s *Service) ForumRebuildProjections(options ForumRebuildProjectionsOptions) (model.ForumProjectionRebuildReport, error) {
+	return s.store.RebuildForumProjections(strings.TrimSpace(options.Ticket), options.Projections)
+}
+
+func validateForumOutboxStatus(status model.ForumOutboxStatus) error {
+	switch status {
+	case model.ForumOutboxStatusPending,
+		model.ForumOutboxStatusProcessing,
+		model.ForumOutboxStatusSent,
+		model.ForumOutboxStatusFailed,
+		model.ForumOutboxStatusDeadLetter:
+		return nil
+	default:
+		return fmt.Errorf("invalid outbox status %q (expected pending|processing|sent|failed|dead_letter)", status)
+	}
+}
+
 func (s *Service) ForumStreamDebugSnapshot(ctx context.Context, options ForumDebugOptions) (model.ForumStreamDebugSnapshot, error) {
 	ticket := strings.TrimSpace(options.Ticket)
 	runID := strings.TrimSpace(options.RunID)
@@ -945,6 +1271,11 @@ func (s *Service) ForumAppendControlSignal(ctx context.Context, options ForumCon
 	if payload.AgentName != strings.TrimSpace(options.AgentName) {
 		return model.ForumThreadView{}, fmt.Errorf("forum control payload agent_name mismatch")
 	}
+	if credential := options.Credential; credential != nil && credential.Scoped() {
+		if payload.RunID != credential.RunID || payload.AgentName != credential.AgentName {
+			return model.ForumThreadView{}, fmt.Errorf("%w: credential is scoped to %s/%s", ErrControlSignalOutOfScope, credential.RunID, credential.AgentName)
+		}
+	}
 	thread, err := s.ensureForumControlThread(payload.RunID, payload.AgentName, strings.TrimSpace(options.Ticket))
 	if err != nil {
 		return model.ForumThreadView{}, err
@@ -985,6 +1316,9 @@ func (s *Service) ForumAppendControlSignal(ctx context.Context, options ForumCon
 	if next == nil {
 		return model.ForumThreadView{}, fmt.Errorf("forum control append returned nil thread")
 	}
+	// The signal is already recorded, so library upkeep is best effort;
+	// `forum guidance sync` backfills answers a failure here missed.
+	_ = s.applyGuidanceLibrary(ctx, *next, payload, eventID, strings.TrimSpace(options.ActorName))
 	return *next, nil
 }
 
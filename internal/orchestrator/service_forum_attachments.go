@@ -0,0 +1,92 @@
+package orchestrator
+
+import (
+	"fmt"
+	"strings"
+
+	"metawsm/internal/model"
+)
+
+const (
+	// maxForumAttachmentBytes caps a single attachment's content.
+	maxForumAttachmentBytes    = 512 * 1024
+	maxForumAttachmentsPerPost = 16
+)
+
+// storeForumAttachments validates attachments, writes their content to the
+// attachment store, and returns metadata-only copies for the post command.
+func (s *Service) storeForumAttachments(attachments []model.ForumAttachment) ([]model.ForumAttachment, error) {
+	if len(attachments) == 0 {
+		return nil, nil
+	}
+	if len(attachments) > maxForumAttachmentsPerPost {
+		return nil, fmt.Errorf("forum posts accept at most %d attachments", maxForumAttachmentsPerPost)
+	}
+	out := make([]model.ForumAttachment, 0, len(attachments))
+	for i, attachment := range attachments {
+		normalized, err := normalizeForumAttachment(attachment)
+		if err != nil {
+			return nil, fmt.Errorf("forum attachment %d: %w", i+1, err)
+		}
+		digest, err := s.store.PutForumAttachmentContent([]byte(normalized.Content))
+		if err != nil {
+			return nil, err
+		}
+		normalized.Digest = digest
+		normalized.Size = int64(len(normalized.Content))
+		normalized.Content = ""
+		out = append(out, normalized)
+	}
+	return out, nil
+}
+
+// loadForumAttachmentContent fills Content on every attachment of posts.
+// Content missing from the attachment store is left empty rather than
+// failing the whole thread read.
+func (s *Service) loadForumAttachmentContent(posts []model.ForumPost) {
+	for i := range posts {
+		for j := range posts[i].Attachments {
+			content, err := s.store.ReadForumAttachmentContent(posts[i].Attachments[j].Digest)
+			if err != nil {
+				continue
+			}
+			posts[i].Attachments[j].Content = string(content)
+		}
+	}
+}
+
+func normalizeForumAttachment(attachment model.ForumAttachment) (model.ForumAttachment, error) {
+	attachment.Kind = model.ForumAttachmentKind(strings.TrimSpace(strings.ToLower(string(attachment.Kind))))
+	attachment.Title = strings.TrimSpace(attachment.Title)
+	attachment.Repo = strings.TrimSpace(attachment.Repo)
+	attachment.Path = strings.TrimSpace(attachment.Path)
+	attachment.Command = strings.TrimSpace(attachment.Command)
+	attachment.Digest = ""
+	attachment.Size = 0
+	if strings.TrimSpace(attachment.Content) == "" {
+		return model.ForumAttachment{}, fmt.Errorf("content is required")
+	}
+	if len(attachment.Content) > maxForumAttachmentBytes {
+		return model.ForumAttachment{}, fmt.Errorf("content exceeds %d bytes", maxForumAttachmentBytes)
+	}
+	if attachment.StartLine < 0 || attachment.EndLine < 0 || (attachment.EndLine > 0 && attachment.EndLine < attachment.StartLine) {
+		return model.ForumAttachment{}, fmt.Errorf("invalid line range %d-%d", attachment.StartLine, attachment.EndLine)
+	}
+	switch attachment.Kind {
+	case model.ForumAttachmentSnippet:
+		if attachment.Path == "" {
+			return model.ForumAttachment{}, fmt.Errorf("snippet attachments require path")
+		}
+	case model.ForumAttachmentDiff, model.ForumAttachmentLog:
+	case model.ForumAttachmentCommandOutput:
+		if attachment.Command == "" {
+			return model.ForumAttachment{}, fmt.Errorf("command_output attachments require command")
+		}
+	default:
+		return model.ForumAttachment{}, fmt.Errorf("kind must be one of snippet|diff|log|command_output")
+	}
+	if attachment.Kind != model.ForumAttachmentCommandOutput {
+		attachment.ExitCode = nil
+	}
+	return attachment, nil
+}
@@ -0,0 +1,89 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"metawsm/internal/model"
+)
+
+func TestForumPostAttachmentsAreStoredContentAddressed(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "metawsm.db")
+	svc, err := NewService(dbPath)
+	if err != nil {
+		t.Fatalf("new service: %v", err)
+	}
+
+	diff := "--- a/main.go\n+++ b/main.go\n@@ -1 +1 @@\n-old\n+new\n"
+	exitCode := 1
+	thread, err := svc.ForumOpenThread(t.Context(), ForumOpenThreadOptions{
+		Ticket:    "METAWSM-017",
+		RunID:     "run-attach-1",
+		Title:     "Review this change",
+		Body:      "Does this diff look right?",
+		ActorType: model.ForumActorAgent,
+		ActorName: "agent-a",
+		Attachments: []model.ForumAttachment{
+			{Kind: model.ForumAttachmentDiff, Content: diff},
+			{Kind: model.ForumAttachmentCommandOutput, Command: "go test ./...", ExitCode: &exitCode, Content: "FAIL\n"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("forum open thread: %v", err)
+	}
+	if _, err := svc.ForumAddPost(t.Context(), ForumAddPostOptions{
+		ThreadID:  thread.ThreadID,
+		Body:      "Same diff again, plus the function.",
+		ActorType: model.ForumActorAgent,
+		ActorName: "agent-a",
+		Attachments: []model.ForumAttachment{
+			{Kind: model.ForumAttachmentDiff, Content: diff},
+			{Kind: model.ForumAttachmentSnippet, Repo: "metawsm", Path: "main.go", StartLine: 10, EndLine: 12, Content: "func main() {\n}\n"},
+		},
+	}); err != nil {
+		t.Fatalf("forum add post: %v", err)
+	}
+	if _, err := svc.ForumAddPost(t.Context(), ForumAddPostOptions{
+		ThreadID:    thread.ThreadID,
+		Body:        "Missing path.",
+		ActorType:   model.ForumActorAgent,
+		ActorName:   "agent-a",
+		Attachments: []model.ForumAttachment{{Kind: model.ForumAttachmentSnippet, Content: "x"}},
+	}); err == nil || !strings.Contains(err.Error(), "require path") {
+		t.Fatalf("expected snippet without path to be rejected, got %v", err)
+	}
+
+	detail, err := svc.ForumGetThread(thread.ThreadID)
+	if err != nil || detail == nil {
+		t.Fatalf("forum get thread: %v", err)
+	}
+	if len(detail.Posts) != 2 {
+		t.Fatalf("expected two posts, got %d", len(detail.Posts))
+	}
+	opening, followUp := detail.Posts[0], detail.Posts[1]
+	if len(opening.Attachments) != 2 || len(followUp.Attachments) != 2 {
+		t.Fatalf("expected two attachments per post, got %d and %d", len(opening.Attachments), len(followUp.Attachments))
+	}
+	if opening.Attachments[0].Content != diff || opening.Attachments[0].Digest != followUp.Attachments[0].Digest {
+		t.Fatalf("expected identical diffs to share a digest and load content, got %+v / %+v", opening.Attachments[0], followUp.Attachments[0])
+	}
+	output := opening.Attachments[1]
+	if output.Command != "go test ./..." || output.ExitCode == nil || *output.ExitCode != 1 || output.Content != "FAIL\n" {
+		t.Fatalf("unexpected command output attachment: %+v", output)
+	}
+	snippet := followUp.Attachments[1]
+	if snippet.Repo != "metawsm" || snippet.Path != "main.go" || snippet.StartLine != 10 || snippet.EndLine != 12 {
+		t.Fatalf("unexpected snippet attachment: %+v", snippet)
+	}
+
+	hexDigest := strings.TrimPrefix(opening.Attachments[0].Digest, "sha256:")
+	blob, err := os.ReadFile(filepath.Join(filepath.Dir(dbPath), "attachments", hexDigest[:2], hexDigest))
+	if err != nil {
+		t.Fatalf("read attachment blob: %v", err)
+	}
+	if string(blob) != diff {
+		t.Fatalf("unexpected attachment blob %q", blob)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"metawsm/internal/model"
 )
@@ -16,6 +17,7 @@ type forumControlAgentState struct {
 	Ticket                  string
 	PendingGuidance         bool
 	PendingGuidanceQuestion string
+	PendingGuidanceSince    time.Time
 	CompletionSignaled      bool
 	ValidationStatus        string
 	ValidationDoneCriteria  string
@@ -86,9 +88,11 @@ func (s *Service) forumControlStatesForRun(runID string, agents []model.AgentRec
 			case model.ForumControlTypeGuidanceRequest:
 				state.PendingGuidance = true
 				state.PendingGuidanceQuestion = strings.TrimSpace(payload.Question)
+				state.PendingGuidanceSince = post.CreatedAt
 			case model.ForumControlTypeGuidanceAnswer:
 				state.PendingGuidance = false
 				state.PendingGuidanceQuestion = ""
+				state.PendingGuidanceSince = time.Time{}
 			case model.ForumControlTypeCompletion:
 				state.CompletionSignaled = true
 			case model.ForumControlTypeValidation:
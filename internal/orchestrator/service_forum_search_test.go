@@ -0,0 +1,111 @@
+package orchestrator
+
+import (
+	"strings"
+	"testing"
+
+	"metawsm/internal/model"
+)
+
+func TestForumSearchThreadsUsesFullTextIndex(t *testing.T) {
+	svc := newTestService(t)
+
+	migration, err := svc.ForumOpenThread(t.Context(), ForumOpenThreadOptions{
+		Ticket:    "METAWSM-018",
+		RunID:     "run-search-1",
+		Title:     "Database migration ordering",
+		Body:      "Should the search index migration run before the outbox change?",
+		ActorType: model.ForumActorAgent,
+		ActorName: "agent-a",
+	})
+	if err != nil {
+		t.Fatalf("open migration thread: %v", err)
+	}
+	if _, err := svc.ForumAddPost(t.Context(), ForumAddPostOptions{
+		ThreadID:  migration.ThreadID,
+		Body:      "Run the rebuild after deploying; the projection catches up.",
+		ActorType: model.ForumActorOperator,
+		ActorName: "operator-a",
+	}); err != nil {
+		t.Fatalf("add operator post: %v", err)
+	}
+	caching, err := svc.ForumOpenThread(t.Context(), ForumOpenThreadOptions{
+		Ticket:    "METAWSM-018",
+		RunID:     "run-search-1",
+		Title:     "Cache warmup",
+		Body:      "The warmup job mentions migration once in passing.",
+		ActorType: model.ForumActorAgent,
+		ActorName: "agent-b",
+	})
+	if err != nil {
+		t.Fatalf("open caching thread: %v", err)
+	}
+	control, err := svc.ForumAppendControlSignal(t.Context(), ForumControlSignalOptions{
+		RunID:     "run-search-1",
+		Ticket:    "METAWSM-018",
+		AgentName: "agent-c",
+		ActorType: model.ForumActorAgent,
+		ActorName: "agent-c",
+		Payload: model.ForumControlPayloadV1{
+			SchemaVersion: model.ForumControlSchemaVersion1,
+			ControlType:   model.ForumControlTypeGuidanceRequest,
+			RunID:         "run-search-1",
+			AgentName:     "agent-c",
+			Question:      "Which tokenizer should the index use?",
+		},
+	})
+	if err != nil {
+		t.Fatalf("append control signal: %v", err)
+	}
+
+	ranked, err := svc.ForumSearchThreads(ForumSearchThreadsOptions{Query: "migration"})
+	if err != nil {
+		t.Fatalf("search migration: %v", err)
+	}
+	if len(ranked) != 2 || ranked[0].ThreadID != migration.ThreadID || ranked[1].ThreadID != caching.ThreadID {
+		t.Fatalf("expected title match ranked above body match, got %+v", ranked)
+	}
+	if ranked[0].Match == nil || ranked[0].Match.Hits != 2 || !strings.Contains(ranked[0].Match.Snippet, "<mark>") {
+		t.Fatalf("expected highlighted match details, got %+v", ranked[0].Match)
+	}
+
+	phrase, err := svc.ForumSearchThreads(ForumSearchThreadsOptions{Query: `"rebuild after deploying"`})
+	if err != nil {
+		t.Fatalf("search phrase: %v", err)
+	}
+	if len(phrase) != 1 || phrase[0].ThreadID != migration.ThreadID {
+		t.Fatalf("expected phrase to match the operator post, got %+v", phrase)
+	}
+
+	prefix, err := svc.ForumSearchThreads(ForumSearchThreadsOptions{Query: "tokeni*"})
+	if err != nil {
+		t.Fatalf("search prefix: %v", err)
+	}
+	if len(prefix) != 1 || prefix[0].ThreadID != control.ThreadID || prefix[0].Match.DocKind != "control" {
+		t.Fatalf("expected prefix to match the control question, got %+v", prefix)
+	}
+
+	byActor, err := svc.ForumSearchThreads(ForumSearchThreadsOptions{Query: "migration OR rebuild", ActorType: model.ForumActorOperator})
+	if err != nil {
+		t.Fatalf("search by actor: %v", err)
+	}
+	if len(byActor) != 1 || byActor[0].Match.ActorName != "operator-a" {
+		t.Fatalf("expected actor filter to keep only the operator post, got %+v", byActor)
+	}
+
+	if _, err := svc.ForumSearchThreads(ForumSearchThreadsOptions{Query: `index-migration) AND "`}); err != nil {
+		t.Fatalf("expected punctuation in a query to be treated as text, got %v", err)
+	}
+
+	report, err := svc.ForumRebuildProjections(ForumRebuildProjectionsOptions{Projections: []string{model.ForumProjectionSearchDocuments}})
+	if err != nil {
+		t.Fatalf("rebuild search projection: %v", err)
+	}
+	if len(report.Diffs) != 0 || len(report.Counts) != 1 || report.Counts[0].RebuiltRows != 8 {
+		t.Fatalf("expected search documents to rebuild without drift, got %+v", report)
+	}
+	rebuilt, err := svc.ForumSearchThreads(ForumSearchThreadsOptions{Query: "migration"})
+	if err != nil || len(rebuilt) != 2 {
+		t.Fatalf("expected search to work after rebuild, got %d threads (%v)", len(rebuilt), err)
+	}
+}
@@ -0,0 +1,513 @@
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"metawsm/internal/model"
+	"metawsm/internal/policy"
+)
+
+// ErrGuidanceAnswerNotFound is returned for an answer_id that is not in the
+// guidance library.
+var ErrGuidanceAnswerNotFound = errors.New("guidance answer not found")
+
+type GuidanceLibraryPromoteOptions struct {
+	// AnswerID copies an existing entry; Question, Context and Answer then
+	// override its text when set.
+	AnswerID  string
+	ScopeType model.GuidanceAnswerScope
+	Scope     string
+	Question  string
+	Context   string
+	Answer    string
+	ActorName string
+}
+
+type GuidanceSuggestOptions struct {
+	// RunID fills in Ticket, Repos and, when Question is empty, the run's
+	// pending guidance question.
+	RunID     string
+	AgentName string
+	Ticket    string
+	Repos     []string
+	Question  string
+	Limit     int
+}
+
+// guidanceStopWords are left out of question similarity; they carry no
+// signal about what is being asked.
+var guidanceStopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "be": true, "can": true, "do": true, "does": true,
+	"for": true, "how": true, "i": true, "in": true, "is": true, "it": true, "of": true, "on": true,
+	"or": true, "should": true, "that": true, "the": true, "this": true, "to": true, "we": true,
+	"what": true, "when": true, "where": true, "which": true, "with": true, "would": true,
+}
+
+func (s *Service) GuidanceLibraryList(filter model.GuidanceAnswerFilter) ([]model.GuidanceAnswer, error) {
+	return s.store.ListGuidanceAnswers(filter)
+}
+
+// GuidanceLibraryPromote turns an answer into canned guidance for a ticket or
+// repo. Promoting a question that already has canned guidance in that scope
+// replaces its answer.
+func (s *Service) GuidanceLibraryPromote(ctx context.Context, options GuidanceLibraryPromoteOptions) (model.GuidanceAnswer, error) {
+	_ = ctx
+	scopeType := model.GuidanceAnswerScope(strings.TrimSpace(strings.ToLower(string(options.ScopeType))))
+	if scopeType != model.GuidanceAnswerScopeTicket && scopeType != model.GuidanceAnswerScopeRepo {
+		return model.GuidanceAnswer{}, fmt.Errorf("guidance scope must be ticket|repo")
+	}
+	scope := strings.TrimSpace(options.Scope)
+	if scope == "" {
+		return model.GuidanceAnswer{}, fmt.Errorf("guidance %s scope is required", scopeType)
+	}
+	now := time.Now()
+	entry := model.GuidanceAnswer{
+		ScopeType: scopeType,
+		Scope:     scope,
+		CreatedBy: strings.TrimSpace(options.ActorName),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if answerID := strings.TrimSpace(options.AnswerID); answerID != "" {
+		source, err := s.store.GetGuidanceAnswer(answerID)
+		if err != nil {
+			return model.GuidanceAnswer{}, err
+		}
+		if source == nil {
+			return model.GuidanceAnswer{}, fmt.Errorf("%w: %s", ErrGuidanceAnswerNotFound, answerID)
+		}
+		entry.Question = source.Question
+		entry.Context = source.Context
+		entry.Answer = source.Answer
+		entry.Ticket = source.Ticket
+		entry.RunID = source.RunID
+		entry.AgentName = source.AgentName
+		entry.ThreadID = source.ThreadID
+		entry.PromotedFrom = source.AnswerID
+	}
+	if v := strings.TrimSpace(options.Question); v != "" {
+		entry.Question = v
+	}
+	if v := strings.TrimSpace(options.Context); v != "" {
+		entry.Context = v
+	}
+	if v := strings.TrimSpace(options.Answer); v != "" {
+		entry.Answer = v
+	}
+	if model.GuidanceQuestionKey(entry.Question) == "" {
+		return model.GuidanceAnswer{}, fmt.Errorf("guidance question is required")
+	}
+	if strings.TrimSpace(entry.Answer) == "" {
+		return model.GuidanceAnswer{}, fmt.Errorf("guidance answer is required")
+	}
+
+	existing, err := s.store.FindGuidanceAnswer(scopeType, scope, entry.Question)
+	if err != nil {
+		return model.GuidanceAnswer{}, err
+	}
+	if existing != nil {
+		existing.Question = entry.Question
+		existing.Context = entry.Context
+		existing.Answer = entry.Answer
+		existing.PromotedFrom = entry.PromotedFrom
+		existing.CreatedBy = entry.CreatedBy
+		existing.UpdatedAt = now
+		if err := s.store.UpdateGuidanceAnswer(*existing); err != nil {
+			return model.GuidanceAnswer{}, err
+		}
+		return *existing, nil
+	}
+	entry.AnswerID = generateForumID("gans")
+	if _, err := s.store.AddGuidanceAnswer(entry); err != nil {
+		return model.GuidanceAnswer{}, err
+	}
+	return entry, nil
+}
+
+func (s *Service) GuidanceLibraryRemove(ctx context.Context, answerID string) error {
+	_ = ctx
+	answerID = strings.TrimSpace(answerID)
+	entry, err := s.store.GetGuidanceAnswer(answerID)
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		return fmt.Errorf("%w: %s", ErrGuidanceAnswerNotFound, answerID)
+	}
+	return s.store.DeleteGuidanceAnswer(answerID)
+}
+
+// GuidanceLibrarySync harvests every answered control thread into the
+// library and returns how many answers were new. Answers already harvested
+// are skipped, so it is safe to run repeatedly.
+func (s *Service) GuidanceLibrarySync(ctx context.Context) (int, error) {
+	_ = ctx
+	mappings, err := s.store.ListForumControlThreads("")
+	if err != nil {
+		return 0, err
+	}
+	added := 0
+	for _, mapping := range mappings {
+		count, err := s.harvestGuidanceAnswers(mapping.ThreadID)
+		if err != nil {
+			return added, err
+		}
+		added += count
+	}
+	return added, nil
+}
+
+// GuidanceSuggest ranks library answers against a question. Canned answers
+// only apply to their own ticket or to repos in the run; harvested history
+// applies everywhere.
+func (s *Service) GuidanceSuggest(options GuidanceSuggestOptions) ([]model.GuidanceSuggestion, error) {
+	cfg, _, err := policy.Load("")
+	if err != nil {
+		return nil, err
+	}
+	runID := strings.TrimSpace(options.RunID)
+	ticket := strings.TrimSpace(options.Ticket)
+	repos := options.Repos
+	question := strings.TrimSpace(options.Question)
+	if runID != "" {
+		if ticket == "" {
+			tickets, err := s.store.GetTickets(runID)
+			if err != nil {
+				return nil, err
+			}
+			if len(tickets) > 0 {
+				ticket = tickets[0]
+			}
+		}
+		if len(repos) == 0 {
+			repos, err = s.guidanceRunRepos(runID)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if question == "" {
+			question, err = s.pendingGuidanceQuestion(runID, strings.TrimSpace(options.AgentName))
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	if question == "" {
+		return nil, fmt.Errorf("question is required (or a run_id with pending guidance)")
+	}
+	limit := options.Limit
+	if limit <= 0 {
+		limit = cfg.Forum.GuidanceLibrary.SuggestLimit
+	}
+	return s.suggestGuidanceAnswers(question, ticket, repos, limit, cfg.Forum.GuidanceLibrary.MinScore)
+}
+
+func (s *Service) suggestGuidanceAnswers(question string, ticket string, repos []string, limit int, minScore float64) ([]model.GuidanceSuggestion, error) {
+	if limit <= 0 {
+		return []model.GuidanceSuggestion{}, nil
+	}
+	entries, err := s.store.ListGuidanceAnswers(model.GuidanceAnswerFilter{})
+	if err != nil {
+		return nil, err
+	}
+	repoSet := map[string]bool{}
+	for _, repo := range repos {
+		repoSet[strings.TrimSpace(repo)] = true
+	}
+	key := model.GuidanceQuestionKey(question)
+	terms := guidanceQuestionTerms(question)
+	candidates := []model.GuidanceSuggestion{}
+	for _, entry := range entries {
+		switch entry.ScopeType {
+		case model.GuidanceAnswerScopeTicket:
+			if entry.Scope != ticket {
+				continue
+			}
+		case model.GuidanceAnswerScopeRepo:
+			if !repoSet[entry.Scope] {
+				continue
+			}
+		}
+		suggestion := model.GuidanceSuggestion{Answer: entry}
+		if model.GuidanceQuestionKey(entry.Question) == key {
+			suggestion.Score = 1
+			suggestion.Exact = true
+		} else {
+			suggestion.Score = guidanceSimilarity(terms, guidanceQuestionTerms(entry.Question))
+		}
+		if suggestion.Score < minScore {
+			continue
+		}
+		candidates = append(candidates, suggestion)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.Exact != b.Exact {
+			return a.Exact
+		}
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if guidanceScopeRank(a.Answer.ScopeType) != guidanceScopeRank(b.Answer.ScopeType) {
+			return guidanceScopeRank(a.Answer.ScopeType) < guidanceScopeRank(b.Answer.ScopeType)
+		}
+		if a.Answer.UseCount != b.Answer.UseCount {
+			return a.Answer.UseCount > b.Answer.UseCount
+		}
+		return a.Answer.UpdatedAt.After(b.Answer.UpdatedAt)
+	})
+	out := []model.GuidanceSuggestion{}
+	seenAnswers := map[string]bool{}
+	for _, candidate := range candidates {
+		answerKey := model.GuidanceQuestionKey(candidate.Answer.Answer)
+		if seenAnswers[answerKey] {
+			continue
+		}
+		seenAnswers[answerKey] = true
+		out = append(out, candidate)
+		if len(out) == limit {
+			break
+		}
+	}
+	return out, nil
+}
+
+// applyGuidanceLibrary runs after a control signal is recorded. Answers are
+// harvested into the library; a new guidance request gets the closest
+// previous answers posted to its thread and, when policy allows, an exact
+// canned match answers it on the spot.
+func (s *Service) applyGuidanceLibrary(ctx context.Context, thread model.ForumThreadView, payload model.ForumControlPayloadV1, eventID string, actorName string) error {
+	cfg, _, err := policy.Load("")
+	if err != nil {
+		return err
+	}
+	if !cfg.Forum.GuidanceLibrary.Enabled {
+		return nil
+	}
+	switch payload.ControlType {
+	case model.ForumControlTypeGuidanceAnswer:
+		if actorName == model.GuidanceAnswerLibraryActor {
+			return nil
+		}
+		_, err := s.harvestGuidanceAnswers(thread.ThreadID)
+		return err
+	case model.ForumControlTypeGuidanceRequest:
+	default:
+		return nil
+	}
+
+	repos, err := s.guidanceRunRepos(payload.RunID)
+	if err != nil {
+		return err
+	}
+	settings := cfg.Forum.GuidanceLibrary
+	suggestions, err := s.suggestGuidanceAnswers(payload.Question, thread.Ticket, repos, settings.SuggestLimit, settings.MinScore)
+	if err != nil {
+		return err
+	}
+	if len(suggestions) == 0 {
+		return nil
+	}
+	if _, err := s.ForumAddPost(ctx, ForumAddPostOptions{
+		ThreadID:    thread.ThreadID,
+		Body:        formatGuidanceSuggestions(suggestions),
+		ActorType:   model.ForumActorSystem,
+		ActorName:   model.GuidanceAnswerLibraryActor,
+		CausationID: eventID,
+	}); err != nil {
+		return err
+	}
+	if !settings.AutoAnswerExact {
+		return nil
+	}
+	for _, suggestion := range suggestions {
+		if !suggestion.Exact || suggestion.Answer.ScopeType == model.GuidanceAnswerScopeHistory {
+			continue
+		}
+		entry := suggestion.Answer
+		if _, err := s.ForumAppendControlSignal(ctx, ForumControlSignalOptions{
+			RunID:       payload.RunID,
+			Ticket:      thread.Ticket,
+			AgentName:   payload.AgentName,
+			ActorType:   model.ForumActorSystem,
+			ActorName:   model.GuidanceAnswerLibraryActor,
+			CausationID: eventID,
+			Payload: model.ForumControlPayloadV1{
+				SchemaVersion: model.ForumControlSchemaVersion1,
+				ControlType:   model.ForumControlTypeGuidanceAnswer,
+				RunID:         payload.RunID,
+				AgentName:     payload.AgentName,
+				Answer:        entry.Answer,
+			},
+		}); err != nil {
+			return err
+		}
+		if err := s.store.RecordGuidanceAnswerUse(entry.AnswerID, time.Now()); err != nil {
+			return err
+		}
+		return s.store.AddEvent(payload.RunID, "guidance", payload.AgentName, "auto_answered", "", "",
+			fmt.Sprintf("answered from %s guidance %s (%s)", entry.ScopeType, entry.AnswerID, entry.Scope))
+	}
+	return nil
+}
+
+// harvestGuidanceAnswers pairs each guidance_answer in a control thread with
+// the request it answered and adds the pairs to the library as history.
+func (s *Service) harvestGuidanceAnswers(threadID string) (int, error) {
+	thread, err := s.store.GetForumThread(threadID)
+	if err != nil {
+		return 0, err
+	}
+	if thread == nil {
+		return 0, nil
+	}
+	posts, err := s.store.ListForumPosts(threadID, 1000)
+	if err != nil {
+		return 0, err
+	}
+	added := 0
+	var pending *model.ForumControlPayloadV1
+	for _, post := range posts {
+		payload, ok := parseForumControlPayload(post.Body)
+		if !ok {
+			continue
+		}
+		switch payload.ControlType {
+		case model.ForumControlTypeGuidanceRequest:
+			request := payload
+			pending = &request
+		case model.ForumControlTypeGuidanceAnswer:
+			request := pending
+			pending = nil
+			if request == nil || post.AuthorName == model.GuidanceAnswerLibraryActor {
+				continue
+			}
+			if model.GuidanceQuestionKey(request.Question) == "" || strings.TrimSpace(payload.Answer) == "" {
+				continue
+			}
+			inserted, err := s.store.AddGuidanceAnswer(model.GuidanceAnswer{
+				AnswerID:      generateForumID("gans"),
+				ScopeType:     model.GuidanceAnswerScopeHistory,
+				Question:      strings.TrimSpace(request.Question),
+				Context:       strings.TrimSpace(request.Context),
+				Answer:        strings.TrimSpace(payload.Answer),
+				Ticket:        thread.Ticket,
+				RunID:         payload.RunID,
+				AgentName:     payload.AgentName,
+				ThreadID:      threadID,
+				SourceEventID: post.EventID,
+				CreatedBy:     post.AuthorName,
+				CreatedAt:     post.CreatedAt,
+				UpdatedAt:     post.CreatedAt,
+			})
+			if err != nil {
+				return added, err
+			}
+			if inserted {
+				added++
+			}
+		}
+	}
+	return added, nil
+}
+
+func (s *Service) guidanceRunRepos(runID string) ([]string, error) {
+	if strings.TrimSpace(runID) == "" {
+		return nil, nil
+	}
+	_, specJSON, _, err := s.store.GetRun(runID)
+	if err != nil {
+		// Control threads may belong to runs this store never started.
+		return nil, nil
+	}
+	var spec model.RunSpec
+	if err := json.Unmarshal([]byte(specJSON), &spec); err != nil {
+		return nil, err
+	}
+	return spec.Repos, nil
+}
+
+func (s *Service) pendingGuidanceQuestion(runID string, agentName string) (string, error) {
+	states, err := s.forumControlStatesForRun(runID, nil)
+	if err != nil {
+		return "", err
+	}
+	names := make([]string, 0, len(states))
+	for name := range states {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		state := states[name]
+		if agentName != "" && name != agentName {
+			continue
+		}
+		if state.PendingGuidance && state.PendingGuidanceQuestion != "" {
+			return state.PendingGuidanceQuestion, nil
+		}
+	}
+	return "", nil
+}
+
+func guidanceQuestionTerms(question string) map[string]bool {
+	terms := map[string]bool{}
+	for _, word := range strings.Fields(model.GuidanceQuestionKey(question)) {
+		if guidanceStopWords[word] {
+			continue
+		}
+		terms[word] = true
+	}
+	return terms
+}
+
+// guidanceSimilarity is the cosine similarity of two term sets.
+func guidanceSimilarity(a map[string]bool, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for term := range a {
+		if b[term] {
+			shared++
+		}
+	}
+	return float64(shared) / math.Sqrt(float64(len(a)*len(b)))
+}
+
+func guidanceScopeRank(scopeType model.GuidanceAnswerScope) int {
+	switch scopeType {
+	case model.GuidanceAnswerScopeTicket:
+		return 0
+	case model.GuidanceAnswerScopeRepo:
+		return 1
+	default:
+		return 2
+	}
+}
+
+func formatGuidanceSuggestions(suggestions []model.GuidanceSuggestion) string {
+	var b strings.Builder
+	b.WriteString("Suggested answers from the guidance library:\n")
+	for i, suggestion := range suggestions {
+		entry := suggestion.Answer
+		source := string(entry.ScopeType)
+		if entry.Scope != "" {
+			source += " " + entry.Scope
+		} else if entry.Ticket != "" {
+			source += " " + entry.Ticket
+		}
+		match := fmt.Sprintf("score %.2f", suggestion.Score)
+		if suggestion.Exact {
+			match = "exact match"
+		}
+		fmt.Fprintf(&b, "\n%d. [%s, %s, %s]\n   Q: %s\n   A: %s\n", i+1, entry.AnswerID, source, match, entry.Question, entry.Answer)
+	}
+	return b.String()
+}
@@ -0,0 +1,149 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"metawsm/internal/model"
+	"metawsm/internal/policy"
+)
+
+func TestGuidanceLibraryHarvestsSuggestsAndAutoAnswers(t *testing.T) {
+	workDir := t.TempDir()
+	t.Chdir(workDir)
+	cfg := policy.Default()
+	cfg.Forum.GuidanceLibrary.AutoAnswerExact = true
+	payload, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatalf("marshal policy: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(workDir, ".metawsm"), 0o755); err != nil {
+		t.Fatalf("mkdir policy dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(workDir, policy.DefaultPolicyPath), payload, 0o644); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+	svc := newTestService(t)
+
+	signal := func(runID string, agent string, controlType model.ForumControlType, text string, actorType model.ForumActorType) model.ForumThreadView {
+		t.Helper()
+		payload := model.ForumControlPayloadV1{
+			SchemaVersion: model.ForumControlSchemaVersion1,
+			ControlType:   controlType,
+			RunID:         runID,
+			AgentName:     agent,
+		}
+		if controlType == model.ForumControlTypeGuidanceRequest {
+			payload.Question = text
+		} else {
+			payload.Answer = text
+		}
+		thread, err := svc.ForumAppendControlSignal(t.Context(), ForumControlSignalOptions{
+			RunID:     runID,
+			Ticket:    "METAWSM-019",
+			AgentName: agent,
+			ActorType: actorType,
+			ActorName: string(actorType) + "-" + agent,
+			Payload:   payload,
+		})
+		if err != nil {
+			t.Fatalf("append %s: %v", controlType, err)
+		}
+		return thread
+	}
+
+	signal("run-lib-1", "agent-a", model.ForumControlTypeGuidanceRequest, "Which test command should I run before committing?", model.ForumActorAgent)
+	signal("run-lib-1", "agent-a", model.ForumControlTypeGuidanceAnswer, "Run make test; the race suite is optional.", model.ForumActorOperator)
+
+	history, err := svc.GuidanceLibraryList(model.GuidanceAnswerFilter{ScopeType: model.GuidanceAnswerScopeHistory})
+	if err != nil {
+		t.Fatalf("list history: %v", err)
+	}
+	if len(history) != 1 || history[0].Question != "Which test command should I run before committing?" || history[0].Ticket != "METAWSM-019" {
+		t.Fatalf("expected the answered question to be harvested, got %+v", history)
+	}
+	added, err := svc.GuidanceLibrarySync(t.Context())
+	if err != nil || added != 0 {
+		t.Fatalf("expected sync to skip harvested answers, got %d (%v)", added, err)
+	}
+
+	suggestions, err := svc.GuidanceSuggest(GuidanceSuggestOptions{Question: "What test command do I run before committing?"})
+	if err != nil {
+		t.Fatalf("suggest: %v", err)
+	}
+	if len(suggestions) != 1 || suggestions[0].Exact || suggestions[0].Answer.AnswerID != history[0].AnswerID {
+		t.Fatalf("expected the harvested answer as a close match, got %+v", suggestions)
+	}
+
+	// A similar question only gets suggestions; history is never auto-answered.
+	similar := signal("run-lib-2", "agent-b", model.ForumControlTypeGuidanceRequest, "Which test command should I run before committing?", model.ForumActorAgent)
+	posts, err := svc.store.ListForumPosts(similar.ThreadID, 100)
+	if err != nil {
+		t.Fatalf("list posts: %v", err)
+	}
+	last := posts[len(posts)-1]
+	if last.AuthorName != model.GuidanceAnswerLibraryActor || !strings.Contains(last.Body, "Run make test") {
+		t.Fatalf("expected a suggestion post after the request, got %+v", posts)
+	}
+
+	if _, err := svc.GuidanceLibraryPromote(t.Context(), GuidanceLibraryPromoteOptions{AnswerID: "gans-missing", ScopeType: model.GuidanceAnswerScopeTicket, Scope: "METAWSM-019"}); !errors.Is(err, ErrGuidanceAnswerNotFound) {
+		t.Fatalf("expected not found promoting a missing answer, got %v", err)
+	}
+	canned, err := svc.GuidanceLibraryPromote(t.Context(), GuidanceLibraryPromoteOptions{
+		AnswerID:  history[0].AnswerID,
+		ScopeType: model.GuidanceAnswerScopeTicket,
+		Scope:     "METAWSM-019",
+		ActorName: "operator-a",
+	})
+	if err != nil {
+		t.Fatalf("promote: %v", err)
+	}
+	if canned.PromotedFrom != history[0].AnswerID || canned.Answer != history[0].Answer {
+		t.Fatalf("unexpected promoted answer %+v", canned)
+	}
+	again, err := svc.GuidanceLibraryPromote(t.Context(), GuidanceLibraryPromoteOptions{
+		ScopeType: model.GuidanceAnswerScopeTicket,
+		Scope:     "METAWSM-019",
+		Question:  "which test command should I run before committing",
+		Answer:    "Run make test and make lint.",
+	})
+	if err != nil || again.AnswerID != canned.AnswerID {
+		t.Fatalf("expected promoting the same question to update the canned answer, got %+v (%v)", again, err)
+	}
+
+	exact := signal("run-lib-3", "agent-c", model.ForumControlTypeGuidanceRequest, "Which test command should I run before committing?", model.ForumActorAgent)
+	posts, err = svc.store.ListForumPosts(exact.ThreadID, 100)
+	if err != nil {
+		t.Fatalf("list posts: %v", err)
+	}
+	answer, ok := parseForumControlPayload(posts[len(posts)-1].Body)
+	if !ok || answer.ControlType != model.ForumControlTypeGuidanceAnswer || answer.Answer != "Run make test and make lint." {
+		t.Fatalf("expected the exact canned match to answer the request, got %+v", posts)
+	}
+	states, err := svc.forumControlStatesForRun("run-lib-3", nil)
+	if err != nil {
+		t.Fatalf("control states: %v", err)
+	}
+	if states["agent-c"].PendingGuidance {
+		t.Fatalf("expected auto-answered guidance to be cleared")
+	}
+	used, err := svc.store.GetGuidanceAnswer(canned.AnswerID)
+	if err != nil || used == nil || used.UseCount != 1 || used.LastUsedAt == nil {
+		t.Fatalf("expected the canned answer use to be recorded, got %+v (%v)", used, err)
+	}
+	history, err = svc.GuidanceLibraryList(model.GuidanceAnswerFilter{ScopeType: model.GuidanceAnswerScopeHistory})
+	if err != nil || len(history) != 1 {
+		t.Fatalf("expected automatic answers to stay out of history, got %d (%v)", len(history), err)
+	}
+
+	if err := svc.GuidanceLibraryRemove(t.Context(), canned.AnswerID); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if err := svc.GuidanceLibraryRemove(t.Context(), canned.AnswerID); !errors.Is(err, ErrGuidanceAnswerNotFound) {
+		t.Fatalf("expected not found removing twice, got %v", err)
+	}
+}
@@ -0,0 +1,390 @@
+package orchestrator
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"metawsm/internal/model"
+	"metawsm/internal/policy"
+	"metawsm/internal/webhook"
+)
+
+// integrationSignatureTolerance bounds how far a signed request's timestamp
+// may drift from now before it is treated as a replay.
+const integrationSignatureTolerance = 5 * time.Minute
+
+var (
+	// ErrIntegrationSourceNotFound is returned for a source name that is not
+	// configured under integrations.sources.
+	ErrIntegrationSourceNotFound = errors.New("integration source not configured")
+	// ErrIntegrationUnauthorized is returned when a payload carries neither a
+	// valid signature nor the source's bearer secret.
+	ErrIntegrationUnauthorized = errors.New("integration request is not authenticated")
+)
+
+var integrationTemplatePattern = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.\-]+)\s*\}\}`)
+
+type IntegrationIngestOptions struct {
+	Source     string
+	Body       []byte
+	Token      string
+	Signature  string
+	Timestamp  string
+	DeliveryID string
+}
+
+type IntegrationIngestResult struct {
+	Source    string              `json:"source"`
+	EventID   string              `json:"event_id"`
+	Duplicate bool                `json:"duplicate,omitempty"`
+	Actions   []IntegrationAction `json:"actions"`
+}
+
+type IntegrationAction struct {
+	Rule     string                 `json:"rule"`
+	Action   string                 `json:"action"`
+	ThreadID string                 `json:"thread_id"`
+	State    model.ForumThreadState `json:"state,omitempty"`
+}
+
+// IngestIntegration authenticates a payload posted by an external system and
+// applies every matching rule of its source as a forum command. A repeated
+// delivery id, or a replayed signed request, is acknowledged without applying
+// the rules again. Each applied
+// rule leaves a receipt keyed by the delivery's event id and the rule name,
+// and the delivery's own receipt is only recorded once every rule succeeded,
+// so a sender retrying after an error resumes with the rules that have not
+// run yet.
+func (s *Service) IngestIntegration(ctx context.Context, options IntegrationIngestOptions) (IntegrationIngestResult, error) {
+	name := strings.TrimSpace(options.Source)
+	cfg, _, err := policy.Load("")
+	if err != nil {
+		return IntegrationIngestResult{}, err
+	}
+	var source *policy.IntegrationSource
+	for i := range cfg.Integrations.Sources {
+		if strings.TrimSpace(cfg.Integrations.Sources[i].Name) == name {
+			source = &cfg.Integrations.Sources[i]
+			break
+		}
+	}
+	if name == "" || source == nil {
+		return IntegrationIngestResult{}, fmt.Errorf("%w: %q", ErrIntegrationSourceNotFound, name)
+	}
+	secret := os.Getenv(strings.TrimSpace(source.SecretEnv))
+	if secret == "" {
+		return IntegrationIngestResult{}, fmt.Errorf("integration source %s secret env %s is not set", name, source.SecretEnv)
+	}
+	if !integrationAuthenticated(secret, options, time.Now()) {
+		return IntegrationIngestResult{}, ErrIntegrationUnauthorized
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(options.Body))
+	decoder.UseNumber()
+	var payload any
+	if err := decoder.Decode(&payload); err != nil {
+		return IntegrationIngestResult{}, fmt.Errorf("decode integration payload: %w", err)
+	}
+
+	eventID := integrationEventID(name, options)
+	result := IntegrationIngestResult{Source: name, EventID: eventID, Actions: []IntegrationAction{}}
+	existing, err := s.store.GetForumEvent(eventID)
+	if err != nil {
+		return IntegrationIngestResult{}, err
+	}
+	if existing != nil {
+		result.Duplicate = true
+		return result, nil
+	}
+
+	for i, rule := range source.Rules {
+		if !integrationRuleMatches(rule.Match, payload) {
+			continue
+		}
+		ruleName := strings.TrimSpace(rule.Name)
+		if ruleName == "" {
+			ruleName = fmt.Sprintf("#%d", i+1)
+		}
+		receipt, err := s.store.GetIntegrationRuleReceipt(eventID, ruleName)
+		if err != nil {
+			return result, err
+		}
+		if receipt != nil {
+			result.Actions = append(result.Actions, IntegrationAction{Rule: ruleName, Action: receipt.Action, ThreadID: receipt.ThreadID, State: receipt.State})
+			continue
+		}
+		action, err := s.applyIntegrationRule(ctx, name, eventID, rule, payload)
+		if err != nil {
+			return result, fmt.Errorf("integration %s rule %s: %w", name, ruleName, err)
+		}
+		action.Rule = ruleName
+		if err := s.store.RecordIntegrationRuleReceipt(model.IntegrationRuleReceipt{
+			EventID:   eventID,
+			Rule:      ruleName,
+			Action:    action.Action,
+			ThreadID:  action.ThreadID,
+			State:     action.State,
+			AppliedAt: time.Now(),
+		}); err != nil {
+			return result, err
+		}
+		result.Actions = append(result.Actions, action)
+	}
+
+	envelope := model.ForumEnvelope{
+		EventID:       eventID,
+		EventType:     "forum.integration." + sanitizeForumIDSegment(name) + ".received",
+		EventVersion:  1,
+		OccurredAt:    time.Now(),
+		ActorType:     model.ForumActorSystem,
+		ActorName:     integrationActorName(name),
+		CorrelationID: eventID,
+	}
+	if len(result.Actions) > 0 {
+		if thread, err := s.store.GetForumThread(result.Actions[0].ThreadID); err == nil && thread != nil {
+			envelope.ThreadID = thread.ThreadID
+			envelope.RunID = thread.RunID
+			envelope.Ticket = thread.Ticket
+		}
+	}
+	if err := s.store.ForumAppendIntegrationEvent(envelope, map[string]any{
+		"source":      name,
+		"delivery_id": strings.TrimSpace(options.DeliveryID),
+		"actions":     result.Actions,
+		"payload":     payload,
+	}); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+func (s *Service) applyIntegrationRule(ctx context.Context, source string, eventID string, rule policy.IntegrationRule, payload any) (IntegrationAction, error) {
+	action := strings.TrimSpace(strings.ToLower(rule.Action))
+	actorName := integrationActorName(source)
+	threadID := renderIntegrationTemplate(rule.ThreadID, payload)
+	body := renderIntegrationTemplate(rule.Body, payload)
+
+	var (
+		thread model.ForumThreadView
+		err    error
+	)
+	switch action {
+	case "open_thread":
+		ticket := renderIntegrationTemplate(rule.Ticket, payload)
+		runID := renderIntegrationTemplate(rule.RunID, payload)
+		if ticket == "" && runID != "" {
+			tickets, err := s.store.GetTickets(runID)
+			if err != nil {
+				return IntegrationAction{}, err
+			}
+			if len(tickets) > 0 {
+				ticket = strings.TrimSpace(tickets[0])
+			}
+		}
+		thread, err = s.ForumOpenThread(ctx, ForumOpenThreadOptions{
+			ThreadID:      threadID,
+			Ticket:        ticket,
+			RunID:         runID,
+			Title:         renderIntegrationTemplate(rule.Title, payload),
+			Body:          body,
+			Priority:      model.ForumPriority(renderIntegrationTemplate(rule.Priority, payload)),
+			ActorType:     model.ForumActorSystem,
+			ActorName:     actorName,
+			CorrelationID: eventID,
+			CausationID:   eventID,
+		})
+	case "add_post":
+		thread, err = s.ForumAddPost(ctx, ForumAddPostOptions{
+			ThreadID:      threadID,
+			Body:          body,
+			ActorType:     model.ForumActorSystem,
+			ActorName:     actorName,
+			CorrelationID: eventID,
+			CausationID:   eventID,
+		})
+	case "answer":
+		thread, err = s.answerIntegrationThread(ctx, threadID, body, actorName, eventID)
+	case "change_state":
+		thread, err = s.ForumChangeState(ctx, ForumChangeStateOptions{
+			ThreadID:      threadID,
+			ToState:       model.ForumThreadState(renderIntegrationTemplate(rule.State, payload)),
+			ActorType:     model.ForumActorSystem,
+			ActorName:     actorName,
+			CorrelationID: eventID,
+			CausationID:   eventID,
+		})
+	default:
+		return IntegrationAction{}, fmt.Errorf("unsupported integration action %q", rule.Action)
+	}
+	if err != nil {
+		return IntegrationAction{}, err
+	}
+	return IntegrationAction{Action: action, ThreadID: thread.ThreadID, State: thread.State}, nil
+}
+
+// answerIntegrationThread answers a thread. When the thread is a run's
+// control thread with a pending guidance request, the answer goes through
+// the guidance flow so the run resumes, exactly as `metawsm guide` would.
+func (s *Service) answerIntegrationThread(ctx context.Context, threadID string, body string, actorName string, eventID string) (model.ForumThreadView, error) {
+	if strings.TrimSpace(threadID) == "" {
+		return model.ForumThreadView{}, fmt.Errorf("forum thread id is required")
+	}
+	thread, err := s.store.GetForumThread(threadID)
+	if err != nil {
+		return model.ForumThreadView{}, err
+	}
+	if thread == nil {
+		return model.ForumThreadView{}, fmt.Errorf("forum thread %s not found", threadID)
+	}
+	if runID := strings.TrimSpace(thread.RunID); runID != "" {
+		agents, err := s.store.GetAgents(runID)
+		if err != nil {
+			return model.ForumThreadView{}, err
+		}
+		states, err := s.forumControlStatesForRun(runID, agents)
+		if err != nil {
+			return model.ForumThreadView{}, err
+		}
+		for _, state := range states {
+			if state.PendingGuidance && state.ThreadID == thread.ThreadID {
+				if _, err := s.guideRun(ctx, runID, thread.ThreadID, body); err != nil {
+					return model.ForumThreadView{}, err
+				}
+				answered, err := s.store.GetForumThread(thread.ThreadID)
+				if err != nil {
+					return model.ForumThreadView{}, err
+				}
+				if answered == nil {
+					return model.ForumThreadView{}, fmt.Errorf("forum guidance answer returned nil thread")
+				}
+				return *answered, nil
+			}
+		}
+	}
+	return s.ForumAnswerThread(ctx, ForumAddPostOptions{
+		ThreadID:      thread.ThreadID,
+		Body:          body,
+		ActorType:     model.ForumActorSystem,
+		ActorName:     actorName,
+		CorrelationID: eventID,
+		CausationID:   eventID,
+	})
+}
+
+// integrationEventID keys a delivery for duplicate detection. Without a
+// delivery id, a signed request is keyed by its signed timestamp and body, so
+// replaying it inside the freshness window is still caught; only unsigned
+// bearer requests without a delivery id get a fresh id each time.
+func integrationEventID(source string, options IntegrationIngestOptions) string {
+	prefix := "fint-" + sanitizeForumIDSegment(source) + "-"
+	if deliveryID := strings.TrimSpace(options.DeliveryID); deliveryID != "" {
+		return prefix + sanitizeForumIDSegment(deliveryID)
+	}
+	if strings.TrimSpace(options.Signature) != "" {
+		digest := sha256.New()
+		digest.Write([]byte(strings.TrimSpace(options.Timestamp)))
+		digest.Write([]byte{'.'})
+		digest.Write(options.Body)
+		return prefix + "sig-" + hex.EncodeToString(digest.Sum(nil))[:32]
+	}
+	return generateForumID("fint")
+}
+
+func integrationActorName(source string) string {
+	return "integration:" + strings.TrimSpace(source)
+}
+
+// integrationAuthenticated accepts either an HMAC signature made with the
+// source secret (the same scheme outbound webhooks use) or the secret itself
+// as a bearer token, for senders that cannot sign.
+func integrationAuthenticated(secret string, options IntegrationIngestOptions, now time.Time) bool {
+	if signature := strings.TrimSpace(options.Signature); signature != "" {
+		return webhook.VerifyFresh(secret, options.Timestamp, options.Body, signature, now, integrationSignatureTolerance)
+	}
+	token := strings.TrimSpace(options.Token)
+	return token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(secret)) == 1
+}
+
+func integrationRuleMatches(match map[string]string, payload any) bool {
+	for path, expected := range match {
+		value, ok := lookupIntegrationPath(payload, path)
+		if !ok || value == nil {
+			return false
+		}
+		expected = strings.TrimSpace(expected)
+		if expected == "*" {
+			continue
+		}
+		if integrationValueString(value) != expected {
+			return false
+		}
+	}
+	return true
+}
+
+func renderIntegrationTemplate(template string, payload any) string {
+	rendered := integrationTemplatePattern.ReplaceAllStringFunc(template, func(token string) string {
+		path := integrationTemplatePattern.FindStringSubmatch(token)[1]
+		value, ok := lookupIntegrationPath(payload, path)
+		if !ok {
+			return ""
+		}
+		return integrationValueString(value)
+	})
+	return strings.TrimSpace(rendered)
+}
+
+// lookupIntegrationPath walks a dotted path through decoded JSON objects;
+// numeric segments index into arrays.
+func lookupIntegrationPath(payload any, path string) (any, bool) {
+	current := payload
+	for _, segment := range strings.Split(strings.TrimSpace(path), ".") {
+		switch node := current.(type) {
+		case map[string]any:
+			next, ok := node[segment]
+			if !ok {
+				return nil, false
+			}
+			current = next
+		case []any:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, false
+			}
+			current = node[index]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+func integrationValueString(value any) string {
+	switch typed := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(typed)
+	case json.Number:
+		return typed.String()
+	case bool:
+		return strconv.FormatBool(typed)
+	default:
+		encoded, err := json.Marshal(typed)
+		if err != nil {
+			return ""
+		}
+		return string(encoded)
+	}
+}
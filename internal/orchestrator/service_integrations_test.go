@@ -0,0 +1,239 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"metawsm/internal/model"
+	"metawsm/internal/policy"
+	"metawsm/internal/webhook"
+)
+
+func TestIngestIntegrationMapsPayloadsToForumCommands(t *testing.T) {
+	workDir := t.TempDir()
+	t.Chdir(workDir)
+	t.Setenv("METAWSM_TEST_CI_SECRET", "ci-secret")
+	t.Setenv("METAWSM_TEST_CHAT_SECRET", "chat-secret")
+	cfg := policy.Default()
+	cfg.Integrations.Sources = []policy.IntegrationSource{
+		{
+			Name:      "ci",
+			SecretEnv: "METAWSM_TEST_CI_SECRET",
+			Rules: []policy.IntegrationRule{{
+				Name:     "ci-failure",
+				Match:    map[string]string{"build.status": "failed", "ticket": "*"},
+				Action:   "open_thread",
+				Ticket:   "{{ticket}}",
+				Title:    "CI failed: {{build.pipeline}}",
+				Body:     "Job {{build.jobs.0}} failed ({{build.number}})",
+				Priority: "urgent",
+			}},
+		},
+		{
+			Name:      "chat",
+			SecretEnv: "METAWSM_TEST_CHAT_SECRET",
+			Rules: []policy.IntegrationRule{{
+				Name:     "reply",
+				Match:    map[string]string{"type": "reply"},
+				Action:   "answer",
+				ThreadID: "{{thread_id}}",
+				Body:     "{{text}}",
+			}},
+		},
+	}
+	payload, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatalf("marshal policy: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(workDir, ".metawsm"), 0o755); err != nil {
+		t.Fatalf("mkdir policy dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(workDir, policy.DefaultPolicyPath), payload, 0o644); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+	svc := newTestService(t)
+
+	ciBody := []byte(`{"ticket":"METAWSM-016","build":{"status":"failed","pipeline":"main","number":42,"jobs":["unit"]}}`)
+	if _, err := svc.IngestIntegration(t.Context(), IntegrationIngestOptions{Source: "ci", Body: ciBody, Token: "wrong"}); !errors.Is(err, ErrIntegrationUnauthorized) {
+		t.Fatalf("expected unauthorized error, got %v", err)
+	}
+	if _, err := svc.IngestIntegration(t.Context(), IntegrationIngestOptions{Source: "missing", Body: ciBody, Token: "ci-secret"}); !errors.Is(err, ErrIntegrationSourceNotFound) {
+		t.Fatalf("expected source not found error, got %v", err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	signed := IntegrationIngestOptions{
+		Source:     "ci",
+		Body:       ciBody,
+		Timestamp:  timestamp,
+		Signature:  webhook.Sign("ci-secret", timestamp, ciBody),
+		DeliveryID: "build-42",
+	}
+	result, err := svc.IngestIntegration(t.Context(), signed)
+	if err != nil {
+		t.Fatalf("ingest ci payload: %v", err)
+	}
+	if len(result.Actions) != 1 || result.Actions[0].Rule != "ci-failure" {
+		t.Fatalf("expected ci-failure rule to fire once, got %+v", result.Actions)
+	}
+	thread, err := svc.store.GetForumThread(result.Actions[0].ThreadID)
+	if err != nil || thread == nil {
+		t.Fatalf("load opened thread: %v", err)
+	}
+	if thread.Ticket != "METAWSM-016" || thread.Title != "CI failed: main" || thread.Priority != model.ForumPriorityUrgent {
+		t.Fatalf("unexpected opened thread: %+v", thread)
+	}
+	if thread.OpenedByType != model.ForumActorSystem || thread.OpenedByName != "integration:ci" {
+		t.Fatalf("expected thread opened by integration actor, got %+v", thread)
+	}
+
+	replay, err := svc.IngestIntegration(t.Context(), signed)
+	if err != nil {
+		t.Fatalf("replay ci payload: %v", err)
+	}
+	if !replay.Duplicate || len(replay.Actions) != 0 {
+		t.Fatalf("expected repeated delivery to be acknowledged as duplicate, got %+v", replay)
+	}
+	threads, err := svc.ForumListThreads(model.ForumThreadFilter{Ticket: "METAWSM-016"})
+	if err != nil {
+		t.Fatalf("list threads: %v", err)
+	}
+	if len(threads) != 1 {
+		t.Fatalf("expected one thread after duplicate delivery, got %d", len(threads))
+	}
+
+	stale := signed
+	stale.DeliveryID = "build-43"
+	stale.Timestamp = strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+	stale.Signature = webhook.Sign("ci-secret", stale.Timestamp, ciBody)
+	if _, err := svc.IngestIntegration(t.Context(), stale); !errors.Is(err, ErrIntegrationUnauthorized) {
+		t.Fatalf("expected stale signature to be rejected, got %v", err)
+	}
+
+	unkeyedBody := []byte(`{"ticket":"METAWSM-017","build":{"status":"failed","pipeline":"nightly","number":7,"jobs":["lint"]}}`)
+	unkeyed := IntegrationIngestOptions{
+		Source:    "ci",
+		Body:      unkeyedBody,
+		Timestamp: timestamp,
+		Signature: webhook.Sign("ci-secret", timestamp, unkeyedBody),
+	}
+	first, err := svc.IngestIntegration(t.Context(), unkeyed)
+	if err != nil || len(first.Actions) != 1 {
+		t.Fatalf("ingest signed payload without delivery id: %+v, %v", first, err)
+	}
+	replayed, err := svc.IngestIntegration(t.Context(), unkeyed)
+	if err != nil {
+		t.Fatalf("replay signed payload without delivery id: %v", err)
+	}
+	if !replayed.Duplicate || replayed.EventID != first.EventID {
+		t.Fatalf("expected replayed signed request to be a duplicate of %s, got %+v", first.EventID, replayed)
+	}
+	if threads, err := svc.ForumListThreads(model.ForumThreadFilter{Ticket: "METAWSM-017"}); err != nil || len(threads) != 1 {
+		t.Fatalf("expected one thread after replayed signed request, got %d (%v)", len(threads), err)
+	}
+
+	ignored, err := svc.IngestIntegration(t.Context(), IntegrationIngestOptions{
+		Source: "ci",
+		Body:   []byte(`{"ticket":"METAWSM-016","build":{"status":"passed"}}`),
+		Token:  "ci-secret",
+	})
+	if err != nil {
+		t.Fatalf("ingest passing build: %v", err)
+	}
+	if len(ignored.Actions) != 0 {
+		t.Fatalf("expected no rule to match a passing build, got %+v", ignored.Actions)
+	}
+
+	replyBody, _ := json.Marshal(map[string]string{"type": "reply", "thread_id": thread.ThreadID, "text": "Rerun with the cache cleared."})
+	answered, err := svc.IngestIntegration(t.Context(), IntegrationIngestOptions{Source: "chat", Body: replyBody, Token: "chat-secret"})
+	if err != nil {
+		t.Fatalf("ingest chat reply: %v", err)
+	}
+	if len(answered.Actions) != 1 || answered.Actions[0].State != model.ForumThreadStateAnswered {
+		t.Fatalf("expected chat reply to answer the thread, got %+v", answered.Actions)
+	}
+}
+
+func TestIngestIntegrationRetryResumesAfterAppliedRules(t *testing.T) {
+	workDir := t.TempDir()
+	t.Chdir(workDir)
+	t.Setenv("METAWSM_TEST_TRACKER_SECRET", "tracker-secret")
+	cfg := policy.Default()
+	cfg.Integrations.Sources = []policy.IntegrationSource{{
+		Name:      "tracker",
+		SecretEnv: "METAWSM_TEST_TRACKER_SECRET",
+		Rules: []policy.IntegrationRule{
+			{
+				Name:     "open",
+				Match:    map[string]string{"id": "*"},
+				Action:   "open_thread",
+				ThreadID: "issue-{{id}}",
+				Ticket:   "{{ticket}}",
+				Title:    "Issue {{id}}",
+				Body:     "Opened from the tracker",
+			},
+			{
+				Name:     "link",
+				Match:    map[string]string{"parent": "*"},
+				Action:   "add_post",
+				ThreadID: "{{parent}}",
+				Body:     "Linked issue {{id}}",
+			},
+		},
+	}}
+	payload, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatalf("marshal policy: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(workDir, ".metawsm"), 0o755); err != nil {
+		t.Fatalf("mkdir policy dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(workDir, policy.DefaultPolicyPath), payload, 0o644); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+	svc := newTestService(t)
+
+	delivery := IntegrationIngestOptions{
+		Source:     "tracker",
+		Body:       []byte(`{"id":"7","ticket":"METAWSM-016","parent":"thread-parent"}`),
+		Token:      "tracker-secret",
+		DeliveryID: "issue-7-created",
+	}
+	if _, err := svc.IngestIntegration(t.Context(), delivery); err == nil {
+		t.Fatalf("expected the link rule to fail while the parent thread is missing")
+	}
+	if _, err := svc.ForumOpenThread(t.Context(), ForumOpenThreadOptions{
+		ThreadID:  "thread-parent",
+		Ticket:    "METAWSM-016",
+		Title:     "Parent",
+		Body:      "Parent thread",
+		ActorType: model.ForumActorHuman,
+		ActorName: "kball",
+	}); err != nil {
+		t.Fatalf("open parent thread: %v", err)
+	}
+
+	result, err := svc.IngestIntegration(t.Context(), delivery)
+	if err != nil {
+		t.Fatalf("retry delivery: %v", err)
+	}
+	if len(result.Actions) != 2 || result.Actions[0].ThreadID != "issue-7" || result.Actions[1].ThreadID != "thread-parent" {
+		t.Fatalf("expected both rules reported on retry, got %+v", result.Actions)
+	}
+	issue, err := svc.store.GetForumThread("issue-7")
+	if err != nil || issue == nil {
+		t.Fatalf("load issue thread: %+v (%v)", issue, err)
+	}
+	if issue.PostsCount != 1 {
+		t.Fatalf("expected the open rule to run once, got %d posts", issue.PostsCount)
+	}
+	parent, err := svc.store.GetForumThread("thread-parent")
+	if err != nil || parent == nil || parent.PostsCount != 2 {
+		t.Fatalf("expected one linked post on the parent thread, got %+v (%v)", parent, err)
+	}
+}
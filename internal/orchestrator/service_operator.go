@@ -0,0 +1,789 @@
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"metawsm/internal/model"
+	"metawsm/internal/policy"
+)
+
+// operatorEventEntity is the events.entity_type of operator decisions.
+const operatorEventEntity = "operator"
+
+// OperatorPassOptions controls one operator supervision pass. An empty RunID
+// supervises every active run. When Holder is set, runs are only acted on
+// while Holder owns their lease, which keeps two operators off the same run.
+type OperatorPassOptions struct {
+	RunID      string
+	PolicyPath string
+	// LLMMode overrides operator.llm.mode from policy when set.
+	LLMMode  string
+	DryRun   bool
+	Holder   string
+	LeaseTTL time.Duration
+	Now      time.Time
+}
+
+type OperatorPassResult struct {
+	LLMMode  string              `json:"llm_mode"`
+	DryRun   bool                `json:"dry_run"`
+	Runs     []OperatorRunResult `json:"runs"`
+	Warnings []string            `json:"warnings,omitempty"`
+}
+
+// OperatorRunResult is what one pass saw and did for a run. Decision is nil
+// when the rules chose noop or repeated the run's previous alert.
+type OperatorRunResult struct {
+	Snapshot RunSnapshot `json:"snapshot"`
+	Active   bool        `json:"active"`
+	// LeaseHolder names the other operator supervising the run, if any.
+	LeaseHolder string            `json:"lease_holder,omitempty"`
+	Decision    *OperatorDecision `json:"decision,omitempty"`
+}
+
+type OperatorDecision struct {
+	RunID    string               `json:"run_id"`
+	Event    string               `json:"event"`
+	Intent   OperatorIntent       `json:"intent"`
+	Reason   string               `json:"reason"`
+	Source   string               `json:"source"`
+	LLMReply *OperatorLLMResponse `json:"llm_reply,omitempty"`
+	// Execute reports whether policy allowed the action; Executed whether it
+	// actually ran.
+	Execute     bool   `json:"execute"`
+	Executed    bool   `json:"executed"`
+	ActionError string `json:"action_error,omitempty"`
+}
+
+// Actionable reports whether the intent maps to an action the operator can
+// execute rather than an escalation.
+func (d OperatorDecision) Actionable() bool {
+	switch d.Intent {
+	case OperatorIntentAutoStopStale, OperatorIntentAutoRestart, OperatorIntentCommitReady, OperatorIntentPRReady, OperatorIntentReviewFeedbackReady:
+		return true
+	default:
+		return false
+	}
+}
+
+// OperatorRunDecisions is the operator's view of one run: who supervises it,
+// its restart memory and its latest decisions, newest first.
+type OperatorRunDecisions struct {
+	RunID     string                  `json:"run_id"`
+	Status    model.RunStatus         `json:"status"`
+	Lease     *model.OperatorLease    `json:"lease,omitempty"`
+	State     *model.OperatorRunState `json:"state,omitempty"`
+	Decisions []model.RunEvent        `json:"decisions"`
+}
+
+// OperatorDecisionInputs is what a decision was made from, stored with each
+// audited decision. SessionEvidence holds the runtime probes the stale-run
+// rule made, so replays need no live sessions.
+type OperatorDecisionInputs struct {
+	Snapshot           RunSnapshot                     `json:"snapshot"`
+	Run                model.RunRecord                 `json:"run"`
+	State              *model.OperatorRunState         `json:"state,omitempty"`
+	UnhealthyIntervals int                             `json:"unhealthy_intervals"`
+	Now                time.Time                       `json:"now"`
+	SessionEvidence    map[string]AgentSessionEvidence `json:"session_evidence,omitempty"`
+}
+
+// ruleInput is what the rules see of inputs, with probe answering runtime
+// probes.
+func (inputs OperatorDecisionInputs) ruleInput(probe operatorSessionProbe) operatorRuleInput {
+	return operatorRuleInput{
+		Snapshot:           inputs.Snapshot,
+		Run:                inputs.Run,
+		State:              inputs.State,
+		UnhealthyIntervals: inputs.UnhealthyIntervals,
+		Now:                inputs.Now,
+		Probe:              probe,
+	}
+}
+
+type operatorSessionProbe func(ctx context.Context, session string) (AgentSessionEvidence, error)
+
+// operatorRuleInput is everything the deterministic rules decide on.
+type operatorRuleInput struct {
+	Snapshot           RunSnapshot
+	Run                model.RunRecord
+	State              *model.OperatorRunState
+	UnhealthyIntervals int
+	Now                time.Time
+	Probe              operatorSessionProbe
+}
+
+// ResolveOperatorLLMMode picks override over the policy mode and defaults to
+// assist.
+func ResolveOperatorLLMMode(override string, policyValue string) (string, error) {
+	mode := strings.TrimSpace(strings.ToLower(override))
+	if mode == "" {
+		mode = strings.TrimSpace(strings.ToLower(policyValue))
+	}
+	if mode == "" {
+		mode = "assist"
+	}
+	switch mode {
+	case "off", "assist", "auto":
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid llm mode %q (expected off|assist|auto)", override)
+	}
+}
+
+// OperatorPass runs one supervision pass: it evaluates operator rules (and the
+// LLM, unless off) for each run, executes allowed actions, and records every
+// new decision as an operator event. Restart budgets, unhealthy streaks and
+// the last alert live in operator_run_states, so passes can come from any
+// process.
+func (s *Service) OperatorPass(ctx context.Context, options OperatorPassOptions) (OperatorPassResult, error) {
+	cfg, _, err := policy.Load(options.PolicyPath)
+	if err != nil {
+		return OperatorPassResult{}, err
+	}
+	llmMode, err := ResolveOperatorLLMMode(options.LLMMode, cfg.Operator.LLM.Mode)
+	if err != nil {
+		return OperatorPassResult{}, err
+	}
+	options.Holder = strings.TrimSpace(options.Holder)
+	if options.Holder != "" && options.LeaseTTL <= 0 {
+		return OperatorPassResult{}, fmt.Errorf("lease ttl must be > 0 when a lease holder is set")
+	}
+	var llm operatorLLMAdapter
+	var llmWarnings []string
+	if llmMode != "off" {
+		// A misconfigured provider leaves the pass on rules alone rather than
+		// stopping supervision.
+		llm, err = s.operatorLLMAdapterFor(cfg)
+		if err != nil {
+			llmWarnings = append(llmWarnings, fmt.Sprintf("operator llm unavailable: %v", err))
+		}
+	}
+
+	activeRuns, err := s.ActiveRuns()
+	if err != nil {
+		return OperatorPassResult{}, err
+	}
+	activeByRunID := map[string]model.RunRecord{}
+	runIDs := []string{}
+	for _, run := range activeRuns {
+		activeByRunID[run.RunID] = run
+		runIDs = append(runIDs, run.RunID)
+	}
+	if runID := strings.TrimSpace(options.RunID); runID != "" {
+		runIDs = []string{runID}
+	}
+	sort.Strings(runIDs)
+
+	result := OperatorPassResult{LLMMode: llmMode, DryRun: options.DryRun, Runs: []OperatorRunResult{}, Warnings: llmWarnings}
+	for i, runID := range runIDs {
+		if i > 0 && options.Holder != "" {
+			// Runs handled earlier in this pass stay leased while the rest
+			// (and any slow LLM calls) are worked through.
+			if err := s.store.RenewOperatorLeases(options.Holder, operatorPassTime(options), options.LeaseTTL); err != nil {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("renew operator leases failed: %v", err))
+			}
+		}
+		report, err := s.StatusReport(ctx, runID)
+		if err != nil {
+			if ctx.Err() != nil {
+				return result, ctx.Err()
+			}
+			return result, err
+		}
+		runResult := OperatorRunResult{Snapshot: report.Snapshot()}
+		run, active := activeByRunID[runID]
+		runResult.Active = active
+		if active {
+			warnings := s.operateRun(ctx, cfg, llmMode, llm, options, run, &runResult)
+			result.Warnings = append(result.Warnings, warnings...)
+		}
+		result.Runs = append(result.Runs, runResult)
+	}
+	return result, nil
+}
+
+// operateRun decides on one active run and fills in runResult. Failures that
+// should not stop the pass come back as warnings.
+func (s *Service) operateRun(
+	ctx context.Context,
+	cfg policy.Config,
+	llmMode string,
+	llm operatorLLMAdapter,
+	options OperatorPassOptions,
+	run model.RunRecord,
+	runResult *OperatorRunResult,
+) (warnings []string) {
+	snapshot := runResult.Snapshot
+	runID := run.RunID
+	now := operatorPassTime(options)
+	if options.Holder != "" {
+		lease, owned, err := s.store.AcquireOperatorLease(runID, options.Holder, now, options.LeaseTTL)
+		if err != nil {
+			return []string{fmt.Sprintf("operator lease for run %s failed: %v", runID, err)}
+		}
+		if !owned {
+			runResult.LeaseHolder = lease.Holder
+			return nil
+		}
+	}
+
+	current, err := s.store.GetOperatorRunState(runID)
+	if err != nil {
+		return []string{fmt.Sprintf("read operator state for run %s failed: %v", runID, err)}
+	}
+	state := model.OperatorRunState{RunID: runID}
+	if current != nil {
+		state = *current
+	}
+	if len(snapshot.UnhealthyAgents) > 0 && snapshot.Status == model.RunStatusRunning {
+		state.UnhealthyIntervals++
+	} else {
+		state.UnhealthyIntervals = 0
+	}
+	defer func() {
+		state.UpdatedAt = now
+		if err := s.store.UpsertOperatorRunState(state); err != nil {
+			warnings = append(warnings, fmt.Sprintf("persist operator state for run %s failed: %v", runID, err))
+		}
+	}()
+
+	inputs := OperatorDecisionInputs{
+		Snapshot:           snapshot,
+		Run:                run,
+		State:              current,
+		UnhealthyIntervals: state.UnhealthyIntervals,
+		Now:                now,
+		SessionEvidence:    map[string]AgentSessionEvidence{},
+	}
+	rule, err := buildOperatorRuleDecision(ctx, cfg, inputs.ruleInput(func(ctx context.Context, session string) (AgentSessionEvidence, error) {
+		evidence, err := s.ProbeAgentSession(ctx, runID, session)
+		if err == nil {
+			inputs.SessionEvidence[session] = evidence
+		}
+		return evidence, err
+	}))
+	if err != nil {
+		warnings = append(warnings, fmt.Sprintf("operator rule evaluation failed for run %s: %v", runID, err))
+		return warnings
+	}
+
+	audit := model.OperatorDecisionRecord{
+		RunID:       runID,
+		Holder:      operatorHolderOrDefault(options.Holder),
+		LLMMode:     llmMode,
+		DryRun:      options.DryRun,
+		RuleIntent:  string(rule.Intent),
+		RuleReason:  rule.Reason,
+		RuleExecute: rule.Execute,
+		CreatedAt:   now,
+	}
+	var llmReply *OperatorLLMResponse
+	if llm != nil {
+		request := operatorLLMRequest{
+			RunID:           runID,
+			RunStatus:       string(snapshot.Status),
+			Tickets:         strings.Join(snapshot.Tickets, ", "),
+			HasGuidance:     operatorSnapshotNeedsGuidance(snapshot),
+			HasUnhealthy:    len(snapshot.UnhealthyAgents) > 0,
+			RuleIntent:      rule.Intent,
+			RuleReason:      rule.Reason,
+			UnhealthyAgents: snapshot.UnhealthyAgents,
+		}
+		audit.LLMRequest, _ = json.Marshal(request)
+		reply, err := llm.Propose(ctx, request)
+		if err != nil {
+			audit.LLMError = err.Error()
+			warnings = append(warnings, fmt.Sprintf("llm proposal failed for run %s: %v", runID, err))
+		} else {
+			llmReply = &reply
+			audit.LLMReply, _ = json.Marshal(reply)
+		}
+	}
+
+	merged := mergeOperatorDecisions(llmMode, rule, llmReply)
+	audit.Intent = string(merged.Intent)
+	audit.Reason = merged.Reason
+	audit.Source = merged.Source
+	audit.Execute = merged.Execute
+	recordAudit := func() {
+		encoded, err := json.Marshal(inputs)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("encode operator decision inputs for run %s failed: %v", runID, err))
+			return
+		}
+		audit.Inputs = encoded
+		if err := s.store.AddOperatorDecision(audit); err != nil {
+			warnings = append(warnings, fmt.Sprintf("audit operator decision for run %s failed: %v", runID, err))
+		}
+	}
+	// A pass that repeats the previous outcome takes no action and is counted
+	// against the decision it repeats rather than audited as a new row.
+	recordRepeat := func() {
+		repeated, err := s.store.RepeatLatestOperatorDecision(runID, now)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("audit repeated operator decision for run %s failed: %v", runID, err))
+			return
+		}
+		if !repeated {
+			recordAudit()
+		}
+	}
+	if merged.Intent == OperatorIntentNoop {
+		audit.Event = operatorEventName(merged.Intent)
+		if state.LastEvent == "" {
+			recordRepeat()
+		} else {
+			recordAudit()
+		}
+		state.LastEvent = ""
+		return warnings
+	}
+	event := operatorEventName(merged.Intent)
+	if state.LastEvent == event {
+		audit.Event = event
+		recordRepeat()
+		return warnings
+	}
+	state.LastEvent = event
+	if rule.Rule != "" && merged.Intent == rule.Intent {
+		recordOperatorRuleFiring(cfg, &state, rule.Rule, now)
+	}
+
+	decision := &OperatorDecision{
+		RunID:    runID,
+		Event:    event,
+		Intent:   merged.Intent,
+		Reason:   merged.Reason,
+		Source:   merged.Source,
+		LLMReply: merged.LLMReply,
+		Execute:  merged.Execute,
+	}
+	runResult.Decision = decision
+	if merged.Execute && !options.DryRun {
+		if err := s.confirmOperatorLease(runID, options); err != nil {
+			decision.ActionError = err.Error()
+			warnings = append(warnings, fmt.Sprintf("operator action skipped for run %s intent=%s: %v", runID, merged.Intent, err))
+		} else if err := s.executeOperatorAction(ctx, runID, merged.Intent, cfg.GitPR.ReviewFeedback.AutoDispatchCapPerInterval); err != nil {
+			decision.ActionError = err.Error()
+			warnings = append(warnings, fmt.Sprintf("operator action failed for run %s intent=%s: %v", runID, merged.Intent, err))
+		} else {
+			decision.Executed = true
+			if merged.Intent == OperatorIntentAutoRestart {
+				restartedAt := now
+				cooldownUntil := now.Add(time.Duration(cfg.Operator.RestartCooldownSeconds) * time.Second)
+				state.RestartAttempts++
+				state.LastRestartAt = &restartedAt
+				state.CooldownUntil = &cooldownUntil
+			}
+		}
+	}
+	if merged.Intent == OperatorIntentEscalateGuidance || merged.Intent == OperatorIntentEscalateBlocked {
+		if err := s.appendOperatorEscalationSummary(ctx, runID, merged.Intent, merged.Reason); err != nil {
+			warnings = append(warnings, fmt.Sprintf("escalation summary write failed for run %s: %v", runID, err))
+		}
+	}
+
+	if err := s.store.AddEvent(runID, operatorEventEntity, audit.Holder, event, "", string(merged.Intent), operatorDecisionMessage(*decision, llmMode, options.DryRun)); err != nil {
+		warnings = append(warnings, fmt.Sprintf("record operator decision for run %s failed: %v", runID, err))
+	}
+	audit.Event = event
+	audit.Executed = decision.Executed
+	audit.ActionError = decision.ActionError
+	recordAudit()
+	return warnings
+}
+
+// operatorPassTime is the pass's fixed clock when the caller set one, and the
+// wall clock otherwise, so lease renewals track how long the pass has run.
+func operatorPassTime(options OperatorPassOptions) time.Time {
+	if !options.Now.IsZero() {
+		return options.Now
+	}
+	return time.Now()
+}
+
+// confirmOperatorLease renews the run's lease right before an action runs and
+// fails when another holder took it over while the decision was being made.
+func (s *Service) confirmOperatorLease(runID string, options OperatorPassOptions) error {
+	if options.Holder == "" {
+		return nil
+	}
+	lease, owned, err := s.store.AcquireOperatorLease(runID, options.Holder, operatorPassTime(options), options.LeaseTTL)
+	if err != nil {
+		return fmt.Errorf("renew operator lease: %w", err)
+	}
+	if !owned {
+		return fmt.Errorf("operator lease is now held by %s", lease.Holder)
+	}
+	return nil
+}
+
+func operatorHolderOrDefault(holder string) string {
+	if holder == "" {
+		return "operator"
+	}
+	return holder
+}
+
+// OperatorDecisions lists the latest operator decisions for runID, or for
+// every active run when runID is empty.
+func (s *Service) OperatorDecisions(runID string, limit int) ([]OperatorRunDecisions, error) {
+	runs := []model.RunRecord{}
+	if runID = strings.TrimSpace(runID); runID != "" {
+		run, _, _, err := s.store.GetRun(runID)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	} else {
+		active, err := s.ActiveRuns()
+		if err != nil {
+			return nil, err
+		}
+		runs = active
+		sort.Slice(runs, func(i, j int) bool { return runs[i].RunID < runs[j].RunID })
+	}
+
+	out := make([]OperatorRunDecisions, 0, len(runs))
+	for _, run := range runs {
+		lease, err := s.store.GetOperatorLease(run.RunID)
+		if err != nil {
+			return nil, err
+		}
+		state, err := s.store.GetOperatorRunState(run.RunID)
+		if err != nil {
+			return nil, err
+		}
+		decisions, err := s.store.ListRunEvents(run.RunID, operatorEventEntity, limit)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, OperatorRunDecisions{
+			RunID:     run.RunID,
+			Status:    run.Status,
+			Lease:     lease,
+			State:     state,
+			Decisions: decisions,
+		})
+	}
+	return out, nil
+}
+
+// ReleaseOperatorLeases frees every run lease held by holder.
+func (s *Service) ReleaseOperatorLeases(holder string) error {
+	return s.store.ReleaseOperatorLeases(holder)
+}
+
+func operatorSnapshotNeedsGuidance(snapshot RunSnapshot) bool {
+	return len(snapshot.PendingGuidance) > 0 || snapshot.Status == model.RunStatusAwaitingGuidance
+}
+
+// buildOperatorRuleDecision applies the policy's operator rules, falling back
+// to the built-in rules when none matches.
+func buildOperatorRuleDecision(ctx context.Context, cfg policy.Config, input operatorRuleInput) (operatorRuleDecision, error) {
+	if traces, matched := traceOperatorPolicyRules(cfg.Operator.Rules, input); matched >= 0 {
+		return policyRuleDecision(ctx, cfg, cfg.Operator.Rules[matched], traces[matched], input)
+	}
+
+	snapshot := input.Snapshot
+	if operatorSnapshotNeedsGuidance(snapshot) {
+		return operatorRuleDecision{
+			Intent:  OperatorIntentEscalateGuidance,
+			Reason:  "operator input is required",
+			Execute: false,
+		}, nil
+	}
+
+	staleAge := time.Duration(cfg.Operator.StaleRunAgeSeconds) * time.Second
+	isStale, staleReason := classifyStaleRunCandidate(snapshot, input.Run, input.Now, staleAge)
+	if isStale {
+		recentWindow := time.Duration(cfg.Health.ActivityStalledSeconds) * time.Second
+		verified, verifyReason, err := verifyStaleRuntimeEvidence(ctx, snapshot, input.Now, recentWindow, input.Probe)
+		if err != nil {
+			return operatorRuleDecision{}, err
+		}
+		if verified {
+			return operatorRuleDecision{
+				Intent:  OperatorIntentAutoStopStale,
+				Reason:  staleReason + "; " + verifyReason,
+				Execute: true,
+			}, nil
+		}
+		return operatorRuleDecision{
+			Intent:  OperatorIntentNoop,
+			Reason:  staleReason + "; " + verifyReason,
+			Execute: false,
+		}, nil
+	}
+
+	if len(snapshot.UnhealthyAgents) > 0 && snapshot.Status == model.RunStatusRunning {
+		confirmations := cfg.Operator.UnhealthyConfirmations
+		if input.UnhealthyIntervals < confirmations {
+			return operatorRuleDecision{
+				Intent:  OperatorIntentNoop,
+				Reason:  fmt.Sprintf("awaiting corroboration (%d/%d unhealthy intervals)", input.UnhealthyIntervals, confirmations),
+				Execute: false,
+			}, nil
+		}
+		if blocked, ok := operatorRestartBlocked(cfg, input); ok {
+			return blocked, nil
+		}
+		return operatorRuleDecision{
+			Intent:  OperatorIntentAutoRestart,
+			Reason:  "unhealthy state corroborated and restart budget available",
+			Execute: true,
+		}, nil
+	}
+
+	mode := strings.TrimSpace(strings.ToLower(cfg.GitPR.Mode))
+	if mode == "" {
+		mode = "assist"
+	}
+	if snapshot.Status == model.RunStatusComplete && mode != "off" {
+		if snapshot.HasDirtyDiffs {
+			return operatorRuleDecision{
+				Intent:  OperatorIntentCommitReady,
+				Reason:  "run completed with dirty repository diffs; commit workflow is ready",
+				Execute: mode == "auto",
+			}, nil
+		}
+		if snapshot.DraftPullRequests > 0 {
+			return operatorRuleDecision{
+				Intent:  OperatorIntentPRReady,
+				Reason:  fmt.Sprintf("run has %d draft pull request record(s); PR creation is ready", snapshot.DraftPullRequests),
+				Execute: mode == "auto",
+			}, nil
+		}
+	}
+	if snapshot.Status == model.RunStatusComplete && cfg.GitPR.ReviewFeedback.Enabled && snapshot.QueuedReviewFeedback > 0 {
+		reviewMode := strings.TrimSpace(strings.ToLower(cfg.GitPR.ReviewFeedback.Mode))
+		if reviewMode == "" {
+			reviewMode = "assist"
+		}
+		return operatorRuleDecision{
+			Intent:  OperatorIntentReviewFeedbackReady,
+			Reason:  fmt.Sprintf("run has %d queued review feedback item(s); review dispatch is ready", snapshot.QueuedReviewFeedback),
+			Execute: reviewMode == "auto",
+		}, nil
+	}
+
+	return operatorRuleDecision{
+		Intent:  OperatorIntentNoop,
+		Reason:  "no deterministic action required",
+		Execute: false,
+	}, nil
+}
+
+// operatorRestartBlocked reports the decision to make instead of a restart
+// when the run's restart budget is spent or its cooldown is active.
+func operatorRestartBlocked(cfg policy.Config, input operatorRuleInput) (operatorRuleDecision, bool) {
+	state := input.State
+	budget := cfg.Operator.RestartBudget
+	if state != nil && state.RestartAttempts >= budget {
+		return operatorRuleDecision{
+			Intent:  OperatorIntentEscalateBlocked,
+			Reason:  fmt.Sprintf("restart budget exhausted (%d/%d)", state.RestartAttempts, budget),
+			Execute: false,
+		}, true
+	}
+	if state != nil && state.CooldownUntil != nil && input.Now.Before(*state.CooldownUntil) {
+		return operatorRuleDecision{
+			Intent:  OperatorIntentNoop,
+			Reason:  fmt.Sprintf("restart cooldown active until %s", state.CooldownUntil.Format(time.RFC3339)),
+			Execute: false,
+		}, true
+	}
+	return operatorRuleDecision{}, false
+}
+
+func classifyStaleRunCandidate(snapshot RunSnapshot, run model.RunRecord, now time.Time, staleAge time.Duration) (bool, string) {
+	if staleAge <= 0 {
+		return false, ""
+	}
+	if operatorSnapshotNeedsGuidance(snapshot) {
+		return false, ""
+	}
+	if len(snapshot.UnhealthyAgents) == 0 {
+		return false, ""
+	}
+	age := now.Sub(run.UpdatedAt)
+	if age < staleAge {
+		return false, ""
+	}
+	return true, fmt.Sprintf("run appears stale (updated %s ago, threshold=%s)", age.Truncate(time.Second), staleAge)
+}
+
+func verifyStaleRuntimeEvidence(ctx context.Context, snapshot RunSnapshot, now time.Time, recentWindow time.Duration, probe operatorSessionProbe) (bool, string, error) {
+	if probe == nil {
+		return false, "runtime probe is unavailable", nil
+	}
+	if recentWindow <= 0 {
+		recentWindow = 5 * time.Minute
+	}
+	if len(snapshot.UnhealthyAgents) == 0 {
+		return false, "no unhealthy agents available to verify", nil
+	}
+
+	evidenceCount := 0
+	for _, agent := range snapshot.UnhealthyAgents {
+		session := strings.TrimSpace(agent.SessionName)
+		if session == "" {
+			continue
+		}
+		evidenceCount++
+		evidence, err := probe(ctx, session)
+		if err != nil {
+			return false, "", err
+		}
+		if evidence.HasSession {
+			if evidence.LastActivity != nil && now.Sub(*evidence.LastActivity) <= recentWindow {
+				return false, fmt.Sprintf("session %s has recent activity within %s", session, recentWindow), nil
+			}
+			if evidence.ExitCode == nil || *evidence.ExitCode == 0 {
+				return false, fmt.Sprintf("session %s still appears running", session), nil
+			}
+		}
+	}
+	if evidenceCount == 0 {
+		return false, "no agent sessions available for runtime verification", nil
+	}
+	return true, "no active agent sessions or recent activity detected", nil
+}
+
+func operatorEventName(intent OperatorIntent) string {
+	switch intent {
+	case OperatorIntentEscalateGuidance:
+		return "guidance_needed"
+	case OperatorIntentAutoStopStale:
+		return "stale_candidate_verified"
+	case OperatorIntentAutoRestart:
+		return "auto_restart_candidate"
+	case OperatorIntentEscalateBlocked:
+		return "escalation_blocked"
+	case OperatorIntentCommitReady:
+		return "commit_ready"
+	case OperatorIntentPRReady:
+		return "pr_ready"
+	case OperatorIntentReviewFeedbackReady:
+		return "review_feedback_ready"
+	default:
+		return "operator_noop"
+	}
+}
+
+// operatorDecisionMessage is the events.message recorded for a decision.
+func operatorDecisionMessage(decision OperatorDecision, llmMode string, dryRun bool) string {
+	outcome := "alert only"
+	switch {
+	case decision.ActionError != "":
+		outcome = "action failed: " + decision.ActionError
+	case decision.Executed:
+		outcome = "action executed"
+	case decision.Actionable():
+		outcome = fmt.Sprintf("action not executed (dry_run=%t llm_mode=%s)", dryRun, llmMode)
+	}
+	return fmt.Sprintf("%s [source=%s; %s]", decision.Reason, decision.Source, outcome)
+}
+
+func (s *Service) executeOperatorAction(ctx context.Context, runID string, intent OperatorIntent, reviewDispatchCap int) error {
+	switch intent {
+	case OperatorIntentAutoRestart:
+		_, err := s.Restart(ctx, RestartOptions{RunID: runID, DryRun: false})
+		return err
+	case OperatorIntentAutoStopStale:
+		return s.Stop(ctx, runID)
+	case OperatorIntentCommitReady:
+		_, err := s.Commit(ctx, CommitOptions{RunID: runID, Actor: "operator"})
+		return err
+	case OperatorIntentPRReady:
+		_, err := s.OpenPullRequests(ctx, PullRequestOptions{RunID: runID, Actor: "operator"})
+		return err
+	case OperatorIntentReviewFeedbackReady:
+		_, err := s.SyncReviewFeedback(ctx, ReviewFeedbackSyncOptions{
+			RunID:    runID,
+			MaxItems: reviewDispatchCap,
+			DryRun:   false,
+		})
+		if err != nil {
+			return err
+		}
+		_, err = s.DispatchQueuedReviewFeedback(ctx, ReviewFeedbackDispatchOptions{
+			RunID:    runID,
+			MaxItems: reviewDispatchCap,
+			DryRun:   false,
+		})
+		return err
+	default:
+		return nil
+	}
+}
+
+// appendOperatorEscalationSummary notes an escalation in each ticket's
+// changelog inside every workspace of the run.
+func (s *Service) appendOperatorEscalationSummary(ctx context.Context, runID string, intent OperatorIntent, summary string) error {
+	runCtx, err := s.OperatorRunContext(runID)
+	if err != nil {
+		return err
+	}
+	if len(runCtx.Tickets) == 0 {
+		return nil
+	}
+	workspaceSet := map[string]struct{}{}
+	for _, agent := range runCtx.Agents {
+		workspaceName := strings.TrimSpace(agent.WorkspaceName)
+		if workspaceName == "" {
+			continue
+		}
+		workspaceSet[workspaceName] = struct{}{}
+	}
+
+	for workspaceName := range workspaceSet {
+		workspacePath, err := resolveWorkspacePath(workspaceName)
+		if err != nil {
+			return err
+		}
+		docRepoPath, err := resolveDocRepoPath(workspacePath, runCtx.DocHomeRepo, runCtx.Repos)
+		if err != nil {
+			return err
+		}
+		for _, ticket := range runCtx.Tickets {
+			_, relativePath, err := resolveTicketDocPath(ctx, ticket)
+			if err != nil {
+				return err
+			}
+			changelogPath := filepath.Join(docRepoPath, "ttmp", relativePath, "changelog.md")
+			entry := "\n\n## " + time.Now().Format(time.RFC3339) + "\n\n" +
+				"- Operator escalation for run `" + runID + "`\n" +
+				"- Intent: `" + string(intent) + "`\n" +
+				"- Summary: " + summary + "\n" +
+				"- Requested decision: review `metawsm status --run-id " + runID + "` and provide guidance.\n"
+			if err := appendOperatorChangelog(changelogPath, entry); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func appendOperatorChangelog(path string, content string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(content); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,149 @@
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"metawsm/internal/model"
+	"metawsm/internal/policy"
+)
+
+// OperatorReplayOptions selects audited decisions to re-evaluate. DecisionID
+// replays a single decision; otherwise the latest Limit decisions for RunID
+// (or every run) are replayed oldest first.
+type OperatorReplayOptions struct {
+	RunID      string
+	DecisionID int64
+	Limit      int
+	PolicyPath string
+}
+
+// OperatorReplayEntry compares one recorded decision with what the current
+// rules decide from the same inputs.
+type OperatorReplayEntry struct {
+	DecisionID      int64          `json:"decision_id"`
+	RunID           string         `json:"run_id"`
+	CreatedAt       time.Time      `json:"created_at"`
+	RecordedIntent  OperatorIntent `json:"recorded_intent"`
+	RecordedExecute bool           `json:"recorded_execute"`
+	Intent          OperatorIntent `json:"intent,omitempty"`
+	Reason          string         `json:"reason,omitempty"`
+	Source          string         `json:"source,omitempty"`
+	Execute         bool           `json:"execute"`
+	Changed         bool           `json:"changed"`
+	Error           string         `json:"error,omitempty"`
+}
+
+type OperatorReplayResult struct {
+	PolicyPath string                `json:"policy_path"`
+	Total      int                   `json:"total"`
+	Changed    int                   `json:"changed"`
+	Failed     int                   `json:"failed"`
+	Entries    []OperatorReplayEntry `json:"entries"`
+}
+
+// OperatorHistory lists audited operator decisions newest first. An empty
+// runID lists every run.
+func (s *Service) OperatorHistory(runID string, limit int) ([]model.OperatorDecisionRecord, error) {
+	return s.store.ListOperatorDecisions(runID, limit)
+}
+
+// PruneOperatorDecisions deletes audited decisions last seen more than
+// operator.decision_retention_days ago and returns how many were removed.
+func (s *Service) PruneOperatorDecisions() (int, error) {
+	cfg := loadPolicyOrDefault()
+	cutoff := time.Now().Add(-time.Duration(cfg.Operator.DecisionRetentionDays) * 24 * time.Hour)
+	return s.store.PruneOperatorDecisions(cutoff)
+}
+
+// OperatorReplay re-evaluates stored decision inputs against the rules in the
+// current policy. The recorded LLM reply and mode are reused rather than
+// asking the LLM again, and recorded session evidence stands in for runtime
+// probes, so replays are offline and deterministic. Nothing is executed.
+func (s *Service) OperatorReplay(ctx context.Context, options OperatorReplayOptions) (OperatorReplayResult, error) {
+	cfg, policyPath, err := policy.Load(options.PolicyPath)
+	if err != nil {
+		return OperatorReplayResult{}, err
+	}
+	records := []model.OperatorDecisionRecord{}
+	if options.DecisionID > 0 {
+		record, err := s.store.GetOperatorDecision(options.DecisionID)
+		if err != nil {
+			return OperatorReplayResult{}, err
+		}
+		if record == nil {
+			return OperatorReplayResult{}, fmt.Errorf("operator decision %d not found", options.DecisionID)
+		}
+		records = append(records, *record)
+	} else {
+		records, err = s.store.ListOperatorDecisions(strings.TrimSpace(options.RunID), options.Limit)
+		if err != nil {
+			return OperatorReplayResult{}, err
+		}
+	}
+
+	result := OperatorReplayResult{PolicyPath: policyPath, Entries: []OperatorReplayEntry{}}
+	for i := len(records) - 1; i >= 0; i-- {
+		entry := replayOperatorDecision(ctx, cfg, records[i])
+		result.Total++
+		if entry.Error != "" {
+			result.Failed++
+		} else if entry.Changed {
+			result.Changed++
+		}
+		result.Entries = append(result.Entries, entry)
+	}
+	return result, nil
+}
+
+func replayOperatorDecision(ctx context.Context, cfg policy.Config, record model.OperatorDecisionRecord) OperatorReplayEntry {
+	entry := OperatorReplayEntry{
+		DecisionID:      record.ID,
+		RunID:           record.RunID,
+		CreatedAt:       record.CreatedAt,
+		RecordedIntent:  OperatorIntent(record.Intent),
+		RecordedExecute: record.Execute,
+	}
+	var inputs OperatorDecisionInputs
+	if err := json.Unmarshal(record.Inputs, &inputs); err != nil {
+		entry.Error = fmt.Sprintf("decode recorded inputs: %v", err)
+		return entry
+	}
+	var llmReply *OperatorLLMResponse
+	if len(record.LLMReply) > 0 {
+		var reply OperatorLLMResponse
+		if err := json.Unmarshal(record.LLMReply, &reply); err != nil {
+			entry.Error = fmt.Sprintf("decode recorded llm reply: %v", err)
+			return entry
+		}
+		llmReply = &reply
+	}
+
+	rule, err := buildOperatorRuleDecision(ctx, cfg, inputs.ruleInput(recordedSessionProbe(inputs.SessionEvidence)))
+	if err != nil {
+		entry.Error = err.Error()
+		return entry
+	}
+	merged := mergeOperatorDecisions(record.LLMMode, rule, llmReply)
+	entry.Intent = merged.Intent
+	entry.Reason = merged.Reason
+	entry.Source = merged.Source
+	entry.Execute = merged.Execute
+	entry.Changed = merged.Intent != entry.RecordedIntent || merged.Execute != entry.RecordedExecute
+	return entry
+}
+
+// recordedSessionProbe answers runtime probes from recorded evidence. A
+// session the original decision never probed has no evidence to replay.
+func recordedSessionProbe(evidence map[string]AgentSessionEvidence) operatorSessionProbe {
+	return func(_ context.Context, session string) (AgentSessionEvidence, error) {
+		recorded, ok := evidence[session]
+		if !ok {
+			return AgentSessionEvidence{}, fmt.Errorf("no recorded session evidence for %s", session)
+		}
+		return recorded, nil
+	}
+}
@@ -0,0 +1,304 @@
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"metawsm/internal/model"
+	"metawsm/internal/policy"
+)
+
+// OperatorRuleTrace explains how one policy rule evaluated against a run.
+// Unmet lists the conditions that did not hold.
+type OperatorRuleTrace struct {
+	Name        string         `json:"name"`
+	Intent      OperatorIntent `json:"intent"`
+	Matched     bool           `json:"matched"`
+	Unmet       []string       `json:"unmet,omitempty"`
+	RateLimited bool           `json:"rate_limited,omitempty"`
+	// Firings counts the rule's decisions for the run inside its rate limit
+	// window.
+	Firings int `json:"firings,omitempty"`
+}
+
+type OperatorRulesTestOptions struct {
+	RunID string
+	// DecisionID evaluates the inputs recorded with an audited decision
+	// instead of the run's live state.
+	DecisionID int64
+	PolicyPath string
+}
+
+// OperatorRulesTestResult is what the operator would decide for a run under
+// a policy. MatchedRule is empty when no policy rule matched and the
+// built-in rules decided.
+type OperatorRulesTestResult struct {
+	PolicyPath  string              `json:"policy_path"`
+	RunID       string              `json:"run_id"`
+	DecisionID  int64               `json:"decision_id,omitempty"`
+	EvaluatedAt time.Time           `json:"evaluated_at"`
+	Rules       []OperatorRuleTrace `json:"rules"`
+	MatchedRule string              `json:"matched_rule,omitempty"`
+	Intent      OperatorIntent      `json:"intent"`
+	Reason      string              `json:"reason"`
+	Execute     bool                `json:"execute"`
+}
+
+// OperatorRulesTest evaluates the policy's operator rules against a run's
+// live snapshot, or against the inputs recorded with an audited decision.
+// It only reads state: nothing is executed and no rule firings are recorded.
+func (s *Service) OperatorRulesTest(ctx context.Context, options OperatorRulesTestOptions) (OperatorRulesTestResult, error) {
+	cfg, policyPath, err := policy.Load(options.PolicyPath)
+	if err != nil {
+		return OperatorRulesTestResult{}, err
+	}
+
+	var inputs OperatorDecisionInputs
+	var probe operatorSessionProbe
+	if options.DecisionID > 0 {
+		record, err := s.store.GetOperatorDecision(options.DecisionID)
+		if err != nil {
+			return OperatorRulesTestResult{}, err
+		}
+		if record == nil {
+			return OperatorRulesTestResult{}, fmt.Errorf("operator decision %d not found", options.DecisionID)
+		}
+		if err := json.Unmarshal(record.Inputs, &inputs); err != nil {
+			return OperatorRulesTestResult{}, fmt.Errorf("decode recorded inputs for decision %d: %w", record.ID, err)
+		}
+		probe = recordedSessionProbe(inputs.SessionEvidence)
+	} else {
+		runID := strings.TrimSpace(options.RunID)
+		if runID == "" {
+			return OperatorRulesTestResult{}, fmt.Errorf("run id or decision id is required")
+		}
+		report, err := s.StatusReport(ctx, runID)
+		if err != nil {
+			return OperatorRulesTestResult{}, err
+		}
+		state, err := s.store.GetOperatorRunState(runID)
+		if err != nil {
+			return OperatorRulesTestResult{}, err
+		}
+		inputs = OperatorDecisionInputs{
+			Snapshot: report.Snapshot(),
+			Run:      report.Run,
+			State:    state,
+			Now:      time.Now(),
+		}
+		// Count this evaluation as the pass it stands in for.
+		if len(inputs.Snapshot.UnhealthyAgents) > 0 && inputs.Snapshot.Status == model.RunStatusRunning {
+			inputs.UnhealthyIntervals = 1
+			if state != nil {
+				inputs.UnhealthyIntervals = state.UnhealthyIntervals + 1
+			}
+		}
+		probe = func(ctx context.Context, session string) (AgentSessionEvidence, error) {
+			return s.ProbeAgentSession(ctx, runID, session)
+		}
+	}
+
+	input := inputs.ruleInput(probe)
+	traces, _ := traceOperatorPolicyRules(cfg.Operator.Rules, input)
+	decision, err := buildOperatorRuleDecision(ctx, cfg, input)
+	if err != nil {
+		return OperatorRulesTestResult{}, err
+	}
+	return OperatorRulesTestResult{
+		PolicyPath:  policyPath,
+		RunID:       inputs.Run.RunID,
+		DecisionID:  options.DecisionID,
+		EvaluatedAt: inputs.Now,
+		Rules:       traces,
+		MatchedRule: decision.Rule,
+		Intent:      decision.Intent,
+		Reason:      decision.Reason,
+		Execute:     decision.Execute,
+	}, nil
+}
+
+// traceOperatorPolicyRules evaluates every rule against input and returns the
+// index of the first match, or -1.
+func traceOperatorPolicyRules(rules []policy.OperatorRule, input operatorRuleInput) ([]OperatorRuleTrace, int) {
+	traces := make([]OperatorRuleTrace, 0, len(rules))
+	matched := -1
+	for i, rule := range rules {
+		trace := OperatorRuleTrace{
+			Name:   rule.Name,
+			Intent: OperatorIntent(rule.Intent),
+			Unmet:  unmetOperatorRuleConditions(rule.When, input),
+		}
+		trace.Matched = len(trace.Unmet) == 0
+		if rule.RateLimit != nil {
+			trace.Firings = operatorRuleFiringsSince(input.State, rule.Name, input.Now.Add(-time.Duration(rule.RateLimit.WindowSeconds)*time.Second))
+			trace.RateLimited = trace.Matched && trace.Firings >= rule.RateLimit.Max
+		}
+		if trace.Matched && matched < 0 {
+			matched = i
+		}
+		traces = append(traces, trace)
+	}
+	return traces, matched
+}
+
+func unmetOperatorRuleConditions(when policy.OperatorRuleConditions, input operatorRuleInput) []string {
+	snapshot := input.Snapshot
+	unmet := []string{}
+	if len(when.RunStatus) > 0 && !containsToken(when.RunStatus, string(snapshot.Status)) {
+		unmet = append(unmet, fmt.Sprintf("run_status %s not in %s", snapshot.Status, strings.Join(when.RunStatus, "|")))
+	}
+
+	hasUnhealthy := len(snapshot.UnhealthyAgents) > 0
+	if when.UnhealthyAgents != nil && *when.UnhealthyAgents != hasUnhealthy {
+		unmet = append(unmet, fmt.Sprintf("unhealthy_agents is %t", hasUnhealthy))
+	}
+	if len(when.AgentHealth) > 0 {
+		found := false
+		for _, agent := range snapshot.UnhealthyAgents {
+			if containsToken(when.AgentHealth, string(agent.Health)) || containsToken(when.AgentHealth, string(agent.Status)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			unmet = append(unmet, fmt.Sprintf("no unhealthy agent is %s", strings.Join(when.AgentHealth, "|")))
+		}
+	}
+	if when.UnhealthyIntervalsMin > 0 && input.UnhealthyIntervals < when.UnhealthyIntervalsMin {
+		unmet = append(unmet, fmt.Sprintf("unhealthy_intervals %d < %d", input.UnhealthyIntervals, when.UnhealthyIntervalsMin))
+	}
+
+	needsGuidance := operatorSnapshotNeedsGuidance(snapshot)
+	if when.GuidancePending != nil && *when.GuidancePending != needsGuidance {
+		unmet = append(unmet, fmt.Sprintf("guidance_pending is %t", needsGuidance))
+	}
+	if when.GuidanceAgeMinSeconds > 0 {
+		minAge := time.Duration(when.GuidanceAgeMinSeconds) * time.Second
+		var oldest *time.Time
+		for _, item := range snapshot.PendingGuidance {
+			if item.RequestedAt != nil && (oldest == nil || item.RequestedAt.Before(*oldest)) {
+				oldest = item.RequestedAt
+			}
+		}
+		switch {
+		case oldest == nil:
+			unmet = append(unmet, "no pending guidance with a known request time")
+		case input.Now.Sub(*oldest) < minAge:
+			unmet = append(unmet, fmt.Sprintf("guidance age %s < %s", input.Now.Sub(*oldest).Truncate(time.Second), minAge))
+		}
+	}
+
+	if when.IdleMinSeconds > 0 {
+		minIdle := time.Duration(when.IdleMinSeconds) * time.Second
+		if idle := input.Now.Sub(input.Run.UpdatedAt); idle < minIdle {
+			unmet = append(unmet, fmt.Sprintf("run idle %s < %s", idle.Truncate(time.Second), minIdle))
+		}
+	}
+	if when.DirtyDiffs != nil && *when.DirtyDiffs != snapshot.HasDirtyDiffs {
+		unmet = append(unmet, fmt.Sprintf("dirty_diffs is %t", snapshot.HasDirtyDiffs))
+	}
+	counts := []struct {
+		field string
+		value int
+		min   int
+	}{
+		{"draft_prs", snapshot.DraftPullRequests, when.DraftPRsMin},
+		{"open_prs", snapshot.OpenPullRequests, when.OpenPRsMin},
+		{"queued_feedback", snapshot.QueuedReviewFeedback, when.QueuedFeedbackMin},
+		{"new_feedback", snapshot.NewReviewFeedback, when.NewFeedbackMin},
+	}
+	for _, count := range counts {
+		if count.min > 0 && count.value < count.min {
+			unmet = append(unmet, fmt.Sprintf("%s %d < %d", count.field, count.value, count.min))
+		}
+	}
+	if len(unmet) == 0 {
+		return nil
+	}
+	return unmet
+}
+
+// policyRuleDecision turns a matched rule into a decision. The restart budget
+// and cooldown still bound auto_restart, and auto_stop_stale still requires
+// runtime evidence that the agents are gone, whatever the rule says.
+func policyRuleDecision(ctx context.Context, cfg policy.Config, rule policy.OperatorRule, trace OperatorRuleTrace, input operatorRuleInput) (operatorRuleDecision, error) {
+	prefix := "rule " + rule.Name + ": "
+	if trace.RateLimited {
+		return operatorRuleDecision{
+			Intent: OperatorIntentNoop,
+			Reason: fmt.Sprintf("%srate limit reached (%d/%d in %s)", prefix, trace.Firings, rule.RateLimit.Max, time.Duration(rule.RateLimit.WindowSeconds)*time.Second),
+			Rule:   rule.Name,
+		}, nil
+	}
+	reason := strings.TrimSpace(rule.Reason)
+	if reason == "" {
+		reason = "conditions matched"
+	}
+	decision := operatorRuleDecision{
+		Intent:  OperatorIntent(rule.Intent),
+		Reason:  prefix + reason,
+		Execute: rule.Execute,
+		Rule:    rule.Name,
+	}
+	switch decision.Intent {
+	case OperatorIntentAutoRestart:
+		if blocked, ok := operatorRestartBlocked(cfg, input); ok {
+			blocked.Reason = prefix + blocked.Reason
+			blocked.Rule = rule.Name
+			return blocked, nil
+		}
+	case OperatorIntentAutoStopStale:
+		recentWindow := time.Duration(cfg.Health.ActivityStalledSeconds) * time.Second
+		verified, verifyReason, err := verifyStaleRuntimeEvidence(ctx, input.Snapshot, input.Now, recentWindow, input.Probe)
+		if err != nil {
+			return operatorRuleDecision{}, err
+		}
+		if !verified {
+			return operatorRuleDecision{Intent: OperatorIntentNoop, Reason: prefix + verifyReason, Rule: rule.Name}, nil
+		}
+		decision.Reason += "; " + verifyReason
+	}
+	return decision, nil
+}
+
+func operatorRuleFiringsSince(state *model.OperatorRunState, ruleName string, since time.Time) int {
+	if state == nil {
+		return 0
+	}
+	count := 0
+	for _, firedAt := range state.RuleFirings[ruleName] {
+		if firedAt.After(since) {
+			count++
+		}
+	}
+	return count
+}
+
+// recordOperatorRuleFiring notes that ruleName decided at now. Firings of
+// rules without a rate limit are not kept, and firings that have left their
+// rule's window are dropped.
+func recordOperatorRuleFiring(cfg policy.Config, state *model.OperatorRunState, ruleName string, now time.Time) {
+	kept := map[string][]time.Time{}
+	for name, firings := range state.RuleFirings {
+		rule := cfg.OperatorRuleByName(name)
+		if rule == nil || rule.RateLimit == nil {
+			continue
+		}
+		since := now.Add(-time.Duration(rule.RateLimit.WindowSeconds) * time.Second)
+		for _, firedAt := range firings {
+			if firedAt.After(since) {
+				kept[name] = append(kept[name], firedAt)
+			}
+		}
+	}
+	if rule := cfg.OperatorRuleByName(ruleName); rule != nil && rule.RateLimit != nil {
+		kept[ruleName] = append(kept[ruleName], now)
+	}
+	if len(kept) == 0 {
+		kept = nil
+	}
+	state.RuleFirings = kept
+}
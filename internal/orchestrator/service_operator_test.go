@@ -0,0 +1,494 @@
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"metawsm/internal/model"
+	"metawsm/internal/policy"
+)
+
+func TestBuildOperatorRuleDecisionGitPRReadiness(t *testing.T) {
+	now := time.Now()
+	cases := []struct {
+		name         string
+		snapshot     RunSnapshot
+		gitPRMode    string
+		reviewMode   string
+		wantIntent   OperatorIntent
+		wantExecuted bool
+	}{
+		{
+			name:       "commit ready assist",
+			snapshot:   RunSnapshot{RunID: "run-commit-ready", Status: model.RunStatusComplete, HasDirtyDiffs: true},
+			gitPRMode:  "assist",
+			wantIntent: OperatorIntentCommitReady,
+		},
+		{
+			name:         "pr ready auto",
+			snapshot:     RunSnapshot{RunID: "run-pr-ready", Status: model.RunStatusComplete, DraftPullRequests: 2},
+			gitPRMode:    "auto",
+			wantIntent:   OperatorIntentPRReady,
+			wantExecuted: true,
+		},
+		{
+			name:         "review feedback ready auto",
+			snapshot:     RunSnapshot{RunID: "run-review-ready", Status: model.RunStatusComplete, QueuedReviewFeedback: 2},
+			gitPRMode:    "assist",
+			reviewMode:   "auto",
+			wantIntent:   OperatorIntentReviewFeedbackReady,
+			wantExecuted: true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := policy.Default()
+			cfg.GitPR.Mode = tc.gitPRMode
+			if tc.reviewMode != "" {
+				cfg.GitPR.ReviewFeedback.Enabled = true
+				cfg.GitPR.ReviewFeedback.Mode = tc.reviewMode
+			}
+			decision, err := buildOperatorRuleDecision(context.Background(), cfg, operatorRuleInput{
+				Snapshot: tc.snapshot,
+				Run:      model.RunRecord{RunID: tc.snapshot.RunID, Status: model.RunStatusComplete, UpdatedAt: now},
+				Now:      now,
+			})
+			if err != nil {
+				t.Fatalf("build operator rule decision: %v", err)
+			}
+			if decision.Intent != tc.wantIntent || decision.Execute != tc.wantExecuted {
+				t.Fatalf("expected %s execute=%t, got %+v", tc.wantIntent, tc.wantExecuted, decision)
+			}
+		})
+	}
+}
+
+func TestBuildOperatorRuleDecisionRestartBudget(t *testing.T) {
+	now := time.Now()
+	cfg := policy.Default()
+	input := operatorRuleInput{
+		Snapshot: RunSnapshot{
+			RunID:           "run-1",
+			Status:          model.RunStatusRunning,
+			UnhealthyAgents: []RunUnhealthyAgentSnapshot{{AgentName: "agent", SessionName: "session-a"}},
+		},
+		Run:                model.RunRecord{RunID: "run-1", Status: model.RunStatusRunning, UpdatedAt: now},
+		UnhealthyIntervals: 1,
+		Now:                now,
+	}
+	decision, err := buildOperatorRuleDecision(context.Background(), cfg, input)
+	if err != nil || decision.Intent != OperatorIntentNoop || !strings.Contains(decision.Reason, "1/2") {
+		t.Fatalf("expected corroboration wait, got %+v (%v)", decision, err)
+	}
+
+	input.UnhealthyIntervals = 2
+	decision, err = buildOperatorRuleDecision(context.Background(), cfg, input)
+	if err != nil || decision.Intent != OperatorIntentAutoRestart || !decision.Execute {
+		t.Fatalf("expected auto restart, got %+v (%v)", decision, err)
+	}
+
+	input.State = &model.OperatorRunState{RunID: "run-1", RestartAttempts: cfg.Operator.RestartBudget}
+	decision, err = buildOperatorRuleDecision(context.Background(), cfg, input)
+	if err != nil || decision.Intent != OperatorIntentEscalateBlocked || decision.Execute {
+		t.Fatalf("expected exhausted budget to escalate, got %+v (%v)", decision, err)
+	}
+}
+
+func TestClassifyStaleRunCandidate(t *testing.T) {
+	now := time.Now()
+	run := model.RunRecord{
+		RunID:     "run-1",
+		Status:    model.RunStatusRunning,
+		UpdatedAt: now.Add(-2 * time.Hour),
+	}
+	snapshot := RunSnapshot{
+		RunID:           "run-1",
+		Status:          model.RunStatusRunning,
+		UnhealthyAgents: []RunUnhealthyAgentSnapshot{{AgentName: "agent"}},
+	}
+
+	stale, reason := classifyStaleRunCandidate(snapshot, run, now, time.Hour)
+	if !stale {
+		t.Fatalf("expected stale candidate")
+	}
+	if !strings.Contains(reason, "run appears stale") {
+		t.Fatalf("expected stale reason, got %q", reason)
+	}
+}
+
+func TestVerifyStaleRuntimeEvidenceRejectsActiveSession(t *testing.T) {
+	now := time.Now()
+	snapshot := RunSnapshot{
+		UnhealthyAgents: []RunUnhealthyAgentSnapshot{{AgentName: "agent", WorkspaceName: "ws", SessionName: "session-a"}},
+	}
+	probe := func(ctx context.Context, session string) (AgentSessionEvidence, error) {
+		lastActivity := now.Add(-30 * time.Second)
+		return AgentSessionEvidence{
+			Session:      session,
+			HasSession:   true,
+			LastActivity: &lastActivity,
+		}, nil
+	}
+
+	verified, reason, err := verifyStaleRuntimeEvidence(context.Background(), snapshot, now, 2*time.Minute, probe)
+	if err != nil {
+		t.Fatalf("verify stale runtime evidence: %v", err)
+	}
+	if verified {
+		t.Fatalf("expected stale verification rejection for active session")
+	}
+	if !strings.Contains(reason, "recent activity") {
+		t.Fatalf("expected recent activity reason, got %q", reason)
+	}
+}
+
+func TestVerifyStaleRuntimeEvidenceAcceptsExitedSessions(t *testing.T) {
+	now := time.Now()
+	snapshot := RunSnapshot{
+		UnhealthyAgents: []RunUnhealthyAgentSnapshot{{AgentName: "agent", WorkspaceName: "ws", SessionName: "session-a"}},
+	}
+	probe := func(ctx context.Context, session string) (AgentSessionEvidence, error) {
+		code := 1
+		return AgentSessionEvidence{
+			Session:    session,
+			HasSession: true,
+			ExitCode:   &code,
+		}, nil
+	}
+
+	verified, reason, err := verifyStaleRuntimeEvidence(context.Background(), snapshot, now, 2*time.Minute, probe)
+	if err != nil {
+		t.Fatalf("verify stale runtime evidence: %v", err)
+	}
+	if !verified {
+		t.Fatalf("expected stale verification success")
+	}
+	if !strings.Contains(reason, "no active agent sessions") {
+		t.Fatalf("expected no-active-session reason, got %q", reason)
+	}
+}
+
+func TestResolveOperatorLLMMode(t *testing.T) {
+	mode, err := ResolveOperatorLLMMode("", "assist")
+	if err != nil || mode != "assist" {
+		t.Fatalf("expected policy mode assist, got %q (%v)", mode, err)
+	}
+	mode, err = ResolveOperatorLLMMode("AUTO", "assist")
+	if err != nil || mode != "auto" {
+		t.Fatalf("expected override auto, got %q (%v)", mode, err)
+	}
+	if _, err := ResolveOperatorLLMMode("invalid", "assist"); err == nil {
+		t.Fatalf("expected invalid mode error")
+	}
+}
+
+func TestOperatorPassRecordsDecisionsUnderLease(t *testing.T) {
+	setupWorkspaceConfigRoot(t)
+	svc := newTestService(t)
+	result, err := svc.Run(t.Context(), RunOptions{
+		Tickets:           []string{"METAWSM-022"},
+		Repos:             []string{"metawsm"},
+		WorkspaceStrategy: model.WorkspaceStrategyCreate,
+		DocSeedMode:       string(model.DocSeedModeNone),
+		DryRun:            true,
+	})
+	if err != nil {
+		t.Fatalf("run dry-run: %v", err)
+	}
+	if err := svc.store.UpdateRunStatus(result.RunID, model.RunStatusAwaitingGuidance, ""); err != nil {
+		t.Fatalf("update run status: %v", err)
+	}
+
+	now := time.Now()
+	options := OperatorPassOptions{LLMMode: "off", Holder: "serve-a", LeaseTTL: time.Minute, Now: now}
+	pass, err := svc.OperatorPass(t.Context(), options)
+	if err != nil {
+		t.Fatalf("operator pass: %v", err)
+	}
+	if pass.LLMMode != "off" || len(pass.Runs) != 1 || !pass.Runs[0].Active {
+		t.Fatalf("unexpected pass result %+v", pass)
+	}
+	decision := pass.Runs[0].Decision
+	if decision == nil || decision.Intent != OperatorIntentEscalateGuidance || decision.Event != "guidance_needed" || decision.Executed {
+		t.Fatalf("expected guidance escalation decision, got %+v", decision)
+	}
+
+	options.Now = now.Add(15 * time.Second)
+	pass, err = svc.OperatorPass(t.Context(), options)
+	if err != nil || pass.Runs[0].Decision != nil {
+		t.Fatalf("expected repeated alert to be suppressed, got %+v (%v)", pass.Runs[0].Decision, err)
+	}
+
+	other := OperatorPassOptions{LLMMode: "off", Holder: "serve-b", LeaseTTL: time.Minute, Now: now.Add(20 * time.Second)}
+	pass, err = svc.OperatorPass(t.Context(), other)
+	if err != nil || pass.Runs[0].LeaseHolder != "serve-a" || pass.Runs[0].Decision != nil {
+		t.Fatalf("expected run leased to serve-a to be skipped, got %+v (%v)", pass.Runs[0], err)
+	}
+
+	views, err := svc.OperatorDecisions("", 10)
+	if err != nil {
+		t.Fatalf("operator decisions: %v", err)
+	}
+	if len(views) != 1 || views[0].RunID != result.RunID || views[0].Lease == nil || views[0].Lease.Holder != "serve-a" {
+		t.Fatalf("unexpected operator decisions view %+v", views)
+	}
+	if len(views[0].Decisions) != 1 {
+		t.Fatalf("expected one recorded decision, got %+v", views[0].Decisions)
+	}
+	event := views[0].Decisions[0]
+	if event.EntityID != "serve-a" || event.EventType != "guidance_needed" || event.ToState != string(OperatorIntentEscalateGuidance) || !strings.Contains(event.Message, "alert only") {
+		t.Fatalf("unexpected decision event %+v", event)
+	}
+	if views[0].State == nil || views[0].State.LastEvent != "guidance_needed" {
+		t.Fatalf("expected last event to persist, got %+v", views[0].State)
+	}
+
+	if err := svc.ReleaseOperatorLeases("serve-a"); err != nil {
+		t.Fatalf("release leases: %v", err)
+	}
+	pass, err = svc.OperatorPass(t.Context(), other)
+	if err != nil || pass.Runs[0].LeaseHolder != "" {
+		t.Fatalf("expected released run to be taken over, got %+v (%v)", pass.Runs[0], err)
+	}
+}
+
+func TestConfirmOperatorLeaseFailsAfterTakeover(t *testing.T) {
+	svc := newStoreOnlyService(t)
+	now := time.Now()
+	options := OperatorPassOptions{Holder: "serve-a", LeaseTTL: time.Minute, Now: now}
+	if _, owned, err := svc.store.AcquireOperatorLease("run-1", "serve-a", now, time.Minute); err != nil || !owned {
+		t.Fatalf("acquire lease: owned=%t err=%v", owned, err)
+	}
+	options.Now = now.Add(50 * time.Second)
+	if err := svc.confirmOperatorLease("run-1", options); err != nil {
+		t.Fatalf("expected held lease to be confirmed, got %v", err)
+	}
+	lease, err := svc.store.GetOperatorLease("run-1")
+	if err != nil || lease == nil || !lease.ExpiresAt.After(now.Add(time.Minute)) {
+		t.Fatalf("expected confirmation to renew the lease, got %+v (%v)", lease, err)
+	}
+
+	if _, owned, err := svc.store.AcquireOperatorLease("run-1", "serve-b", now.Add(3*time.Minute), time.Minute); err != nil || !owned {
+		t.Fatalf("take over expired lease: owned=%t err=%v", owned, err)
+	}
+	options.Now = now.Add(3 * time.Minute)
+	if err := svc.confirmOperatorLease("run-1", options); err == nil || !strings.Contains(err.Error(), "serve-b") {
+		t.Fatalf("expected confirmation to fail once serve-b holds the lease, got %v", err)
+	}
+}
+
+func TestOperatorReplayFlagsPolicyChanges(t *testing.T) {
+	setupWorkspaceConfigRoot(t)
+	svc := newTestService(t)
+	result, err := svc.Run(t.Context(), RunOptions{
+		Tickets:           []string{"METAWSM-024"},
+		Repos:             []string{"metawsm"},
+		WorkspaceStrategy: model.WorkspaceStrategyCreate,
+		DocSeedMode:       string(model.DocSeedModeNone),
+		DryRun:            true,
+	})
+	if err != nil {
+		t.Fatalf("run dry-run: %v", err)
+	}
+	if err := svc.store.UpdateRunStatus(result.RunID, model.RunStatusAwaitingGuidance, ""); err != nil {
+		t.Fatalf("update run status: %v", err)
+	}
+	if _, err := svc.OperatorPass(t.Context(), OperatorPassOptions{LLMMode: "off", Holder: "serve-a", LeaseTTL: time.Minute}); err != nil {
+		t.Fatalf("operator pass: %v", err)
+	}
+
+	history, err := svc.OperatorHistory(result.RunID, 10)
+	if err != nil {
+		t.Fatalf("operator history: %v", err)
+	}
+	if len(history) != 1 || history[0].Intent != string(OperatorIntentEscalateGuidance) || history[0].Holder != "serve-a" || len(history[0].Inputs) == 0 {
+		t.Fatalf("unexpected operator history %+v", history)
+	}
+
+	replay, err := svc.OperatorReplay(t.Context(), OperatorReplayOptions{RunID: result.RunID})
+	if err != nil {
+		t.Fatalf("operator replay: %v", err)
+	}
+	if replay.Total != 1 || replay.Changed != 0 || replay.Failed != 0 || replay.Entries[0].Intent != OperatorIntentEscalateGuidance {
+		t.Fatalf("expected unchanged replay, got %+v", replay)
+	}
+
+	changed := history[0]
+	changed.Intent = string(OperatorIntentNoop)
+	if err := svc.store.AddOperatorDecision(changed); err != nil {
+		t.Fatalf("add operator decision: %v", err)
+	}
+	replay, err = svc.OperatorReplay(t.Context(), OperatorReplayOptions{RunID: result.RunID})
+	if err != nil {
+		t.Fatalf("operator replay: %v", err)
+	}
+	if replay.Total != 2 || replay.Changed != 1 || !replay.Entries[1].Changed || replay.Entries[1].RecordedIntent != OperatorIntentNoop {
+		t.Fatalf("expected drifted decision to be flagged, got %+v", replay)
+	}
+}
+
+func TestOperatorPassCountsRepeatedPassesWithoutAddingRows(t *testing.T) {
+	setupWorkspaceConfigRoot(t)
+	svc := newTestService(t)
+	result, err := svc.Run(t.Context(), RunOptions{
+		Tickets:           []string{"METAWSM-024"},
+		Repos:             []string{"metawsm"},
+		WorkspaceStrategy: model.WorkspaceStrategyCreate,
+		DocSeedMode:       string(model.DocSeedModeNone),
+		DryRun:            true,
+	})
+	if err != nil {
+		t.Fatalf("run dry-run: %v", err)
+	}
+	if err := svc.store.UpdateRunStatus(result.RunID, model.RunStatusAwaitingGuidance, ""); err != nil {
+		t.Fatalf("update run status: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := svc.OperatorPass(t.Context(), OperatorPassOptions{LLMMode: "off"}); err != nil {
+			t.Fatalf("operator pass %d: %v", i, err)
+		}
+	}
+
+	history, err := svc.OperatorHistory(result.RunID, 10)
+	if err != nil {
+		t.Fatalf("operator history: %v", err)
+	}
+	if len(history) != 1 {
+		t.Fatalf("expected repeated passes to add no rows, got %+v", history)
+	}
+	if history[0].Event != "guidance_needed" || history[0].RepeatedCount != 2 || history[0].LastSeenAt.Before(history[0].CreatedAt) {
+		t.Fatalf("expected the alert to count two repeats, got %+v", history[0])
+	}
+}
+
+func TestBuildOperatorRuleDecisionPolicyRules(t *testing.T) {
+	now := time.Now()
+	yes := true
+	cfg := policy.Default()
+	cfg.Operator.Rules = []policy.OperatorRule{
+		{
+			Name:   "old-guidance",
+			When:   policy.OperatorRuleConditions{GuidancePending: &yes, GuidanceAgeMinSeconds: 600},
+			Intent: "escalate_blocked",
+			Reason: "guidance unanswered for 10m",
+		},
+		{
+			Name:      "restart-dead",
+			When:      policy.OperatorRuleConditions{RunStatus: []string{"running"}, AgentHealth: []string{"dead"}},
+			Intent:    "auto_restart",
+			Execute:   true,
+			RateLimit: &policy.OperatorRuleRateLimit{Max: 1, WindowSeconds: 3600},
+		},
+		{
+			Name:   "feedback-assist",
+			When:   policy.OperatorRuleConditions{RunStatus: []string{"completed"}, QueuedFeedbackMin: 1},
+			Intent: "review_feedback_ready",
+		},
+	}
+	decide := func(input operatorRuleInput) operatorRuleDecision {
+		t.Helper()
+		input.Now = now
+		decision, err := buildOperatorRuleDecision(context.Background(), cfg, input)
+		if err != nil {
+			t.Fatalf("build operator rule decision: %v", err)
+		}
+		return decision
+	}
+
+	requested := now.Add(-20 * time.Minute)
+	guidance := RunSnapshot{RunID: "run-1", Status: model.RunStatusAwaitingGuidance, PendingGuidance: []RunGuidanceSnapshot{{ThreadID: "t-1", RequestedAt: &requested}}}
+	if decision := decide(operatorRuleInput{Snapshot: guidance}); decision.Rule != "old-guidance" || decision.Intent != OperatorIntentEscalateBlocked {
+		t.Fatalf("expected old guidance rule, got %+v", decision)
+	}
+	requested = now.Add(-time.Minute)
+	if decision := decide(operatorRuleInput{Snapshot: guidance}); decision.Rule != "" || decision.Intent != OperatorIntentEscalateGuidance {
+		t.Fatalf("expected fresh guidance to fall back to built-in rules, got %+v", decision)
+	}
+
+	dead := RunSnapshot{RunID: "run-1", Status: model.RunStatusRunning, UnhealthyAgents: []RunUnhealthyAgentSnapshot{{AgentName: "agent", Health: model.HealthStateDead}}}
+	decision := decide(operatorRuleInput{Snapshot: dead, UnhealthyIntervals: 1})
+	if decision.Rule != "restart-dead" || decision.Intent != OperatorIntentAutoRestart || !decision.Execute {
+		t.Fatalf("expected rule to restart without corroboration, got %+v", decision)
+	}
+	state := &model.OperatorRunState{RunID: "run-1"}
+	recordOperatorRuleFiring(cfg, state, "restart-dead", now.Add(-10*time.Minute))
+	decision = decide(operatorRuleInput{Snapshot: dead, State: state})
+	if decision.Rule != "restart-dead" || decision.Intent != OperatorIntentNoop || !strings.Contains(decision.Reason, "rate limit reached (1/1") {
+		t.Fatalf("expected rate limited rule to decide noop, got %+v", decision)
+	}
+	state.RuleFirings["restart-dead"] = []time.Time{now.Add(-2 * time.Hour)}
+	state.RestartAttempts = cfg.Operator.RestartBudget
+	decision = decide(operatorRuleInput{Snapshot: dead, State: state})
+	if decision.Intent != OperatorIntentEscalateBlocked || decision.Execute {
+		t.Fatalf("expected restart budget to bound rule restarts, got %+v", decision)
+	}
+
+	feedback := RunSnapshot{RunID: "run-1", Status: model.RunStatusComplete, QueuedReviewFeedback: 2}
+	if decision := decide(operatorRuleInput{Snapshot: feedback}); decision.Rule != "feedback-assist" || decision.Execute {
+		t.Fatalf("expected feedback rule without execution, got %+v", decision)
+	}
+	traces, matched := traceOperatorPolicyRules(cfg.Operator.Rules, operatorRuleInput{Snapshot: feedback, Now: now})
+	if matched != 2 || len(traces) != 3 || traces[0].Matched || len(traces[1].Unmet) != 2 {
+		t.Fatalf("unexpected rule traces %+v (matched %d)", traces, matched)
+	}
+}
+
+func TestOperatorRulesTestEvaluatesRecordedDecision(t *testing.T) {
+	setupWorkspaceConfigRoot(t)
+	svc := newTestService(t)
+	result, err := svc.Run(t.Context(), RunOptions{
+		Tickets:           []string{"METAWSM-025"},
+		Repos:             []string{"metawsm"},
+		WorkspaceStrategy: model.WorkspaceStrategyCreate,
+		DocSeedMode:       string(model.DocSeedModeNone),
+		DryRun:            true,
+	})
+	if err != nil {
+		t.Fatalf("run dry-run: %v", err)
+	}
+	if err := svc.store.UpdateRunStatus(result.RunID, model.RunStatusAwaitingGuidance, ""); err != nil {
+		t.Fatalf("update run status: %v", err)
+	}
+	if _, err := svc.OperatorPass(t.Context(), OperatorPassOptions{LLMMode: "off", Holder: "serve-a", LeaseTTL: time.Minute}); err != nil {
+		t.Fatalf("operator pass: %v", err)
+	}
+	history, err := svc.OperatorHistory(result.RunID, 1)
+	if err != nil || len(history) != 1 {
+		t.Fatalf("expected one audited decision, got %+v (%v)", history, err)
+	}
+
+	cfg := policy.Default()
+	cfg.Operator.Rules = []policy.OperatorRule{{
+		Name:   "guidance-blocks",
+		When:   policy.OperatorRuleConditions{RunStatus: []string{"awaiting_guidance"}},
+		Intent: "escalate_blocked",
+	}}
+	payload, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatalf("marshal policy: %v", err)
+	}
+	policyPath := filepath.Join(t.TempDir(), "policy.json")
+	if err := os.WriteFile(policyPath, payload, 0o644); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+
+	recorded, err := svc.OperatorRulesTest(t.Context(), OperatorRulesTestOptions{DecisionID: history[0].ID, PolicyPath: policyPath})
+	if err != nil {
+		t.Fatalf("operator rules test: %v", err)
+	}
+	if recorded.RunID != result.RunID || recorded.MatchedRule != "guidance-blocks" || recorded.Intent != OperatorIntentEscalateBlocked || len(recorded.Rules) != 1 || !recorded.Rules[0].Matched {
+		t.Fatalf("unexpected recorded rules test %+v", recorded)
+	}
+	live, err := svc.OperatorRulesTest(t.Context(), OperatorRulesTestOptions{RunID: result.RunID})
+	if err != nil {
+		t.Fatalf("operator rules test live: %v", err)
+	}
+	if live.MatchedRule != "" || live.Intent != OperatorIntentEscalateGuidance {
+		t.Fatalf("expected default policy to use built-in rules, got %+v", live)
+	}
+}
@@ -0,0 +1,40 @@
+package orchestrator
+
+import (
+	"metawsm/internal/policy"
+	"metawsm/internal/store"
+)
+
+type MigrateOptions = store.MigrateOptions
+
+// DatabaseSchemaStatus reports applied and pending migrations without starting
+// the forum bus or migrating the database.
+func DatabaseSchemaStatus(dbPath string) (store.SchemaStatus, error) {
+	sqliteStore := newStore(dbPath, loadPolicyOrDefault())
+	defer sqliteStore.Close()
+	return sqliteStore.SchemaStatus()
+}
+
+func MigrateDatabase(dbPath string, options MigrateOptions) (store.MigrateResult, error) {
+	sqliteStore := newStore(dbPath, loadPolicyOrDefault())
+	defer sqliteStore.Close()
+	return sqliteStore.Migrate(options)
+}
+
+func newStore(dbPath string, cfg policy.Config) *store.SQLiteStore {
+	sqliteStore := store.NewSQLiteStore(dbPath)
+	sqliteStore.Backend = cfg.Store.Backend
+	sqliteStore.OutboxMaxAttempts = cfg.Forum.Outbox.MaxAttempts
+	sqliteStore.OutboxBackoffBaseMS = cfg.Forum.Outbox.BackoffBaseSeconds * 1000
+	sqliteStore.OutboxBackoffMaxMS = cfg.Forum.Outbox.BackoffMaxSeconds * 1000
+	sqliteStore.OutboxLeaseMS = cfg.Forum.Outbox.LeaseSeconds * 1000
+	return sqliteStore
+}
+
+func loadPolicyOrDefault() policy.Config {
+	cfg, _, err := policy.Load("")
+	if err != nil {
+		return policy.Default()
+	}
+	return cfg
+}
@@ -0,0 +1,620 @@
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"metawsm/internal/model"
+	"metawsm/internal/policy"
+)
+
+// RunStatusReport is everything known about one run at GeneratedAt. Status
+// renders it as text; watch, operator, `metawsm status --output json|yaml`
+// and the run API read the fields directly instead of parsing that text.
+type RunStatusReport struct {
+	Run             model.RunRecord          `json:"run"`
+	Mode            model.RunMode            `json:"mode,omitempty"`
+	Template        string                   `json:"template,omitempty"`
+	Tickets         []string                 `json:"tickets"`
+	Docs            RunStatusDocs            `json:"docs"`
+	Brief           *model.RunBrief          `json:"brief,omitempty"`
+	PendingGuidance []RunStatusGuidance      `json:"pending_guidance"`
+	Escalations     []model.ForumThreadView  `json:"escalations"`
+	Forum           RunStatusForum           `json:"forum"`
+	Diffs           []RunStatusWorkspaceDiff `json:"diffs"`
+	PullRequests    []model.RunPullRequest   `json:"pull_requests"`
+	ReviewFeedback  RunStatusReviewFeedback  `json:"review_feedback"`
+	Steps           RunStatusSteps           `json:"steps"`
+	Agents          []RunStatusAgent         `json:"agents"`
+	// Next lists suggested follow-up commands once the run is complete.
+	Next []string `json:"next,omitempty"`
+	// Warnings are advisory doc freshness findings; they never block a run.
+	Warnings    []string  `json:"warnings"`
+	GeneratedAt time.Time `json:"generated_at"`
+}
+
+type RunStatusDocs struct {
+	HomeRepo          string                 `json:"home_repo"`
+	AuthorityMode     model.DocAuthorityMode `json:"authority_mode"`
+	SeedMode          model.DocSeedMode      `json:"seed_mode"`
+	FreshnessRevision string                 `json:"freshness_revision,omitempty"`
+	Sync              []model.DocSyncState   `json:"sync"`
+}
+
+// RunStatusGuidance is a guidance request still waiting on an agent's forum
+// control thread.
+type RunStatusGuidance struct {
+	ThreadID      string `json:"thread_id"`
+	AgentName     string `json:"agent_name"`
+	WorkspaceName string `json:"workspace_name"`
+	Question      string `json:"question"`
+	// RequestedAt is when the agent asked; nil when unknown.
+	RequestedAt *time.Time `json:"requested_at,omitempty"`
+}
+
+// RunStatusForum counts the run's forum threads by state. Recent holds the
+// most recently updated threads.
+type RunStatusForum struct {
+	Total           int                     `json:"total"`
+	New             int                     `json:"new"`
+	WaitingOperator int                     `json:"waiting_operator"`
+	WaitingHuman    int                     `json:"waiting_human"`
+	Answered        int                     `json:"answered"`
+	Closed          int                     `json:"closed"`
+	Escalations     int                     `json:"escalations"`
+	Recent          []model.ForumThreadView `json:"recent"`
+}
+
+type RunStatusWorkspaceDiff struct {
+	WorkspaceName string              `json:"workspace_name"`
+	WorkspacePath string              `json:"workspace_path,omitempty"`
+	Error         string              `json:"error,omitempty"`
+	Repos         []RunStatusRepoDiff `json:"repos"`
+}
+
+// RunStatusRepoDiff is one repo in a workspace; Changes holds its
+// `git status --porcelain` lines and is empty when the repo is clean.
+type RunStatusRepoDiff struct {
+	Repo    string   `json:"repo"`
+	Error   string   `json:"error,omitempty"`
+	Changes []string `json:"changes"`
+}
+
+type RunStatusReviewFeedback struct {
+	Queued    int `json:"queued"`
+	New       int `json:"new"`
+	Addressed int `json:"addressed"`
+	Ignored   int `json:"ignored"`
+}
+
+type RunStatusSteps struct {
+	Total    int                `json:"total"`
+	Done     int                `json:"done"`
+	Running  int                `json:"running"`
+	Pending  int                `json:"pending"`
+	Failed   int                `json:"failed"`
+	Branches []RunStatusBranch  `json:"branches"`
+	Items    []model.StepRecord `json:"items"`
+}
+
+// RunStatusAgent is one agent session with its health evaluated at the
+// report's GeneratedAt. Ages are Go durations, or "-" when never observed.
+type RunStatusAgent struct {
+	Name           string            `json:"name"`
+	WorkspaceName  string            `json:"workspace_name"`
+	SessionName    string            `json:"session_name"`
+	Status         model.AgentStatus `json:"status"`
+	Health         model.HealthState `json:"health"`
+	LastActivityAt *time.Time        `json:"last_activity_at,omitempty"`
+	LastProgressAt *time.Time        `json:"last_progress_at,omitempty"`
+	ActivityAge    string            `json:"activity_age"`
+	ProgressAge    string            `json:"progress_age"`
+	Unhealthy      bool              `json:"unhealthy"`
+}
+
+// StatusReport refreshes agent health, bootstrap signals and workspace
+// progress for runID, then collects the run's full status.
+func (s *Service) StatusReport(ctx context.Context, runID string) (RunStatusReport, error) {
+	record, specJSON, policyJSON, err := s.store.GetRun(runID)
+	if err != nil {
+		return RunStatusReport{}, err
+	}
+	var spec model.RunSpec
+	if err := json.Unmarshal([]byte(specJSON), &spec); err != nil {
+		spec = model.RunSpec{}
+	}
+	steps, err := s.store.GetSteps(runID)
+	if err != nil {
+		return RunStatusReport{}, err
+	}
+	agents, err := s.store.GetAgents(runID)
+	if err != nil {
+		return RunStatusReport{}, err
+	}
+	tickets, err := s.store.GetTickets(runID)
+	if err != nil {
+		return RunStatusReport{}, err
+	}
+
+	cfg, _, err := policy.Load("")
+	if err != nil {
+		cfg = policy.Default()
+	}
+
+	rt := s.runAgentRuntime(policyJSON)
+	now := time.Now()
+	for _, agent := range agents {
+		health, status, lastActivity, lastProgress := evaluateHealth(ctx, cfg, rt, agent, now)
+		_ = s.store.UpdateAgentStatus(runID, agent.Name, agent.WorkspaceName, status, health, lastActivity, lastProgress)
+	}
+	s.refreshAgentTranscripts(runID, cfg)
+	agents, _ = s.store.GetAgents(runID)
+	if spec.Mode == model.RunModeBootstrap {
+		if err := s.syncBootstrapSignals(ctx, runID, record.Status, spec, agents); err != nil {
+			return RunStatusReport{}, err
+		}
+		record, _, _, _ = s.store.GetRun(runID)
+	}
+
+	workspaceDiffs := collectWorkspaceDiffs(ctx, workspaceNamesFromAgents(agents), spec.Repos)
+	progressByWorkspace := latestProgressFromWorkspaceDiffs(workspaceDiffs)
+	for i := range agents {
+		progressAt, ok := progressByWorkspace[agents[i].WorkspaceName]
+		if !ok {
+			continue
+		}
+		if agents[i].LastProgressAt != nil && !progressAt.After(*agents[i].LastProgressAt) {
+			continue
+		}
+		progressCopy := progressAt
+		agents[i].LastProgressAt = &progressCopy
+		_ = s.store.UpdateAgentStatus(
+			runID,
+			agents[i].Name,
+			agents[i].WorkspaceName,
+			agents[i].Status,
+			agents[i].HealthState,
+			agents[i].LastActivityAt,
+			agents[i].LastProgressAt,
+		)
+	}
+
+	controlStates, err := s.forumControlStatesForRun(runID, agents)
+	if err != nil {
+		return RunStatusReport{}, err
+	}
+	forumThreads, err := s.store.ListForumThreads(model.ForumThreadFilter{RunID: runID, Limit: 200})
+	if err != nil {
+		return RunStatusReport{}, err
+	}
+	brief, err := s.store.GetRunBrief(runID)
+	if err != nil {
+		return RunStatusReport{}, err
+	}
+	docSyncStates, err := s.store.ListDocSyncStates(runID)
+	if err != nil {
+		return RunStatusReport{}, err
+	}
+	runPullRequests, err := s.store.ListRunPullRequests(runID)
+	if err != nil {
+		return RunStatusReport{}, err
+	}
+	runReviewFeedback, err := s.store.ListRunReviewFeedback(runID)
+	if err != nil {
+		return RunStatusReport{}, err
+	}
+
+	report := RunStatusReport{
+		Run:             record,
+		Mode:            spec.Mode,
+		Template:        spec.Template,
+		Tickets:         tickets,
+		Brief:           brief,
+		PendingGuidance: []RunStatusGuidance{},
+		Escalations:     []model.ForumThreadView{},
+		Diffs:           make([]RunStatusWorkspaceDiff, 0, len(workspaceDiffs)),
+		PullRequests:    runPullRequests,
+		Agents:          make([]RunStatusAgent, 0, len(agents)),
+		Warnings:        []string{},
+		GeneratedAt:     now,
+	}
+
+	report.Docs = RunStatusDocs{
+		HomeRepo:          effectiveDocHomeRepo(spec),
+		AuthorityMode:     normalizeDocAuthorityMode(string(spec.DocAuthorityMode)),
+		SeedMode:          normalizeDocSeedMode(string(spec.DocSeedMode)),
+		FreshnessRevision: strings.TrimSpace(spec.DocFreshnessRevision),
+		Sync:              docSyncStates,
+	}
+	if report.Docs.AuthorityMode == "" {
+		report.Docs.AuthorityMode = model.DocAuthorityModeWorkspaceActive
+	}
+	if report.Docs.SeedMode == "" {
+		report.Docs.SeedMode = model.DocSeedModeCopyFromRepoOnStart
+	}
+	if report.Docs.FreshnessRevision == "" {
+		report.Docs.FreshnessRevision = latestDocFreshnessRevision(docSyncStates)
+	}
+	report.Warnings = append(report.Warnings, docFreshnessWarnings(docSyncStates, report.Docs.SeedMode, cfg.Docs.StaleWarningSeconds, now)...)
+
+	controlThreadIDs := map[string]struct{}{}
+	for _, agent := range agents {
+		state, ok := controlStates[agent.Name]
+		if !ok {
+			continue
+		}
+		if strings.TrimSpace(state.ThreadID) != "" {
+			controlThreadIDs[state.ThreadID] = struct{}{}
+		}
+		if state.PendingGuidance {
+			item := RunStatusGuidance{
+				ThreadID:      strings.TrimSpace(state.ThreadID),
+				AgentName:     strings.TrimSpace(agent.Name),
+				WorkspaceName: strings.TrimSpace(agent.WorkspaceName),
+				Question:      strings.TrimSpace(state.PendingGuidanceQuestion),
+			}
+			if !state.PendingGuidanceSince.IsZero() {
+				requestedAt := state.PendingGuidanceSince
+				item.RequestedAt = &requestedAt
+			}
+			report.PendingGuidance = append(report.PendingGuidance, item)
+		}
+	}
+
+	slaMinutes := cfg.Forum.SLA.EscalationMinutes
+	if slaMinutes <= 0 {
+		slaMinutes = 30
+	}
+	slaThreshold := time.Duration(slaMinutes) * time.Minute
+	for _, thread := range forumThreads {
+		if _, isControl := controlThreadIDs[thread.ThreadID]; isControl {
+			continue
+		}
+		if thread.State != model.ForumThreadStateNew && thread.State != model.ForumThreadStateWaitingHuman {
+			continue
+		}
+		age := now.Sub(thread.UpdatedAt)
+		if thread.Priority == model.ForumPriorityUrgent || thread.Priority == model.ForumPriorityHigh || age >= slaThreshold {
+			report.Escalations = append(report.Escalations, thread)
+		}
+	}
+	report.Forum = RunStatusForum{Total: len(forumThreads), Escalations: len(report.Escalations)}
+	for _, thread := range forumThreads {
+		switch thread.State {
+		case model.ForumThreadStateNew:
+			report.Forum.New++
+		case model.ForumThreadStateWaitingOperator:
+			report.Forum.WaitingOperator++
+		case model.ForumThreadStateWaitingHuman:
+			report.Forum.WaitingHuman++
+		case model.ForumThreadStateAnswered:
+			report.Forum.Answered++
+		case model.ForumThreadStateClosed:
+			report.Forum.Closed++
+		}
+	}
+	recent := len(forumThreads)
+	if recent > 5 {
+		recent = 5
+	}
+	report.Forum.Recent = forumThreads[:recent]
+
+	for _, diff := range workspaceDiffs {
+		item := RunStatusWorkspaceDiff{
+			WorkspaceName: diff.WorkspaceName,
+			WorkspacePath: diff.WorkspacePath,
+			Repos:         make([]RunStatusRepoDiff, 0, len(diff.Repos)),
+		}
+		if diff.Error != nil {
+			item.Error = diff.Error.Error()
+		}
+		for _, repo := range diff.Repos {
+			repoItem := RunStatusRepoDiff{Repo: repo.RepoLabel, Changes: repo.StatusLines}
+			if repo.Error != nil {
+				repoItem.Error = repo.Error.Error()
+			}
+			if repoItem.Changes == nil {
+				repoItem.Changes = []string{}
+			}
+			item.Repos = append(item.Repos, repoItem)
+		}
+		report.Diffs = append(report.Diffs, item)
+	}
+
+	for _, item := range runReviewFeedback {
+		switch item.Status {
+		case model.ReviewFeedbackStatusQueued:
+			report.ReviewFeedback.Queued++
+		case model.ReviewFeedbackStatusNew:
+			report.ReviewFeedback.New++
+		case model.ReviewFeedbackStatusAddressed:
+			report.ReviewFeedback.Addressed++
+		case model.ReviewFeedbackStatusIgnored:
+			report.ReviewFeedback.Ignored++
+		}
+	}
+
+	report.Steps = RunStatusSteps{Total: len(steps), Branches: summarizeStepBranches(steps), Items: steps}
+	for _, step := range steps {
+		switch step.Status {
+		case model.StepStatusDone:
+			report.Steps.Done++
+		case model.StepStatusFailed:
+			report.Steps.Failed++
+		case model.StepStatusRunning:
+			report.Steps.Running++
+		default:
+			report.Steps.Pending++
+		}
+	}
+
+	for _, agent := range agents {
+		report.Agents = append(report.Agents, RunStatusAgent{
+			Name:           agent.Name,
+			WorkspaceName:  agent.WorkspaceName,
+			SessionName:    agent.SessionName,
+			Status:         agent.Status,
+			Health:         agent.HealthState,
+			LastActivityAt: agent.LastActivityAt,
+			LastProgressAt: agent.LastProgressAt,
+			ActivityAge:    formatAgeOrDash(now, agent.LastActivityAt),
+			ProgressAge:    formatAgeOrDash(now, agent.LastProgressAt),
+			Unhealthy:      isUnhealthySnapshotAgent(agent.Status, agent.HealthState),
+		})
+	}
+
+	if record.Status == model.RunStatusComplete {
+		selector := "--run-id " + runID
+		if len(tickets) == 1 {
+			selector = "--ticket " + tickets[0]
+		}
+		report.Next = []string{
+			fmt.Sprintf("metawsm iterate %s --feedback \"<feedback from diff review>\"", selector),
+			fmt.Sprintf("metawsm merge %s --dry-run", selector),
+			fmt.Sprintf("metawsm merge %s", selector),
+			fmt.Sprintf("metawsm close %s", selector),
+		}
+	}
+	return report, nil
+}
+
+// Status returns the run's status as the human-readable text printed by
+// `metawsm status`.
+func (s *Service) Status(ctx context.Context, runID string) (string, error) {
+	report, err := s.StatusReport(ctx, runID)
+	if err != nil {
+		return "", err
+	}
+	return FormatRunStatus(report), nil
+}
+
+// Snapshot condenses the report to the fields run listings and webhooks use.
+func (r RunStatusReport) Snapshot() RunSnapshot {
+	snapshot := RunSnapshot{
+		RunID:                r.Run.RunID,
+		Status:               r.Run.Status,
+		Tickets:              r.Tickets,
+		PendingGuidance:      make([]RunGuidanceSnapshot, 0, len(r.PendingGuidance)),
+		UnhealthyAgents:      make([]RunUnhealthyAgentSnapshot, 0, len(r.Agents)),
+		HasDirtyDiffs:        r.HasDirtyDiffs(),
+		QueuedReviewFeedback: r.ReviewFeedback.Queued,
+		NewReviewFeedback:    r.ReviewFeedback.New,
+	}
+	for _, item := range r.PendingGuidance {
+		snapshot.PendingGuidance = append(snapshot.PendingGuidance, RunGuidanceSnapshot(item))
+	}
+	for _, agent := range r.Agents {
+		if !agent.Unhealthy {
+			continue
+		}
+		snapshot.UnhealthyAgents = append(snapshot.UnhealthyAgents, RunUnhealthyAgentSnapshot{
+			AgentName:     strings.TrimSpace(agent.Name),
+			WorkspaceName: strings.TrimSpace(agent.WorkspaceName),
+			SessionName:   strings.TrimSpace(agent.SessionName),
+			Status:        agent.Status,
+			Health:        agent.Health,
+			LastActivity:  formatTimeOrDash(agent.LastActivityAt),
+			LastProgress:  formatTimeOrDash(agent.LastProgressAt),
+			ActivityAge:   agent.ActivityAge,
+			ProgressAge:   agent.ProgressAge,
+		})
+	}
+	for _, item := range r.PullRequests {
+		switch strings.TrimSpace(strings.ToLower(string(item.PRState))) {
+		case "draft":
+			snapshot.DraftPullRequests++
+		case "open":
+			snapshot.OpenPullRequests++
+		}
+	}
+	return snapshot
+}
+
+// HasDirtyDiffs reports whether any readable workspace repo has uncommitted
+// changes.
+func (r RunStatusReport) HasDirtyDiffs() bool {
+	for _, diff := range r.Diffs {
+		if diff.Error != "" {
+			continue
+		}
+		for _, repo := range diff.Repos {
+			if repo.Error == "" && len(repo.Changes) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// FormatRunStatus renders report as the text printed by `metawsm status`.
+func FormatRunStatus(report RunStatusReport) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Run: %s\n", report.Run.RunID))
+	b.WriteString(fmt.Sprintf("Status: %s\n", report.Run.Status))
+	if report.Mode != "" {
+		b.WriteString(fmt.Sprintf("Mode: %s\n", report.Mode))
+	}
+	b.WriteString(fmt.Sprintf("Tickets: %s\n", strings.Join(report.Tickets, ", ")))
+	b.WriteString("Docs:\n")
+	b.WriteString(fmt.Sprintf("  home_repo=%s\n", emptyAsUnknown(report.Docs.HomeRepo)))
+	b.WriteString(fmt.Sprintf("  authority=%s\n", report.Docs.AuthorityMode))
+	b.WriteString(fmt.Sprintf("  seed_mode=%s\n", report.Docs.SeedMode))
+	b.WriteString(fmt.Sprintf("  freshness_revision=%s\n", emptyAsUnknown(report.Docs.FreshnessRevision)))
+	if len(report.Docs.Sync) == 0 {
+		b.WriteString("  sync=none\n")
+	} else {
+		for _, state := range report.Docs.Sync {
+			b.WriteString(fmt.Sprintf(
+				"  sync ticket=%s workspace=%s status=%s revision=%s updated_at=%s\n",
+				state.Ticket,
+				state.WorkspaceName,
+				state.Status,
+				emptyAsUnknown(state.Revision),
+				state.UpdatedAt.Format(time.RFC3339),
+			))
+		}
+	}
+	for _, warning := range report.Warnings {
+		b.WriteString(fmt.Sprintf("  warning=%s (warning-only)\n", warning))
+	}
+	if brief := report.Brief; brief != nil {
+		b.WriteString("Brief:\n")
+		b.WriteString(fmt.Sprintf("  goal=%s\n", brief.Goal))
+		b.WriteString(fmt.Sprintf("  scope=%s\n", brief.Scope))
+		b.WriteString(fmt.Sprintf("  done=%s\n", brief.DoneCriteria))
+		b.WriteString(fmt.Sprintf("  constraints=%s\n", brief.Constraints))
+		b.WriteString(fmt.Sprintf("  merge_intent=%s\n", brief.MergeIntent))
+	}
+	if len(report.PendingGuidance) > 0 || len(report.Escalations) > 0 {
+		b.WriteString("Guidance:\n")
+		for _, item := range report.PendingGuidance {
+			b.WriteString(fmt.Sprintf("  - forum control thread=%s agent=%s workspace=%s question=%s\n", item.ThreadID, item.AgentName, item.WorkspaceName, item.Question))
+		}
+		for _, thread := range report.Escalations {
+			b.WriteString(fmt.Sprintf("  - forum thread=%s state=%s priority=%s title=%s\n", thread.ThreadID, thread.State, thread.Priority, thread.Title))
+		}
+	}
+	if forum := report.Forum; forum.Total > 0 {
+		b.WriteString("Forum:\n")
+		b.WriteString(fmt.Sprintf("  - total=%d new=%d waiting_operator=%d waiting_human=%d answered=%d closed=%d escalations=%d\n",
+			forum.Total,
+			forum.New,
+			forum.WaitingOperator,
+			forum.WaitingHuman,
+			forum.Answered,
+			forum.Closed,
+			forum.Escalations,
+		))
+		for _, item := range forum.Recent {
+			b.WriteString(fmt.Sprintf("  - thread=%s state=%s priority=%s assignee=%s/%s posts=%d updated_at=%s title=%s\n",
+				item.ThreadID,
+				item.State,
+				item.Priority,
+				valueOrDefault(string(item.AssigneeType), "-"),
+				valueOrDefault(item.AssigneeName, "-"),
+				item.PostsCount,
+				item.UpdatedAt.Format(time.RFC3339),
+				item.Title,
+			))
+		}
+	}
+	if len(report.Diffs) > 0 {
+		b.WriteString("Diffs:\n")
+		for _, diff := range report.Diffs {
+			if diff.Error != "" {
+				b.WriteString(fmt.Sprintf("  - %s error=%s\n", diff.WorkspaceName, diff.Error))
+				continue
+			}
+			b.WriteString(fmt.Sprintf("  - %s path=%s\n", diff.WorkspaceName, diff.WorkspacePath))
+			for _, repo := range diff.Repos {
+				if repo.Error != "" {
+					b.WriteString(fmt.Sprintf("    * %s error=%s\n", repo.Repo, repo.Error))
+					continue
+				}
+				if len(repo.Changes) == 0 {
+					b.WriteString(fmt.Sprintf("    * %s clean\n", repo.Repo))
+					continue
+				}
+				b.WriteString(fmt.Sprintf("    * %s dirty files=%d\n", repo.Repo, len(repo.Changes)))
+				limit := len(repo.Changes)
+				if limit > 8 {
+					limit = 8
+				}
+				for i := 0; i < limit; i++ {
+					b.WriteString(fmt.Sprintf("      %s\n", repo.Changes[i]))
+				}
+				if len(repo.Changes) > limit {
+					b.WriteString(fmt.Sprintf("      ... (%d more)\n", len(repo.Changes)-limit))
+				}
+			}
+		}
+	}
+	if len(report.PullRequests) > 0 {
+		b.WriteString("Pull Requests:\n")
+		for _, item := range report.PullRequests {
+			b.WriteString(fmt.Sprintf("  - %s/%s state=%s head=%s base=%s number=%d url=%s actor=%s\n",
+				valueOrDefault(item.Ticket, "unknown-ticket"),
+				valueOrDefault(item.Repo, "unknown-repo"),
+				valueOrDefault(string(item.PRState), "unknown"),
+				valueOrDefault(item.HeadBranch, "-"),
+				valueOrDefault(item.BaseBranch, "-"),
+				item.PRNumber,
+				valueOrDefault(item.PRURL, "-"),
+				valueOrDefault(item.Actor, "-"),
+			))
+		}
+	}
+	if feedback := report.ReviewFeedback; feedback != (RunStatusReviewFeedback{}) {
+		b.WriteString("Review Feedback:\n")
+		b.WriteString(fmt.Sprintf("  - status=queued count=%d\n", feedback.Queued))
+		b.WriteString(fmt.Sprintf("  - status=new count=%d\n", feedback.New))
+		b.WriteString(fmt.Sprintf("  - status=addressed count=%d\n", feedback.Addressed))
+		b.WriteString(fmt.Sprintf("  - status=ignored count=%d\n", feedback.Ignored))
+	}
+	if len(report.Next) > 0 {
+		b.WriteString("Next:\n")
+		for _, command := range report.Next {
+			b.WriteString(fmt.Sprintf("  - %s\n", command))
+		}
+	}
+
+	steps := report.Steps
+	b.WriteString(fmt.Sprintf("Steps: total=%d done=%d running=%d pending=%d failed=%d\n", steps.Total, steps.Done, steps.Running, steps.Pending, steps.Failed))
+	if len(steps.Branches) > 1 {
+		b.WriteString("Branches:\n")
+		for _, branch := range steps.Branches {
+			b.WriteString(fmt.Sprintf("  - %s state=%s done=%d/%d running=%d failed=%d blocked=%d next=%s\n",
+				branch.Ticket,
+				branch.State,
+				branch.Done,
+				branch.Total,
+				branch.Running,
+				branch.Failed,
+				branch.Blocked,
+				valueOrDefault(branch.Next, "-"),
+			))
+		}
+	}
+	b.WriteString("Agents:\n")
+	if len(report.Agents) == 0 {
+		b.WriteString("  - none\n")
+	} else {
+		for _, agent := range report.Agents {
+			b.WriteString(fmt.Sprintf("  - %s@%s session=%s status=%s health=%s last_activity=%s activity_age=%s last_progress=%s progress_age=%s\n",
+				agent.Name,
+				agent.WorkspaceName,
+				agent.SessionName,
+				agent.Status,
+				agent.Health,
+				formatTimeOrDash(agent.LastActivityAt),
+				agent.ActivityAge,
+				formatTimeOrDash(agent.LastProgressAt),
+				agent.ProgressAge,
+			))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,81 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"metawsm/internal/model"
+)
+
+func TestStatusReportDrivesStatusText(t *testing.T) {
+	svc := newTestService(t)
+	result, err := svc.Run(t.Context(), RunOptions{
+		Tickets:           []string{"METAWSM-021", "METAWSM-022"},
+		Repos:             []string{"metawsm"},
+		WorkspaceStrategy: model.WorkspaceStrategyCreate,
+		DocSeedMode:       string(model.DocSeedModeNone),
+		DryRun:            true,
+	})
+	if err != nil {
+		t.Fatalf("run dry-run: %v", err)
+	}
+
+	report, err := svc.StatusReport(t.Context(), result.RunID)
+	if err != nil {
+		t.Fatalf("status report: %v", err)
+	}
+	if report.Run.RunID != result.RunID || report.Run.Status != model.RunStatusPaused {
+		t.Fatalf("unexpected run record %+v", report.Run)
+	}
+	if strings.Join(report.Tickets, ",") != "METAWSM-021,METAWSM-022" || report.Docs.HomeRepo != "metawsm" || report.Docs.SeedMode != model.DocSeedModeNone {
+		t.Fatalf("unexpected report run details %+v", report)
+	}
+	if report.Steps.Total != len(result.Steps) || report.Steps.Pending != len(result.Steps) || len(report.Steps.Items) != len(result.Steps) {
+		t.Fatalf("unexpected step counts %+v", report.Steps)
+	}
+	if len(report.Steps.Branches) != 2 || report.Steps.Branches[0].Ticket != "METAWSM-021" {
+		t.Fatalf("expected one branch per ticket, got %+v", report.Steps.Branches)
+	}
+	if len(report.Agents) != 2 || report.Agents[0].Name != "agent" || report.Agents[0].Unhealthy {
+		t.Fatalf("unexpected agents %+v", report.Agents)
+	}
+	if report.Snapshot().RunID != result.RunID || report.HasDirtyDiffs() {
+		t.Fatalf("unexpected snapshot %+v", report.Snapshot())
+	}
+
+	payload, err := json.Marshal(report)
+	if err != nil {
+		t.Fatalf("marshal report: %v", err)
+	}
+	for _, field := range []string{`"pending_guidance":[]`, `"branches":[`, `"activity_age":`, `"review_feedback":{"queued":0`} {
+		if !strings.Contains(string(payload), field) {
+			t.Fatalf("expected report JSON to contain %s, got %s", field, payload)
+		}
+	}
+
+	text := FormatRunStatus(report)
+	for _, line := range []string{
+		"Run: " + result.RunID + "\n",
+		"Status: paused\n",
+		"Tickets: METAWSM-021, METAWSM-022\n",
+		"  seed_mode=none\n",
+		"Branches:\n",
+		"Agents:\n",
+	} {
+		if !strings.Contains(text, line) {
+			t.Fatalf("expected status text to contain %q, got:\n%s", line, text)
+		}
+	}
+	if strings.Contains(text, "Review Feedback:") || strings.Contains(text, "Guidance:") {
+		t.Fatalf("expected empty sections to be omitted, got:\n%s", text)
+	}
+	status, err := svc.Status(t.Context(), result.RunID)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	// Agent ages move between calls, so compare everything before them.
+	if head := text[:strings.Index(text, "Agents:\n")]; !strings.HasPrefix(status, head) {
+		t.Fatalf("expected Status to render the report, got:\n%s\nwant prefix:\n%s", status, head)
+	}
+}
@@ -0,0 +1,98 @@
+package orchestrator
+
+import (
+	"fmt"
+	"strings"
+
+	"metawsm/internal/model"
+	"metawsm/internal/policy"
+)
+
+// applyRunTemplate expands options.Template and uses it to fill every run
+// option the caller left empty. Template agents that carry a profile are added
+// to cfg.Agents, replacing a policy agent of the same name, so the resolved
+// agent set and the stored policy snapshot agree. It returns nil when the run
+// has no template.
+func applyRunTemplate(cfg *policy.Config, options *RunOptions, tickets []string, runID string) (*policy.RunTemplate, error) {
+	name := strings.TrimSpace(options.Template)
+	if name == "" {
+		if len(options.TemplateVars) > 0 {
+			return nil, fmt.Errorf("template variables require --template")
+		}
+		return nil, nil
+	}
+	template, ok := policy.FindRunTemplate(*cfg, name)
+	if !ok {
+		return nil, fmt.Errorf("run template %q not found", name)
+	}
+	vars := map[string]string{}
+	for key, value := range options.TemplateVars {
+		vars[key] = value
+	}
+	vars[policy.RunTemplateVarTicket] = tickets[0]
+	vars[policy.RunTemplateVarTickets] = strings.Join(tickets, ",")
+	vars[policy.RunTemplateVarRunID] = runID
+	expanded, err := policy.ExpandRunTemplate(template, vars)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(normalizeTokens(options.Repos)) == 0 {
+		options.Repos = expanded.Repos
+	}
+	if strings.TrimSpace(options.DocHomeRepo) == "" && strings.TrimSpace(options.DocRepo) == "" {
+		options.DocHomeRepo = expanded.DocHomeRepo
+	}
+	if strings.TrimSpace(options.DocAuthorityMode) == "" {
+		options.DocAuthorityMode = expanded.DocAuthorityMode
+	}
+	if strings.TrimSpace(options.DocSeedMode) == "" {
+		options.DocSeedMode = expanded.DocSeedMode
+	}
+	if strings.TrimSpace(options.BaseBranch) == "" {
+		options.BaseBranch = expanded.BaseBranch
+	}
+	if options.WorkspaceStrategy == "" {
+		options.WorkspaceStrategy = model.WorkspaceStrategy(expanded.WorkspaceStrategy)
+	}
+
+	templateAgents := make([]string, 0, len(expanded.Agents))
+	for _, agent := range expanded.Agents {
+		templateAgents = append(templateAgents, agent.Name)
+		if strings.TrimSpace(agent.Profile) == "" {
+			continue
+		}
+		replaced := false
+		for i := range cfg.Agents {
+			if cfg.Agents[i].Name == agent.Name {
+				cfg.Agents[i].Profile = agent.Profile
+				replaced = true
+			}
+		}
+		if !replaced {
+			cfg.Agents = append(cfg.Agents, agent)
+		}
+	}
+	if len(normalizeTokens(options.AgentNames)) == 0 {
+		options.AgentNames = templateAgents
+	}
+
+	if expanded.Brief != nil {
+		brief := model.RunBrief{}
+		if options.RunBrief != nil {
+			brief = *options.RunBrief
+		}
+		fill := func(field *string, value string) {
+			if strings.TrimSpace(*field) == "" {
+				*field = value
+			}
+		}
+		fill(&brief.Goal, expanded.Brief.Goal)
+		fill(&brief.Scope, expanded.Brief.Scope)
+		fill(&brief.DoneCriteria, expanded.Brief.DoneCriteria)
+		fill(&brief.Constraints, expanded.Brief.Constraints)
+		fill(&brief.MergeIntent, expanded.Brief.MergeIntent)
+		options.RunBrief = &brief
+	}
+	return &expanded, nil
+}
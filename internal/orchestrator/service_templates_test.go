@@ -0,0 +1,109 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"metawsm/internal/model"
+	"metawsm/internal/policy"
+)
+
+func TestRunDryRunAppliesRunTemplate(t *testing.T) {
+	root := t.TempDir()
+	cfg := policy.Default()
+	cfg.RunTemplates = []policy.RunTemplate{{
+		Name:        "backend-bugfix",
+		Variables:   map[string]string{"service": ""},
+		Repos:       []string{"{{service}}", "shared"},
+		DocSeedMode: string(model.DocSeedModeNone),
+		BaseBranch:  "release",
+		Agents:      []policy.Agent{{Name: "fixer", Profile: "default-shell"}},
+		Steps: []model.RunTemplateStep{
+			{Name: "deps", Command: "make -C {{service}} deps TICKET={{ticket}}"},
+		},
+		Brief: &policy.RunTemplateBrief{Goal: "Fix {{ticket}}", DoneCriteria: "tests pass in {{service}}"},
+	}}
+	payload, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatalf("marshal policy: %v", err)
+	}
+	policyPath := filepath.Join(root, "policy.json")
+	if err := os.WriteFile(policyPath, payload, 0o644); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+	svc := newTestService(t)
+
+	if _, err := svc.Run(t.Context(), RunOptions{
+		Tickets:    []string{"BUG-1"},
+		PolicyPath: policyPath,
+		Template:   "backend-bugfix",
+		DryRun:     true,
+	}); err == nil || !strings.Contains(err.Error(), `requires variable "service"`) {
+		t.Fatalf("expected missing template variable error, got %v", err)
+	}
+
+	result, err := svc.Run(t.Context(), RunOptions{
+		Tickets:      []string{"BUG-1", "BUG-2"},
+		BaseBranch:   "main",
+		PolicyPath:   policyPath,
+		Template:     "backend-bugfix",
+		TemplateVars: map[string]string{"service": "api"},
+		DryRun:       true,
+		RunBrief:     &model.RunBrief{Goal: "Fix the login crash"},
+	})
+	if err != nil {
+		t.Fatalf("run dry-run: %v", err)
+	}
+
+	_, specJSON, _, err := svc.store.GetRun(result.RunID)
+	if err != nil {
+		t.Fatalf("get run: %v", err)
+	}
+	var spec model.RunSpec
+	if err := json.Unmarshal([]byte(specJSON), &spec); err != nil {
+		t.Fatalf("unmarshal stored spec: %v", err)
+	}
+	if spec.Template != "backend-bugfix" || strings.Join(spec.Repos, ",") != "api,shared" || spec.DocHomeRepo != "api" {
+		t.Fatalf("expected template run defaults, got %+v", spec)
+	}
+	if spec.BaseBranch != "main" || spec.DocSeedMode != model.DocSeedModeNone {
+		t.Fatalf("expected explicit flags to win over the template, got base=%q seed=%q", spec.BaseBranch, spec.DocSeedMode)
+	}
+	if len(spec.Agents) != 1 || spec.Agents[0].Name != "fixer" {
+		t.Fatalf("expected the template agent mix, got %+v", spec.Agents)
+	}
+
+	brief, err := svc.store.GetRunBrief(result.RunID)
+	if err != nil || brief == nil {
+		t.Fatalf("get brief: %+v (%v)", brief, err)
+	}
+	if brief.Goal != "Fix the login crash" || brief.DoneCriteria != "tests pass in api" {
+		t.Fatalf("expected the template to fill only empty brief fields, got %+v", brief)
+	}
+
+	for _, ticket := range []string{"BUG-1", "BUG-2"} {
+		workspace := workspaceNameFor(ticket, result.RunID)
+		var extra, start *model.PlanStep
+		for i := range result.Steps {
+			step := &result.Steps[i]
+			switch step.Name {
+			case "deps-" + workspace:
+				extra = step
+			case "tmux-start-fixer-" + workspace:
+				start = step
+			}
+		}
+		if extra == nil || start == nil {
+			t.Fatalf("expected template and agent steps for %s, got %+v", ticket, result.Steps)
+		}
+		if extra.Kind != "template_step" || extra.Command != "make -C 'api' deps TICKET='"+ticket+"'" {
+			t.Fatalf("unexpected template step %+v", extra)
+		}
+		if len(start.DependsOn) != 1 || start.DependsOn[0] != extra.Index {
+			t.Fatalf("expected agents to start after the template step, got %+v", start)
+		}
+	}
+}
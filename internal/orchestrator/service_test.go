@@ -1,6 +1,7 @@
 package orchestrator
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -13,6 +14,7 @@ import (
 
 	"metawsm/internal/model"
 	"metawsm/internal/policy"
+	"metawsm/internal/store"
 )
 
 func TestRunDryRunPersistsPlan(t *testing.T) {
@@ -109,6 +111,39 @@ func TestRunDryRunPersistsPlan(t *testing.T) {
 	}
 }
 
+func TestStartRunDryRunReturnsStoredPlan(t *testing.T) {
+	if _, err := exec.LookPath("sqlite3"); err != nil {
+		t.Skip("sqlite3 not available")
+	}
+
+	dbPath := filepath.Join(t.TempDir(), "metawsm.db")
+	svc, err := NewService(dbPath)
+	if err != nil {
+		t.Fatalf("new service: %v", err)
+	}
+	defer svc.Shutdown()
+
+	result, err := svc.StartRun(RunOptions{
+		Tickets:           []string{"METAWSM-001"},
+		Repos:             []string{"metawsm"},
+		WorkspaceStrategy: model.WorkspaceStrategyCreate,
+		DryRun:            true,
+	})
+	if err != nil {
+		t.Fatalf("start run dry-run: %v", err)
+	}
+	if result.RunID == "" || len(result.Steps) == 0 {
+		t.Fatalf("expected run id and plan, got %#v", result)
+	}
+	record, _, _, err := svc.store.GetRun(result.RunID)
+	if err != nil {
+		t.Fatalf("get run: %v", err)
+	}
+	if record.Status != model.RunStatusPaused {
+		t.Fatalf("expected paused run status after dry-run, got %s", record.Status)
+	}
+}
+
 func TestRunDryRunUsesExplicitDocRepoOverride(t *testing.T) {
 	if _, err := exec.LookPath("sqlite3"); err != nil {
 		t.Skip("sqlite3 not available")
@@ -528,6 +563,129 @@ func TestStatusIncludesForumEscalationGuidance(t *testing.T) {
 	}
 }
 
+func TestForumEscalateOverdueThreadsWalksEscalationChain(t *testing.T) {
+	workDir := t.TempDir()
+	t.Chdir(workDir)
+	cfg := policy.Default()
+	cfg.Forum.SLA.EscalationMinutes = 30
+	cfg.Forum.SLA.EscalationChains = []policy.ForumEscalationChain{{
+		Ticket: "METAWSM-014",
+		Assignees: []policy.ForumEscalationAssignee{
+			{Type: "operator", Name: "triage"},
+			{Type: "human", Name: "lead"},
+		},
+	}}
+	payload, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatalf("marshal policy: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(workDir, ".metawsm"), 0o755); err != nil {
+		t.Fatalf("mkdir policy dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(workDir, policy.DefaultPolicyPath), payload, 0o644); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+	svc := newTestService(t)
+
+	openWaiting := func(title string, state model.ForumThreadState) model.ForumThreadView {
+		t.Helper()
+		thread, err := svc.ForumOpenThread(t.Context(), ForumOpenThreadOptions{
+			Ticket:    "METAWSM-014",
+			Title:     title,
+			Body:      "Anyone?",
+			Priority:  model.ForumPriorityLow,
+			ActorType: model.ForumActorAgent,
+			ActorName: "agent",
+		})
+		if err != nil {
+			t.Fatalf("forum open thread: %v", err)
+		}
+		thread, err = svc.ForumChangeState(t.Context(), ForumChangeStateOptions{
+			ThreadID:  thread.ThreadID,
+			ToState:   state,
+			ActorType: model.ForumActorAgent,
+			ActorName: "agent",
+		})
+		if err != nil {
+			t.Fatalf("forum change state: %v", err)
+		}
+		return thread
+	}
+	ignored := openWaiting("Ignored question", model.ForumThreadStateWaitingOperator)
+	if _, err := svc.ForumAnswerThread(t.Context(), ForumAddPostOptions{
+		ThreadID:  openWaiting("Answered question", model.ForumThreadStateWaitingHuman).ThreadID,
+		Body:      "Done",
+		ActorType: model.ForumActorHuman,
+		ActorName: "kball",
+	}); err != nil {
+		t.Fatalf("forum answer thread: %v", err)
+	}
+
+	start := time.Now()
+	result, err := svc.ForumEscalateOverdueThreads(t.Context(), ForumEscalationPassOptions{Now: start.Add(10 * time.Minute)})
+	if err != nil {
+		t.Fatalf("escalation pass: %v", err)
+	}
+	if result.Checked != 1 || len(result.Escalated) != 0 {
+		t.Fatalf("expected nothing overdue yet, got %+v", result)
+	}
+
+	first := start.Add(31 * time.Minute)
+	result, err = svc.ForumEscalateOverdueThreads(t.Context(), ForumEscalationPassOptions{Now: first})
+	if err != nil {
+		t.Fatalf("escalation pass: %v", err)
+	}
+	if len(result.Escalated) != 1 {
+		t.Fatalf("expected one escalation, got %+v", result)
+	}
+	escalation := result.Escalated[0]
+	if escalation.ThreadID != ignored.ThreadID || escalation.Level != 1 ||
+		escalation.ToPriority != model.ForumPriorityNormal || escalation.ToAssigneeName != "triage" {
+		t.Fatalf("unexpected first escalation: %+v", escalation)
+	}
+
+	result, err = svc.ForumEscalateOverdueThreads(t.Context(), ForumEscalationPassOptions{Now: first.Add(time.Minute)})
+	if err != nil {
+		t.Fatalf("escalation pass: %v", err)
+	}
+	if len(result.Escalated) != 0 {
+		t.Fatalf("expected escalation to restart the SLA window, got %+v", result)
+	}
+
+	result, err = svc.ForumEscalateOverdueThreads(t.Context(), ForumEscalationPassOptions{Now: first.Add(31 * time.Minute)})
+	if err != nil {
+		t.Fatalf("escalation pass: %v", err)
+	}
+	if len(result.Escalated) != 1 {
+		t.Fatalf("expected second escalation, got %+v", result)
+	}
+	escalation = result.Escalated[0]
+	if escalation.Level != 2 || escalation.ToPriority != model.ForumPriorityHigh ||
+		escalation.ToAssigneeType != model.ForumActorHuman || escalation.ToAssigneeName != "lead" {
+		t.Fatalf("unexpected second escalation: %+v", escalation)
+	}
+
+	detail, err := svc.ForumGetThread(ignored.ThreadID)
+	if err != nil || detail == nil {
+		t.Fatalf("forum get thread: %v", err)
+	}
+	if detail.Thread.Priority != model.ForumPriorityHigh || detail.Thread.AssigneeName != "lead" || len(detail.Escalations) != 2 {
+		t.Fatalf("unexpected escalated thread detail: %+v", detail)
+	}
+	escalatedEvents := 0
+	for _, event := range detail.Events {
+		if event.Envelope.EventType == "forum.thread_escalated" {
+			escalatedEvents++
+		}
+	}
+	if escalatedEvents != 2 {
+		t.Fatalf("expected 2 forum.thread_escalated events, got %d", escalatedEvents)
+	}
+	if topic := forumEventTopicForType(svc.forumTopics, "forum.thread_escalated"); topic != "forum.events.thread_escalated" {
+		t.Fatalf("unexpected escalation topic %q", topic)
+	}
+}
+
 func TestRunSnapshotReturnsTypedForumGuidance(t *testing.T) {
 	if _, err := exec.LookPath("sqlite3"); err != nil {
 		t.Skip("sqlite3 not available")
@@ -775,6 +933,265 @@ func TestBuildPlanSkipsTicketContextSyncWhenSeedDisabled(t *testing.T) {
 	}
 }
 
+func TestBuildPlanDeclaresPerTicketBranchDependencies(t *testing.T) {
+	spec := model.RunSpec{
+		RunID:             "run-20260207-093102",
+		Mode:              model.RunModeStandard,
+		Tickets:           []string{"METAWSM-004", "METAWSM-005"},
+		Repos:             []string{"metawsm"},
+		DocSeedMode:       model.DocSeedModeCopyFromRepoOnStart,
+		WorkspaceStrategy: model.WorkspaceStrategyCreate,
+		Agents: []model.AgentSpec{
+			{Name: "planner", Command: "bash"},
+			{Name: "coder", Command: "bash"},
+		},
+	}
+
+	steps := buildPlan(spec, policy.Default())
+	if len(steps) != 10 {
+		t.Fatalf("expected 10 steps for two tickets with two agents, got %d", len(steps))
+	}
+	byIndex := map[int]model.PlanStep{}
+	for _, step := range steps {
+		byIndex[step.Index] = step
+	}
+	for _, step := range steps {
+		if step.Kind == "shell" && strings.HasPrefix(step.Name, "verify-doc-ticket-") {
+			if len(step.DependsOn) != 0 {
+				t.Fatalf("expected verify step %s to be a branch root, got deps %v", step.Name, step.DependsOn)
+			}
+			continue
+		}
+		if len(step.DependsOn) != 1 {
+			t.Fatalf("expected step %s to have one dependency, got %v", step.Name, step.DependsOn)
+		}
+		parent := byIndex[step.DependsOn[0]]
+		if parent.Ticket != step.Ticket {
+			t.Fatalf("expected step %s to depend on its own ticket branch, got parent %s", step.Name, parent.Name)
+		}
+		if step.Kind == "tmux_start" && parent.Kind != "ticket_context_sync" {
+			t.Fatalf("expected tmux step %s to wait for ticket context sync, got %s", step.Name, parent.Name)
+		}
+	}
+}
+
+func TestNormalizePlanDependenciesChainsLegacyLinearPlans(t *testing.T) {
+	steps := normalizePlanDependencies([]model.PlanStep{{Index: 1}, {Index: 2}, {Index: 3}})
+	if len(steps[0].DependsOn) != 0 || steps[1].DependsOn[0] != 1 || steps[2].DependsOn[0] != 2 {
+		t.Fatalf("expected legacy steps chained linearly, got %+v", steps)
+	}
+	if err := validatePlanDependencies([]model.PlanStep{{Index: 1, DependsOn: []int{9}}}); err == nil {
+		t.Fatalf("expected unknown dependency to be rejected")
+	}
+}
+
+func TestValidatePlanDependenciesRejectsCycles(t *testing.T) {
+	cycle := []model.PlanStep{
+		{Index: 1, Name: "a", DependsOn: []int{2}},
+		{Index: 2, Name: "b", DependsOn: []int{1}},
+		{Index: 3, Name: "c"},
+	}
+	err := validatePlanDependencies(cycle)
+	if err == nil || !strings.Contains(err.Error(), "cycle") || !strings.Contains(err.Error(), "1 a") || !strings.Contains(err.Error(), "2 b") || strings.Contains(err.Error(), "3 c") {
+		t.Fatalf("expected A->B->A cycle to be rejected, got %v", err)
+	}
+	diamond := []model.PlanStep{
+		{Index: 1, Name: "root"},
+		{Index: 2, Name: "left", DependsOn: []int{1}},
+		{Index: 3, Name: "right", DependsOn: []int{1, 1}},
+		{Index: 4, Name: "join", DependsOn: []int{2, 3}},
+	}
+	if err := validatePlanDependencies(diamond); err != nil {
+		t.Fatalf("expected acyclic diamond to validate, got %v", err)
+	}
+}
+
+func TestExecuteStepsRunsIndependentBranchesInParallelAndResumesPartialBranches(t *testing.T) {
+	if _, err := exec.LookPath("zsh"); err != nil {
+		t.Skip("zsh not available")
+	}
+
+	svc := newStoreOnlyService(t)
+	dir := t.TempDir()
+	marker := filepath.Join(dir, "fixed")
+	spec := model.RunSpec{RunID: "run-dag-1", Tickets: []string{"T1", "T2"}, CreatedAt: time.Now()}
+	if err := svc.store.CreateRun(spec, `{"version":1}`); err != nil {
+		t.Fatalf("create run: %v", err)
+	}
+	steps := []model.PlanStep{
+		{Index: 1, Name: "t1-slow", Kind: "shell", Command: "sleep 0.4", Blocking: true, Ticket: "T1", Status: model.StepStatusPending},
+		{Index: 2, Name: "t1-next", Kind: "shell", Command: "true", Blocking: true, Ticket: "T1", DependsOn: []int{1}, Status: model.StepStatusPending},
+		{Index: 3, Name: "t2-slow", Kind: "shell", Command: "sleep 0.6", Blocking: true, Ticket: "T2", Status: model.StepStatusPending},
+		{Index: 4, Name: "t2-gated", Kind: "shell", Command: fmt.Sprintf("test -f %s", marker), Blocking: true, Ticket: "T2", DependsOn: []int{3}, Status: model.StepStatusPending},
+		{Index: 5, Name: "t2-after", Kind: "shell", Command: "true", Blocking: true, Ticket: "T2", DependsOn: []int{4}, Status: model.StepStatusPending},
+	}
+	if err := svc.store.SaveSteps(spec.RunID, steps); err != nil {
+		t.Fatalf("save steps: %v", err)
+	}
+	cfg := policy.Default()
+	cfg.Execution.StepRetries = 0
+	cfg.Execution.MaxParallelSteps = 2
+
+	started := time.Now()
+	err := svc.executeSteps(t.Context(), spec, cfg, steps)
+	if err == nil || !strings.Contains(err.Error(), "t2-gated") {
+		t.Fatalf("expected t2-gated failure, got %v", err)
+	}
+	if elapsed := time.Since(started); elapsed > 950*time.Millisecond {
+		t.Fatalf("expected independent slow steps to overlap, took %s", elapsed)
+	}
+
+	records, err := svc.store.GetSteps(spec.RunID)
+	if err != nil {
+		t.Fatalf("get steps: %v", err)
+	}
+	wantStatus := []model.StepStatus{model.StepStatusDone, model.StepStatusDone, model.StepStatusDone, model.StepStatusFailed, model.StepStatusPending}
+	for i, record := range records {
+		if record.Status != wantStatus[i] {
+			t.Fatalf("expected step %d status %s, got %s", record.Index, wantStatus[i], record.Status)
+		}
+	}
+	if records[4].DependsOn[0] != 4 {
+		t.Fatalf("expected persisted dependencies, got %v", records[4].DependsOn)
+	}
+	branches := summarizeStepBranches(records)
+	if len(branches) != 2 || branches[0].State != "done" || branches[1].State != "failed" || branches[1].Blocked != 1 {
+		t.Fatalf("unexpected branch summary: %+v", branches)
+	}
+
+	if err := os.WriteFile(marker, []byte("ok"), 0o644); err != nil {
+		t.Fatalf("write marker: %v", err)
+	}
+	resumed := make([]model.PlanStep, 0, len(records))
+	for _, record := range records {
+		resumed = append(resumed, model.PlanStep{
+			Index: record.Index, Name: record.Name, Kind: record.Kind, Command: record.Command,
+			Blocking: record.Blocking, Ticket: record.Ticket, DependsOn: record.DependsOn, Status: record.Status,
+		})
+	}
+	if err := svc.executeSteps(t.Context(), spec, cfg, resumed); err != nil {
+		t.Fatalf("resume partial branch: %v", err)
+	}
+	records, err = svc.store.GetSteps(spec.RunID)
+	if err != nil {
+		t.Fatalf("get steps after resume: %v", err)
+	}
+	for _, record := range records {
+		if record.Status != model.StepStatusDone {
+			t.Fatalf("expected step %d done after resume, got %s", record.Index, record.Status)
+		}
+	}
+}
+
+func TestShutdownDuringRunPausesRunInsteadOfFailingIt(t *testing.T) {
+	if _, err := exec.LookPath("zsh"); err != nil {
+		t.Skip("zsh not available")
+	}
+
+	dbPath := filepath.Join(t.TempDir(), "metawsm.db")
+	sqliteStore := store.NewSQLiteStore(dbPath)
+	if err := sqliteStore.Init(); err != nil {
+		t.Fatalf("init store: %v", err)
+	}
+	svc := &Service{store: sqliteStore}
+	spec := model.RunSpec{RunID: "run-shutdown-1", Tickets: []string{"T1"}, CreatedAt: time.Now()}
+	if err := svc.store.CreateRun(spec, `{"version":1}`); err != nil {
+		t.Fatalf("create run: %v", err)
+	}
+	if err := svc.store.UpdateRunStatus(spec.RunID, model.RunStatusPlanning, ""); err != nil {
+		t.Fatalf("set planning status: %v", err)
+	}
+	steps := []model.PlanStep{
+		{Index: 1, Name: "long", Kind: "shell", Command: "sleep 30", Ticket: "T1", Status: model.StepStatusPending},
+	}
+	if err := svc.store.SaveSteps(spec.RunID, steps); err != nil {
+		t.Fatalf("save steps: %v", err)
+	}
+	cfg := policy.Default()
+	cfg.Execution.StepRetries = 2
+
+	ctx := svc.backgroundContext()
+	svc.backgroundRuns.Add(1)
+	go func() {
+		defer svc.backgroundRuns.Done()
+		_ = svc.executePlannedRun(ctx, spec, cfg, steps)
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		records, err := svc.store.GetSteps(spec.RunID)
+		if err != nil {
+			t.Fatalf("get steps: %v", err)
+		}
+		if len(records) == 1 && records[0].Status == model.StepStatusRunning {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("step never started: %+v", records)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	svc.Shutdown()
+
+	reopened := store.NewSQLiteStore(dbPath)
+	if err := reopened.Init(); err != nil {
+		t.Fatalf("reopen store: %v", err)
+	}
+	defer reopened.Close()
+	record, _, _, err := reopened.GetRun(spec.RunID)
+	if err != nil {
+		t.Fatalf("get run: %v", err)
+	}
+	if record.Status != model.RunStatusPaused {
+		t.Fatalf("expected shutdown to pause the run, got %s", record.Status)
+	}
+	events, err := reopened.ListRunEvents(spec.RunID, "run", 1)
+	if err != nil || len(events) != 1 || events[0].ToState != string(model.RunStatusPaused) || !strings.Contains(events[0].Message, "daemon shutdown") {
+		t.Fatalf("expected a daemon shutdown transition, got %+v (%v)", events, err)
+	}
+	records, err := reopened.GetSteps(spec.RunID)
+	if err != nil || len(records) != 1 || records[0].Status != model.StepStatusFailed {
+		t.Fatalf("expected the interrupted step left for resume, got %+v (%v)", records, err)
+	}
+}
+
+func TestExecutePlannedRunPausesWhenBackgroundContextIsCancelled(t *testing.T) {
+	svc := newStoreOnlyService(t)
+	spec := model.RunSpec{RunID: "run-shutdown-2", Tickets: []string{"T1"}, CreatedAt: time.Now()}
+	if err := svc.store.CreateRun(spec, `{"version":1}`); err != nil {
+		t.Fatalf("create run: %v", err)
+	}
+	if err := svc.store.UpdateRunStatus(spec.RunID, model.RunStatusPlanning, ""); err != nil {
+		t.Fatalf("set planning status: %v", err)
+	}
+	steps := []model.PlanStep{{Index: 1, Name: "never", Kind: "shell", Command: "true", Ticket: "T1", Status: model.StepStatusPending}}
+	if err := svc.store.SaveSteps(spec.RunID, steps); err != nil {
+		t.Fatalf("save steps: %v", err)
+	}
+
+	ctx := svc.backgroundContext()
+	svc.backgroundCancel()
+	if err := svc.executePlannedRun(ctx, spec, policy.Default(), steps); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected cancelled execution, got %v", err)
+	}
+	record, _, _, err := svc.store.GetRun(spec.RunID)
+	if err != nil {
+		t.Fatalf("get run: %v", err)
+	}
+	if record.Status != model.RunStatusPaused {
+		t.Fatalf("expected cancelled run to be paused, got %s", record.Status)
+	}
+}
+
+func newStoreOnlyService(t *testing.T) *Service {
+	t.Helper()
+	sqliteStore := store.NewSQLiteStore(filepath.Join(t.TempDir(), "metawsm.db"))
+	if err := sqliteStore.Init(); err != nil {
+		t.Fatalf("init store: %v", err)
+	}
+	t.Cleanup(func() { _ = sqliteStore.Close() })
+	return &Service{store: sqliteStore}
+}
+
 func TestParseDocmgrTicketListPaths(t *testing.T) {
 	output := []byte("" +
 		"Docs root: `/tmp/metawsm/ttmp`\n" +
@@ -0,0 +1,304 @@
+package orchestrator
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"metawsm/internal/model"
+	"metawsm/internal/policy"
+)
+
+const (
+	defaultTranscriptMaxBytes  int64 = 10 * 1024 * 1024
+	defaultTranscriptReadBytes int64 = 64 * 1024
+)
+
+var transcriptNameRegex = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// AgentTranscriptReadOptions selects a slice of an agent transcript. A negative
+// Offset reads the last Limit bytes. Generation is the chunk generation the
+// Offset came from; when the transcript has rotated since, reading restarts
+// at 0 with Reset set. A negative Generation skips that check, leaving only an
+// Offset beyond the current file size to reveal a rotation.
+type AgentTranscriptReadOptions struct {
+	RunID         string
+	AgentName     string
+	WorkspaceName string
+	Offset        int64
+	Limit         int64
+	Generation    int
+}
+
+type AgentTranscriptChunk struct {
+	RunID         string `json:"run_id"`
+	AgentName     string `json:"agent_name"`
+	WorkspaceName string `json:"workspace_name"`
+	Path          string `json:"path"`
+	Offset        int64  `json:"offset"`
+	NextOffset    int64  `json:"next_offset"`
+	Size          int64  `json:"size"`
+	Reset         bool   `json:"reset"`
+	// Generation counts the transcript's rotations; pass it back with
+	// NextOffset so a rotation is detected even after the file regrows.
+	Generation int    `json:"generation"`
+	Data       string `json:"data"`
+}
+
+func (s *Service) transcriptsDir() string {
+	return filepath.Join(filepath.Dir(s.runtimeStateDir()), "runs")
+}
+
+// agentTranscriptPath is .metawsm/runs/<run>/<agent>-<workspace>.log next to the store.
+func (s *Service) agentTranscriptPath(runID string, agentName string, workspaceName string) string {
+	name := transcriptNameRegex.ReplaceAllString(agentName+"-"+workspaceName, "_")
+	return filepath.Join(s.transcriptsDir(), transcriptNameRegex.ReplaceAllString(runID, "_"), name+".log")
+}
+
+// prepareAgentTranscript creates the transcript directory and indexes the file
+// before the runtime starts appending to it.
+func (s *Service) prepareAgentTranscript(runID string, agentName string, workspaceName string, rt AgentRuntime) (string, error) {
+	path := s.agentTranscriptPath(runID, agentName, workspaceName)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("create transcript dir: %w", err)
+	}
+	now := time.Now()
+	transcript := model.AgentTranscript{
+		RunID:         runID,
+		AgentName:     agentName,
+		WorkspaceName: workspaceName,
+		Path:          path,
+		Runtime:       rt.Name(),
+		StartedAt:     &now,
+		UpdatedAt:     &now,
+	}
+	if existing, ok := s.findAgentTranscript(runID, agentName, workspaceName); ok {
+		transcript.Rotations = existing.Rotations
+	}
+	if info, err := os.Stat(path); err == nil {
+		transcript.SizeBytes = info.Size()
+	}
+	if err := s.store.UpsertAgentTranscript(transcript); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+func (s *Service) findAgentTranscript(runID string, agentName string, workspaceName string) (model.AgentTranscript, bool) {
+	transcripts, err := s.store.ListAgentTranscripts(runID)
+	if err != nil {
+		return model.AgentTranscript{}, false
+	}
+	for _, transcript := range transcripts {
+		if transcript.AgentName == agentName && transcript.WorkspaceName == workspaceName {
+			return transcript, true
+		}
+	}
+	return model.AgentTranscript{}, false
+}
+
+// AgentTranscripts lists indexed transcripts for a run, optionally for one agent.
+func (s *Service) AgentTranscripts(runID string, agentName string) ([]model.AgentTranscript, error) {
+	transcripts, err := s.store.ListAgentTranscripts(runID)
+	if err != nil {
+		return nil, err
+	}
+	agentName = strings.TrimSpace(agentName)
+	if agentName == "" {
+		return transcripts, nil
+	}
+	out := make([]model.AgentTranscript, 0, len(transcripts))
+	for _, transcript := range transcripts {
+		if transcript.AgentName == agentName {
+			out = append(out, transcript)
+		}
+	}
+	return out, nil
+}
+
+func (s *Service) resolveAgentTranscript(runID string, agentName string, workspaceName string) (model.AgentTranscript, error) {
+	agentName = strings.TrimSpace(agentName)
+	if agentName == "" {
+		return model.AgentTranscript{}, fmt.Errorf("agent name is required")
+	}
+	transcripts, err := s.AgentTranscripts(runID, agentName)
+	if err != nil {
+		return model.AgentTranscript{}, err
+	}
+	workspaceName = strings.TrimSpace(workspaceName)
+	matches := make([]model.AgentTranscript, 0, len(transcripts))
+	for _, transcript := range transcripts {
+		if workspaceName == "" || transcript.WorkspaceName == workspaceName {
+			matches = append(matches, transcript)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return model.AgentTranscript{}, fmt.Errorf("no transcript recorded for agent %s in run %s", agentName, runID)
+	case 1:
+		return matches[0], nil
+	default:
+		workspaces := make([]string, 0, len(matches))
+		for _, match := range matches {
+			workspaces = append(workspaces, match.WorkspaceName)
+		}
+		return model.AgentTranscript{}, fmt.Errorf("agent %s has transcripts in several workspaces (%s); select one with a workspace", agentName, strings.Join(workspaces, ", "))
+	}
+}
+
+// ReadAgentTranscript returns up to Limit bytes of an agent transcript.
+func (s *Service) ReadAgentTranscript(options AgentTranscriptReadOptions) (AgentTranscriptChunk, error) {
+	runID := strings.TrimSpace(options.RunID)
+	if runID == "" {
+		return AgentTranscriptChunk{}, fmt.Errorf("run id is required")
+	}
+	transcript, err := s.resolveAgentTranscript(runID, options.AgentName, options.WorkspaceName)
+	if err != nil {
+		return AgentTranscriptChunk{}, err
+	}
+	offset := options.Offset
+	rotated := offset > 0 && options.Generation >= 0 && options.Generation != transcript.Rotations
+	if rotated {
+		offset = 0
+	}
+	chunk, err := readTranscriptChunk(transcript.Path, offset, options.Limit)
+	if err != nil {
+		return AgentTranscriptChunk{}, err
+	}
+	chunk.Reset = chunk.Reset || rotated
+	chunk.Generation = transcript.Rotations
+	chunk.RunID = transcript.RunID
+	chunk.AgentName = transcript.AgentName
+	chunk.WorkspaceName = transcript.WorkspaceName
+	return chunk, nil
+}
+
+func readTranscriptChunk(path string, offset int64, limit int64) (AgentTranscriptChunk, error) {
+	if limit <= 0 {
+		limit = defaultTranscriptReadBytes
+	}
+	chunk := AgentTranscriptChunk{Path: path}
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return chunk, nil
+	}
+	if err != nil {
+		return chunk, fmt.Errorf("open transcript: %w", err)
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return chunk, err
+	}
+	chunk.Size = info.Size()
+	switch {
+	case offset < 0:
+		offset = chunk.Size - limit
+		if offset < 0 {
+			offset = 0
+		}
+	case offset > chunk.Size:
+		offset = 0
+		chunk.Reset = true
+	}
+	chunk.Offset = offset
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return chunk, err
+	}
+	data, err := io.ReadAll(io.LimitReader(file, limit))
+	if err != nil {
+		return chunk, fmt.Errorf("read transcript: %w", err)
+	}
+	chunk.Data = string(data)
+	chunk.NextOffset = offset + int64(len(data))
+	return chunk, nil
+}
+
+// RotateAgentTranscripts applies transcript rotation to every run that is not
+// closed and returns how many transcripts rotated. The serve worker calls it
+// on a timer so capture stays within transcripts.max_bytes between status
+// reads.
+func (s *Service) RotateAgentTranscripts() (int, error) {
+	runs, err := s.store.ListRuns()
+	if err != nil {
+		return 0, err
+	}
+	cfg := loadPolicyOrDefault()
+	rotated := 0
+	for _, run := range runs {
+		if run.Status == model.RunStatusClosed {
+			continue
+		}
+		rotated += s.refreshAgentTranscripts(run.RunID, cfg)
+	}
+	return rotated, nil
+}
+
+// refreshAgentTranscripts rotates oversized transcripts, records their
+// current size and returns how many rotated. Rotation copies then truncates
+// in place because the runtime keeps the file open for appending, so the
+// inode never changes; readers detect it through the rotation count.
+func (s *Service) refreshAgentTranscripts(runID string, cfg policy.Config) int {
+	s.transcriptMu.Lock()
+	defer s.transcriptMu.Unlock()
+	transcripts, err := s.store.ListAgentTranscripts(runID)
+	if err != nil {
+		return 0
+	}
+	rotations := 0
+	maxBytes := cfg.Transcripts.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = defaultTranscriptMaxBytes
+	}
+	for _, transcript := range transcripts {
+		info, err := os.Stat(transcript.Path)
+		if err != nil {
+			continue
+		}
+		size := info.Size()
+		rotated := false
+		if size > maxBytes {
+			if err := rotateTranscript(transcript.Path, cfg.Transcripts.Keep); err != nil {
+				continue
+			}
+			rotated = true
+			size = 0
+		}
+		if !rotated && size == transcript.SizeBytes {
+			continue
+		}
+		now := time.Now()
+		transcript.SizeBytes = size
+		transcript.UpdatedAt = &now
+		if rotated {
+			transcript.Rotations++
+			rotations++
+		}
+		_ = s.store.UpsertAgentTranscript(transcript)
+	}
+	return rotations
+}
+
+// rotateTranscript shifts path.N-1 to path.N, copies the live file to path.1 and
+// truncates it. With keep=0 the live file is truncated without a backup.
+func rotateTranscript(path string, keep int) error {
+	if keep > 0 {
+		_ = os.Remove(fmt.Sprintf("%s.%d", path, keep))
+		for i := keep - 1; i >= 1; i-- {
+			from := fmt.Sprintf("%s.%d", path, i)
+			if _, err := os.Stat(from); err == nil {
+				if err := os.Rename(from, fmt.Sprintf("%s.%d", path, i+1)); err != nil {
+					return err
+				}
+			}
+		}
+		if err := copyFile(path, path+".1", 0o644); err != nil {
+			return err
+		}
+	}
+	return os.Truncate(path, 0)
+}
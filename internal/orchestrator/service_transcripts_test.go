@@ -0,0 +1,127 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"metawsm/internal/model"
+	"metawsm/internal/policy"
+)
+
+func TestReadTranscriptChunkTailsAndResetsAfterRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agent-ws.log")
+	if err := os.WriteFile(path, []byte("0123456789"), 0o644); err != nil {
+		t.Fatalf("write transcript: %v", err)
+	}
+
+	chunk, err := readTranscriptChunk(path, -1, 4)
+	if err != nil {
+		t.Fatalf("tail read: %v", err)
+	}
+	if chunk.Data != "6789" || chunk.Offset != 6 || chunk.NextOffset != 10 {
+		t.Fatalf("unexpected tail chunk: %#v", chunk)
+	}
+
+	if err := rotateTranscript(path, 2); err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+	if err := os.WriteFile(path, []byte("new"), 0o644); err != nil {
+		t.Fatalf("rewrite transcript: %v", err)
+	}
+	chunk, err = readTranscriptChunk(path, chunk.NextOffset, 0)
+	if err != nil {
+		t.Fatalf("read after rotation: %v", err)
+	}
+	if !chunk.Reset || chunk.Data != "new" || chunk.NextOffset != 3 {
+		t.Fatalf("expected reset read from start, got %#v", chunk)
+	}
+}
+
+func TestRotateTranscriptKeepsBoundedBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agent-ws.log")
+	for _, content := range []string{"first", "second", "third"} {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write transcript: %v", err)
+		}
+		if err := rotateTranscript(path, 2); err != nil {
+			t.Fatalf("rotate: %v", err)
+		}
+	}
+	for suffix, want := range map[string]string{".1": "third", ".2": "second"} {
+		data, err := os.ReadFile(path + suffix)
+		if err != nil {
+			t.Fatalf("read backup %s: %v", suffix, err)
+		}
+		if string(data) != want {
+			t.Fatalf("expected %s to hold %q, got %q", suffix, want, string(data))
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Fatalf("expected no third backup, got %v", err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Size() != 0 {
+		t.Fatalf("expected live transcript truncated, got %v", err)
+	}
+}
+
+func TestRotateAgentTranscriptsResetsReadersAfterRegrowth(t *testing.T) {
+	workDir := t.TempDir()
+	t.Chdir(workDir)
+	cfg := policy.Default()
+	cfg.Transcripts.MaxBytes = 8
+	cfg.Transcripts.Keep = 1
+	payload, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatalf("marshal policy: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(workDir, ".metawsm"), 0o755); err != nil {
+		t.Fatalf("mkdir policy dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(workDir, policy.DefaultPolicyPath), payload, 0o644); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+	svc := newTestService(t)
+	createRunWithTicketFixture(t, svc, "run-transcripts", "METAWSM-005", "ws-1", model.RunStatusRunning, false)
+	path := filepath.Join(workDir, "agent-ws-1.log")
+	if err := svc.store.UpsertAgentTranscript(model.AgentTranscript{
+		RunID:         "run-transcripts",
+		AgentName:     "agent",
+		WorkspaceName: "ws-1",
+		Path:          path,
+		Runtime:       "tmux",
+	}); err != nil {
+		t.Fatalf("index transcript: %v", err)
+	}
+	if err := os.WriteFile(path, []byte("0123456789abcdef"), 0o644); err != nil {
+		t.Fatalf("write transcript: %v", err)
+	}
+
+	options := AgentTranscriptReadOptions{RunID: "run-transcripts", AgentName: "agent", Generation: -1}
+	first, err := svc.ReadAgentTranscript(options)
+	if err != nil {
+		t.Fatalf("read transcript: %v", err)
+	}
+	if first.NextOffset != 16 || first.Generation != 0 {
+		t.Fatalf("unexpected first chunk: %#v", first)
+	}
+
+	rotated, err := svc.RotateAgentTranscripts()
+	if err != nil || rotated != 1 {
+		t.Fatalf("expected one rotation, got %d (%v)", rotated, err)
+	}
+	// The live file regrows past the reader's offset before its next read.
+	if err := os.WriteFile(path, []byte("after rotation, longer"), 0o644); err != nil {
+		t.Fatalf("regrow transcript: %v", err)
+	}
+	options.Offset = first.NextOffset
+	options.Generation = first.Generation
+	next, err := svc.ReadAgentTranscript(options)
+	if err != nil {
+		t.Fatalf("read after rotation: %v", err)
+	}
+	if !next.Reset || next.Offset != 0 || next.Data != "after rotation, longer" || next.Generation != 1 {
+		t.Fatalf("expected reset read of the new generation, got %#v", next)
+	}
+}
@@ -0,0 +1,268 @@
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"metawsm/internal/model"
+	"metawsm/internal/policy"
+	"metawsm/internal/webhook"
+)
+
+// EnqueueWebhookEvent queues one outbox delivery per configured endpoint that
+// matches event and returns how many were queued. Forum events are tagged
+// with their thread's current priority so endpoints can filter on it.
+func (s *Service) EnqueueWebhookEvent(ctx context.Context, event model.WebhookEvent) (int, error) {
+	_ = ctx
+	if s.forumBus == nil {
+		return 0, fmt.Errorf("forum bus runtime not configured")
+	}
+	cfg, _, err := policy.Load("")
+	if err != nil {
+		return 0, err
+	}
+	return s.enqueueWebhookEvent(cfg, event)
+}
+
+func (s *Service) enqueueWebhookEvent(cfg policy.Config, event model.WebhookEvent) (int, error) {
+	if len(cfg.Webhooks.Endpoints) == 0 {
+		return 0, nil
+	}
+	if strings.TrimSpace(event.EventID) == "" {
+		return 0, fmt.Errorf("webhook event_id is required")
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+	if event.Priority == "" && strings.TrimSpace(event.ThreadID) != "" {
+		thread, err := s.store.GetForumThread(event.ThreadID)
+		if err != nil {
+			return 0, err
+		}
+		if thread != nil {
+			event.Priority = thread.Priority
+		}
+	}
+	queued := 0
+	for _, endpoint := range cfg.Webhooks.Endpoints {
+		if !webhook.Matches(endpoint, event) {
+			continue
+		}
+		delivery := model.WebhookDelivery{Endpoint: strings.TrimSpace(endpoint.Name), Event: event}
+		if _, err := s.forumBus.Publish(s.forumTopics.IntegrationTopic("webhook"), delivery.Endpoint, delivery); err != nil {
+			return queued, err
+		}
+		queued++
+	}
+	return queued, nil
+}
+
+const (
+	runStatusWebhookCursor  = "webhooks.run_status"
+	forumEventWebhookCursor = "webhooks.forum_events"
+	webhookCursorPageSize   = 100
+)
+
+type RunStatusWebhookResult struct {
+	Transitions int `json:"transitions"`
+	Enqueued    int `json:"enqueued"`
+}
+
+type ForumEventWebhookResult struct {
+	Events   int `json:"events"`
+	Enqueued int `json:"enqueued"`
+}
+
+// EnqueueRunStatusWebhooks turns run transitions recorded since the last call
+// into run.status_changed webhooks. A stored cursor over the events table
+// survives restarts; the first call only moves it to the newest event so a
+// fresh daemon does not replay history. Event IDs come from the transition,
+// so an event re-queued after a crash keeps its ID.
+func (s *Service) EnqueueRunStatusWebhooks(ctx context.Context) (RunStatusWebhookResult, error) {
+	result := RunStatusWebhookResult{}
+	if s.forumBus == nil {
+		return result, fmt.Errorf("forum bus runtime not configured")
+	}
+	cursor, ok, err := s.store.GetNotifierCursor(runStatusWebhookCursor)
+	if err != nil {
+		return result, err
+	}
+	if !ok {
+		lastID, err := s.store.LastEventID()
+		if err != nil {
+			return result, err
+		}
+		return result, s.store.SetNotifierCursor(runStatusWebhookCursor, lastID)
+	}
+	cfg, _, err := policy.Load("")
+	if err != nil {
+		return result, err
+	}
+	for {
+		transitions, err := s.store.ListRunTransitionsAfter(cursor, webhookCursorPageSize)
+		if err != nil {
+			return result, err
+		}
+		for _, transition := range transitions {
+			if err := ctx.Err(); err != nil {
+				return result, err
+			}
+			if len(cfg.Webhooks.Endpoints) > 0 {
+				event, err := s.runStatusWebhookEvent(transition)
+				if err != nil {
+					return result, err
+				}
+				queued, err := s.enqueueWebhookEvent(cfg, event)
+				if err != nil {
+					return result, err
+				}
+				result.Enqueued += queued
+			}
+			cursor = transition.ID
+			if err := s.store.SetNotifierCursor(runStatusWebhookCursor, cursor); err != nil {
+				return result, err
+			}
+			result.Transitions++
+		}
+		if len(transitions) < webhookCursorPageSize {
+			return result, nil
+		}
+	}
+}
+
+// EnqueueForumEventWebhooks queues webhooks for forum events stored since the
+// last call, read from forum_events by sequence behind a stored cursor, so
+// events written while no daemon ran are still delivered. Like run status
+// webhooks, the first call only moves the cursor to the newest event.
+// Integration bookkeeping events are not forwarded.
+func (s *Service) EnqueueForumEventWebhooks(ctx context.Context) (ForumEventWebhookResult, error) {
+	result := ForumEventWebhookResult{}
+	if s.forumBus == nil {
+		return result, fmt.Errorf("forum bus runtime not configured")
+	}
+	cursor, ok, err := s.store.GetNotifierCursor(forumEventWebhookCursor)
+	if err != nil {
+		return result, err
+	}
+	if !ok {
+		lastSequence, err := s.store.LastForumEventSequence()
+		if err != nil {
+			return result, err
+		}
+		return result, s.store.SetNotifierCursor(forumEventWebhookCursor, lastSequence)
+	}
+	cfg, _, err := policy.Load("")
+	if err != nil {
+		return result, err
+	}
+	integrationPrefix := strings.TrimSpace(s.forumTopics.IntegrationPrefix) + "."
+	for {
+		events, err := s.store.WatchForumEvents("", cursor, webhookCursorPageSize)
+		if err != nil {
+			return result, err
+		}
+		for _, event := range events {
+			if err := ctx.Err(); err != nil {
+				return result, err
+			}
+			if len(cfg.Webhooks.Endpoints) > 0 && !strings.HasPrefix(event.Envelope.EventType, integrationPrefix) {
+				queued, err := s.enqueueWebhookEvent(cfg, forumWebhookEvent(event))
+				if err != nil {
+					return result, err
+				}
+				result.Enqueued += queued
+			}
+			cursor = event.Sequence
+			if err := s.store.SetNotifierCursor(forumEventWebhookCursor, cursor); err != nil {
+				return result, err
+			}
+			result.Events++
+		}
+		if len(events) < webhookCursorPageSize {
+			return result, nil
+		}
+	}
+}
+
+func forumWebhookEvent(event model.ForumEvent) model.WebhookEvent {
+	webhookEvent := model.WebhookEvent{
+		EventID:    event.Envelope.EventID,
+		EventType:  event.Envelope.EventType,
+		OccurredAt: event.Envelope.OccurredAt,
+		RunID:      event.Envelope.RunID,
+		ThreadID:   event.Envelope.ThreadID,
+		ForumEvent: &event,
+	}
+	if ticket := strings.TrimSpace(event.Envelope.Ticket); ticket != "" {
+		webhookEvent.Tickets = []string{ticket}
+	}
+	return webhookEvent
+}
+
+func (s *Service) runStatusWebhookEvent(transition model.RunEvent) (model.WebhookEvent, error) {
+	tickets, err := s.store.GetTickets(transition.RunID)
+	if err != nil {
+		return model.WebhookEvent{}, err
+	}
+	agents, err := s.store.GetAgents(transition.RunID)
+	if err != nil {
+		return model.WebhookEvent{}, err
+	}
+	controlStates, err := s.forumControlStatesForRun(transition.RunID, agents)
+	if err != nil {
+		return model.WebhookEvent{}, err
+	}
+	guidance := []string{}
+	for _, agent := range agents {
+		state, ok := controlStates[agent.Name]
+		if !ok || !state.PendingGuidance {
+			continue
+		}
+		guidance = append(guidance, strings.TrimSpace(state.PendingGuidanceQuestion))
+	}
+	return model.WebhookEvent{
+		EventID:           fmt.Sprintf("run-%s-transition-%d", transition.RunID, transition.ID),
+		EventType:         model.WebhookEventRunStatusChanged,
+		OccurredAt:        transition.CreatedAt,
+		Tickets:           tickets,
+		RunID:             transition.RunID,
+		RunStatus:         model.RunStatus(transition.ToState),
+		PreviousRunStatus: model.RunStatus(transition.FromState),
+		Guidance:          guidance,
+	}, nil
+}
+
+// deliverWebhook is the outbox handler for webhook deliveries. Returning an
+// error leaves the message to the outbox retry and dead-letter policy.
+func (s *Service) deliverWebhook(ctx context.Context, message model.ForumOutboxMessage) error {
+	var delivery model.WebhookDelivery
+	if err := json.Unmarshal([]byte(message.PayloadJSON), &delivery); err != nil {
+		return fmt.Errorf("decode webhook delivery: %w", err)
+	}
+	cfg, _, err := policy.Load("")
+	if err != nil {
+		return err
+	}
+	var endpoint *policy.WebhookEndpoint
+	for i := range cfg.Webhooks.Endpoints {
+		if strings.TrimSpace(cfg.Webhooks.Endpoints[i].Name) == delivery.Endpoint {
+			endpoint = &cfg.Webhooks.Endpoints[i]
+			break
+		}
+	}
+	if endpoint == nil {
+		// The endpoint was removed from policy after the event was queued.
+		return nil
+	}
+	secret := os.Getenv(strings.TrimSpace(endpoint.SecretEnv))
+	if secret == "" {
+		return fmt.Errorf("webhook endpoint %s secret env %s is not set", endpoint.Name, endpoint.SecretEnv)
+	}
+	client := &http.Client{Timeout: time.Duration(cfg.Webhooks.TimeoutSeconds) * time.Second}
+	return webhook.Deliver(ctx, client, *endpoint, secret, message.MessageID, delivery.Event, time.Now())
+}
@@ -0,0 +1,297 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"metawsm/internal/model"
+	"metawsm/internal/policy"
+	"metawsm/internal/webhook"
+)
+
+func TestEnqueueWebhookEventDeliversSignedPayloadThroughOutbox(t *testing.T) {
+	type received struct {
+		event     string
+		signature string
+		timestamp string
+		body      []byte
+	}
+	deliveries := make(chan received, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		deliveries <- received{
+			event:     r.Header.Get(webhook.EventHeader),
+			signature: r.Header.Get(webhook.SignatureHeader),
+			timestamp: r.Header.Get(webhook.TimestampHeader),
+			body:      body,
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	workDir := t.TempDir()
+	t.Chdir(workDir)
+	t.Setenv("METAWSM_TEST_WEBHOOK_SECRET", "s3cret")
+	cfg := policy.Default()
+	cfg.Webhooks.Endpoints = []policy.WebhookEndpoint{
+		{Name: "relay", URL: server.URL, SecretEnv: "METAWSM_TEST_WEBHOOK_SECRET", RunStatuses: []string{"failed"}},
+		{Name: "other-ticket", URL: server.URL, SecretEnv: "METAWSM_TEST_WEBHOOK_SECRET", Tickets: []string{"OTHER-1"}},
+	}
+	payload, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatalf("marshal policy: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(workDir, ".metawsm"), 0o755); err != nil {
+		t.Fatalf("mkdir policy dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(workDir, policy.DefaultPolicyPath), payload, 0o644); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+	svc := newTestService(t)
+
+	queued, err := svc.EnqueueWebhookEvent(t.Context(), model.WebhookEvent{
+		EventID:   "run-1-running",
+		EventType: model.WebhookEventRunStatusChanged,
+		Tickets:   []string{"METAWSM-015"},
+		RunID:     "run-1",
+		RunStatus: model.RunStatusRunning,
+	})
+	if err != nil {
+		t.Fatalf("enqueue filtered webhook: %v", err)
+	}
+	if queued != 0 {
+		t.Fatalf("expected run status filter to skip running status, queued %d", queued)
+	}
+
+	queued, err = svc.EnqueueWebhookEvent(t.Context(), model.WebhookEvent{
+		EventID:           "run-1-failed",
+		EventType:         model.WebhookEventRunStatusChanged,
+		Tickets:           []string{"METAWSM-015"},
+		RunID:             "run-1",
+		RunStatus:         model.RunStatusFailed,
+		PreviousRunStatus: model.RunStatusRunning,
+	})
+	if err != nil {
+		t.Fatalf("enqueue webhook: %v", err)
+	}
+	if queued != 1 {
+		t.Fatalf("expected one matching endpoint, queued %d", queued)
+	}
+
+	if _, err := svc.ProcessForumBusOnce(t.Context(), 10); err != nil {
+		t.Fatalf("process forum bus: %v", err)
+	}
+	select {
+	case got := <-deliveries:
+		if got.event != model.WebhookEventRunStatusChanged {
+			t.Fatalf("unexpected event header %q", got.event)
+		}
+		if !webhook.Verify("s3cret", got.timestamp, got.body, got.signature) {
+			t.Fatalf("signature did not verify: %q", got.signature)
+		}
+		var event model.WebhookEvent
+		if err := json.Unmarshal(got.body, &event); err != nil {
+			t.Fatalf("decode delivered event: %v", err)
+		}
+		if event.RunID != "run-1" || event.RunStatus != model.RunStatusFailed {
+			t.Fatalf("unexpected delivered event: %+v", event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for webhook delivery")
+	}
+
+	sent, err := svc.store.ListForumOutboxByStatus(model.ForumOutboxStatusSent, 50)
+	if err != nil {
+		t.Fatalf("list sent outbox: %v", err)
+	}
+	found := false
+	for _, message := range sent {
+		if message.Topic == svc.forumTopics.IntegrationTopic("webhook") && message.MessageKey == "relay" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected webhook delivery to be marked sent in the outbox")
+	}
+}
+
+func TestEnqueueRunStatusWebhooksFollowsRecordedTransitions(t *testing.T) {
+	workDir := t.TempDir()
+	t.Chdir(workDir)
+	cfg := policy.Default()
+	cfg.Webhooks.Endpoints = []policy.WebhookEndpoint{
+		{Name: "relay", URL: "http://127.0.0.1:1/hook", SecretEnv: "METAWSM_TEST_WEBHOOK_SECRET"},
+	}
+	payload, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatalf("marshal policy: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(workDir, ".metawsm"), 0o755); err != nil {
+		t.Fatalf("mkdir policy dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(workDir, policy.DefaultPolicyPath), payload, 0o644); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+	svc := newTestService(t)
+
+	spec := model.RunSpec{
+		RunID:             "run-webhooks",
+		Mode:              model.RunModeBootstrap,
+		Tickets:           []string{"METAWSM-015"},
+		Repos:             []string{"metawsm"},
+		WorkspaceStrategy: model.WorkspaceStrategyCreate,
+		Agents:            []model.AgentSpec{{Name: "agent", Command: "bash"}},
+		PolicyPath:        ".metawsm/policy.json",
+		CreatedAt:         time.Now(),
+	}
+	if err := svc.store.CreateRun(spec, `{"version":1}`); err != nil {
+		t.Fatalf("create run: %v", err)
+	}
+	if err := svc.transitionRun(spec.RunID, model.RunStatusCreated, model.RunStatusPlanning, "planning"); err != nil {
+		t.Fatalf("transition to planning: %v", err)
+	}
+
+	seeded, err := svc.EnqueueRunStatusWebhooks(t.Context())
+	if err != nil {
+		t.Fatalf("seed run status webhooks: %v", err)
+	}
+	if seeded.Transitions != 0 {
+		t.Fatalf("expected the first pass to only seed the cursor, got %+v", seeded)
+	}
+
+	if err := svc.transitionRun(spec.RunID, model.RunStatusPlanning, model.RunStatusRunning, "running"); err != nil {
+		t.Fatalf("transition to running: %v", err)
+	}
+	result, err := svc.EnqueueRunStatusWebhooks(t.Context())
+	if err != nil {
+		t.Fatalf("enqueue run status webhooks: %v", err)
+	}
+	if result.Transitions != 1 || result.Enqueued != 1 {
+		t.Fatalf("expected one transition queued once, got %+v", result)
+	}
+	again, err := svc.EnqueueRunStatusWebhooks(t.Context())
+	if err != nil {
+		t.Fatalf("repeat run status webhooks: %v", err)
+	}
+	if again.Transitions != 0 {
+		t.Fatalf("expected no transitions after the cursor advanced, got %+v", again)
+	}
+
+	events, err := svc.store.ListRunEvents(spec.RunID, "run", 1)
+	if err != nil || len(events) != 1 {
+		t.Fatalf("load latest transition: %+v (%v)", events, err)
+	}
+	pending, err := svc.store.ListForumOutboxByStatus(model.ForumOutboxStatusPending, 50)
+	if err != nil {
+		t.Fatalf("list pending outbox: %v", err)
+	}
+	deliveries := []model.WebhookDelivery{}
+	for _, message := range pending {
+		if message.Topic != svc.forumTopics.IntegrationTopic("webhook") {
+			continue
+		}
+		var delivery model.WebhookDelivery
+		if err := json.Unmarshal([]byte(message.PayloadJSON), &delivery); err != nil {
+			t.Fatalf("decode webhook delivery: %v", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	if len(deliveries) != 1 {
+		t.Fatalf("expected one queued webhook delivery, got %+v", deliveries)
+	}
+	event := deliveries[0].Event
+	wantID := fmt.Sprintf("run-%s-transition-%d", spec.RunID, events[0].ID)
+	if event.EventID != wantID || event.RunStatus != model.RunStatusRunning || event.PreviousRunStatus != model.RunStatusPlanning {
+		t.Fatalf("unexpected run status webhook: %+v", event)
+	}
+	if len(event.Tickets) != 1 || event.Tickets[0] != "METAWSM-015" {
+		t.Fatalf("expected run tickets on webhook, got %+v", event.Tickets)
+	}
+}
+
+func TestEnqueueForumEventWebhooksReadsStoredEventsBehindCursor(t *testing.T) {
+	workDir := t.TempDir()
+	t.Chdir(workDir)
+	cfg := policy.Default()
+	cfg.Webhooks.Endpoints = []policy.WebhookEndpoint{
+		{Name: "relay", URL: "http://127.0.0.1:1/hook", SecretEnv: "METAWSM_TEST_WEBHOOK_SECRET"},
+	}
+	payload, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatalf("marshal policy: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(workDir, ".metawsm"), 0o755); err != nil {
+		t.Fatalf("mkdir policy dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(workDir, policy.DefaultPolicyPath), payload, 0o644); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+	svc := newTestService(t)
+
+	openThread := func(threadID string) {
+		t.Helper()
+		if _, err := svc.ForumOpenThread(t.Context(), ForumOpenThreadOptions{
+			ThreadID:  threadID,
+			Ticket:    "METAWSM-015",
+			Title:     "Which schema?",
+			Body:      "Need guidance",
+			ActorType: model.ForumActorAgent,
+			ActorName: "agent",
+		}); err != nil {
+			t.Fatalf("open thread %s: %v", threadID, err)
+		}
+	}
+	openThread("thread-before-seed")
+	seeded, err := svc.EnqueueForumEventWebhooks(t.Context())
+	if err != nil {
+		t.Fatalf("seed forum event webhooks: %v", err)
+	}
+	if seeded.Events != 0 {
+		t.Fatalf("expected the first pass to only seed the cursor, got %+v", seeded)
+	}
+
+	openThread("thread-after-seed")
+	result, err := svc.EnqueueForumEventWebhooks(t.Context())
+	if err != nil {
+		t.Fatalf("enqueue forum event webhooks: %v", err)
+	}
+	if result.Events != 1 || result.Enqueued != 1 {
+		t.Fatalf("expected the new event queued once, got %+v", result)
+	}
+	again, err := svc.EnqueueForumEventWebhooks(t.Context())
+	if err != nil {
+		t.Fatalf("repeat forum event webhooks: %v", err)
+	}
+	if again.Events != 0 {
+		t.Fatalf("expected no events after the cursor advanced, got %+v", again)
+	}
+
+	pending, err := svc.store.ListForumOutboxByStatus(model.ForumOutboxStatusPending, 50)
+	if err != nil {
+		t.Fatalf("list pending outbox: %v", err)
+	}
+	threads := []string{}
+	for _, message := range pending {
+		if message.Topic != svc.forumTopics.IntegrationTopic("webhook") {
+			continue
+		}
+		var delivery model.WebhookDelivery
+		if err := json.Unmarshal([]byte(message.PayloadJSON), &delivery); err != nil {
+			t.Fatalf("decode webhook delivery: %v", err)
+		}
+		if delivery.Event.ForumEvent == nil || delivery.Event.EventID != delivery.Event.ForumEvent.Envelope.EventID {
+			t.Fatalf("expected the stored forum event on the webhook, got %+v", delivery.Event)
+		}
+		threads = append(threads, delivery.Event.ThreadID)
+	}
+	if len(threads) != 1 || threads[0] != "thread-after-seed" {
+		t.Fatalf("expected one webhook for the thread opened after seeding, got %+v", threads)
+	}
+}
@@ -0,0 +1,145 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+
+	"metawsm/internal/model"
+)
+
+// OperatorRuleIntents are the intents an operator rule can choose. Only the
+// actionable ones may set execute.
+var OperatorRuleIntents = map[string]bool{
+	"noop":                  false,
+	"escalate_guidance":     false,
+	"escalate_blocked":      false,
+	"auto_restart":          true,
+	"auto_stop_stale":       true,
+	"commit_ready":          true,
+	"pr_ready":              true,
+	"review_feedback_ready": true,
+}
+
+// OperatorRule maps run conditions onto an operator intent. Rules are tried in
+// order and the first whose conditions all hold decides; when none match, the
+// built-in operator rules apply. A rule that matches but has used up its rate
+// limit decides noop, so it never falls through to a broader rule.
+type OperatorRule struct {
+	Name      string                 `json:"name"`
+	When      OperatorRuleConditions `json:"when"`
+	Intent    string                 `json:"intent"`
+	Execute   bool                   `json:"execute,omitempty"`
+	Reason    string                 `json:"reason,omitempty"`
+	RateLimit *OperatorRuleRateLimit `json:"rate_limit,omitempty"`
+}
+
+// OperatorRuleConditions must all hold for a rule to match. Unset fields
+// match anything, so a rule with no conditions matches every run.
+type OperatorRuleConditions struct {
+	// RunStatus matches when the run's status is one of the listed values.
+	RunStatus []string `json:"run_status,omitempty"`
+	// UnhealthyAgents and GuidancePending match when the run has (true) or
+	// lacks (false) unhealthy agents or pending guidance.
+	UnhealthyAgents *bool `json:"unhealthy_agents,omitempty"`
+	// AgentHealth matches when an unhealthy agent's health or status is one
+	// of stalled, dead or failed.
+	AgentHealth []string `json:"agent_health,omitempty"`
+	// UnhealthyIntervalsMin is the number of consecutive operator passes
+	// that must have seen unhealthy agents.
+	UnhealthyIntervalsMin int   `json:"unhealthy_intervals_min,omitempty"`
+	GuidancePending       *bool `json:"guidance_pending,omitempty"`
+	// GuidanceAgeMinSeconds matches when the oldest pending guidance
+	// request has waited at least this long.
+	GuidanceAgeMinSeconds int `json:"guidance_age_min_seconds,omitempty"`
+	// IdleMinSeconds matches when the run record has not changed for at
+	// least this long.
+	IdleMinSeconds    int   `json:"idle_min_seconds,omitempty"`
+	DirtyDiffs        *bool `json:"dirty_diffs,omitempty"`
+	DraftPRsMin       int   `json:"draft_prs_min,omitempty"`
+	OpenPRsMin        int   `json:"open_prs_min,omitempty"`
+	QueuedFeedbackMin int   `json:"queued_feedback_min,omitempty"`
+	NewFeedbackMin    int   `json:"new_feedback_min,omitempty"`
+}
+
+// OperatorRuleRateLimit caps how many decisions a rule makes for one run
+// within a sliding window.
+type OperatorRuleRateLimit struct {
+	Max           int `json:"max"`
+	WindowSeconds int `json:"window_seconds"`
+}
+
+// OperatorRuleByName returns the named rule, or nil.
+func (c Config) OperatorRuleByName(name string) *OperatorRule {
+	for i := range c.Operator.Rules {
+		if c.Operator.Rules[i].Name == name {
+			return &c.Operator.Rules[i]
+		}
+	}
+	return nil
+}
+
+func validateOperatorRules(rules []OperatorRule) error {
+	seenNames := map[string]struct{}{}
+	for _, rule := range rules {
+		name := strings.TrimSpace(rule.Name)
+		if name == "" {
+			return fmt.Errorf("operator.rules.name cannot be empty")
+		}
+		for _, r := range name {
+			if !(r >= 'a' && r <= 'z') && !(r >= '0' && r <= '9') && r != '-' && r != '_' {
+				return fmt.Errorf("operator rule name %q must use only a-z, 0-9, - and _", name)
+			}
+		}
+		if _, exists := seenNames[name]; exists {
+			return fmt.Errorf("duplicate operator rule name %q", name)
+		}
+		seenNames[name] = struct{}{}
+
+		actionable, ok := OperatorRuleIntents[rule.Intent]
+		if !ok {
+			return fmt.Errorf("operator rule %q intent must be noop|escalate_guidance|escalate_blocked|auto_restart|auto_stop_stale|commit_ready|pr_ready|review_feedback_ready", name)
+		}
+		if rule.Execute && !actionable {
+			return fmt.Errorf("operator rule %q cannot execute intent %s", name, rule.Intent)
+		}
+		if rule.RateLimit != nil && (rule.RateLimit.Max <= 0 || rule.RateLimit.WindowSeconds <= 0) {
+			return fmt.Errorf("operator rule %q rate_limit.max and rate_limit.window_seconds must be > 0", name)
+		}
+
+		when := rule.When
+		for _, status := range when.RunStatus {
+			switch model.RunStatus(strings.TrimSpace(status)) {
+			case model.RunStatusCreated, model.RunStatusPlanning, model.RunStatusRunning, model.RunStatusAwaitingGuidance,
+				model.RunStatusPaused, model.RunStatusFailed, model.RunStatusStopping, model.RunStatusStopped,
+				model.RunStatusClosing, model.RunStatusClosed, model.RunStatusComplete:
+			default:
+				return fmt.Errorf("operator rule %q has unknown run_status %q", name, status)
+			}
+		}
+		for _, health := range when.AgentHealth {
+			switch strings.TrimSpace(health) {
+			case string(model.HealthStateStalled), string(model.HealthStateDead), string(model.AgentStatusFailed):
+			default:
+				return fmt.Errorf("operator rule %q agent_health must be stalled|dead|failed", name)
+			}
+		}
+		counts := []struct {
+			field string
+			value int
+		}{
+			{"unhealthy_intervals_min", when.UnhealthyIntervalsMin},
+			{"guidance_age_min_seconds", when.GuidanceAgeMinSeconds},
+			{"idle_min_seconds", when.IdleMinSeconds},
+			{"draft_prs_min", when.DraftPRsMin},
+			{"open_prs_min", when.OpenPRsMin},
+			{"queued_feedback_min", when.QueuedFeedbackMin},
+			{"new_feedback_min", when.NewFeedbackMin},
+		}
+		for _, count := range counts {
+			if count.value < 0 {
+				return fmt.Errorf("operator rule %q %s cannot be negative", name, count.field)
+			}
+		}
+	}
+	return nil
+}
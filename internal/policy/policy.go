@@ -33,8 +33,16 @@ type Config struct {
 	Tmux struct {
 		SessionPattern string `json:"session_pattern"`
 	} `json:"tmux"`
+	Runtime struct {
+		Kind string `json:"kind"`
+	} `json:"runtime"`
+	Transcripts struct {
+		MaxBytes int64 `json:"max_bytes"`
+		Keep     int   `json:"keep"`
+	} `json:"transcripts"`
 	Execution struct {
-		StepRetries int `json:"step_retries"`
+		StepRetries      int `json:"step_retries"`
+		MaxParallelSteps int `json:"max_parallel_steps"`
 	} `json:"execution"`
 	Health struct {
 		IdleSeconds            int `json:"idle_seconds"`
@@ -44,22 +52,43 @@ type Config struct {
 	Close struct {
 		RequireCleanGit bool `json:"require_clean_git"`
 	} `json:"close"`
+	Store struct {
+		Backend string `json:"backend"`
+	} `json:"store"`
+	Server struct {
+		Auth struct {
+			Mode                 string `json:"mode"`
+			AgentTokenTTLSeconds int    `json:"agent_token_ttl_seconds"`
+		} `json:"auth"`
+	} `json:"server"`
 	Operator struct {
 		UnhealthyConfirmations int `json:"unhealthy_confirmations"`
 		RestartBudget          int `json:"restart_budget"`
 		RestartCooldownSeconds int `json:"restart_cooldown_seconds"`
 		StaleRunAgeSeconds     int `json:"stale_run_age_seconds"`
-		LLM                    struct {
-			Mode           string `json:"mode"`
+		// DecisionRetentionDays is how long audited operator decisions are
+		// kept after they were last seen; the serve worker prunes older ones.
+		DecisionRetentionDays int `json:"decision_retention_days"`
+		LLM                   struct {
+			Mode string `json:"mode"`
+			// Provider selects the adapter: codex (CLI), http (an
+			// OpenAI-compatible chat completions endpoint at URL), or
+			// scripted (recorded responses replayed from ScriptPath).
+			Provider       string `json:"provider"`
 			Command        string `json:"command"`
 			Model          string `json:"model"`
+			URL            string `json:"url"`
+			APIKeyEnv      string `json:"api_key_env"`
+			ScriptPath     string `json:"script_path"`
 			TimeoutSeconds int    `json:"timeout_seconds"`
 			MaxTokens      int    `json:"max_tokens"`
 		} `json:"llm"`
+		Rules []OperatorRule `json:"rules"`
 	} `json:"operator"`
 	Forum struct {
-		Enabled bool `json:"enabled"`
-		Topics  struct {
+		Enabled   bool   `json:"enabled"`
+		Transport string `json:"transport"`
+		Topics    struct {
 			CommandPrefix     string `json:"command_prefix"`
 			EventPrefix       string `json:"event_prefix"`
 			IntegrationPrefix string `json:"integration_prefix"`
@@ -70,12 +99,26 @@ type Config struct {
 			Group    string `json:"group"`
 			Consumer string `json:"consumer"`
 		} `json:"redis"`
+		Outbox struct {
+			MaxAttempts        int `json:"max_attempts"`
+			BackoffBaseSeconds int `json:"backoff_base_seconds"`
+			BackoffMaxSeconds  int `json:"backoff_max_seconds"`
+			LeaseSeconds       int `json:"lease_seconds"`
+		} `json:"outbox"`
 		SLA struct {
-			EscalationMinutes int `json:"escalation_minutes"`
+			EscalationMinutes int                    `json:"escalation_minutes"`
+			NotifyCommand     string                 `json:"notify_command"`
+			EscalationChains  []ForumEscalationChain `json:"escalation_chains"`
 		} `json:"sla"`
 		DocsSync struct {
 			Enabled bool `json:"enabled"`
 		} `json:"docs_sync"`
+		GuidanceLibrary struct {
+			Enabled         bool    `json:"enabled"`
+			SuggestLimit    int     `json:"suggest_limit"`
+			MinScore        float64 `json:"min_score"`
+			AutoAnswerExact bool    `json:"auto_answer_exact"`
+		} `json:"guidance_library"`
 	} `json:"forum"`
 	GitPR struct {
 		Mode              string   `json:"mode"`
@@ -97,8 +140,16 @@ type Config struct {
 			AutoDispatchCapPerInterval int      `json:"auto_dispatch_cap_per_interval"`
 		} `json:"review_feedback"`
 	} `json:"git_pr"`
+	Webhooks struct {
+		TimeoutSeconds int               `json:"timeout_seconds"`
+		Endpoints      []WebhookEndpoint `json:"endpoints"`
+	} `json:"webhooks"`
+	Integrations struct {
+		Sources []IntegrationSource `json:"sources"`
+	} `json:"integrations"`
 	AgentProfiles []AgentProfile `json:"agent_profiles"`
 	Agents        []Agent        `json:"agents"`
+	RunTemplates  []RunTemplate  `json:"run_templates"`
 }
 
 type AgentProfile struct {
@@ -122,6 +173,60 @@ type DocAPIEndpoint struct {
 	Workspace string `json:"workspace,omitempty"`
 }
 
+// ForumEscalationChain lists who an overdue thread on Ticket is handed to,
+// one assignee per escalation level. Ticket "*" applies to tickets without a
+// chain of their own.
+type ForumEscalationChain struct {
+	Ticket    string                    `json:"ticket"`
+	Assignees []ForumEscalationAssignee `json:"assignees"`
+}
+
+type ForumEscalationAssignee struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+// WebhookEndpoint receives signed JSON for matching forum and run events. Each
+// filter list narrows only the events that carry that attribute: priorities
+// apply to forum events, run_statuses to run status changes; an empty list
+// matches everything.
+type WebhookEndpoint struct {
+	Name        string   `json:"name"`
+	URL         string   `json:"url"`
+	SecretEnv   string   `json:"secret_env"`
+	Tickets     []string `json:"tickets,omitempty"`
+	EventTypes  []string `json:"event_types,omitempty"`
+	Priorities  []string `json:"priorities,omitempty"`
+	RunStatuses []string `json:"run_statuses,omitempty"`
+}
+
+// IntegrationSource accepts payloads posted to /api/v1/integrations/{name}.
+// Callers authenticate with the secret named by secret_env, either as a
+// bearer token or as an X-Metawsm-Signature HMAC of the body.
+type IntegrationSource struct {
+	Name      string            `json:"name"`
+	SecretEnv string            `json:"secret_env"`
+	Rules     []IntegrationRule `json:"rules"`
+}
+
+// IntegrationRule maps one kind of payload onto a forum command. It fires
+// when every match entry equals the payload value at that dotted path ("*"
+// only requires the path to be present). Ticket, run_id, thread_id, title,
+// body, priority and state are templates: "{{path}}" is replaced by the
+// payload value at path.
+type IntegrationRule struct {
+	Name     string            `json:"name"`
+	Match    map[string]string `json:"match,omitempty"`
+	Action   string            `json:"action"`
+	Ticket   string            `json:"ticket,omitempty"`
+	RunID    string            `json:"run_id,omitempty"`
+	ThreadID string            `json:"thread_id,omitempty"`
+	Title    string            `json:"title,omitempty"`
+	Body     string            `json:"body,omitempty"`
+	Priority string            `json:"priority,omitempty"`
+	State    string            `json:"state,omitempty"`
+}
+
 type Agent struct {
 	Name    string `json:"name"`
 	Profile string `json:"profile"`
@@ -139,21 +244,32 @@ func Default() Config {
 	cfg.Docs.StaleWarningSeconds = 900
 	cfg.Docs.API.RequestTimeoutSec = 3
 	cfg.Tmux.SessionPattern = "{agent}-{workspace}"
+	cfg.Runtime.Kind = "tmux"
+	cfg.Transcripts.MaxBytes = 10 * 1024 * 1024
+	cfg.Transcripts.Keep = 3
 	cfg.Execution.StepRetries = 1
+	cfg.Execution.MaxParallelSteps = 4
 	cfg.Health.IdleSeconds = 300
 	cfg.Health.ActivityStalledSeconds = 900
 	cfg.Health.ProgressStalledSeconds = 1200
 	cfg.Close.RequireCleanGit = true
+	cfg.Store.Backend = "driver"
+	cfg.Server.Auth.Mode = "off"
+	cfg.Server.Auth.AgentTokenTTLSeconds = 43200
 	cfg.Operator.UnhealthyConfirmations = 2
 	cfg.Operator.RestartBudget = 3
 	cfg.Operator.RestartCooldownSeconds = 60
 	cfg.Operator.StaleRunAgeSeconds = 3600
+	cfg.Operator.DecisionRetentionDays = 30
 	cfg.Operator.LLM.Mode = "assist"
+	cfg.Operator.LLM.Provider = "codex"
 	cfg.Operator.LLM.Command = "codex"
 	cfg.Operator.LLM.Model = ""
 	cfg.Operator.LLM.TimeoutSeconds = 30
 	cfg.Operator.LLM.MaxTokens = 400
+	cfg.Operator.Rules = []OperatorRule{}
 	cfg.Forum.Enabled = true
+	cfg.Forum.Transport = "embedded"
 	cfg.Forum.Topics.CommandPrefix = "forum.commands"
 	cfg.Forum.Topics.EventPrefix = "forum.events"
 	cfg.Forum.Topics.IntegrationPrefix = "forum.integration"
@@ -161,8 +277,20 @@ func Default() Config {
 	cfg.Forum.Redis.Stream = "metawsm-forum"
 	cfg.Forum.Redis.Group = "metawsm-forum"
 	cfg.Forum.Redis.Consumer = "operator"
+	cfg.Forum.Outbox.MaxAttempts = 8
+	cfg.Forum.Outbox.BackoffBaseSeconds = 2
+	cfg.Forum.Outbox.BackoffMaxSeconds = 300
+	cfg.Forum.Outbox.LeaseSeconds = 300
 	cfg.Forum.SLA.EscalationMinutes = 30
+	cfg.Forum.SLA.EscalationChains = []ForumEscalationChain{}
 	cfg.Forum.DocsSync.Enabled = true
+	cfg.Forum.GuidanceLibrary.Enabled = true
+	cfg.Forum.GuidanceLibrary.SuggestLimit = 3
+	cfg.Forum.GuidanceLibrary.MinScore = 0.5
+	cfg.Forum.GuidanceLibrary.AutoAnswerExact = false
+	cfg.Webhooks.TimeoutSeconds = 10
+	cfg.Webhooks.Endpoints = []WebhookEndpoint{}
+	cfg.Integrations.Sources = []IntegrationSource{}
 	cfg.GitPR.Mode = "assist"
 	cfg.GitPR.CredentialMode = "local_user_auth"
 	cfg.GitPR.BranchTemplate = "{ticket}/{repo}/{run}"
@@ -218,7 +346,18 @@ func Load(path string) (Config, string, error) {
 	if err == nil {
 		finalPath = absPath
 	}
+	templates, err := loadRunTemplateFiles(finalPath)
+	if err != nil {
+		return cfg, finalPath, err
+	}
 	if _, err := os.Stat(finalPath); os.IsNotExist(err) {
+		if len(templates) == 0 {
+			return cfg, finalPath, nil
+		}
+		cfg.RunTemplates = append(cfg.RunTemplates, templates...)
+		if err := Validate(cfg); err != nil {
+			return cfg, finalPath, fmt.Errorf("validate run templates in %s: %w", filepath.Join(filepath.Dir(finalPath), RunTemplatesDir), err)
+		}
 		return cfg, finalPath, nil
 	}
 
@@ -229,6 +368,7 @@ func Load(path string) (Config, string, error) {
 	if err := json.Unmarshal(b, &cfg); err != nil {
 		return cfg, finalPath, fmt.Errorf("parse policy %s: %w", finalPath, err)
 	}
+	cfg.RunTemplates = append(cfg.RunTemplates, templates...)
 	if err := Validate(cfg); err != nil {
 		return cfg, finalPath, fmt.Errorf("validate policy %s: %w", finalPath, err)
 	}
@@ -260,6 +400,25 @@ func Validate(cfg Config) error {
 	if strings.TrimSpace(cfg.Tmux.SessionPattern) == "" {
 		return fmt.Errorf("tmux.session_pattern cannot be empty")
 	}
+	switch strings.TrimSpace(strings.ToLower(cfg.Runtime.Kind)) {
+	case "tmux", "process":
+	default:
+		return fmt.Errorf("runtime.kind must be tmux|process")
+	}
+	if cfg.Transcripts.MaxBytes <= 0 {
+		return fmt.Errorf("transcripts.max_bytes must be > 0")
+	}
+	if cfg.Transcripts.Keep < 0 {
+		return fmt.Errorf("transcripts.keep must be >= 0")
+	}
+	switch strings.TrimSpace(strings.ToLower(cfg.Server.Auth.Mode)) {
+	case "off", "token":
+	default:
+		return fmt.Errorf("server.auth.mode must be off|token")
+	}
+	if cfg.Server.Auth.AgentTokenTTLSeconds <= 0 {
+		return fmt.Errorf("server.auth.agent_token_ttl_seconds must be > 0")
+	}
 	authorityMode := strings.TrimSpace(cfg.Docs.AuthorityMode)
 	if authorityMode == "" {
 		return fmt.Errorf("docs.authority_mode cannot be empty")
@@ -298,6 +457,11 @@ func Validate(cfg Config) error {
 	if cfg.Health.ActivityStalledSeconds < cfg.Health.IdleSeconds {
 		return fmt.Errorf("activity_stalled_seconds must be >= idle_seconds")
 	}
+	switch strings.TrimSpace(strings.ToLower(cfg.Store.Backend)) {
+	case "driver", "cli":
+	default:
+		return fmt.Errorf("store.backend must be driver|cli")
+	}
 	if cfg.Operator.UnhealthyConfirmations <= 0 {
 		return fmt.Errorf("operator.unhealthy_confirmations must be > 0")
 	}
@@ -310,13 +474,30 @@ func Validate(cfg Config) error {
 	if cfg.Operator.StaleRunAgeSeconds <= 0 {
 		return fmt.Errorf("operator.stale_run_age_seconds must be > 0")
 	}
+	if cfg.Operator.DecisionRetentionDays <= 0 {
+		return fmt.Errorf("operator.decision_retention_days must be > 0")
+	}
 	switch strings.TrimSpace(strings.ToLower(cfg.Operator.LLM.Mode)) {
 	case "off", "assist", "auto":
 	default:
 		return fmt.Errorf("operator.llm.mode must be off|assist|auto")
 	}
-	if strings.TrimSpace(cfg.Operator.LLM.Command) == "" {
-		return fmt.Errorf("operator.llm.command cannot be empty")
+	switch strings.TrimSpace(strings.ToLower(cfg.Operator.LLM.Provider)) {
+	case "", "codex":
+		if strings.TrimSpace(cfg.Operator.LLM.Command) == "" {
+			return fmt.Errorf("operator.llm.command cannot be empty")
+		}
+	case "http":
+		parsed, err := url.Parse(strings.TrimSpace(cfg.Operator.LLM.URL))
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("operator.llm.url must be an http(s) URL when provider is http")
+		}
+	case "scripted":
+		if strings.TrimSpace(cfg.Operator.LLM.ScriptPath) == "" {
+			return fmt.Errorf("operator.llm.script_path cannot be empty when provider is scripted")
+		}
+	default:
+		return fmt.Errorf("operator.llm.provider must be codex|http|scripted")
 	}
 	if cfg.Operator.LLM.TimeoutSeconds <= 0 {
 		return fmt.Errorf("operator.llm.timeout_seconds must be > 0")
@@ -324,6 +505,9 @@ func Validate(cfg Config) error {
 	if cfg.Operator.LLM.MaxTokens <= 0 {
 		return fmt.Errorf("operator.llm.max_tokens must be > 0")
 	}
+	if err := validateOperatorRules(cfg.Operator.Rules); err != nil {
+		return err
+	}
 	if strings.TrimSpace(cfg.Forum.Topics.CommandPrefix) == "" {
 		return fmt.Errorf("forum.topics.command_prefix cannot be empty")
 	}
@@ -333,21 +517,78 @@ func Validate(cfg Config) error {
 	if strings.TrimSpace(cfg.Forum.Topics.IntegrationPrefix) == "" {
 		return fmt.Errorf("forum.topics.integration_prefix cannot be empty")
 	}
-	if strings.TrimSpace(cfg.Forum.Redis.URL) == "" {
-		return fmt.Errorf("forum.redis.url cannot be empty")
+	switch strings.TrimSpace(strings.ToLower(cfg.Forum.Transport)) {
+	case "embedded":
+	case "redis":
+		if strings.TrimSpace(cfg.Forum.Redis.URL) == "" {
+			return fmt.Errorf("forum.redis.url cannot be empty")
+		}
+		if strings.TrimSpace(cfg.Forum.Redis.Stream) == "" {
+			return fmt.Errorf("forum.redis.stream cannot be empty")
+		}
+		if strings.TrimSpace(cfg.Forum.Redis.Group) == "" {
+			return fmt.Errorf("forum.redis.group cannot be empty")
+		}
+		if strings.TrimSpace(cfg.Forum.Redis.Consumer) == "" {
+			return fmt.Errorf("forum.redis.consumer cannot be empty")
+		}
+	default:
+		return fmt.Errorf("forum.transport must be embedded|redis")
+	}
+	if cfg.Forum.Outbox.MaxAttempts <= 0 {
+		return fmt.Errorf("forum.outbox.max_attempts must be > 0")
 	}
-	if strings.TrimSpace(cfg.Forum.Redis.Stream) == "" {
-		return fmt.Errorf("forum.redis.stream cannot be empty")
+	if cfg.Forum.Outbox.BackoffBaseSeconds <= 0 {
+		return fmt.Errorf("forum.outbox.backoff_base_seconds must be > 0")
 	}
-	if strings.TrimSpace(cfg.Forum.Redis.Group) == "" {
-		return fmt.Errorf("forum.redis.group cannot be empty")
+	if cfg.Forum.Outbox.BackoffMaxSeconds < cfg.Forum.Outbox.BackoffBaseSeconds {
+		return fmt.Errorf("forum.outbox.backoff_max_seconds must be >= forum.outbox.backoff_base_seconds")
 	}
-	if strings.TrimSpace(cfg.Forum.Redis.Consumer) == "" {
-		return fmt.Errorf("forum.redis.consumer cannot be empty")
+	if cfg.Forum.Outbox.LeaseSeconds <= 0 {
+		return fmt.Errorf("forum.outbox.lease_seconds must be > 0")
 	}
 	if cfg.Forum.SLA.EscalationMinutes <= 0 {
 		return fmt.Errorf("forum.sla.escalation_minutes must be > 0")
 	}
+	if cfg.Forum.GuidanceLibrary.SuggestLimit < 0 {
+		return fmt.Errorf("forum.guidance_library.suggest_limit must be >= 0")
+	}
+	if cfg.Forum.GuidanceLibrary.MinScore < 0 || cfg.Forum.GuidanceLibrary.MinScore > 1 {
+		return fmt.Errorf("forum.guidance_library.min_score must be between 0 and 1")
+	}
+	chainTickets := map[string]bool{}
+	for _, chain := range cfg.Forum.SLA.EscalationChains {
+		ticket := strings.TrimSpace(chain.Ticket)
+		if ticket == "" {
+			return fmt.Errorf("forum.sla.escalation_chains.ticket cannot be empty (use \"*\" for the default chain)")
+		}
+		if chainTickets[ticket] {
+			return fmt.Errorf("duplicate forum escalation chain for ticket %q", ticket)
+		}
+		chainTickets[ticket] = true
+		if len(chain.Assignees) == 0 {
+			return fmt.Errorf("forum escalation chain %q must list at least one assignee", ticket)
+		}
+		for _, assignee := range chain.Assignees {
+			switch strings.TrimSpace(strings.ToLower(assignee.Type)) {
+			case "human", "operator", "agent":
+			default:
+				return fmt.Errorf("forum escalation chain %q assignee type must be human|operator|agent", ticket)
+			}
+			if strings.TrimSpace(assignee.Name) == "" {
+				return fmt.Errorf("forum escalation chain %q assignee name cannot be empty", ticket)
+			}
+		}
+	}
+	if cfg.Webhooks.TimeoutSeconds <= 0 {
+		return fmt.Errorf("webhooks.timeout_seconds must be > 0")
+	}
+	if err := validateWebhookEndpoints(cfg.Webhooks.Endpoints); err != nil {
+		return err
+	}
+	if err := validateIntegrationSources(cfg.Integrations.Sources); err != nil {
+		return err
+	}
 	switch strings.TrimSpace(strings.ToLower(cfg.GitPR.Mode)) {
 	case "off", "assist", "auto":
 	default:
@@ -417,6 +658,9 @@ func Validate(cfg Config) error {
 	if cfg.Execution.StepRetries < 0 {
 		return fmt.Errorf("execution.step_retries must be >= 0")
 	}
+	if cfg.Execution.MaxParallelSteps <= 0 {
+		return fmt.Errorf("execution.max_parallel_steps must be > 0")
+	}
 	if len(cfg.AgentProfiles) == 0 {
 		return fmt.Errorf("agent_profiles must contain at least one entry")
 	}
@@ -467,7 +711,23 @@ func Validate(cfg Config) error {
 			return fmt.Errorf("agent %q references unknown profile %q", name, profile)
 		}
 	}
-	return nil
+	return validateRunTemplates(cfg)
+}
+
+// ForumEscalationChainFor returns the escalation chain for ticket, falling back
+// to the "*" chain. It returns nil when neither is configured.
+func ForumEscalationChainFor(cfg Config, ticket string) []ForumEscalationAssignee {
+	ticket = strings.TrimSpace(ticket)
+	var fallback []ForumEscalationAssignee
+	for _, chain := range cfg.Forum.SLA.EscalationChains {
+		switch strings.TrimSpace(chain.Ticket) {
+		case ticket:
+			return chain.Assignees
+		case "*":
+			fallback = chain.Assignees
+		}
+	}
+	return fallback
 }
 
 func isSupportedGitPRCheck(check string) bool {
@@ -512,6 +772,106 @@ func validateDocAPIEndpoints(kind string, endpoints []DocAPIEndpoint, seenNames
 	return nil
 }
 
+func validateWebhookEndpoints(endpoints []WebhookEndpoint) error {
+	seenNames := map[string]struct{}{}
+	for _, endpoint := range endpoints {
+		name := strings.TrimSpace(endpoint.Name)
+		if name == "" {
+			return fmt.Errorf("webhooks.endpoints.name cannot be empty")
+		}
+		if _, exists := seenNames[name]; exists {
+			return fmt.Errorf("duplicate webhook endpoint name %q", name)
+		}
+		seenNames[name] = struct{}{}
+		parsedURL, err := url.Parse(strings.TrimSpace(endpoint.URL))
+		if err != nil || parsedURL.Host == "" || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") {
+			return fmt.Errorf("webhook endpoint %q has invalid url %q", name, endpoint.URL)
+		}
+		if strings.TrimSpace(endpoint.SecretEnv) == "" {
+			return fmt.Errorf("webhook endpoint %q requires secret_env", name)
+		}
+		for _, ticket := range endpoint.Tickets {
+			if strings.TrimSpace(ticket) == "" {
+				return fmt.Errorf("webhook endpoint %q tickets cannot contain empty values", name)
+			}
+		}
+		for _, eventType := range endpoint.EventTypes {
+			if strings.TrimSpace(eventType) == "" {
+				return fmt.Errorf("webhook endpoint %q event_types cannot contain empty values", name)
+			}
+		}
+		for _, priority := range endpoint.Priorities {
+			switch model.ForumPriority(strings.TrimSpace(strings.ToLower(priority))) {
+			case model.ForumPriorityLow, model.ForumPriorityNormal, model.ForumPriorityHigh, model.ForumPriorityUrgent:
+			default:
+				return fmt.Errorf("webhook endpoint %q priorities must be low|normal|high|urgent", name)
+			}
+		}
+		for _, status := range endpoint.RunStatuses {
+			if strings.TrimSpace(status) == "" {
+				return fmt.Errorf("webhook endpoint %q run_statuses cannot contain empty values", name)
+			}
+		}
+	}
+	return nil
+}
+
+func validateIntegrationSources(sources []IntegrationSource) error {
+	seenNames := map[string]struct{}{}
+	for _, source := range sources {
+		name := strings.TrimSpace(source.Name)
+		if name == "" {
+			return fmt.Errorf("integrations.sources.name cannot be empty")
+		}
+		for _, r := range name {
+			if !(r >= 'a' && r <= 'z') && !(r >= '0' && r <= '9') && r != '-' && r != '_' {
+				return fmt.Errorf("integration source name %q must use only a-z, 0-9, - and _", name)
+			}
+		}
+		if _, exists := seenNames[name]; exists {
+			return fmt.Errorf("duplicate integration source name %q", name)
+		}
+		seenNames[name] = struct{}{}
+		if strings.TrimSpace(source.SecretEnv) == "" {
+			return fmt.Errorf("integration source %q requires secret_env", name)
+		}
+		if len(source.Rules) == 0 {
+			return fmt.Errorf("integration source %q requires at least one rule", name)
+		}
+		for i, rule := range source.Rules {
+			ruleName := strings.TrimSpace(rule.Name)
+			if ruleName == "" {
+				ruleName = fmt.Sprintf("#%d", i+1)
+			}
+			for path := range rule.Match {
+				if strings.TrimSpace(path) == "" {
+					return fmt.Errorf("integration source %q rule %s match paths cannot be empty", name, ruleName)
+				}
+			}
+			switch strings.TrimSpace(strings.ToLower(rule.Action)) {
+			case "open_thread":
+				if strings.TrimSpace(rule.Ticket) == "" && strings.TrimSpace(rule.RunID) == "" {
+					return fmt.Errorf("integration source %q rule %s open_thread requires ticket or run_id", name, ruleName)
+				}
+				if strings.TrimSpace(rule.Title) == "" || strings.TrimSpace(rule.Body) == "" {
+					return fmt.Errorf("integration source %q rule %s open_thread requires title and body", name, ruleName)
+				}
+			case "add_post", "answer":
+				if strings.TrimSpace(rule.ThreadID) == "" || strings.TrimSpace(rule.Body) == "" {
+					return fmt.Errorf("integration source %q rule %s %s requires thread_id and body", name, ruleName, rule.Action)
+				}
+			case "change_state":
+				if strings.TrimSpace(rule.ThreadID) == "" || strings.TrimSpace(rule.State) == "" {
+					return fmt.Errorf("integration source %q rule %s change_state requires thread_id and state", name, ruleName)
+				}
+			default:
+				return fmt.Errorf("integration source %q rule %s action must be open_thread|add_post|answer|change_state", name, ruleName)
+			}
+		}
+	}
+	return nil
+}
+
 func ResolveAgents(cfg Config, requested []string, policyPath string) ([]model.AgentSpec, error) {
 	agentByName := map[string]Agent{}
 	for _, agent := range cfg.Agents {
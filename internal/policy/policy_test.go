@@ -273,6 +273,216 @@ func TestValidateRejectsInvalidOperatorLLMMode(t *testing.T) {
 	}
 }
 
+func TestValidateForumTransport(t *testing.T) {
+	cfg := Default()
+	cfg.Forum.Redis.URL = ""
+	if err := Validate(cfg); err != nil {
+		t.Fatalf("expected embedded transport to ignore redis settings, got %v", err)
+	}
+
+	cfg.Forum.Transport = "redis"
+	err := Validate(cfg)
+	if err == nil || !strings.Contains(err.Error(), "forum.redis.url") {
+		t.Fatalf("expected redis transport to require forum.redis.url, got %v", err)
+	}
+
+	cfg.Forum.Transport = "nats"
+	err = Validate(cfg)
+	if err == nil || !strings.Contains(err.Error(), "forum.transport") {
+		t.Fatalf("expected forum.transport validation error, got %v", err)
+	}
+}
+
+func TestValidateForumOutboxRetry(t *testing.T) {
+	cfg := Default()
+	cfg.Forum.Outbox.MaxAttempts = 0
+	err := Validate(cfg)
+	if err == nil || !strings.Contains(err.Error(), "forum.outbox.max_attempts") {
+		t.Fatalf("expected forum.outbox.max_attempts validation error, got %v", err)
+	}
+
+	cfg = Default()
+	cfg.Forum.Outbox.BackoffMaxSeconds = 1
+	err = Validate(cfg)
+	if err == nil || !strings.Contains(err.Error(), "forum.outbox.backoff_max_seconds") {
+		t.Fatalf("expected forum.outbox.backoff_max_seconds validation error, got %v", err)
+	}
+}
+
+func TestForumEscalationChainsValidateAndResolve(t *testing.T) {
+	cfg := Default()
+	cfg.Forum.SLA.EscalationChains = []ForumEscalationChain{
+		{Ticket: "*", Assignees: []ForumEscalationAssignee{{Type: "operator", Name: "oncall"}}},
+		{Ticket: "METAWSM-014", Assignees: []ForumEscalationAssignee{
+			{Type: "operator", Name: "triage"},
+			{Type: "human", Name: "lead"},
+		}},
+	}
+	if err := Validate(cfg); err != nil {
+		t.Fatalf("validate escalation chains: %v", err)
+	}
+	if chain := ForumEscalationChainFor(cfg, "METAWSM-014"); len(chain) != 2 || chain[1].Name != "lead" {
+		t.Fatalf("expected ticket chain, got %#v", chain)
+	}
+	if chain := ForumEscalationChainFor(cfg, "OTHER-1"); len(chain) != 1 || chain[0].Name != "oncall" {
+		t.Fatalf("expected default chain, got %#v", chain)
+	}
+
+	cfg.Forum.SLA.EscalationChains = append(cfg.Forum.SLA.EscalationChains, ForumEscalationChain{
+		Ticket:    "METAWSM-015",
+		Assignees: []ForumEscalationAssignee{{Type: "robot", Name: "x"}},
+	})
+	err := Validate(cfg)
+	if err == nil || !strings.Contains(err.Error(), "human|operator|agent") {
+		t.Fatalf("expected escalation assignee type validation error, got %v", err)
+	}
+
+	cfg = Default()
+	cfg.Forum.SLA.EscalationChains = []ForumEscalationChain{{Ticket: "*"}}
+	err = Validate(cfg)
+	if err == nil || !strings.Contains(err.Error(), "at least one assignee") {
+		t.Fatalf("expected empty chain validation error, got %v", err)
+	}
+}
+
+func TestValidateWebhookEndpoints(t *testing.T) {
+	cfg := Default()
+	cfg.Webhooks.Endpoints = []WebhookEndpoint{{
+		Name:        "relay",
+		URL:         "https://relay.example.com/hooks/metawsm",
+		SecretEnv:   "METAWSM_RELAY_SECRET",
+		Priorities:  []string{"high", "urgent"},
+		RunStatuses: []string{"failed", "awaiting_guidance"},
+	}}
+	if err := Validate(cfg); err != nil {
+		t.Fatalf("validate webhook endpoint: %v", err)
+	}
+
+	cfg.Webhooks.Endpoints[0].SecretEnv = ""
+	err := Validate(cfg)
+	if err == nil || !strings.Contains(err.Error(), "secret_env") {
+		t.Fatalf("expected secret_env validation error, got %v", err)
+	}
+
+	cfg.Webhooks.Endpoints[0].SecretEnv = "METAWSM_RELAY_SECRET"
+	cfg.Webhooks.Endpoints[0].URL = "ftp://relay.example.com"
+	err = Validate(cfg)
+	if err == nil || !strings.Contains(err.Error(), "invalid url") {
+		t.Fatalf("expected url validation error, got %v", err)
+	}
+
+	cfg.Webhooks.Endpoints[0].URL = "https://relay.example.com"
+	cfg.Webhooks.Endpoints[0].Priorities = []string{"critical"}
+	err = Validate(cfg)
+	if err == nil || !strings.Contains(err.Error(), "priorities") {
+		t.Fatalf("expected priority validation error, got %v", err)
+	}
+}
+
+func TestValidateIntegrationSources(t *testing.T) {
+	cfg := Default()
+	cfg.Integrations.Sources = []IntegrationSource{{
+		Name:      "ci",
+		SecretEnv: "METAWSM_CI_SECRET",
+		Rules: []IntegrationRule{{
+			Name:     "ci-failure",
+			Match:    map[string]string{"status": "failed"},
+			Action:   "open_thread",
+			RunID:    "{{run_id}}",
+			Title:    "CI failed",
+			Body:     "{{url}}",
+			Priority: "urgent",
+		}},
+	}}
+	if err := Validate(cfg); err != nil {
+		t.Fatalf("validate integration source: %v", err)
+	}
+
+	cfg.Integrations.Sources[0].Rules[0].Action = "delete_thread"
+	err := Validate(cfg)
+	if err == nil || !strings.Contains(err.Error(), "action must be") {
+		t.Fatalf("expected action validation error, got %v", err)
+	}
+
+	cfg.Integrations.Sources[0].Rules[0].Action = "change_state"
+	err = Validate(cfg)
+	if err == nil || !strings.Contains(err.Error(), "requires thread_id and state") {
+		t.Fatalf("expected change_state validation error, got %v", err)
+	}
+
+	cfg.Integrations.Sources[0].Rules[0].Action = "open_thread"
+	cfg.Integrations.Sources[0].Name = "CI/alerts"
+	err = Validate(cfg)
+	if err == nil || !strings.Contains(err.Error(), "must use only") {
+		t.Fatalf("expected source name validation error, got %v", err)
+	}
+}
+
+func TestValidateRejectsInvalidStoreBackend(t *testing.T) {
+	cfg := Default()
+	cfg.Store.Backend = "postgres"
+
+	err := Validate(cfg)
+	if err == nil {
+		t.Fatalf("expected store backend validation error")
+	}
+	if !strings.Contains(err.Error(), "store.backend") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestValidateRejectsUnknownRuntimeKind(t *testing.T) {
+	cfg := Default()
+	cfg.Runtime.Kind = "docker"
+
+	err := Validate(cfg)
+	if err == nil {
+		t.Fatalf("expected runtime kind validation error")
+	}
+	if !strings.Contains(err.Error(), "runtime.kind") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestValidateRejectsNonPositiveTranscriptMaxBytes(t *testing.T) {
+	cfg := Default()
+	cfg.Transcripts.MaxBytes = 0
+
+	err := Validate(cfg)
+	if err == nil {
+		t.Fatalf("expected transcripts.max_bytes validation error")
+	}
+	if !strings.Contains(err.Error(), "transcripts.max_bytes") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestValidateRejectsUnknownServerAuthMode(t *testing.T) {
+	cfg := Default()
+	cfg.Server.Auth.Mode = "basic"
+
+	err := Validate(cfg)
+	if err == nil {
+		t.Fatalf("expected server.auth.mode validation error")
+	}
+	if !strings.Contains(err.Error(), "server.auth.mode") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestValidateRejectsNonPositiveAgentTokenTTL(t *testing.T) {
+	cfg := Default()
+	cfg.Server.Auth.AgentTokenTTLSeconds = 0
+
+	err := Validate(cfg)
+	if err == nil {
+		t.Fatalf("expected server.auth.agent_token_ttl_seconds validation error")
+	}
+	if !strings.Contains(err.Error(), "server.auth.agent_token_ttl_seconds") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
 func TestValidateRejectsMissingOperatorCommand(t *testing.T) {
 	cfg := Default()
 	cfg.Operator.LLM.Command = ""
@@ -286,6 +496,63 @@ func TestValidateRejectsMissingOperatorCommand(t *testing.T) {
 	}
 }
 
+func TestValidateOperatorLLMProvider(t *testing.T) {
+	cfg := Default()
+	cfg.Operator.LLM.Provider = "http"
+	if err := Validate(cfg); err == nil || !strings.Contains(err.Error(), "operator.llm.url") {
+		t.Fatalf("expected operator llm url validation error, got %v", err)
+	}
+	cfg.Operator.LLM.URL = "http://127.0.0.1:8080/v1/chat/completions"
+	cfg.Operator.LLM.Command = ""
+	if err := Validate(cfg); err != nil {
+		t.Fatalf("expected http provider without command to validate: %v", err)
+	}
+
+	cfg.Operator.LLM.Provider = "scripted"
+	if err := Validate(cfg); err == nil || !strings.Contains(err.Error(), "operator.llm.script_path") {
+		t.Fatalf("expected operator llm script path validation error, got %v", err)
+	}
+
+	cfg.Operator.LLM.Provider = "telepathy"
+	if err := Validate(cfg); err == nil || !strings.Contains(err.Error(), "operator.llm.provider") {
+		t.Fatalf("expected operator llm provider validation error, got %v", err)
+	}
+}
+
+func TestValidateOperatorRules(t *testing.T) {
+	cfg := Default()
+	cfg.Operator.Rules = []OperatorRule{
+		{Name: "restart-dead", When: OperatorRuleConditions{RunStatus: []string{"running"}, AgentHealth: []string{"dead"}}, Intent: "auto_restart", Execute: true, RateLimit: &OperatorRuleRateLimit{Max: 1, WindowSeconds: 3600}},
+		{Name: "quiet", Intent: "noop"},
+	}
+	if err := Validate(cfg); err != nil {
+		t.Fatalf("expected operator rules to validate: %v", err)
+	}
+
+	cases := []struct {
+		name string
+		rule OperatorRule
+		want string
+	}{
+		{name: "unknown intent", rule: OperatorRule{Name: "x", Intent: "reboot"}, want: "intent must be"},
+		{name: "execute escalation", rule: OperatorRule{Name: "x", Intent: "escalate_blocked", Execute: true}, want: "cannot execute"},
+		{name: "bad status", rule: OperatorRule{Name: "x", Intent: "noop", When: OperatorRuleConditions{RunStatus: []string{"done"}}}, want: "unknown run_status"},
+		{name: "bad health", rule: OperatorRule{Name: "x", Intent: "noop", When: OperatorRuleConditions{AgentHealth: []string{"healthy"}}}, want: "agent_health"},
+		{name: "bad rate limit", rule: OperatorRule{Name: "x", Intent: "noop", RateLimit: &OperatorRuleRateLimit{Max: 1}}, want: "rate_limit"},
+		{name: "negative count", rule: OperatorRule{Name: "x", Intent: "noop", When: OperatorRuleConditions{DraftPRsMin: -1}}, want: "draft_prs_min"},
+		{name: "duplicate name", rule: OperatorRule{Name: "quiet", Intent: "noop"}, want: "duplicate operator rule"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			invalid := Default()
+			invalid.Operator.Rules = []OperatorRule{{Name: "quiet", Intent: "noop"}, tc.rule}
+			if err := Validate(invalid); err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("expected error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
 func TestValidateRejectsInvalidOperatorBudgets(t *testing.T) {
 	cfg := Default()
 	cfg.Operator.RestartBudget = 0
@@ -0,0 +1,369 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"metawsm/internal/model"
+)
+
+// RunTemplatesDir holds one run template per *.json file, beside the policy
+// file. A file's template is named after the file unless it sets name.
+const RunTemplatesDir = "templates"
+
+// Variables every run template can reference without declaring them. Ticket
+// and workspace in step commands are rendered once per ticket in the run;
+// elsewhere ticket is the run's first ticket.
+const (
+	RunTemplateVarTicket    = "ticket"
+	RunTemplateVarTickets   = "tickets"
+	RunTemplateVarRunID     = "run_id"
+	RunTemplateVarWorkspace = "workspace"
+)
+
+// RunTemplate is a named set of RunSpec defaults for `metawsm run --template`.
+// Explicit run flags win over template values. String fields may reference
+// {{name}} variables; variables maps each declared variable to its default,
+// and an empty default makes the variable required.
+type RunTemplate struct {
+	Name              string                  `json:"name"`
+	Description       string                  `json:"description,omitempty"`
+	Variables         map[string]string       `json:"variables,omitempty"`
+	Repos             []string                `json:"repos,omitempty"`
+	DocHomeRepo       string                  `json:"doc_home_repo,omitempty"`
+	DocAuthorityMode  string                  `json:"doc_authority_mode,omitempty"`
+	DocSeedMode       string                  `json:"doc_seed_mode,omitempty"`
+	BaseBranch        string                  `json:"base_branch,omitempty"`
+	WorkspaceStrategy string                  `json:"workspace_strategy,omitempty"`
+	Agents            []Agent                 `json:"agents,omitempty"`
+	Steps             []model.RunTemplateStep `json:"steps,omitempty"`
+	Brief             *RunTemplateBrief       `json:"brief,omitempty"`
+	// Source is the file the template was read from; it is empty for
+	// templates defined in the policy itself.
+	Source string `json:"-"`
+}
+
+// RunTemplateBrief is the brief skeleton a template fills in for fields the
+// caller leaves empty.
+type RunTemplateBrief struct {
+	Goal         string `json:"goal,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+	DoneCriteria string `json:"done_criteria,omitempty"`
+	Constraints  string `json:"constraints,omitempty"`
+	MergeIntent  string `json:"merge_intent,omitempty"`
+}
+
+var runTemplateVariablePattern = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_\-]+)\s*\}\}`)
+
+// FindRunTemplate returns the template called name.
+func FindRunTemplate(cfg Config, name string) (RunTemplate, bool) {
+	name = strings.TrimSpace(name)
+	for _, template := range cfg.RunTemplates {
+		if strings.TrimSpace(template.Name) == name {
+			return template, true
+		}
+	}
+	return RunTemplate{}, false
+}
+
+// ExpandRunTemplate substitutes variables into every string field of
+// template. vars overrides declared defaults and supplies the built-in
+// variables; ticket and workspace are left in step commands for the planner
+// to render per ticket. Every variable the template references must end up
+// with a non-empty value. Values may not contain {{, so a substituted value
+// is never rendered a second time.
+func ExpandRunTemplate(template RunTemplate, vars map[string]string) (RunTemplate, error) {
+	values := map[string]string{}
+	for name, value := range template.Variables {
+		values[name] = value
+	}
+	for name, value := range vars {
+		name = strings.TrimSpace(name)
+		if _, declared := template.Variables[name]; !declared && !isBuiltinRunTemplateVariable(name, false) {
+			return RunTemplate{}, fmt.Errorf("run template %q does not declare variable %q", template.Name, name)
+		}
+		values[name] = value
+	}
+	for name, value := range values {
+		if strings.Contains(value, "{{") {
+			return RunTemplate{}, fmt.Errorf("run template %q variable %q value cannot contain {{", template.Name, name)
+		}
+	}
+	for _, name := range RunTemplateVariables(template) {
+		if isBuiltinRunTemplateVariable(name, true) && !isBuiltinRunTemplateVariable(name, false) {
+			continue
+		}
+		if strings.TrimSpace(values[name]) == "" {
+			return RunTemplate{}, fmt.Errorf("run template %q requires variable %q", template.Name, name)
+		}
+	}
+
+	render := func(value string) string { return RenderRunTemplateString(value, values) }
+	out := template
+	out.Variables = nil
+	out.Description = render(template.Description)
+	out.Repos = make([]string, 0, len(template.Repos))
+	for _, repo := range template.Repos {
+		out.Repos = append(out.Repos, render(repo))
+	}
+	out.DocHomeRepo = render(template.DocHomeRepo)
+	out.DocAuthorityMode = render(template.DocAuthorityMode)
+	out.DocSeedMode = render(template.DocSeedMode)
+	out.BaseBranch = render(template.BaseBranch)
+	out.WorkspaceStrategy = render(template.WorkspaceStrategy)
+	out.Agents = make([]Agent, 0, len(template.Agents))
+	for _, agent := range template.Agents {
+		out.Agents = append(out.Agents, Agent{Name: render(agent.Name), Profile: render(agent.Profile)})
+	}
+	stepValues := map[string]string{}
+	for name, value := range values {
+		if name != RunTemplateVarTicket && name != RunTemplateVarWorkspace {
+			stepValues[name] = value
+		}
+	}
+	out.Steps = make([]model.RunTemplateStep, 0, len(template.Steps))
+	for _, step := range template.Steps {
+		out.Steps = append(out.Steps, model.RunTemplateStep{
+			Name:    render(step.Name),
+			Command: RenderRunTemplateCommand(step.Command, stepValues),
+		})
+	}
+	if template.Brief != nil {
+		out.Brief = &RunTemplateBrief{
+			Goal:         render(template.Brief.Goal),
+			Scope:        render(template.Brief.Scope),
+			DoneCriteria: render(template.Brief.DoneCriteria),
+			Constraints:  render(template.Brief.Constraints),
+			MergeIntent:  render(template.Brief.MergeIntent),
+		}
+	}
+	return out, nil
+}
+
+// RenderRunTemplateString replaces {{name}} with vars[name]. References to
+// variables missing from vars are left as written.
+func RenderRunTemplateString(value string, vars map[string]string) string {
+	return runTemplateVariablePattern.ReplaceAllStringFunc(value, func(token string) string {
+		name := runTemplateVariablePattern.FindStringSubmatch(token)[1]
+		if replacement, ok := vars[name]; ok {
+			return replacement
+		}
+		return token
+	})
+}
+
+// RenderRunTemplateCommand is RenderRunTemplateString for shell commands:
+// each substituted value is single-quoted, so it reaches the command as one
+// word and cannot inject shell syntax. Template authors should therefore not
+// quote variable references themselves.
+func RenderRunTemplateCommand(value string, vars map[string]string) string {
+	quoted := make(map[string]string, len(vars))
+	for name, replacement := range vars {
+		quoted[name] = "'" + strings.ReplaceAll(replacement, "'", `'"'"'`) + "'"
+	}
+	return RenderRunTemplateString(value, quoted)
+}
+
+// RunTemplateVariables lists the variables template references, sorted.
+func RunTemplateVariables(template RunTemplate) []string {
+	seen := map[string]bool{}
+	for _, value := range runTemplateStrings(template, true) {
+		for _, match := range runTemplateVariablePattern.FindAllStringSubmatch(value, -1) {
+			seen[match[1]] = true
+		}
+	}
+	out := make([]string, 0, len(seen))
+	for name := range seen {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// runTemplateStrings returns the template fields that accept variables;
+// step commands are included only when withSteps is set.
+func runTemplateStrings(template RunTemplate, withSteps bool) []string {
+	values := []string{
+		template.Description,
+		template.DocHomeRepo,
+		template.DocAuthorityMode,
+		template.DocSeedMode,
+		template.BaseBranch,
+		template.WorkspaceStrategy,
+	}
+	values = append(values, template.Repos...)
+	for _, agent := range template.Agents {
+		values = append(values, agent.Name, agent.Profile)
+	}
+	for _, step := range template.Steps {
+		values = append(values, step.Name)
+		if withSteps {
+			values = append(values, step.Command)
+		}
+	}
+	if template.Brief != nil {
+		values = append(values,
+			template.Brief.Goal,
+			template.Brief.Scope,
+			template.Brief.DoneCriteria,
+			template.Brief.Constraints,
+			template.Brief.MergeIntent,
+		)
+	}
+	return values
+}
+
+// isBuiltinRunTemplateVariable reports whether name is supplied by the
+// planner. Workspace is only available in step commands.
+func isBuiltinRunTemplateVariable(name string, inStep bool) bool {
+	switch name {
+	case RunTemplateVarTicket, RunTemplateVarTickets, RunTemplateVarRunID:
+		return true
+	case RunTemplateVarWorkspace:
+		return inStep
+	default:
+		return false
+	}
+}
+
+// loadRunTemplateFiles reads the *.json templates beside policyPath.
+func loadRunTemplateFiles(policyPath string) ([]RunTemplate, error) {
+	dir := filepath.Join(filepath.Dir(policyPath), RunTemplatesDir)
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	out := make([]RunTemplate, 0, len(paths))
+	for _, path := range paths {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read run template %s: %w", path, err)
+		}
+		var template RunTemplate
+		if err := json.Unmarshal(b, &template); err != nil {
+			return nil, fmt.Errorf("parse run template %s: %w", path, err)
+		}
+		if strings.TrimSpace(template.Name) == "" {
+			template.Name = strings.TrimSuffix(filepath.Base(path), ".json")
+		}
+		template.Source = path
+		out = append(out, template)
+	}
+	return out, nil
+}
+
+func validateRunTemplates(cfg Config) error {
+	profiles := map[string]bool{}
+	for _, profile := range cfg.AgentProfiles {
+		profiles[strings.TrimSpace(profile.Name)] = true
+	}
+	agents := map[string]bool{}
+	for _, agent := range cfg.Agents {
+		agents[strings.TrimSpace(agent.Name)] = true
+	}
+	seenNames := map[string]struct{}{}
+	for _, template := range cfg.RunTemplates {
+		name := strings.TrimSpace(template.Name)
+		if name == "" {
+			return fmt.Errorf("run_templates.name cannot be empty")
+		}
+		for _, r := range name {
+			if !(r >= 'a' && r <= 'z') && !(r >= '0' && r <= '9') && r != '-' && r != '_' {
+				return fmt.Errorf("run template name %q must use only a-z, 0-9, - and _", name)
+			}
+		}
+		if _, exists := seenNames[name]; exists {
+			return fmt.Errorf("duplicate run template %q", name)
+		}
+		seenNames[name] = struct{}{}
+
+		for variable := range template.Variables {
+			if !runTemplateVariablePattern.MatchString("{{" + variable + "}}") {
+				return fmt.Errorf("run template %q variable %q must use only letters, digits, - and _", name, variable)
+			}
+			if isBuiltinRunTemplateVariable(variable, true) {
+				return fmt.Errorf("run template %q cannot redeclare built-in variable %q", name, variable)
+			}
+		}
+		for _, value := range runTemplateStrings(template, false) {
+			for _, match := range runTemplateVariablePattern.FindAllStringSubmatch(value, -1) {
+				if _, declared := template.Variables[match[1]]; !declared && !isBuiltinRunTemplateVariable(match[1], false) {
+					return fmt.Errorf("run template %q references undeclared variable %q", name, match[1])
+				}
+			}
+		}
+		for _, step := range template.Steps {
+			for _, match := range runTemplateVariablePattern.FindAllStringSubmatch(step.Command, -1) {
+				if _, declared := template.Variables[match[1]]; !declared && !isBuiltinRunTemplateVariable(match[1], true) {
+					return fmt.Errorf("run template %q step %q references undeclared variable %q", name, step.Name, match[1])
+				}
+			}
+		}
+
+		if strategy := strings.TrimSpace(template.WorkspaceStrategy); strategy != "" && !strings.Contains(strategy, "{{") {
+			switch model.WorkspaceStrategy(strategy) {
+			case model.WorkspaceStrategyCreate, model.WorkspaceStrategyFork, model.WorkspaceStrategyReuse:
+			default:
+				return fmt.Errorf("run template %q workspace_strategy must be create|fork|reuse", name)
+			}
+		}
+		if mode := strings.TrimSpace(template.DocAuthorityMode); mode != "" && !strings.Contains(mode, "{{") && mode != string(model.DocAuthorityModeWorkspaceActive) {
+			return fmt.Errorf("run template %q doc_authority_mode must be %q", name, model.DocAuthorityModeWorkspaceActive)
+		}
+		if mode := strings.TrimSpace(template.DocSeedMode); mode != "" && !strings.Contains(mode, "{{") {
+			switch model.DocSeedMode(mode) {
+			case model.DocSeedModeNone, model.DocSeedModeCopyFromRepoOnStart:
+			default:
+				return fmt.Errorf("run template %q doc_seed_mode must be none|copy_from_repo_on_start", name)
+			}
+		}
+		for _, repo := range template.Repos {
+			if strings.TrimSpace(repo) == "" {
+				return fmt.Errorf("run template %q repos cannot contain empty values", name)
+			}
+		}
+
+		seenAgents := map[string]bool{}
+		for _, agent := range template.Agents {
+			agentName := strings.TrimSpace(agent.Name)
+			if agentName == "" {
+				return fmt.Errorf("run template %q agent name cannot be empty", name)
+			}
+			if seenAgents[agentName] {
+				return fmt.Errorf("run template %q lists agent %q twice", name, agentName)
+			}
+			seenAgents[agentName] = true
+			profile := strings.TrimSpace(agent.Profile)
+			switch {
+			case profile == "":
+				if !agents[agentName] && !strings.Contains(agentName, "{{") {
+					return fmt.Errorf("run template %q agent %q is not in agents; give it a profile", name, agentName)
+				}
+			case !profiles[profile] && !strings.Contains(profile, "{{"):
+				return fmt.Errorf("run template %q agent %q references unknown profile %q", name, agentName, profile)
+			}
+		}
+
+		seenSteps := map[string]bool{}
+		for _, step := range template.Steps {
+			stepName := strings.TrimSpace(step.Name)
+			if stepName == "" {
+				return fmt.Errorf("run template %q step name cannot be empty", name)
+			}
+			if seenSteps[stepName] {
+				return fmt.Errorf("run template %q has duplicate step %q", name, stepName)
+			}
+			seenSteps[stepName] = true
+			if strings.TrimSpace(step.Command) == "" {
+				return fmt.Errorf("run template %q step %q requires a command", name, stepName)
+			}
+		}
+	}
+	return nil
+}
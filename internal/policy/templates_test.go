@@ -0,0 +1,144 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"metawsm/internal/model"
+)
+
+func backendBugfixTemplate() RunTemplate {
+	return RunTemplate{
+		Name:        "backend-bugfix",
+		Description: "Fix a backend bug in {{service}}",
+		Variables:   map[string]string{"service": "", "branch": "main"},
+		Repos:       []string{"{{service}}", "shared"},
+		DocHomeRepo: "{{service}}",
+		BaseBranch:  "{{branch}}",
+		Agents: []Agent{
+			{Name: "agent"},
+			{Name: "reviewer", Profile: "default-shell"},
+		},
+		Steps: []model.RunTemplateStep{
+			{Name: "lint", Command: "make -C {{service}} lint TICKET={{ticket}} WS={{workspace}}"},
+		},
+		Brief: &RunTemplateBrief{Goal: "Fix {{ticket}} in {{service}}"},
+	}
+}
+
+func TestValidateAcceptsRunTemplate(t *testing.T) {
+	cfg := Default()
+	cfg.RunTemplates = []RunTemplate{backendBugfixTemplate()}
+	if err := Validate(cfg); err != nil {
+		t.Fatalf("expected run template to validate: %v", err)
+	}
+}
+
+func TestValidateRejectsInvalidRunTemplates(t *testing.T) {
+	cases := []struct {
+		name   string
+		mutate func(*RunTemplate)
+		want   string
+	}{
+		{name: "bad name", mutate: func(t *RunTemplate) { t.Name = "Backend Bugfix" }, want: "must use only"},
+		{name: "undeclared variable", mutate: func(t *RunTemplate) { t.BaseBranch = "{{release}}" }, want: `undeclared variable "release"`},
+		{name: "workspace outside steps", mutate: func(t *RunTemplate) { t.DocHomeRepo = "{{workspace}}" }, want: `undeclared variable "workspace"`},
+		{name: "builtin redeclared", mutate: func(t *RunTemplate) { t.Variables["ticket"] = "" }, want: "built-in variable"},
+		{name: "strategy", mutate: func(t *RunTemplate) { t.WorkspaceStrategy = "clone" }, want: "workspace_strategy"},
+		{name: "seed mode", mutate: func(t *RunTemplate) { t.DocSeedMode = "always" }, want: "doc_seed_mode"},
+		{name: "unknown agent", mutate: func(t *RunTemplate) { t.Agents = []Agent{{Name: "ghost"}} }, want: `agent "ghost" is not in agents`},
+		{name: "unknown profile", mutate: func(t *RunTemplate) { t.Agents = []Agent{{Name: "ghost", Profile: "missing"}} }, want: `unknown profile "missing"`},
+		{name: "step command", mutate: func(t *RunTemplate) { t.Steps = []model.RunTemplateStep{{Name: "lint"}} }, want: "requires a command"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			template := backendBugfixTemplate()
+			tc.mutate(&template)
+			cfg := Default()
+			cfg.RunTemplates = []RunTemplate{template}
+			err := Validate(cfg)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("expected error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+
+	cfg := Default()
+	cfg.RunTemplates = []RunTemplate{backendBugfixTemplate(), backendBugfixTemplate()}
+	if err := Validate(cfg); err == nil || !strings.Contains(err.Error(), "duplicate run template") {
+		t.Fatalf("expected duplicate run template error, got %v", err)
+	}
+}
+
+func TestExpandRunTemplateSubstitutesVariables(t *testing.T) {
+	template := backendBugfixTemplate()
+	if _, err := ExpandRunTemplate(template, map[string]string{"ticket": "BUG-1"}); err == nil || !strings.Contains(err.Error(), `requires variable "service"`) {
+		t.Fatalf("expected missing required variable error, got %v", err)
+	}
+	if _, err := ExpandRunTemplate(template, map[string]string{"service": "api", "colour": "red"}); err == nil || !strings.Contains(err.Error(), `does not declare variable "colour"`) {
+		t.Fatalf("expected undeclared variable error, got %v", err)
+	}
+
+	expanded, err := ExpandRunTemplate(template, map[string]string{"service": "api", "ticket": "BUG-1"})
+	if err != nil {
+		t.Fatalf("expand: %v", err)
+	}
+	if strings.Join(expanded.Repos, ",") != "api,shared" || expanded.DocHomeRepo != "api" || expanded.BaseBranch != "main" {
+		t.Fatalf("unexpected expanded run defaults %+v", expanded)
+	}
+	if expanded.Brief == nil || expanded.Brief.Goal != "Fix BUG-1 in api" {
+		t.Fatalf("unexpected expanded brief %+v", expanded.Brief)
+	}
+	if got := expanded.Steps[0].Command; got != "make -C 'api' lint TICKET={{ticket}} WS={{workspace}}" {
+		t.Fatalf("expected per-ticket variables to stay in step commands, got %q", got)
+	}
+	if _, err := ExpandRunTemplate(template, map[string]string{"service": "{{ticket}}"}); err == nil || !strings.Contains(err.Error(), "cannot contain {{") {
+		t.Fatalf("expected template syntax in a value to be rejected, got %v", err)
+	}
+	injected, err := ExpandRunTemplate(template, map[string]string{"service": "api; touch /tmp/pwned 'x'", "ticket": "BUG-1"})
+	if err != nil {
+		t.Fatalf("expand with shell syntax in a value: %v", err)
+	}
+	if got := injected.Steps[0].Command; got != `make -C 'api; touch /tmp/pwned '"'"'x'"'"'' lint TICKET={{ticket}} WS={{workspace}}` {
+		t.Fatalf("expected step command values to be shell-quoted, got %q", got)
+	}
+	if got := strings.Join(RunTemplateVariables(template), ","); got != "branch,service,ticket,workspace" {
+		t.Fatalf("unexpected template variables %q", got)
+	}
+}
+
+func TestLoadReadsRunTemplateFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "policy.json")
+	if err := os.MkdirAll(filepath.Join(tmpDir, RunTemplatesDir), 0o755); err != nil {
+		t.Fatalf("mkdir templates: %v", err)
+	}
+	body := `{"description":"Docs only","repos":["docs"],"doc_seed_mode":"none"}`
+	if err := os.WriteFile(filepath.Join(tmpDir, RunTemplatesDir, "docs-only.json"), []byte(body), 0o644); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+
+	cfg, _, err := Load(path)
+	if err != nil {
+		t.Fatalf("load without policy file: %v", err)
+	}
+	template, ok := FindRunTemplate(cfg, "docs-only")
+	if !ok || template.Description != "Docs only" || !strings.HasSuffix(template.Source, "docs-only.json") {
+		t.Fatalf("expected template named after its file, got %+v", cfg.RunTemplates)
+	}
+
+	if err := SaveDefault(path); err != nil {
+		t.Fatalf("save default policy: %v", err)
+	}
+	if _, _, err := Load(path); err != nil {
+		t.Fatalf("load with policy file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, RunTemplatesDir, "broken.json"), []byte(`{"workspace_strategy":"clone"}`), 0o644); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+	if _, _, err := Load(path); err == nil || !strings.Contains(err.Error(), "workspace_strategy") {
+		t.Fatalf("expected invalid template file to fail load, got %v", err)
+	}
+}
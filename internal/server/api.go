@@ -3,7 +3,9 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
@@ -11,29 +13,56 @@ import (
 
 	"metawsm/internal/model"
 	"metawsm/internal/serviceapi"
+	"metawsm/internal/webhook"
 )
 
 func (r *Runtime) registerRoutes(mux *http.ServeMux) {
-	mux.HandleFunc("/api/v1/health", r.handleHealth)
-	mux.HandleFunc("/api/v1/runs", r.handleRuns)
-	mux.HandleFunc("/api/v1/runs/", r.handleRunByID)
-	mux.HandleFunc("/api/v1/forum/threads", r.handleForumThreads)
-	mux.HandleFunc("/api/v1/forum/threads/", r.handleForumThreadAction)
-	mux.HandleFunc("/api/v1/forum/search", r.handleForumSearch)
-	mux.HandleFunc("/api/v1/forum/queues", r.handleForumQueues)
-	mux.HandleFunc("/api/v1/forum/control/signal", r.handleForumControlSignal)
-	mux.HandleFunc("/api/v1/forum/events", r.handleForumEvents)
-	mux.HandleFunc("/api/v1/forum/stats", r.handleForumStats)
-	mux.HandleFunc("/api/v1/forum/debug", r.handleForumDebug)
-	mux.HandleFunc("/api/v1/forum/stream", r.handleForumStream)
+	mux.HandleFunc("/api/v1/health", r.authorize(r.handleHealth))
+	mux.HandleFunc("/api/v1/runs", r.authorize(r.handleRuns))
+	mux.HandleFunc("/api/v1/runs/", r.authorize(r.handleRunByID))
+	mux.HandleFunc("/api/v1/forum/threads", r.authorize(r.handleForumThreads))
+	mux.HandleFunc("/api/v1/forum/threads/", r.authorize(r.handleForumThreadAction))
+	mux.HandleFunc("/api/v1/forum/search", r.authorize(r.handleForumSearch))
+	mux.HandleFunc("/api/v1/forum/queues", r.authorize(r.handleForumQueues))
+	mux.HandleFunc("/api/v1/forum/control/signal", r.authorize(r.handleForumControlSignal))
+	mux.HandleFunc("/api/v1/forum/events", r.authorize(r.handleForumEvents))
+	mux.HandleFunc("/api/v1/forum/stats", r.authorize(r.handleForumStats))
+	mux.HandleFunc("/api/v1/forum/debug", r.authorize(r.handleForumDebug))
+	mux.HandleFunc("/api/v1/forum/outbox", r.authorize(r.handleForumOutbox))
+	mux.HandleFunc("/api/v1/forum/outbox/retry", r.authorize(r.handleForumOutboxRetry))
+	mux.HandleFunc("/api/v1/forum/outbox/purge", r.authorize(r.handleForumOutboxPurge))
+	mux.HandleFunc("/api/v1/forum/projections/rebuild", r.authorize(r.handleForumRebuildProjections))
+	mux.HandleFunc("/api/v1/forum/escalations/run", r.authorize(r.handleForumEscalationsRun))
+	mux.HandleFunc("/api/v1/forum/guidance/library", r.authorize(r.handleGuidanceLibrary))
+	mux.HandleFunc("/api/v1/forum/guidance/library/", r.authorize(r.handleGuidanceLibraryAction))
+	mux.HandleFunc("/api/v1/forum/guidance/suggestions", r.authorize(r.handleGuidanceSuggestions))
+	mux.HandleFunc("/api/v1/operator", r.authorize(r.handleOperator))
+	mux.HandleFunc("/api/v1/operator/", r.authorize(r.handleOperatorAction))
+	mux.HandleFunc("/api/v1/integrations/", r.authorize(r.handleIntegrationIngress))
+	mux.HandleFunc("/api/v1/forum/stream", r.authorize(r.handleForumStream))
+	mux.HandleFunc("/api/v1/forum/stream/stats", r.authorize(r.handleForumStreamStats))
 }
 
 func (r *Runtime) handleRuns(w http.ResponseWriter, req *http.Request) {
-	if req.Method != http.MethodGet {
-		writeAPIError(w, http.StatusMethodNotAllowed, "method_not_allowed", "only GET is supported")
+	switch req.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		r.handleStartRun(w, req)
+		return
+	default:
+		writeAPIError(w, http.StatusMethodNotAllowed, "method_not_allowed", "only GET and POST are supported")
 		return
 	}
 	ticket := strings.TrimSpace(req.URL.Query().Get("ticket"))
+	if ticket != "" && strings.EqualFold(strings.TrimSpace(req.URL.Query().Get("latest")), "true") {
+		runID, err := r.service.ResolveRunID(req.Context(), "", ticket)
+		if err != nil {
+			writeAPIError(w, http.StatusNotFound, "run_not_found", err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"run_id": runID})
+		return
+	}
 	snapshots, err := r.service.ListRunSnapshots(req.Context(), ticket)
 	if err != nil {
 		writeAPIError(w, http.StatusInternalServerError, "list_runs_failed", err.Error())
@@ -42,22 +71,228 @@ func (r *Runtime) handleRuns(w http.ResponseWriter, req *http.Request) {
 	writeJSON(w, http.StatusOK, map[string]any{"runs": snapshots})
 }
 
+// handleStartRun plans a run from a RunSpec-shaped body and answers with the
+// plan while the daemon executes it in the background. Runs always use the
+// daemon's policy; callers cannot point it at another file on the host.
+func (r *Runtime) handleStartRun(w http.ResponseWriter, req *http.Request) {
+	var payload runCreateRequest
+	if err := decodeJSON(req, &payload); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_json", err.Error())
+		return
+	}
+	if strings.TrimSpace(payload.PolicyPath) != "" {
+		writeAPIError(w, http.StatusBadRequest, "policy_path_unsupported", "policy_path cannot be set over the API; the daemon uses its own policy")
+		return
+	}
+	agentNames := make([]string, 0, len(payload.Agents))
+	for _, agent := range payload.Agents {
+		agentNames = append(agentNames, strings.TrimSpace(agent.Name))
+	}
+	result, err := r.service.StartRun(req.Context(), serviceapi.RunOptions{
+		RunID:             strings.TrimSpace(payload.RunID),
+		Tickets:           payload.Tickets,
+		Repos:             payload.Repos,
+		DocRepo:           strings.TrimSpace(payload.DocRepo),
+		DocHomeRepo:       strings.TrimSpace(payload.DocHomeRepo),
+		DocAuthorityMode:  strings.TrimSpace(string(payload.DocAuthorityMode)),
+		DocSeedMode:       strings.TrimSpace(string(payload.DocSeedMode)),
+		BaseBranch:        strings.TrimSpace(payload.BaseBranch),
+		AgentNames:        agentNames,
+		WorkspaceStrategy: model.WorkspaceStrategy(strings.TrimSpace(string(payload.WorkspaceStrategy))),
+		DryRun:            payload.DryRun,
+		Mode:              payload.Mode,
+		RunBrief:          payload.Brief,
+		Template:          strings.TrimSpace(payload.Template),
+		TemplateVars:      payload.TemplateVars,
+	})
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "run_start_failed", err.Error())
+		return
+	}
+	status := http.StatusAccepted
+	if payload.DryRun {
+		status = http.StatusOK
+	}
+	writeJSON(w, status, map[string]any{
+		"run_id":  result.RunID,
+		"dry_run": payload.DryRun,
+		"steps":   result.Steps,
+	})
+}
+
 func (r *Runtime) handleRunByID(w http.ResponseWriter, req *http.Request) {
+	path := strings.Trim(strings.TrimPrefix(req.URL.Path, "/api/v1/runs/"), "/")
+	segments := strings.Split(path, "/")
+	runID := strings.TrimSpace(segments[0])
+	if runID == "" {
+		writeAPIError(w, http.StatusBadRequest, "invalid_run_id", "run id is required")
+		return
+	}
+	if len(segments) == 2 {
+		if req.Method != http.MethodPost {
+			writeAPIError(w, http.StatusMethodNotAllowed, "method_not_allowed", "only POST is supported")
+			return
+		}
+		r.handleRunAction(w, req, runID, strings.TrimSpace(strings.ToLower(segments[1])))
+		return
+	}
 	if req.Method != http.MethodGet {
 		writeAPIError(w, http.StatusMethodNotAllowed, "method_not_allowed", "only GET is supported")
 		return
 	}
-	runID := strings.TrimSpace(strings.TrimPrefix(req.URL.Path, "/api/v1/runs/"))
-	if runID == "" || strings.Contains(runID, "/") {
-		writeAPIError(w, http.StatusBadRequest, "invalid_run_id", "run id is required")
+	if len(segments) == 4 && segments[1] == "agents" && segments[3] == "logs" {
+		r.handleAgentLogs(w, req, runID, strings.TrimSpace(segments[2]))
 		return
 	}
-	snapshot, err := r.service.RunSnapshot(req.Context(), runID)
+	if len(segments) != 1 {
+		writeAPIError(w, http.StatusNotFound, "unknown_action", "unsupported run route")
+		return
+	}
+	report, err := r.service.RunStatusReport(req.Context(), runID)
 	if err != nil {
 		writeAPIError(w, http.StatusNotFound, "run_not_found", err.Error())
 		return
 	}
-	writeJSON(w, http.StatusOK, map[string]any{"run": snapshot})
+	writeJSON(w, http.StatusOK, map[string]any{"run": report.Snapshot(), "status": report})
+}
+
+// handleRunAction applies one lifecycle mutation to a run. Actions with a
+// dry-run mode honour "dry_run" in the body and return the planned actions;
+// stop and resume have none and reject it. Resume answers 202 once the run
+// is running again and continues its steps in the background.
+func (r *Runtime) handleRunAction(w http.ResponseWriter, req *http.Request, runID string, action string) {
+	var payload runActionRequest
+	// An empty body is fine for actions without options (stop, resume).
+	if err := decodeJSON(req, &payload); err != nil && !errors.Is(err, io.EOF) {
+		writeAPIError(w, http.StatusBadRequest, "invalid_json", err.Error())
+		return
+	}
+	if payload.DryRun && (action == "stop" || action == "resume") {
+		writeAPIError(w, http.StatusBadRequest, "dry_run_unsupported", action+" does not support dry_run")
+		return
+	}
+	ctx := req.Context()
+	status := http.StatusOK
+	var (
+		result any
+		err    error
+	)
+	switch action {
+	case "stop":
+		err = r.service.StopRun(ctx, runID)
+	case "resume":
+		err = r.service.StartResumeRun(ctx, runID)
+		status = http.StatusAccepted
+	case "restart":
+		result, err = r.service.RestartRun(ctx, serviceapi.RestartOptions{RunID: runID, DryRun: payload.DryRun})
+	case "iterate":
+		result, err = r.service.IterateRun(ctx, serviceapi.IterateOptions{
+			RunID:    runID,
+			Feedback: strings.TrimSpace(payload.Feedback),
+			DryRun:   payload.DryRun,
+		})
+	case "commit":
+		result, err = r.service.CommitRun(ctx, serviceapi.CommitOptions{
+			RunID:   runID,
+			Message: strings.TrimSpace(payload.Message),
+			Actor:   requestActorName(req, payload.Actor),
+			DryRun:  payload.DryRun,
+		})
+	case "pr":
+		result, err = r.service.OpenPullRequests(ctx, serviceapi.PullRequestOptions{
+			RunID:  runID,
+			Title:  strings.TrimSpace(payload.Title),
+			Body:   strings.TrimSpace(payload.Body),
+			Actor:  requestActorName(req, payload.Actor),
+			DryRun: payload.DryRun,
+		})
+	case "merge":
+		result, err = r.service.MergeRun(ctx, serviceapi.MergeOptions{RunID: runID, DryRun: payload.DryRun})
+	case "close":
+		err = r.service.CloseRun(ctx, serviceapi.CloseOptions{
+			RunID:          runID,
+			DryRun:         payload.DryRun,
+			ChangelogEntry: strings.TrimSpace(payload.ChangelogEntry),
+		})
+	case "cleanup":
+		deleteWorkspaces := true
+		if payload.DeleteWorkspaces != nil {
+			deleteWorkspaces = *payload.DeleteWorkspaces
+		}
+		result, err = r.service.CleanupRun(ctx, serviceapi.CleanupOptions{
+			RunID:            runID,
+			DryRun:           payload.DryRun,
+			DeleteWorkspaces: deleteWorkspaces,
+		})
+	default:
+		writeAPIError(w, http.StatusNotFound, "unknown_action", "unsupported run action")
+		return
+	}
+	if err != nil {
+		var inProgress *serviceapi.RunMutationInProgressError
+		if errors.As(err, &inProgress) {
+			writeAPIError(w, http.StatusConflict, "run_mutation_in_progress", err.Error())
+			return
+		}
+		if errors.Is(err, serviceapi.ErrRunNotFound) {
+			writeAPIError(w, http.StatusNotFound, "run_not_found", err.Error())
+			return
+		}
+		writeAPIError(w, http.StatusBadRequest, "run_"+action+"_failed", err.Error())
+		return
+	}
+	response := map[string]any{
+		"run_id":  runID,
+		"action":  action,
+		"dry_run": payload.DryRun,
+	}
+	if result != nil {
+		response["result"] = result
+	}
+	writeJSON(w, status, response)
+}
+
+// handleAgentLogs serves one slice of an agent transcript, or tails it over a
+// WebSocket when the request asks for an upgrade.
+func (r *Runtime) handleAgentLogs(w http.ResponseWriter, req *http.Request, runID string, agentName string) {
+	if agentName == "" {
+		writeAPIError(w, http.StatusBadRequest, "invalid_agent", "agent name is required")
+		return
+	}
+	query := req.URL.Query()
+	offset, err := parseInt64Query(query.Get("offset"), -1)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_offset", err.Error())
+		return
+	}
+	limit, err := parseInt64Query(query.Get("limit"), 0)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_limit", err.Error())
+		return
+	}
+	generation, err := parseInt64Query(query.Get("generation"), -1)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_generation", err.Error())
+		return
+	}
+	options := serviceapi.AgentTranscriptReadOptions{
+		RunID:         runID,
+		AgentName:     agentName,
+		WorkspaceName: strings.TrimSpace(query.Get("workspace")),
+		Offset:        offset,
+		Limit:         limit,
+		Generation:    int(generation),
+	}
+	if strings.EqualFold(strings.TrimSpace(req.Header.Get("Upgrade")), "websocket") {
+		r.handleAgentLogStream(w, req, options)
+		return
+	}
+	chunk, err := r.service.ReadAgentTranscript(req.Context(), options)
+	if err != nil {
+		writeAPIError(w, http.StatusNotFound, "agent_log_not_found", err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"log": chunk})
 }
 
 func (r *Runtime) handleForumThreads(w http.ResponseWriter, req *http.Request) {
@@ -80,6 +315,7 @@ func (r *Runtime) handleForumThreads(w http.ResponseWriter, req *http.Request) {
 			writeAPIError(w, http.StatusBadRequest, "invalid_json", err.Error())
 			return
 		}
+		actorType, actorName := requestActor(req, payload.ActorType, payload.ActorName)
 		thread, err := r.service.ForumOpenThread(req.Context(), serviceapi.ForumOpenThreadOptions{
 			ThreadID:      strings.TrimSpace(payload.ThreadID),
 			Ticket:        strings.TrimSpace(payload.Ticket),
@@ -88,11 +324,17 @@ func (r *Runtime) handleForumThreads(w http.ResponseWriter, req *http.Request) {
 			Title:         strings.TrimSpace(payload.Title),
 			Body:          strings.TrimSpace(payload.Body),
 			Priority:      model.ForumPriority(strings.TrimSpace(payload.Priority)),
-			ActorType:     model.ForumActorType(strings.TrimSpace(payload.ActorType)),
-			ActorName:     strings.TrimSpace(payload.ActorName),
+			Attachments:   payload.Attachments,
+			ActorType:     actorType,
+			ActorName:     actorName,
 			CorrelationID: strings.TrimSpace(payload.CorrelationID),
 			CausationID:   strings.TrimSpace(payload.CausationID),
+			Credential:    requestCredential(req),
 		})
+		if errors.Is(err, serviceapi.ErrForumWriteOutOfScope) {
+			writeAPIError(w, http.StatusForbidden, "forbidden", err.Error())
+			return
+		}
 		if err != nil {
 			writeAPIError(w, http.StatusBadRequest, "forum_open_failed", err.Error())
 			return
@@ -146,14 +388,21 @@ func (r *Runtime) handleForumThreadAction(w http.ResponseWriter, req *http.Reque
 			writeAPIError(w, http.StatusBadRequest, "invalid_json", err.Error())
 			return
 		}
+		actorType, actorName := requestActor(req, payload.ActorType, payload.ActorName)
 		thread, err := r.service.ForumAddPost(req.Context(), serviceapi.ForumAddPostOptions{
 			ThreadID:      threadID,
 			Body:          strings.TrimSpace(payload.Body),
-			ActorType:     model.ForumActorType(strings.TrimSpace(payload.ActorType)),
-			ActorName:     strings.TrimSpace(payload.ActorName),
+			Attachments:   payload.Attachments,
+			ActorType:     actorType,
+			ActorName:     actorName,
 			CorrelationID: strings.TrimSpace(payload.CorrelationID),
 			CausationID:   strings.TrimSpace(payload.CausationID),
+			Credential:    requestCredential(req),
 		})
+		if errors.Is(err, serviceapi.ErrForumWriteOutOfScope) {
+			writeAPIError(w, http.StatusForbidden, "forbidden", err.Error())
+			return
+		}
 		if err != nil {
 			writeAPIError(w, http.StatusBadRequest, "forum_add_post_failed", err.Error())
 			return
@@ -165,13 +414,14 @@ func (r *Runtime) handleForumThreadAction(w http.ResponseWriter, req *http.Reque
 			writeAPIError(w, http.StatusBadRequest, "invalid_json", err.Error())
 			return
 		}
+		actorType, actorName := requestActor(req, payload.ActorType, payload.ActorName)
 		thread, err := r.service.ForumAssignThread(req.Context(), serviceapi.ForumAssignThreadOptions{
 			ThreadID:       threadID,
 			AssigneeType:   model.ForumActorType(strings.TrimSpace(payload.AssigneeType)),
 			AssigneeName:   strings.TrimSpace(payload.AssigneeName),
 			AssignmentNote: strings.TrimSpace(payload.AssignmentNote),
-			ActorType:      model.ForumActorType(strings.TrimSpace(payload.ActorType)),
-			ActorName:      strings.TrimSpace(payload.ActorName),
+			ActorType:      actorType,
+			ActorName:      actorName,
 			CorrelationID:  strings.TrimSpace(payload.CorrelationID),
 			CausationID:    strings.TrimSpace(payload.CausationID),
 		})
@@ -186,11 +436,12 @@ func (r *Runtime) handleForumThreadAction(w http.ResponseWriter, req *http.Reque
 			writeAPIError(w, http.StatusBadRequest, "invalid_json", err.Error())
 			return
 		}
+		actorType, actorName := requestActor(req, payload.ActorType, payload.ActorName)
 		thread, err := r.service.ForumChangeState(req.Context(), serviceapi.ForumChangeStateOptions{
 			ThreadID:      threadID,
 			ToState:       model.ForumThreadState(strings.TrimSpace(payload.ToState)),
-			ActorType:     model.ForumActorType(strings.TrimSpace(payload.ActorType)),
-			ActorName:     strings.TrimSpace(payload.ActorName),
+			ActorType:     actorType,
+			ActorName:     actorName,
 			CorrelationID: strings.TrimSpace(payload.CorrelationID),
 			CausationID:   strings.TrimSpace(payload.CausationID),
 		})
@@ -205,11 +456,12 @@ func (r *Runtime) handleForumThreadAction(w http.ResponseWriter, req *http.Reque
 			writeAPIError(w, http.StatusBadRequest, "invalid_json", err.Error())
 			return
 		}
+		actorType, actorName := requestActor(req, payload.ActorType, payload.ActorName)
 		thread, err := r.service.ForumSetPriority(req.Context(), serviceapi.ForumSetPriorityOptions{
 			ThreadID:      threadID,
 			Priority:      model.ForumPriority(strings.TrimSpace(payload.Priority)),
-			ActorType:     model.ForumActorType(strings.TrimSpace(payload.ActorType)),
-			ActorName:     strings.TrimSpace(payload.ActorName),
+			ActorType:     actorType,
+			ActorName:     actorName,
 			CorrelationID: strings.TrimSpace(payload.CorrelationID),
 			CausationID:   strings.TrimSpace(payload.CausationID),
 		})
@@ -224,10 +476,11 @@ func (r *Runtime) handleForumThreadAction(w http.ResponseWriter, req *http.Reque
 			writeAPIError(w, http.StatusBadRequest, "invalid_json", err.Error())
 			return
 		}
+		actorType, actorName := requestActor(req, payload.ActorType, payload.ActorName)
 		thread, err := r.service.ForumCloseThread(req.Context(), serviceapi.ForumChangeStateOptions{
 			ThreadID:      threadID,
-			ActorType:     model.ForumActorType(strings.TrimSpace(payload.ActorType)),
-			ActorName:     strings.TrimSpace(payload.ActorName),
+			ActorType:     actorType,
+			ActorName:     actorName,
 			CorrelationID: strings.TrimSpace(payload.CorrelationID),
 			CausationID:   strings.TrimSpace(payload.CausationID),
 		})
@@ -304,16 +557,23 @@ func (r *Runtime) handleForumControlSignal(w http.ResponseWriter, req *http.Requ
 		writeAPIError(w, http.StatusBadRequest, "invalid_json", err.Error())
 		return
 	}
-	thread, err := r.service.ForumAppendControlSignal(req.Context(), serviceapi.ForumControlSignalOptions{
+	actorType, actorName := requestActor(req, payload.ActorType, payload.ActorName)
+	options := serviceapi.ForumControlSignalOptions{
 		RunID:         strings.TrimSpace(payload.RunID),
 		Ticket:        strings.TrimSpace(payload.Ticket),
 		AgentName:     strings.TrimSpace(payload.AgentName),
-		ActorType:     model.ForumActorType(strings.TrimSpace(payload.ActorType)),
-		ActorName:     strings.TrimSpace(payload.ActorName),
+		ActorType:     actorType,
+		ActorName:     actorName,
 		CorrelationID: strings.TrimSpace(payload.CorrelationID),
 		CausationID:   strings.TrimSpace(payload.CausationID),
 		Payload:       payload.Payload,
-	})
+		Credential:    requestCredential(req),
+	}
+	thread, err := r.service.ForumAppendControlSignal(req.Context(), options)
+	if errors.Is(err, serviceapi.ErrControlSignalOutOfScope) {
+		writeAPIError(w, http.StatusForbidden, "forbidden", err.Error())
+		return
+	}
 	if err != nil {
 		writeAPIError(w, http.StatusBadRequest, "forum_control_signal_failed", err.Error())
 		return
@@ -395,6 +655,363 @@ func (r *Runtime) handleForumDebug(w http.ResponseWriter, req *http.Request) {
 	})
 }
 
+func (r *Runtime) handleForumOutbox(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		writeAPIError(w, http.StatusMethodNotAllowed, "method_not_allowed", "only GET is supported")
+		return
+	}
+	query := req.URL.Query()
+	limit, err := parseIntQuery(query.Get("limit"), 50)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_limit", err.Error())
+		return
+	}
+	messages, err := r.service.ForumListOutbox(req.Context(), serviceapi.ForumOutboxListOptions{
+		Status: model.ForumOutboxStatus(strings.TrimSpace(query.Get("status"))),
+		Limit:  limit,
+	})
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "forum_outbox_list_failed", err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"messages": messages})
+}
+
+// handleForumOutboxRetry requeues dead letters so operators can replay them
+// once the underlying failure is fixed.
+func (r *Runtime) handleForumOutboxRetry(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		writeAPIError(w, http.StatusMethodNotAllowed, "method_not_allowed", "only POST is supported")
+		return
+	}
+	var payload forumOutboxRetryRequest
+	if err := decodeJSON(req, &payload); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_json", err.Error())
+		return
+	}
+	requeued, err := r.service.ForumRetryOutbox(req.Context(), serviceapi.ForumOutboxRetryOptions{
+		MessageIDs: payload.MessageIDs,
+		All:        payload.All,
+	})
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "forum_outbox_retry_failed", err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"requeued": requeued})
+}
+
+func (r *Runtime) handleForumOutboxPurge(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		writeAPIError(w, http.StatusMethodNotAllowed, "method_not_allowed", "only POST is supported")
+		return
+	}
+	var payload forumOutboxPurgeRequest
+	if err := decodeJSON(req, &payload); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_json", err.Error())
+		return
+	}
+	if payload.OlderThanSeconds < 0 {
+		writeAPIError(w, http.StatusBadRequest, "invalid_older_than", "older_than_seconds must be >= 0")
+		return
+	}
+	purged, err := r.service.ForumPurgeOutbox(req.Context(), serviceapi.ForumOutboxPurgeOptions{
+		Status:    model.ForumOutboxStatus(strings.TrimSpace(payload.Status)),
+		OlderThan: time.Duration(payload.OlderThanSeconds) * time.Second,
+	})
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "forum_outbox_purge_failed", err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"purged": purged})
+}
+
+func (r *Runtime) handleForumRebuildProjections(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		writeAPIError(w, http.StatusMethodNotAllowed, "method_not_allowed", "only POST is supported")
+		return
+	}
+	var payload forumRebuildProjectionsRequest
+	if err := decodeJSON(req, &payload); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_json", err.Error())
+		return
+	}
+	report, err := r.service.ForumRebuildProjections(req.Context(), serviceapi.ForumRebuildProjectionsOptions{
+		Ticket:      strings.TrimSpace(payload.Ticket),
+		Projections: payload.Projections,
+	})
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "forum_rebuild_projections_failed", err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"report": report})
+}
+
+func (r *Runtime) handleForumEscalationsRun(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		writeAPIError(w, http.StatusMethodNotAllowed, "method_not_allowed", "only POST is supported")
+		return
+	}
+	var payload forumEscalationsRunRequest
+	if err := decodeJSON(req, &payload); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_json", err.Error())
+		return
+	}
+	options := serviceapi.ForumEscalationPassOptions{}
+	if now := strings.TrimSpace(payload.Now); now != "" {
+		parsed, err := time.Parse(time.RFC3339, now)
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, "invalid_now", "now must be an RFC3339 timestamp")
+			return
+		}
+		options.Now = parsed
+	}
+	result, err := r.service.ForumEscalateOverdueThreads(req.Context(), options)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "forum_escalation_failed", err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"result": result})
+}
+
+func (r *Runtime) handleGuidanceLibrary(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		query := req.URL.Query()
+		limit, err := parseIntQuery(query.Get("limit"), 100)
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, "invalid_filter", err.Error())
+			return
+		}
+		answers, err := r.service.GuidanceLibraryList(model.GuidanceAnswerFilter{
+			ScopeType: model.GuidanceAnswerScope(strings.TrimSpace(query.Get("scope_type"))),
+			Scope:     strings.TrimSpace(query.Get("scope")),
+			Ticket:    strings.TrimSpace(query.Get("ticket")),
+			Limit:     limit,
+		})
+		if err != nil {
+			writeAPIError(w, http.StatusInternalServerError, "guidance_library_failed", err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"answers": answers})
+	case http.MethodPost:
+		var payload guidancePromoteRequest
+		if err := decodeJSON(req, &payload); err != nil {
+			writeAPIError(w, http.StatusBadRequest, "invalid_json", err.Error())
+			return
+		}
+		answer, err := r.service.GuidanceLibraryPromote(req.Context(), serviceapi.GuidanceLibraryPromoteOptions{
+			AnswerID:  strings.TrimSpace(payload.AnswerID),
+			ScopeType: model.GuidanceAnswerScope(strings.TrimSpace(payload.ScopeType)),
+			Scope:     strings.TrimSpace(payload.Scope),
+			Question:  strings.TrimSpace(payload.Question),
+			Context:   strings.TrimSpace(payload.Context),
+			Answer:    strings.TrimSpace(payload.Answer),
+			ActorName: requestActorName(req, payload.ActorName),
+		})
+		if errors.Is(err, serviceapi.ErrGuidanceAnswerNotFound) {
+			writeAPIError(w, http.StatusNotFound, "not_found", err.Error())
+			return
+		}
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, "guidance_promote_failed", err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"answer": answer})
+	default:
+		writeAPIError(w, http.StatusMethodNotAllowed, "method_not_allowed", "only GET and POST are supported")
+	}
+}
+
+// handleGuidanceLibraryAction serves POST .../library/sync and
+// POST .../library/{answer_id}/remove.
+func (r *Runtime) handleGuidanceLibraryAction(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		writeAPIError(w, http.StatusMethodNotAllowed, "method_not_allowed", "only POST is supported")
+		return
+	}
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(req.URL.Path, "/api/v1/forum/guidance/library/"), "/"), "/")
+	switch {
+	case len(parts) == 1 && parts[0] == "sync":
+		added, err := r.service.GuidanceLibrarySync(req.Context())
+		if err != nil {
+			writeAPIError(w, http.StatusInternalServerError, "guidance_sync_failed", err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"added": added})
+	case len(parts) == 2 && parts[0] != "" && parts[1] == "remove":
+		err := r.service.GuidanceLibraryRemove(req.Context(), parts[0])
+		if errors.Is(err, serviceapi.ErrGuidanceAnswerNotFound) {
+			writeAPIError(w, http.StatusNotFound, "not_found", err.Error())
+			return
+		}
+		if err != nil {
+			writeAPIError(w, http.StatusInternalServerError, "guidance_remove_failed", err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"removed": parts[0]})
+	default:
+		writeAPIError(w, http.StatusNotFound, "unknown_action", "unsupported guidance library action")
+	}
+}
+
+func (r *Runtime) handleGuidanceSuggestions(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		writeAPIError(w, http.StatusMethodNotAllowed, "method_not_allowed", "only GET is supported")
+		return
+	}
+	query := req.URL.Query()
+	limit, err := parseIntQuery(query.Get("limit"), 0)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_filter", err.Error())
+		return
+	}
+	repos := []string{}
+	for _, repo := range strings.Split(query.Get("repos"), ",") {
+		if repo = strings.TrimSpace(repo); repo != "" {
+			repos = append(repos, repo)
+		}
+	}
+	suggestions, err := r.service.GuidanceSuggest(serviceapi.GuidanceSuggestOptions{
+		RunID:     strings.TrimSpace(query.Get("run_id")),
+		AgentName: strings.TrimSpace(query.Get("agent")),
+		Ticket:    strings.TrimSpace(query.Get("ticket")),
+		Repos:     repos,
+		Question:  strings.TrimSpace(query.Get("question")),
+		Limit:     limit,
+	})
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "guidance_suggest_failed", err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"suggestions": suggestions})
+}
+
+func (r *Runtime) handleOperator(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		writeAPIError(w, http.StatusMethodNotAllowed, "method_not_allowed", "only GET is supported")
+		return
+	}
+	query := req.URL.Query()
+	limit, err := parseIntQuery(query.Get("limit"), 0)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_filter", err.Error())
+		return
+	}
+	runs, err := r.service.OperatorDecisions(req.Context(), strings.TrimSpace(query.Get("run_id")), limit)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "operator_decisions_failed", err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"supervisor": r.operator.Snapshot(), "runs": runs})
+}
+
+func (r *Runtime) handleOperatorAction(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		writeAPIError(w, http.StatusMethodNotAllowed, "method_not_allowed", "only POST is supported")
+		return
+	}
+	if r.operator == nil {
+		writeAPIError(w, http.StatusServiceUnavailable, "operator_unavailable", "operator supervisor is not running")
+		return
+	}
+	action := strings.Trim(strings.TrimPrefix(req.URL.Path, "/api/v1/operator/"), "/")
+	switch action {
+	case "pause":
+		writeJSON(w, http.StatusOK, map[string]any{"supervisor": r.operator.Pause()})
+	case "resume":
+		writeJSON(w, http.StatusOK, map[string]any{"supervisor": r.operator.Resume()})
+	case "llm-mode":
+		var payload operatorLLMModeRequest
+		if err := decodeJSON(req, &payload); err != nil {
+			writeAPIError(w, http.StatusBadRequest, "invalid_json", err.Error())
+			return
+		}
+		snapshot, err := r.operator.SetLLMMode(payload.Mode)
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, "invalid_llm_mode", err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"supervisor": snapshot})
+	default:
+		r.handleNotFound(w, req)
+	}
+}
+
+// maxIntegrationPayloadBytes caps inbound integration payloads.
+const maxIntegrationPayloadBytes = 1 << 20
+
+// handleIntegrationIngress accepts external payloads for a configured
+// integration source. The route skips API tokens; the service authenticates
+// the request against the source's own secret.
+func (r *Runtime) handleIntegrationIngress(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		writeAPIError(w, http.StatusMethodNotAllowed, "method_not_allowed", "only POST is supported")
+		return
+	}
+	name := strings.Trim(strings.TrimPrefix(req.URL.Path, "/api/v1/integrations/"), "/")
+	if name == "" || strings.Contains(name, "/") {
+		writeAPIError(w, http.StatusNotFound, "not_found", "integration source is required")
+		return
+	}
+	if req.Body == nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_json", "request body is required")
+		return
+	}
+	defer req.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(req.Body, maxIntegrationPayloadBytes+1))
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_body", err.Error())
+		return
+	}
+	if len(body) > maxIntegrationPayloadBytes {
+		writeAPIError(w, http.StatusRequestEntityTooLarge, "payload_too_large", "integration payload exceeds 1 MiB")
+		return
+	}
+	if !json.Valid(body) {
+		writeAPIError(w, http.StatusBadRequest, "invalid_json", "integration payload must be JSON")
+		return
+	}
+	result, err := r.service.IngestIntegration(req.Context(), serviceapi.IntegrationIngestOptions{
+		Source:     name,
+		Body:       body,
+		Token:      requestToken(req),
+		Signature:  req.Header.Get(webhook.SignatureHeader),
+		Timestamp:  req.Header.Get(webhook.TimestampHeader),
+		DeliveryID: req.Header.Get(webhook.DeliveryHeader),
+	})
+	switch {
+	case errors.Is(err, serviceapi.ErrIntegrationSourceNotFound):
+		writeAPIError(w, http.StatusNotFound, "not_found", err.Error())
+		return
+	case errors.Is(err, serviceapi.ErrIntegrationUnauthorized):
+		writeAPIError(w, http.StatusUnauthorized, "unauthorized", err.Error())
+		return
+	case err != nil:
+		writeAPIError(w, http.StatusUnprocessableEntity, "integration_failed", err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"result": result})
+}
+
+// handleForumStreamStats reports per-subscriber fan-out and backpressure for
+// open WebSocket and SSE streams.
+func (r *Runtime) handleForumStreamStats(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		writeAPIError(w, http.StatusMethodNotAllowed, "method_not_allowed", "only GET is supported")
+		return
+	}
+	summary := ForumStreamSummary{}
+	subscribers := []ForumSubscriberStats{}
+	if r.eventBroker != nil {
+		summary = r.eventBroker.Summary()
+		subscribers = r.eventBroker.Stats()
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"summary":     summary,
+		"subscribers": subscribers,
+	})
+}
+
 func parseForumThreadFilter(req *http.Request) (model.ForumThreadFilter, error) {
 	query := req.URL.Query()
 	limit, err := parseIntQuery(query.Get("limit"), 50)
@@ -428,6 +1045,8 @@ func parseForumSearchOptions(req *http.Request) (serviceapi.ForumSearchThreadsOp
 		State:      model.ForumThreadState(strings.TrimSpace(query.Get("state"))),
 		Priority:   model.ForumPriority(strings.TrimSpace(query.Get("priority"))),
 		Assignee:   strings.TrimSpace(query.Get("assignee")),
+		ActorType:  model.ForumActorType(strings.TrimSpace(query.Get("actor_type"))),
+		ActorName:  strings.TrimSpace(query.Get("actor")),
 		ViewerType: model.ForumViewerType(strings.TrimSpace(query.Get("viewer_type"))),
 		ViewerID:   strings.TrimSpace(query.Get("viewer_id")),
 		Limit:      limit,
@@ -510,26 +1129,48 @@ func writeAPIError(w http.ResponseWriter, status int, code string, message strin
 	})
 }
 
+// runCreateRequest is a model.RunSpec plus an optional bootstrap brief and run
+// template variables. Agents are resolved by name against the daemon's policy;
+// other agent fields are ignored.
+type runCreateRequest struct {
+	model.RunSpec
+	Brief        *model.RunBrief   `json:"brief"`
+	TemplateVars map[string]string `json:"template_vars"`
+}
+
+type runActionRequest struct {
+	DryRun           bool   `json:"dry_run"`
+	Feedback         string `json:"feedback"`
+	Message          string `json:"message"`
+	Actor            string `json:"actor"`
+	Title            string `json:"title"`
+	Body             string `json:"body"`
+	ChangelogEntry   string `json:"changelog_entry"`
+	DeleteWorkspaces *bool  `json:"delete_workspaces"`
+}
+
 type forumOpenThreadRequest struct {
-	ThreadID      string `json:"thread_id"`
-	Ticket        string `json:"ticket"`
-	RunID         string `json:"run_id"`
-	AgentName     string `json:"agent_name"`
-	Title         string `json:"title"`
-	Body          string `json:"body"`
-	Priority      string `json:"priority"`
-	ActorType     string `json:"actor_type"`
-	ActorName     string `json:"actor_name"`
-	CorrelationID string `json:"correlation_id"`
-	CausationID   string `json:"causation_id"`
+	ThreadID      string                  `json:"thread_id"`
+	Ticket        string                  `json:"ticket"`
+	RunID         string                  `json:"run_id"`
+	AgentName     string                  `json:"agent_name"`
+	Title         string                  `json:"title"`
+	Body          string                  `json:"body"`
+	Priority      string                  `json:"priority"`
+	Attachments   []model.ForumAttachment `json:"attachments"`
+	ActorType     string                  `json:"actor_type"`
+	ActorName     string                  `json:"actor_name"`
+	CorrelationID string                  `json:"correlation_id"`
+	CausationID   string                  `json:"causation_id"`
 }
 
 type forumAddPostRequest struct {
-	Body          string `json:"body"`
-	ActorType     string `json:"actor_type"`
-	ActorName     string `json:"actor_name"`
-	CorrelationID string `json:"correlation_id"`
-	CausationID   string `json:"causation_id"`
+	Body          string                  `json:"body"`
+	Attachments   []model.ForumAttachment `json:"attachments"`
+	ActorType     string                  `json:"actor_type"`
+	ActorName     string                  `json:"actor_name"`
+	CorrelationID string                  `json:"correlation_id"`
+	CausationID   string                  `json:"causation_id"`
 }
 
 type forumAssignThreadRequest struct {
@@ -576,6 +1217,41 @@ type forumControlSignalRequest struct {
 	Payload       model.ForumControlPayloadV1 `json:"payload"`
 }
 
+type forumOutboxRetryRequest struct {
+	MessageIDs []string `json:"message_ids"`
+	All        bool     `json:"all"`
+}
+
+type forumOutboxPurgeRequest struct {
+	Status           string `json:"status"`
+	OlderThanSeconds int64  `json:"older_than_seconds"`
+}
+
+type forumRebuildProjectionsRequest struct {
+	Ticket      string   `json:"ticket"`
+	Projections []string `json:"projections"`
+}
+
+type guidancePromoteRequest struct {
+	AnswerID  string `json:"answer_id"`
+	ScopeType string `json:"scope_type"`
+	Scope     string `json:"scope"`
+	Question  string `json:"question"`
+	Context   string `json:"context"`
+	Answer    string `json:"answer"`
+	ActorName string `json:"actor_name"`
+}
+
+type forumEscalationsRunRequest struct {
+	Now string `json:"now"`
+}
+
+// operatorLLMModeRequest sets the daemon operator's LLM mode; an empty mode
+// falls back to operator.llm.mode from policy.
+type operatorLLMModeRequest struct {
+	Mode string `json:"mode"`
+}
+
 type forumMarkSeenRequest struct {
 	ViewerType            string `json:"viewer_type"`
 	ViewerID              string `json:"viewer_id"`
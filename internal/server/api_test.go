@@ -11,6 +11,7 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -51,6 +52,356 @@ func TestHandleRuns(t *testing.T) {
 	}
 }
 
+func TestRemoteCoreStartRunPostsRunSpec(t *testing.T) {
+	core := &mockCore{
+		startRunFn: func(_ context.Context, options serviceapi.RunOptions) (serviceapi.RunResult, error) {
+			if len(options.Tickets) != 1 || options.Tickets[0] != "METAWSM-011" {
+				t.Fatalf("unexpected tickets: %#v", options.Tickets)
+			}
+			if len(options.AgentNames) != 1 || options.AgentNames[0] != "coder" {
+				t.Fatalf("expected agent names from run spec agents, got %#v", options.AgentNames)
+			}
+			if options.Mode != model.RunModeBootstrap || options.RunBrief == nil || options.RunBrief.Goal != "ship" {
+				t.Fatalf("expected bootstrap mode with brief, got %#v", options)
+			}
+			return serviceapi.RunResult{
+				RunID: "run-9",
+				Steps: []model.PlanStep{{Index: 1, Name: "verify-ticket", Kind: "shell", Status: model.StepStatusPending}},
+			}, nil
+		},
+	}
+	runtime := newTestRuntime(core)
+	mux := http.NewServeMux()
+	runtime.registerRoutes(mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	remote := serviceapi.NewRemoteCore(server.URL, time.Second)
+	result, err := remote.StartRun(context.Background(), serviceapi.RunOptions{
+		Tickets:    []string{"METAWSM-011"},
+		Repos:      []string{"metawsm"},
+		AgentNames: []string{"coder"},
+		Mode:       model.RunModeBootstrap,
+		RunBrief:   &model.RunBrief{Ticket: "METAWSM-011", Goal: "ship"},
+	})
+	if err != nil {
+		t.Fatalf("remote start run: %v", err)
+	}
+	if result.RunID != "run-9" || len(result.Steps) != 1 || result.Steps[0].Name != "verify-ticket" {
+		t.Fatalf("unexpected start result: %#v", result)
+	}
+}
+
+func TestHandleStartRunRejectsPolicyPath(t *testing.T) {
+	core := &mockCore{
+		startRunFn: func(_ context.Context, options serviceapi.RunOptions) (serviceapi.RunResult, error) {
+			t.Fatalf("expected run start to be rejected, got %#v", options)
+			return serviceapi.RunResult{}, nil
+		},
+	}
+	runtime := newTestRuntime(core)
+	mux := http.NewServeMux()
+	runtime.registerRoutes(mux)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/runs", strings.NewReader(`{"tickets":["METAWSM-011"],"repos":["metawsm"],"policy_path":"/etc/other-policy.json"}`))
+	response := httptest.NewRecorder()
+	mux.ServeHTTP(response, request)
+	if response.Code != http.StatusBadRequest || !strings.Contains(response.Body.String(), "policy_path_unsupported") {
+		t.Fatalf("expected 400 policy_path_unsupported, got %d: %s", response.Code, response.Body.String())
+	}
+}
+
+func TestHandleRunActionCommitReturnsStructuredResult(t *testing.T) {
+	core := &mockCore{
+		commitRunFn: func(_ context.Context, options serviceapi.CommitOptions) (serviceapi.CommitResult, error) {
+			if options.RunID != "run-1" || !options.DryRun || options.Message != "ship it" {
+				t.Fatalf("unexpected commit options: %#v", options)
+			}
+			return serviceapi.CommitResult{
+				RunID: "run-1",
+				Repos: []serviceapi.CommitRepoResult{{Repo: "metawsm", Branch: "METAWSM-011/run-1", Actions: []string{"git commit"}}},
+			}, nil
+		},
+	}
+	runtime := newTestRuntime(core)
+	mux := http.NewServeMux()
+	runtime.registerRoutes(mux)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/runs/run-1/commit", strings.NewReader(`{"message":"ship it","dry_run":true}`))
+	response := httptest.NewRecorder()
+	mux.ServeHTTP(response, request)
+	if response.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", response.Code, response.Body.String())
+	}
+	var payload struct {
+		RunID  string                  `json:"run_id"`
+		DryRun bool                    `json:"dry_run"`
+		Result serviceapi.CommitResult `json:"result"`
+	}
+	if err := json.Unmarshal(response.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal commit result: %v", err)
+	}
+	if payload.RunID != "run-1" || !payload.DryRun {
+		t.Fatalf("unexpected action envelope: %#v", payload)
+	}
+	if len(payload.Result.Repos) != 1 || payload.Result.Repos[0].Branch != "METAWSM-011/run-1" {
+		t.Fatalf("unexpected commit result: %#v", payload.Result)
+	}
+}
+
+func TestRemoteCoreDrivesRunActions(t *testing.T) {
+	core := &mockCore{
+		resolveRunIDFn: func(_ context.Context, runID string, ticket string) (string, error) {
+			if runID != "" || ticket != "METAWSM-011" {
+				t.Fatalf("unexpected resolve request run=%q ticket=%q", runID, ticket)
+			}
+			return "run-7", nil
+		},
+		cleanupRunFn: func(_ context.Context, options serviceapi.CleanupOptions) (serviceapi.CleanupResult, error) {
+			if options.RunID != "run-7" || !options.DryRun || options.DeleteWorkspaces {
+				t.Fatalf("unexpected cleanup options: %#v", options)
+			}
+			return serviceapi.CleanupResult{RunID: "run-7", Actions: []string{"tmux kill-session -t agent-ws"}}, nil
+		},
+	}
+	runtime := newTestRuntime(core)
+	mux := http.NewServeMux()
+	runtime.registerRoutes(mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	remote := serviceapi.NewRemoteCore(server.URL, time.Second)
+	result, err := remote.CleanupRun(context.Background(), serviceapi.CleanupOptions{
+		Ticket:           "METAWSM-011",
+		DryRun:           true,
+		DeleteWorkspaces: false,
+	})
+	if err != nil {
+		t.Fatalf("remote cleanup: %v", err)
+	}
+	if result.RunID != "run-7" || len(result.Actions) != 1 {
+		t.Fatalf("unexpected cleanup result: %#v", result)
+	}
+}
+
+func TestHandleRunActionStopAcceptsEmptyBody(t *testing.T) {
+	stopped := ""
+	core := &mockCore{
+		stopRunFn: func(_ context.Context, runID string) error {
+			stopped = runID
+			return nil
+		},
+	}
+	runtime := newTestRuntime(core)
+	mux := http.NewServeMux()
+	runtime.registerRoutes(mux)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/runs/run-1/stop", nil)
+	response := httptest.NewRecorder()
+	mux.ServeHTTP(response, request)
+	if response.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", response.Code, response.Body.String())
+	}
+	if stopped != "run-1" {
+		t.Fatalf("expected stop for run-1, got %q", stopped)
+	}
+}
+
+func TestHandleRunActionRejectsDryRunStopAndMapsMissingRun(t *testing.T) {
+	stopped := false
+	core := &mockCore{
+		stopRunFn: func(_ context.Context, runID string) error {
+			stopped = true
+			return fmt.Errorf("%w: %s", serviceapi.ErrRunNotFound, runID)
+		},
+	}
+	runtime := newTestRuntime(core)
+	mux := http.NewServeMux()
+	runtime.registerRoutes(mux)
+
+	for _, action := range []string{"stop", "resume"} {
+		request := httptest.NewRequest(http.MethodPost, "/api/v1/runs/run-1/"+action, strings.NewReader(`{"dry_run":true}`))
+		response := httptest.NewRecorder()
+		mux.ServeHTTP(response, request)
+		if response.Code != http.StatusBadRequest || !strings.Contains(response.Body.String(), "dry_run_unsupported") {
+			t.Fatalf("expected 400 dry_run_unsupported for %s, got %d: %s", action, response.Code, response.Body.String())
+		}
+	}
+	if stopped {
+		t.Fatalf("expected a dry-run stop not to stop the run")
+	}
+
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/runs/run-missing/stop", nil)
+	response := httptest.NewRecorder()
+	mux.ServeHTTP(response, request)
+	if response.Code != http.StatusNotFound || !strings.Contains(response.Body.String(), "run_not_found") {
+		t.Fatalf("expected 404 run_not_found, got %d: %s", response.Code, response.Body.String())
+	}
+
+	request = httptest.NewRequest(http.MethodPost, "/api/v1/runs/run-1/resume", nil)
+	response = httptest.NewRecorder()
+	mux.ServeHTTP(response, request)
+	if response.Code != http.StatusAccepted {
+		t.Fatalf("expected 202 for resume, got %d: %s", response.Code, response.Body.String())
+	}
+}
+
+func TestHandleRunReturnsSnapshotAndStatusReport(t *testing.T) {
+	core := &mockCore{
+		runStatusReportFn: func(_ context.Context, runID string) (serviceapi.RunStatusReport, error) {
+			var report serviceapi.RunStatusReport
+			err := json.Unmarshal([]byte(`{
+				"tickets": ["METAWSM-021"],
+				"agents": [{"name": "agent", "workspace_name": "ws-1", "status": "stalled", "health": "stalled", "activity_age": "1h0m0s", "unhealthy": true}],
+				"review_feedback": {"queued": 2}
+			}`), &report)
+			report.Run = model.RunRecord{RunID: runID, Status: model.RunStatusRunning}
+			return report, err
+		},
+	}
+	runtime := newTestRuntime(core)
+	mux := http.NewServeMux()
+	runtime.registerRoutes(mux)
+
+	request := httptest.NewRequest(http.MethodGet, "/api/v1/runs/run-1", nil)
+	response := httptest.NewRecorder()
+	mux.ServeHTTP(response, request)
+	if response.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", response.Code, response.Body.String())
+	}
+	var payload struct {
+		Run    serviceapi.RunSnapshot     `json:"run"`
+		Status serviceapi.RunStatusReport `json:"status"`
+	}
+	if err := json.Unmarshal(response.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if payload.Run.RunID != "run-1" || len(payload.Run.UnhealthyAgents) != 1 || payload.Run.QueuedReviewFeedback != 2 {
+		t.Fatalf("expected the snapshot to be derived from the report, got %+v", payload.Run)
+	}
+	if payload.Status.Run.Status != model.RunStatusRunning || len(payload.Status.Agents) != 1 || payload.Status.Agents[0].ActivityAge != "1h0m0s" {
+		t.Fatalf("unexpected status report %+v", payload.Status)
+	}
+}
+
+func TestHandleRunActionMapsMutationLockToConflict(t *testing.T) {
+	core := &mockCore{
+		restartRunFn: func(_ context.Context, options serviceapi.RestartOptions) (serviceapi.RestartResult, error) {
+			return serviceapi.RestartResult{}, &serviceapi.RunMutationInProgressError{RunID: options.RunID, Operation: "commit"}
+		},
+	}
+	runtime := newTestRuntime(core)
+	mux := http.NewServeMux()
+	runtime.registerRoutes(mux)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/runs/run-1/restart", strings.NewReader(`{}`))
+	response := httptest.NewRecorder()
+	mux.ServeHTTP(response, request)
+	if response.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d: %s", response.Code, response.Body.String())
+	}
+
+	request = httptest.NewRequest(http.MethodGet, "/api/v1/runs/run-1/restart", nil)
+	response = httptest.NewRecorder()
+	mux.ServeHTTP(response, request)
+	if response.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405 for GET action, got %d", response.Code)
+	}
+}
+
+func TestHandleAgentLogs(t *testing.T) {
+	core := &mockCore{
+		readAgentLogFn: func(_ context.Context, options serviceapi.AgentTranscriptReadOptions) (serviceapi.AgentTranscriptChunk, error) {
+			if options.RunID != "run-1" || options.AgentName != "agent" || options.WorkspaceName != "ws-1" {
+				t.Fatalf("unexpected transcript options: %#v", options)
+			}
+			if options.Offset != -1 {
+				t.Fatalf("expected tail offset by default, got %d", options.Offset)
+			}
+			return serviceapi.AgentTranscriptChunk{RunID: "run-1", AgentName: "agent", WorkspaceName: "ws-1", Data: "hello\n", NextOffset: 6, Size: 6}, nil
+		},
+	}
+	runtime := newTestRuntime(core)
+	mux := http.NewServeMux()
+	runtime.registerRoutes(mux)
+
+	request := httptest.NewRequest(http.MethodGet, "/api/v1/runs/run-1/agents/agent/logs?workspace=ws-1", nil)
+	response := httptest.NewRecorder()
+	mux.ServeHTTP(response, request)
+	if response.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", response.Code, response.Body.String())
+	}
+	var payload struct {
+		Log serviceapi.AgentTranscriptChunk `json:"log"`
+	}
+	if err := json.Unmarshal(response.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal log: %v", err)
+	}
+	if payload.Log.Data != "hello\n" || payload.Log.NextOffset != 6 {
+		t.Fatalf("unexpected log chunk: %#v", payload.Log)
+	}
+}
+
+func TestHandleAgentLogStreamSendsNewOutput(t *testing.T) {
+	calls := 0
+	core := &mockCore{
+		readAgentLogFn: func(_ context.Context, options serviceapi.AgentTranscriptReadOptions) (serviceapi.AgentTranscriptChunk, error) {
+			calls++
+			if calls == 1 {
+				return serviceapi.AgentTranscriptChunk{Offset: 0, NextOffset: 0}, nil
+			}
+			if options.Offset != 0 {
+				t.Errorf("expected follow-up read from offset 0, got %d", options.Offset)
+			}
+			return serviceapi.AgentTranscriptChunk{Offset: 0, NextOffset: 4, Size: 4, Data: "more"}, nil
+		},
+	}
+	runtime := newTestRuntime(core)
+	runtime.streamBeat = time.Minute
+	conn, reader := openTestWebSocket(t, runtime, "/api/v1/runs/run-1/agents/agent/logs")
+	defer conn.Close()
+
+	frame := readWebSocketJSONFrame(t, conn, reader, time.Second)
+	if frame["type"] != "agent.log" {
+		t.Fatalf("expected agent.log frame, got %#v", frame["type"])
+	}
+	logPayload, ok := frame["log"].(map[string]any)
+	if !ok || logPayload["data"] != "more" {
+		t.Fatalf("expected new transcript output in frame, got %#v", frame["log"])
+	}
+}
+
+func TestHandleAgentLogStreamSendsEachChunkOnce(t *testing.T) {
+	calls := 0
+	core := &mockCore{
+		readAgentLogFn: func(_ context.Context, options serviceapi.AgentTranscriptReadOptions) (serviceapi.AgentTranscriptChunk, error) {
+			calls++
+			if calls == 1 {
+				return serviceapi.AgentTranscriptChunk{NextOffset: 5, Size: 5, Generation: 2, Data: "hello"}, nil
+			}
+			if options.Offset != 5 || options.Generation != 2 {
+				t.Errorf("expected follow-up read from offset 5 generation 2, got %d/%d", options.Offset, options.Generation)
+			}
+			return serviceapi.AgentTranscriptChunk{Offset: 5, NextOffset: 5, Size: 5, Generation: 2}, nil
+		},
+	}
+	runtime := newTestRuntime(core)
+	runtime.streamBeat = 10 * time.Millisecond
+	conn, reader := openTestWebSocket(t, runtime, "/api/v1/runs/run-1/agents/agent/logs")
+	defer conn.Close()
+
+	frame := readWebSocketJSONFrame(t, conn, reader, time.Second)
+	if frame["type"] != "agent.log" {
+		t.Fatalf("expected agent.log frame, got %#v", frame["type"])
+	}
+	for i := 0; i < 4; i++ {
+		frame = readWebSocketJSONFrame(t, conn, reader, time.Second)
+		if frame["type"] != "heartbeat" {
+			t.Fatalf("expected only heartbeats after the first chunk, got %#v", frame)
+		}
+	}
+}
+
 func TestHandleForumOpenThread(t *testing.T) {
 	core := &mockCore{
 		forumOpenThreadFn: func(_ context.Context, options serviceapi.ForumOpenThreadOptions) (model.ForumThreadView, error) {
@@ -156,22 +507,441 @@ func TestHandleForumDebug(t *testing.T) {
 			if options.Limit != 15 {
 				t.Fatalf("unexpected limit %d", options.Limit)
 			}
-			return model.ForumStreamDebugSnapshot{
-				GeneratedAt: time.Now().UTC(),
-				Ticket:      options.Ticket,
-				RunID:       options.RunID,
-				Outbox: model.ForumOutboxStats{
-					PendingCount:    3,
-					ProcessingCount: 1,
-					FailedCount:     2,
-				},
-				Bus: model.ForumBusDebug{
-					Running:       true,
-					Healthy:       true,
-					StreamName:    "metawsm-forum.abc",
-					ConsumerGroup: "metawsm-forum.abc",
-					ConsumerName:  "operator-abc",
-				},
+			return model.ForumStreamDebugSnapshot{
+				GeneratedAt: time.Now().UTC(),
+				Ticket:      options.Ticket,
+				RunID:       options.RunID,
+				Outbox: model.ForumOutboxStats{
+					PendingCount:    3,
+					ProcessingCount: 1,
+					FailedCount:     2,
+				},
+				Bus: model.ForumBusDebug{
+					Running:       true,
+					Healthy:       true,
+					StreamName:    "metawsm-forum.abc",
+					ConsumerGroup: "metawsm-forum.abc",
+					ConsumerName:  "operator-abc",
+				},
+			}, nil
+		},
+	}
+	runtime := newTestRuntime(core)
+	mux := http.NewServeMux()
+	runtime.registerRoutes(mux)
+
+	request := httptest.NewRequest(http.MethodGet, "/api/v1/forum/debug?ticket=METAWSM-011&run_id=run-123&limit=15", nil)
+	response := httptest.NewRecorder()
+	mux.ServeHTTP(response, request)
+	if response.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", response.Code, response.Body.String())
+	}
+	var payload struct {
+		Debug model.ForumStreamDebugSnapshot `json:"debug"`
+	}
+	if err := json.Unmarshal(response.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal forum debug: %v", err)
+	}
+	if payload.Debug.Ticket != "METAWSM-011" {
+		t.Fatalf("unexpected payload ticket %q", payload.Debug.Ticket)
+	}
+	if payload.Debug.Bus.StreamName != "metawsm-forum.abc" {
+		t.Fatalf("unexpected stream name %q", payload.Debug.Bus.StreamName)
+	}
+}
+
+func TestRemoteCoreManagesForumOutbox(t *testing.T) {
+	core := &mockCore{
+		forumListOutboxFn: func(_ context.Context, options serviceapi.ForumOutboxListOptions) ([]model.ForumOutboxMessage, error) {
+			if options.Status != model.ForumOutboxStatusDeadLetter || options.Limit != 5 {
+				t.Fatalf("unexpected list options: %#v", options)
+			}
+			return []model.ForumOutboxMessage{{
+				MessageID:    "fmsg-1",
+				Topic:        "forum.commands.open_thread",
+				Status:       model.ForumOutboxStatusDeadLetter,
+				AttemptCount: 8,
+				LastError:    "boom",
+			}}, nil
+		},
+		forumRetryOutboxFn: func(_ context.Context, options serviceapi.ForumOutboxRetryOptions) (int, error) {
+			if len(options.MessageIDs) != 1 || options.MessageIDs[0] != "fmsg-1" || options.All {
+				t.Fatalf("unexpected retry options: %#v", options)
+			}
+			return 1, nil
+		},
+		forumPurgeOutboxFn: func(_ context.Context, options serviceapi.ForumOutboxPurgeOptions) (int, error) {
+			if options.Status != model.ForumOutboxStatusSent || options.OlderThan != time.Hour {
+				t.Fatalf("unexpected purge options: %#v", options)
+			}
+			return 3, nil
+		},
+	}
+	runtime := newTestRuntime(core)
+	mux := http.NewServeMux()
+	runtime.registerRoutes(mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	remote := serviceapi.NewRemoteCore(server.URL, time.Second)
+	messages, err := remote.ForumListOutbox(context.Background(), serviceapi.ForumOutboxListOptions{
+		Status: model.ForumOutboxStatusDeadLetter,
+		Limit:  5,
+	})
+	if err != nil {
+		t.Fatalf("remote list outbox: %v", err)
+	}
+	if len(messages) != 1 || messages[0].MessageID != "fmsg-1" || messages[0].AttemptCount != 8 {
+		t.Fatalf("unexpected outbox messages: %#v", messages)
+	}
+	requeued, err := remote.ForumRetryOutbox(context.Background(), serviceapi.ForumOutboxRetryOptions{MessageIDs: []string{"fmsg-1"}})
+	if err != nil {
+		t.Fatalf("remote retry outbox: %v", err)
+	}
+	if requeued != 1 {
+		t.Fatalf("expected one requeued message, got %d", requeued)
+	}
+	purged, err := remote.ForumPurgeOutbox(context.Background(), serviceapi.ForumOutboxPurgeOptions{
+		Status:    model.ForumOutboxStatusSent,
+		OlderThan: time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("remote purge outbox: %v", err)
+	}
+	if purged != 3 {
+		t.Fatalf("expected three purged messages, got %d", purged)
+	}
+}
+
+func TestRemoteCoreRebuildsForumProjections(t *testing.T) {
+	core := &mockCore{
+		forumRebuildProjectionsFn: func(_ context.Context, options serviceapi.ForumRebuildProjectionsOptions) (model.ForumProjectionRebuildReport, error) {
+			if options.Ticket != "METAWSM-011" || len(options.Projections) != 1 || options.Projections[0] != model.ForumProjectionQueueView {
+				t.Fatalf("unexpected rebuild options: %#v", options)
+			}
+			return model.ForumProjectionRebuildReport{
+				Ticket:         options.Ticket,
+				Projections:    options.Projections,
+				EventsReplayed: 4,
+				Diffs: []model.ForumProjectionDiff{{
+					Projection: model.ForumProjectionQueueView,
+					Key:        "thread-1",
+					Kind:       model.ForumProjectionDiffAdded,
+				}},
+			}, nil
+		},
+	}
+	runtime := newTestRuntime(core)
+	mux := http.NewServeMux()
+	runtime.registerRoutes(mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	remote := serviceapi.NewRemoteCore(server.URL, time.Second)
+	report, err := remote.ForumRebuildProjections(context.Background(), serviceapi.ForumRebuildProjectionsOptions{
+		Ticket:      "METAWSM-011",
+		Projections: []string{model.ForumProjectionQueueView},
+	})
+	if err != nil {
+		t.Fatalf("remote rebuild projections: %v", err)
+	}
+	if report.EventsReplayed != 4 || len(report.Diffs) != 1 || report.Diffs[0].Key != "thread-1" {
+		t.Fatalf("unexpected rebuild report: %#v", report)
+	}
+}
+
+func TestRemoteCoreRunsForumEscalationPass(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	core := &mockCore{
+		forumEscalateFn: func(_ context.Context, options serviceapi.ForumEscalationPassOptions) (serviceapi.ForumEscalationPassResult, error) {
+			if !options.Now.Equal(now) {
+				t.Fatalf("unexpected escalation pass time %s", options.Now)
+			}
+			return serviceapi.ForumEscalationPassResult{
+				Checked: 3,
+				Escalated: []model.ForumEscalation{{
+					ThreadID:       "thread-1",
+					Level:          1,
+					ToPriority:     model.ForumPriorityHigh,
+					ToAssigneeName: "oncall",
+				}},
+			}, nil
+		},
+	}
+	runtime := newTestRuntime(core)
+	mux := http.NewServeMux()
+	runtime.registerRoutes(mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	remote := serviceapi.NewRemoteCore(server.URL, time.Second)
+	result, err := remote.ForumEscalateOverdueThreads(context.Background(), serviceapi.ForumEscalationPassOptions{Now: now})
+	if err != nil {
+		t.Fatalf("remote escalation pass: %v", err)
+	}
+	if result.Checked != 3 || len(result.Escalated) != 1 || result.Escalated[0].ToAssigneeName != "oncall" {
+		t.Fatalf("unexpected escalation result: %#v", result)
+	}
+}
+
+func TestForumWorkerRecordsEscalationPass(t *testing.T) {
+	core := &mockCore{
+		forumEscalateFn: func(context.Context, serviceapi.ForumEscalationPassOptions) (serviceapi.ForumEscalationPassResult, error) {
+			return serviceapi.ForumEscalationPassResult{
+				Escalated:    []model.ForumEscalation{{ThreadID: "thread-1"}, {ThreadID: "thread-2"}},
+				NotifyErrors: []string{"thread-2: exit status 1"},
+			}, nil
+		},
+	}
+	worker := NewForumWorker(core, time.Second, 10, time.Minute, time.Minute, nil)
+	worker.runEscalationPass(context.Background())
+
+	snapshot := worker.Snapshot()
+	if snapshot.TotalEscalated != 2 || snapshot.LastEscalationAt == nil {
+		t.Fatalf("unexpected escalation snapshot: %+v", snapshot)
+	}
+	if !strings.Contains(snapshot.EscalationError, "thread-2") {
+		t.Fatalf("expected notify failure in snapshot, got %q", snapshot.EscalationError)
+	}
+	if snapshot.ConsecutiveErrors != 0 {
+		t.Fatalf("escalation notify failures should not count as bus errors: %+v", snapshot)
+	}
+}
+
+func TestForumWorkerRecordsOperatorDecisionPrune(t *testing.T) {
+	prunes := 0
+	core := &mockCore{
+		pruneOperatorDecisionsFn: func(context.Context) (int, error) {
+			prunes++
+			if prunes == 2 {
+				return 0, fmt.Errorf("database is locked")
+			}
+			return 4, nil
+		},
+	}
+	worker := NewForumWorker(core, time.Second, 10, time.Minute, time.Minute, nil)
+	worker.runOperatorDecisionPrune(context.Background())
+	worker.runOperatorDecisionPrune(context.Background())
+
+	snapshot := worker.Snapshot()
+	if snapshot.TotalDecisionsPruned != 4 || !strings.Contains(snapshot.DecisionPruneError, "locked") {
+		t.Fatalf("unexpected prune snapshot: %+v", snapshot)
+	}
+}
+
+func TestWebhookNotifierQueuesRunTransitionsAndForumEvents(t *testing.T) {
+	passes := 0
+	core := &mockCore{
+		enqueueRunStatusWebhooksFn: func(context.Context) (serviceapi.RunStatusWebhookResult, error) {
+			passes++
+			if passes == 2 {
+				return serviceapi.RunStatusWebhookResult{Transitions: 1}, fmt.Errorf("outbox unavailable")
+			}
+			return serviceapi.RunStatusWebhookResult{Transitions: 2, Enqueued: 1}, nil
+		},
+		enqueueForumEventWebhooksFn: func(context.Context) (serviceapi.ForumEventWebhookResult, error) {
+			return serviceapi.ForumEventWebhookResult{Events: 3, Enqueued: 2}, nil
+		},
+	}
+	notifier := NewWebhookNotifier(core, NewForumEventBroker(0), time.Minute, nil)
+
+	notifier.pollRuns(context.Background())
+	notifier.pollRuns(context.Background())
+	snapshot := notifier.Snapshot()
+	if passes != 2 || snapshot.Transitions != 3 || snapshot.Enqueued != 1 {
+		t.Fatalf("unexpected notifier snapshot after polls: %+v", snapshot)
+	}
+	if !strings.Contains(snapshot.LastError, "outbox unavailable") {
+		t.Fatalf("expected poll failure in snapshot, got %q", snapshot.LastError)
+	}
+
+	notifier.pollForumEvents(context.Background())
+	if snapshot := notifier.Snapshot(); snapshot.ForumEvents != 3 || snapshot.Enqueued != 3 || snapshot.EventsSeen != 6 {
+		t.Fatalf("unexpected notifier snapshot: %+v", snapshot)
+	}
+}
+
+func TestWebhookNotifierReadsForumEventsFromStoreWhenWoken(t *testing.T) {
+	polls := make(chan struct{}, 16)
+	core := &mockCore{
+		enqueueForumEventWebhooksFn: func(context.Context) (serviceapi.ForumEventWebhookResult, error) {
+			polls <- struct{}{}
+			return serviceapi.ForumEventWebhookResult{}, nil
+		},
+	}
+	broker := NewForumEventBroker(1)
+	notifier := NewWebhookNotifier(core, broker, time.Hour, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	notifier.Start(ctx)
+
+	waitPoll := func(label string) {
+		t.Helper()
+		select {
+		case <-polls:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for %s", label)
+		}
+	}
+	waitPoll("startup poll")
+	// The broker holds one event for the notifier and drops the rest; the
+	// wake-up still reads everything from the store.
+	for i := 0; i < 5; i++ {
+		broker.Publish(model.ForumEvent{Envelope: model.ForumEnvelope{EventID: fmt.Sprintf("evt-%d", i), Ticket: "METAWSM-015"}})
+	}
+	waitPoll("wake-up poll")
+
+	cancel()
+	if !notifier.Wait(2 * time.Second) {
+		t.Fatalf("notifier did not stop")
+	}
+}
+
+func TestOperatorSupervisorPassesAndRoutes(t *testing.T) {
+	var passes []serviceapi.OperatorPassOptions
+	released := []string{}
+	core := &mockCore{
+		operatorPassFn: func(_ context.Context, options serviceapi.OperatorPassOptions) (serviceapi.OperatorPassResult, error) {
+			passes = append(passes, options)
+			return serviceapi.OperatorPassResult{
+				LLMMode: "off",
+				Runs: []serviceapi.OperatorRunResult{
+					{Active: true, Decision: &serviceapi.OperatorDecision{RunID: "run-1", Event: "guidance_needed", Intent: "escalate_guidance"}},
+					{LeaseHolder: "cli:other:1"},
+				},
+			}, nil
+		},
+		operatorDecisionsFn: func(_ context.Context, runID string, limit int) ([]serviceapi.OperatorRunDecisions, error) {
+			if runID != "run-1" || limit != 5 {
+				t.Fatalf("unexpected decisions query run=%q limit=%d", runID, limit)
+			}
+			return []serviceapi.OperatorRunDecisions{{RunID: runID, Decisions: []model.RunEvent{{RunID: runID, EventType: "guidance_needed"}}}}, nil
+		},
+		releaseOperatorLeasesFn: func(_ context.Context, holder string) error {
+			released = append(released, holder)
+			return nil
+		},
+	}
+	runtime := newTestRuntime(core)
+	runtime.operator = NewOperatorSupervisor(core, 20*time.Second, "", "", true, false, nil)
+	mux := http.NewServeMux()
+	runtime.registerRoutes(mux)
+
+	runtime.operator.runPass(context.Background())
+	if len(passes) != 1 || !passes[0].DryRun || passes[0].LeaseTTL != time.Minute || !strings.HasPrefix(passes[0].Holder, "serve:") {
+		t.Fatalf("unexpected pass options %+v", passes)
+	}
+	snapshot := runtime.operator.Snapshot()
+	if snapshot.TotalPasses != 1 || snapshot.TotalDecisions != 1 || snapshot.SupervisedRuns != 1 || snapshot.LeasedElsewhere != 1 || snapshot.LLMMode != "off" {
+		t.Fatalf("unexpected supervisor snapshot %+v", snapshot)
+	}
+
+	get := httptest.NewRecorder()
+	mux.ServeHTTP(get, httptest.NewRequest(http.MethodGet, "/api/v1/operator?run_id=run-1&limit=5", nil))
+	if get.Code != http.StatusOK {
+		t.Fatalf("expected 200 from operator, got %d: %s", get.Code, get.Body.String())
+	}
+	var view struct {
+		Supervisor OperatorSupervisorSnapshot        `json:"supervisor"`
+		Runs       []serviceapi.OperatorRunDecisions `json:"runs"`
+	}
+	if err := json.Unmarshal(get.Body.Bytes(), &view); err != nil || len(view.Runs) != 1 || len(view.Runs[0].Decisions) != 1 || view.Supervisor.TotalPasses != 1 {
+		t.Fatalf("unexpected operator response %s (%v)", get.Body.String(), err)
+	}
+
+	pause := httptest.NewRecorder()
+	mux.ServeHTTP(pause, httptest.NewRequest(http.MethodPost, "/api/v1/operator/pause", nil))
+	if pause.Code != http.StatusOK || !runtime.operator.Snapshot().Paused {
+		t.Fatalf("expected pause to succeed, got %d: %s", pause.Code, pause.Body.String())
+	}
+	if len(released) != 1 || released[0] != snapshot.Holder {
+		t.Fatalf("expected pause to release leases, got %v", released)
+	}
+	runtime.operator.runPass(context.Background())
+	if len(passes) != 1 {
+		t.Fatalf("expected paused supervisor to skip passes, got %d", len(passes))
+	}
+	resume := httptest.NewRecorder()
+	mux.ServeHTTP(resume, httptest.NewRequest(http.MethodPost, "/api/v1/operator/resume", nil))
+	if resume.Code != http.StatusOK || runtime.operator.Snapshot().Paused {
+		t.Fatalf("expected resume to succeed, got %d: %s", resume.Code, resume.Body.String())
+	}
+
+	mode := httptest.NewRecorder()
+	mux.ServeHTTP(mode, httptest.NewRequest(http.MethodPost, "/api/v1/operator/llm-mode", strings.NewReader(`{"mode":"AUTO"}`)))
+	if mode.Code != http.StatusOK || runtime.operator.Snapshot().LLMModeOverride != "auto" {
+		t.Fatalf("expected llm mode override, got %d: %s", mode.Code, mode.Body.String())
+	}
+	invalid := httptest.NewRecorder()
+	mux.ServeHTTP(invalid, httptest.NewRequest(http.MethodPost, "/api/v1/operator/llm-mode", strings.NewReader(`{"mode":"yolo"}`)))
+	if invalid.Code != http.StatusBadRequest || !strings.Contains(invalid.Body.String(), "invalid_llm_mode") {
+		t.Fatalf("expected invalid mode rejection, got %d: %s", invalid.Code, invalid.Body.String())
+	}
+}
+
+func TestOperatorSupervisorPauseWaitsForInFlightPass(t *testing.T) {
+	entered := make(chan struct{})
+	unblock := make(chan struct{})
+	var mu sync.Mutex
+	passDone, released := false, false
+	core := &mockCore{
+		operatorPassFn: func(_ context.Context, _ serviceapi.OperatorPassOptions) (serviceapi.OperatorPassResult, error) {
+			close(entered)
+			<-unblock
+			mu.Lock()
+			passDone = true
+			mu.Unlock()
+			return serviceapi.OperatorPassResult{LLMMode: "off"}, nil
+		},
+		releaseOperatorLeasesFn: func(_ context.Context, _ string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if !passDone {
+				t.Errorf("expected leases to be released after the in-flight pass finished")
+			}
+			released = true
+			return nil
+		},
+	}
+	supervisor := NewOperatorSupervisor(core, 20*time.Second, "", "", false, false, nil)
+	go supervisor.runPass(context.Background())
+	<-entered
+
+	paused := make(chan OperatorSupervisorSnapshot)
+	go func() { paused <- supervisor.Pause() }()
+	select {
+	case <-paused:
+		t.Fatalf("expected pause to wait for the in-flight pass")
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(unblock)
+	select {
+	case snapshot := <-paused:
+		mu.Lock()
+		defer mu.Unlock()
+		if !snapshot.Paused || !released || snapshot.TotalPasses != 1 {
+			t.Fatalf("expected pause to release leases after the pass, got %+v released=%t", snapshot, released)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("pause did not return after the pass finished")
+	}
+}
+
+func TestHandleIntegrationIngress(t *testing.T) {
+	var captured serviceapi.IntegrationIngestOptions
+	core := &mockCore{
+		ingestIntegrationFn: func(_ context.Context, options serviceapi.IntegrationIngestOptions) (serviceapi.IntegrationIngestResult, error) {
+			captured = options
+			switch options.Source {
+			case "unknown":
+				return serviceapi.IntegrationIngestResult{}, fmt.Errorf("%w: %q", serviceapi.ErrIntegrationSourceNotFound, options.Source)
+			case "locked":
+				return serviceapi.IntegrationIngestResult{}, serviceapi.ErrIntegrationUnauthorized
+			}
+			return serviceapi.IntegrationIngestResult{
+				Source:  options.Source,
+				EventID: "fint-ci-d1",
+				Actions: []serviceapi.IntegrationAction{{Rule: "ci-failure", Action: "open_thread", ThreadID: "thread-1"}},
 			}, nil
 		},
 	}
@@ -179,23 +949,37 @@ func TestHandleForumDebug(t *testing.T) {
 	mux := http.NewServeMux()
 	runtime.registerRoutes(mux)
 
-	request := httptest.NewRequest(http.MethodGet, "/api/v1/forum/debug?ticket=METAWSM-011&run_id=run-123&limit=15", nil)
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/integrations/ci", strings.NewReader(`{"status":"failed"}`))
+	request.Header.Set("X-Metawsm-Signature", "sha256=abc")
+	request.Header.Set("X-Metawsm-Timestamp", "1700000000")
+	request.Header.Set("X-Metawsm-Delivery", "d1")
 	response := httptest.NewRecorder()
 	mux.ServeHTTP(response, request)
 	if response.Code != http.StatusOK {
 		t.Fatalf("expected 200, got %d: %s", response.Code, response.Body.String())
 	}
-	var payload struct {
-		Debug model.ForumStreamDebugSnapshot `json:"debug"`
-	}
-	if err := json.Unmarshal(response.Body.Bytes(), &payload); err != nil {
-		t.Fatalf("unmarshal forum debug: %v", err)
+	if captured.Source != "ci" || string(captured.Body) != `{"status":"failed"}` || captured.Signature != "sha256=abc" || captured.Timestamp != "1700000000" || captured.DeliveryID != "d1" {
+		t.Fatalf("unexpected ingest options: %+v", captured)
 	}
-	if payload.Debug.Ticket != "METAWSM-011" {
-		t.Fatalf("unexpected payload ticket %q", payload.Debug.Ticket)
+	if !strings.Contains(response.Body.String(), `"thread_id":"thread-1"`) {
+		t.Fatalf("expected actions in response, got %s", response.Body.String())
 	}
-	if payload.Debug.Bus.StreamName != "metawsm-forum.abc" {
-		t.Fatalf("unexpected stream name %q", payload.Debug.Bus.StreamName)
+
+	cases := []struct {
+		path   string
+		body   string
+		status int
+	}{
+		{path: "/api/v1/integrations/unknown", body: `{}`, status: http.StatusNotFound},
+		{path: "/api/v1/integrations/locked", body: `{}`, status: http.StatusUnauthorized},
+		{path: "/api/v1/integrations/ci", body: `not json`, status: http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		response := httptest.NewRecorder()
+		mux.ServeHTTP(response, httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader(tc.body)))
+		if response.Code != tc.status {
+			t.Fatalf("%s: expected %d, got %d: %s", tc.path, tc.status, response.Code, response.Body.String())
+		}
 	}
 }
 
@@ -220,6 +1004,9 @@ func TestHandleForumSearch(t *testing.T) {
 			if options.Assignee != "kball" {
 				t.Fatalf("unexpected assignee %q", options.Assignee)
 			}
+			if options.ActorType != model.ForumActorAgent || options.ActorName != "agent-a" {
+				t.Fatalf("unexpected actor filter %q/%q", options.ActorType, options.ActorName)
+			}
 			if options.ViewerType != model.ForumViewerHuman {
 				t.Fatalf("unexpected viewer type %q", options.ViewerType)
 			}
@@ -249,7 +1036,7 @@ func TestHandleForumSearch(t *testing.T) {
 	mux := http.NewServeMux()
 	runtime.registerRoutes(mux)
 
-	request := httptest.NewRequest(http.MethodGet, "/api/v1/forum/search?query=validation+mismatch&ticket=METAWSM-011&run_id=run-123&state=waiting_human&priority=high&assignee=kball&viewer_type=human&viewer_id=human:kball&limit=15&cursor=22", nil)
+	request := httptest.NewRequest(http.MethodGet, "/api/v1/forum/search?query=validation+mismatch&ticket=METAWSM-011&run_id=run-123&state=waiting_human&priority=high&assignee=kball&actor_type=agent&actor=agent-a&viewer_type=human&viewer_id=human:kball&limit=15&cursor=22", nil)
 	response := httptest.NewRecorder()
 	mux.ServeHTTP(response, request)
 	if response.Code != http.StatusOK {
@@ -269,6 +1056,56 @@ func TestHandleForumSearch(t *testing.T) {
 	}
 }
 
+func TestHandleGuidanceLibraryRoutes(t *testing.T) {
+	core := &mockCore{
+		guidanceSuggestFn: func(options serviceapi.GuidanceSuggestOptions) ([]model.GuidanceSuggestion, error) {
+			if options.RunID != "run-123" || options.Question != "which test command" || options.Limit != 2 {
+				t.Fatalf("unexpected suggest options %+v", options)
+			}
+			if len(options.Repos) != 2 || options.Repos[0] != "metawsm" || options.Repos[1] != "docs" {
+				t.Fatalf("unexpected repos %v", options.Repos)
+			}
+			return []model.GuidanceSuggestion{{Answer: model.GuidanceAnswer{AnswerID: "gans-1"}, Score: 1, Exact: true}}, nil
+		},
+		guidancePromoteFn: func(_ context.Context, options serviceapi.GuidanceLibraryPromoteOptions) (model.GuidanceAnswer, error) {
+			if options.AnswerID != "gans-1" || options.ScopeType != model.GuidanceAnswerScopeRepo || options.Scope != "metawsm" {
+				t.Fatalf("unexpected promote options %+v", options)
+			}
+			return model.GuidanceAnswer{AnswerID: "gans-2", ScopeType: options.ScopeType, Scope: options.Scope}, nil
+		},
+		guidanceRemoveFn: func(_ context.Context, answerID string) error {
+			return fmt.Errorf("%w: %s", serviceapi.ErrGuidanceAnswerNotFound, answerID)
+		},
+	}
+	runtime := newTestRuntime(core)
+	mux := http.NewServeMux()
+	runtime.registerRoutes(mux)
+
+	suggest := httptest.NewRecorder()
+	mux.ServeHTTP(suggest, httptest.NewRequest(http.MethodGet, "/api/v1/forum/guidance/suggestions?run_id=run-123&question=which+test+command&repos=metawsm,docs&limit=2", nil))
+	if suggest.Code != http.StatusOK {
+		t.Fatalf("expected 200 from suggestions, got %d: %s", suggest.Code, suggest.Body.String())
+	}
+	var suggestions struct {
+		Suggestions []model.GuidanceSuggestion `json:"suggestions"`
+	}
+	if err := json.Unmarshal(suggest.Body.Bytes(), &suggestions); err != nil || len(suggestions.Suggestions) != 1 || !suggestions.Suggestions[0].Exact {
+		t.Fatalf("unexpected suggestions response %s (%v)", suggest.Body.String(), err)
+	}
+
+	promote := httptest.NewRecorder()
+	mux.ServeHTTP(promote, httptest.NewRequest(http.MethodPost, "/api/v1/forum/guidance/library", strings.NewReader(`{"answer_id":"gans-1","scope_type":"repo","scope":"metawsm"}`)))
+	if promote.Code != http.StatusOK || !strings.Contains(promote.Body.String(), "gans-2") {
+		t.Fatalf("expected promoted answer, got %d: %s", promote.Code, promote.Body.String())
+	}
+
+	remove := httptest.NewRecorder()
+	mux.ServeHTTP(remove, httptest.NewRequest(http.MethodPost, "/api/v1/forum/guidance/library/gans-missing/remove", nil))
+	if remove.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 removing a missing answer, got %d: %s", remove.Code, remove.Body.String())
+	}
+}
+
 func TestHandleForumQueues(t *testing.T) {
 	core := &mockCore{
 		forumListQueueFn: func(options serviceapi.ForumQueueOptions) ([]model.ForumThreadView, error) {
@@ -397,7 +1234,7 @@ func TestHandleForumStreamSendsCatchUpFrame(t *testing.T) {
 		},
 	}
 	runtime := newTestRuntime(core)
-	conn, reader := openTestWebSocket(t, runtime, "/api/v1/forum/stream?ticket=METAWSM-011")
+	conn, reader := openTestWebSocket(t, runtime, "/api/v1/forum/stream?ticket=METAWSM-011&cursor=0")
 	defer conn.Close()
 
 	frame := readWebSocketJSONFrame(t, conn, reader, 500*time.Millisecond)
@@ -418,7 +1255,7 @@ func TestHandleForumStreamSendsHeartbeatWhenIdle(t *testing.T) {
 		},
 	}
 	runtime := newTestRuntime(core)
-	conn, reader := openTestWebSocket(t, runtime, "/api/v1/forum/stream?ticket=METAWSM-011")
+	conn, reader := openTestWebSocket(t, runtime, "/api/v1/forum/stream?ticket=METAWSM-011&cursor=0")
 	defer conn.Close()
 
 	frame := readWebSocketJSONFrame(t, conn, reader, 500*time.Millisecond)
@@ -439,7 +1276,7 @@ func TestHandleForumStreamSendsLiveBrokerEventFrame(t *testing.T) {
 		},
 	}
 	runtime := newTestRuntime(core)
-	conn, reader := openTestWebSocket(t, runtime, "/api/v1/forum/stream?ticket=METAWSM-011")
+	conn, reader := openTestWebSocket(t, runtime, "/api/v1/forum/stream?ticket=METAWSM-011&cursor=0")
 	defer conn.Close()
 
 	go func() {
@@ -470,31 +1307,278 @@ func TestHandleForumStreamSendsLiveBrokerEventFrame(t *testing.T) {
 	}
 }
 
+func TestHandleForumStreamAnswersPingAndClose(t *testing.T) {
+	runtime := newTestRuntime(&mockCore{})
+	conn, reader := openTestWebSocket(t, runtime, "/api/v1/forum/stream?ticket=METAWSM-011")
+	defer conn.Close()
+
+	writeTestWebSocketFrame(t, conn, websocketOpPing, []byte("are-you-there"))
+	opcode, payload := readWebSocketControlFrame(t, conn, reader)
+	if opcode != websocketOpPong || string(payload) != "are-you-there" {
+		t.Fatalf("expected pong echoing ping payload, got opcode %#x payload %q", opcode, payload)
+	}
+
+	writeTestWebSocketFrame(t, conn, websocketOpClose, []byte{0x03, 0xe8})
+	opcode, payload = readWebSocketControlFrame(t, conn, reader)
+	if opcode != websocketOpClose || len(payload) < 2 || int(payload[0])<<8|int(payload[1]) != websocketCloseNormal {
+		t.Fatalf("expected normal close reply, got opcode %#x payload %v", opcode, payload)
+	}
+}
+
+func TestHandleForumStreamRejectsUnmaskedClientFrames(t *testing.T) {
+	runtime := newTestRuntime(&mockCore{})
+	conn, reader := openTestWebSocket(t, runtime, "/api/v1/forum/stream?ticket=METAWSM-011")
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte{0x80 | websocketOpText, 0x02, '{', '}'}); err != nil {
+		t.Fatalf("write unmasked frame: %v", err)
+	}
+	opcode, payload := readWebSocketControlFrame(t, conn, reader)
+	if opcode != websocketOpClose || len(payload) < 2 || int(payload[0])<<8|int(payload[1]) != websocketCloseProtocolError {
+		t.Fatalf("expected protocol error close, got opcode %#x payload %v", opcode, payload)
+	}
+}
+
+func TestHandleForumStreamSubscribeCommandReplaysAddedTicket(t *testing.T) {
+	core := &mockCore{
+		forumWatchEventsFn: func(ticket string, cursor int64, _ int) ([]model.ForumEvent, error) {
+			if ticket != "METAWSM-012" || cursor != 0 {
+				return nil, nil
+			}
+			return []model.ForumEvent{
+				{Sequence: 5, Envelope: model.ForumEnvelope{EventID: "evt-5", Ticket: "METAWSM-012"}},
+			}, nil
+		},
+	}
+	runtime := newTestRuntime(core)
+	conn, reader := openTestWebSocket(t, runtime, "/api/v1/forum/stream?ticket=METAWSM-011")
+	defer conn.Close()
+
+	writeTestWebSocketFrame(t, conn, websocketOpText, []byte(`{"type":"subscribe","tickets":["METAWSM-012"],"cursor":0}`))
+	frame := readWebSocketJSONFrameOfType(t, conn, reader, "forum.events")
+	if frame["next_cursor"] != float64(5) {
+		t.Fatalf("expected next_cursor 5, got %#v", frame["next_cursor"])
+	}
+	frame = readWebSocketJSONFrameOfType(t, conn, reader, "subscription")
+	tickets, ok := frame["tickets"].([]any)
+	if !ok || len(tickets) != 2 {
+		t.Fatalf("expected both tickets subscribed, got %#v", frame["tickets"])
+	}
+
+	runtime.eventBroker.Publish(model.ForumEvent{Sequence: 6, Envelope: model.ForumEnvelope{EventID: "evt-6", Ticket: "METAWSM-012"}})
+	frame = readWebSocketJSONFrameOfType(t, conn, reader, "forum.events")
+	if frame["next_cursor"] != float64(6) {
+		t.Fatalf("expected live event after subscribe, got %#v", frame["next_cursor"])
+	}
+}
+
+func TestHandleForumStreamServesSSEFromLastEventID(t *testing.T) {
+	core := &mockCore{
+		forumWatchEventsFn: func(_ string, cursor int64, _ int) ([]model.ForumEvent, error) {
+			if cursor != 6 {
+				t.Errorf("expected resume cursor 6, got %d", cursor)
+			}
+			return []model.ForumEvent{
+				{Sequence: 7, Envelope: model.ForumEnvelope{EventID: "evt-7", Ticket: "METAWSM-011"}},
+			}, nil
+		},
+	}
+	runtime := newTestRuntime(core)
+	mux := http.NewServeMux()
+	runtime.registerRoutes(mux)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/v1/forum/stream?ticket=METAWSM-011", nil)
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+	request.Header.Set("Accept", "text/event-stream")
+	request.Header.Set("Last-Event-ID", "6")
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("open event stream: %v", err)
+	}
+	defer response.Body.Close()
+	if got := response.Header.Get("Content-Type"); got != "text/event-stream" {
+		t.Fatalf("expected text/event-stream content type, got %q", got)
+	}
+
+	reader := bufio.NewReader(response.Body)
+	var id, event string
+	for id == "" || event == "" {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read event stream: %v", err)
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "id: "):
+			id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: ") && id != "":
+			event = strings.TrimPrefix(line, "event: ")
+		}
+	}
+	if id != "7" || event != "forum.events" {
+		t.Fatalf("expected forum.events with id 7, got event %q id %q", event, id)
+	}
+}
+
+func TestHandleForumStreamWithoutCursorStartsAtLatestSequence(t *testing.T) {
+	core := &mockCore{
+		forumWatchEventsFn: func(_ string, cursor int64, _ int) ([]model.ForumEvent, error) {
+			t.Errorf("expected no history read for a fresh stream, got read after %d", cursor)
+			return nil, nil
+		},
+		forumLatestEventSequenceFn: func() (int64, error) {
+			return 42, nil
+		},
+	}
+	runtime := newTestRuntime(core)
+	conn, reader := openTestWebSocket(t, runtime, "/api/v1/forum/stream?ticket=METAWSM-011")
+	defer conn.Close()
+
+	frame := readWebSocketJSONFrame(t, conn, reader, 500*time.Millisecond)
+	if frame["type"] != "heartbeat" || frame["next_cursor"] != float64(42) {
+		t.Fatalf("expected heartbeat at the latest sequence, got %#v", frame)
+	}
+
+	runtime.eventBroker.Publish(model.ForumEvent{Sequence: 41, Envelope: model.ForumEnvelope{EventID: "evt-41", Ticket: "METAWSM-011"}})
+	runtime.eventBroker.Publish(model.ForumEvent{Sequence: 43, Envelope: model.ForumEnvelope{EventID: "evt-43", Ticket: "METAWSM-011"}})
+	frame = readWebSocketJSONFrameOfType(t, conn, reader, "forum.events")
+	events, ok := frame["events"].([]any)
+	if !ok || len(events) != 1 || frame["next_cursor"] != float64(43) {
+		t.Fatalf("expected only the event after the latest sequence, got %#v", frame)
+	}
+}
+
+type recordingForumStreamSink struct {
+	batches [][]model.ForumEvent
+	cursors []int64
+}
+
+func (s *recordingForumStreamSink) sendEvents(events []model.ForumEvent, nextCursor int64) error {
+	s.batches = append(s.batches, append([]model.ForumEvent{}, events...))
+	s.cursors = append(s.cursors, nextCursor)
+	return nil
+}
+
+func (s *recordingForumStreamSink) sendHeartbeat(int64) error { return nil }
+
+func (s *recordingForumStreamSink) sendFrame(string, map[string]any) error { return nil }
+
+func TestSendForumCatchUpSendsEachPageBeforeReadingTheNext(t *testing.T) {
+	stored := map[string][]int64{
+		"METAWSM-011": {1, 3, 5, 7},
+		"METAWSM-012": {2, 4},
+	}
+	sink := &recordingForumStreamSink{}
+	sentBeforeRead := []int{}
+	core := &mockCore{
+		forumWatchEventsFn: func(ticket string, cursor int64, limit int) ([]model.ForumEvent, error) {
+			if limit != 2 {
+				t.Errorf("expected reads bounded by limit 2, got %d", limit)
+			}
+			sentBeforeRead = append(sentBeforeRead, len(sink.batches))
+			page := []model.ForumEvent{}
+			for _, sequence := range stored[ticket] {
+				if sequence > cursor && len(page) < limit {
+					page = append(page, model.ForumEvent{Sequence: sequence, Envelope: model.ForumEnvelope{Ticket: ticket}})
+				}
+			}
+			return page, nil
+		},
+	}
+	runtime := newTestRuntime(core)
+
+	cursor, err := runtime.sendForumCatchUp(sink, []string{"METAWSM-011", "METAWSM-012"}, "", 0, 0, 2)
+	if err != nil {
+		t.Fatalf("catch up: %v", err)
+	}
+	if cursor != 7 {
+		t.Fatalf("expected cursor 7, got %d", cursor)
+	}
+	sequences := []int64{}
+	for _, batch := range sink.batches {
+		if len(batch) > 2 {
+			t.Fatalf("expected batches of at most 2 events, got %d", len(batch))
+		}
+		for _, event := range batch {
+			sequences = append(sequences, event.Sequence)
+		}
+	}
+	if fmt.Sprint(sequences) != "[1 2 3 4 5 7]" {
+		t.Fatalf("expected every event once in sequence order, got %v", sequences)
+	}
+	if fmt.Sprint(sink.cursors) != "[2 3 5 7]" {
+		t.Fatalf("unexpected batch cursors %v", sink.cursors)
+	}
+	if fmt.Sprint(sentBeforeRead) != "[0 0 2 2 4 4]" {
+		t.Fatalf("expected each round sent before the next read, got %v", sentBeforeRead)
+	}
+}
+
+func TestHandleForumStreamStats(t *testing.T) {
+	runtime := newTestRuntime(&mockCore{})
+	subscription := runtime.eventBroker.SubscribeTickets("websocket", []string{"METAWSM-011"}, "")
+	defer subscription.Close()
+	runtime.eventBroker.Publish(model.ForumEvent{Sequence: 1, Envelope: model.ForumEnvelope{Ticket: "METAWSM-011"}})
+
+	mux := http.NewServeMux()
+	runtime.registerRoutes(mux)
+	request := httptest.NewRequest(http.MethodGet, "/api/v1/forum/stream/stats", nil)
+	response := httptest.NewRecorder()
+	mux.ServeHTTP(response, request)
+	if response.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", response.Code, response.Body.String())
+	}
+	var payload struct {
+		Summary     ForumStreamSummary     `json:"summary"`
+		Subscribers []ForumSubscriberStats `json:"subscribers"`
+	}
+	if err := json.Unmarshal(response.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal stats response: %v", err)
+	}
+	if payload.Summary.Subscribers != 1 || payload.Summary.Delivered != 1 {
+		t.Fatalf("unexpected summary %+v", payload.Summary)
+	}
+	if len(payload.Subscribers) != 1 || payload.Subscribers[0].Tickets[0] != "METAWSM-011" {
+		t.Fatalf("unexpected subscribers %+v", payload.Subscribers)
+	}
+}
+
 func readWebSocketFrame(reader *bufio.Reader) ([]byte, error) {
+	_, payload, err := readWebSocketFrameWithOpcode(reader)
+	return payload, err
+}
+
+func readWebSocketFrameWithOpcode(reader *bufio.Reader) (byte, []byte, error) {
 	header := make([]byte, 2)
 	if _, err := io.ReadFull(reader, header); err != nil {
-		return nil, err
+		return 0, nil, err
 	}
 	payloadLen := int(header[1] & 0x7f)
 	switch payloadLen {
 	case 126:
 		extended := make([]byte, 2)
 		if _, err := io.ReadFull(reader, extended); err != nil {
-			return nil, err
+			return 0, nil, err
 		}
 		payloadLen = int(extended[0])<<8 | int(extended[1])
 	case 127:
 		extended := make([]byte, 8)
 		if _, err := io.ReadFull(reader, extended); err != nil {
-			return nil, err
+			return 0, nil, err
 		}
 		payloadLen = int(extended[7])
 	}
 	payload := make([]byte, payloadLen)
 	if _, err := io.ReadFull(reader, payload); err != nil {
-		return nil, err
+		return 0, nil, err
 	}
-	return payload, nil
+	return header[0] & 0x0f, payload, nil
 }
 
 func openTestWebSocket(t *testing.T, runtime *Runtime, path string) (net.Conn, *bufio.Reader) {
@@ -569,10 +1653,60 @@ func readWebSocketJSONFrame(t *testing.T, conn net.Conn, reader *bufio.Reader, t
 	return frame
 }
 
+// readWebSocketJSONFrameOfType skips heartbeats and other frames until one
+// of frameType arrives.
+func readWebSocketJSONFrameOfType(t *testing.T, conn net.Conn, reader *bufio.Reader, frameType string) map[string]any {
+	t.Helper()
+	for i := 0; i < 20; i++ {
+		frame := readWebSocketJSONFrame(t, conn, reader, 500*time.Millisecond)
+		if frame["type"] == frameType {
+			return frame
+		}
+	}
+	t.Fatalf("no %s frame received", frameType)
+	return nil
+}
+
+// readWebSocketControlFrame skips text frames until a control frame arrives.
+func readWebSocketControlFrame(t *testing.T, conn net.Conn, reader *bufio.Reader) (byte, []byte) {
+	t.Helper()
+	for i := 0; i < 20; i++ {
+		if err := conn.SetReadDeadline(time.Now().Add(500 * time.Millisecond)); err != nil {
+			t.Fatalf("set read deadline: %v", err)
+		}
+		opcode, payload, err := readWebSocketFrameWithOpcode(reader)
+		if err != nil {
+			t.Fatalf("read websocket frame: %v", err)
+		}
+		if opcode >= websocketOpClose {
+			return opcode, payload
+		}
+	}
+	t.Fatalf("no control frame received")
+	return 0, nil
+}
+
+// writeTestWebSocketFrame sends one masked client frame.
+func writeTestWebSocketFrame(t *testing.T, conn net.Conn, opcode byte, payload []byte) {
+	t.Helper()
+	if len(payload) > 125 {
+		t.Fatalf("test frames are limited to 125 bytes")
+	}
+	mask := []byte{0x12, 0x34, 0x56, 0x78}
+	frame := []byte{0x80 | opcode, 0x80 | byte(len(payload))}
+	frame = append(frame, mask...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	if _, err := conn.Write(frame); err != nil {
+		t.Fatalf("write websocket frame: %v", err)
+	}
+}
+
 func newTestRuntime(core serviceapi.Core) *Runtime {
 	return &Runtime{
 		service:     core,
-		worker:      NewForumWorker(core, time.Second, 10, time.Minute, nil),
+		worker:      NewForumWorker(core, time.Second, 10, time.Minute, time.Minute, nil),
 		startedAt:   time.Now().UTC(),
 		eventBroker: NewForumEventBroker(32),
 		streamBeat:  50 * time.Millisecond,
@@ -582,29 +1716,61 @@ func newTestRuntime(core serviceapi.Core) *Runtime {
 type mockCore struct {
 	listRunSnapshotsFn func(context.Context, string) ([]serviceapi.RunSnapshot, error)
 	runSnapshotFn      func(context.Context, string) (serviceapi.RunSnapshot, error)
+	runStatusReportFn  func(context.Context, string) (serviceapi.RunStatusReport, error)
+	readAgentLogFn     func(context.Context, serviceapi.AgentTranscriptReadOptions) (serviceapi.AgentTranscriptChunk, error)
+	startRunFn         func(context.Context, serviceapi.RunOptions) (serviceapi.RunResult, error)
+	resolveRunIDFn     func(context.Context, string, string) (string, error)
+	stopRunFn          func(context.Context, string) error
+	restartRunFn       func(context.Context, serviceapi.RestartOptions) (serviceapi.RestartResult, error)
+	commitRunFn        func(context.Context, serviceapi.CommitOptions) (serviceapi.CommitResult, error)
+	cleanupRunFn       func(context.Context, serviceapi.CleanupOptions) (serviceapi.CleanupResult, error)
+	authenticateFn     func(context.Context, string) (serviceapi.APIPrincipal, error)
 
-	forumOpenThreadFn          func(context.Context, serviceapi.ForumOpenThreadOptions) (model.ForumThreadView, error)
-	forumAddPostFn             func(context.Context, serviceapi.ForumAddPostOptions) (model.ForumThreadView, error)
-	forumAnswerThreadFn        func(context.Context, serviceapi.ForumAddPostOptions) (model.ForumThreadView, error)
-	forumAssignThreadFn        func(context.Context, serviceapi.ForumAssignThreadOptions) (model.ForumThreadView, error)
-	forumChangeStateFn         func(context.Context, serviceapi.ForumChangeStateOptions) (model.ForumThreadView, error)
-	forumSetPriorityFn         func(context.Context, serviceapi.ForumSetPriorityOptions) (model.ForumThreadView, error)
-	forumCloseThreadFn         func(context.Context, serviceapi.ForumChangeStateOptions) (model.ForumThreadView, error)
-	forumControlSignalFn       func(context.Context, serviceapi.ForumControlSignalOptions) (model.ForumThreadView, error)
-	forumStreamDebugSnapshotFn func(context.Context, serviceapi.ForumDebugOptions) (model.ForumStreamDebugSnapshot, error)
-	forumListThreadsFn         func(model.ForumThreadFilter) ([]model.ForumThreadView, error)
-	forumGetThreadFn           func(string) (*serviceapi.ForumThreadDetail, error)
-	forumListStatsFn           func(string, string) ([]model.ForumThreadStats, error)
-	forumWatchEventsFn         func(string, int64, int) ([]model.ForumEvent, error)
-	forumSearchThreadsFn       func(serviceapi.ForumSearchThreadsOptions) ([]model.ForumThreadView, error)
-	forumListQueueFn           func(serviceapi.ForumQueueOptions) ([]model.ForumThreadView, error)
-	forumMarkThreadSeenFn      func(context.Context, serviceapi.ForumMarkThreadSeenOptions) (model.ForumThreadSeen, error)
+	forumOpenThreadFn           func(context.Context, serviceapi.ForumOpenThreadOptions) (model.ForumThreadView, error)
+	forumAddPostFn              func(context.Context, serviceapi.ForumAddPostOptions) (model.ForumThreadView, error)
+	forumAnswerThreadFn         func(context.Context, serviceapi.ForumAddPostOptions) (model.ForumThreadView, error)
+	forumAssignThreadFn         func(context.Context, serviceapi.ForumAssignThreadOptions) (model.ForumThreadView, error)
+	forumChangeStateFn          func(context.Context, serviceapi.ForumChangeStateOptions) (model.ForumThreadView, error)
+	forumSetPriorityFn          func(context.Context, serviceapi.ForumSetPriorityOptions) (model.ForumThreadView, error)
+	forumCloseThreadFn          func(context.Context, serviceapi.ForumChangeStateOptions) (model.ForumThreadView, error)
+	forumControlSignalFn        func(context.Context, serviceapi.ForumControlSignalOptions) (model.ForumThreadView, error)
+	forumStreamDebugSnapshotFn  func(context.Context, serviceapi.ForumDebugOptions) (model.ForumStreamDebugSnapshot, error)
+	forumListOutboxFn           func(context.Context, serviceapi.ForumOutboxListOptions) ([]model.ForumOutboxMessage, error)
+	forumRetryOutboxFn          func(context.Context, serviceapi.ForumOutboxRetryOptions) (int, error)
+	forumPurgeOutboxFn          func(context.Context, serviceapi.ForumOutboxPurgeOptions) (int, error)
+	forumRebuildProjectionsFn   func(context.Context, serviceapi.ForumRebuildProjectionsOptions) (model.ForumProjectionRebuildReport, error)
+	forumEscalateFn             func(context.Context, serviceapi.ForumEscalationPassOptions) (serviceapi.ForumEscalationPassResult, error)
+	enqueueForumEventWebhooksFn func(context.Context) (serviceapi.ForumEventWebhookResult, error)
+	enqueueRunStatusWebhooksFn  func(context.Context) (serviceapi.RunStatusWebhookResult, error)
+	ingestIntegrationFn         func(context.Context, serviceapi.IntegrationIngestOptions) (serviceapi.IntegrationIngestResult, error)
+	forumListThreadsFn          func(model.ForumThreadFilter) ([]model.ForumThreadView, error)
+	forumGetThreadFn            func(string) (*serviceapi.ForumThreadDetail, error)
+	forumListStatsFn            func(string, string) ([]model.ForumThreadStats, error)
+	forumWatchEventsFn          func(string, int64, int) ([]model.ForumEvent, error)
+	forumLatestEventSequenceFn  func() (int64, error)
+	forumSearchThreadsFn        func(serviceapi.ForumSearchThreadsOptions) ([]model.ForumThreadView, error)
+	forumListQueueFn            func(serviceapi.ForumQueueOptions) ([]model.ForumThreadView, error)
+	forumMarkThreadSeenFn       func(context.Context, serviceapi.ForumMarkThreadSeenOptions) (model.ForumThreadSeen, error)
+	guidancePromoteFn           func(context.Context, serviceapi.GuidanceLibraryPromoteOptions) (model.GuidanceAnswer, error)
+	guidanceRemoveFn            func(context.Context, string) error
+	guidanceSuggestFn           func(serviceapi.GuidanceSuggestOptions) ([]model.GuidanceSuggestion, error)
+	operatorPassFn              func(context.Context, serviceapi.OperatorPassOptions) (serviceapi.OperatorPassResult, error)
+	operatorDecisionsFn         func(context.Context, string, int) ([]serviceapi.OperatorRunDecisions, error)
+	releaseOperatorLeasesFn     func(context.Context, string) error
+	pruneOperatorDecisionsFn    func(context.Context) (int, error)
 }
 
 func (m *mockCore) Shutdown() {}
 
 func (m *mockCore) ProcessForumBusOnce(_ context.Context, _ int) (int, error) { return 0, nil }
+func (m *mockCore) RotateAgentTranscripts(_ context.Context) (int, error)     { return 0, nil }
 func (m *mockCore) ForumBusHealth() error                                     { return nil }
+func (m *mockCore) PruneOperatorDecisions(ctx context.Context) (int, error) {
+	if m.pruneOperatorDecisionsFn == nil {
+		return 0, nil
+	}
+	return m.pruneOperatorDecisionsFn(ctx)
+}
 func (m *mockCore) ForumOutboxStats() (model.ForumOutboxStats, error) {
 	return model.ForumOutboxStats{}, nil
 }
@@ -614,12 +1780,126 @@ func (m *mockCore) ForumStreamDebugSnapshot(ctx context.Context, options service
 	}
 	return m.forumStreamDebugSnapshotFn(ctx, options)
 }
+func (m *mockCore) ForumListOutbox(ctx context.Context, options serviceapi.ForumOutboxListOptions) ([]model.ForumOutboxMessage, error) {
+	if m.forumListOutboxFn == nil {
+		return nil, nil
+	}
+	return m.forumListOutboxFn(ctx, options)
+}
+func (m *mockCore) ForumRetryOutbox(ctx context.Context, options serviceapi.ForumOutboxRetryOptions) (int, error) {
+	if m.forumRetryOutboxFn == nil {
+		return 0, nil
+	}
+	return m.forumRetryOutboxFn(ctx, options)
+}
+func (m *mockCore) ForumPurgeOutbox(ctx context.Context, options serviceapi.ForumOutboxPurgeOptions) (int, error) {
+	if m.forumPurgeOutboxFn == nil {
+		return 0, nil
+	}
+	return m.forumPurgeOutboxFn(ctx, options)
+}
+func (m *mockCore) ForumRebuildProjections(ctx context.Context, options serviceapi.ForumRebuildProjectionsOptions) (model.ForumProjectionRebuildReport, error) {
+	if m.forumRebuildProjectionsFn == nil {
+		return model.ForumProjectionRebuildReport{}, nil
+	}
+	return m.forumRebuildProjectionsFn(ctx, options)
+}
+func (m *mockCore) ForumEscalateOverdueThreads(ctx context.Context, options serviceapi.ForumEscalationPassOptions) (serviceapi.ForumEscalationPassResult, error) {
+	if m.forumEscalateFn == nil {
+		return serviceapi.ForumEscalationPassResult{}, nil
+	}
+	return m.forumEscalateFn(ctx, options)
+}
+func (m *mockCore) EnqueueForumEventWebhooks(ctx context.Context) (serviceapi.ForumEventWebhookResult, error) {
+	if m.enqueueForumEventWebhooksFn == nil {
+		return serviceapi.ForumEventWebhookResult{}, nil
+	}
+	return m.enqueueForumEventWebhooksFn(ctx)
+}
+func (m *mockCore) EnqueueRunStatusWebhooks(ctx context.Context) (serviceapi.RunStatusWebhookResult, error) {
+	if m.enqueueRunStatusWebhooksFn == nil {
+		return serviceapi.RunStatusWebhookResult{}, nil
+	}
+	return m.enqueueRunStatusWebhooksFn(ctx)
+}
+func (m *mockCore) IngestIntegration(ctx context.Context, options serviceapi.IntegrationIngestOptions) (serviceapi.IntegrationIngestResult, error) {
+	if m.ingestIntegrationFn == nil {
+		return serviceapi.IntegrationIngestResult{}, nil
+	}
+	return m.ingestIntegrationFn(ctx, options)
+}
+func (m *mockCore) AuthenticateAPIToken(ctx context.Context, token string) (serviceapi.APIPrincipal, error) {
+	if m.authenticateFn == nil {
+		return serviceapi.APIPrincipal{}, serviceapi.ErrInvalidAPIToken
+	}
+	return m.authenticateFn(ctx, token)
+}
 func (m *mockCore) RunSnapshot(ctx context.Context, runID string) (serviceapi.RunSnapshot, error) {
 	if m.runSnapshotFn == nil {
 		return serviceapi.RunSnapshot{}, fmt.Errorf("run snapshot not implemented")
 	}
 	return m.runSnapshotFn(ctx, runID)
 }
+func (m *mockCore) RunStatusReport(ctx context.Context, runID string) (serviceapi.RunStatusReport, error) {
+	if m.runStatusReportFn == nil {
+		return serviceapi.RunStatusReport{}, fmt.Errorf("run status report not implemented")
+	}
+	return m.runStatusReportFn(ctx, runID)
+}
+func (m *mockCore) ReadAgentTranscript(ctx context.Context, options serviceapi.AgentTranscriptReadOptions) (serviceapi.AgentTranscriptChunk, error) {
+	if m.readAgentLogFn == nil {
+		return serviceapi.AgentTranscriptChunk{}, fmt.Errorf("agent transcript not implemented")
+	}
+	return m.readAgentLogFn(ctx, options)
+}
+func (m *mockCore) StartRun(ctx context.Context, options serviceapi.RunOptions) (serviceapi.RunResult, error) {
+	if m.startRunFn == nil {
+		return serviceapi.RunResult{}, fmt.Errorf("start run not implemented")
+	}
+	return m.startRunFn(ctx, options)
+}
+func (m *mockCore) ResolveRunID(ctx context.Context, runID string, ticket string) (string, error) {
+	if m.resolveRunIDFn == nil {
+		return runID, nil
+	}
+	return m.resolveRunIDFn(ctx, runID, ticket)
+}
+func (m *mockCore) StopRun(ctx context.Context, runID string) error {
+	if m.stopRunFn == nil {
+		return nil
+	}
+	return m.stopRunFn(ctx, runID)
+}
+func (m *mockCore) ResumeRun(_ context.Context, _ string) error      { return nil }
+func (m *mockCore) StartResumeRun(_ context.Context, _ string) error { return nil }
+func (m *mockCore) RestartRun(ctx context.Context, options serviceapi.RestartOptions) (serviceapi.RestartResult, error) {
+	if m.restartRunFn == nil {
+		return serviceapi.RestartResult{RunID: options.RunID}, nil
+	}
+	return m.restartRunFn(ctx, options)
+}
+func (m *mockCore) IterateRun(_ context.Context, options serviceapi.IterateOptions) (serviceapi.IterateResult, error) {
+	return serviceapi.IterateResult{RunID: options.RunID}, nil
+}
+func (m *mockCore) CommitRun(ctx context.Context, options serviceapi.CommitOptions) (serviceapi.CommitResult, error) {
+	if m.commitRunFn == nil {
+		return serviceapi.CommitResult{RunID: options.RunID}, nil
+	}
+	return m.commitRunFn(ctx, options)
+}
+func (m *mockCore) OpenPullRequests(_ context.Context, options serviceapi.PullRequestOptions) (serviceapi.PullRequestResult, error) {
+	return serviceapi.PullRequestResult{RunID: options.RunID}, nil
+}
+func (m *mockCore) MergeRun(_ context.Context, options serviceapi.MergeOptions) (serviceapi.MergeResult, error) {
+	return serviceapi.MergeResult{RunID: options.RunID}, nil
+}
+func (m *mockCore) CloseRun(_ context.Context, _ serviceapi.CloseOptions) error { return nil }
+func (m *mockCore) CleanupRun(ctx context.Context, options serviceapi.CleanupOptions) (serviceapi.CleanupResult, error) {
+	if m.cleanupRunFn == nil {
+		return serviceapi.CleanupResult{RunID: options.RunID}, nil
+	}
+	return m.cleanupRunFn(ctx, options)
+}
 func (m *mockCore) ListRunSnapshots(ctx context.Context, ticket string) ([]serviceapi.RunSnapshot, error) {
 	if m.listRunSnapshotsFn == nil {
 		return []serviceapi.RunSnapshot{}, nil
@@ -698,6 +1978,12 @@ func (m *mockCore) ForumWatchEvents(ticket string, cursor int64, limit int) ([]m
 	}
 	return m.forumWatchEventsFn(ticket, cursor, limit)
 }
+func (m *mockCore) ForumLatestEventSequence() (int64, error) {
+	if m.forumLatestEventSequenceFn == nil {
+		return 0, nil
+	}
+	return m.forumLatestEventSequenceFn()
+}
 
 func (m *mockCore) ForumSearchThreads(options serviceapi.ForumSearchThreadsOptions) ([]model.ForumThreadView, error) {
 	if m.forumSearchThreadsFn == nil {
@@ -719,3 +2005,51 @@ func (m *mockCore) ForumMarkThreadSeen(ctx context.Context, options serviceapi.F
 	}
 	return m.forumMarkThreadSeenFn(ctx, options)
 }
+
+func (m *mockCore) GuidanceLibraryList(_ model.GuidanceAnswerFilter) ([]model.GuidanceAnswer, error) {
+	return []model.GuidanceAnswer{}, nil
+}
+
+func (m *mockCore) GuidanceLibraryPromote(ctx context.Context, options serviceapi.GuidanceLibraryPromoteOptions) (model.GuidanceAnswer, error) {
+	if m.guidancePromoteFn == nil {
+		return model.GuidanceAnswer{}, nil
+	}
+	return m.guidancePromoteFn(ctx, options)
+}
+
+func (m *mockCore) GuidanceLibraryRemove(ctx context.Context, answerID string) error {
+	if m.guidanceRemoveFn == nil {
+		return nil
+	}
+	return m.guidanceRemoveFn(ctx, answerID)
+}
+
+func (m *mockCore) GuidanceLibrarySync(_ context.Context) (int, error) { return 0, nil }
+
+func (m *mockCore) GuidanceSuggest(options serviceapi.GuidanceSuggestOptions) ([]model.GuidanceSuggestion, error) {
+	if m.guidanceSuggestFn == nil {
+		return []model.GuidanceSuggestion{}, nil
+	}
+	return m.guidanceSuggestFn(options)
+}
+
+func (m *mockCore) OperatorPass(ctx context.Context, options serviceapi.OperatorPassOptions) (serviceapi.OperatorPassResult, error) {
+	if m.operatorPassFn == nil {
+		return serviceapi.OperatorPassResult{}, nil
+	}
+	return m.operatorPassFn(ctx, options)
+}
+
+func (m *mockCore) OperatorDecisions(ctx context.Context, runID string, limit int) ([]serviceapi.OperatorRunDecisions, error) {
+	if m.operatorDecisionsFn == nil {
+		return []serviceapi.OperatorRunDecisions{}, nil
+	}
+	return m.operatorDecisionsFn(ctx, runID, limit)
+}
+
+func (m *mockCore) ReleaseOperatorLeases(ctx context.Context, holder string) error {
+	if m.releaseOperatorLeasesFn == nil {
+		return nil
+	}
+	return m.releaseOperatorLeasesFn(ctx, holder)
+}
@@ -0,0 +1,181 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"metawsm/internal/model"
+	"metawsm/internal/serviceapi"
+)
+
+const (
+	AuthModeOff   = "off"
+	AuthModeToken = "token"
+)
+
+var (
+	allAPIRoles      = []model.APIRole{model.APIRoleViewer, model.APIRoleHuman, model.APIRoleOperator, model.APIRoleAgent}
+	forumWriterRoles = []model.APIRole{model.APIRoleHuman, model.APIRoleOperator, model.APIRoleAgent}
+	forumTriageRoles = []model.APIRole{model.APIRoleHuman, model.APIRoleOperator}
+	controlRoles     = []model.APIRole{model.APIRoleOperator, model.APIRoleAgent}
+	operatorRoles    = []model.APIRole{model.APIRoleOperator}
+)
+
+// apiAccessRule grants roles access to routes matching method and pattern. A
+// "*" pattern segment matches any single path segment.
+type apiAccessRule struct {
+	method  string
+	pattern string
+	public  bool
+	roles   []model.APIRole
+}
+
+// apiAccessRules are checked in order; the first match wins and unmatched
+// routes fall through to the read-only rule for GETs or are denied.
+var apiAccessRules = []apiAccessRule{
+	{method: http.MethodGet, pattern: "/api/v1/health", public: true},
+	{method: http.MethodPost, pattern: "/api/v1/integrations/*", public: true},
+	{method: http.MethodPost, pattern: "/api/v1/runs", roles: operatorRoles},
+	{method: http.MethodPost, pattern: "/api/v1/runs/*/*", roles: operatorRoles},
+	{method: http.MethodPost, pattern: "/api/v1/forum/threads", roles: forumWriterRoles},
+	{method: http.MethodPost, pattern: "/api/v1/forum/threads/*/posts", roles: forumWriterRoles},
+	{method: http.MethodPost, pattern: "/api/v1/forum/threads/*/seen", roles: allAPIRoles},
+	{method: http.MethodPost, pattern: "/api/v1/forum/threads/*/assign", roles: forumTriageRoles},
+	{method: http.MethodPost, pattern: "/api/v1/forum/threads/*/state", roles: forumTriageRoles},
+	{method: http.MethodPost, pattern: "/api/v1/forum/threads/*/priority", roles: forumTriageRoles},
+	{method: http.MethodPost, pattern: "/api/v1/forum/threads/*/close", roles: forumTriageRoles},
+	{method: http.MethodPost, pattern: "/api/v1/forum/control/signal", roles: controlRoles},
+	{method: http.MethodPost, pattern: "/api/v1/forum/outbox/*", roles: operatorRoles},
+	{method: http.MethodPost, pattern: "/api/v1/forum/projections/rebuild", roles: operatorRoles},
+	{method: http.MethodPost, pattern: "/api/v1/forum/escalations/run", roles: operatorRoles},
+	{method: http.MethodPost, pattern: "/api/v1/forum/guidance/library", roles: operatorRoles},
+	{method: http.MethodPost, pattern: "/api/v1/forum/guidance/library/*", roles: operatorRoles},
+	{method: http.MethodPost, pattern: "/api/v1/forum/guidance/library/*/remove", roles: operatorRoles},
+	{method: http.MethodPost, pattern: "/api/v1/operator/*", roles: operatorRoles},
+	{method: http.MethodGet, pattern: "", roles: allAPIRoles},
+}
+
+type principalContextKey struct{}
+
+func normalizeAuthMode(mode string) string {
+	if strings.TrimSpace(strings.ToLower(mode)) == AuthModeToken {
+		return AuthModeToken
+	}
+	return AuthModeOff
+}
+
+// authorize wraps an API handler with bearer-token authentication and the
+// per-route role check. With auth off every request passes through unchanged.
+func (r *Runtime) authorize(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if r.opts.AuthMode != AuthModeToken {
+			next(w, req)
+			return
+		}
+		rule, ok := matchAPIAccessRule(req.Method, req.URL.Path)
+		if ok && rule.public {
+			next(w, req)
+			return
+		}
+		token := requestToken(req)
+		if token == "" {
+			writeAPIError(w, http.StatusUnauthorized, "unauthorized", "bearer token is required")
+			return
+		}
+		principal, err := r.service.AuthenticateAPIToken(req.Context(), token)
+		if err != nil {
+			if errors.Is(err, serviceapi.ErrInvalidAPIToken) {
+				writeAPIError(w, http.StatusUnauthorized, "unauthorized", err.Error())
+				return
+			}
+			writeAPIError(w, http.StatusInternalServerError, "auth_failed", err.Error())
+			return
+		}
+		if !ok || !roleAllowed(rule.roles, principal.Role) {
+			writeAPIError(w, http.StatusForbidden, "forbidden", fmt.Sprintf("role %s may not %s %s", principal.Role, req.Method, req.URL.Path))
+			return
+		}
+		next(w, req.WithContext(context.WithValue(req.Context(), principalContextKey{}, principal)))
+	}
+}
+
+func principalFromContext(ctx context.Context) (serviceapi.APIPrincipal, bool) {
+	principal, ok := ctx.Value(principalContextKey{}).(serviceapi.APIPrincipal)
+	return principal, ok
+}
+
+// requestCredential returns the authenticated principal for service calls that
+// enforce agent scope, or nil when auth is off.
+func requestCredential(req *http.Request) *serviceapi.APIPrincipal {
+	if principal, ok := principalFromContext(req.Context()); ok {
+		return &principal
+	}
+	return nil
+}
+
+// requestActor returns the actor recorded for a write: the authenticated
+// principal when there is one, otherwise whatever the client sent.
+func requestActor(req *http.Request, actorType string, actorName string) (model.ForumActorType, string) {
+	if principal, ok := principalFromContext(req.Context()); ok {
+		return principal.Role.ActorType(), principal.ActorName
+	}
+	return model.ForumActorType(strings.TrimSpace(actorType)), strings.TrimSpace(actorName)
+}
+
+func requestActorName(req *http.Request, actorName string) string {
+	if principal, ok := principalFromContext(req.Context()); ok {
+		return principal.ActorName
+	}
+	return strings.TrimSpace(actorName)
+}
+
+// requestToken reads the bearer token. Browsers cannot set headers on
+// WebSocket or EventSource requests, so GETs may pass access_token instead.
+func requestToken(req *http.Request) string {
+	header := strings.TrimSpace(req.Header.Get("Authorization"))
+	if len(header) > len("Bearer ") && strings.EqualFold(header[:len("Bearer ")], "Bearer ") {
+		return strings.TrimSpace(header[len("Bearer "):])
+	}
+	if req.Method == http.MethodGet {
+		return strings.TrimSpace(req.URL.Query().Get("access_token"))
+	}
+	return ""
+}
+
+func matchAPIAccessRule(method string, path string) (apiAccessRule, bool) {
+	for _, rule := range apiAccessRules {
+		if rule.method != method {
+			continue
+		}
+		if rule.pattern == "" || routeMatches(rule.pattern, path) {
+			return rule, true
+		}
+	}
+	return apiAccessRule{}, false
+}
+
+func routeMatches(pattern string, path string) bool {
+	patternSegments := strings.Split(strings.Trim(pattern, "/"), "/")
+	pathSegments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(patternSegments) != len(pathSegments) {
+		return false
+	}
+	for i, segment := range patternSegments {
+		if segment != "*" && segment != pathSegments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func roleAllowed(roles []model.APIRole, role model.APIRole) bool {
+	for _, allowed := range roles {
+		if allowed == role {
+			return true
+		}
+	}
+	return false
+}
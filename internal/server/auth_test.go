@@ -0,0 +1,205 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"metawsm/internal/model"
+	"metawsm/internal/serviceapi"
+)
+
+func newTokenAuthMux(core *mockCore) *http.ServeMux {
+	principals := map[string]serviceapi.APIPrincipal{
+		"viewer-token":   {TokenID: "tok_v", Role: model.APIRoleViewer, ActorName: "dashboard"},
+		"human-token":    {TokenID: "tok_h", Role: model.APIRoleHuman, ActorName: "kball"},
+		"agent-token":    {TokenID: "tok_a", Role: model.APIRoleAgent, ActorName: "agent"},
+		"operator-token": {TokenID: "tok_o", Role: model.APIRoleOperator, ActorName: "operator"},
+	}
+	core.authenticateFn = func(_ context.Context, token string) (serviceapi.APIPrincipal, error) {
+		principal, ok := principals[token]
+		if !ok {
+			return serviceapi.APIPrincipal{}, serviceapi.ErrInvalidAPIToken
+		}
+		return principal, nil
+	}
+	runtime := newTestRuntime(core)
+	runtime.opts.AuthMode = AuthModeToken
+	mux := http.NewServeMux()
+	runtime.registerRoutes(mux)
+	return mux
+}
+
+func TestAuthorizeEnforcesRolesPerEndpoint(t *testing.T) {
+	core := &mockCore{
+		listRunSnapshotsFn: func(context.Context, string) ([]serviceapi.RunSnapshot, error) { return nil, nil },
+		stopRunFn:          func(context.Context, string) error { return nil },
+		forumOpenThreadFn: func(context.Context, serviceapi.ForumOpenThreadOptions) (model.ForumThreadView, error) {
+			return model.ForumThreadView{}, nil
+		},
+	}
+	mux := newTokenAuthMux(core)
+
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		token  string
+		status int
+	}{
+		{name: "health is public", method: http.MethodGet, path: "/api/v1/health", status: http.StatusOK},
+		{name: "missing token", method: http.MethodGet, path: "/api/v1/runs", status: http.StatusUnauthorized},
+		{name: "unknown token", method: http.MethodGet, path: "/api/v1/runs", token: "nope", status: http.StatusUnauthorized},
+		{name: "viewer reads runs", method: http.MethodGet, path: "/api/v1/runs", token: "viewer-token", status: http.StatusOK},
+		{name: "query token on GET", method: http.MethodGet, path: "/api/v1/runs?access_token=viewer-token", status: http.StatusOK},
+		{name: "query token ignored on POST", method: http.MethodPost, path: "/api/v1/runs/run-1/stop?access_token=operator-token", status: http.StatusUnauthorized},
+		{name: "viewer cannot open thread", method: http.MethodPost, path: "/api/v1/forum/threads", body: `{}`, token: "viewer-token", status: http.StatusForbidden},
+		{name: "agent opens thread", method: http.MethodPost, path: "/api/v1/forum/threads", body: `{}`, token: "agent-token", status: http.StatusOK},
+		{name: "agent cannot stop run", method: http.MethodPost, path: "/api/v1/runs/run-1/stop", token: "agent-token", status: http.StatusForbidden},
+		{name: "human cannot stop run", method: http.MethodPost, path: "/api/v1/runs/run-1/stop", token: "human-token", status: http.StatusForbidden},
+		{name: "operator stops run", method: http.MethodPost, path: "/api/v1/runs/run-1/stop", token: "operator-token", status: http.StatusOK},
+		{name: "human cannot replay dead letters", method: http.MethodPost, path: "/api/v1/forum/outbox/retry", body: `{"all":true}`, token: "human-token", status: http.StatusForbidden},
+		{name: "operator replays dead letters", method: http.MethodPost, path: "/api/v1/forum/outbox/retry", body: `{"all":true}`, token: "operator-token", status: http.StatusOK},
+		{name: "integrations authenticate with their own secret", method: http.MethodPost, path: "/api/v1/integrations/ci", body: `{}`, status: http.StatusOK},
+		{name: "agent cannot force escalations", method: http.MethodPost, path: "/api/v1/forum/escalations/run", body: `{}`, token: "agent-token", status: http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		request := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+		if tc.token != "" {
+			request.Header.Set("Authorization", "Bearer "+tc.token)
+		}
+		response := httptest.NewRecorder()
+		mux.ServeHTTP(response, request)
+		if response.Code != tc.status {
+			t.Fatalf("%s: expected %d, got %d: %s", tc.name, tc.status, response.Code, response.Body.String())
+		}
+	}
+}
+
+func TestAuthorizeFillsForumActorFromPrincipal(t *testing.T) {
+	core := &mockCore{
+		forumAddPostFn: func(_ context.Context, options serviceapi.ForumAddPostOptions) (model.ForumThreadView, error) {
+			if options.ActorType != model.ForumActorHuman || options.ActorName != "kball" {
+				t.Fatalf("expected actor from token, got %s/%s", options.ActorType, options.ActorName)
+			}
+			return model.ForumThreadView{ThreadID: options.ThreadID}, nil
+		},
+	}
+	mux := newTokenAuthMux(core)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/forum/threads/fthr-1/posts", strings.NewReader(`{"body":"hi","actor_type":"operator","actor_name":"someone-else"}`))
+	request.Header.Set("Authorization", "Bearer human-token")
+	response := httptest.NewRecorder()
+	mux.ServeHTTP(response, request)
+	if response.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", response.Code, response.Body.String())
+	}
+}
+
+func TestControlSignalMapsOutOfScopeCredentialToForbidden(t *testing.T) {
+	core := &mockCore{
+		forumControlSignalFn: func(_ context.Context, options serviceapi.ForumControlSignalOptions) (model.ForumThreadView, error) {
+			if options.Credential == nil || options.Credential.RunID != "run-1" {
+				t.Fatalf("expected scoped credential to reach the core, got %+v", options.Credential)
+			}
+			if options.RunID != options.Credential.RunID {
+				return model.ForumThreadView{}, serviceapi.ErrControlSignalOutOfScope
+			}
+			return model.ForumThreadView{ThreadID: "fthr-1"}, nil
+		},
+	}
+	mux := newTokenAuthMux(core)
+	base := core.authenticateFn
+	core.authenticateFn = func(ctx context.Context, token string) (serviceapi.APIPrincipal, error) {
+		if token == "scoped-agent-token" {
+			return serviceapi.APIPrincipal{TokenID: "tok_s", Role: model.APIRoleAgent, ActorName: "agent", RunID: "run-1", AgentName: "agent"}, nil
+		}
+		return base(ctx, token)
+	}
+
+	for _, tc := range []struct {
+		runID  string
+		status int
+	}{
+		{runID: "run-1", status: http.StatusOK},
+		{runID: "run-2", status: http.StatusForbidden},
+	} {
+		body := `{"run_id":"` + tc.runID + `","ticket":"T-1","agent_name":"agent","payload":{"schema_version":1,"control_type":"guidance_request","run_id":"` + tc.runID + `","agent_name":"agent","question":"?"}}`
+		request := httptest.NewRequest(http.MethodPost, "/api/v1/forum/control/signal", strings.NewReader(body))
+		request.Header.Set("Authorization", "Bearer scoped-agent-token")
+		response := httptest.NewRecorder()
+		mux.ServeHTTP(response, request)
+		if response.Code != tc.status {
+			t.Fatalf("%s: expected %d, got %d: %s", tc.runID, tc.status, response.Code, response.Body.String())
+		}
+	}
+}
+
+func TestRemoteCoreSendsBearerToken(t *testing.T) {
+	stopped := ""
+	core := &mockCore{
+		stopRunFn: func(_ context.Context, runID string) error {
+			stopped = runID
+			return nil
+		},
+	}
+	server := httptest.NewServer(newTokenAuthMux(core))
+	defer server.Close()
+
+	err := serviceapi.NewRemoteCore(server.URL, time.Second).StopRun(context.Background(), "run-1")
+	if err == nil || !strings.Contains(err.Error(), "unauthorized") {
+		t.Fatalf("expected unauthorized error without token, got %v", err)
+	}
+	remote := serviceapi.NewRemoteCore(server.URL, time.Second).WithToken("operator-token")
+	if err := remote.StopRun(context.Background(), "run-1"); err != nil {
+		t.Fatalf("remote stop with token: %v", err)
+	}
+	if stopped != "run-1" {
+		t.Fatalf("expected stop for run-1, got %q", stopped)
+	}
+}
+
+func TestForumWritesMapOutOfScopeCredentialToForbidden(t *testing.T) {
+	core := &mockCore{
+		forumOpenThreadFn: func(_ context.Context, options serviceapi.ForumOpenThreadOptions) (model.ForumThreadView, error) {
+			if options.Credential == nil || options.Credential.RunID != "run-1" {
+				t.Fatalf("expected scoped credential to reach the core, got %+v", options.Credential)
+			}
+			return model.ForumThreadView{}, serviceapi.ErrForumWriteOutOfScope
+		},
+		forumAddPostFn: func(_ context.Context, options serviceapi.ForumAddPostOptions) (model.ForumThreadView, error) {
+			if options.Credential == nil || options.Credential.RunID != "run-1" {
+				t.Fatalf("expected scoped credential to reach the core, got %+v", options.Credential)
+			}
+			return model.ForumThreadView{}, serviceapi.ErrForumWriteOutOfScope
+		},
+	}
+	mux := newTokenAuthMux(core)
+	base := core.authenticateFn
+	core.authenticateFn = func(ctx context.Context, token string) (serviceapi.APIPrincipal, error) {
+		if token == "scoped-agent-token" {
+			return serviceapi.APIPrincipal{TokenID: "tok_s", Role: model.APIRoleAgent, ActorName: "agent", RunID: "run-1", AgentName: "agent"}, nil
+		}
+		return base(ctx, token)
+	}
+
+	for _, tc := range []struct {
+		path string
+		body string
+	}{
+		{path: "/api/v1/forum/threads", body: `{"ticket":"T-1","run_id":"run-2","title":"t","body":"b"}`},
+		{path: "/api/v1/forum/threads/fthr-2/posts", body: `{"body":"b"}`},
+	} {
+		request := httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader(tc.body))
+		request.Header.Set("Authorization", "Bearer scoped-agent-token")
+		response := httptest.NewRecorder()
+		mux.ServeHTTP(response, request)
+		if response.Code != http.StatusForbidden {
+			t.Fatalf("%s: expected 403, got %d: %s", tc.path, response.Code, response.Body.String())
+		}
+	}
+}
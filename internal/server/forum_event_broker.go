@@ -1,17 +1,52 @@
 package server
 
 import (
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"metawsm/internal/model"
 )
 
-type forumEventSubscriber struct {
-	id     int64
-	ticket string
-	runID  string
-	ch     chan model.ForumEvent
+// ForumEventSubscription is one stream's view of the broker. Its ticket filter
+// can change while the stream is open; counters track how well the subscriber
+// keeps up with fan-out.
+type ForumEventSubscription struct {
+	id          int64
+	broker      *ForumEventBroker
+	transport   string
+	runID       string
+	connectedAt time.Time
+	ch          chan model.ForumEvent
+
+	mu sync.RWMutex
+	// tickets is nil when the subscription follows every ticket.
+	tickets map[string]string
+
+	delivered atomic.Int64
+	dropped   atomic.Int64
+}
+
+// ForumSubscriberStats is a point-in-time view of one subscription.
+type ForumSubscriberStats struct {
+	ID            int64     `json:"id"`
+	Transport     string    `json:"transport"`
+	Tickets       []string  `json:"tickets,omitempty"`
+	RunID         string    `json:"run_id,omitempty"`
+	ConnectedAt   time.Time `json:"connected_at"`
+	Delivered     int64     `json:"delivered"`
+	Dropped       int64     `json:"dropped"`
+	QueueDepth    int       `json:"queue_depth"`
+	QueueCapacity int       `json:"queue_capacity"`
+}
+
+// ForumStreamSummary aggregates broker fan-out since startup.
+type ForumStreamSummary struct {
+	Subscribers int   `json:"subscribers"`
+	Delivered   int64 `json:"delivered"`
+	Dropped     int64 `json:"dropped"`
 }
 
 type ForumEventBroker struct {
@@ -19,7 +54,10 @@ type ForumEventBroker struct {
 	closed      bool
 	nextID      int64
 	bufferSize  int
-	subscribers map[int64]forumEventSubscriber
+	subscribers map[int64]*ForumEventSubscription
+
+	delivered atomic.Int64
+	dropped   atomic.Int64
 }
 
 func NewForumEventBroker(bufferSize int) *ForumEventBroker {
@@ -28,57 +66,98 @@ func NewForumEventBroker(bufferSize int) *ForumEventBroker {
 	}
 	return &ForumEventBroker{
 		bufferSize:  bufferSize,
-		subscribers: make(map[int64]forumEventSubscriber),
+		subscribers: make(map[int64]*ForumEventSubscription),
 	}
 }
 
 func (b *ForumEventBroker) Subscribe(ticket string, runID string) (<-chan model.ForumEvent, func()) {
+	var tickets []string
+	if strings.TrimSpace(ticket) != "" {
+		tickets = []string{ticket}
+	}
+	subscription := b.SubscribeTickets("", tickets, runID)
+	return subscription.Events(), subscription.Close
+}
+
+// SubscribeTickets registers a subscription for events on any of tickets (all
+// tickets when empty), optionally narrowed to one run. transport labels the
+// subscription in stats.
+func (b *ForumEventBroker) SubscribeTickets(transport string, tickets []string, runID string) *ForumEventSubscription {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	ch := make(chan model.ForumEvent, b.bufferSize)
-	if b.closed {
-		close(ch)
-		return ch, func() {}
+	subscription := &ForumEventSubscription{
+		broker:      b,
+		transport:   strings.TrimSpace(transport),
+		runID:       strings.TrimSpace(runID),
+		connectedAt: time.Now().UTC(),
+		ch:          make(chan model.ForumEvent, b.bufferSize),
 	}
-
-	b.nextID++
-	subscriber := forumEventSubscriber{
-		id:     b.nextID,
-		ticket: strings.TrimSpace(ticket),
-		runID:  strings.TrimSpace(runID),
-		ch:     ch,
+	if len(normalizeForumTickets(tickets)) > 0 {
+		subscription.tickets = map[string]string{}
+		subscription.addTicketsLocked(tickets)
 	}
-	b.subscribers[subscriber.id] = subscriber
-	return ch, func() {
-		b.unsubscribe(subscriber.id)
+	if b.closed {
+		close(subscription.ch)
+		return subscription
 	}
+	b.nextID++
+	subscription.id = b.nextID
+	b.subscribers[subscription.id] = subscription
+	return subscription
 }
 
 func (b *ForumEventBroker) Publish(event model.ForumEvent) int {
+	// Sends never block, so fan-out runs under the read lock; that keeps
+	// unsubscribe from closing a channel mid-send.
 	b.mu.RLock()
+	defer b.mu.RUnlock()
 	if b.closed {
-		b.mu.RUnlock()
 		return 0
 	}
-	snapshot := make([]forumEventSubscriber, 0, len(b.subscribers))
-	for _, subscriber := range b.subscribers {
-		snapshot = append(snapshot, subscriber)
-	}
-	b.mu.RUnlock()
 
 	delivered := 0
-	for _, subscriber := range snapshot {
-		if !matchesEventFilter(subscriber, event) {
+	for _, subscriber := range b.subscribers {
+		if !subscriber.matches(event) {
 			continue
 		}
-		if tryPublishEvent(subscriber.ch, event) {
+		ok, dropped := tryPublishEvent(subscriber.ch, event)
+		if dropped > 0 {
+			subscriber.dropped.Add(int64(dropped))
+			b.dropped.Add(int64(dropped))
+		}
+		if ok {
+			subscriber.delivered.Add(1)
+			b.delivered.Add(1)
 			delivered++
 		}
 	}
 	return delivered
 }
 
+// Stats lists open subscriptions ordered by id.
+func (b *ForumEventBroker) Stats() []ForumSubscriberStats {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	out := make([]ForumSubscriberStats, 0, len(b.subscribers))
+	for _, subscriber := range b.subscribers {
+		out = append(out, subscriber.Stats())
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+func (b *ForumEventBroker) Summary() ForumStreamSummary {
+	b.mu.RLock()
+	subscribers := len(b.subscribers)
+	b.mu.RUnlock()
+	return ForumStreamSummary{
+		Subscribers: subscribers,
+		Delivered:   b.delivered.Load(),
+		Dropped:     b.dropped.Load(),
+	}
+}
+
 func (b *ForumEventBroker) Close() {
 	b.mu.Lock()
 	defer b.mu.Unlock()
@@ -103,31 +182,145 @@ func (b *ForumEventBroker) unsubscribe(id int64) {
 	close(subscriber.ch)
 }
 
-func matchesEventFilter(subscriber forumEventSubscriber, event model.ForumEvent) bool {
-	if subscriber.ticket != "" && !strings.EqualFold(strings.TrimSpace(event.Envelope.Ticket), subscriber.ticket) {
-		return false
+func (s *ForumEventSubscription) Events() <-chan model.ForumEvent {
+	return s.ch
+}
+
+func (s *ForumEventSubscription) Close() {
+	if s.id == 0 {
+		return
 	}
-	if subscriber.runID != "" && !strings.EqualFold(strings.TrimSpace(event.Envelope.RunID), subscriber.runID) {
+	s.broker.unsubscribe(s.id)
+}
+
+// Dropped reports how many events were discarded because the subscriber fell
+// behind. Streams compare it between batches to know when to re-read from the
+// store.
+func (s *ForumEventSubscription) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+// AllTickets reports whether the subscription follows every ticket.
+func (s *ForumEventSubscription) AllTickets() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tickets == nil
+}
+
+// Tickets returns the subscribed tickets in sorted order.
+func (s *ForumEventSubscription) Tickets() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]string, 0, len(s.tickets))
+	for _, ticket := range s.tickets {
+		out = append(out, ticket)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// AddTickets extends a ticket-scoped subscription and returns the tickets that
+// were not already subscribed.
+func (s *ForumEventSubscription) AddTickets(tickets []string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tickets == nil {
+		return nil
+	}
+	return s.addTicketsLocked(tickets)
+}
+
+func (s *ForumEventSubscription) RemoveTickets(tickets []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tickets == nil {
+		return
+	}
+	for _, ticket := range normalizeForumTickets(tickets) {
+		delete(s.tickets, strings.ToLower(ticket))
+	}
+}
+
+func (s *ForumEventSubscription) Stats() ForumSubscriberStats {
+	return ForumSubscriberStats{
+		ID:            s.id,
+		Transport:     s.transport,
+		Tickets:       s.Tickets(),
+		RunID:         s.runID,
+		ConnectedAt:   s.connectedAt,
+		Delivered:     s.delivered.Load(),
+		Dropped:       s.dropped.Load(),
+		QueueDepth:    len(s.ch),
+		QueueCapacity: cap(s.ch),
+	}
+}
+
+func (s *ForumEventSubscription) addTicketsLocked(tickets []string) []string {
+	added := []string{}
+	for _, ticket := range normalizeForumTickets(tickets) {
+		key := strings.ToLower(ticket)
+		if _, ok := s.tickets[key]; ok {
+			continue
+		}
+		s.tickets[key] = ticket
+		added = append(added, ticket)
+	}
+	return added
+}
+
+func (s *ForumEventSubscription) matches(event model.ForumEvent) bool {
+	if s.runID != "" && !strings.EqualFold(strings.TrimSpace(event.Envelope.RunID), s.runID) {
 		return false
 	}
-	return true
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.tickets == nil {
+		return true
+	}
+	_, ok := s.tickets[strings.ToLower(strings.TrimSpace(event.Envelope.Ticket))]
+	return ok
 }
 
-func tryPublishEvent(ch chan model.ForumEvent, event model.ForumEvent) bool {
+// normalizeForumTickets trims tickets and drops blanks and case-insensitive
+// duplicates, keeping the first spelling.
+func normalizeForumTickets(tickets []string) []string {
+	out := make([]string, 0, len(tickets))
+	seen := map[string]struct{}{}
+	for _, ticket := range tickets {
+		ticket = strings.TrimSpace(ticket)
+		if ticket == "" {
+			continue
+		}
+		key := strings.ToLower(ticket)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		out = append(out, ticket)
+	}
+	return out
+}
+
+// tryPublishEvent never blocks broker fan-out. It reports whether event was
+// queued and how many events were discarded to make room or because the
+// subscriber stayed full.
+func tryPublishEvent(ch chan model.ForumEvent, event model.ForumEvent) (bool, int) {
 	select {
 	case ch <- event:
-		return true
+		return true, 0
 	default:
 		// Drop one stale message and retry once to avoid blocking broker fanout.
+		dropped := 0
 		select {
 		case <-ch:
+			dropped++
 		default:
 		}
 		select {
 		case ch <- event:
-			return true
+			return true, dropped
 		default:
-			return false
+			return false, dropped + 1
 		}
 	}
 }
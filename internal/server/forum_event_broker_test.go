@@ -105,6 +105,61 @@ func TestForumEventBrokerDropsStaleMessagesForSlowSubscribers(t *testing.T) {
 	}
 }
 
+func TestForumEventBrokerSubscriptionTracksTicketChanges(t *testing.T) {
+	broker := NewForumEventBroker(8)
+	t.Cleanup(broker.Close)
+
+	subscription := broker.SubscribeTickets("websocket", []string{"METAWSM-011", " metawsm-011 ", "METAWSM-012"}, "")
+	defer subscription.Close()
+	if got := subscription.Tickets(); len(got) != 2 {
+		t.Fatalf("expected 2 normalized tickets, got %v", got)
+	}
+
+	added := subscription.AddTickets([]string{"METAWSM-012", "METAWSM-013"})
+	if len(added) != 1 || added[0] != "METAWSM-013" {
+		t.Fatalf("expected only METAWSM-013 to be added, got %v", added)
+	}
+	subscription.RemoveTickets([]string{"metawsm-011"})
+
+	broker.Publish(model.ForumEvent{Sequence: 1, Envelope: model.ForumEnvelope{Ticket: "METAWSM-011"}})
+	broker.Publish(model.ForumEvent{Sequence: 2, Envelope: model.ForumEnvelope{Ticket: "METAWSM-012"}})
+	broker.Publish(model.ForumEvent{Sequence: 3, Envelope: model.ForumEnvelope{Ticket: "METAWSM-013"}})
+
+	assertReceivesSequences(t, subscription.Events(), []int64{2, 3})
+}
+
+func TestForumEventBrokerStatsCountDeliveredAndDropped(t *testing.T) {
+	broker := NewForumEventBroker(1)
+	t.Cleanup(broker.Close)
+
+	subscription := broker.SubscribeTickets("sse", []string{"METAWSM-011"}, "")
+	defer subscription.Close()
+
+	for sequence := int64(1); sequence <= 3; sequence++ {
+		broker.Publish(model.ForumEvent{Sequence: sequence, Envelope: model.ForumEnvelope{Ticket: "METAWSM-011"}})
+	}
+
+	stats := broker.Stats()
+	if len(stats) != 1 {
+		t.Fatalf("expected one subscriber, got %d", len(stats))
+	}
+	if stats[0].Transport != "sse" || stats[0].Delivered != 3 || stats[0].Dropped != 2 {
+		t.Fatalf("unexpected subscriber stats %+v", stats[0])
+	}
+	if stats[0].QueueDepth != 1 || stats[0].QueueCapacity != 1 {
+		t.Fatalf("unexpected queue stats %+v", stats[0])
+	}
+	summary := broker.Summary()
+	if summary.Subscribers != 1 || summary.Delivered != 3 || summary.Dropped != 2 {
+		t.Fatalf("unexpected summary %+v", summary)
+	}
+
+	subscription.Close()
+	if got := broker.Summary().Subscribers; got != 0 {
+		t.Fatalf("expected no subscribers after close, got %d", got)
+	}
+}
+
 func assertReceivesSequences(t *testing.T, ch <-chan model.ForumEvent, expected []int64) {
 	t.Helper()
 	for _, sequence := range expected {
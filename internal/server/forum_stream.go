@@ -0,0 +1,299 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"metawsm/internal/model"
+)
+
+// forumStreamSink is the transport side of a forum event stream. WebSocket and
+// SSE streams share subscription, catch-up, and heartbeat handling and differ
+// only in how frames are written.
+type forumStreamSink interface {
+	sendEvents(events []model.ForumEvent, nextCursor int64) error
+	sendHeartbeat(nextCursor int64) error
+	sendFrame(frameType string, payload map[string]any) error
+}
+
+// forumStreamRequest is the initial stream selection. Cursor is the last
+// sequence the client has seen; only later events are sent. A request without
+// a cursor (resume false) starts at the newest stored event and receives live
+// events only.
+type forumStreamRequest struct {
+	tickets []string
+	runID   string
+	cursor  int64
+	resume  bool
+	limit   int
+}
+
+// forumStreamCommand changes the tickets a WebSocket stream follows. When
+// Cursor is set, history after it is replayed for newly added tickets.
+type forumStreamCommand struct {
+	Type    string   `json:"type"`
+	Tickets []string `json:"tickets"`
+	Cursor  *int64   `json:"cursor,omitempty"`
+}
+
+// parseForumStreamRequest reads tickets from repeated ticket parameters or a
+// comma-separated tickets parameter. The resume cursor comes from the
+// Last-Event-ID header, then last_event_id, then cursor; cursor=0 replays the
+// whole history.
+func parseForumStreamRequest(req *http.Request) (forumStreamRequest, error) {
+	query := req.URL.Query()
+	tickets := append([]string{}, query["ticket"]...)
+	for _, value := range query["tickets"] {
+		tickets = append(tickets, strings.Split(value, ",")...)
+	}
+	cursorValue := strings.TrimSpace(req.Header.Get("Last-Event-ID"))
+	if cursorValue == "" {
+		cursorValue = query.Get("last_event_id")
+	}
+	if strings.TrimSpace(cursorValue) == "" {
+		cursorValue = query.Get("cursor")
+	}
+	cursor, err := parseInt64Query(cursorValue, 0)
+	if err != nil {
+		return forumStreamRequest{}, err
+	}
+	limit, err := parseIntQuery(query.Get("limit"), 100)
+	if err != nil {
+		return forumStreamRequest{}, err
+	}
+	if limit <= 0 {
+		limit = 100
+	}
+	return forumStreamRequest{
+		tickets: normalizeForumTickets(tickets),
+		runID:   strings.TrimSpace(query.Get("run_id")),
+		cursor:  cursor,
+		resume:  strings.TrimSpace(cursorValue) != "",
+		limit:   limit,
+	}, nil
+}
+
+// runForumStream subscribes to the broker before replaying history so nothing
+// published during catch-up is lost; sequences at or below the cursor are
+// skipped. A subscriber that falls behind and loses events to backpressure is
+// resynced from the store. It returns nil when ctx ends or the broker closes.
+func (r *Runtime) runForumStream(ctx context.Context, sink forumStreamSink, request forumStreamRequest, transport string, commands <-chan forumStreamCommand) error {
+	var subscription *ForumEventSubscription
+	var events <-chan model.ForumEvent
+	if r.eventBroker != nil {
+		subscription = r.eventBroker.SubscribeTickets(transport, request.tickets, request.runID)
+		defer subscription.Close()
+		events = subscription.Events()
+	}
+	// streamTickets is nil when the stream follows every ticket; a scoped
+	// stream whose tickets were all unsubscribed reads nothing.
+	streamTickets := func() []string {
+		if subscription == nil {
+			if len(request.tickets) == 0 {
+				return nil
+			}
+			return request.tickets
+		}
+		if subscription.AllTickets() {
+			return nil
+		}
+		return subscription.Tickets()
+	}
+
+	var nextCursor int64
+	var err error
+	if request.resume {
+		nextCursor, err = r.sendForumCatchUp(sink, streamTickets(), request.runID, request.cursor, request.cursor, request.limit)
+	} else {
+		nextCursor, err = r.service.ForumLatestEventSequence()
+	}
+	if err != nil {
+		return err
+	}
+	var lastDropped int64
+	if subscription != nil {
+		lastDropped = subscription.Dropped()
+	}
+	heartbeat := time.NewTicker(r.streamHeartbeatInterval())
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if dropped := subscription.Dropped(); dropped != lastDropped {
+				lastDropped = dropped
+				nextCursor, err = r.sendForumCatchUp(sink, streamTickets(), request.runID, nextCursor, nextCursor, request.limit)
+				if err != nil {
+					return err
+				}
+			}
+			batch := make([]model.ForumEvent, 0, request.limit)
+			if event.Sequence > nextCursor {
+				nextCursor = event.Sequence
+				batch = append(batch, event)
+			}
+		drain:
+			for len(batch) < request.limit {
+				select {
+				case nextEvent, ok := <-events:
+					if !ok {
+						return nil
+					}
+					if nextEvent.Sequence <= nextCursor {
+						continue
+					}
+					nextCursor = nextEvent.Sequence
+					batch = append(batch, nextEvent)
+				default:
+					break drain
+				}
+			}
+			if len(batch) == 0 {
+				continue
+			}
+			if err := sink.sendEvents(batch, nextCursor); err != nil {
+				return err
+			}
+		case command := <-commands:
+			nextCursor, err = r.applyForumStreamCommand(sink, subscription, command, request, nextCursor)
+			if err != nil {
+				return err
+			}
+		case <-heartbeat.C:
+			if err := sink.sendHeartbeat(nextCursor); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (r *Runtime) applyForumStreamCommand(sink forumStreamSink, subscription *ForumEventSubscription, command forumStreamCommand, request forumStreamRequest, nextCursor int64) (int64, error) {
+	commandType := strings.TrimSpace(strings.ToLower(command.Type))
+	if commandType != "subscribe" && commandType != "unsubscribe" {
+		return nextCursor, sink.sendFrame("error", map[string]any{"message": "unknown command type " + strconv.Quote(command.Type)})
+	}
+	if subscription == nil || subscription.AllTickets() {
+		return nextCursor, sink.sendFrame("error", map[string]any{"message": "stream follows all tickets; open it with ticket parameters to manage subscriptions"})
+	}
+	if commandType == "unsubscribe" {
+		subscription.RemoveTickets(command.Tickets)
+		return nextCursor, sink.sendFrame("subscription", map[string]any{"tickets": subscription.Tickets()})
+	}
+	added := subscription.AddTickets(command.Tickets)
+	if command.Cursor != nil && len(added) > 0 {
+		var err error
+		nextCursor, err = r.sendForumCatchUp(sink, added, request.runID, *command.Cursor, nextCursor, request.limit)
+		if err != nil {
+			return nextCursor, err
+		}
+	}
+	return nextCursor, sink.sendFrame("subscription", map[string]any{"tickets": subscription.Tickets()})
+}
+
+// sendForumCatchUp replays stored events after `after` for tickets (every
+// ticket when nil) in sequence order and returns the new high-water cursor,
+// which never moves below floor. Each round reads at most limit events per
+// ticket and sends them before reading on, so a long backlog is never held in
+// memory.
+func (r *Runtime) sendForumCatchUp(sink forumStreamSink, tickets []string, runID string, after int64, floor int64, limit int) (int64, error) {
+	if tickets == nil {
+		tickets = []string{""}
+	}
+	highWater := floor
+	cursor := after
+	for {
+		events, pageEnd, more, err := r.readForumEventsPage(tickets, cursor, limit)
+		if err != nil {
+			return highWater, err
+		}
+		if pageEnd > highWater {
+			highWater = pageEnd
+		}
+		events = filterForumEventsByRunID(events, runID)
+		for start := 0; start < len(events); start += limit {
+			end := start + limit
+			if end > len(events) {
+				end = len(events)
+			}
+			batchCursor := events[end-1].Sequence
+			if end == len(events) {
+				batchCursor = highWater
+			} else if batchCursor < floor {
+				batchCursor = floor
+			}
+			if err := sink.sendEvents(events[start:end], batchCursor); err != nil {
+				return highWater, err
+			}
+		}
+		if !more {
+			return highWater, nil
+		}
+		cursor = pageEnd
+	}
+}
+
+// readForumEventsPage reads one page of up to limit events after `after` for
+// each ticket and merges them by sequence. When some ticket filled its page,
+// only events up to the lowest last sequence among full pages are returned so
+// a later round cannot deliver an older event; more reports that case.
+// pageEnd is the sequence the next round reads after.
+func (r *Runtime) readForumEventsPage(tickets []string, after int64, limit int) ([]model.ForumEvent, int64, bool, error) {
+	pageEnd := after
+	fullEnd := int64(0)
+	more := false
+	seen := map[int64]struct{}{}
+	merged := []model.ForumEvent{}
+	for _, ticket := range tickets {
+		page, err := r.service.ForumWatchEvents(ticket, after, limit)
+		if err != nil {
+			return nil, after, false, err
+		}
+		for _, event := range page {
+			if _, ok := seen[event.Sequence]; ok {
+				continue
+			}
+			seen[event.Sequence] = struct{}{}
+			merged = append(merged, event)
+		}
+		if len(page) == 0 {
+			continue
+		}
+		last := page[len(page)-1].Sequence
+		if last > pageEnd {
+			pageEnd = last
+		}
+		if len(page) >= limit && last > after && (!more || last < fullEnd) {
+			more = true
+			fullEnd = last
+		}
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].Sequence < merged[j].Sequence })
+	if !more {
+		return merged, pageEnd, false, nil
+	}
+	cut := sort.Search(len(merged), func(i int) bool { return merged[i].Sequence > fullEnd })
+	return merged[:cut], fullEnd, true, nil
+}
+
+func filterForumEventsByRunID(events []model.ForumEvent, runID string) []model.ForumEvent {
+	runID = strings.TrimSpace(runID)
+	if runID == "" {
+		return events
+	}
+	filtered := make([]model.ForumEvent, 0, len(events))
+	for _, event := range events {
+		if strings.EqualFold(strings.TrimSpace(event.Envelope.RunID), runID) {
+			filtered = append(filtered, event)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,283 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"metawsm/internal/serviceapi"
+)
+
+type OperatorSupervisorSnapshot struct {
+	Running bool   `json:"running"`
+	Paused  bool   `json:"paused"`
+	Holder  string `json:"holder"`
+	// IntervalSeconds is the time between supervision passes.
+	IntervalSeconds int `json:"interval_seconds"`
+	// LLMModeOverride replaces operator.llm.mode from policy when set;
+	// LLMMode is the mode the last pass actually used.
+	LLMModeOverride string     `json:"llm_mode_override,omitempty"`
+	LLMMode         string     `json:"llm_mode,omitempty"`
+	DryRun          bool       `json:"dry_run"`
+	LastPassAt      *time.Time `json:"last_pass_at,omitempty"`
+	LastErrorAt     *time.Time `json:"last_error_at,omitempty"`
+	LastError       string     `json:"last_error,omitempty"`
+	SupervisedRuns  int        `json:"supervised_runs"`
+	LeasedElsewhere int        `json:"leased_elsewhere"`
+	TotalPasses     int64      `json:"total_passes"`
+	TotalDecisions  int64      `json:"total_decisions"`
+	TotalExecuted   int64      `json:"total_executed"`
+}
+
+// OperatorSupervisor runs operator passes inside the daemon so runs stay
+// supervised without a terminal. Each pass only acts on runs whose lease this
+// supervisor holds; leases are released on shutdown.
+type OperatorSupervisor struct {
+	service    serviceapi.Core
+	interval   time.Duration
+	policyPath string
+	logger     *log.Logger
+
+	mu sync.RWMutex
+	// passMu is held for the length of a pass, so Pause can wait for an
+	// in-flight pass before releasing the leases it is acting under.
+	passMu   sync.Mutex
+	doneChan chan struct{}
+	wake     chan struct{}
+	snapshot OperatorSupervisorSnapshot
+}
+
+func NewOperatorSupervisor(service serviceapi.Core, interval time.Duration, policyPath string, llmMode string, dryRun bool, paused bool, logger *log.Logger) *OperatorSupervisor {
+	if interval <= 0 {
+		interval = 15 * time.Second
+	}
+	return &OperatorSupervisor{
+		service:    service,
+		interval:   interval,
+		policyPath: strings.TrimSpace(policyPath),
+		logger:     logger,
+		wake:       make(chan struct{}, 1),
+		snapshot: OperatorSupervisorSnapshot{
+			Paused:          paused,
+			Holder:          operatorHolderID(),
+			IntervalSeconds: int(interval / time.Second),
+			LLMModeOverride: strings.TrimSpace(strings.ToLower(llmMode)),
+			DryRun:          dryRun,
+		},
+	}
+}
+
+// operatorHolderID names this daemon in operator leases and decision events.
+func operatorHolderID() string {
+	host, err := os.Hostname()
+	if err != nil || strings.TrimSpace(host) == "" {
+		host = "unknown"
+	}
+	return fmt.Sprintf("serve:%s:%d", host, os.Getpid())
+}
+
+func (o *OperatorSupervisor) Start(ctx context.Context) {
+	if o == nil || o.service == nil {
+		return
+	}
+	o.mu.Lock()
+	if o.doneChan != nil {
+		o.mu.Unlock()
+		return
+	}
+	o.doneChan = make(chan struct{})
+	done := o.doneChan
+	o.snapshot.Running = true
+	o.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		o.loop(ctx)
+		o.release()
+		o.mu.Lock()
+		o.snapshot.Running = false
+		o.mu.Unlock()
+	}()
+}
+
+func (o *OperatorSupervisor) Wait(timeout time.Duration) bool {
+	if o == nil {
+		return true
+	}
+	o.mu.RLock()
+	done := o.doneChan
+	o.mu.RUnlock()
+	if done == nil {
+		return true
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+func (o *OperatorSupervisor) Snapshot() OperatorSupervisorSnapshot {
+	if o == nil {
+		return OperatorSupervisorSnapshot{}
+	}
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	copySnapshot := o.snapshot
+	copySnapshot.LastPassAt = cloneTimePtr(o.snapshot.LastPassAt)
+	copySnapshot.LastErrorAt = cloneTimePtr(o.snapshot.LastErrorAt)
+	return copySnapshot
+}
+
+// Pause stops further passes, waits for one already running to finish, and
+// releases this supervisor's leases so another operator can take over its
+// runs.
+func (o *OperatorSupervisor) Pause() OperatorSupervisorSnapshot {
+	o.mu.Lock()
+	wasPaused := o.snapshot.Paused
+	o.snapshot.Paused = true
+	o.mu.Unlock()
+	if !wasPaused {
+		o.passMu.Lock()
+		o.release()
+		o.passMu.Unlock()
+	}
+	return o.Snapshot()
+}
+
+// Resume re-enables passes and runs one immediately.
+func (o *OperatorSupervisor) Resume() OperatorSupervisorSnapshot {
+	o.mu.Lock()
+	o.snapshot.Paused = false
+	o.mu.Unlock()
+	o.trigger()
+	return o.Snapshot()
+}
+
+// SetLLMMode overrides the policy LLM mode; an empty mode restores it.
+func (o *OperatorSupervisor) SetLLMMode(mode string) (OperatorSupervisorSnapshot, error) {
+	mode = strings.TrimSpace(strings.ToLower(mode))
+	if mode != "" {
+		if _, err := serviceapi.ResolveOperatorLLMMode(mode, ""); err != nil {
+			return OperatorSupervisorSnapshot{}, err
+		}
+	}
+	o.mu.Lock()
+	o.snapshot.LLMModeOverride = mode
+	o.mu.Unlock()
+	return o.Snapshot(), nil
+}
+
+func (o *OperatorSupervisor) trigger() {
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (o *OperatorSupervisor) loop(ctx context.Context) {
+	ticker := time.NewTicker(o.interval)
+	defer ticker.Stop()
+
+	o.runPass(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			o.runPass(ctx)
+		case <-o.wake:
+			o.runPass(ctx)
+		}
+	}
+}
+
+func (o *OperatorSupervisor) runPass(ctx context.Context) {
+	o.passMu.Lock()
+	defer o.passMu.Unlock()
+	o.mu.RLock()
+	paused := o.snapshot.Paused
+	options := serviceapi.OperatorPassOptions{
+		PolicyPath: o.policyPath,
+		LLMMode:    o.snapshot.LLMModeOverride,
+		DryRun:     o.snapshot.DryRun,
+		Holder:     o.snapshot.Holder,
+		LeaseTTL:   3 * o.interval,
+	}
+	o.mu.RUnlock()
+	if paused {
+		return
+	}
+
+	now := time.Now().UTC()
+	result, err := o.service.OperatorPass(ctx, options)
+	if err != nil && ctx.Err() != nil {
+		return
+	}
+
+	supervised, leasedElsewhere := 0, 0
+	decisions, executed := 0, 0
+	for _, run := range result.Runs {
+		switch {
+		case run.LeaseHolder != "":
+			leasedElsewhere++
+		case run.Active:
+			supervised++
+		}
+		if run.Decision == nil {
+			continue
+		}
+		decisions++
+		if run.Decision.Executed {
+			executed++
+		}
+		if o.logger != nil {
+			o.logger.Printf(
+				"operator decision: run=%s event=%s intent=%s source=%s executed=%t reason=%q",
+				run.Decision.RunID,
+				run.Decision.Event,
+				run.Decision.Intent,
+				run.Decision.Source,
+				run.Decision.Executed,
+				run.Decision.Reason,
+			)
+		}
+	}
+	if o.logger != nil {
+		for _, warning := range result.Warnings {
+			o.logger.Printf("operator warning: %s", warning)
+		}
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.snapshot.LastPassAt = timePtr(now)
+	o.snapshot.TotalPasses++
+	if err != nil {
+		o.snapshot.LastErrorAt = timePtr(now)
+		o.snapshot.LastError = strings.TrimSpace(err.Error())
+		return
+	}
+	o.snapshot.LastError = ""
+	o.snapshot.LLMMode = result.LLMMode
+	o.snapshot.SupervisedRuns = supervised
+	o.snapshot.LeasedElsewhere = leasedElsewhere
+	o.snapshot.TotalDecisions += int64(decisions)
+	o.snapshot.TotalExecuted += int64(executed)
+}
+
+func (o *OperatorSupervisor) release() {
+	if o == nil || o.service == nil {
+		return
+	}
+	if err := o.service.ReleaseOperatorLeases(context.Background(), o.Snapshot().Holder); err != nil && o.logger != nil {
+		o.logger.Printf("operator warning: release leases: %v", err)
+	}
+}
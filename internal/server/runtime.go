@@ -22,14 +22,35 @@ type Options struct {
 	WorkerInterval  time.Duration
 	WorkerBatchSize int
 	WorkerLogPeriod time.Duration
+	// EscalationInterval is how often the worker checks forum threads for
+	// SLA escalation.
+	EscalationInterval time.Duration
+	// WebhookPollInterval is how often run statuses are polled for webhook
+	// notifications.
+	WebhookPollInterval time.Duration
+	// OperatorInterval is how often the in-daemon operator supervises
+	// active runs.
+	OperatorInterval time.Duration
+	// OperatorPolicyPath overrides the default policy file for operator passes.
+	OperatorPolicyPath string
+	// OperatorLLMMode overrides operator.llm.mode from policy when set.
+	OperatorLLMMode string
+	// OperatorDryRun records decisions without executing actions.
+	OperatorDryRun bool
+	// OperatorPaused starts the operator paused until resumed via the API.
+	OperatorPaused  bool
 	ShutdownTimeout time.Duration
 	StreamHeartbeat time.Duration
+	// AuthMode is "off" (default) or "token" to require bearer tokens on /api/v1.
+	AuthMode string
 }
 
 type Runtime struct {
 	opts          Options
 	service       serviceapi.Core
 	worker        *ForumWorker
+	webhooks      *WebhookNotifier
+	operator      *OperatorSupervisor
 	startedAt     time.Time
 	server        *http.Server
 	eventBroker   *ForumEventBroker
@@ -38,12 +59,15 @@ type Runtime struct {
 }
 
 type HealthResponse struct {
-	Status    string                 `json:"status"`
-	StartedAt time.Time              `json:"started_at"`
-	Now       time.Time              `json:"now"`
-	Worker    ForumWorkerSnapshot    `json:"worker"`
-	Outbox    model.ForumOutboxStats `json:"outbox"`
-	ForumBus  HealthBusStatus        `json:"forum_bus"`
+	Status    string                     `json:"status"`
+	StartedAt time.Time                  `json:"started_at"`
+	Now       time.Time                  `json:"now"`
+	Worker    ForumWorkerSnapshot        `json:"worker"`
+	Outbox    model.ForumOutboxStats     `json:"outbox"`
+	ForumBus  HealthBusStatus            `json:"forum_bus"`
+	Stream    ForumStreamSummary         `json:"stream"`
+	Webhooks  WebhookNotifierSnapshot    `json:"webhooks"`
+	Operator  OperatorSupervisorSnapshot `json:"operator"`
 }
 
 type HealthBusStatus struct {
@@ -58,12 +82,15 @@ func NewRuntime(options Options) (*Runtime, error) {
 		return nil, err
 	}
 	logger := log.New(os.Stdout, "", 0)
+	eventBroker := NewForumEventBroker(128)
 	runtime := &Runtime{
 		opts:        options,
 		service:     service,
-		worker:      NewForumWorker(service, options.WorkerInterval, options.WorkerBatchSize, options.WorkerLogPeriod, logger),
+		worker:      NewForumWorker(service, options.WorkerInterval, options.WorkerBatchSize, options.EscalationInterval, options.WorkerLogPeriod, logger),
+		webhooks:    NewWebhookNotifier(service, eventBroker, options.WebhookPollInterval, logger),
+		operator:    NewOperatorSupervisor(service, options.OperatorInterval, options.OperatorPolicyPath, options.OperatorLLMMode, options.OperatorDryRun, options.OperatorPaused, logger),
 		startedAt:   time.Now().UTC(),
-		eventBroker: NewForumEventBroker(128),
+		eventBroker: eventBroker,
 		streamBeat:  options.StreamHeartbeat,
 	}
 	mux := http.NewServeMux()
@@ -92,6 +119,8 @@ func (r *Runtime) Run(ctx context.Context) error {
 	workerCtx, workerCancel := context.WithCancel(context.Background())
 	defer workerCancel()
 	r.worker.Start(workerCtx)
+	r.webhooks.Start(workerCtx)
+	r.operator.Start(workerCtx)
 	r.startEventPump()
 
 	errCh := make(chan error, 1)
@@ -108,6 +137,8 @@ func (r *Runtime) Run(ctx context.Context) error {
 		if err != nil {
 			workerCancel()
 			_ = r.worker.Wait(2 * time.Second)
+			_ = r.webhooks.Wait(2 * time.Second)
+			_ = r.operator.Wait(2 * time.Second)
 			r.stopForumEventPump()
 			r.service.Shutdown()
 			return err
@@ -119,12 +150,16 @@ func (r *Runtime) Run(ctx context.Context) error {
 	if err := r.server.Shutdown(shutdownCtx); err != nil {
 		workerCancel()
 		_ = r.worker.Wait(2 * time.Second)
+		_ = r.webhooks.Wait(2 * time.Second)
+		_ = r.operator.Wait(2 * time.Second)
 		r.stopForumEventPump()
 		r.service.Shutdown()
 		return err
 	}
 	workerCancel()
 	_ = r.worker.Wait(2 * time.Second)
+	_ = r.webhooks.Wait(2 * time.Second)
+	_ = r.operator.Wait(2 * time.Second)
 	r.stopForumEventPump()
 	r.service.Shutdown()
 	return nil
@@ -146,12 +181,22 @@ func normalizeOptions(options Options) Options {
 	if options.WorkerLogPeriod <= 0 {
 		options.WorkerLogPeriod = 15 * time.Second
 	}
+	if options.EscalationInterval <= 0 {
+		options.EscalationInterval = time.Minute
+	}
+	if options.WebhookPollInterval <= 0 {
+		options.WebhookPollInterval = 15 * time.Second
+	}
+	if options.OperatorInterval <= 0 {
+		options.OperatorInterval = 15 * time.Second
+	}
 	if options.ShutdownTimeout <= 0 {
 		options.ShutdownTimeout = 5 * time.Second
 	}
 	if options.StreamHeartbeat <= 0 {
 		options.StreamHeartbeat = 25 * time.Second
 	}
+	options.AuthMode = normalizeAuthMode(options.AuthMode)
 	return options
 }
 
@@ -218,6 +263,11 @@ func (r *Runtime) handleHealth(w http.ResponseWriter, _ *http.Request) {
 		Worker:    r.worker.Snapshot(),
 		Outbox:    outboxStats,
 		ForumBus:  bus,
+		Webhooks:  r.webhooks.Snapshot(),
+		Operator:  r.operator.Snapshot(),
+	}
+	if r.eventBroker != nil {
+		response.Stream = r.eventBroker.Summary()
 	}
 	statusCode := http.StatusOK
 	if !bus.Healthy {
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"metawsm/internal/model"
+)
+
+// sseRetryMillis is the reconnect delay suggested to EventSource clients.
+const sseRetryMillis = 3000
+
+func acceptsEventStream(req *http.Request) bool {
+	for _, value := range req.Header.Values("Accept") {
+		for _, part := range strings.Split(value, ",") {
+			mediaType, _, _ := strings.Cut(part, ";")
+			if strings.EqualFold(strings.TrimSpace(mediaType), "text/event-stream") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// serveForumEventStream streams forum events as Server-Sent Events. Each
+// event batch carries its cursor as the SSE id, so a reconnecting EventSource
+// resumes from Last-Event-ID without replaying what it already saw.
+func (r *Runtime) serveForumEventStream(w http.ResponseWriter, req *http.Request, request forumStreamRequest) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeAPIError(w, http.StatusInternalServerError, "streaming_unsupported", "response writer does not support flushing")
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	sink := &sseForumSink{w: w, flusher: flusher}
+	if _, err := fmt.Fprintf(w, "retry: %d\n\n", sseRetryMillis); err != nil {
+		return
+	}
+	flusher.Flush()
+	if err := r.runForumStream(req.Context(), sink, request, "sse", nil); err != nil {
+		_ = sink.sendFrame("error", map[string]any{"message": err.Error()})
+	}
+}
+
+type sseForumSink struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+func (s *sseForumSink) sendEvents(events []model.ForumEvent, nextCursor int64) error {
+	return s.write("forum.events", strconv.FormatInt(nextCursor, 10), forumEventsPayload(events, nextCursor))
+}
+
+func (s *sseForumSink) sendHeartbeat(nextCursor int64) error {
+	return s.write("heartbeat", "", heartbeatPayload(nextCursor))
+}
+
+func (s *sseForumSink) sendFrame(frameType string, payload map[string]any) error {
+	frame := map[string]any{"type": frameType}
+	for key, value := range payload {
+		frame[key] = value
+	}
+	return s.write(frameType, "", frame)
+}
+
+// write emits one SSE message. Events without an id leave the client's
+// Last-Event-ID untouched.
+func (s *sseForumSink) write(event string, id string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	message := strings.Builder{}
+	if id != "" {
+		message.WriteString("id: ")
+		message.WriteString(id)
+		message.WriteString("\n")
+	}
+	message.WriteString("event: ")
+	message.WriteString(event)
+	message.WriteString("\ndata: ")
+	message.Write(body)
+	message.WriteString("\n\n")
+	if _, err := s.w.Write([]byte(message.String())); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
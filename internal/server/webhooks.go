@@ -0,0 +1,196 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"metawsm/internal/model"
+	"metawsm/internal/serviceapi"
+)
+
+type WebhookNotifierSnapshot struct {
+	Running        bool       `json:"running"`
+	Transitions    int64      `json:"transitions"`
+	ForumEvents    int64      `json:"forum_events"`
+	EventsSeen     int64      `json:"events_seen"`
+	Enqueued       int64      `json:"enqueued"`
+	LastEnqueuedAt *time.Time `json:"last_enqueued_at,omitempty"`
+	LastErrorAt    *time.Time `json:"last_error_at,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+}
+
+// WebhookNotifier turns stored forum events and run transitions into webhook
+// deliveries. The service reads both behind stored cursors; broker events only
+// wake the notifier early, so a subscriber falling behind loses nothing. It
+// only queues deliveries; the forum worker delivers them through the outbox,
+// which owns retries.
+type WebhookNotifier struct {
+	service      serviceapi.Core
+	broker       *ForumEventBroker
+	pollInterval time.Duration
+	logger       *log.Logger
+
+	mu       sync.RWMutex
+	doneChan chan struct{}
+	snapshot WebhookNotifierSnapshot
+}
+
+func NewWebhookNotifier(service serviceapi.Core, broker *ForumEventBroker, pollInterval time.Duration, logger *log.Logger) *WebhookNotifier {
+	if pollInterval <= 0 {
+		pollInterval = 15 * time.Second
+	}
+	return &WebhookNotifier{
+		service:      service,
+		broker:       broker,
+		pollInterval: pollInterval,
+		logger:       logger,
+	}
+}
+
+func (n *WebhookNotifier) Start(ctx context.Context) {
+	if n == nil || n.service == nil {
+		return
+	}
+	n.mu.Lock()
+	if n.doneChan != nil {
+		n.mu.Unlock()
+		return
+	}
+	n.doneChan = make(chan struct{})
+	done := n.doneChan
+	n.snapshot.Running = true
+	n.mu.Unlock()
+
+	var wake <-chan model.ForumEvent
+	var subscription *ForumEventSubscription
+	if n.broker != nil {
+		subscription = n.broker.SubscribeTickets("webhook", nil, "")
+		wake = subscription.Events()
+	}
+	go func() {
+		defer close(done)
+		if subscription != nil {
+			defer subscription.Close()
+		}
+		n.loop(ctx, wake)
+		n.mu.Lock()
+		n.snapshot.Running = false
+		n.mu.Unlock()
+	}()
+}
+
+func (n *WebhookNotifier) Wait(timeout time.Duration) bool {
+	if n == nil {
+		return true
+	}
+	n.mu.RLock()
+	done := n.doneChan
+	n.mu.RUnlock()
+	if done == nil {
+		return true
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+func (n *WebhookNotifier) Snapshot() WebhookNotifierSnapshot {
+	if n == nil {
+		return WebhookNotifierSnapshot{}
+	}
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	copySnapshot := n.snapshot
+	copySnapshot.LastEnqueuedAt = cloneTimePtr(n.snapshot.LastEnqueuedAt)
+	copySnapshot.LastErrorAt = cloneTimePtr(n.snapshot.LastErrorAt)
+	return copySnapshot
+}
+
+func (n *WebhookNotifier) loop(ctx context.Context, wake <-chan model.ForumEvent) {
+	ticker := time.NewTicker(n.pollInterval)
+	defer ticker.Stop()
+
+	n.pollRuns(ctx)
+	n.pollForumEvents(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case _, ok := <-wake:
+			if !ok {
+				return
+			}
+			drainForumEvents(wake)
+			n.pollForumEvents(ctx)
+		case <-ticker.C:
+			n.pollRuns(ctx)
+			n.pollForumEvents(ctx)
+		}
+	}
+}
+
+// drainForumEvents empties queued wake-ups so a burst costs one store read.
+func drainForumEvents(events <-chan model.ForumEvent) {
+	for {
+		select {
+		case _, ok := <-events:
+			if !ok {
+				return
+			}
+		default:
+			return
+		}
+	}
+}
+
+// pollForumEvents queues webhooks for forum events stored since the last poll.
+func (n *WebhookNotifier) pollForumEvents(ctx context.Context) {
+	result, err := n.service.EnqueueForumEventWebhooks(ctx)
+	n.mu.Lock()
+	n.snapshot.ForumEvents += int64(result.Events)
+	n.recordEnqueuedLocked(result.Events, result.Enqueued)
+	n.mu.Unlock()
+	if err != nil && ctx.Err() == nil {
+		n.recordError(fmt.Errorf("enqueue forum event webhooks: %w", err))
+	}
+}
+
+// pollRuns queues webhooks for run transitions recorded since the last poll.
+// The service keeps the cursor, so a restart neither drops nor replays them.
+func (n *WebhookNotifier) pollRuns(ctx context.Context) {
+	result, err := n.service.EnqueueRunStatusWebhooks(ctx)
+	n.mu.Lock()
+	n.snapshot.Transitions += int64(result.Transitions)
+	n.recordEnqueuedLocked(result.Transitions, result.Enqueued)
+	n.mu.Unlock()
+	if err != nil && ctx.Err() == nil {
+		n.recordError(fmt.Errorf("enqueue run status webhooks: %w", err))
+	}
+}
+
+func (n *WebhookNotifier) recordEnqueuedLocked(seen int, enqueued int) {
+	n.snapshot.EventsSeen += int64(seen)
+	if enqueued > 0 {
+		n.snapshot.Enqueued += int64(enqueued)
+		n.snapshot.LastEnqueuedAt = timePtr(time.Now().UTC())
+	}
+}
+
+func (n *WebhookNotifier) recordError(err error) {
+	n.mu.Lock()
+	n.snapshot.LastErrorAt = timePtr(time.Now().UTC())
+	n.snapshot.LastError = strings.TrimSpace(err.Error())
+	n.mu.Unlock()
+	if n.logger != nil {
+		n.logger.Printf("webhook notifier: %v", err)
+	}
+}
@@ -1,38 +1,93 @@
 package server
 
 import (
+	"bufio"
+	"context"
 	"crypto/sha1"
 	"encoding/base64"
 	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
 	"metawsm/internal/model"
+	"metawsm/internal/serviceapi"
 )
 
-const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+const (
+	websocketGUID           = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	websocketWriteTimeout   = 10 * time.Second
+	websocketMaxMessageSize = 1 << 20
+	agentLogPollInterval    = 250 * time.Millisecond
+)
+
+const (
+	websocketOpContinuation byte = 0x0
+	websocketOpText         byte = 0x1
+	websocketOpBinary       byte = 0x2
+	websocketOpClose        byte = 0x8
+	websocketOpPing         byte = 0x9
+	websocketOpPong         byte = 0xA
+)
+
+const (
+	websocketCloseNormal          = 1000
+	websocketCloseProtocolError   = 1002
+	websocketCloseUnsupportedData = 1003
+	websocketCloseInvalidPayload  = 1007
+	websocketCloseMessageTooBig   = 1009
+	websocketCloseInternalError   = 1011
+)
+
+var errWebSocketClosed = errors.New("websocket connection closed")
+
+// websocketConn is a server-side RFC 6455 connection. Writes are serialized so
+// the read loop can answer pings and close frames while a stream is sending.
+type websocketConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+
+	writeMu   sync.Mutex
+	closeSent bool
+}
+
+// websocketCloseError ends the connection with a close frame carrying code.
+type websocketCloseError struct {
+	code   int
+	reason string
+}
+
+func (e *websocketCloseError) Error() string {
+	return fmt.Sprintf("websocket close %d: %s", e.code, e.reason)
+}
+
+type websocketFrame struct {
+	fin     bool
+	opcode  byte
+	payload []byte
+}
 
 func (r *Runtime) handleForumStream(w http.ResponseWriter, req *http.Request) {
 	if req.Method != http.MethodGet {
 		writeAPIError(w, http.StatusMethodNotAllowed, "method_not_allowed", "only GET is supported")
 		return
 	}
-	ticket := strings.TrimSpace(req.URL.Query().Get("ticket"))
-	cursor, err := parseInt64Query(req.URL.Query().Get("cursor"), 0)
+	request, err := parseForumStreamRequest(req)
 	if err != nil {
-		writeAPIError(w, http.StatusBadRequest, "invalid_cursor", err.Error())
+		writeAPIError(w, http.StatusBadRequest, "invalid_stream_request", err.Error())
 		return
 	}
-	limit, err := parseIntQuery(req.URL.Query().Get("limit"), 100)
-	if err != nil {
-		writeAPIError(w, http.StatusBadRequest, "invalid_limit", err.Error())
+	if !isWebSocketUpgrade(req) && acceptsEventStream(req) {
+		r.serveForumEventStream(w, req, request)
 		return
 	}
-	runID := strings.TrimSpace(req.URL.Query().Get("run_id"))
 
 	conn, err := upgradeWebSocket(w, req)
 	if err != nil {
@@ -41,128 +96,154 @@ func (r *Runtime) handleForumStream(w http.ResponseWriter, req *http.Request) {
 	}
 	defer conn.Close()
 
-	if err := r.streamForumEvents(conn, ticket, runID, cursor, limit); err != nil {
-		_ = writeWebSocketJSON(conn, map[string]any{
+	ctx, cancel := context.WithCancel(req.Context())
+	defer cancel()
+	commands := make(chan forumStreamCommand)
+	go func() {
+		defer cancel()
+		conn.readMessages(func(message []byte) {
+			var command forumStreamCommand
+			if err := json.Unmarshal(message, &command); err != nil {
+				_ = conn.writeJSON(map[string]any{"type": "error", "message": "invalid command: " + err.Error()})
+				return
+			}
+			select {
+			case commands <- command:
+			case <-ctx.Done():
+			}
+		})
+	}()
+
+	if err := r.runForumStream(ctx, websocketForumSink{conn: conn}, request, "websocket", commands); err != nil {
+		_ = conn.writeJSON(map[string]any{
 			"type":    "error",
 			"message": err.Error(),
 		})
+		_ = conn.writeClose(websocketCloseInternalError, "stream failed")
+		return
 	}
+	_ = conn.writeClose(websocketCloseNormal, "")
+}
+
+// websocketForumSink writes forum stream frames as JSON text messages.
+type websocketForumSink struct {
+	conn *websocketConn
 }
 
-func (r *Runtime) streamForumEvents(conn net.Conn, ticket string, runID string, cursor int64, limit int) error {
-	if limit <= 0 {
-		limit = 100
+func (s websocketForumSink) sendEvents(events []model.ForumEvent, nextCursor int64) error {
+	return s.conn.writeJSON(forumEventsPayload(events, nextCursor))
+}
+
+func (s websocketForumSink) sendHeartbeat(nextCursor int64) error {
+	return s.conn.writeJSON(heartbeatPayload(nextCursor))
+}
+
+func (s websocketForumSink) sendFrame(frameType string, payload map[string]any) error {
+	frame := map[string]any{"type": frameType}
+	for key, value := range payload {
+		frame[key] = value
 	}
-	nextCursor := cursor
-	catchUp, err := r.service.ForumWatchEvents(ticket, nextCursor, limit)
+	return s.conn.writeJSON(frame)
+}
+
+func (r *Runtime) handleAgentLogStream(w http.ResponseWriter, req *http.Request, options serviceapi.AgentTranscriptReadOptions) {
+	// Resolve the transcript before upgrading so unknown agents get a plain 404.
+	first, err := r.service.ReadAgentTranscript(req.Context(), options)
 	if err != nil {
-		return err
+		writeAPIError(w, http.StatusNotFound, "agent_log_not_found", err.Error())
+		return
 	}
-	if len(catchUp) > 0 {
-		nextCursor = catchUp[len(catchUp)-1].Sequence
-		filtered := filterForumEventsByRunID(catchUp, runID)
-		if len(filtered) > 0 {
-			if err := writeForumEventsFrame(conn, filtered, nextCursor); err != nil {
-				return err
-			}
-		}
+	conn, err := upgradeWebSocket(w, req)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "websocket_upgrade_failed", err.Error())
+		return
 	}
+	defer conn.Close()
 
-	if r.eventBroker == nil {
-		return streamHeartbeatOnly(conn, r.streamHeartbeatInterval(), nextCursor)
+	ctx, cancel := context.WithCancel(req.Context())
+	defer cancel()
+	go func() {
+		defer cancel()
+		conn.readMessages(nil)
+	}()
+
+	if err := r.streamAgentLog(ctx, conn, options, first); err != nil {
+		_ = conn.writeJSON(map[string]any{
+			"type":    "error",
+			"message": err.Error(),
+		})
+		_ = conn.writeClose(websocketCloseInternalError, "stream failed")
+		return
 	}
-	eventsCh, unsubscribe := r.eventBroker.Subscribe(ticket, runID)
-	defer unsubscribe()
+	_ = conn.writeClose(websocketCloseNormal, "")
+}
+
+func (r *Runtime) streamAgentLog(ctx context.Context, conn *websocketConn, options serviceapi.AgentTranscriptReadOptions, chunk serviceapi.AgentTranscriptChunk) error {
+	poll := time.NewTicker(agentLogPollInterval)
+	defer poll.Stop()
 	heartbeat := time.NewTicker(r.streamHeartbeatInterval())
 	defer heartbeat.Stop()
 
 	for {
-		select {
-		case event, ok := <-eventsCh:
-			if !ok {
-				return nil
-			}
-			batch := make([]model.ForumEvent, 0, limit)
-			if event.Sequence > nextCursor {
-				nextCursor = event.Sequence
-				batch = append(batch, event)
-			}
-		drain:
-			for len(batch) < limit {
-				select {
-				case nextEvent, ok := <-eventsCh:
-					if !ok {
-						return nil
-					}
-					if nextEvent.Sequence <= nextCursor {
-						continue
-					}
-					nextCursor = nextEvent.Sequence
-					batch = append(batch, nextEvent)
-				default:
-					break drain
-				}
-			}
-			if len(batch) == 0 {
-				continue
-			}
-			if err := writeForumEventsFrame(conn, batch, nextCursor); err != nil {
+		if chunk.Data != "" || chunk.Reset {
+			if err := writeAgentLogFrame(conn, chunk); err != nil {
 				return err
 			}
+		}
+		options.Offset = chunk.NextOffset
+		options.Generation = chunk.Generation
+		// Each chunk goes out once; a heartbeat wakes the loop with nothing new.
+		chunk.Data, chunk.Reset = "", false
+		select {
+		case <-ctx.Done():
+			return nil
 		case <-heartbeat.C:
-			if err := writeHeartbeatFrame(conn, nextCursor); err != nil {
+			if err := conn.writeJSON(heartbeatPayload(options.Offset)); err != nil {
 				return err
 			}
+			continue
+		case <-poll.C:
+		}
+		next, err := r.service.ReadAgentTranscript(ctx, options)
+		if err != nil {
+			return err
 		}
+		chunk = next
 	}
 }
 
-func filterForumEventsByRunID(events []model.ForumEvent, runID string) []model.ForumEvent {
-	runID = strings.TrimSpace(runID)
-	if runID == "" {
-		return events
-	}
-	filtered := make([]model.ForumEvent, 0, len(events))
-	for _, event := range events {
-		if strings.EqualFold(strings.TrimSpace(event.Envelope.RunID), runID) {
-			filtered = append(filtered, event)
-		}
-	}
-	return filtered
+func writeAgentLogFrame(conn *websocketConn, chunk serviceapi.AgentTranscriptChunk) error {
+	return conn.writeJSON(map[string]any{
+		"type":        "agent.log",
+		"log":         chunk,
+		"next_cursor": chunk.NextOffset,
+		"sent_at":     time.Now().UTC().Format(time.RFC3339Nano),
+	})
 }
 
-func writeForumEventsFrame(conn net.Conn, events []model.ForumEvent, nextCursor int64) error {
-	return writeWebSocketJSON(conn, map[string]any{
+func forumEventsPayload(events []model.ForumEvent, nextCursor int64) map[string]any {
+	return map[string]any{
 		"type":        "forum.events",
 		"events":      events,
 		"next_cursor": nextCursor,
 		"sent_at":     time.Now().UTC().Format(time.RFC3339Nano),
-	})
+	}
 }
 
-func writeHeartbeatFrame(conn net.Conn, nextCursor int64) error {
-	return writeWebSocketJSON(conn, map[string]any{
+func heartbeatPayload(nextCursor int64) map[string]any {
+	return map[string]any{
 		"type":        "heartbeat",
 		"next_cursor": nextCursor,
 		"sent_at":     time.Now().UTC().Format(time.RFC3339Nano),
-	})
+	}
 }
 
-func streamHeartbeatOnly(conn net.Conn, interval time.Duration, nextCursor int64) error {
-	if interval <= 0 {
-		interval = 25 * time.Second
-	}
-	heartbeat := time.NewTicker(interval)
-	defer heartbeat.Stop()
-	for range heartbeat.C {
-		if err := writeHeartbeatFrame(conn, nextCursor); err != nil {
-			return err
-		}
-	}
-	return nil
+func isWebSocketUpgrade(req *http.Request) bool {
+	return headerContainsToken(req.Header.Get("Connection"), "upgrade") &&
+		strings.EqualFold(strings.TrimSpace(req.Header.Get("Upgrade")), "websocket")
 }
 
-func upgradeWebSocket(w http.ResponseWriter, req *http.Request) (net.Conn, error) {
+func upgradeWebSocket(w http.ResponseWriter, req *http.Request) (*websocketConn, error) {
 	if !headerContainsToken(req.Header.Get("Connection"), "upgrade") {
 		return nil, fmt.Errorf("connection header must include Upgrade")
 	}
@@ -184,6 +265,9 @@ func upgradeWebSocket(w http.ResponseWriter, req *http.Request) (net.Conn, error
 	if err != nil {
 		return nil, err
 	}
+	// The server may have armed deadlines for the HTTP exchange; the stream
+	// manages its own from here on.
+	_ = conn.SetDeadline(time.Time{})
 
 	accept := websocketAcceptKey(websocketKey)
 	response := strings.Builder{}
@@ -202,7 +286,8 @@ func upgradeWebSocket(w http.ResponseWriter, req *http.Request) (net.Conn, error
 		_ = conn.Close()
 		return nil, err
 	}
-	return conn, nil
+	// Keep the hijacked reader: it may already hold client frames.
+	return &websocketConn{conn: conn, reader: rw.Reader}, nil
 }
 
 func websocketAcceptKey(key string) string {
@@ -210,15 +295,191 @@ func websocketAcceptKey(key string) string {
 	return base64.StdEncoding.EncodeToString(hash[:])
 }
 
-func writeWebSocketJSON(conn net.Conn, payload any) error {
+func (c *websocketConn) Close() error {
+	return c.conn.Close()
+}
+
+// readMessages reads client frames until the peer closes or the connection
+// fails. Pings are answered inline, fragmented messages are reassembled, and
+// complete text messages go to onText (ignored when nil). Protocol violations
+// end the connection with the matching close code.
+func (c *websocketConn) readMessages(onText func([]byte)) {
+	var message []byte
+	fragmented := false
+	for {
+		frame, err := c.readFrame()
+		if err == nil {
+			err = c.handleFrame(frame, &message, &fragmented, onText)
+		}
+		if err != nil {
+			var closeErr *websocketCloseError
+			if errors.As(err, &closeErr) {
+				_ = c.writeClose(closeErr.code, closeErr.reason)
+			}
+			return
+		}
+	}
+}
+
+func (c *websocketConn) handleFrame(frame websocketFrame, message *[]byte, fragmented *bool, onText func([]byte)) error {
+	switch frame.opcode {
+	case websocketOpPing:
+		return c.writeFrame(websocketOpPong, frame.payload)
+	case websocketOpPong:
+		return nil
+	case websocketOpClose:
+		code, reason, err := parseWebSocketClosePayload(frame.payload)
+		if err != nil {
+			return err
+		}
+		_ = c.writeClose(code, reason)
+		return errWebSocketClosed
+	case websocketOpBinary:
+		return &websocketCloseError{code: websocketCloseUnsupportedData, reason: "binary messages are not supported"}
+	case websocketOpText:
+		if *fragmented {
+			return &websocketCloseError{code: websocketCloseProtocolError, reason: "expected continuation frame"}
+		}
+		*message = append((*message)[:0], frame.payload...)
+		*fragmented = !frame.fin
+	case websocketOpContinuation:
+		if !*fragmented {
+			return &websocketCloseError{code: websocketCloseProtocolError, reason: "unexpected continuation frame"}
+		}
+		if len(*message)+len(frame.payload) > websocketMaxMessageSize {
+			return &websocketCloseError{code: websocketCloseMessageTooBig, reason: "message too large"}
+		}
+		*message = append(*message, frame.payload...)
+		*fragmented = !frame.fin
+	}
+	if *fragmented {
+		return nil
+	}
+	if !utf8.Valid(*message) {
+		return &websocketCloseError{code: websocketCloseInvalidPayload, reason: "text message is not valid UTF-8"}
+	}
+	if onText != nil {
+		onText(append([]byte(nil), (*message)...))
+	}
+	return nil
+}
+
+// readFrame reads and unmasks one client frame, enforcing the framing rules
+// of RFC 6455 section 5.
+func (c *websocketConn) readFrame() (websocketFrame, error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(c.reader, header); err != nil {
+		return websocketFrame{}, err
+	}
+	frame := websocketFrame{
+		fin:    header[0]&0x80 != 0,
+		opcode: header[0] & 0x0f,
+	}
+	if header[0]&0x70 != 0 {
+		return frame, &websocketCloseError{code: websocketCloseProtocolError, reason: "reserved bits must be zero"}
+	}
+	switch frame.opcode {
+	case websocketOpContinuation, websocketOpText, websocketOpBinary:
+	case websocketOpClose, websocketOpPing, websocketOpPong:
+		if !frame.fin {
+			return frame, &websocketCloseError{code: websocketCloseProtocolError, reason: "control frames must not be fragmented"}
+		}
+	default:
+		return frame, &websocketCloseError{code: websocketCloseProtocolError, reason: fmt.Sprintf("unknown opcode %#x", frame.opcode)}
+	}
+	if header[1]&0x80 == 0 {
+		return frame, &websocketCloseError{code: websocketCloseProtocolError, reason: "client frames must be masked"}
+	}
+
+	size := uint64(header[1] & 0x7f)
+	switch size {
+	case 126:
+		extended := make([]byte, 2)
+		if _, err := io.ReadFull(c.reader, extended); err != nil {
+			return frame, err
+		}
+		size = uint64(binary.BigEndian.Uint16(extended))
+	case 127:
+		extended := make([]byte, 8)
+		if _, err := io.ReadFull(c.reader, extended); err != nil {
+			return frame, err
+		}
+		size = binary.BigEndian.Uint64(extended)
+	}
+	if frame.opcode >= websocketOpClose && size > 125 {
+		return frame, &websocketCloseError{code: websocketCloseProtocolError, reason: "control frame payload too large"}
+	}
+	if size > websocketMaxMessageSize {
+		return frame, &websocketCloseError{code: websocketCloseMessageTooBig, reason: "message too large"}
+	}
+
+	mask := make([]byte, 4)
+	if _, err := io.ReadFull(c.reader, mask); err != nil {
+		return frame, err
+	}
+	frame.payload = make([]byte, size)
+	if _, err := io.ReadFull(c.reader, frame.payload); err != nil {
+		return frame, err
+	}
+	for i := range frame.payload {
+		frame.payload[i] ^= mask[i%4]
+	}
+	return frame, nil
+}
+
+// parseWebSocketClosePayload returns the status to echo back. A close frame
+// without a body is answered with a normal closure.
+func parseWebSocketClosePayload(payload []byte) (int, string, error) {
+	if len(payload) == 0 {
+		return websocketCloseNormal, "", nil
+	}
+	if len(payload) == 1 {
+		return 0, "", &websocketCloseError{code: websocketCloseProtocolError, reason: "invalid close payload"}
+	}
+	reason := payload[2:]
+	if !utf8.Valid(reason) {
+		return 0, "", &websocketCloseError{code: websocketCloseInvalidPayload, reason: "close reason is not valid UTF-8"}
+	}
+	return int(binary.BigEndian.Uint16(payload[:2])), string(reason), nil
+}
+
+func (c *websocketConn) writeJSON(payload any) error {
 	body, err := json.Marshal(payload)
 	if err != nil {
 		return err
 	}
-	return writeWebSocketFrame(conn, 0x1, body)
+	return c.writeFrame(websocketOpText, body)
+}
+
+// writeClose sends a close frame once; later writes fail with
+// errWebSocketClosed.
+func (c *websocketConn) writeClose(code int, reason string) error {
+	payload := make([]byte, 2, 2+len(reason))
+	binary.BigEndian.PutUint16(payload, uint16(code))
+	payload = append(payload, reason...)
+	if len(payload) > 125 {
+		payload = payload[:125]
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if c.closeSent {
+		return errWebSocketClosed
+	}
+	c.closeSent = true
+	return c.writeFrameLocked(websocketOpClose, payload)
+}
+
+func (c *websocketConn) writeFrame(opcode byte, payload []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if c.closeSent {
+		return errWebSocketClosed
+	}
+	return c.writeFrameLocked(opcode, payload)
 }
 
-func writeWebSocketFrame(conn net.Conn, opcode byte, payload []byte) error {
+func (c *websocketConn) writeFrameLocked(opcode byte, payload []byte) error {
 	header := make([]byte, 0, 10)
 	header = append(header, 0x80|opcode)
 	size := len(payload)
@@ -234,10 +495,10 @@ func writeWebSocketFrame(conn net.Conn, opcode byte, payload []byte) error {
 		binary.BigEndian.PutUint64(extended, uint64(size))
 		header = append(header, extended...)
 	}
-	if err := conn.SetWriteDeadline(time.Now().Add(10 * time.Second)); err != nil {
+	if err := c.conn.SetWriteDeadline(time.Now().Add(websocketWriteTimeout)); err != nil {
 		return err
 	}
-	if _, err := conn.Write(append(header, payload...)); err != nil {
+	if _, err := c.conn.Write(append(header, payload...)); err != nil {
 		return err
 	}
 	return nil
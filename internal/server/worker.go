@@ -11,28 +11,44 @@ import (
 	"metawsm/internal/serviceapi"
 )
 
+// transcriptRotationInterval is how often the worker checks agent transcripts
+// against transcripts.max_bytes.
+const transcriptRotationInterval = 10 * time.Second
+
+// operatorDecisionPruneInterval is how often the worker drops audited
+// operator decisions older than operator.decision_retention_days.
+const operatorDecisionPruneInterval = time.Hour
+
 type ForumWorkerSnapshot struct {
-	Running           bool                   `json:"running"`
-	StartedAt         *time.Time             `json:"started_at,omitempty"`
-	LastTickAt        *time.Time             `json:"last_tick_at,omitempty"`
-	LastProcessedAt   *time.Time             `json:"last_processed_at,omitempty"`
-	LastErrorAt       *time.Time             `json:"last_error_at,omitempty"`
-	LastError         string                 `json:"last_error,omitempty"`
-	ConsecutiveErrors int                    `json:"consecutive_errors"`
-	TotalProcessed    int64                  `json:"total_processed"`
-	TotalBatches      int64                  `json:"total_batches"`
-	IdleBatches       int64                  `json:"idle_batches"`
-	BusHealthy        bool                   `json:"bus_healthy"`
-	BusError          string                 `json:"bus_error,omitempty"`
-	Outbox            model.ForumOutboxStats `json:"outbox"`
+	Running              bool                   `json:"running"`
+	StartedAt            *time.Time             `json:"started_at,omitempty"`
+	LastTickAt           *time.Time             `json:"last_tick_at,omitempty"`
+	LastProcessedAt      *time.Time             `json:"last_processed_at,omitempty"`
+	LastErrorAt          *time.Time             `json:"last_error_at,omitempty"`
+	LastError            string                 `json:"last_error,omitempty"`
+	ConsecutiveErrors    int                    `json:"consecutive_errors"`
+	TotalProcessed       int64                  `json:"total_processed"`
+	TotalBatches         int64                  `json:"total_batches"`
+	IdleBatches          int64                  `json:"idle_batches"`
+	BusHealthy           bool                   `json:"bus_healthy"`
+	BusError             string                 `json:"bus_error,omitempty"`
+	Outbox               model.ForumOutboxStats `json:"outbox"`
+	LastEscalationAt     *time.Time             `json:"last_escalation_at,omitempty"`
+	EscalationError      string                 `json:"escalation_error,omitempty"`
+	TotalEscalated       int64                  `json:"total_escalated"`
+	TranscriptError      string                 `json:"transcript_error,omitempty"`
+	TotalRotations       int64                  `json:"total_transcript_rotations"`
+	DecisionPruneError   string                 `json:"decision_prune_error,omitempty"`
+	TotalDecisionsPruned int64                  `json:"total_decisions_pruned"`
 }
 
 type ForumWorker struct {
-	service     serviceapi.Core
-	interval    time.Duration
-	batchSize   int
-	logInterval time.Duration
-	logger      *log.Logger
+	service            serviceapi.Core
+	interval           time.Duration
+	batchSize          int
+	escalationInterval time.Duration
+	logInterval        time.Duration
+	logger             *log.Logger
 
 	mu       sync.RWMutex
 	running  bool
@@ -41,22 +57,26 @@ type ForumWorker struct {
 	snapshot ForumWorkerSnapshot
 }
 
-func NewForumWorker(service serviceapi.Core, interval time.Duration, batchSize int, logInterval time.Duration, logger *log.Logger) *ForumWorker {
+func NewForumWorker(service serviceapi.Core, interval time.Duration, batchSize int, escalationInterval time.Duration, logInterval time.Duration, logger *log.Logger) *ForumWorker {
 	if interval <= 0 {
 		interval = 500 * time.Millisecond
 	}
 	if batchSize <= 0 {
 		batchSize = 100
 	}
+	if escalationInterval <= 0 {
+		escalationInterval = time.Minute
+	}
 	if logInterval <= 0 {
 		logInterval = 15 * time.Second
 	}
 	return &ForumWorker{
-		service:     service,
-		interval:    interval,
-		batchSize:   batchSize,
-		logInterval: logInterval,
-		logger:      logger,
+		service:            service,
+		interval:           interval,
+		batchSize:          batchSize,
+		escalationInterval: escalationInterval,
+		logInterval:        logInterval,
+		logger:             logger,
 		snapshot: ForumWorkerSnapshot{
 			BusHealthy: true,
 			Outbox:     model.ForumOutboxStats{},
@@ -119,6 +139,7 @@ func (w *ForumWorker) Snapshot() ForumWorkerSnapshot {
 	copySnapshot.LastProcessedAt = cloneTimePtr(w.snapshot.LastProcessedAt)
 	copySnapshot.LastErrorAt = cloneTimePtr(w.snapshot.LastErrorAt)
 	copySnapshot.Outbox = w.snapshot.Outbox
+	copySnapshot.LastEscalationAt = cloneTimePtr(w.snapshot.LastEscalationAt)
 	return copySnapshot
 }
 
@@ -128,13 +149,29 @@ func (w *ForumWorker) loop(ctx context.Context) {
 	logTicker := time.NewTicker(w.logInterval)
 	defer logTicker.Stop()
 
+	escalationTicker := time.NewTicker(w.escalationInterval)
+	defer escalationTicker.Stop()
+	transcriptTicker := time.NewTicker(transcriptRotationInterval)
+	defer transcriptTicker.Stop()
+	pruneTicker := time.NewTicker(operatorDecisionPruneInterval)
+	defer pruneTicker.Stop()
+
 	w.runIteration(ctx)
+	w.runEscalationPass(ctx)
+	w.runTranscriptRotation(ctx)
+	w.runOperatorDecisionPrune(ctx)
 	for {
 		select {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
 			w.runIteration(ctx)
+		case <-escalationTicker.C:
+			w.runEscalationPass(ctx)
+		case <-transcriptTicker.C:
+			w.runTranscriptRotation(ctx)
+		case <-pruneTicker.C:
+			w.runOperatorDecisionPrune(ctx)
 		case <-logTicker.C:
 			w.logSnapshot()
 		}
@@ -189,6 +226,85 @@ func (w *ForumWorker) runIteration(ctx context.Context) {
 	}
 }
 
+// runEscalationPass escalates forum threads that have outlived their SLA.
+// Failures are kept apart from the bus error counters so a bad notify hook
+// never looks like a stuck outbox.
+func (w *ForumWorker) runEscalationPass(ctx context.Context) {
+	if w.service == nil {
+		return
+	}
+	now := time.Now().UTC()
+	result, err := w.service.ForumEscalateOverdueThreads(ctx, serviceapi.ForumEscalationPassOptions{})
+	if err != nil && ctx.Err() != nil {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.snapshot.LastEscalationAt = timePtr(now)
+	switch {
+	case err != nil:
+		w.snapshot.EscalationError = strings.TrimSpace(err.Error())
+	case len(result.NotifyErrors) > 0:
+		w.snapshot.EscalationError = "notify: " + strings.Join(result.NotifyErrors, "; ")
+	default:
+		w.snapshot.EscalationError = ""
+	}
+	w.snapshot.TotalEscalated += int64(len(result.Escalated))
+	if w.logger != nil {
+		for _, escalation := range result.Escalated {
+			w.logger.Printf(
+				"forum escalation: thread=%s level=%d priority=%s assignee=%s reason=%q",
+				escalation.ThreadID,
+				escalation.Level,
+				escalation.ToPriority,
+				escalation.ToAssigneeName,
+				escalation.Reason,
+			)
+		}
+	}
+}
+
+// runTranscriptRotation rotates agent transcripts that outgrew
+// transcripts.max_bytes while their agents keep writing.
+func (w *ForumWorker) runTranscriptRotation(ctx context.Context) {
+	if w.service == nil {
+		return
+	}
+	rotated, err := w.service.RotateAgentTranscripts(ctx)
+	if err != nil && ctx.Err() != nil {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.snapshot.TranscriptError = ""
+	if err != nil {
+		w.snapshot.TranscriptError = strings.TrimSpace(err.Error())
+	}
+	w.snapshot.TotalRotations += int64(rotated)
+}
+
+// runOperatorDecisionPrune enforces operator.decision_retention_days on the
+// operator decision audit log.
+func (w *ForumWorker) runOperatorDecisionPrune(ctx context.Context) {
+	if w.service == nil {
+		return
+	}
+	pruned, err := w.service.PruneOperatorDecisions(ctx)
+	if err != nil && ctx.Err() != nil {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.snapshot.DecisionPruneError = ""
+	if err != nil {
+		w.snapshot.DecisionPruneError = strings.TrimSpace(err.Error())
+	}
+	w.snapshot.TotalDecisionsPruned += int64(pruned)
+}
+
 func (w *ForumWorker) logSnapshot() {
 	if w.logger == nil {
 		return
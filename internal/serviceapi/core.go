@@ -16,11 +16,68 @@ type ForumChangeStateOptions = orchestrator.ForumChangeStateOptions
 type ForumSetPriorityOptions = orchestrator.ForumSetPriorityOptions
 type ForumControlSignalOptions = orchestrator.ForumControlSignalOptions
 type ForumDebugOptions = orchestrator.ForumDebugOptions
+type ForumOutboxListOptions = orchestrator.ForumOutboxListOptions
+type ForumOutboxRetryOptions = orchestrator.ForumOutboxRetryOptions
+type ForumOutboxPurgeOptions = orchestrator.ForumOutboxPurgeOptions
+type ForumRebuildProjectionsOptions = orchestrator.ForumRebuildProjectionsOptions
+type ForumEscalationPassOptions = orchestrator.ForumEscalationPassOptions
+type ForumEscalationPassResult = orchestrator.ForumEscalationPassResult
+type RunStatusWebhookResult = orchestrator.RunStatusWebhookResult
+type ForumEventWebhookResult = orchestrator.ForumEventWebhookResult
+type IntegrationIngestOptions = orchestrator.IntegrationIngestOptions
+type IntegrationIngestResult = orchestrator.IntegrationIngestResult
+type IntegrationAction = orchestrator.IntegrationAction
 type ForumSearchThreadsOptions = orchestrator.ForumSearchThreadsOptions
+type GuidanceLibraryPromoteOptions = orchestrator.GuidanceLibraryPromoteOptions
+type GuidanceSuggestOptions = orchestrator.GuidanceSuggestOptions
 type ForumQueueOptions = orchestrator.ForumQueueOptions
 type ForumMarkThreadSeenOptions = orchestrator.ForumMarkThreadSeenOptions
 type ForumThreadDetail = orchestrator.ForumThreadDetail
 type RunSnapshot = orchestrator.RunSnapshot
+type RunStatusReport = orchestrator.RunStatusReport
+type RunGuidanceSnapshot = orchestrator.RunGuidanceSnapshot
+type AgentTranscriptReadOptions = orchestrator.AgentTranscriptReadOptions
+type AgentTranscriptChunk = orchestrator.AgentTranscriptChunk
+type RunOptions = orchestrator.RunOptions
+type RunResult = orchestrator.RunResult
+type RestartOptions = orchestrator.RestartOptions
+type RestartResult = orchestrator.RestartResult
+type CleanupOptions = orchestrator.CleanupOptions
+type CleanupResult = orchestrator.CleanupResult
+type MergeOptions = orchestrator.MergeOptions
+type MergeResult = orchestrator.MergeResult
+type IterateOptions = orchestrator.IterateOptions
+type IterateResult = orchestrator.IterateResult
+type CommitOptions = orchestrator.CommitOptions
+type CommitResult = orchestrator.CommitResult
+type CommitRepoResult = orchestrator.CommitRepoResult
+type PullRequestOptions = orchestrator.PullRequestOptions
+type PullRequestResult = orchestrator.PullRequestResult
+type PullRequestRepoResult = orchestrator.PullRequestRepoResult
+type CloseOptions = orchestrator.CloseOptions
+type RunMutationInProgressError = orchestrator.RunMutationInProgressError
+type APIPrincipal = orchestrator.APIPrincipal
+type OperatorPassOptions = orchestrator.OperatorPassOptions
+type OperatorPassResult = orchestrator.OperatorPassResult
+type OperatorRunResult = orchestrator.OperatorRunResult
+type OperatorDecision = orchestrator.OperatorDecision
+type OperatorRunDecisions = orchestrator.OperatorRunDecisions
+
+var ErrInvalidAPIToken = orchestrator.ErrInvalidAPIToken
+
+var ErrControlSignalOutOfScope = orchestrator.ErrControlSignalOutOfScope
+
+var ErrForumWriteOutOfScope = orchestrator.ErrForumWriteOutOfScope
+
+var ErrIntegrationSourceNotFound = orchestrator.ErrIntegrationSourceNotFound
+
+var ErrIntegrationUnauthorized = orchestrator.ErrIntegrationUnauthorized
+
+var ErrGuidanceAnswerNotFound = orchestrator.ErrGuidanceAnswerNotFound
+
+var ErrRunNotFound = orchestrator.ErrRunNotFound
+
+var ResolveOperatorLLMMode = orchestrator.ResolveOperatorLLMMode
 
 type LiveForumEventSubscriber interface {
 	SubscribeForumEvents(callback func(model.ForumEvent)) (func(), error)
@@ -33,9 +90,37 @@ type Core interface {
 	ForumBusHealth() error
 	ForumOutboxStats() (model.ForumOutboxStats, error)
 	ForumStreamDebugSnapshot(ctx context.Context, options ForumDebugOptions) (model.ForumStreamDebugSnapshot, error)
+	ForumListOutbox(ctx context.Context, options ForumOutboxListOptions) ([]model.ForumOutboxMessage, error)
+	ForumRetryOutbox(ctx context.Context, options ForumOutboxRetryOptions) (int, error)
+	ForumPurgeOutbox(ctx context.Context, options ForumOutboxPurgeOptions) (int, error)
+	ForumRebuildProjections(ctx context.Context, options ForumRebuildProjectionsOptions) (model.ForumProjectionRebuildReport, error)
+	ForumEscalateOverdueThreads(ctx context.Context, options ForumEscalationPassOptions) (ForumEscalationPassResult, error)
+	EnqueueRunStatusWebhooks(ctx context.Context) (RunStatusWebhookResult, error)
+	EnqueueForumEventWebhooks(ctx context.Context) (ForumEventWebhookResult, error)
+	IngestIntegration(ctx context.Context, options IntegrationIngestOptions) (IntegrationIngestResult, error)
+	AuthenticateAPIToken(ctx context.Context, token string) (APIPrincipal, error)
 
 	RunSnapshot(ctx context.Context, runID string) (RunSnapshot, error)
+	RunStatusReport(ctx context.Context, runID string) (RunStatusReport, error)
 	ListRunSnapshots(ctx context.Context, ticket string) ([]RunSnapshot, error)
+	ReadAgentTranscript(ctx context.Context, options AgentTranscriptReadOptions) (AgentTranscriptChunk, error)
+	RotateAgentTranscripts(ctx context.Context) (int, error)
+	PruneOperatorDecisions(ctx context.Context) (int, error)
+
+	StartRun(ctx context.Context, options RunOptions) (RunResult, error)
+	ResolveRunID(ctx context.Context, runID string, ticket string) (string, error)
+	StopRun(ctx context.Context, runID string) error
+	ResumeRun(ctx context.Context, runID string) error
+	// StartResumeRun resumes a run and returns once it is running again; the
+	// remaining steps execute in the background.
+	StartResumeRun(ctx context.Context, runID string) error
+	RestartRun(ctx context.Context, options RestartOptions) (RestartResult, error)
+	IterateRun(ctx context.Context, options IterateOptions) (IterateResult, error)
+	CommitRun(ctx context.Context, options CommitOptions) (CommitResult, error)
+	OpenPullRequests(ctx context.Context, options PullRequestOptions) (PullRequestResult, error)
+	MergeRun(ctx context.Context, options MergeOptions) (MergeResult, error)
+	CloseRun(ctx context.Context, options CloseOptions) error
+	CleanupRun(ctx context.Context, options CleanupOptions) (CleanupResult, error)
 
 	ForumOpenThread(ctx context.Context, options ForumOpenThreadOptions) (model.ForumThreadView, error)
 	ForumAddPost(ctx context.Context, options ForumAddPostOptions) (model.ForumThreadView, error)
@@ -50,9 +135,20 @@ type Core interface {
 	ForumGetThread(threadID string) (*ForumThreadDetail, error)
 	ForumListStats(ticket string, runID string) ([]model.ForumThreadStats, error)
 	ForumWatchEvents(ticket string, cursor int64, limit int) ([]model.ForumEvent, error)
+	ForumLatestEventSequence() (int64, error)
 	ForumSearchThreads(options ForumSearchThreadsOptions) ([]model.ForumThreadView, error)
 	ForumListQueue(options ForumQueueOptions) ([]model.ForumThreadView, error)
 	ForumMarkThreadSeen(ctx context.Context, options ForumMarkThreadSeenOptions) (model.ForumThreadSeen, error)
+
+	GuidanceLibraryList(filter model.GuidanceAnswerFilter) ([]model.GuidanceAnswer, error)
+	GuidanceLibraryPromote(ctx context.Context, options GuidanceLibraryPromoteOptions) (model.GuidanceAnswer, error)
+	GuidanceLibraryRemove(ctx context.Context, answerID string) error
+	GuidanceLibrarySync(ctx context.Context) (int, error)
+	GuidanceSuggest(options GuidanceSuggestOptions) ([]model.GuidanceSuggestion, error)
+
+	OperatorPass(ctx context.Context, options OperatorPassOptions) (OperatorPassResult, error)
+	OperatorDecisions(ctx context.Context, runID string, limit int) ([]OperatorRunDecisions, error)
+	ReleaseOperatorLeases(ctx context.Context, holder string) error
 }
 
 type LocalCore struct {
@@ -90,10 +186,50 @@ func (l *LocalCore) ForumStreamDebugSnapshot(ctx context.Context, options ForumD
 	return l.service.ForumStreamDebugSnapshot(ctx, options)
 }
 
+func (l *LocalCore) ForumListOutbox(_ context.Context, options ForumOutboxListOptions) ([]model.ForumOutboxMessage, error) {
+	return l.service.ForumListOutbox(options)
+}
+
+func (l *LocalCore) ForumRetryOutbox(_ context.Context, options ForumOutboxRetryOptions) (int, error) {
+	return l.service.ForumRetryOutbox(options)
+}
+
+func (l *LocalCore) ForumPurgeOutbox(_ context.Context, options ForumOutboxPurgeOptions) (int, error) {
+	return l.service.ForumPurgeOutbox(options)
+}
+
+func (l *LocalCore) ForumRebuildProjections(_ context.Context, options ForumRebuildProjectionsOptions) (model.ForumProjectionRebuildReport, error) {
+	return l.service.ForumRebuildProjections(options)
+}
+
+func (l *LocalCore) ForumEscalateOverdueThreads(ctx context.Context, options ForumEscalationPassOptions) (ForumEscalationPassResult, error) {
+	return l.service.ForumEscalateOverdueThreads(ctx, options)
+}
+
+func (l *LocalCore) EnqueueRunStatusWebhooks(ctx context.Context) (RunStatusWebhookResult, error) {
+	return l.service.EnqueueRunStatusWebhooks(ctx)
+}
+
+func (l *LocalCore) EnqueueForumEventWebhooks(ctx context.Context) (ForumEventWebhookResult, error) {
+	return l.service.EnqueueForumEventWebhooks(ctx)
+}
+
+func (l *LocalCore) IngestIntegration(ctx context.Context, options IntegrationIngestOptions) (IntegrationIngestResult, error) {
+	return l.service.IngestIntegration(ctx, options)
+}
+
+func (l *LocalCore) AuthenticateAPIToken(_ context.Context, token string) (APIPrincipal, error) {
+	return l.service.AuthenticateAPIToken(token)
+}
+
 func (l *LocalCore) RunSnapshot(ctx context.Context, runID string) (RunSnapshot, error) {
 	return l.service.RunSnapshot(ctx, runID)
 }
 
+func (l *LocalCore) RunStatusReport(ctx context.Context, runID string) (RunStatusReport, error) {
+	return l.service.StatusReport(ctx, runID)
+}
+
 func (l *LocalCore) ListRunSnapshots(ctx context.Context, ticket string) ([]RunSnapshot, error) {
 	runs, err := l.service.ListRuns()
 	if err != nil {
@@ -117,6 +253,66 @@ func (l *LocalCore) ListRunSnapshots(ctx context.Context, ticket string) ([]RunS
 	return out, nil
 }
 
+func (l *LocalCore) ReadAgentTranscript(_ context.Context, options AgentTranscriptReadOptions) (AgentTranscriptChunk, error) {
+	return l.service.ReadAgentTranscript(options)
+}
+
+func (l *LocalCore) RotateAgentTranscripts(_ context.Context) (int, error) {
+	return l.service.RotateAgentTranscripts()
+}
+
+func (l *LocalCore) PruneOperatorDecisions(_ context.Context) (int, error) {
+	return l.service.PruneOperatorDecisions()
+}
+
+func (l *LocalCore) StartRun(_ context.Context, options RunOptions) (RunResult, error) {
+	return l.service.StartRun(options)
+}
+
+func (l *LocalCore) ResolveRunID(_ context.Context, runID string, ticket string) (string, error) {
+	return l.service.ResolveRunID(runID, ticket)
+}
+
+func (l *LocalCore) StopRun(ctx context.Context, runID string) error {
+	return l.service.Stop(ctx, runID)
+}
+
+func (l *LocalCore) ResumeRun(ctx context.Context, runID string) error {
+	return l.service.Resume(ctx, runID)
+}
+
+func (l *LocalCore) StartResumeRun(_ context.Context, runID string) error {
+	return l.service.StartResume(runID)
+}
+
+func (l *LocalCore) RestartRun(ctx context.Context, options RestartOptions) (RestartResult, error) {
+	return l.service.Restart(ctx, options)
+}
+
+func (l *LocalCore) IterateRun(ctx context.Context, options IterateOptions) (IterateResult, error) {
+	return l.service.Iterate(ctx, options)
+}
+
+func (l *LocalCore) CommitRun(ctx context.Context, options CommitOptions) (CommitResult, error) {
+	return l.service.Commit(ctx, options)
+}
+
+func (l *LocalCore) OpenPullRequests(ctx context.Context, options PullRequestOptions) (PullRequestResult, error) {
+	return l.service.OpenPullRequests(ctx, options)
+}
+
+func (l *LocalCore) MergeRun(ctx context.Context, options MergeOptions) (MergeResult, error) {
+	return l.service.Merge(ctx, options)
+}
+
+func (l *LocalCore) CloseRun(ctx context.Context, options CloseOptions) error {
+	return l.service.Close(ctx, options)
+}
+
+func (l *LocalCore) CleanupRun(ctx context.Context, options CleanupOptions) (CleanupResult, error) {
+	return l.service.Cleanup(ctx, options)
+}
+
 func (l *LocalCore) ForumOpenThread(ctx context.Context, options ForumOpenThreadOptions) (model.ForumThreadView, error) {
 	return l.service.ForumOpenThread(ctx, options)
 }
@@ -165,6 +361,10 @@ func (l *LocalCore) ForumWatchEvents(ticket string, cursor int64, limit int) ([]
 	return l.service.ForumWatchEvents(ticket, cursor, limit)
 }
 
+func (l *LocalCore) ForumLatestEventSequence() (int64, error) {
+	return l.service.ForumLatestEventSequence()
+}
+
 func (l *LocalCore) ForumSearchThreads(options ForumSearchThreadsOptions) ([]model.ForumThreadView, error) {
 	return l.service.ForumSearchThreads(options)
 }
@@ -177,6 +377,38 @@ func (l *LocalCore) ForumMarkThreadSeen(ctx context.Context, options ForumMarkTh
 	return l.service.ForumMarkThreadSeen(ctx, options)
 }
 
+func (l *LocalCore) GuidanceLibraryList(filter model.GuidanceAnswerFilter) ([]model.GuidanceAnswer, error) {
+	return l.service.GuidanceLibraryList(filter)
+}
+
+func (l *LocalCore) GuidanceLibraryPromote(ctx context.Context, options GuidanceLibraryPromoteOptions) (model.GuidanceAnswer, error) {
+	return l.service.GuidanceLibraryPromote(ctx, options)
+}
+
+func (l *LocalCore) GuidanceLibraryRemove(ctx context.Context, answerID string) error {
+	return l.service.GuidanceLibraryRemove(ctx, answerID)
+}
+
+func (l *LocalCore) GuidanceLibrarySync(ctx context.Context) (int, error) {
+	return l.service.GuidanceLibrarySync(ctx)
+}
+
+func (l *LocalCore) GuidanceSuggest(options GuidanceSuggestOptions) ([]model.GuidanceSuggestion, error) {
+	return l.service.GuidanceSuggest(options)
+}
+
+func (l *LocalCore) OperatorPass(ctx context.Context, options OperatorPassOptions) (OperatorPassResult, error) {
+	return l.service.OperatorPass(ctx, options)
+}
+
+func (l *LocalCore) OperatorDecisions(_ context.Context, runID string, limit int) ([]OperatorRunDecisions, error) {
+	return l.service.OperatorDecisions(runID, limit)
+}
+
+func (l *LocalCore) ReleaseOperatorLeases(_ context.Context, holder string) error {
+	return l.service.ReleaseOperatorLeases(holder)
+}
+
 func (l *LocalCore) SubscribeForumEvents(callback func(model.ForumEvent)) (func(), error) {
 	return l.service.SubscribeForumEvents(callback)
 }
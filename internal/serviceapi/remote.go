@@ -17,6 +17,7 @@ import (
 
 type RemoteCore struct {
 	baseURL string
+	token   string
 	client  *http.Client
 }
 
@@ -32,6 +33,12 @@ func NewRemoteCore(baseURL string, timeout time.Duration) *RemoteCore {
 	}
 }
 
+// WithToken sets the bearer token sent with every request.
+func (r *RemoteCore) WithToken(token string) *RemoteCore {
+	r.token = strings.TrimSpace(token)
+	return r
+}
+
 func (r *RemoteCore) Shutdown() {}
 
 func (r *RemoteCore) ProcessForumBusOnce(_ context.Context, _ int) (int, error) {
@@ -81,6 +88,95 @@ func (r *RemoteCore) ForumStreamDebugSnapshot(ctx context.Context, options Forum
 	return response.Debug, nil
 }
 
+func (r *RemoteCore) ForumListOutbox(ctx context.Context, options ForumOutboxListOptions) ([]model.ForumOutboxMessage, error) {
+	query := map[string]string{}
+	if status := strings.TrimSpace(string(options.Status)); status != "" {
+		query["status"] = status
+	}
+	if options.Limit > 0 {
+		query["limit"] = strconv.Itoa(options.Limit)
+	}
+	var response struct {
+		Messages []model.ForumOutboxMessage `json:"messages"`
+	}
+	if err := r.doJSON(ctx, http.MethodGet, "/api/v1/forum/outbox", query, nil, &response); err != nil {
+		return nil, err
+	}
+	return response.Messages, nil
+}
+
+func (r *RemoteCore) ForumRetryOutbox(ctx context.Context, options ForumOutboxRetryOptions) (int, error) {
+	payload := map[string]any{
+		"message_ids": options.MessageIDs,
+		"all":         options.All,
+	}
+	var response struct {
+		Requeued int `json:"requeued"`
+	}
+	if err := r.doJSON(ctx, http.MethodPost, "/api/v1/forum/outbox/retry", nil, payload, &response); err != nil {
+		return 0, err
+	}
+	return response.Requeued, nil
+}
+
+func (r *RemoteCore) ForumPurgeOutbox(ctx context.Context, options ForumOutboxPurgeOptions) (int, error) {
+	payload := map[string]any{
+		"status":             strings.TrimSpace(string(options.Status)),
+		"older_than_seconds": int64(options.OlderThan / time.Second),
+	}
+	var response struct {
+		Purged int `json:"purged"`
+	}
+	if err := r.doJSON(ctx, http.MethodPost, "/api/v1/forum/outbox/purge", nil, payload, &response); err != nil {
+		return 0, err
+	}
+	return response.Purged, nil
+}
+
+func (r *RemoteCore) ForumRebuildProjections(ctx context.Context, options ForumRebuildProjectionsOptions) (model.ForumProjectionRebuildReport, error) {
+	payload := map[string]any{
+		"ticket":      strings.TrimSpace(options.Ticket),
+		"projections": options.Projections,
+	}
+	var response struct {
+		Report model.ForumProjectionRebuildReport `json:"report"`
+	}
+	if err := r.doJSON(ctx, http.MethodPost, "/api/v1/forum/projections/rebuild", nil, payload, &response); err != nil {
+		return model.ForumProjectionRebuildReport{}, err
+	}
+	return response.Report, nil
+}
+
+func (r *RemoteCore) ForumEscalateOverdueThreads(ctx context.Context, options ForumEscalationPassOptions) (ForumEscalationPassResult, error) {
+	payload := map[string]any{}
+	if !options.Now.IsZero() {
+		payload["now"] = options.Now.UTC().Format(time.RFC3339)
+	}
+	var response struct {
+		Result ForumEscalationPassResult `json:"result"`
+	}
+	if err := r.doJSON(ctx, http.MethodPost, "/api/v1/forum/escalations/run", nil, payload, &response); err != nil {
+		return ForumEscalationPassResult{}, err
+	}
+	return response.Result, nil
+}
+
+func (r *RemoteCore) EnqueueRunStatusWebhooks(_ context.Context) (RunStatusWebhookResult, error) {
+	return RunStatusWebhookResult{}, fmt.Errorf("remote core does not support EnqueueRunStatusWebhooks")
+}
+
+func (r *RemoteCore) EnqueueForumEventWebhooks(_ context.Context) (ForumEventWebhookResult, error) {
+	return ForumEventWebhookResult{}, fmt.Errorf("remote core does not support EnqueueForumEventWebhooks")
+}
+
+func (r *RemoteCore) IngestIntegration(_ context.Context, _ IntegrationIngestOptions) (IntegrationIngestResult, error) {
+	return IntegrationIngestResult{}, fmt.Errorf("remote core does not support IngestIntegration")
+}
+
+func (r *RemoteCore) AuthenticateAPIToken(_ context.Context, _ string) (APIPrincipal, error) {
+	return APIPrincipal{}, fmt.Errorf("remote core does not support AuthenticateAPIToken")
+}
+
 func (r *RemoteCore) RunSnapshot(ctx context.Context, runID string) (RunSnapshot, error) {
 	var response struct {
 		Run RunSnapshot `json:"run"`
@@ -91,6 +187,16 @@ func (r *RemoteCore) RunSnapshot(ctx context.Context, runID string) (RunSnapshot
 	return response.Run, nil
 }
 
+func (r *RemoteCore) RunStatusReport(ctx context.Context, runID string) (RunStatusReport, error) {
+	var response struct {
+		Status RunStatusReport `json:"status"`
+	}
+	if err := r.doJSON(ctx, http.MethodGet, "/api/v1/runs/"+url.PathEscape(strings.TrimSpace(runID)), nil, nil, &response); err != nil {
+		return RunStatusReport{}, err
+	}
+	return response.Status, nil
+}
+
 func (r *RemoteCore) ListRunSnapshots(ctx context.Context, ticket string) ([]RunSnapshot, error) {
 	query := map[string]string{}
 	if strings.TrimSpace(ticket) != "" {
@@ -105,6 +211,225 @@ func (r *RemoteCore) ListRunSnapshots(ctx context.Context, ticket string) ([]Run
 	return response.Runs, nil
 }
 
+func (r *RemoteCore) ReadAgentTranscript(ctx context.Context, options AgentTranscriptReadOptions) (AgentTranscriptChunk, error) {
+	query := map[string]string{
+		"offset": strconv.FormatInt(options.Offset, 10),
+	}
+	if workspace := strings.TrimSpace(options.WorkspaceName); workspace != "" {
+		query["workspace"] = workspace
+	}
+	if options.Limit > 0 {
+		query["limit"] = strconv.FormatInt(options.Limit, 10)
+	}
+	if options.Generation >= 0 {
+		query["generation"] = strconv.Itoa(options.Generation)
+	}
+	path := "/api/v1/runs/" + url.PathEscape(strings.TrimSpace(options.RunID)) + "/agents/" + url.PathEscape(strings.TrimSpace(options.AgentName)) + "/logs"
+	var response struct {
+		Log AgentTranscriptChunk `json:"log"`
+	}
+	if err := r.doJSON(ctx, http.MethodGet, path, query, nil, &response); err != nil {
+		return AgentTranscriptChunk{}, err
+	}
+	return response.Log, nil
+}
+
+func (r *RemoteCore) RotateAgentTranscripts(_ context.Context) (int, error) {
+	return 0, fmt.Errorf("remote core does not support RotateAgentTranscripts")
+}
+
+func (r *RemoteCore) PruneOperatorDecisions(_ context.Context) (int, error) {
+	return 0, fmt.Errorf("remote core does not support PruneOperatorDecisions")
+}
+
+// StartRun submits a RunSpec-shaped body; the daemon returns the plan and keeps
+// executing it after the response.
+func (r *RemoteCore) StartRun(ctx context.Context, options RunOptions) (RunResult, error) {
+	agents := make([]map[string]any, 0, len(options.AgentNames))
+	for _, name := range options.AgentNames {
+		agents = append(agents, map[string]any{"name": strings.TrimSpace(name)})
+	}
+	payload := map[string]any{
+		"run_id":             strings.TrimSpace(options.RunID),
+		"mode":               string(options.Mode),
+		"tickets":            options.Tickets,
+		"repos":              options.Repos,
+		"doc_repo":           strings.TrimSpace(options.DocRepo),
+		"doc_home_repo":      strings.TrimSpace(options.DocHomeRepo),
+		"doc_authority_mode": strings.TrimSpace(options.DocAuthorityMode),
+		"doc_seed_mode":      strings.TrimSpace(options.DocSeedMode),
+		"base_branch":        strings.TrimSpace(options.BaseBranch),
+		"workspace_strategy": string(options.WorkspaceStrategy),
+		"agents":             agents,
+		"dry_run":            options.DryRun,
+	}
+	if options.RunBrief != nil {
+		payload["brief"] = options.RunBrief
+	}
+	if template := strings.TrimSpace(options.Template); template != "" {
+		payload["template"] = template
+		payload["template_vars"] = options.TemplateVars
+	}
+	var response struct {
+		RunID string           `json:"run_id"`
+		Steps []model.PlanStep `json:"steps"`
+	}
+	if err := r.doJSON(ctx, http.MethodPost, "/api/v1/runs", nil, payload, &response); err != nil {
+		return RunResult{}, err
+	}
+	return RunResult{RunID: response.RunID, Steps: response.Steps}, nil
+}
+
+// ResolveRunID asks the daemon for the latest run of ticket; an explicit run id
+// is returned unchanged.
+func (r *RemoteCore) ResolveRunID(ctx context.Context, runID string, ticket string) (string, error) {
+	if runID = strings.TrimSpace(runID); runID != "" {
+		return runID, nil
+	}
+	ticket = strings.TrimSpace(ticket)
+	if ticket == "" {
+		return "", fmt.Errorf("either run id or ticket is required")
+	}
+	var response struct {
+		RunID string `json:"run_id"`
+	}
+	query := map[string]string{"ticket": ticket, "latest": "true"}
+	if err := r.doJSON(ctx, http.MethodGet, "/api/v1/runs", query, nil, &response); err != nil {
+		return "", err
+	}
+	return response.RunID, nil
+}
+
+func (r *RemoteCore) StopRun(ctx context.Context, runID string) error {
+	return r.doJSON(ctx, http.MethodPost, runActionPath(runID, "stop"), nil, map[string]any{}, nil)
+}
+
+func (r *RemoteCore) ResumeRun(ctx context.Context, runID string) error {
+	return r.doJSON(ctx, http.MethodPost, runActionPath(runID, "resume"), nil, map[string]any{}, nil)
+}
+
+// StartResumeRun is ResumeRun: the daemon always resumes in the background.
+func (r *RemoteCore) StartResumeRun(ctx context.Context, runID string) error {
+	return r.ResumeRun(ctx, runID)
+}
+
+func (r *RemoteCore) RestartRun(ctx context.Context, options RestartOptions) (RestartResult, error) {
+	runID, err := r.ResolveRunID(ctx, options.RunID, options.Ticket)
+	if err != nil {
+		return RestartResult{}, err
+	}
+	var response struct {
+		Result RestartResult `json:"result"`
+	}
+	payload := map[string]any{"dry_run": options.DryRun}
+	if err := r.doJSON(ctx, http.MethodPost, runActionPath(runID, "restart"), nil, payload, &response); err != nil {
+		return RestartResult{}, err
+	}
+	return response.Result, nil
+}
+
+func (r *RemoteCore) IterateRun(ctx context.Context, options IterateOptions) (IterateResult, error) {
+	runID, err := r.ResolveRunID(ctx, options.RunID, options.Ticket)
+	if err != nil {
+		return IterateResult{}, err
+	}
+	var response struct {
+		Result IterateResult `json:"result"`
+	}
+	payload := map[string]any{
+		"feedback": strings.TrimSpace(options.Feedback),
+		"dry_run":  options.DryRun,
+	}
+	if err := r.doJSON(ctx, http.MethodPost, runActionPath(runID, "iterate"), nil, payload, &response); err != nil {
+		return IterateResult{}, err
+	}
+	return response.Result, nil
+}
+
+func (r *RemoteCore) CommitRun(ctx context.Context, options CommitOptions) (CommitResult, error) {
+	runID, err := r.ResolveRunID(ctx, options.RunID, options.Ticket)
+	if err != nil {
+		return CommitResult{}, err
+	}
+	var response struct {
+		Result CommitResult `json:"result"`
+	}
+	payload := map[string]any{
+		"message": strings.TrimSpace(options.Message),
+		"actor":   strings.TrimSpace(options.Actor),
+		"dry_run": options.DryRun,
+	}
+	if err := r.doJSON(ctx, http.MethodPost, runActionPath(runID, "commit"), nil, payload, &response); err != nil {
+		return CommitResult{}, err
+	}
+	return response.Result, nil
+}
+
+func (r *RemoteCore) OpenPullRequests(ctx context.Context, options PullRequestOptions) (PullRequestResult, error) {
+	runID, err := r.ResolveRunID(ctx, options.RunID, options.Ticket)
+	if err != nil {
+		return PullRequestResult{}, err
+	}
+	var response struct {
+		Result PullRequestResult `json:"result"`
+	}
+	payload := map[string]any{
+		"title":   strings.TrimSpace(options.Title),
+		"body":    strings.TrimSpace(options.Body),
+		"actor":   strings.TrimSpace(options.Actor),
+		"dry_run": options.DryRun,
+	}
+	if err := r.doJSON(ctx, http.MethodPost, runActionPath(runID, "pr"), nil, payload, &response); err != nil {
+		return PullRequestResult{}, err
+	}
+	return response.Result, nil
+}
+
+func (r *RemoteCore) MergeRun(ctx context.Context, options MergeOptions) (MergeResult, error) {
+	runID, err := r.ResolveRunID(ctx, options.RunID, options.Ticket)
+	if err != nil {
+		return MergeResult{}, err
+	}
+	var response struct {
+		Result MergeResult `json:"result"`
+	}
+	payload := map[string]any{"dry_run": options.DryRun}
+	if err := r.doJSON(ctx, http.MethodPost, runActionPath(runID, "merge"), nil, payload, &response); err != nil {
+		return MergeResult{}, err
+	}
+	return response.Result, nil
+}
+
+func (r *RemoteCore) CloseRun(ctx context.Context, options CloseOptions) error {
+	payload := map[string]any{
+		"dry_run":         options.DryRun,
+		"changelog_entry": strings.TrimSpace(options.ChangelogEntry),
+	}
+	return r.doJSON(ctx, http.MethodPost, runActionPath(options.RunID, "close"), nil, payload, nil)
+}
+
+func (r *RemoteCore) CleanupRun(ctx context.Context, options CleanupOptions) (CleanupResult, error) {
+	runID, err := r.ResolveRunID(ctx, options.RunID, options.Ticket)
+	if err != nil {
+		return CleanupResult{}, err
+	}
+	var response struct {
+		Result CleanupResult `json:"result"`
+	}
+	payload := map[string]any{
+		"dry_run":           options.DryRun,
+		"delete_workspaces": options.DeleteWorkspaces,
+	}
+	if err := r.doJSON(ctx, http.MethodPost, runActionPath(runID, "cleanup"), nil, payload, &response); err != nil {
+		return CleanupResult{}, err
+	}
+	return response.Result, nil
+}
+
+func runActionPath(runID string, action string) string {
+	return "/api/v1/runs/" + url.PathEscape(strings.TrimSpace(runID)) + "/" + action
+}
+
 func (r *RemoteCore) ForumOpenThread(ctx context.Context, options ForumOpenThreadOptions) (model.ForumThreadView, error) {
 	payload := map[string]any{
 		"thread_id":      strings.TrimSpace(options.ThreadID),
@@ -114,6 +439,7 @@ func (r *RemoteCore) ForumOpenThread(ctx context.Context, options ForumOpenThrea
 		"title":          strings.TrimSpace(options.Title),
 		"body":           strings.TrimSpace(options.Body),
 		"priority":       strings.TrimSpace(string(options.Priority)),
+		"attachments":    options.Attachments,
 		"actor_type":     strings.TrimSpace(string(options.ActorType)),
 		"actor_name":     strings.TrimSpace(options.ActorName),
 		"correlation_id": strings.TrimSpace(options.CorrelationID),
@@ -131,6 +457,7 @@ func (r *RemoteCore) ForumOpenThread(ctx context.Context, options ForumOpenThrea
 func (r *RemoteCore) ForumAddPost(ctx context.Context, options ForumAddPostOptions) (model.ForumThreadView, error) {
 	payload := map[string]any{
 		"body":           strings.TrimSpace(options.Body),
+		"attachments":    options.Attachments,
 		"actor_type":     strings.TrimSpace(string(options.ActorType)),
 		"actor_name":     strings.TrimSpace(options.ActorName),
 		"correlation_id": strings.TrimSpace(options.CorrelationID),
@@ -320,6 +647,10 @@ func (r *RemoteCore) ForumWatchEvents(ticket string, cursor int64, limit int) ([
 	return response.Events, nil
 }
 
+func (r *RemoteCore) ForumLatestEventSequence() (int64, error) {
+	return 0, fmt.Errorf("remote core does not support ForumLatestEventSequence")
+}
+
 func (r *RemoteCore) ForumSearchThreads(options ForumSearchThreadsOptions) ([]model.ForumThreadView, error) {
 	query := map[string]string{}
 	if strings.TrimSpace(options.Query) != "" {
@@ -340,6 +671,12 @@ func (r *RemoteCore) ForumSearchThreads(options ForumSearchThreadsOptions) ([]mo
 	if strings.TrimSpace(options.Assignee) != "" {
 		query["assignee"] = strings.TrimSpace(options.Assignee)
 	}
+	if strings.TrimSpace(string(options.ActorType)) != "" {
+		query["actor_type"] = strings.TrimSpace(string(options.ActorType))
+	}
+	if strings.TrimSpace(options.ActorName) != "" {
+		query["actor"] = strings.TrimSpace(options.ActorName)
+	}
 	if strings.TrimSpace(string(options.ViewerType)) != "" {
 		query["viewer_type"] = strings.TrimSpace(string(options.ViewerType))
 	}
@@ -418,6 +755,117 @@ func (r *RemoteCore) ForumMarkThreadSeen(ctx context.Context, options ForumMarkT
 	return response.Seen, nil
 }
 
+func (r *RemoteCore) GuidanceLibraryList(filter model.GuidanceAnswerFilter) ([]model.GuidanceAnswer, error) {
+	query := map[string]string{}
+	if strings.TrimSpace(string(filter.ScopeType)) != "" {
+		query["scope_type"] = strings.TrimSpace(string(filter.ScopeType))
+	}
+	if strings.TrimSpace(filter.Scope) != "" {
+		query["scope"] = strings.TrimSpace(filter.Scope)
+	}
+	if strings.TrimSpace(filter.Ticket) != "" {
+		query["ticket"] = strings.TrimSpace(filter.Ticket)
+	}
+	if filter.Limit > 0 {
+		query["limit"] = strconv.Itoa(filter.Limit)
+	}
+	var response struct {
+		Answers []model.GuidanceAnswer `json:"answers"`
+	}
+	if err := r.doJSON(context.Background(), http.MethodGet, "/api/v1/forum/guidance/library", query, nil, &response); err != nil {
+		return nil, err
+	}
+	return response.Answers, nil
+}
+
+func (r *RemoteCore) GuidanceLibraryPromote(ctx context.Context, options GuidanceLibraryPromoteOptions) (model.GuidanceAnswer, error) {
+	payload := map[string]any{
+		"answer_id":  strings.TrimSpace(options.AnswerID),
+		"scope_type": strings.TrimSpace(string(options.ScopeType)),
+		"scope":      strings.TrimSpace(options.Scope),
+		"question":   strings.TrimSpace(options.Question),
+		"context":    strings.TrimSpace(options.Context),
+		"answer":     strings.TrimSpace(options.Answer),
+		"actor_name": strings.TrimSpace(options.ActorName),
+	}
+	var response struct {
+		Answer model.GuidanceAnswer `json:"answer"`
+	}
+	if err := r.doJSON(ctx, http.MethodPost, "/api/v1/forum/guidance/library", nil, payload, &response); err != nil {
+		return model.GuidanceAnswer{}, err
+	}
+	return response.Answer, nil
+}
+
+func (r *RemoteCore) GuidanceLibraryRemove(ctx context.Context, answerID string) error {
+	path := "/api/v1/forum/guidance/library/" + url.PathEscape(strings.TrimSpace(answerID)) + "/remove"
+	return r.doJSON(ctx, http.MethodPost, path, nil, map[string]any{}, nil)
+}
+
+func (r *RemoteCore) GuidanceLibrarySync(ctx context.Context) (int, error) {
+	var response struct {
+		Added int `json:"added"`
+	}
+	if err := r.doJSON(ctx, http.MethodPost, "/api/v1/forum/guidance/library/sync", nil, map[string]any{}, &response); err != nil {
+		return 0, err
+	}
+	return response.Added, nil
+}
+
+func (r *RemoteCore) GuidanceSuggest(options GuidanceSuggestOptions) ([]model.GuidanceSuggestion, error) {
+	query := map[string]string{}
+	if strings.TrimSpace(options.RunID) != "" {
+		query["run_id"] = strings.TrimSpace(options.RunID)
+	}
+	if strings.TrimSpace(options.AgentName) != "" {
+		query["agent"] = strings.TrimSpace(options.AgentName)
+	}
+	if strings.TrimSpace(options.Ticket) != "" {
+		query["ticket"] = strings.TrimSpace(options.Ticket)
+	}
+	if len(options.Repos) > 0 {
+		query["repos"] = strings.Join(options.Repos, ",")
+	}
+	if strings.TrimSpace(options.Question) != "" {
+		query["question"] = strings.TrimSpace(options.Question)
+	}
+	if options.Limit > 0 {
+		query["limit"] = strconv.Itoa(options.Limit)
+	}
+	var response struct {
+		Suggestions []model.GuidanceSuggestion `json:"suggestions"`
+	}
+	if err := r.doJSON(context.Background(), http.MethodGet, "/api/v1/forum/guidance/suggestions", query, nil, &response); err != nil {
+		return nil, err
+	}
+	return response.Suggestions, nil
+}
+
+func (r *RemoteCore) OperatorPass(_ context.Context, _ OperatorPassOptions) (OperatorPassResult, error) {
+	return OperatorPassResult{}, fmt.Errorf("remote core does not support OperatorPass")
+}
+
+func (r *RemoteCore) OperatorDecisions(ctx context.Context, runID string, limit int) ([]OperatorRunDecisions, error) {
+	query := map[string]string{}
+	if strings.TrimSpace(runID) != "" {
+		query["run_id"] = strings.TrimSpace(runID)
+	}
+	if limit > 0 {
+		query["limit"] = strconv.Itoa(limit)
+	}
+	var response struct {
+		Runs []OperatorRunDecisions `json:"runs"`
+	}
+	if err := r.doJSON(ctx, http.MethodGet, "/api/v1/operator", query, nil, &response); err != nil {
+		return nil, err
+	}
+	return response.Runs, nil
+}
+
+func (r *RemoteCore) ReleaseOperatorLeases(_ context.Context, _ string) error {
+	return fmt.Errorf("remote core does not support ReleaseOperatorLeases")
+}
+
 func (r *RemoteCore) doJSON(ctx context.Context, method string, path string, query map[string]string, body any, out any) error {
 	if ctx == nil {
 		ctx = context.Background()
@@ -448,6 +896,9 @@ func (r *RemoteCore) doJSON(ctx context.Context, method string, path string, que
 		return err
 	}
 	request.Header.Set("Accept", "application/json")
+	if r.token != "" {
+		request.Header.Set("Authorization", "Bearer "+r.token)
+	}
 	if method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch {
 		request.Header.Set("Content-Type", "application/json")
 	}
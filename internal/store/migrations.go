@@ -0,0 +1,813 @@
+package store
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Migration is one numbered, forward-only schema change. Applied versions are
+// recorded in schema_migrations and each migration runs in its own transaction.
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+type AppliedMigration struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+}
+
+type SchemaStatus struct {
+	DBPath         string
+	CurrentVersion int
+	LatestVersion  int
+	Applied        []AppliedMigration
+	Pending        []Migration
+}
+
+type MigrateOptions struct {
+	ToVersion int
+	DryRun    bool
+}
+
+type MigrateResult struct {
+	FromVersion int
+	ToVersion   int
+	DryRun      bool
+	Migrations  []Migration
+}
+
+// SchemaTooNewError reports a database migrated by a newer metawsm binary.
+type SchemaTooNewError struct {
+	DBPath           string
+	Version          int
+	SupportedVersion int
+}
+
+func (e *SchemaTooNewError) Error() string {
+	return fmt.Sprintf(
+		"database %s is at schema version %d but this metawsm binary only supports up to %d; upgrade metawsm",
+		e.DBPath, e.Version, e.SupportedVersion,
+	)
+}
+
+// migrations must stay ordered by version. Never edit a released migration;
+// append a new one instead.
+var migrations = []Migration{
+	{Version: 1, Name: "initial_schema", SQL: migration0001InitialSchema},
+	{Version: 2, Name: "step_dependencies", SQL: migration0002StepDependencies},
+	{Version: 3, Name: "agent_transcripts", SQL: migration0003AgentTranscripts},
+	{Version: 4, Name: "api_tokens", SQL: migration0004APITokens},
+	{Version: 5, Name: "api_token_scope", SQL: migration0005APITokenScope},
+	{Version: 6, Name: "forum_outbox_retry", SQL: migration0006ForumOutboxRetry},
+	{Version: 7, Name: "forum_thread_escalations", SQL: migration0007ForumThreadEscalations},
+	{Version: 8, Name: "forum_post_attachments", SQL: migration0008ForumPostAttachments},
+	{Version: 9, Name: "forum_search_index", SQL: migration0009ForumSearchIndex},
+	{Version: 10, Name: "guidance_answers", SQL: migration0010GuidanceAnswers},
+	{Version: 11, Name: "operator_supervision", SQL: migration0011OperatorSupervision},
+	{Version: 12, Name: "operator_decisions", SQL: migration0012OperatorDecisions},
+	{Version: 13, Name: "operator_rule_firings", SQL: migration0013OperatorRuleFirings},
+	{Version: 14, Name: "integration_rule_receipts", SQL: migration0014IntegrationRuleReceipts},
+	{Version: 15, Name: "operator_decision_repeats", SQL: migration0015OperatorDecisionRepeats},
+	{Version: 16, Name: "notifier_cursors", SQL: migration0016NotifierCursors},
+	{Version: 17, Name: "operator_decision_repeat_counts", SQL: migration0017OperatorDecisionRepeatCounts},
+}
+
+func Migrations() []Migration {
+	out := make([]Migration, len(migrations))
+	copy(out, migrations)
+	return out
+}
+
+func LatestSchemaVersion() int {
+	if len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].Version
+}
+
+func (s *SQLiteStore) SchemaStatus() (SchemaStatus, error) {
+	status := SchemaStatus{
+		DBPath:        s.DBPath,
+		LatestVersion: LatestSchemaVersion(),
+		Applied:       []AppliedMigration{},
+		Pending:       []Migration{},
+	}
+	applied, err := s.appliedMigrations()
+	if err != nil {
+		return status, err
+	}
+	appliedVersions := map[int]struct{}{}
+	for _, item := range applied {
+		appliedVersions[item.Version] = struct{}{}
+		if item.Version > status.CurrentVersion {
+			status.CurrentVersion = item.Version
+		}
+	}
+	status.Applied = applied
+	for _, migration := range migrations {
+		if _, ok := appliedVersions[migration.Version]; !ok {
+			status.Pending = append(status.Pending, migration)
+		}
+	}
+	return status, nil
+}
+
+// Migrate applies pending migrations up to options.ToVersion (latest when zero).
+// Dry runs report the plan without touching the database file.
+func (s *SQLiteStore) Migrate(options MigrateOptions) (MigrateResult, error) {
+	result := MigrateResult{DryRun: options.DryRun}
+	if !options.DryRun {
+		if err := s.prepareSchema(); err != nil {
+			return result, err
+		}
+	}
+	status, err := s.SchemaStatus()
+	if err != nil {
+		return result, err
+	}
+	result.FromVersion = status.CurrentVersion
+	if status.CurrentVersion > status.LatestVersion {
+		return result, &SchemaTooNewError{
+			DBPath:           s.DBPath,
+			Version:          status.CurrentVersion,
+			SupportedVersion: status.LatestVersion,
+		}
+	}
+
+	target := options.ToVersion
+	if target <= 0 {
+		target = status.LatestVersion
+	}
+	if target > status.LatestVersion {
+		return result, fmt.Errorf("unknown schema version %d (latest is %d)", target, status.LatestVersion)
+	}
+	if target < status.CurrentVersion {
+		return result, fmt.Errorf("cannot migrate down from schema version %d to %d", status.CurrentVersion, target)
+	}
+	result.ToVersion = target
+
+	result.Migrations = []Migration{}
+	for _, migration := range status.Pending {
+		if migration.Version > target {
+			break
+		}
+		result.Migrations = append(result.Migrations, migration)
+	}
+	if options.DryRun {
+		return result, nil
+	}
+	for _, migration := range result.Migrations {
+		if err := s.applyMigration(migration); err != nil {
+			return result, fmt.Errorf("apply migration %d (%s): %w", migration.Version, migration.Name, err)
+		}
+	}
+	return result, nil
+}
+
+func (s *SQLiteStore) prepareSchema() error {
+	if err := os.MkdirAll(filepath.Dir(s.DBPath), 0o755); err != nil {
+		return fmt.Errorf("create db dir: %w", err)
+	}
+	return s.execSQL(`PRAGMA journal_mode=WAL;
+CREATE TABLE IF NOT EXISTS schema_migrations (
+  version INTEGER PRIMARY KEY,
+  applied_at TEXT NOT NULL
+);`)
+}
+
+func (s *SQLiteStore) applyMigration(migration Migration) error {
+	// Recording the version first makes a concurrent migrator fail on the
+	// primary key before it re-runs non-idempotent statements.
+	script := fmt.Sprintf(
+		`BEGIN IMMEDIATE;
+INSERT INTO schema_migrations (version, applied_at) VALUES (%d, %s);
+%s
+COMMIT;`,
+		migration.Version,
+		quote(time.Now().Format(time.RFC3339)),
+		strings.TrimSpace(migration.SQL),
+	)
+	if err := s.execSQL(script); err != nil {
+		applied, checkErr := s.migrationApplied(migration.Version)
+		if checkErr == nil && applied {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *SQLiteStore) migrationApplied(version int) (bool, error) {
+	rows, err := s.queryJSON(`SELECT version FROM schema_migrations WHERE version=?;`, version)
+	if err != nil {
+		return false, err
+	}
+	return len(rows) > 0, nil
+}
+
+func (s *SQLiteStore) appliedMigrations() ([]AppliedMigration, error) {
+	if _, err := os.Stat(s.DBPath); os.IsNotExist(err) {
+		return []AppliedMigration{}, nil
+	}
+	tables, err := s.queryJSON(`SELECT name FROM sqlite_master WHERE type='table' AND name='schema_migrations';`)
+	if err != nil {
+		return nil, err
+	}
+	if len(tables) == 0 {
+		return []AppliedMigration{}, nil
+	}
+	rows, err := s.queryJSON(`SELECT version, applied_at FROM schema_migrations ORDER BY version;`)
+	if err != nil {
+		return nil, err
+	}
+	names := map[int]string{}
+	for _, migration := range migrations {
+		names[migration.Version] = migration.Name
+	}
+	out := make([]AppliedMigration, 0, len(rows))
+	for _, row := range rows {
+		version := asInt(row["version"])
+		out = append(out, AppliedMigration{
+			Version:   version,
+			Name:      names[version],
+			AppliedAt: parseTimePtr(asString(row["applied_at"])),
+		})
+	}
+	return out, nil
+}
+
+const migration0001InitialSchema = `
+CREATE TABLE IF NOT EXISTS runs (
+  run_id TEXT PRIMARY KEY,
+  status TEXT NOT NULL,
+  created_at TEXT NOT NULL,
+  updated_at TEXT NOT NULL,
+  spec_json TEXT NOT NULL,
+  policy_json TEXT NOT NULL,
+  error_text TEXT NOT NULL DEFAULT ''
+);
+CREATE TABLE IF NOT EXISTS run_tickets (
+  run_id TEXT NOT NULL,
+  ticket TEXT NOT NULL,
+  PRIMARY KEY (run_id, ticket)
+);
+CREATE TABLE IF NOT EXISTS steps (
+  run_id TEXT NOT NULL,
+  step_index INTEGER NOT NULL,
+  name TEXT NOT NULL,
+  kind TEXT NOT NULL,
+  command_text TEXT NOT NULL,
+  blocking INTEGER NOT NULL,
+  ticket TEXT NOT NULL DEFAULT '',
+  workspace_name TEXT NOT NULL DEFAULT '',
+  agent_name TEXT NOT NULL DEFAULT '',
+  status TEXT NOT NULL,
+  error_text TEXT NOT NULL DEFAULT '',
+  started_at TEXT NOT NULL DEFAULT '',
+  finished_at TEXT NOT NULL DEFAULT '',
+  PRIMARY KEY (run_id, step_index)
+);
+CREATE TABLE IF NOT EXISTS agents (
+  run_id TEXT NOT NULL,
+  agent_name TEXT NOT NULL,
+  workspace_name TEXT NOT NULL,
+  session_name TEXT NOT NULL,
+  status TEXT NOT NULL,
+  health_state TEXT NOT NULL,
+  last_activity_at TEXT NOT NULL DEFAULT '',
+  last_progress_at TEXT NOT NULL DEFAULT '',
+  PRIMARY KEY (run_id, agent_name, workspace_name)
+);
+CREATE TABLE IF NOT EXISTS events (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  run_id TEXT NOT NULL,
+  entity_type TEXT NOT NULL,
+  entity_id TEXT NOT NULL,
+  event_type TEXT NOT NULL,
+  from_state TEXT NOT NULL DEFAULT '',
+  to_state TEXT NOT NULL DEFAULT '',
+  message TEXT NOT NULL DEFAULT '',
+  created_at TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS run_briefs (
+  run_id TEXT PRIMARY KEY,
+  ticket TEXT NOT NULL,
+  goal TEXT NOT NULL,
+  scope TEXT NOT NULL,
+  done_criteria TEXT NOT NULL,
+  constraints_text TEXT NOT NULL,
+  merge_intent TEXT NOT NULL,
+  qa_json TEXT NOT NULL,
+  created_at TEXT NOT NULL,
+  updated_at TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS guidance_requests (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  run_id TEXT NOT NULL,
+  workspace_name TEXT NOT NULL,
+  agent_name TEXT NOT NULL,
+  question TEXT NOT NULL,
+  context_text TEXT NOT NULL DEFAULT '',
+  answer_text TEXT NOT NULL DEFAULT '',
+  status TEXT NOT NULL,
+  created_at TEXT NOT NULL,
+  answered_at TEXT NOT NULL DEFAULT ''
+);
+CREATE TABLE IF NOT EXISTS forum_threads (
+  thread_id TEXT PRIMARY KEY,
+  ticket TEXT NOT NULL,
+  run_id TEXT NOT NULL DEFAULT '',
+  agent_name TEXT NOT NULL DEFAULT '',
+  title TEXT NOT NULL,
+  state TEXT NOT NULL,
+  priority TEXT NOT NULL,
+  assignee_type TEXT NOT NULL DEFAULT '',
+  assignee_name TEXT NOT NULL DEFAULT '',
+  opened_by_type TEXT NOT NULL,
+  opened_by_name TEXT NOT NULL DEFAULT '',
+  opened_at TEXT NOT NULL,
+  updated_at TEXT NOT NULL,
+  closed_at TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS idx_forum_threads_ticket_state_priority
+  ON forum_threads (ticket, state, priority);
+CREATE TABLE IF NOT EXISTS forum_posts (
+  post_id TEXT PRIMARY KEY,
+  thread_id TEXT NOT NULL,
+  event_id TEXT NOT NULL UNIQUE,
+  author_type TEXT NOT NULL,
+  author_name TEXT NOT NULL DEFAULT '',
+  body_text TEXT NOT NULL,
+  created_at TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_forum_posts_thread_created
+  ON forum_posts (thread_id, created_at);
+CREATE TABLE IF NOT EXISTS forum_assignments (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  thread_id TEXT NOT NULL,
+  event_id TEXT NOT NULL UNIQUE,
+  from_assignee_type TEXT NOT NULL DEFAULT '',
+  from_assignee_name TEXT NOT NULL DEFAULT '',
+  to_assignee_type TEXT NOT NULL DEFAULT '',
+  to_assignee_name TEXT NOT NULL DEFAULT '',
+  changed_by_type TEXT NOT NULL,
+  changed_by_name TEXT NOT NULL DEFAULT '',
+  changed_at TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS forum_state_transitions (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  thread_id TEXT NOT NULL,
+  event_id TEXT NOT NULL UNIQUE,
+  from_state TEXT NOT NULL DEFAULT '',
+  to_state TEXT NOT NULL,
+  changed_by_type TEXT NOT NULL,
+  changed_by_name TEXT NOT NULL DEFAULT '',
+  changed_at TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS forum_events (
+  sequence INTEGER PRIMARY KEY AUTOINCREMENT,
+  event_id TEXT NOT NULL UNIQUE,
+  event_type TEXT NOT NULL,
+  event_version INTEGER NOT NULL,
+  occurred_at TEXT NOT NULL,
+  thread_id TEXT NOT NULL,
+  run_id TEXT NOT NULL DEFAULT '',
+  ticket TEXT NOT NULL,
+  agent_name TEXT NOT NULL DEFAULT '',
+  actor_type TEXT NOT NULL,
+  actor_name TEXT NOT NULL DEFAULT '',
+  correlation_id TEXT NOT NULL DEFAULT '',
+  causation_id TEXT NOT NULL DEFAULT '',
+  payload_json TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS idx_forum_events_ticket_sequence
+  ON forum_events (ticket, sequence);
+CREATE TABLE IF NOT EXISTS forum_thread_views (
+  thread_id TEXT PRIMARY KEY,
+  ticket TEXT NOT NULL,
+  run_id TEXT NOT NULL DEFAULT '',
+  agent_name TEXT NOT NULL DEFAULT '',
+  title TEXT NOT NULL,
+  state TEXT NOT NULL,
+  priority TEXT NOT NULL,
+  assignee_type TEXT NOT NULL DEFAULT '',
+  assignee_name TEXT NOT NULL DEFAULT '',
+  opened_by_type TEXT NOT NULL,
+  opened_by_name TEXT NOT NULL DEFAULT '',
+  posts_count INTEGER NOT NULL DEFAULT 0,
+  last_post_at TEXT NOT NULL DEFAULT '',
+  last_post_by_type TEXT NOT NULL DEFAULT '',
+  last_post_by_name TEXT NOT NULL DEFAULT '',
+  opened_at TEXT NOT NULL,
+  updated_at TEXT NOT NULL,
+  closed_at TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS idx_forum_thread_views_ticket_filters
+  ON forum_thread_views (ticket, state, priority, updated_at);
+CREATE TABLE IF NOT EXISTS forum_thread_reads (
+  thread_id TEXT NOT NULL,
+  viewer_type TEXT NOT NULL,
+  viewer_id TEXT NOT NULL,
+  last_seen_event_sequence INTEGER NOT NULL DEFAULT 0,
+  updated_at TEXT NOT NULL,
+  PRIMARY KEY (thread_id, viewer_type, viewer_id)
+);
+CREATE INDEX IF NOT EXISTS idx_forum_thread_reads_viewer
+  ON forum_thread_reads (viewer_type, viewer_id, updated_at);
+CREATE TABLE IF NOT EXISTS forum_thread_queue_view (
+  thread_id TEXT PRIMARY KEY,
+  ticket TEXT NOT NULL,
+  run_id TEXT NOT NULL DEFAULT '',
+  state TEXT NOT NULL,
+  priority TEXT NOT NULL,
+  assignee_name TEXT NOT NULL DEFAULT '',
+  last_event_sequence INTEGER NOT NULL DEFAULT 0,
+  last_actor_type TEXT NOT NULL DEFAULT '',
+  last_non_system_actor_type TEXT NOT NULL DEFAULT '',
+  last_human_or_operator_sequence INTEGER NOT NULL DEFAULT 0,
+  last_agent_sequence INTEGER NOT NULL DEFAULT 0,
+  updated_at TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_forum_thread_queue_view_filters
+  ON forum_thread_queue_view (ticket, run_id, state, priority, last_event_sequence DESC);
+CREATE TABLE IF NOT EXISTS forum_thread_stats (
+  ticket TEXT NOT NULL,
+  run_id TEXT NOT NULL DEFAULT '',
+  state TEXT NOT NULL,
+  priority TEXT NOT NULL,
+  thread_count INTEGER NOT NULL DEFAULT 0,
+  updated_at TEXT NOT NULL,
+  PRIMARY KEY (ticket, run_id, state, priority)
+);
+CREATE TABLE IF NOT EXISTS forum_projection_events (
+  projection_name TEXT NOT NULL,
+  event_id TEXT NOT NULL,
+  applied_at TEXT NOT NULL,
+  PRIMARY KEY (projection_name, event_id)
+);
+CREATE TABLE IF NOT EXISTS forum_control_threads (
+  run_id TEXT NOT NULL,
+  agent_name TEXT NOT NULL,
+  ticket TEXT NOT NULL,
+  thread_id TEXT NOT NULL UNIQUE,
+  created_at TEXT NOT NULL,
+  updated_at TEXT NOT NULL,
+  PRIMARY KEY (run_id, agent_name)
+);
+CREATE TABLE IF NOT EXISTS forum_outbox (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  message_id TEXT NOT NULL UNIQUE,
+  topic TEXT NOT NULL,
+  message_key TEXT NOT NULL DEFAULT '',
+  payload_json TEXT NOT NULL,
+  status TEXT NOT NULL,
+  attempt_count INTEGER NOT NULL DEFAULT 0,
+  last_error TEXT NOT NULL DEFAULT '',
+  created_at TEXT NOT NULL,
+  updated_at TEXT NOT NULL,
+  sent_at TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS idx_forum_outbox_status_created
+  ON forum_outbox (status, created_at, id);
+CREATE TABLE IF NOT EXISTS doc_sync_states (
+  run_id TEXT NOT NULL,
+  ticket TEXT NOT NULL,
+  workspace_name TEXT NOT NULL,
+  doc_home_repo TEXT NOT NULL DEFAULT '',
+  doc_authority_mode TEXT NOT NULL DEFAULT '',
+  doc_seed_mode TEXT NOT NULL DEFAULT '',
+  status TEXT NOT NULL,
+  revision TEXT NOT NULL DEFAULT '',
+  error_text TEXT NOT NULL DEFAULT '',
+  updated_at TEXT NOT NULL,
+  PRIMARY KEY (run_id, ticket, workspace_name)
+);
+CREATE TABLE IF NOT EXISTS operator_run_states (
+  run_id TEXT PRIMARY KEY,
+  restart_attempts INTEGER NOT NULL DEFAULT 0,
+  last_restart_at TEXT NOT NULL DEFAULT '',
+  cooldown_until TEXT NOT NULL DEFAULT '',
+  updated_at TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS run_pull_requests (
+  run_id TEXT NOT NULL,
+  ticket TEXT NOT NULL,
+  repo TEXT NOT NULL,
+  workspace_name TEXT NOT NULL DEFAULT '',
+  head_branch TEXT NOT NULL DEFAULT '',
+  base_branch TEXT NOT NULL DEFAULT '',
+  remote_name TEXT NOT NULL DEFAULT '',
+  commit_sha TEXT NOT NULL DEFAULT '',
+  pr_number INTEGER NOT NULL DEFAULT 0,
+  pr_url TEXT NOT NULL DEFAULT '',
+  pr_state TEXT NOT NULL DEFAULT '',
+  credential_mode TEXT NOT NULL DEFAULT '',
+  actor TEXT NOT NULL DEFAULT '',
+  validation_json TEXT NOT NULL DEFAULT '',
+  error_text TEXT NOT NULL DEFAULT '',
+  created_at TEXT NOT NULL,
+  updated_at TEXT NOT NULL,
+  PRIMARY KEY (run_id, ticket, repo)
+);
+CREATE TABLE IF NOT EXISTS run_review_feedback (
+  run_id TEXT NOT NULL,
+  ticket TEXT NOT NULL,
+  repo TEXT NOT NULL,
+  workspace_name TEXT NOT NULL DEFAULT '',
+  pr_number INTEGER NOT NULL DEFAULT 0,
+  pr_url TEXT NOT NULL DEFAULT '',
+  source_type TEXT NOT NULL,
+  source_id TEXT NOT NULL,
+  source_url TEXT NOT NULL DEFAULT '',
+  author TEXT NOT NULL DEFAULT '',
+  body_text TEXT NOT NULL DEFAULT '',
+  file_path TEXT NOT NULL DEFAULT '',
+  line_number INTEGER NOT NULL DEFAULT 0,
+  status TEXT NOT NULL DEFAULT '',
+  error_text TEXT NOT NULL DEFAULT '',
+  created_at TEXT NOT NULL,
+  updated_at TEXT NOT NULL,
+  last_seen_at TEXT NOT NULL,
+  addressed_at TEXT NOT NULL DEFAULT '',
+  PRIMARY KEY (run_id, ticket, repo, pr_number, source_type, source_id)
+);
+CREATE INDEX IF NOT EXISTS idx_run_review_feedback_run_status
+  ON run_review_feedback (run_id, status);`
+
+const migration0002StepDependencies = `
+ALTER TABLE steps ADD COLUMN depends_on_json TEXT NOT NULL DEFAULT '';
+`
+
+const migration0003AgentTranscripts = `
+CREATE TABLE IF NOT EXISTS agent_transcripts (
+  run_id TEXT NOT NULL,
+  agent_name TEXT NOT NULL,
+  workspace_name TEXT NOT NULL,
+  path TEXT NOT NULL,
+  runtime TEXT NOT NULL DEFAULT '',
+  size_bytes INTEGER NOT NULL DEFAULT 0,
+  rotations INTEGER NOT NULL DEFAULT 0,
+  started_at TEXT NOT NULL DEFAULT '',
+  updated_at TEXT NOT NULL DEFAULT '',
+  PRIMARY KEY (run_id, agent_name, workspace_name)
+);
+`
+
+const migration0004APITokens = `
+CREATE TABLE IF NOT EXISTS api_tokens (
+  token_id TEXT PRIMARY KEY,
+  token_hash TEXT NOT NULL UNIQUE,
+  role TEXT NOT NULL,
+  actor_name TEXT NOT NULL,
+  created_at TEXT NOT NULL,
+  expires_at TEXT NOT NULL DEFAULT '',
+  revoked_at TEXT NOT NULL DEFAULT ''
+);
+`
+
+const migration0005APITokenScope = `
+ALTER TABLE api_tokens ADD COLUMN run_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE api_tokens ADD COLUMN agent_name TEXT NOT NULL DEFAULT '';
+ALTER TABLE api_tokens ADD COLUMN workspace_name TEXT NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_api_tokens_run_agent ON api_tokens(run_id, agent_name, workspace_name);
+`
+
+// migration0006ForumOutboxRetry adds retry scheduling and claim leases to the
+// outbox. Rows already in processing get an expired lease so the next claim
+// picks them up.
+const migration0006ForumOutboxRetry = `
+ALTER TABLE forum_outbox ADD COLUMN next_attempt_at TEXT NOT NULL DEFAULT '';
+ALTER TABLE forum_outbox ADD COLUMN lease_expires_at TEXT NOT NULL DEFAULT '';
+UPDATE forum_outbox SET lease_expires_at=updated_at WHERE status='processing';
+CREATE INDEX IF NOT EXISTS idx_forum_outbox_status_next_attempt ON forum_outbox(status, next_attempt_at, id);
+`
+
+// migration0007ForumThreadEscalations lets forum_state_transitions record SLA
+// escalations alongside state changes. Existing rows are plain state changes.
+const migration0007ForumThreadEscalations = `
+ALTER TABLE forum_state_transitions ADD COLUMN kind TEXT NOT NULL DEFAULT 'state';
+ALTER TABLE forum_state_transitions ADD COLUMN escalation_level INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE forum_state_transitions ADD COLUMN from_priority TEXT NOT NULL DEFAULT '';
+ALTER TABLE forum_state_transitions ADD COLUMN to_priority TEXT NOT NULL DEFAULT '';
+ALTER TABLE forum_state_transitions ADD COLUMN from_assignee_type TEXT NOT NULL DEFAULT '';
+ALTER TABLE forum_state_transitions ADD COLUMN from_assignee_name TEXT NOT NULL DEFAULT '';
+ALTER TABLE forum_state_transitions ADD COLUMN to_assignee_type TEXT NOT NULL DEFAULT '';
+ALTER TABLE forum_state_transitions ADD COLUMN to_assignee_name TEXT NOT NULL DEFAULT '';
+ALTER TABLE forum_state_transitions ADD COLUMN reason TEXT NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_forum_state_transitions_thread_kind ON forum_state_transitions(thread_id, kind, id);
+`
+
+const migration0008ForumPostAttachments = `
+CREATE TABLE IF NOT EXISTS forum_post_attachments (
+  post_id TEXT NOT NULL,
+  position INTEGER NOT NULL,
+  thread_id TEXT NOT NULL,
+  kind TEXT NOT NULL,
+  digest TEXT NOT NULL,
+  size_bytes INTEGER NOT NULL DEFAULT 0,
+  title TEXT NOT NULL DEFAULT '',
+  repo TEXT NOT NULL DEFAULT '',
+  path TEXT NOT NULL DEFAULT '',
+  start_line INTEGER NOT NULL DEFAULT 0,
+  end_line INTEGER NOT NULL DEFAULT 0,
+  command TEXT NOT NULL DEFAULT '',
+  exit_code INTEGER,
+  PRIMARY KEY (post_id, position)
+);
+CREATE INDEX IF NOT EXISTS idx_forum_post_attachments_thread ON forum_post_attachments(thread_id, post_id, position);
+`
+
+// migration0009ForumSearchIndex adds the forum_search_documents projection
+// (thread titles, post bodies, and the text fields of control payloads) and
+// an external-content FTS5 index over it kept in sync by triggers. Existing
+// threads and posts are backfilled.
+const migration0009ForumSearchIndex = `
+CREATE TABLE IF NOT EXISTS forum_search_documents (
+  doc_rowid INTEGER PRIMARY KEY,
+  doc_id TEXT NOT NULL UNIQUE,
+  thread_id TEXT NOT NULL,
+  ticket TEXT NOT NULL,
+  run_id TEXT NOT NULL DEFAULT '',
+  doc_kind TEXT NOT NULL,
+  actor_type TEXT NOT NULL DEFAULT '',
+  actor_name TEXT NOT NULL DEFAULT '',
+  title TEXT NOT NULL DEFAULT '',
+  body TEXT NOT NULL DEFAULT '',
+  created_at TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_forum_search_documents_thread ON forum_search_documents(thread_id);
+CREATE INDEX IF NOT EXISTS idx_forum_search_documents_ticket ON forum_search_documents(ticket);
+CREATE INDEX IF NOT EXISTS idx_forum_search_documents_actor ON forum_search_documents(actor_type, actor_name);
+CREATE VIRTUAL TABLE IF NOT EXISTS forum_search_index USING fts5(
+  title,
+  body,
+  content='forum_search_documents',
+  content_rowid='doc_rowid',
+  tokenize='unicode61 remove_diacritics 2'
+);
+CREATE TRIGGER IF NOT EXISTS forum_search_documents_ai AFTER INSERT ON forum_search_documents BEGIN
+  INSERT INTO forum_search_index(rowid, title, body) VALUES (new.doc_rowid, new.title, new.body);
+END;
+CREATE TRIGGER IF NOT EXISTS forum_search_documents_ad AFTER DELETE ON forum_search_documents BEGIN
+  INSERT INTO forum_search_index(forum_search_index, rowid, title, body) VALUES ('delete', old.doc_rowid, old.title, old.body);
+END;
+CREATE TRIGGER IF NOT EXISTS forum_search_documents_au AFTER UPDATE ON forum_search_documents BEGIN
+  INSERT INTO forum_search_index(forum_search_index, rowid, title, body) VALUES ('delete', old.doc_rowid, old.title, old.body);
+  INSERT INTO forum_search_index(rowid, title, body) VALUES (new.doc_rowid, new.title, new.body);
+END;
+INSERT OR IGNORE INTO forum_search_documents (doc_id, thread_id, ticket, run_id, doc_kind, actor_type, actor_name, title, body, created_at)
+SELECT 'thread:' || thread_id, thread_id, ticket, run_id, 'title', opened_by_type, opened_by_name, title, '', opened_at
+FROM forum_threads;
+INSERT OR IGNORE INTO forum_search_documents (doc_id, thread_id, ticket, run_id, doc_kind, actor_type, actor_name, title, body, created_at)
+SELECT
+  'post:' || p.post_id,
+  p.thread_id,
+  t.ticket,
+  t.run_id,
+  CASE WHEN json_valid(p.body_text) AND json_extract(p.body_text, '$.control_type') IS NOT NULL THEN 'control' ELSE 'post' END,
+  p.author_type,
+  p.author_name,
+  '',
+  CASE WHEN json_valid(p.body_text) AND json_extract(p.body_text, '$.control_type') IS NOT NULL THEN
+    trim(
+      COALESCE(json_extract(p.body_text, '$.question'), '') || char(10) ||
+      COALESCE(json_extract(p.body_text, '$.context'), '') || char(10) ||
+      COALESCE(json_extract(p.body_text, '$.answer'), '') || char(10) ||
+      COALESCE(json_extract(p.body_text, '$.summary'), '') || char(10) ||
+      COALESCE(json_extract(p.body_text, '$.done_criteria'), ''),
+      char(10)
+    )
+  ELSE p.body_text END,
+  p.created_at
+FROM forum_posts p
+JOIN forum_threads t ON t.thread_id = p.thread_id;
+`
+
+// migration0010GuidanceAnswers adds the guidance answer library: question and
+// answer pairs harvested from control threads plus canned ticket- and
+// repo-scoped answers. question_key is the normalized question used for exact
+// matches.
+const migration0010GuidanceAnswers = `
+CREATE TABLE IF NOT EXISTS guidance_answers (
+  answer_id TEXT PRIMARY KEY,
+  scope_type TEXT NOT NULL,
+  scope TEXT NOT NULL DEFAULT '',
+  question TEXT NOT NULL,
+  question_key TEXT NOT NULL,
+  context TEXT NOT NULL DEFAULT '',
+  answer TEXT NOT NULL,
+  ticket TEXT NOT NULL DEFAULT '',
+  run_id TEXT NOT NULL DEFAULT '',
+  agent_name TEXT NOT NULL DEFAULT '',
+  thread_id TEXT NOT NULL DEFAULT '',
+  source_event_id TEXT NOT NULL DEFAULT '',
+  promoted_from TEXT NOT NULL DEFAULT '',
+  created_by TEXT NOT NULL DEFAULT '',
+  use_count INTEGER NOT NULL DEFAULT 0,
+  created_at TEXT NOT NULL,
+  updated_at TEXT NOT NULL,
+  last_used_at TEXT NOT NULL DEFAULT ''
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_guidance_answers_source_event ON guidance_answers(source_event_id) WHERE source_event_id <> '';
+CREATE INDEX IF NOT EXISTS idx_guidance_answers_scope ON guidance_answers(scope_type, scope, question_key);
+CREATE INDEX IF NOT EXISTS idx_guidance_answers_ticket ON guidance_answers(ticket);
+`
+
+// migration0011OperatorSupervision moves the operator's per-run memory into
+// operator_run_states and adds operator_leases, which lets only one operator
+// instance act on a run at a time.
+const migration0011OperatorSupervision = `
+ALTER TABLE operator_run_states ADD COLUMN unhealthy_intervals INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE operator_run_states ADD COLUMN last_event TEXT NOT NULL DEFAULT '';
+CREATE TABLE IF NOT EXISTS operator_leases (
+  run_id TEXT PRIMARY KEY,
+  holder TEXT NOT NULL,
+  acquired_at TEXT NOT NULL,
+  expires_at TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_events_run_entity ON events(run_id, entity_type, id);
+`
+
+// migration0012OperatorDecisions adds the operator decision audit log: the
+// inputs, rule result, LLM exchange and outcome of each decision.
+const migration0012OperatorDecisions = `
+CREATE TABLE IF NOT EXISTS operator_decisions (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  run_id TEXT NOT NULL,
+  holder TEXT NOT NULL DEFAULT '',
+  llm_mode TEXT NOT NULL DEFAULT '',
+  dry_run INTEGER NOT NULL DEFAULT 0,
+  inputs_json TEXT NOT NULL,
+  rule_intent TEXT NOT NULL,
+  rule_reason TEXT NOT NULL DEFAULT '',
+  rule_execute INTEGER NOT NULL DEFAULT 0,
+  llm_request_json TEXT NOT NULL DEFAULT '',
+  llm_reply_json TEXT NOT NULL DEFAULT '',
+  llm_error TEXT NOT NULL DEFAULT '',
+  intent TEXT NOT NULL,
+  reason TEXT NOT NULL DEFAULT '',
+  source TEXT NOT NULL DEFAULT '',
+  execute INTEGER NOT NULL DEFAULT 0,
+  event TEXT NOT NULL DEFAULT '',
+  executed INTEGER NOT NULL DEFAULT 0,
+  action_error TEXT NOT NULL DEFAULT '',
+  created_at TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_operator_decisions_run ON operator_decisions(run_id, id);
+`
+
+// migration0013OperatorRuleFirings stores when each policy operator rule last
+// decided for a run, which rule rate limits count against.
+const migration0013OperatorRuleFirings = `
+ALTER TABLE operator_run_states ADD COLUMN rule_firings_json TEXT NOT NULL DEFAULT '';
+`
+
+// migration0014IntegrationRuleReceipts records each integration rule applied
+// for a delivery, so retries resume after the last rule that succeeded.
+const migration0014IntegrationRuleReceipts = `
+CREATE TABLE IF NOT EXISTS integration_rule_receipts (
+  event_id TEXT NOT NULL,
+  rule TEXT NOT NULL,
+  action TEXT NOT NULL,
+  thread_id TEXT NOT NULL DEFAULT '',
+  state TEXT NOT NULL DEFAULT '',
+  applied_at TEXT NOT NULL,
+  PRIMARY KEY (event_id, rule)
+);
+`
+
+// migration0015OperatorDecisionRepeats flags audited decisions that repeated
+// the previous pass's outcome, so every pass can be audited while history
+// still lists changes quickly.
+const migration0015OperatorDecisionRepeats = `
+ALTER TABLE operator_decisions ADD COLUMN repeated INTEGER NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_operator_decisions_run_repeated ON operator_decisions(run_id, repeated, id);
+`
+
+// migration0016NotifierCursors stores how far each notifier has read the
+// events table, so a restarted daemon resumes where it stopped.
+const migration0016NotifierCursors = `
+CREATE TABLE IF NOT EXISTS notifier_cursors (
+  name TEXT PRIMARY KEY,
+  position INTEGER NOT NULL,
+  updated_at TEXT NOT NULL
+);
+`
+
+// migration0017OperatorDecisionRepeatCounts folds repeated operator passes
+// into the decision they repeat: a repeat bumps that row's count and last
+// seen time instead of adding a row. Rows written as repeats under migration
+// 15 are dropped; the repeated column is no longer written.
+const migration0017OperatorDecisionRepeatCounts = `
+DROP INDEX IF EXISTS idx_operator_decisions_run_repeated;
+DELETE FROM operator_decisions WHERE repeated=1;
+ALTER TABLE operator_decisions ADD COLUMN repeated_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE operator_decisions ADD COLUMN last_seen_at TEXT NOT NULL DEFAULT '';
+UPDATE operator_decisions SET last_seen_at=created_at;
+`
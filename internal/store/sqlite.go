@@ -1,26 +1,39 @@
 package store
 
 import (
-	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
-	"os/exec"
-	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"metawsm/internal/model"
 )
 
+// ErrRunNotFound is returned by lookups for a run id that is not stored.
+var ErrRunNotFound = errors.New("run not found")
+
 type SQLiteStore struct {
 	DBPath             string
+	Backend            string
 	SQLitePath         string
 	BusyTimeoutMS      int
 	BusyRetryCount     int
 	BusyRetryBackoffMS int
 
+	// Outbox retry settings: failed messages are retried with exponential
+	// backoff until OutboxMaxAttempts, then moved to dead_letter. Claims hold
+	// a lease of OutboxLeaseMS before another worker may reclaim them.
+	OutboxMaxAttempts   int
+	OutboxBackoffBaseMS int
+	OutboxBackoffMaxMS  int
+	OutboxLeaseMS       int
+
+	backendMu   sync.Mutex
+	backendImpl sqliteBackend
+
 	retryObserver func(operation string, attempt int, err error)
 }
 
@@ -30,322 +43,22 @@ func NewSQLiteStore(dbPath string) *SQLiteStore {
 	}
 	return &SQLiteStore{
 		DBPath:             dbPath,
+		Backend:            BackendDriver,
 		SQLitePath:         "sqlite3",
 		BusyTimeoutMS:      1500,
 		BusyRetryCount:     6,
 		BusyRetryBackoffMS: 100,
+
+		OutboxMaxAttempts:   8,
+		OutboxBackoffBaseMS: 2000,
+		OutboxBackoffMaxMS:  300000,
+		OutboxLeaseMS:       300000,
 	}
 }
 
 func (s *SQLiteStore) Init() error {
-	if err := os.MkdirAll(filepath.Dir(s.DBPath), 0o755); err != nil {
-		return fmt.Errorf("create db dir: %w", err)
-	}
-
-	schema := `
-PRAGMA journal_mode=WAL;
-CREATE TABLE IF NOT EXISTS schema_migrations (
-  version INTEGER PRIMARY KEY,
-  applied_at TEXT NOT NULL
-);
-CREATE TABLE IF NOT EXISTS runs (
-  run_id TEXT PRIMARY KEY,
-  status TEXT NOT NULL,
-  created_at TEXT NOT NULL,
-  updated_at TEXT NOT NULL,
-  spec_json TEXT NOT NULL,
-  policy_json TEXT NOT NULL,
-  error_text TEXT NOT NULL DEFAULT ''
-);
-CREATE TABLE IF NOT EXISTS run_tickets (
-  run_id TEXT NOT NULL,
-  ticket TEXT NOT NULL,
-  PRIMARY KEY (run_id, ticket)
-);
-CREATE TABLE IF NOT EXISTS steps (
-  run_id TEXT NOT NULL,
-  step_index INTEGER NOT NULL,
-  name TEXT NOT NULL,
-  kind TEXT NOT NULL,
-  command_text TEXT NOT NULL,
-  blocking INTEGER NOT NULL,
-  ticket TEXT NOT NULL DEFAULT '',
-  workspace_name TEXT NOT NULL DEFAULT '',
-  agent_name TEXT NOT NULL DEFAULT '',
-  status TEXT NOT NULL,
-  error_text TEXT NOT NULL DEFAULT '',
-  started_at TEXT NOT NULL DEFAULT '',
-  finished_at TEXT NOT NULL DEFAULT '',
-  PRIMARY KEY (run_id, step_index)
-);
-CREATE TABLE IF NOT EXISTS agents (
-  run_id TEXT NOT NULL,
-  agent_name TEXT NOT NULL,
-  workspace_name TEXT NOT NULL,
-  session_name TEXT NOT NULL,
-  status TEXT NOT NULL,
-  health_state TEXT NOT NULL,
-  last_activity_at TEXT NOT NULL DEFAULT '',
-  last_progress_at TEXT NOT NULL DEFAULT '',
-  PRIMARY KEY (run_id, agent_name, workspace_name)
-);
-CREATE TABLE IF NOT EXISTS events (
-  id INTEGER PRIMARY KEY AUTOINCREMENT,
-  run_id TEXT NOT NULL,
-  entity_type TEXT NOT NULL,
-  entity_id TEXT NOT NULL,
-  event_type TEXT NOT NULL,
-  from_state TEXT NOT NULL DEFAULT '',
-  to_state TEXT NOT NULL DEFAULT '',
-  message TEXT NOT NULL DEFAULT '',
-  created_at TEXT NOT NULL
-);
-CREATE TABLE IF NOT EXISTS run_briefs (
-  run_id TEXT PRIMARY KEY,
-  ticket TEXT NOT NULL,
-  goal TEXT NOT NULL,
-  scope TEXT NOT NULL,
-  done_criteria TEXT NOT NULL,
-  constraints_text TEXT NOT NULL,
-  merge_intent TEXT NOT NULL,
-  qa_json TEXT NOT NULL,
-  created_at TEXT NOT NULL,
-  updated_at TEXT NOT NULL
-);
-CREATE TABLE IF NOT EXISTS guidance_requests (
-  id INTEGER PRIMARY KEY AUTOINCREMENT,
-  run_id TEXT NOT NULL,
-  workspace_name TEXT NOT NULL,
-  agent_name TEXT NOT NULL,
-  question TEXT NOT NULL,
-  context_text TEXT NOT NULL DEFAULT '',
-  answer_text TEXT NOT NULL DEFAULT '',
-  status TEXT NOT NULL,
-  created_at TEXT NOT NULL,
-  answered_at TEXT NOT NULL DEFAULT ''
-);
-CREATE TABLE IF NOT EXISTS forum_threads (
-  thread_id TEXT PRIMARY KEY,
-  ticket TEXT NOT NULL,
-  run_id TEXT NOT NULL DEFAULT '',
-  agent_name TEXT NOT NULL DEFAULT '',
-  title TEXT NOT NULL,
-  state TEXT NOT NULL,
-  priority TEXT NOT NULL,
-  assignee_type TEXT NOT NULL DEFAULT '',
-  assignee_name TEXT NOT NULL DEFAULT '',
-  opened_by_type TEXT NOT NULL,
-  opened_by_name TEXT NOT NULL DEFAULT '',
-  opened_at TEXT NOT NULL,
-  updated_at TEXT NOT NULL,
-  closed_at TEXT NOT NULL DEFAULT ''
-);
-CREATE INDEX IF NOT EXISTS idx_forum_threads_ticket_state_priority
-  ON forum_threads (ticket, state, priority);
-CREATE TABLE IF NOT EXISTS forum_posts (
-  post_id TEXT PRIMARY KEY,
-  thread_id TEXT NOT NULL,
-  event_id TEXT NOT NULL UNIQUE,
-  author_type TEXT NOT NULL,
-  author_name TEXT NOT NULL DEFAULT '',
-  body_text TEXT NOT NULL,
-  created_at TEXT NOT NULL
-);
-CREATE INDEX IF NOT EXISTS idx_forum_posts_thread_created
-  ON forum_posts (thread_id, created_at);
-CREATE TABLE IF NOT EXISTS forum_assignments (
-  id INTEGER PRIMARY KEY AUTOINCREMENT,
-  thread_id TEXT NOT NULL,
-  event_id TEXT NOT NULL UNIQUE,
-  from_assignee_type TEXT NOT NULL DEFAULT '',
-  from_assignee_name TEXT NOT NULL DEFAULT '',
-  to_assignee_type TEXT NOT NULL DEFAULT '',
-  to_assignee_name TEXT NOT NULL DEFAULT '',
-  changed_by_type TEXT NOT NULL,
-  changed_by_name TEXT NOT NULL DEFAULT '',
-  changed_at TEXT NOT NULL
-);
-CREATE TABLE IF NOT EXISTS forum_state_transitions (
-  id INTEGER PRIMARY KEY AUTOINCREMENT,
-  thread_id TEXT NOT NULL,
-  event_id TEXT NOT NULL UNIQUE,
-  from_state TEXT NOT NULL DEFAULT '',
-  to_state TEXT NOT NULL,
-  changed_by_type TEXT NOT NULL,
-  changed_by_name TEXT NOT NULL DEFAULT '',
-  changed_at TEXT NOT NULL
-);
-CREATE TABLE IF NOT EXISTS forum_events (
-  sequence INTEGER PRIMARY KEY AUTOINCREMENT,
-  event_id TEXT NOT NULL UNIQUE,
-  event_type TEXT NOT NULL,
-  event_version INTEGER NOT NULL,
-  occurred_at TEXT NOT NULL,
-  thread_id TEXT NOT NULL,
-  run_id TEXT NOT NULL DEFAULT '',
-  ticket TEXT NOT NULL,
-  agent_name TEXT NOT NULL DEFAULT '',
-  actor_type TEXT NOT NULL,
-  actor_name TEXT NOT NULL DEFAULT '',
-  correlation_id TEXT NOT NULL DEFAULT '',
-  causation_id TEXT NOT NULL DEFAULT '',
-  payload_json TEXT NOT NULL DEFAULT ''
-);
-CREATE INDEX IF NOT EXISTS idx_forum_events_ticket_sequence
-  ON forum_events (ticket, sequence);
-CREATE TABLE IF NOT EXISTS forum_thread_views (
-  thread_id TEXT PRIMARY KEY,
-  ticket TEXT NOT NULL,
-  run_id TEXT NOT NULL DEFAULT '',
-  agent_name TEXT NOT NULL DEFAULT '',
-  title TEXT NOT NULL,
-  state TEXT NOT NULL,
-  priority TEXT NOT NULL,
-  assignee_type TEXT NOT NULL DEFAULT '',
-  assignee_name TEXT NOT NULL DEFAULT '',
-  opened_by_type TEXT NOT NULL,
-  opened_by_name TEXT NOT NULL DEFAULT '',
-  posts_count INTEGER NOT NULL DEFAULT 0,
-  last_post_at TEXT NOT NULL DEFAULT '',
-  last_post_by_type TEXT NOT NULL DEFAULT '',
-  last_post_by_name TEXT NOT NULL DEFAULT '',
-  opened_at TEXT NOT NULL,
-  updated_at TEXT NOT NULL,
-  closed_at TEXT NOT NULL DEFAULT ''
-);
-CREATE INDEX IF NOT EXISTS idx_forum_thread_views_ticket_filters
-  ON forum_thread_views (ticket, state, priority, updated_at);
-CREATE TABLE IF NOT EXISTS forum_thread_reads (
-  thread_id TEXT NOT NULL,
-  viewer_type TEXT NOT NULL,
-  viewer_id TEXT NOT NULL,
-  last_seen_event_sequence INTEGER NOT NULL DEFAULT 0,
-  updated_at TEXT NOT NULL,
-  PRIMARY KEY (thread_id, viewer_type, viewer_id)
-);
-CREATE INDEX IF NOT EXISTS idx_forum_thread_reads_viewer
-  ON forum_thread_reads (viewer_type, viewer_id, updated_at);
-CREATE TABLE IF NOT EXISTS forum_thread_queue_view (
-  thread_id TEXT PRIMARY KEY,
-  ticket TEXT NOT NULL,
-  run_id TEXT NOT NULL DEFAULT '',
-  state TEXT NOT NULL,
-  priority TEXT NOT NULL,
-  assignee_name TEXT NOT NULL DEFAULT '',
-  last_event_sequence INTEGER NOT NULL DEFAULT 0,
-  last_actor_type TEXT NOT NULL DEFAULT '',
-  last_non_system_actor_type TEXT NOT NULL DEFAULT '',
-  last_human_or_operator_sequence INTEGER NOT NULL DEFAULT 0,
-  last_agent_sequence INTEGER NOT NULL DEFAULT 0,
-  updated_at TEXT NOT NULL
-);
-CREATE INDEX IF NOT EXISTS idx_forum_thread_queue_view_filters
-  ON forum_thread_queue_view (ticket, run_id, state, priority, last_event_sequence DESC);
-CREATE TABLE IF NOT EXISTS forum_thread_stats (
-  ticket TEXT NOT NULL,
-  run_id TEXT NOT NULL DEFAULT '',
-  state TEXT NOT NULL,
-  priority TEXT NOT NULL,
-  thread_count INTEGER NOT NULL DEFAULT 0,
-  updated_at TEXT NOT NULL,
-  PRIMARY KEY (ticket, run_id, state, priority)
-);
-CREATE TABLE IF NOT EXISTS forum_projection_events (
-  projection_name TEXT NOT NULL,
-  event_id TEXT NOT NULL,
-  applied_at TEXT NOT NULL,
-  PRIMARY KEY (projection_name, event_id)
-);
-CREATE TABLE IF NOT EXISTS forum_control_threads (
-  run_id TEXT NOT NULL,
-  agent_name TEXT NOT NULL,
-  ticket TEXT NOT NULL,
-  thread_id TEXT NOT NULL UNIQUE,
-  created_at TEXT NOT NULL,
-  updated_at TEXT NOT NULL,
-  PRIMARY KEY (run_id, agent_name)
-);
-CREATE TABLE IF NOT EXISTS forum_outbox (
-  id INTEGER PRIMARY KEY AUTOINCREMENT,
-  message_id TEXT NOT NULL UNIQUE,
-  topic TEXT NOT NULL,
-  message_key TEXT NOT NULL DEFAULT '',
-  payload_json TEXT NOT NULL,
-  status TEXT NOT NULL,
-  attempt_count INTEGER NOT NULL DEFAULT 0,
-  last_error TEXT NOT NULL DEFAULT '',
-  created_at TEXT NOT NULL,
-  updated_at TEXT NOT NULL,
-  sent_at TEXT NOT NULL DEFAULT ''
-);
-CREATE INDEX IF NOT EXISTS idx_forum_outbox_status_created
-  ON forum_outbox (status, created_at, id);
-CREATE TABLE IF NOT EXISTS doc_sync_states (
-  run_id TEXT NOT NULL,
-  ticket TEXT NOT NULL,
-  workspace_name TEXT NOT NULL,
-  doc_home_repo TEXT NOT NULL DEFAULT '',
-  doc_authority_mode TEXT NOT NULL DEFAULT '',
-  doc_seed_mode TEXT NOT NULL DEFAULT '',
-  status TEXT NOT NULL,
-  revision TEXT NOT NULL DEFAULT '',
-  error_text TEXT NOT NULL DEFAULT '',
-  updated_at TEXT NOT NULL,
-  PRIMARY KEY (run_id, ticket, workspace_name)
-);
-CREATE TABLE IF NOT EXISTS operator_run_states (
-  run_id TEXT PRIMARY KEY,
-  restart_attempts INTEGER NOT NULL DEFAULT 0,
-  last_restart_at TEXT NOT NULL DEFAULT '',
-  cooldown_until TEXT NOT NULL DEFAULT '',
-  updated_at TEXT NOT NULL
-);
-CREATE TABLE IF NOT EXISTS run_pull_requests (
-  run_id TEXT NOT NULL,
-  ticket TEXT NOT NULL,
-  repo TEXT NOT NULL,
-  workspace_name TEXT NOT NULL DEFAULT '',
-  head_branch TEXT NOT NULL DEFAULT '',
-  base_branch TEXT NOT NULL DEFAULT '',
-  remote_name TEXT NOT NULL DEFAULT '',
-  commit_sha TEXT NOT NULL DEFAULT '',
-  pr_number INTEGER NOT NULL DEFAULT 0,
-  pr_url TEXT NOT NULL DEFAULT '',
-  pr_state TEXT NOT NULL DEFAULT '',
-  credential_mode TEXT NOT NULL DEFAULT '',
-  actor TEXT NOT NULL DEFAULT '',
-  validation_json TEXT NOT NULL DEFAULT '',
-  error_text TEXT NOT NULL DEFAULT '',
-  created_at TEXT NOT NULL,
-  updated_at TEXT NOT NULL,
-  PRIMARY KEY (run_id, ticket, repo)
-);
-CREATE TABLE IF NOT EXISTS run_review_feedback (
-  run_id TEXT NOT NULL,
-  ticket TEXT NOT NULL,
-  repo TEXT NOT NULL,
-  workspace_name TEXT NOT NULL DEFAULT '',
-  pr_number INTEGER NOT NULL DEFAULT 0,
-  pr_url TEXT NOT NULL DEFAULT '',
-  source_type TEXT NOT NULL,
-  source_id TEXT NOT NULL,
-  source_url TEXT NOT NULL DEFAULT '',
-  author TEXT NOT NULL DEFAULT '',
-  body_text TEXT NOT NULL DEFAULT '',
-  file_path TEXT NOT NULL DEFAULT '',
-  line_number INTEGER NOT NULL DEFAULT 0,
-  status TEXT NOT NULL DEFAULT '',
-  error_text TEXT NOT NULL DEFAULT '',
-  created_at TEXT NOT NULL,
-  updated_at TEXT NOT NULL,
-  last_seen_at TEXT NOT NULL,
-  addressed_at TEXT NOT NULL DEFAULT '',
-  PRIMARY KEY (run_id, ticket, repo, pr_number, source_type, source_id)
-);
-CREATE INDEX IF NOT EXISTS idx_run_review_feedback_run_status
-  ON run_review_feedback (run_id, status);`
-
-	return s.execSQL(schema)
+	_, err := s.Migrate(MigrateOptions{})
+	return err
 }
 
 func (s *SQLiteStore) CreateRun(spec model.RunSpec, policyJSON string) error {
@@ -354,28 +67,27 @@ func (s *SQLiteStore) CreateRun(spec model.RunSpec, policyJSON string) error {
 		return fmt.Errorf("marshal run spec: %w", err)
 	}
 	now := time.Now().Format(time.RFC3339)
-	sql := fmt.Sprintf(
+	if err := s.execSQL(
 		`INSERT INTO runs (run_id, status, created_at, updated_at, spec_json, policy_json, error_text)
-VALUES (%s, %s, %s, %s, %s, %s, '');`,
-		quote(spec.RunID), quote(string(model.RunStatusCreated)), quote(now), quote(now), quote(string(specBytes)), quote(policyJSON),
-	)
-	if err := s.execSQL(sql); err != nil {
+VALUES (?, ?, ?, ?, ?, ?, '');`,
+		spec.RunID,
+		string(model.RunStatusCreated),
+		now,
+		now,
+		string(specBytes),
+		policyJSON,
+	); err != nil {
 		return err
 	}
 
-	var ticketSQL strings.Builder
+	statements := make([]sqlStatement, 0, len(spec.Tickets))
 	for _, ticket := range spec.Tickets {
-		ticketSQL.WriteString(fmt.Sprintf(
-			"INSERT OR IGNORE INTO run_tickets (run_id, ticket) VALUES (%s, %s);\n",
-			quote(spec.RunID), quote(ticket),
+		statements = append(statements, statement(
+			`INSERT OR IGNORE INTO run_tickets (run_id, ticket) VALUES (?, ?);`,
+			spec.RunID, ticket,
 		))
 	}
-	if ticketSQL.Len() > 0 {
-		if err := s.execSQL(ticketSQL.String()); err != nil {
-			return err
-		}
-	}
-	return nil
+	return s.execTx(statements...)
 }
 
 func (s *SQLiteStore) UpsertRunBrief(brief model.RunBrief) error {
@@ -392,32 +104,30 @@ func (s *SQLiteStore) UpsertRunBrief(brief model.RunBrief) error {
 	if updatedAt.IsZero() {
 		updatedAt = now
 	}
-	sql := fmt.Sprintf(
+	return s.execSQL(
 		`INSERT OR REPLACE INTO run_briefs
   (run_id, ticket, goal, scope, done_criteria, constraints_text, merge_intent, qa_json, created_at, updated_at)
 VALUES
-  (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s);`,
-		quote(brief.RunID),
-		quote(brief.Ticket),
-		quote(brief.Goal),
-		quote(brief.Scope),
-		quote(brief.DoneCriteria),
-		quote(brief.Constraints),
-		quote(brief.MergeIntent),
-		quote(string(qaJSON)),
-		quote(createdAt.Format(time.RFC3339)),
-		quote(updatedAt.Format(time.RFC3339)),
+  (?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`,
+		brief.RunID,
+		brief.Ticket,
+		brief.Goal,
+		brief.Scope,
+		brief.DoneCriteria,
+		brief.Constraints,
+		brief.MergeIntent,
+		string(qaJSON),
+		createdAt.Format(time.RFC3339),
+		updatedAt.Format(time.RFC3339),
 	)
-	return s.execSQL(sql)
 }
 
 func (s *SQLiteStore) GetRunBrief(runID string) (*model.RunBrief, error) {
-	sql := fmt.Sprintf(
+	rows, err := s.queryJSON(
 		`SELECT run_id, ticket, goal, scope, done_criteria, constraints_text, merge_intent, qa_json, created_at, updated_at
-FROM run_briefs WHERE run_id=%s;`,
-		quote(runID),
+FROM run_briefs WHERE run_id=?;`,
+		runID,
 	)
-	rows, err := s.queryJSON(sql)
 	if err != nil {
 		return nil, err
 	}
@@ -465,30 +175,29 @@ func (s *SQLiteStore) AddGuidanceRequest(req model.GuidanceRequest) (int64, erro
 	if status == "" {
 		status = model.GuidanceStatusPending
 	}
-	sql := fmt.Sprintf(
+	if err := s.execSQL(
 		`INSERT INTO guidance_requests
   (run_id, workspace_name, agent_name, question, context_text, answer_text, status, created_at, answered_at)
 VALUES
-  (%s, %s, %s, %s, %s, %s, %s, %s, '');`,
-		quote(req.RunID),
-		quote(req.WorkspaceName),
-		quote(req.AgentName),
-		quote(req.Question),
-		quote(req.Context),
-		quote(req.Answer),
-		quote(string(status)),
-		quote(createdAt.Format(time.RFC3339)),
-	)
-	if err := s.execSQL(sql); err != nil {
+  (?, ?, ?, ?, ?, ?, ?, ?, '');`,
+		req.RunID,
+		req.WorkspaceName,
+		req.AgentName,
+		req.Question,
+		req.Context,
+		req.Answer,
+		string(status),
+		createdAt.Format(time.RFC3339),
+	); err != nil {
 		return 0, err
 	}
-	idRows, err := s.queryJSON(fmt.Sprintf(
+	idRows, err := s.queryJSON(
 		`SELECT id FROM guidance_requests
-WHERE run_id=%s
+WHERE run_id=?
 ORDER BY id DESC
 LIMIT 1;`,
-		quote(req.RunID),
-	))
+		req.RunID,
+	)
 	if err != nil {
 		return 0, err
 	}
@@ -499,18 +208,15 @@ LIMIT 1;`,
 }
 
 func (s *SQLiteStore) ListGuidanceRequests(runID string, status model.GuidanceStatus) ([]model.GuidanceRequest, error) {
-	clauses := []string{fmt.Sprintf("run_id=%s", quote(runID))}
-	if strings.TrimSpace(string(status)) != "" {
-		clauses = append(clauses, fmt.Sprintf("status=%s", quote(string(status))))
-	}
-	sql := fmt.Sprintf(
+	rows, err := s.queryJSON(
 		`SELECT id, run_id, workspace_name, agent_name, question, context_text, answer_text, status, created_at, answered_at
 FROM guidance_requests
-WHERE %s
+WHERE run_id=? AND (?='' OR status=?)
 ORDER BY id;`,
-		strings.Join(clauses, " AND "),
+		runID,
+		strings.TrimSpace(string(status)),
+		string(status),
 	)
-	rows, err := s.queryJSON(sql)
 	if err != nil {
 		return nil, err
 	}
@@ -539,75 +245,80 @@ ORDER BY id;`,
 
 func (s *SQLiteStore) MarkGuidanceAnswered(id int64, answer string) error {
 	now := time.Now().Format(time.RFC3339)
-	sql := fmt.Sprintf(
+	return s.execSQL(
 		`UPDATE guidance_requests
-SET status=%s, answer_text=%s, answered_at=%s
-WHERE id=%d;`,
-		quote(string(model.GuidanceStatusAnswered)),
-		quote(answer),
-		quote(now),
+SET status=?, answer_text=?, answered_at=?
+WHERE id=?;`,
+		string(model.GuidanceStatusAnswered),
+		answer,
+		now,
 		id,
 	)
-	return s.execSQL(sql)
 }
 
 func (s *SQLiteStore) SaveSteps(runID string, steps []model.PlanStep) error {
-	var b strings.Builder
+	statements := make([]sqlStatement, 0, len(steps))
 	for _, step := range steps {
 		blocking := 0
 		if step.Blocking {
 			blocking = 1
 		}
-		b.WriteString(fmt.Sprintf(
+		dependsOnJSON := ""
+		if len(step.DependsOn) > 0 {
+			encoded, err := json.Marshal(step.DependsOn)
+			if err != nil {
+				return fmt.Errorf("marshal step %d depends_on: %w", step.Index, err)
+			}
+			dependsOnJSON = string(encoded)
+		}
+		statements = append(statements, statement(
 			`INSERT OR REPLACE INTO steps
-  (run_id, step_index, name, kind, command_text, blocking, ticket, workspace_name, agent_name, status, error_text, started_at, finished_at)
+  (run_id, step_index, name, kind, command_text, blocking, ticket, workspace_name, agent_name, depends_on_json, status, error_text, started_at, finished_at)
 VALUES
-  (%s, %d, %s, %s, %s, %d, %s, %s, %s, %s, '', '', '');
-`,
-			quote(runID),
+  (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, '', '', '');`,
+			runID,
 			step.Index,
-			quote(step.Name),
-			quote(step.Kind),
-			quote(step.Command),
+			step.Name,
+			step.Kind,
+			step.Command,
 			blocking,
-			quote(step.Ticket),
-			quote(step.WorkspaceName),
-			quote(step.Agent),
-			quote(string(step.Status)),
+			step.Ticket,
+			step.WorkspaceName,
+			step.Agent,
+			dependsOnJSON,
+			string(step.Status),
 		))
 	}
-	if b.Len() == 0 {
-		return nil
-	}
-	return s.execSQL(b.String())
+	return s.execTx(statements...)
 }
 
 func (s *SQLiteStore) UpsertAgent(agent model.AgentRecord) error {
-	sql := fmt.Sprintf(
+	return s.execSQL(
 		`INSERT OR REPLACE INTO agents
   (run_id, agent_name, workspace_name, session_name, status, health_state, last_activity_at, last_progress_at)
 VALUES
-  (%s, %s, %s, %s, %s, %s, %s, %s);`,
-		quote(agent.RunID),
-		quote(agent.Name),
-		quote(agent.WorkspaceName),
-		quote(agent.SessionName),
-		quote(string(agent.Status)),
-		quote(string(agent.HealthState)),
-		quote(formatTime(agent.LastActivityAt)),
-		quote(formatTime(agent.LastProgressAt)),
+  (?, ?, ?, ?, ?, ?, ?, ?);`,
+		agent.RunID,
+		agent.Name,
+		agent.WorkspaceName,
+		agent.SessionName,
+		string(agent.Status),
+		string(agent.HealthState),
+		formatTime(agent.LastActivityAt),
+		formatTime(agent.LastProgressAt),
 	)
-	return s.execSQL(sql)
 }
 
 func (s *SQLiteStore) UpdateRunStatus(runID string, status model.RunStatus, errorText string) error {
-	sql := fmt.Sprintf(
+	return s.execSQL(
 		`UPDATE runs
-SET status=%s, updated_at=%s, error_text=%s
-WHERE run_id=%s;`,
-		quote(string(status)), quote(time.Now().Format(time.RFC3339)), quote(errorText), quote(runID),
+SET status=?, updated_at=?, error_text=?
+WHERE run_id=?;`,
+		string(status),
+		time.Now().Format(time.RFC3339),
+		errorText,
+		runID,
 	)
-	return s.execSQL(sql)
 }
 
 func (s *SQLiteStore) UpdateStepStatus(runID string, stepIndex int, status model.StepStatus, errorText string, markStarted bool, markFinished bool) error {
@@ -619,53 +330,162 @@ func (s *SQLiteStore) UpdateStepStatus(runID string, stepIndex int, status model
 	if markFinished {
 		finished = time.Now().Format(time.RFC3339)
 	}
-	sql := fmt.Sprintf(
+	return s.execSQL(
 		`UPDATE steps
-SET status=%s,
-    error_text=%s,
-    started_at=CASE WHEN %s != '' THEN %s ELSE started_at END,
-    finished_at=CASE WHEN %s != '' THEN %s ELSE finished_at END
-WHERE run_id=%s AND step_index=%d;`,
-		quote(string(status)),
-		quote(errorText),
-		quote(started),
-		quote(started),
-		quote(finished),
-		quote(finished),
-		quote(runID),
+SET status=?,
+    error_text=?,
+    started_at=CASE WHEN ? != '' THEN ? ELSE started_at END,
+    finished_at=CASE WHEN ? != '' THEN ? ELSE finished_at END
+WHERE run_id=? AND step_index=?;`,
+		string(status),
+		errorText,
+		started,
+		started,
+		finished,
+		finished,
+		runID,
 		stepIndex,
 	)
-	return s.execSQL(sql)
 }
 
 func (s *SQLiteStore) UpdateAgentStatus(runID string, agentName string, workspaceName string, status model.AgentStatus, health model.HealthState, lastActivity *time.Time, lastProgress *time.Time) error {
-	sql := fmt.Sprintf(
+	return s.execSQL(
 		`UPDATE agents
-SET status=%s,
-    health_state=%s,
-    last_activity_at=%s,
-    last_progress_at=%s
-WHERE run_id=%s AND agent_name=%s AND workspace_name=%s;`,
-		quote(string(status)),
-		quote(string(health)),
-		quote(formatTime(lastActivity)),
-		quote(formatTime(lastProgress)),
-		quote(runID),
-		quote(agentName),
-		quote(workspaceName),
+SET status=?,
+    health_state=?,
+    last_activity_at=?,
+    last_progress_at=?
+WHERE run_id=? AND agent_name=? AND workspace_name=?;`,
+		string(status),
+		string(health),
+		formatTime(lastActivity),
+		formatTime(lastProgress),
+		runID,
+		agentName,
+		workspaceName,
 	)
-	return s.execSQL(sql)
 }
 
 func (s *SQLiteStore) AddEvent(runID, entityType, entityID, eventType, fromState, toState, message string) error {
-	sql := fmt.Sprintf(
+	return s.execSQL(
 		`INSERT INTO events
   (run_id, entity_type, entity_id, event_type, from_state, to_state, message, created_at)
 VALUES
-  (%s, %s, %s, %s, %s, %s, %s, %s);`,
-		quote(runID), quote(entityType), quote(entityID), quote(eventType), quote(fromState), quote(toState), quote(message), quote(time.Now().Format(time.RFC3339)),
+  (?, ?, ?, ?, ?, ?, ?, ?);`,
+		runID, entityType, entityID, eventType, fromState, toState, message, time.Now().Format(time.RFC3339),
+	)
+}
+
+// ListRunEvents returns the newest events for runID first. An empty
+// entityType matches every entity.
+func (s *SQLiteStore) ListRunEvents(runID string, entityType string, limit int) ([]model.RunEvent, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	rows, err := s.queryJSON(
+		`SELECT id, run_id, entity_type, entity_id, event_type, from_state, to_state, message, created_at
+FROM events
+WHERE run_id=? AND (?='' OR entity_type=?)
+ORDER BY id DESC
+LIMIT ?;`,
+		runID, entityType, entityType, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	events := make([]model.RunEvent, 0, len(rows))
+	for _, row := range rows {
+		createdAt, err := time.Parse(time.RFC3339, asString(row["created_at"]))
+		if err != nil {
+			return nil, fmt.Errorf("parse events created_at: %w", err)
+		}
+		events = append(events, model.RunEvent{
+			ID:         int64(asInt(row["id"])),
+			RunID:      asString(row["run_id"]),
+			EntityType: asString(row["entity_type"]),
+			EntityID:   asString(row["entity_id"]),
+			EventType:  asString(row["event_type"]),
+			FromState:  asString(row["from_state"]),
+			ToState:    asString(row["to_state"]),
+			Message:    asString(row["message"]),
+			CreatedAt:  createdAt,
+		})
+	}
+	return events, nil
+}
+
+// ListRunTransitionsAfter returns run status transitions recorded after the
+// event afterID, oldest first.
+func (s *SQLiteStore) ListRunTransitionsAfter(afterID int64, limit int) ([]model.RunEvent, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	rows, err := s.queryJSON(
+		`SELECT id, run_id, entity_type, entity_id, event_type, from_state, to_state, message, created_at
+FROM events
+WHERE entity_type='run' AND event_type='transition' AND id>?
+ORDER BY id ASC
+LIMIT ?;`,
+		afterID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	events := make([]model.RunEvent, 0, len(rows))
+	for _, row := range rows {
+		createdAt, err := time.Parse(time.RFC3339, asString(row["created_at"]))
+		if err != nil {
+			return nil, fmt.Errorf("parse events created_at: %w", err)
+		}
+		events = append(events, model.RunEvent{
+			ID:         int64(asInt(row["id"])),
+			RunID:      asString(row["run_id"]),
+			EntityType: asString(row["entity_type"]),
+			EntityID:   asString(row["entity_id"]),
+			EventType:  asString(row["event_type"]),
+			FromState:  asString(row["from_state"]),
+			ToState:    asString(row["to_state"]),
+			Message:    asString(row["message"]),
+			CreatedAt:  createdAt,
+		})
+	}
+	return events, nil
+}
+
+// LastEventID returns the id of the newest event, or 0 when none exist.
+func (s *SQLiteStore) LastEventID() (int64, error) {
+	rows, err := s.queryJSON(`SELECT COALESCE(MAX(id), 0) AS id FROM events;`)
+	if err != nil {
+		return 0, err
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	return int64(asInt(rows[0]["id"])), nil
+}
+
+// GetNotifierCursor returns the stored position of the named notifier and
+// whether one has been recorded.
+func (s *SQLiteStore) GetNotifierCursor(name string) (int64, bool, error) {
+	rows, err := s.queryJSON(`SELECT position FROM notifier_cursors WHERE name=?;`, name)
+	if err != nil {
+		return 0, false, err
+	}
+	if len(rows) == 0 {
+		return 0, false, nil
+	}
+	return int64(asInt(rows[0]["position"])), true, nil
+}
+
+func (s *SQLiteStore) SetNotifierCursor(name string, position int64) error {
+	return s.execSQL(
+		`INSERT INTO notifier_cursors (name, position, updated_at)
+VALUES (?, ?, ?)
+ON CONFLICT(name) DO UPDATE SET
+  position=excluded.position,
+  updated_at=excluded.updated_at;`,
+		name, position, time.Now().Format(time.RFC3339),
 	)
-	return s.execSQL(sql)
 }
 
 func (s *SQLiteStore) UpsertOperatorRunState(state model.OperatorRunState) error {
@@ -673,28 +493,37 @@ func (s *SQLiteStore) UpsertOperatorRunState(state model.OperatorRunState) error
 	if updatedAt.IsZero() {
 		updatedAt = time.Now()
 	}
-	sql := fmt.Sprintf(
+	ruleFiringsJSON := ""
+	if len(state.RuleFirings) > 0 {
+		encoded, err := json.Marshal(state.RuleFirings)
+		if err != nil {
+			return fmt.Errorf("marshal operator rule firings: %w", err)
+		}
+		ruleFiringsJSON = string(encoded)
+	}
+	return s.execSQL(
 		`INSERT OR REPLACE INTO operator_run_states
-  (run_id, restart_attempts, last_restart_at, cooldown_until, updated_at)
+  (run_id, restart_attempts, last_restart_at, cooldown_until, unhealthy_intervals, last_event, rule_firings_json, updated_at)
 VALUES
-  (%s, %d, %s, %s, %s);`,
-		quote(state.RunID),
+  (?, ?, ?, ?, ?, ?, ?, ?);`,
+		state.RunID,
 		state.RestartAttempts,
-		quote(formatTime(state.LastRestartAt)),
-		quote(formatTime(state.CooldownUntil)),
-		quote(updatedAt.Format(time.RFC3339)),
+		formatTime(state.LastRestartAt),
+		formatTime(state.CooldownUntil),
+		state.UnhealthyIntervals,
+		state.LastEvent,
+		ruleFiringsJSON,
+		updatedAt.Format(time.RFC3339),
 	)
-	return s.execSQL(sql)
 }
 
 func (s *SQLiteStore) GetOperatorRunState(runID string) (*model.OperatorRunState, error) {
-	sql := fmt.Sprintf(
-		`SELECT run_id, restart_attempts, last_restart_at, cooldown_until, updated_at
+	rows, err := s.queryJSON(
+		`SELECT run_id, restart_attempts, last_restart_at, cooldown_until, unhealthy_intervals, last_event, rule_firings_json, updated_at
 FROM operator_run_states
-WHERE run_id=%s;`,
-		quote(runID),
+WHERE run_id=?;`,
+		runID,
 	)
-	rows, err := s.queryJSON(sql)
 	if err != nil {
 		return nil, err
 	}
@@ -707,11 +536,18 @@ WHERE run_id=%s;`,
 		return nil, fmt.Errorf("parse operator_run_states updated_at: %w", err)
 	}
 	state := &model.OperatorRunState{
-		RunID:           asString(row["run_id"]),
-		RestartAttempts: asInt(row["restart_attempts"]),
-		LastRestartAt:   parseTimePtr(asString(row["last_restart_at"])),
-		CooldownUntil:   parseTimePtr(asString(row["cooldown_until"])),
-		UpdatedAt:       updatedAt,
+		RunID:              asString(row["run_id"]),
+		RestartAttempts:    asInt(row["restart_attempts"]),
+		LastRestartAt:      parseTimePtr(asString(row["last_restart_at"])),
+		CooldownUntil:      parseTimePtr(asString(row["cooldown_until"])),
+		UnhealthyIntervals: asInt(row["unhealthy_intervals"]),
+		LastEvent:          asString(row["last_event"]),
+		UpdatedAt:          updatedAt,
+	}
+	if value := strings.TrimSpace(asString(row["rule_firings_json"])); value != "" {
+		if err := json.Unmarshal([]byte(value), &state.RuleFirings); err != nil {
+			return nil, fmt.Errorf("parse operator_run_states rule_firings_json: %w", err)
+		}
 	}
 	return state, nil
 }
@@ -726,41 +562,39 @@ func (s *SQLiteStore) UpsertRunPullRequest(record model.RunPullRequest) error {
 	if updatedAt.IsZero() {
 		updatedAt = now
 	}
-	sql := fmt.Sprintf(
+	return s.execSQL(
 		`INSERT OR REPLACE INTO run_pull_requests
   (run_id, ticket, repo, workspace_name, head_branch, base_branch, remote_name, commit_sha, pr_number, pr_url, pr_state, credential_mode, actor, validation_json, error_text, created_at, updated_at)
 VALUES
-  (%s, %s, %s, %s, %s, %s, %s, %s, %d, %s, %s, %s, %s, %s, %s, %s, %s);`,
-		quote(record.RunID),
-		quote(record.Ticket),
-		quote(record.Repo),
-		quote(record.WorkspaceName),
-		quote(record.HeadBranch),
-		quote(record.BaseBranch),
-		quote(record.RemoteName),
-		quote(record.CommitSHA),
+  (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`,
+		record.RunID,
+		record.Ticket,
+		record.Repo,
+		record.WorkspaceName,
+		record.HeadBranch,
+		record.BaseBranch,
+		record.RemoteName,
+		record.CommitSHA,
 		record.PRNumber,
-		quote(record.PRURL),
-		quote(string(record.PRState)),
-		quote(record.CredentialMode),
-		quote(record.Actor),
-		quote(record.ValidationJSON),
-		quote(record.ErrorText),
-		quote(createdAt.Format(time.RFC3339)),
-		quote(updatedAt.Format(time.RFC3339)),
+		record.PRURL,
+		string(record.PRState),
+		record.CredentialMode,
+		record.Actor,
+		record.ValidationJSON,
+		record.ErrorText,
+		createdAt.Format(time.RFC3339),
+		updatedAt.Format(time.RFC3339),
 	)
-	return s.execSQL(sql)
 }
 
 func (s *SQLiteStore) ListRunPullRequests(runID string) ([]model.RunPullRequest, error) {
-	sql := fmt.Sprintf(
+	rows, err := s.queryJSON(
 		`SELECT run_id, ticket, repo, workspace_name, head_branch, base_branch, remote_name, commit_sha, pr_number, pr_url, pr_state, credential_mode, actor, validation_json, error_text, created_at, updated_at
 FROM run_pull_requests
-WHERE run_id=%s
+WHERE run_id=?
 ORDER BY ticket, repo;`,
-		quote(runID),
+		runID,
 	)
-	rows, err := s.queryJSON(sql)
 	if err != nil {
 		return nil, err
 	}
@@ -815,43 +649,41 @@ func (s *SQLiteStore) UpsertRunReviewFeedback(record model.RunReviewFeedback) er
 	if record.AddressedAt != nil {
 		addressedAt = record.AddressedAt.Format(time.RFC3339)
 	}
-	sql := fmt.Sprintf(
+	return s.execSQL(
 		`INSERT OR REPLACE INTO run_review_feedback
   (run_id, ticket, repo, workspace_name, pr_number, pr_url, source_type, source_id, source_url, author, body_text, file_path, line_number, status, error_text, created_at, updated_at, last_seen_at, addressed_at)
 VALUES
-  (%s, %s, %s, %s, %d, %s, %s, %s, %s, %s, %s, %s, %d, %s, %s, %s, %s, %s, %s);`,
-		quote(record.RunID),
-		quote(record.Ticket),
-		quote(record.Repo),
-		quote(record.WorkspaceName),
+  (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`,
+		record.RunID,
+		record.Ticket,
+		record.Repo,
+		record.WorkspaceName,
 		record.PRNumber,
-		quote(record.PRURL),
-		quote(string(record.SourceType)),
-		quote(record.SourceID),
-		quote(record.SourceURL),
-		quote(record.Author),
-		quote(record.Body),
-		quote(record.FilePath),
+		record.PRURL,
+		string(record.SourceType),
+		record.SourceID,
+		record.SourceURL,
+		record.Author,
+		record.Body,
+		record.FilePath,
 		record.Line,
-		quote(string(record.Status)),
-		quote(record.ErrorText),
-		quote(createdAt.Format(time.RFC3339)),
-		quote(updatedAt.Format(time.RFC3339)),
-		quote(lastSeenAt.Format(time.RFC3339)),
-		quote(addressedAt),
+		string(record.Status),
+		record.ErrorText,
+		createdAt.Format(time.RFC3339),
+		updatedAt.Format(time.RFC3339),
+		lastSeenAt.Format(time.RFC3339),
+		addressedAt,
 	)
-	return s.execSQL(sql)
 }
 
 func (s *SQLiteStore) ListRunReviewFeedback(runID string) ([]model.RunReviewFeedback, error) {
-	sql := fmt.Sprintf(
+	rows, err := s.queryJSON(
 		`SELECT run_id, ticket, repo, workspace_name, pr_number, pr_url, source_type, source_id, source_url, author, body_text, file_path, line_number, status, error_text, created_at, updated_at, last_seen_at, addressed_at
 FROM run_review_feedback
-WHERE run_id=%s
+WHERE run_id=?
 ORDER BY ticket, repo, pr_number, source_type, source_id;`,
-		quote(runID),
+		runID,
 	)
-	rows, err := s.queryJSON(sql)
 	if err != nil {
 		return nil, err
 	}
@@ -859,15 +691,14 @@ ORDER BY ticket, repo, pr_number, source_type, source_id;`,
 }
 
 func (s *SQLiteStore) ListRunReviewFeedbackByStatus(runID string, status model.ReviewFeedbackStatus) ([]model.RunReviewFeedback, error) {
-	sql := fmt.Sprintf(
+	rows, err := s.queryJSON(
 		`SELECT run_id, ticket, repo, workspace_name, pr_number, pr_url, source_type, source_id, source_url, author, body_text, file_path, line_number, status, error_text, created_at, updated_at, last_seen_at, addressed_at
 FROM run_review_feedback
-WHERE run_id=%s AND status=%s
+WHERE run_id=? AND status=?
 ORDER BY ticket, repo, pr_number, source_type, source_id;`,
-		quote(runID),
-		quote(string(status)),
+		runID,
+		string(status),
 	)
-	rows, err := s.queryJSON(sql)
 	if err != nil {
 		return nil, err
 	}
@@ -889,22 +720,21 @@ func (s *SQLiteStore) UpdateRunReviewFeedbackStatus(
 	if addressedAt != nil {
 		addressedAtValue = addressedAt.Format(time.RFC3339)
 	}
-	sql := fmt.Sprintf(
+	return s.execSQL(
 		`UPDATE run_review_feedback
-SET status=%s, error_text=%s, addressed_at=%s, updated_at=%s
-WHERE run_id=%s AND ticket=%s AND repo=%s AND pr_number=%d AND source_type=%s AND source_id=%s;`,
-		quote(string(status)),
-		quote(errorText),
-		quote(addressedAtValue),
-		quote(time.Now().Format(time.RFC3339)),
-		quote(runID),
-		quote(ticket),
-		quote(repo),
+SET status=?, error_text=?, addressed_at=?, updated_at=?
+WHERE run_id=? AND ticket=? AND repo=? AND pr_number=? AND source_type=? AND source_id=?;`,
+		string(status),
+		errorText,
+		addressedAtValue,
+		time.Now().Format(time.RFC3339),
+		runID,
+		ticket,
+		repo,
 		prNumber,
-		quote(string(sourceType)),
-		quote(sourceID),
+		string(sourceType),
+		sourceID,
 	)
-	return s.execSQL(sql)
 }
 
 func parseRunReviewFeedbackRows(rows []map[string]any) ([]model.RunReviewFeedback, error) {
@@ -952,34 +782,32 @@ func (s *SQLiteStore) UpsertDocSyncState(state model.DocSyncState) error {
 	if updatedAt.IsZero() {
 		updatedAt = time.Now()
 	}
-	sql := fmt.Sprintf(
+	return s.execSQL(
 		`INSERT OR REPLACE INTO doc_sync_states
   (run_id, ticket, workspace_name, doc_home_repo, doc_authority_mode, doc_seed_mode, status, revision, error_text, updated_at)
 VALUES
-  (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s);`,
-		quote(state.RunID),
-		quote(state.Ticket),
-		quote(state.WorkspaceName),
-		quote(state.DocHomeRepo),
-		quote(state.DocAuthorityMode),
-		quote(state.DocSeedMode),
-		quote(string(state.Status)),
-		quote(state.Revision),
-		quote(state.ErrorText),
-		quote(updatedAt.Format(time.RFC3339)),
+  (?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`,
+		state.RunID,
+		state.Ticket,
+		state.WorkspaceName,
+		state.DocHomeRepo,
+		state.DocAuthorityMode,
+		state.DocSeedMode,
+		string(state.Status),
+		state.Revision,
+		state.ErrorText,
+		updatedAt.Format(time.RFC3339),
 	)
-	return s.execSQL(sql)
 }
 
 func (s *SQLiteStore) ListDocSyncStates(runID string) ([]model.DocSyncState, error) {
-	sql := fmt.Sprintf(
+	rows, err := s.queryJSON(
 		`SELECT run_id, ticket, workspace_name, doc_home_repo, doc_authority_mode, doc_seed_mode, status, revision, error_text, updated_at
 FROM doc_sync_states
-WHERE run_id=%s
+WHERE run_id=?
 ORDER BY ticket, workspace_name;`,
-		quote(runID),
+		runID,
 	)
-	rows, err := s.queryJSON(sql)
 	if err != nil {
 		return nil, err
 	}
@@ -1019,28 +847,26 @@ func (s *SQLiteStore) UpdateRunDocFreshnessRevision(runID string, revision strin
 	if err != nil {
 		return fmt.Errorf("marshal run spec: %w", err)
 	}
-	sql := fmt.Sprintf(
+	return s.execSQL(
 		`UPDATE runs
-SET spec_json=%s, updated_at=%s
-WHERE run_id=%s;`,
-		quote(string(encoded)),
-		quote(time.Now().Format(time.RFC3339)),
-		quote(runID),
+SET spec_json=?, updated_at=?
+WHERE run_id=?;`,
+		string(encoded),
+		time.Now().Format(time.RFC3339),
+		runID,
 	)
-	return s.execSQL(sql)
 }
 
 func (s *SQLiteStore) GetRun(runID string) (model.RunRecord, string, string, error) {
-	sql := fmt.Sprintf(
-		`SELECT run_id, status, created_at, updated_at, error_text, spec_json, policy_json FROM runs WHERE run_id=%s;`,
-		quote(runID),
+	rows, err := s.queryJSON(
+		`SELECT run_id, status, created_at, updated_at, error_text, spec_json, policy_json FROM runs WHERE run_id=?;`,
+		runID,
 	)
-	rows, err := s.queryJSON(sql)
 	if err != nil {
 		return model.RunRecord{}, "", "", err
 	}
 	if len(rows) == 0 {
-		return model.RunRecord{}, "", "", fmt.Errorf("run %s not found", runID)
+		return model.RunRecord{}, "", "", fmt.Errorf("%w: %s", ErrRunNotFound, runID)
 	}
 	row := rows[0]
 	record, err := parseRunRecord(row)
@@ -1070,8 +896,10 @@ func (s *SQLiteStore) ListRuns() ([]model.RunRecord, error) {
 }
 
 func (s *SQLiteStore) GetTickets(runID string) ([]string, error) {
-	sql := fmt.Sprintf(`SELECT ticket FROM run_tickets WHERE run_id=%s ORDER BY ticket;`, quote(runID))
-	rows, err := s.queryJSON(sql)
+	rows, err := s.queryJSON(
+		`SELECT ticket FROM run_tickets WHERE run_id=? ORDER BY ticket;`,
+		runID,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -1083,16 +911,15 @@ func (s *SQLiteStore) GetTickets(runID string) ([]string, error) {
 }
 
 func (s *SQLiteStore) FindLatestRunIDByTicket(ticket string) (string, error) {
-	sql := fmt.Sprintf(
+	rows, err := s.queryJSON(
 		`SELECT r.run_id
 FROM runs r
 JOIN run_tickets t ON t.run_id = r.run_id
-WHERE t.ticket=%s
+WHERE t.ticket=?
 ORDER BY r.updated_at DESC, r.run_id DESC
 LIMIT 1;`,
-		quote(ticket),
+		ticket,
 	)
-	rows, err := s.queryJSON(sql)
 	if err != nil {
 		return "", err
 	}
@@ -1103,15 +930,14 @@ LIMIT 1;`,
 }
 
 func (s *SQLiteStore) ListRunIDsByTicket(ticket string) ([]string, error) {
-	sql := fmt.Sprintf(
+	rows, err := s.queryJSON(
 		`SELECT r.run_id
 FROM runs r
 JOIN run_tickets t ON t.run_id = r.run_id
-WHERE t.ticket=%s
+WHERE t.ticket=?
 ORDER BY r.updated_at DESC, r.run_id DESC;`,
-		quote(ticket),
+		ticket,
 	)
-	rows, err := s.queryJSON(sql)
 	if err != nil {
 		return nil, err
 	}
@@ -1127,17 +953,22 @@ ORDER BY r.updated_at DESC, r.run_id DESC;`,
 }
 
 func (s *SQLiteStore) GetSteps(runID string) ([]model.StepRecord, error) {
-	sql := fmt.Sprintf(
-		`SELECT run_id, step_index, name, kind, command_text, blocking, ticket, workspace_name, agent_name, status, error_text, started_at, finished_at
-FROM steps WHERE run_id=%s ORDER BY step_index;`,
-		quote(runID),
+	rows, err := s.queryJSON(
+		`SELECT run_id, step_index, name, kind, command_text, blocking, ticket, workspace_name, agent_name, depends_on_json, status, error_text, started_at, finished_at
+FROM steps WHERE run_id=? ORDER BY step_index;`,
+		runID,
 	)
-	rows, err := s.queryJSON(sql)
 	if err != nil {
 		return nil, err
 	}
 	out := make([]model.StepRecord, 0, len(rows))
 	for _, row := range rows {
+		var dependsOn []int
+		if raw := strings.TrimSpace(asString(row["depends_on_json"])); raw != "" {
+			if err := json.Unmarshal([]byte(raw), &dependsOn); err != nil {
+				return nil, fmt.Errorf("parse step depends_on_json: %w", err)
+			}
+		}
 		step := model.StepRecord{
 			RunID:         asString(row["run_id"]),
 			Index:         asInt(row["step_index"]),
@@ -1148,6 +979,7 @@ FROM steps WHERE run_id=%s ORDER BY step_index;`,
 			Ticket:        asString(row["ticket"]),
 			WorkspaceName: asString(row["workspace_name"]),
 			Agent:         asString(row["agent_name"]),
+			DependsOn:     dependsOn,
 			Status:        model.StepStatus(asString(row["status"])),
 			ErrorText:     asString(row["error_text"]),
 			StartedAt:     parseTimePtr(asString(row["started_at"])),
@@ -1159,12 +991,11 @@ FROM steps WHERE run_id=%s ORDER BY step_index;`,
 }
 
 func (s *SQLiteStore) GetAgents(runID string) ([]model.AgentRecord, error) {
-	sql := fmt.Sprintf(
+	rows, err := s.queryJSON(
 		`SELECT run_id, agent_name, workspace_name, session_name, status, health_state, last_activity_at, last_progress_at
-FROM agents WHERE run_id=%s ORDER BY workspace_name, agent_name;`,
-		quote(runID),
+FROM agents WHERE run_id=? ORDER BY workspace_name, agent_name;`,
+		runID,
 	)
-	rows, err := s.queryJSON(sql)
 	if err != nil {
 		return nil, err
 	}
@@ -1185,11 +1016,170 @@ FROM agents WHERE run_id=%s ORDER BY workspace_name, agent_name;`,
 	return out, nil
 }
 
-func (s *SQLiteStore) execSQL(sql string) error {
+func (s *SQLiteStore) UpsertAgentTranscript(transcript model.AgentTranscript) error {
+	return s.execSQL(
+		`INSERT INTO agent_transcripts
+  (run_id, agent_name, workspace_name, path, runtime, size_bytes, rotations, started_at, updated_at)
+VALUES
+  (?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(run_id, agent_name, workspace_name) DO UPDATE SET
+  path=excluded.path,
+  runtime=excluded.runtime,
+  size_bytes=excluded.size_bytes,
+  rotations=excluded.rotations,
+  started_at=excluded.started_at,
+  updated_at=excluded.updated_at;`,
+		transcript.RunID,
+		transcript.AgentName,
+		transcript.WorkspaceName,
+		transcript.Path,
+		transcript.Runtime,
+		transcript.SizeBytes,
+		transcript.Rotations,
+		formatTime(transcript.StartedAt),
+		formatTime(transcript.UpdatedAt),
+	)
+}
+
+func (s *SQLiteStore) ListAgentTranscripts(runID string) ([]model.AgentTranscript, error) {
+	rows, err := s.queryJSON(
+		`SELECT run_id, agent_name, workspace_name, path, runtime, size_bytes, rotations, started_at, updated_at
+FROM agent_transcripts WHERE run_id=? ORDER BY workspace_name, agent_name;`,
+		runID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]model.AgentTranscript, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, model.AgentTranscript{
+			RunID:         asString(row["run_id"]),
+			AgentName:     asString(row["agent_name"]),
+			WorkspaceName: asString(row["workspace_name"]),
+			Path:          asString(row["path"]),
+			Runtime:       asString(row["runtime"]),
+			SizeBytes:     int64(asInt(row["size_bytes"])),
+			Rotations:     asInt(row["rotations"]),
+			StartedAt:     parseTimePtr(asString(row["started_at"])),
+			UpdatedAt:     parseTimePtr(asString(row["updated_at"])),
+		})
+	}
+	return out, nil
+}
+
+func (s *SQLiteStore) CreateAPIToken(token model.APIToken) error {
+	return s.execSQL(
+		`INSERT INTO api_tokens
+  (token_id, token_hash, role, actor_name, run_id, agent_name, workspace_name, created_at, expires_at, revoked_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`,
+		token.TokenID,
+		token.TokenHash,
+		string(token.Role),
+		token.ActorName,
+		token.RunID,
+		token.AgentName,
+		token.WorkspaceName,
+		token.CreatedAt.Format(time.RFC3339),
+		formatTime(token.ExpiresAt),
+		formatTime(token.RevokedAt),
+	)
+}
+
+// GetAPITokenByHash returns nil when no token has the given hash.
+func (s *SQLiteStore) GetAPITokenByHash(tokenHash string) (*model.APIToken, error) {
+	rows, err := s.queryJSON(
+		`SELECT token_id, token_hash, role, actor_name, run_id, agent_name, workspace_name, created_at, expires_at, revoked_at
+FROM api_tokens WHERE token_hash=?;`,
+		tokenHash,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	token, err := parseAPIToken(rows[0])
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (s *SQLiteStore) ListAPITokens() ([]model.APIToken, error) {
+	rows, err := s.queryJSON(
+		`SELECT token_id, token_hash, role, actor_name, run_id, agent_name, workspace_name, created_at, expires_at, revoked_at
+FROM api_tokens ORDER BY created_at, token_id;`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]model.APIToken, 0, len(rows))
+	for _, row := range rows {
+		token, err := parseAPIToken(row)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, token)
+	}
+	return out, nil
+}
+
+// RevokeAPIToken marks a token revoked. Revoking an already revoked token keeps
+// the original revocation time.
+func (s *SQLiteStore) RevokeAPIToken(tokenID string, revokedAt time.Time) error {
+	rows, err := s.queryJSON(`SELECT revoked_at FROM api_tokens WHERE token_id=?;`, tokenID)
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return fmt.Errorf("api token %s not found", tokenID)
+	}
+	return s.execSQL(
+		`UPDATE api_tokens SET revoked_at=? WHERE token_id=? AND revoked_at='';`,
+		revokedAt.Format(time.RFC3339),
+		tokenID,
+	)
+}
+
+// RevokeAgentAPITokens revokes the active credentials minted for a run. Empty
+// agentName or workspaceName match every agent or workspace in the run.
+func (s *SQLiteStore) RevokeAgentAPITokens(runID string, agentName string, workspaceName string, revokedAt time.Time) error {
+	return s.execSQL(
+		`UPDATE api_tokens SET revoked_at=?
+WHERE run_id=? AND run_id<>'' AND (?='' OR agent_name=?) AND (?='' OR workspace_name=?) AND revoked_at='';`,
+		revokedAt.Format(time.RFC3339),
+		runID,
+		agentName,
+		agentName,
+		workspaceName,
+		workspaceName,
+	)
+}
+
+func parseAPIToken(row map[string]any) (model.APIToken, error) {
+	createdAt, err := time.Parse(time.RFC3339, asString(row["created_at"]))
+	if err != nil {
+		return model.APIToken{}, fmt.Errorf("parse api_tokens created_at: %w", err)
+	}
+	return model.APIToken{
+		TokenID:       asString(row["token_id"]),
+		TokenHash:     asString(row["token_hash"]),
+		Role:          model.APIRole(asString(row["role"])),
+		ActorName:     asString(row["actor_name"]),
+		RunID:         asString(row["run_id"]),
+		AgentName:     asString(row["agent_name"]),
+		WorkspaceName: asString(row["workspace_name"]),
+		CreatedAt:     createdAt,
+		ExpiresAt:     parseTimePtr(asString(row["expires_at"])),
+		RevokedAt:     parseTimePtr(asString(row["revoked_at"])),
+	}, nil
+}
+
+func (s *SQLiteStore) execSQL(sql string, args ...any) error {
 	attempts := s.retryAttempts()
 	var lastErr error
 	for attempt := 1; attempt <= attempts; attempt++ {
-		err := s.execSQLOnce(sql)
+		err := s.execSQLOnce(sql, args...)
 		if err == nil {
 			return nil
 		}
@@ -1203,11 +1193,34 @@ func (s *SQLiteStore) execSQL(sql string) error {
 	return lastErr
 }
 
-func (s *SQLiteStore) queryJSON(sql string) ([]map[string]any, error) {
+// execTx runs statements in one transaction, retrying the whole transaction
+// while the database is busy.
+func (s *SQLiteStore) execTx(statements ...sqlStatement) error {
+	if len(statements) == 0 {
+		return nil
+	}
 	attempts := s.retryAttempts()
 	var lastErr error
 	for attempt := 1; attempt <= attempts; attempt++ {
-		rows, err := s.queryJSONOnce(sql)
+		err := s.execTxOnce(statements)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !isSQLiteBusyError(err) || attempt == attempts {
+			return err
+		}
+		s.notifyRetry("exec", attempt, err)
+		time.Sleep(s.retryDelay(attempt))
+	}
+	return lastErr
+}
+
+func (s *SQLiteStore) queryJSON(sql string, args ...any) ([]map[string]any, error) {
+	attempts := s.retryAttempts()
+	var lastErr error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		rows, err := s.queryJSONOnce(sql, args...)
 		if err == nil {
 			return rows, nil
 		}
@@ -1221,47 +1234,28 @@ func (s *SQLiteStore) queryJSON(sql string) ([]map[string]any, error) {
 	return nil, lastErr
 }
 
-func (s *SQLiteStore) execSQLOnce(sql string) error {
-	cmd := exec.Command(s.SQLitePath, s.sqliteCLIArgs(sql, false)...)
-	var stderr bytes.Buffer
-	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("sqlite exec failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+func (s *SQLiteStore) execSQLOnce(sql string, args ...any) error {
+	backend, err := s.backend()
+	if err != nil {
+		return err
 	}
-	return nil
+	return backend.exec(sql, args...)
 }
 
-func (s *SQLiteStore) queryJSONOnce(sql string) ([]map[string]any, error) {
-	cmd := exec.Command(s.SQLitePath, s.sqliteCLIArgs(sql, true)...)
-	var stdout bytes.Buffer
-	var stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("sqlite query failed: %w: %s", err, strings.TrimSpace(stderr.String()))
-	}
-
-	output := strings.TrimSpace(stdout.String())
-	if output == "" {
-		return []map[string]any{}, nil
-	}
-	rows := []map[string]any{}
-	if err := json.Unmarshal(stdout.Bytes(), &rows); err != nil {
-		return nil, fmt.Errorf("parse sqlite json output: %w", err)
+func (s *SQLiteStore) execTxOnce(statements []sqlStatement) error {
+	backend, err := s.backend()
+	if err != nil {
+		return err
 	}
-	return rows, nil
+	return backend.execTx(statements)
 }
 
-func (s *SQLiteStore) sqliteCLIArgs(sql string, jsonMode bool) []string {
-	args := []string{}
-	if s.BusyTimeoutMS > 0 {
-		args = append(args, "-cmd", ".timeout "+strconv.Itoa(s.BusyTimeoutMS))
-	}
-	if jsonMode {
-		args = append(args, "-json")
+func (s *SQLiteStore) queryJSONOnce(sql string, args ...any) ([]map[string]any, error) {
+	backend, err := s.backend()
+	if err != nil {
+		return nil, err
 	}
-	args = append(args, s.DBPath, sql)
-	return args
+	return backend.query(sql, args...)
 }
 
 func (s *SQLiteStore) retryAttempts() int {
@@ -1327,6 +1321,10 @@ func asString(v any) string {
 		return typed
 	case float64:
 		return strconv.FormatFloat(typed, 'f', -1, 64)
+	case int64:
+		return strconv.FormatInt(typed, 10)
+	case []byte:
+		return string(typed)
 	case bool:
 		if typed {
 			return "1"
@@ -1346,6 +1344,8 @@ func asInt(v any) int {
 		return n
 	case int:
 		return typed
+	case int64:
+		return int(typed)
 	default:
 		return 0
 	}
@@ -0,0 +1,373 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+const (
+	// BackendDriver runs statements in-process through database/sql.
+	BackendDriver = "driver"
+	// BackendCLI shells out to the sqlite3 binary at SQLitePath for every statement.
+	BackendCLI = "cli"
+
+	driverMaxOpenConns    = 8
+	driverMaxIdleConns    = 4
+	driverConnMaxIdleTime = 5 * time.Minute
+)
+
+// sqliteBackend executes SQL against the store database. Scripts passed to exec
+// may contain several statements (including BEGIN IMMEDIATE ... COMMIT blocks)
+// when no args are given; parameterized calls must be a single statement.
+// execTx runs parameterized statements inside one BEGIN IMMEDIATE transaction.
+type sqliteBackend interface {
+	exec(script string, args ...any) error
+	execTx(statements []sqlStatement) error
+	query(query string, args ...any) ([]map[string]any, error)
+	close() error
+}
+
+// sqlStatement is a single statement with its ? placeholder args.
+type sqlStatement struct {
+	query string
+	args  []any
+}
+
+func statement(query string, args ...any) sqlStatement {
+	return sqlStatement{query: query, args: args}
+}
+
+// placeholders returns n comma-separated ? placeholders for an IN list.
+func placeholders(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+func normalizeBackend(backend string) string {
+	switch strings.TrimSpace(strings.ToLower(backend)) {
+	case BackendCLI:
+		return BackendCLI
+	default:
+		return BackendDriver
+	}
+}
+
+// Close releases the backend connection pool. The store reopens lazily on next use.
+func (s *SQLiteStore) Close() error {
+	s.backendMu.Lock()
+	defer s.backendMu.Unlock()
+	if s.backendImpl == nil {
+		return nil
+	}
+	err := s.backendImpl.close()
+	s.backendImpl = nil
+	return err
+}
+
+func (s *SQLiteStore) backend() (sqliteBackend, error) {
+	s.backendMu.Lock()
+	defer s.backendMu.Unlock()
+	if s.backendImpl != nil {
+		return s.backendImpl, nil
+	}
+	switch normalizeBackend(s.Backend) {
+	case BackendCLI:
+		s.backendImpl = &cliBackend{store: s}
+	default:
+		backend, err := openDriverBackend(s.DBPath, s.BusyTimeoutMS)
+		if err != nil {
+			return nil, err
+		}
+		s.backendImpl = backend
+	}
+	return s.backendImpl, nil
+}
+
+type cliBackend struct {
+	store *SQLiteStore
+}
+
+func (b *cliBackend) exec(script string, args ...any) error {
+	bound, err := bindSQLArgs(script, args)
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(b.store.SQLitePath, b.store.sqliteCLIArgs(bound, false)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("sqlite exec failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+func (b *cliBackend) execTx(statements []sqlStatement) error {
+	var script strings.Builder
+	script.WriteString("BEGIN IMMEDIATE;\n")
+	for _, statement := range statements {
+		bound, err := bindSQLArgs(statement.query, statement.args)
+		if err != nil {
+			return err
+		}
+		script.WriteString(strings.TrimSuffix(strings.TrimSpace(bound), ";"))
+		script.WriteString(";\n")
+	}
+	script.WriteString("COMMIT;")
+	return b.exec(script.String())
+}
+
+func (b *cliBackend) query(query string, args ...any) ([]map[string]any, error) {
+	bound, err := bindSQLArgs(query, args)
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.Command(b.store.SQLitePath, b.store.sqliteCLIArgs(bound, true)...)
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("sqlite query failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	output := strings.TrimSpace(stdout.String())
+	if output == "" {
+		return []map[string]any{}, nil
+	}
+	rows := []map[string]any{}
+	if err := json.Unmarshal(stdout.Bytes(), &rows); err != nil {
+		return nil, fmt.Errorf("parse sqlite json output: %w", err)
+	}
+	return rows, nil
+}
+
+func (b *cliBackend) close() error {
+	return nil
+}
+
+func (s *SQLiteStore) sqliteCLIArgs(sql string, jsonMode bool) []string {
+	args := []string{}
+	if s.BusyTimeoutMS > 0 {
+		args = append(args, "-cmd", ".timeout "+strconv.Itoa(s.BusyTimeoutMS))
+	}
+	if jsonMode {
+		args = append(args, "-json")
+	}
+	args = append(args, s.DBPath, sql)
+	return args
+}
+
+type driverBackend struct {
+	db *sql.DB
+
+	mu    sync.Mutex
+	stmts map[string]*sql.Stmt
+}
+
+func openDriverBackend(dbPath string, busyTimeoutMS int) (*driverBackend, error) {
+	dsn := dbPath
+	if busyTimeoutMS > 0 {
+		dsn += fmt.Sprintf("?_pragma=busy_timeout(%d)", busyTimeoutMS)
+	}
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite database: %w", err)
+	}
+	db.SetMaxOpenConns(driverMaxOpenConns)
+	db.SetMaxIdleConns(driverMaxIdleConns)
+	db.SetConnMaxIdleTime(driverConnMaxIdleTime)
+	return &driverBackend{
+		db:    db,
+		stmts: map[string]*sql.Stmt{},
+	}, nil
+}
+
+func (b *driverBackend) exec(script string, args ...any) error {
+	ctx := context.Background()
+	if len(args) > 0 {
+		stmt, err := b.prepare(ctx, script)
+		if err != nil {
+			return fmt.Errorf("sqlite exec failed: %w", err)
+		}
+		if _, err := stmt.ExecContext(ctx, args...); err != nil {
+			return fmt.Errorf("sqlite exec failed: %w", err)
+		}
+		return nil
+	}
+
+	// Scripts run on a single pooled connection so explicit transactions stay
+	// on the connection that opened them.
+	conn, err := b.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("sqlite exec failed: %w", err)
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, script); err != nil {
+		// A failed statement inside BEGIN ... COMMIT leaves the transaction open;
+		// roll it back before the connection returns to the pool.
+		_, _ = conn.ExecContext(ctx, "ROLLBACK;")
+		return fmt.Errorf("sqlite exec failed: %w", err)
+	}
+	return nil
+}
+
+func (b *driverBackend) execTx(statements []sqlStatement) error {
+	ctx := context.Background()
+	conn, err := b.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("sqlite exec failed: %w", err)
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, "BEGIN IMMEDIATE;"); err != nil {
+		return fmt.Errorf("sqlite exec failed: %w", err)
+	}
+	for _, statement := range statements {
+		if _, err := conn.ExecContext(ctx, statement.query, statement.args...); err != nil {
+			_, _ = conn.ExecContext(ctx, "ROLLBACK;")
+			return fmt.Errorf("sqlite exec failed: %w", err)
+		}
+	}
+	if _, err := conn.ExecContext(ctx, "COMMIT;"); err != nil {
+		_, _ = conn.ExecContext(ctx, "ROLLBACK;")
+		return fmt.Errorf("sqlite exec failed: %w", err)
+	}
+	return nil
+}
+
+func (b *driverBackend) query(query string, args ...any) ([]map[string]any, error) {
+	ctx := context.Background()
+	var (
+		rows *sql.Rows
+		err  error
+	)
+	if len(args) > 0 {
+		stmt, prepErr := b.prepare(ctx, query)
+		if prepErr != nil {
+			return nil, fmt.Errorf("sqlite query failed: %w", prepErr)
+		}
+		rows, err = stmt.QueryContext(ctx, args...)
+	} else {
+		rows, err = b.db.QueryContext(ctx, query)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("sqlite query failed: %w", err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("sqlite query failed: %w", err)
+	}
+	out := []map[string]any{}
+	for rows.Next() {
+		values := make([]any, len(columns))
+		targets := make([]any, len(columns))
+		for i := range values {
+			targets[i] = &values[i]
+		}
+		if err := rows.Scan(targets...); err != nil {
+			return nil, fmt.Errorf("sqlite query failed: %w", err)
+		}
+		row := make(map[string]any, len(columns))
+		for i, column := range columns {
+			if raw, ok := values[i].([]byte); ok {
+				row[column] = string(raw)
+				continue
+			}
+			row[column] = values[i]
+		}
+		out = append(out, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("sqlite query failed: %w", err)
+	}
+	return out, nil
+}
+
+func (b *driverBackend) prepare(ctx context.Context, query string) (*sql.Stmt, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if stmt, ok := b.stmts[query]; ok {
+		return stmt, nil
+	}
+	stmt, err := b.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	b.stmts[query] = stmt
+	return stmt, nil
+}
+
+func (b *driverBackend) close() error {
+	b.mu.Lock()
+	for query, stmt := range b.stmts {
+		_ = stmt.Close()
+		delete(b.stmts, query)
+	}
+	b.mu.Unlock()
+	return b.db.Close()
+}
+
+// bindSQLArgs inlines ? placeholders as SQL literals for the CLI backend, which
+// has no way to pass bound parameters. Placeholders inside quoted strings are left alone.
+func bindSQLArgs(query string, args []any) (string, error) {
+	if len(args) == 0 {
+		return query, nil
+	}
+	var b strings.Builder
+	next := 0
+	inString := false
+	for _, r := range query {
+		switch {
+		case r == '\'':
+			inString = !inString
+			b.WriteRune(r)
+		case r == '?' && !inString:
+			if next >= len(args) {
+				return "", fmt.Errorf("sqlite query has more placeholders than args")
+			}
+			b.WriteString(sqlLiteral(args[next]))
+			next++
+		default:
+			b.WriteRune(r)
+		}
+	}
+	if next != len(args) {
+		return "", fmt.Errorf("sqlite query expects %d args, got %d", next, len(args))
+	}
+	return b.String(), nil
+}
+
+func sqlLiteral(v any) string {
+	switch typed := v.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return quote(typed)
+	case int:
+		return strconv.Itoa(typed)
+	case int64:
+		return strconv.FormatInt(typed, 10)
+	case float64:
+		return strconv.FormatFloat(typed, 'f', -1, 64)
+	case bool:
+		if typed {
+			return "1"
+		}
+		return "0"
+	default:
+		return quote(fmt.Sprint(v))
+	}
+}
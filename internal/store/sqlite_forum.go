@@ -24,83 +24,97 @@ func (s *SQLiteStore) ForumOpenThread(cmd model.ForumOpenThreadCommand) (*model.
 	if priority == "" {
 		priority = model.ForumPriorityNormal
 	}
-	payload, err := json.Marshal(map[string]any{
+	payloadFields := map[string]any{
 		"title":    cmd.Title,
 		"body":     cmd.Body,
 		"priority": priority,
-	})
+	}
+	if len(cmd.Attachments) > 0 {
+		payloadFields["attachments"] = cmd.Attachments
+	}
+	payload, err := json.Marshal(payloadFields)
 	if err != nil {
 		return nil, fmt.Errorf("marshal forum open payload: %w", err)
 	}
 
 	postID := cmd.Envelope.EventID + ".post"
+	attachmentInserts, err := forumAttachmentInserts(postID, cmd.Envelope.ThreadID, cmd.Attachments)
+	if err != nil {
+		return nil, err
+	}
 	nowRFC3339 := now.Format(time.RFC3339)
-	sql := fmt.Sprintf(
-		`BEGIN IMMEDIATE;
-INSERT INTO forum_threads
+	statements := []sqlStatement{
+		statement(
+			`INSERT INTO forum_threads
   (thread_id, ticket, run_id, agent_name, title, state, priority, assignee_type, assignee_name, opened_by_type, opened_by_name, opened_at, updated_at, closed_at)
 VALUES
-  (%s, %s, %s, %s, %s, %s, %s, '', '', %s, %s, %s, %s, '');
-INSERT INTO forum_posts
+  (?, ?, ?, ?, ?, ?, ?, '', '', ?, ?, ?, ?, '');`,
+			cmd.Envelope.ThreadID,
+			cmd.Envelope.Ticket,
+			cmd.Envelope.RunID,
+			cmd.Envelope.AgentName,
+			cmd.Title,
+			string(model.ForumThreadStateNew),
+			string(priority),
+			string(cmd.Envelope.ActorType),
+			cmd.Envelope.ActorName,
+			nowRFC3339,
+			nowRFC3339,
+		),
+		statement(
+			`INSERT INTO forum_posts
   (post_id, thread_id, event_id, author_type, author_name, body_text, created_at)
 VALUES
-  (%s, %s, %s, %s, %s, %s, %s);
-INSERT INTO forum_events
+  (?, ?, ?, ?, ?, ?, ?);`,
+			postID,
+			cmd.Envelope.ThreadID,
+			cmd.Envelope.EventID,
+			string(cmd.Envelope.ActorType),
+			cmd.Envelope.ActorName,
+			cmd.Body,
+			nowRFC3339,
+		),
+		statement(
+			`INSERT INTO forum_events
   (event_id, event_type, event_version, occurred_at, thread_id, run_id, ticket, agent_name, actor_type, actor_name, correlation_id, causation_id, payload_json)
 VALUES
-  (%s, %s, %d, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s);
-INSERT INTO forum_thread_views
+  (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`,
+			cmd.Envelope.EventID,
+			cmd.Envelope.EventType,
+			cmd.Envelope.EventVersion,
+			nowRFC3339,
+			cmd.Envelope.ThreadID,
+			cmd.Envelope.RunID,
+			cmd.Envelope.Ticket,
+			cmd.Envelope.AgentName,
+			string(cmd.Envelope.ActorType),
+			cmd.Envelope.ActorName,
+			cmd.Envelope.CorrelationID,
+			cmd.Envelope.CausationID,
+			string(payload),
+		),
+		statement(
+			`INSERT INTO forum_thread_views
   (thread_id, ticket, run_id, agent_name, title, state, priority, assignee_type, assignee_name, opened_by_type, opened_by_name, posts_count, last_post_at, last_post_by_type, last_post_by_name, opened_at, updated_at, closed_at)
 VALUES
-  (%s, %s, %s, %s, %s, %s, %s, '', '', %s, %s, 1, %s, %s, %s, %s, %s, '');
-COMMIT;`,
-		quote(cmd.Envelope.ThreadID),
-		quote(cmd.Envelope.Ticket),
-		quote(cmd.Envelope.RunID),
-		quote(cmd.Envelope.AgentName),
-		quote(cmd.Title),
-		quote(string(model.ForumThreadStateNew)),
-		quote(string(priority)),
-		quote(string(cmd.Envelope.ActorType)),
-		quote(cmd.Envelope.ActorName),
-		quote(nowRFC3339),
-		quote(nowRFC3339),
-		quote(postID),
-		quote(cmd.Envelope.ThreadID),
-		quote(cmd.Envelope.EventID),
-		quote(string(cmd.Envelope.ActorType)),
-		quote(cmd.Envelope.ActorName),
-		quote(cmd.Body),
-		quote(nowRFC3339),
-		quote(cmd.Envelope.EventID),
-		quote(cmd.Envelope.EventType),
-		cmd.Envelope.EventVersion,
-		quote(nowRFC3339),
-		quote(cmd.Envelope.ThreadID),
-		quote(cmd.Envelope.RunID),
-		quote(cmd.Envelope.Ticket),
-		quote(cmd.Envelope.AgentName),
-		quote(string(cmd.Envelope.ActorType)),
-		quote(cmd.Envelope.ActorName),
-		quote(cmd.Envelope.CorrelationID),
-		quote(cmd.Envelope.CausationID),
-		quote(string(payload)),
-		quote(cmd.Envelope.ThreadID),
-		quote(cmd.Envelope.Ticket),
-		quote(cmd.Envelope.RunID),
-		quote(cmd.Envelope.AgentName),
-		quote(cmd.Title),
-		quote(string(model.ForumThreadStateNew)),
-		quote(string(priority)),
-		quote(string(cmd.Envelope.ActorType)),
-		quote(cmd.Envelope.ActorName),
-		quote(nowRFC3339),
-		quote(string(cmd.Envelope.ActorType)),
-		quote(cmd.Envelope.ActorName),
-		quote(nowRFC3339),
-		quote(nowRFC3339),
-	)
-	if err := s.execSQL(sql); err != nil {
+  (?, ?, ?, ?, ?, ?, ?, '', '', ?, ?, 1, ?, ?, ?, ?, ?, '');`,
+			cmd.Envelope.ThreadID,
+			cmd.Envelope.Ticket,
+			cmd.Envelope.RunID,
+			cmd.Envelope.AgentName,
+			cmd.Title,
+			string(model.ForumThreadStateNew),
+			string(priority),
+			string(cmd.Envelope.ActorType),
+			cmd.Envelope.ActorName,
+			nowRFC3339,
+			string(cmd.Envelope.ActorType),
+			cmd.Envelope.ActorName,
+			nowRFC3339,
+			nowRFC3339,
+		),
+	}
+	if err := s.execTx(append(statements, attachmentInserts...)...); err != nil {
 		return nil, err
 	}
 	if err := s.refreshForumThreadStats(cmd.Envelope.Ticket); err != nil {
@@ -109,6 +123,9 @@ COMMIT;`,
 	if err := s.refreshForumThreadQueueView(cmd.Envelope.ThreadID); err != nil {
 		return nil, err
 	}
+	if err := s.refreshForumSearchDocuments(cmd.Envelope.ThreadID); err != nil {
+		return nil, err
+	}
 	return s.GetForumThread(cmd.Envelope.ThreadID)
 }
 
@@ -130,67 +147,84 @@ func (s *SQLiteStore) ForumAddPost(cmd model.ForumAddPostCommand) (*model.ForumT
 		now = time.Now()
 	}
 	nowRFC3339 := now.Format(time.RFC3339)
-	payload, err := json.Marshal(map[string]any{"body": cmd.Body})
+	payloadFields := map[string]any{"body": cmd.Body}
+	if len(cmd.Attachments) > 0 {
+		payloadFields["attachments"] = cmd.Attachments
+	}
+	payload, err := json.Marshal(payloadFields)
 	if err != nil {
 		return nil, fmt.Errorf("marshal forum post payload: %w", err)
 	}
 
 	postID := cmd.Envelope.EventID + ".post"
-	sql := fmt.Sprintf(
-		`BEGIN IMMEDIATE;
-INSERT INTO forum_posts
+	attachmentInserts, err := forumAttachmentInserts(postID, cmd.Envelope.ThreadID, cmd.Attachments)
+	if err != nil {
+		return nil, err
+	}
+	statements := []sqlStatement{
+		statement(
+			`INSERT INTO forum_posts
   (post_id, thread_id, event_id, author_type, author_name, body_text, created_at)
 VALUES
-  (%s, %s, %s, %s, %s, %s, %s);
-INSERT INTO forum_events
+  (?, ?, ?, ?, ?, ?, ?);`,
+			postID,
+			cmd.Envelope.ThreadID,
+			cmd.Envelope.EventID,
+			string(cmd.Envelope.ActorType),
+			cmd.Envelope.ActorName,
+			cmd.Body,
+			nowRFC3339,
+		),
+		statement(
+			`INSERT INTO forum_events
   (event_id, event_type, event_version, occurred_at, thread_id, run_id, ticket, agent_name, actor_type, actor_name, correlation_id, causation_id, payload_json)
 VALUES
-  (%s, %s, %d, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s);
-UPDATE forum_threads
-SET updated_at=%s
-WHERE thread_id=%s;
-UPDATE forum_thread_views
+  (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`,
+			cmd.Envelope.EventID,
+			cmd.Envelope.EventType,
+			cmd.Envelope.EventVersion,
+			nowRFC3339,
+			cmd.Envelope.ThreadID,
+			cmd.Envelope.RunID,
+			thread.Ticket,
+			thread.AgentName,
+			string(cmd.Envelope.ActorType),
+			cmd.Envelope.ActorName,
+			cmd.Envelope.CorrelationID,
+			cmd.Envelope.CausationID,
+			string(payload),
+		),
+		statement(
+			`UPDATE forum_threads
+SET updated_at=?
+WHERE thread_id=?;`,
+			nowRFC3339,
+			cmd.Envelope.ThreadID,
+		),
+		statement(
+			`UPDATE forum_thread_views
 SET posts_count=posts_count+1,
-    last_post_at=%s,
-    last_post_by_type=%s,
-    last_post_by_name=%s,
-    updated_at=%s
-WHERE thread_id=%s;
-COMMIT;`,
-		quote(postID),
-		quote(cmd.Envelope.ThreadID),
-		quote(cmd.Envelope.EventID),
-		quote(string(cmd.Envelope.ActorType)),
-		quote(cmd.Envelope.ActorName),
-		quote(cmd.Body),
-		quote(nowRFC3339),
-		quote(cmd.Envelope.EventID),
-		quote(cmd.Envelope.EventType),
-		cmd.Envelope.EventVersion,
-		quote(nowRFC3339),
-		quote(cmd.Envelope.ThreadID),
-		quote(cmd.Envelope.RunID),
-		quote(thread.Ticket),
-		quote(thread.AgentName),
-		quote(string(cmd.Envelope.ActorType)),
-		quote(cmd.Envelope.ActorName),
-		quote(cmd.Envelope.CorrelationID),
-		quote(cmd.Envelope.CausationID),
-		quote(string(payload)),
-		quote(nowRFC3339),
-		quote(cmd.Envelope.ThreadID),
-		quote(nowRFC3339),
-		quote(string(cmd.Envelope.ActorType)),
-		quote(cmd.Envelope.ActorName),
-		quote(nowRFC3339),
-		quote(cmd.Envelope.ThreadID),
-	)
-	if err := s.execSQL(sql); err != nil {
+    last_post_at=?,
+    last_post_by_type=?,
+    last_post_by_name=?,
+    updated_at=?
+WHERE thread_id=?;`,
+			nowRFC3339,
+			string(cmd.Envelope.ActorType),
+			cmd.Envelope.ActorName,
+			nowRFC3339,
+			cmd.Envelope.ThreadID,
+		),
+	}
+	if err := s.execTx(append(statements, attachmentInserts...)...); err != nil {
 		return nil, err
 	}
 	if err := s.refreshForumThreadQueueView(cmd.Envelope.ThreadID); err != nil {
 		return nil, err
 	}
+	if err := s.refreshForumSearchDocuments(cmd.Envelope.ThreadID); err != nil {
+		return nil, err
+	}
 	return s.GetForumThread(cmd.Envelope.ThreadID)
 }
 
@@ -223,59 +257,64 @@ func (s *SQLiteStore) ForumAssignThread(cmd model.ForumAssignThreadCommand) (*mo
 		return nil, fmt.Errorf("marshal forum assign payload: %w", err)
 	}
 
-	sql := fmt.Sprintf(
-		`BEGIN IMMEDIATE;
-INSERT INTO forum_assignments
+	if err := s.execTx(
+		statement(
+			`INSERT INTO forum_assignments
   (thread_id, event_id, from_assignee_type, from_assignee_name, to_assignee_type, to_assignee_name, changed_by_type, changed_by_name, changed_at)
 VALUES
-  (%s, %s, %s, %s, %s, %s, %s, %s, %s);
-INSERT INTO forum_events
+  (?, ?, ?, ?, ?, ?, ?, ?, ?);`,
+			cmd.Envelope.ThreadID,
+			cmd.Envelope.EventID,
+			string(thread.AssigneeType),
+			thread.AssigneeName,
+			string(cmd.AssigneeType),
+			cmd.AssigneeName,
+			string(cmd.Envelope.ActorType),
+			cmd.Envelope.ActorName,
+			nowRFC3339,
+		),
+		statement(
+			`INSERT INTO forum_events
   (event_id, event_type, event_version, occurred_at, thread_id, run_id, ticket, agent_name, actor_type, actor_name, correlation_id, causation_id, payload_json)
 VALUES
-  (%s, %s, %d, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s);
-UPDATE forum_threads
-SET assignee_type=%s,
-    assignee_name=%s,
-    updated_at=%s
-WHERE thread_id=%s;
-UPDATE forum_thread_views
-SET assignee_type=%s,
-    assignee_name=%s,
-    updated_at=%s
-WHERE thread_id=%s;
-COMMIT;`,
-		quote(cmd.Envelope.ThreadID),
-		quote(cmd.Envelope.EventID),
-		quote(string(thread.AssigneeType)),
-		quote(thread.AssigneeName),
-		quote(string(cmd.AssigneeType)),
-		quote(cmd.AssigneeName),
-		quote(string(cmd.Envelope.ActorType)),
-		quote(cmd.Envelope.ActorName),
-		quote(nowRFC3339),
-		quote(cmd.Envelope.EventID),
-		quote(cmd.Envelope.EventType),
-		cmd.Envelope.EventVersion,
-		quote(nowRFC3339),
-		quote(cmd.Envelope.ThreadID),
-		quote(thread.RunID),
-		quote(thread.Ticket),
-		quote(thread.AgentName),
-		quote(string(cmd.Envelope.ActorType)),
-		quote(cmd.Envelope.ActorName),
-		quote(cmd.Envelope.CorrelationID),
-		quote(cmd.Envelope.CausationID),
-		quote(string(payload)),
-		quote(string(cmd.AssigneeType)),
-		quote(cmd.AssigneeName),
-		quote(nowRFC3339),
-		quote(cmd.Envelope.ThreadID),
-		quote(string(cmd.AssigneeType)),
-		quote(cmd.AssigneeName),
-		quote(nowRFC3339),
-		quote(cmd.Envelope.ThreadID),
-	)
-	if err := s.execSQL(sql); err != nil {
+  (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`,
+			cmd.Envelope.EventID,
+			cmd.Envelope.EventType,
+			cmd.Envelope.EventVersion,
+			nowRFC3339,
+			cmd.Envelope.ThreadID,
+			thread.RunID,
+			thread.Ticket,
+			thread.AgentName,
+			string(cmd.Envelope.ActorType),
+			cmd.Envelope.ActorName,
+			cmd.Envelope.CorrelationID,
+			cmd.Envelope.CausationID,
+			string(payload),
+		),
+		statement(
+			`UPDATE forum_threads
+SET assignee_type=?,
+    assignee_name=?,
+    updated_at=?
+WHERE thread_id=?;`,
+			string(cmd.AssigneeType),
+			cmd.AssigneeName,
+			nowRFC3339,
+			cmd.Envelope.ThreadID,
+		),
+		statement(
+			`UPDATE forum_thread_views
+SET assignee_type=?,
+    assignee_name=?,
+    updated_at=?
+WHERE thread_id=?;`,
+			string(cmd.AssigneeType),
+			cmd.AssigneeName,
+			nowRFC3339,
+			cmd.Envelope.ThreadID,
+		),
+	); err != nil {
 		return nil, err
 	}
 	if err := s.refreshForumThreadQueueView(cmd.Envelope.ThreadID); err != nil {
@@ -329,57 +368,62 @@ func (s *SQLiteStore) forumChangeState(envelope model.ForumEnvelope, toState mod
 		return nil, fmt.Errorf("marshal forum state payload: %w", err)
 	}
 
-	sql := fmt.Sprintf(
-		`BEGIN IMMEDIATE;
-INSERT INTO forum_state_transitions
+	if err := s.execTx(
+		statement(
+			`INSERT INTO forum_state_transitions
   (thread_id, event_id, from_state, to_state, changed_by_type, changed_by_name, changed_at)
 VALUES
-  (%s, %s, %s, %s, %s, %s, %s);
-INSERT INTO forum_events
+  (?, ?, ?, ?, ?, ?, ?);`,
+			envelope.ThreadID,
+			envelope.EventID,
+			string(thread.State),
+			string(toState),
+			string(envelope.ActorType),
+			envelope.ActorName,
+			nowRFC3339,
+		),
+		statement(
+			`INSERT INTO forum_events
   (event_id, event_type, event_version, occurred_at, thread_id, run_id, ticket, agent_name, actor_type, actor_name, correlation_id, causation_id, payload_json)
 VALUES
-  (%s, %s, %d, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s);
-UPDATE forum_threads
-SET state=%s,
-    updated_at=%s,
-    closed_at=%s
-WHERE thread_id=%s;
-UPDATE forum_thread_views
-SET state=%s,
-    updated_at=%s,
-    closed_at=%s
-WHERE thread_id=%s;
-COMMIT;`,
-		quote(envelope.ThreadID),
-		quote(envelope.EventID),
-		quote(string(thread.State)),
-		quote(string(toState)),
-		quote(string(envelope.ActorType)),
-		quote(envelope.ActorName),
-		quote(nowRFC3339),
-		quote(envelope.EventID),
-		quote(eventType),
-		envelope.EventVersion,
-		quote(nowRFC3339),
-		quote(envelope.ThreadID),
-		quote(thread.RunID),
-		quote(thread.Ticket),
-		quote(thread.AgentName),
-		quote(string(envelope.ActorType)),
-		quote(envelope.ActorName),
-		quote(envelope.CorrelationID),
-		quote(envelope.CausationID),
-		quote(string(payload)),
-		quote(string(toState)),
-		quote(nowRFC3339),
-		quote(closedAt),
-		quote(envelope.ThreadID),
-		quote(string(toState)),
-		quote(nowRFC3339),
-		quote(closedAt),
-		quote(envelope.ThreadID),
-	)
-	if err := s.execSQL(sql); err != nil {
+  (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`,
+			envelope.EventID,
+			eventType,
+			envelope.EventVersion,
+			nowRFC3339,
+			envelope.ThreadID,
+			thread.RunID,
+			thread.Ticket,
+			thread.AgentName,
+			string(envelope.ActorType),
+			envelope.ActorName,
+			envelope.CorrelationID,
+			envelope.CausationID,
+			string(payload),
+		),
+		statement(
+			`UPDATE forum_threads
+SET state=?,
+    updated_at=?,
+    closed_at=?
+WHERE thread_id=?;`,
+			string(toState),
+			nowRFC3339,
+			closedAt,
+			envelope.ThreadID,
+		),
+		statement(
+			`UPDATE forum_thread_views
+SET state=?,
+    updated_at=?,
+    closed_at=?
+WHERE thread_id=?;`,
+			string(toState),
+			nowRFC3339,
+			closedAt,
+			envelope.ThreadID,
+		),
+	); err != nil {
 		return nil, err
 	}
 	if err := s.refreshForumThreadStats(thread.Ticket); err != nil {
@@ -417,42 +461,170 @@ func (s *SQLiteStore) ForumSetPriority(cmd model.ForumSetPriorityCommand) (*mode
 		return nil, fmt.Errorf("marshal forum priority payload: %w", err)
 	}
 
-	sql := fmt.Sprintf(
-		`BEGIN IMMEDIATE;
-INSERT INTO forum_events
+	if err := s.execTx(
+		statement(
+			`INSERT INTO forum_events
   (event_id, event_type, event_version, occurred_at, thread_id, run_id, ticket, agent_name, actor_type, actor_name, correlation_id, causation_id, payload_json)
 VALUES
-  (%s, %s, %d, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s);
-UPDATE forum_threads
-SET priority=%s,
-    updated_at=%s
-WHERE thread_id=%s;
-UPDATE forum_thread_views
-SET priority=%s,
-    updated_at=%s
-WHERE thread_id=%s;
-COMMIT;`,
-		quote(cmd.Envelope.EventID),
-		quote(cmd.Envelope.EventType),
-		cmd.Envelope.EventVersion,
-		quote(nowRFC3339),
-		quote(cmd.Envelope.ThreadID),
-		quote(thread.RunID),
-		quote(thread.Ticket),
-		quote(thread.AgentName),
-		quote(string(cmd.Envelope.ActorType)),
-		quote(cmd.Envelope.ActorName),
-		quote(cmd.Envelope.CorrelationID),
-		quote(cmd.Envelope.CausationID),
-		quote(string(payload)),
-		quote(string(cmd.Priority)),
-		quote(nowRFC3339),
-		quote(cmd.Envelope.ThreadID),
-		quote(string(cmd.Priority)),
-		quote(nowRFC3339),
-		quote(cmd.Envelope.ThreadID),
-	)
-	if err := s.execSQL(sql); err != nil {
+  (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`,
+			cmd.Envelope.EventID,
+			cmd.Envelope.EventType,
+			cmd.Envelope.EventVersion,
+			nowRFC3339,
+			cmd.Envelope.ThreadID,
+			thread.RunID,
+			thread.Ticket,
+			thread.AgentName,
+			string(cmd.Envelope.ActorType),
+			cmd.Envelope.ActorName,
+			cmd.Envelope.CorrelationID,
+			cmd.Envelope.CausationID,
+			string(payload),
+		),
+		statement(
+			`UPDATE forum_threads
+SET priority=?,
+    updated_at=?
+WHERE thread_id=?;`,
+			string(cmd.Priority),
+			nowRFC3339,
+			cmd.Envelope.ThreadID,
+		),
+		statement(
+			`UPDATE forum_thread_views
+SET priority=?,
+    updated_at=?
+WHERE thread_id=?;`,
+			string(cmd.Priority),
+			nowRFC3339,
+			cmd.Envelope.ThreadID,
+		),
+	); err != nil {
+		return nil, err
+	}
+	if err := s.refreshForumThreadStats(thread.Ticket); err != nil {
+		return nil, err
+	}
+	if err := s.refreshForumThreadQueueView(cmd.Envelope.ThreadID); err != nil {
+		return nil, err
+	}
+	return s.GetForumThread(cmd.Envelope.ThreadID)
+}
+
+// ForumEscalateThread records an SLA escalation: the priority bump and any
+// reassignment land on the thread, the escalation is kept in
+// forum_state_transitions, and a forum.thread_escalated event is appended.
+func (s *SQLiteStore) ForumEscalateThread(cmd model.ForumEscalateThreadCommand) (*model.ForumThreadView, error) {
+	if ok, err := s.forumEventExists(cmd.Envelope.EventID); err != nil {
+		return nil, err
+	} else if ok {
+		return s.GetForumThread(cmd.Envelope.ThreadID)
+	}
+	thread, err := s.GetForumThread(cmd.Envelope.ThreadID)
+	if err != nil {
+		return nil, err
+	}
+	if thread == nil {
+		return nil, fmt.Errorf("forum thread %s not found", cmd.Envelope.ThreadID)
+	}
+	now := cmd.Envelope.OccurredAt
+	if now.IsZero() {
+		now = time.Now()
+	}
+	nowRFC3339 := now.Format(time.RFC3339)
+	priority := cmd.Priority
+	if strings.TrimSpace(string(priority)) == "" {
+		priority = thread.Priority
+	}
+	assigneeType := cmd.AssigneeType
+	assigneeName := strings.TrimSpace(cmd.AssigneeName)
+	if strings.TrimSpace(string(assigneeType)) == "" {
+		assigneeType = thread.AssigneeType
+		assigneeName = thread.AssigneeName
+	}
+	payload, err := json.Marshal(map[string]any{
+		"level":              cmd.Level,
+		"state":              thread.State,
+		"from_priority":      thread.Priority,
+		"to_priority":        priority,
+		"from_assignee_type": thread.AssigneeType,
+		"from_assignee_name": thread.AssigneeName,
+		"to_assignee_type":   assigneeType,
+		"to_assignee_name":   assigneeName,
+		"reason":             cmd.Reason,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal forum escalation payload: %w", err)
+	}
+
+	if err := s.execTx(
+		statement(
+			`INSERT INTO forum_state_transitions
+  (thread_id, event_id, from_state, to_state, changed_by_type, changed_by_name, changed_at, kind, escalation_level, from_priority, to_priority, from_assignee_type, from_assignee_name, to_assignee_type, to_assignee_name, reason)
+VALUES
+  (?, ?, ?, ?, ?, ?, ?, 'escalation', ?, ?, ?, ?, ?, ?, ?, ?);`,
+			cmd.Envelope.ThreadID,
+			cmd.Envelope.EventID,
+			string(thread.State),
+			string(thread.State),
+			string(cmd.Envelope.ActorType),
+			cmd.Envelope.ActorName,
+			nowRFC3339,
+			cmd.Level,
+			string(thread.Priority),
+			string(priority),
+			string(thread.AssigneeType),
+			thread.AssigneeName,
+			string(assigneeType),
+			assigneeName,
+			cmd.Reason,
+		),
+		statement(
+			`INSERT INTO forum_events
+  (event_id, event_type, event_version, occurred_at, thread_id, run_id, ticket, agent_name, actor_type, actor_name, correlation_id, causation_id, payload_json)
+VALUES
+  (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`,
+			cmd.Envelope.EventID,
+			cmd.Envelope.EventType,
+			cmd.Envelope.EventVersion,
+			nowRFC3339,
+			cmd.Envelope.ThreadID,
+			thread.RunID,
+			thread.Ticket,
+			thread.AgentName,
+			string(cmd.Envelope.ActorType),
+			cmd.Envelope.ActorName,
+			cmd.Envelope.CorrelationID,
+			cmd.Envelope.CausationID,
+			string(payload),
+		),
+		statement(
+			`UPDATE forum_threads
+SET priority=?,
+    assignee_type=?,
+    assignee_name=?,
+    updated_at=?
+WHERE thread_id=?;`,
+			string(priority),
+			string(assigneeType),
+			assigneeName,
+			nowRFC3339,
+			cmd.Envelope.ThreadID,
+		),
+		statement(
+			`UPDATE forum_thread_views
+SET priority=?,
+    assignee_type=?,
+    assignee_name=?,
+    updated_at=?
+WHERE thread_id=?;`,
+			string(priority),
+			string(assigneeType),
+			assigneeName,
+			nowRFC3339,
+			cmd.Envelope.ThreadID,
+		),
+	); err != nil {
 		return nil, err
 	}
 	if err := s.refreshForumThreadStats(thread.Ticket); err != nil {
@@ -464,8 +636,67 @@ COMMIT;`,
 	return s.GetForumThread(cmd.Envelope.ThreadID)
 }
 
+// ForumEscalationLevel counts the escalations recorded for a thread since it
+// was last answered, so a thread that was answered and then stalls again
+// starts back at the top of its escalation chain. Moving between waiting
+// states does not reset the level.
+func (s *SQLiteStore) ForumEscalationLevel(threadID string) (int, error) {
+	rows, err := s.queryJSON(
+		`SELECT COUNT(*) AS level
+FROM forum_state_transitions
+WHERE thread_id=? AND kind='escalation'
+  AND id > COALESCE((SELECT MAX(id) FROM forum_state_transitions WHERE thread_id=? AND kind='state' AND to_state='answered'), 0);`,
+		strings.TrimSpace(threadID),
+		strings.TrimSpace(threadID),
+	)
+	if err != nil {
+		return 0, err
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	return asInt(rows[0]["level"]), nil
+}
+
+func (s *SQLiteStore) ListForumEscalations(threadID string) ([]model.ForumEscalation, error) {
+	rows, err := s.queryJSON(
+		`SELECT thread_id, event_id, to_state, escalation_level, from_priority, to_priority, from_assignee_type, from_assignee_name, to_assignee_type, to_assignee_name, reason, changed_by_type, changed_by_name, changed_at
+FROM forum_state_transitions
+WHERE thread_id=? AND kind='escalation'
+ORDER BY id;`,
+		strings.TrimSpace(threadID),
+	)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]model.ForumEscalation, 0, len(rows))
+	for _, row := range rows {
+		escalatedAt, err := time.Parse(time.RFC3339, asString(row["changed_at"]))
+		if err != nil {
+			return nil, fmt.Errorf("parse forum escalation changed_at: %w", err)
+		}
+		out = append(out, model.ForumEscalation{
+			ThreadID:         asString(row["thread_id"]),
+			EventID:          asString(row["event_id"]),
+			State:            model.ForumThreadState(asString(row["to_state"])),
+			Level:            asInt(row["escalation_level"]),
+			FromPriority:     model.ForumPriority(asString(row["from_priority"])),
+			ToPriority:       model.ForumPriority(asString(row["to_priority"])),
+			FromAssigneeType: model.ForumActorType(asString(row["from_assignee_type"])),
+			FromAssigneeName: asString(row["from_assignee_name"]),
+			ToAssigneeType:   model.ForumActorType(asString(row["to_assignee_type"])),
+			ToAssigneeName:   asString(row["to_assignee_name"]),
+			Reason:           asString(row["reason"]),
+			ChangedByType:    model.ForumActorType(asString(row["changed_by_type"])),
+			ChangedByName:    asString(row["changed_by_name"]),
+			EscalatedAt:      escalatedAt,
+		})
+	}
+	return out, nil
+}
+
 func (s *SQLiteStore) GetForumThread(threadID string) (*model.ForumThreadView, error) {
-	sql := fmt.Sprintf(
+	rows, err := s.queryJSON(
 		`SELECT
   v.thread_id,
   v.ticket,
@@ -489,10 +720,9 @@ func (s *SQLiteStore) GetForumThread(threadID string) (*model.ForumThreadView, e
   COALESCE(q.last_non_system_actor_type, '') AS last_actor_type
 FROM forum_thread_views v
 LEFT JOIN forum_thread_queue_view q ON q.thread_id = v.thread_id
-WHERE v.thread_id=%s;`,
-		quote(threadID),
+WHERE v.thread_id=?;`,
+		threadID,
 	)
-	rows, err := s.queryJSON(sql)
 	if err != nil {
 		return nil, err
 	}
@@ -506,22 +736,80 @@ WHERE v.thread_id=%s;`,
 	return &view, nil
 }
 
+// ListForumThreadsAfter pages through every thread in state ordered by thread
+// id, starting after afterThreadID. Unlike ListForumThreads it is stable while
+// the listed threads change, so sweeps can walk the whole set.
+func (s *SQLiteStore) ListForumThreadsAfter(state model.ForumThreadState, afterThreadID string, limit int) ([]model.ForumThreadView, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	rows, err := s.queryJSON(
+		`SELECT
+  v.thread_id,
+  v.ticket,
+  v.run_id,
+  v.agent_name,
+  v.title,
+  v.state,
+  v.priority,
+  v.assignee_type,
+  v.assignee_name,
+  v.opened_by_type,
+  v.opened_by_name,
+  v.posts_count,
+  v.last_post_at,
+  v.last_post_by_type,
+  v.last_post_by_name,
+  v.opened_at,
+  v.updated_at,
+  v.closed_at,
+  COALESCE(q.last_event_sequence, 0) AS last_event_sequence,
+  COALESCE(q.last_non_system_actor_type, '') AS last_actor_type
+FROM forum_thread_views v
+LEFT JOIN forum_thread_queue_view q ON q.thread_id = v.thread_id
+WHERE v.state=? AND v.thread_id > ?
+ORDER BY v.thread_id
+LIMIT ?;`,
+		string(state),
+		afterThreadID,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]model.ForumThreadView, 0, len(rows))
+	for _, row := range rows {
+		view, err := parseForumThreadView(row)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, view)
+	}
+	return out, nil
+}
+
 func (s *SQLiteStore) ListForumThreads(filter model.ForumThreadFilter) ([]model.ForumThreadView, error) {
 	clauses := []string{"1=1"}
+	args := []any{}
 	if v := strings.TrimSpace(filter.Ticket); v != "" {
-		clauses = append(clauses, fmt.Sprintf("v.ticket=%s", quote(v)))
+		clauses = append(clauses, "v.ticket=?")
+		args = append(args, v)
 	}
 	if v := strings.TrimSpace(filter.RunID); v != "" {
-		clauses = append(clauses, fmt.Sprintf("v.run_id=%s", quote(v)))
+		clauses = append(clauses, "v.run_id=?")
+		args = append(args, v)
 	}
 	if v := strings.TrimSpace(string(filter.State)); v != "" {
-		clauses = append(clauses, fmt.Sprintf("v.state=%s", quote(v)))
+		clauses = append(clauses, "v.state=?")
+		args = append(args, v)
 	}
 	if v := strings.TrimSpace(string(filter.Priority)); v != "" {
-		clauses = append(clauses, fmt.Sprintf("v.priority=%s", quote(v)))
+		clauses = append(clauses, "v.priority=?")
+		args = append(args, v)
 	}
 	if v := strings.TrimSpace(filter.Assignee); v != "" {
-		clauses = append(clauses, fmt.Sprintf("v.assignee_name=%s", quote(v)))
+		clauses = append(clauses, "v.assignee_name=?")
+		args = append(args, v)
 	}
 	limit := filter.Limit
 	if limit <= 0 {
@@ -562,11 +850,10 @@ ORDER BY
   END,
   COALESCE(q.last_event_sequence, 0) DESC,
   v.updated_at DESC
-LIMIT %d;`,
+LIMIT ?;`,
 		strings.Join(clauses, " AND "),
-		limit,
 	)
-	rows, err := s.queryJSON(sql)
+	rows, err := s.queryJSON(sql, append(args, limit)...)
 	if err != nil {
 		return nil, err
 	}
@@ -583,28 +870,36 @@ LIMIT %d;`,
 
 func (s *SQLiteStore) SearchForumThreads(filter model.ForumThreadSearchFilter) ([]model.ForumThreadView, error) {
 	clauses := []string{"1=1"}
+	whereArgs := []any{}
 	if v := strings.TrimSpace(filter.Ticket); v != "" {
-		clauses = append(clauses, fmt.Sprintf("v.ticket=%s", quote(v)))
+		clauses = append(clauses, "v.ticket=?")
+		whereArgs = append(whereArgs, v)
 	}
 	if v := strings.TrimSpace(filter.RunID); v != "" {
-		clauses = append(clauses, fmt.Sprintf("v.run_id=%s", quote(v)))
+		clauses = append(clauses, "v.run_id=?")
+		whereArgs = append(whereArgs, v)
 	}
 	if v := strings.TrimSpace(string(filter.State)); v != "" {
-		clauses = append(clauses, fmt.Sprintf("v.state=%s", quote(v)))
+		clauses = append(clauses, "v.state=?")
+		whereArgs = append(whereArgs, v)
 	}
 	if v := strings.TrimSpace(string(filter.Priority)); v != "" {
-		clauses = append(clauses, fmt.Sprintf("v.priority=%s", quote(v)))
+		clauses = append(clauses, "v.priority=?")
+		whereArgs = append(whereArgs, v)
 	}
 	if v := strings.TrimSpace(filter.Assignee); v != "" {
-		clauses = append(clauses, fmt.Sprintf("v.assignee_name=%s", quote(v)))
+		clauses = append(clauses, "v.assignee_name=?")
+		whereArgs = append(whereArgs, v)
 	}
 	if filter.Cursor > 0 {
-		clauses = append(clauses, fmt.Sprintf("COALESCE(q.last_event_sequence, 0) < %d", filter.Cursor))
+		clauses = append(clauses, "COALESCE(q.last_event_sequence, 0) < ?")
+		whereArgs = append(whereArgs, filter.Cursor)
 	}
 
 	viewerType := strings.TrimSpace(string(filter.ViewerType))
 	viewerID := strings.TrimSpace(filter.ViewerID)
 	viewerJoin := "LEFT JOIN forum_thread_reads r ON 1=0"
+	joinArgs := []any{}
 	if viewerType != "" || viewerID != "" {
 		normalizedViewerType, err := normalizeForumViewerType(filter.ViewerType)
 		if err != nil {
@@ -613,28 +908,61 @@ func (s *SQLiteStore) SearchForumThreads(filter model.ForumThreadSearchFilter) (
 		if viewerID == "" {
 			return nil, fmt.Errorf("forum viewer_id is required")
 		}
-		viewerJoin = fmt.Sprintf(
-			"LEFT JOIN forum_thread_reads r ON r.thread_id = v.thread_id AND r.viewer_type = %s AND r.viewer_id = %s",
-			quote(string(normalizedViewerType)),
-			quote(viewerID),
-		)
+		viewerJoin = "LEFT JOIN forum_thread_reads r ON r.thread_id = v.thread_id AND r.viewer_type = ? AND r.viewer_id = ?"
+		joinArgs = append(joinArgs, string(normalizedViewerType), viewerID)
 	}
 
-	query := strings.ToLower(strings.TrimSpace(filter.Query))
+	searchCTE := ""
+	searchJoin := ""
+	searchColumns := ""
 	relevanceOrder := ""
-	if query != "" {
-		likePattern := "%" + query + "%"
-		queryPredicate := fmt.Sprintf(
-			"(LOWER(v.title) LIKE %s OR EXISTS (SELECT 1 FROM forum_posts p WHERE p.thread_id = v.thread_id AND LOWER(p.body_text) LIKE %s))",
-			quote(likePattern),
-			quote(likePattern),
-		)
-		relevanceOrder = fmt.Sprintf(
-			"(CASE WHEN LOWER(v.title) LIKE %s THEN 2 ELSE 0 END + CASE WHEN EXISTS (SELECT 1 FROM forum_posts p WHERE p.thread_id = v.thread_id AND LOWER(p.body_text) LIKE %s) THEN 1 ELSE 0 END) DESC,",
-			quote(likePattern),
-			quote(likePattern),
+	searchArgs := []any{}
+	actorClauses, actorArgs := forumSearchActorClauses(filter)
+	if query := strings.TrimSpace(filter.Query); query != "" {
+		match := forumSearchMatchExpression(query)
+		if match == "" {
+			return []model.ForumThreadView{}, nil
+		}
+		hitClauses := append([]string{"forum_search_index MATCH ?"}, actorClauses...)
+		searchArgs = append(append(searchArgs, match), actorArgs...)
+		searchCTE = fmt.Sprintf(
+			`WITH search_hits AS (
+  SELECT
+    d.thread_id,
+    d.doc_kind,
+    d.actor_type,
+    d.actor_name,
+    bm25(forum_search_index, 4.0, 1.0) AS rank,
+    snippet(forum_search_index, -1, '<mark>', '</mark>', '…', 16) AS snippet
+  FROM forum_search_index
+  JOIN forum_search_documents d ON d.doc_rowid = forum_search_index.rowid
+  WHERE %s
+),
+search_best AS (
+  SELECT
+    *,
+    ROW_NUMBER() OVER (PARTITION BY thread_id ORDER BY rank) AS match_row,
+    COUNT(*) OVER (PARTITION BY thread_id) AS hits
+  FROM search_hits
+)
+`,
+			strings.Join(hitClauses, " AND "),
 		)
-		clauses = append(clauses, queryPredicate)
+		searchJoin = "JOIN search_best b ON b.thread_id = v.thread_id AND b.match_row = 1"
+		searchColumns = `,
+  b.rank AS match_rank,
+  b.hits AS match_hits,
+  b.doc_kind AS match_doc_kind,
+  b.actor_type AS match_actor_type,
+  b.actor_name AS match_actor_name,
+  b.snippet AS match_snippet`
+		relevanceOrder = "b.rank,"
+	} else if len(actorClauses) > 0 {
+		clauses = append(clauses, fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM forum_search_documents d WHERE d.thread_id = v.thread_id AND %s)",
+			strings.Join(actorClauses, " AND "),
+		))
+		whereArgs = append(whereArgs, actorArgs...)
 	}
 
 	unansweredExpr := "CASE WHEN v.state IN ('new', 'waiting_human', 'waiting_operator') AND COALESCE(q.last_agent_sequence, 0) > COALESCE(q.last_human_or_operator_sequence, 0) AND COALESCE(q.last_non_system_actor_type, '') = 'agent' THEN 1 ELSE 0 END"
@@ -645,7 +973,7 @@ func (s *SQLiteStore) SearchForumThreads(filter model.ForumThreadSearchFilter) (
 	}
 
 	sql := fmt.Sprintf(
-		`SELECT
+		`%sSELECT
   v.thread_id,
   v.ticket,
   v.run_id,
@@ -667,10 +995,11 @@ func (s *SQLiteStore) SearchForumThreads(filter model.ForumThreadSearchFilter) (
   COALESCE(q.last_event_sequence, 0) AS last_event_sequence,
   COALESCE(q.last_non_system_actor_type, '') AS last_actor_type,
   %s AS is_unseen,
-  %s AS is_unanswered
+  %s AS is_unanswered%s
 FROM forum_thread_views v
 LEFT JOIN forum_thread_queue_view q ON q.thread_id = v.thread_id
 %s
+%s
 WHERE %s
 ORDER BY
   %s
@@ -683,16 +1012,18 @@ ORDER BY
   END,
   COALESCE(q.last_event_sequence, 0) DESC,
   v.updated_at DESC
-LIMIT %d;`,
+LIMIT ?;`,
+		searchCTE,
 		seenExpr,
 		unansweredExpr,
+		searchColumns,
 		viewerJoin,
+		searchJoin,
 		strings.Join(clauses, " AND "),
 		relevanceOrder,
-		limit,
 	)
-
-	rows, err := s.queryJSON(sql)
+	args := append(append(append(searchArgs, joinArgs...), whereArgs...), limit)
+	rows, err := s.queryJSON(sql, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -702,6 +1033,7 @@ LIMIT %d;`,
 		if err != nil {
 			return nil, err
 		}
+		view.Match = parseForumSearchMatch(row)
 		out = append(out, view)
 	}
 	return out, nil
@@ -714,28 +1046,36 @@ func (s *SQLiteStore) ListForumQueue(filter model.ForumQueueFilter) ([]model.For
 	}
 
 	clauses := []string{"1=1"}
+	whereArgs := []any{}
 	if v := strings.TrimSpace(filter.Ticket); v != "" {
-		clauses = append(clauses, fmt.Sprintf("v.ticket=%s", quote(v)))
+		clauses = append(clauses, "v.ticket=?")
+		whereArgs = append(whereArgs, v)
 	}
 	if v := strings.TrimSpace(filter.RunID); v != "" {
-		clauses = append(clauses, fmt.Sprintf("v.run_id=%s", quote(v)))
+		clauses = append(clauses, "v.run_id=?")
+		whereArgs = append(whereArgs, v)
 	}
 	if v := strings.TrimSpace(string(filter.State)); v != "" {
-		clauses = append(clauses, fmt.Sprintf("v.state=%s", quote(v)))
+		clauses = append(clauses, "v.state=?")
+		whereArgs = append(whereArgs, v)
 	}
 	if v := strings.TrimSpace(string(filter.Priority)); v != "" {
-		clauses = append(clauses, fmt.Sprintf("v.priority=%s", quote(v)))
+		clauses = append(clauses, "v.priority=?")
+		whereArgs = append(whereArgs, v)
 	}
 	if v := strings.TrimSpace(filter.Assignee); v != "" {
-		clauses = append(clauses, fmt.Sprintf("v.assignee_name=%s", quote(v)))
+		clauses = append(clauses, "v.assignee_name=?")
+		whereArgs = append(whereArgs, v)
 	}
 	if filter.Cursor > 0 {
-		clauses = append(clauses, fmt.Sprintf("COALESCE(q.last_event_sequence, 0) < %d", filter.Cursor))
+		clauses = append(clauses, "COALESCE(q.last_event_sequence, 0) < ?")
+		whereArgs = append(whereArgs, filter.Cursor)
 	}
 
 	viewerType := strings.TrimSpace(string(filter.ViewerType))
 	viewerID := strings.TrimSpace(filter.ViewerID)
 	viewerJoin := "LEFT JOIN forum_thread_reads r ON 1=0"
+	joinArgs := []any{}
 	if queueType == model.ForumQueueUnseen || viewerType != "" || viewerID != "" {
 		normalizedViewerType, err := normalizeForumViewerType(filter.ViewerType)
 		if err != nil {
@@ -744,11 +1084,8 @@ func (s *SQLiteStore) ListForumQueue(filter model.ForumQueueFilter) ([]model.For
 		if viewerID == "" {
 			return nil, fmt.Errorf("forum viewer_id is required")
 		}
-		viewerJoin = fmt.Sprintf(
-			"LEFT JOIN forum_thread_reads r ON r.thread_id = v.thread_id AND r.viewer_type = %s AND r.viewer_id = %s",
-			quote(string(normalizedViewerType)),
-			quote(viewerID),
-		)
+		viewerJoin = "LEFT JOIN forum_thread_reads r ON r.thread_id = v.thread_id AND r.viewer_type = ? AND r.viewer_id = ?"
+		joinArgs = append(joinArgs, string(normalizedViewerType), viewerID)
 	}
 
 	unansweredExpr := "CASE WHEN v.state IN ('new', 'waiting_human', 'waiting_operator') AND COALESCE(q.last_agent_sequence, 0) > COALESCE(q.last_human_or_operator_sequence, 0) AND COALESCE(q.last_non_system_actor_type, '') = 'agent' THEN 1 ELSE 0 END"
@@ -804,14 +1141,14 @@ ORDER BY
   END,
   COALESCE(q.last_event_sequence, 0) DESC,
   v.updated_at DESC
-LIMIT %d;`,
+LIMIT ?;`,
 		seenExpr,
 		unansweredExpr,
 		viewerJoin,
 		strings.Join(clauses, " AND "),
-		limit,
 	)
-	rows, err := s.queryJSON(sql)
+	args := append(append(joinArgs, whereArgs...), limit)
+	rows, err := s.queryJSON(sql, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -854,35 +1191,34 @@ func (s *SQLiteStore) MarkForumThreadSeen(threadID string, viewerType model.Foru
 		lastSeenEventSequence = resolvedSequence
 	}
 	now := time.Now().Format(time.RFC3339)
-	sql := fmt.Sprintf(
+	if err := s.execSQL(
 		`INSERT INTO forum_thread_reads
   (thread_id, viewer_type, viewer_id, last_seen_event_sequence, updated_at)
 VALUES
-  (%s, %s, %s, %d, %s)
+  (?, ?, ?, ?, ?)
 ON CONFLICT(thread_id, viewer_type, viewer_id) DO UPDATE SET
   last_seen_event_sequence=MAX(forum_thread_reads.last_seen_event_sequence, excluded.last_seen_event_sequence),
   updated_at=CASE
     WHEN excluded.last_seen_event_sequence > forum_thread_reads.last_seen_event_sequence THEN excluded.updated_at
     ELSE forum_thread_reads.updated_at
   END;`,
-		quote(threadID),
-		quote(string(normalizedViewerType)),
-		quote(viewerID),
+		threadID,
+		string(normalizedViewerType),
+		viewerID,
 		lastSeenEventSequence,
-		quote(now),
-	)
-	if err := s.execSQL(sql); err != nil {
+		now,
+	); err != nil {
 		return nil, err
 	}
-	rows, err := s.queryJSON(fmt.Sprintf(
+	rows, err := s.queryJSON(
 		`SELECT thread_id, viewer_type, viewer_id, last_seen_event_sequence, updated_at
 FROM forum_thread_reads
-WHERE thread_id=%s AND viewer_type=%s AND viewer_id=%s
+WHERE thread_id=? AND viewer_type=? AND viewer_id=?
 LIMIT 1;`,
-		quote(threadID),
-		quote(string(normalizedViewerType)),
-		quote(viewerID),
-	))
+		threadID,
+		string(normalizedViewerType),
+		viewerID,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -908,25 +1244,25 @@ func (s *SQLiteStore) latestForumThreadEventSequence(threadID string) (int64, er
 	if threadID == "" {
 		return 0, fmt.Errorf("forum thread id is required")
 	}
-	rows, err := s.queryJSON(fmt.Sprintf(
+	rows, err := s.queryJSON(
 		`SELECT COALESCE(last_event_sequence, 0) AS sequence
 FROM forum_thread_queue_view
-WHERE thread_id=%s
+WHERE thread_id=?
 LIMIT 1;`,
-		quote(threadID),
-	))
+		threadID,
+	)
 	if err != nil {
 		return 0, err
 	}
 	if len(rows) > 0 {
 		return int64(asInt(rows[0]["sequence"])), nil
 	}
-	rows, err = s.queryJSON(fmt.Sprintf(
+	rows, err = s.queryJSON(
 		`SELECT COALESCE(MAX(sequence), 0) AS sequence
 FROM forum_events
-WHERE thread_id=%s;`,
-		quote(threadID),
-	))
+WHERE thread_id=?;`,
+		threadID,
+	)
 	if err != nil {
 		return 0, err
 	}
@@ -940,16 +1276,19 @@ func (s *SQLiteStore) ListForumPosts(threadID string, limit int) ([]model.ForumP
 	if limit <= 0 {
 		limit = 200
 	}
-	sql := fmt.Sprintf(
+	rows, err := s.queryJSON(
 		`SELECT post_id, thread_id, event_id, author_type, author_name, body_text, created_at
 FROM forum_posts
-WHERE thread_id=%s
+WHERE thread_id=?
 ORDER BY created_at
-LIMIT %d;`,
-		quote(threadID),
+LIMIT ?;`,
+		threadID,
 		limit,
 	)
-	rows, err := s.queryJSON(sql)
+	if err != nil {
+		return nil, err
+	}
+	attachments, err := s.listForumPostAttachments(threadID)
 	if err != nil {
 		return nil, err
 	}
@@ -959,14 +1298,16 @@ LIMIT %d;`,
 		if err != nil {
 			return nil, fmt.Errorf("parse forum post created_at: %w", err)
 		}
+		postID := asString(row["post_id"])
 		out = append(out, model.ForumPost{
-			PostID:     asString(row["post_id"]),
-			ThreadID:   asString(row["thread_id"]),
-			EventID:    asString(row["event_id"]),
-			AuthorType: model.ForumActorType(asString(row["author_type"])),
-			AuthorName: asString(row["author_name"]),
-			Body:       asString(row["body_text"]),
-			CreatedAt:  createdAt,
+			PostID:      postID,
+			ThreadID:    asString(row["thread_id"]),
+			EventID:     asString(row["event_id"]),
+			AuthorType:  model.ForumActorType(asString(row["author_type"])),
+			AuthorName:  asString(row["author_name"]),
+			Body:        asString(row["body_text"]),
+			Attachments: attachments[postID],
+			CreatedAt:   createdAt,
 		})
 	}
 	return out, nil
@@ -980,16 +1321,15 @@ func (s *SQLiteStore) ListForumThreadEvents(threadID string, limit int) ([]model
 	if limit <= 0 {
 		limit = 200
 	}
-	sql := fmt.Sprintf(
+	rows, err := s.queryJSON(
 		`SELECT sequence, event_id, event_type, event_version, occurred_at, thread_id, run_id, ticket, agent_name, actor_type, actor_name, correlation_id, causation_id, payload_json
 FROM forum_events
-WHERE thread_id=%s
+WHERE thread_id=?
 ORDER BY sequence
-LIMIT %d;`,
-		quote(threadID),
+LIMIT ?;`,
+		threadID,
 		limit,
 	)
-	rows, err := s.queryJSON(sql)
 	if err != nil {
 		return nil, err
 	}
@@ -1022,21 +1362,14 @@ LIMIT %d;`,
 }
 
 func (s *SQLiteStore) ListForumThreadStats(ticket string, runID string) ([]model.ForumThreadStats, error) {
-	clauses := []string{"1=1"}
-	if strings.TrimSpace(ticket) != "" {
-		clauses = append(clauses, fmt.Sprintf("ticket=%s", quote(ticket)))
-	}
-	if strings.TrimSpace(runID) != "" {
-		clauses = append(clauses, fmt.Sprintf("run_id=%s", quote(runID)))
-	}
-	sql := fmt.Sprintf(
+	rows, err := s.queryJSON(
 		`SELECT ticket, run_id, state, priority, thread_count, updated_at
 FROM forum_thread_stats
-WHERE %s
+WHERE (?='' OR ticket=?) AND (?='' OR run_id=?)
 ORDER BY ticket, run_id, state, priority;`,
-		strings.Join(clauses, " AND "),
+		strings.TrimSpace(ticket), ticket,
+		strings.TrimSpace(runID), runID,
 	)
-	rows, err := s.queryJSON(sql)
 	if err != nil {
 		return nil, err
 	}
@@ -1062,20 +1395,16 @@ func (s *SQLiteStore) WatchForumEvents(ticket string, cursor int64, limit int) (
 	if limit <= 0 {
 		limit = 100
 	}
-	clauses := []string{fmt.Sprintf("sequence > %d", cursor)}
-	if strings.TrimSpace(ticket) != "" {
-		clauses = append(clauses, fmt.Sprintf("ticket=%s", quote(ticket)))
-	}
-	sql := fmt.Sprintf(
+	rows, err := s.queryJSON(
 		`SELECT sequence, event_id, event_type, event_version, occurred_at, thread_id, run_id, ticket, agent_name, actor_type, actor_name, correlation_id, causation_id, payload_json
 FROM forum_events
-WHERE %s
+WHERE sequence > ? AND (?='' OR ticket=?)
 ORDER BY sequence
-LIMIT %d;`,
-		strings.Join(clauses, " AND "),
+LIMIT ?;`,
+		cursor,
+		strings.TrimSpace(ticket), ticket,
 		limit,
 	)
-	rows, err := s.queryJSON(sql)
 	if err != nil {
 		return nil, err
 	}
@@ -1107,29 +1436,35 @@ LIMIT %d;`,
 	return out, nil
 }
 
+// LastForumEventSequence returns the sequence of the newest forum event, or 0
+// when none exist.
+func (s *SQLiteStore) LastForumEventSequence() (int64, error) {
+	rows, err := s.queryJSON(`SELECT COALESCE(MAX(sequence), 0) AS sequence FROM forum_events;`)
+	if err != nil {
+		return 0, err
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	return int64(asInt(rows[0]["sequence"])), nil
+}
+
 func (s *SQLiteStore) ListRecentForumEvents(ticket string, runID string, limit int) ([]model.ForumEvent, error) {
 	if limit <= 0 {
 		limit = 100
 	}
-	clauses := []string{"1=1"}
 	ticket = strings.TrimSpace(ticket)
 	runID = strings.TrimSpace(runID)
-	if ticket != "" {
-		clauses = append(clauses, fmt.Sprintf("ticket=%s", quote(ticket)))
-	}
-	if runID != "" {
-		clauses = append(clauses, fmt.Sprintf("run_id=%s", quote(runID)))
-	}
-	sql := fmt.Sprintf(
+	rows, err := s.queryJSON(
 		`SELECT sequence, event_id, event_type, event_version, occurred_at, thread_id, run_id, ticket, agent_name, actor_type, actor_name, correlation_id, causation_id, payload_json
 FROM forum_events
-WHERE %s
+WHERE (?='' OR ticket=?) AND (?='' OR run_id=?)
 ORDER BY sequence DESC
-LIMIT %d;`,
-		strings.Join(clauses, " AND "),
+LIMIT ?;`,
+		ticket, ticket,
+		runID, runID,
 		limit,
 	)
-	rows, err := s.queryJSON(sql)
 	if err != nil {
 		return nil, err
 	}
@@ -1166,14 +1501,13 @@ func (s *SQLiteStore) GetForumEvent(eventID string) (*model.ForumEvent, error) {
 	if eventID == "" {
 		return nil, fmt.Errorf("forum event id is required")
 	}
-	sql := fmt.Sprintf(
+	rows, err := s.queryJSON(
 		`SELECT sequence, event_id, event_type, event_version, occurred_at, thread_id, run_id, ticket, agent_name, actor_type, actor_name, correlation_id, causation_id, payload_json
 FROM forum_events
-WHERE event_id=%s
+WHERE event_id=?
 LIMIT 1;`,
-		quote(eventID),
+		eventID,
 	)
-	rows, err := s.queryJSON(sql)
 	if err != nil {
 		return nil, err
 	}
@@ -1207,6 +1541,10 @@ LIMIT 1;`,
 }
 
 func (s *SQLiteStore) ApplyForumEventProjections(event model.ForumEvent) error {
+	return s.applyForumEventProjections(event, model.ForumProjections())
+}
+
+func (s *SQLiteStore) applyForumEventProjections(event model.ForumEvent, projections []string) error {
 	eventID := strings.TrimSpace(event.Envelope.EventID)
 	threadID := strings.TrimSpace(event.Envelope.ThreadID)
 	ticket := strings.TrimSpace(event.Envelope.Ticket)
@@ -1219,19 +1557,31 @@ func (s *SQLiteStore) ApplyForumEventProjections(event model.ForumEvent) error {
 	if ticket == "" {
 		return fmt.Errorf("forum projection ticket is required")
 	}
-	if err := s.applyForumProjectionEvent("forum_thread_views_v1", eventID, func() error {
-		return s.refreshForumThreadView(threadID)
-	}); err != nil {
-		return err
-	}
-	if err := s.applyForumProjectionEvent("forum_thread_queue_view_v1", eventID, func() error {
-		return s.refreshForumThreadQueueView(threadID)
-	}); err != nil {
-		return err
+	for _, projection := range projections {
+		var refresh func() error
+		switch projection {
+		case model.ForumProjectionThreadViews:
+			refresh = func() error { return s.refreshForumThreadView(threadID) }
+		case model.ForumProjectionQueueView:
+			refresh = func() error { return s.refreshForumThreadQueueView(threadID) }
+		case model.ForumProjectionThreadStats:
+			refresh = func() error { return s.refreshForumThreadStats(ticket) }
+		case model.ForumProjectionSearchDocuments:
+			refresh = func() error { return s.refreshForumSearchDocuments(threadID) }
+		default:
+			return fmt.Errorf("unknown forum projection %q", projection)
+		}
+		if err := s.applyForumProjectionEvent(forumProjectionEventName(projection), eventID, refresh); err != nil {
+			return err
+		}
 	}
-	return s.applyForumProjectionEvent("forum_thread_stats_v1", eventID, func() error {
-		return s.refreshForumThreadStats(ticket)
-	})
+	return nil
+}
+
+// forumProjectionEventName is the forum_projection_events key recording which
+// events a projection has applied.
+func forumProjectionEventName(projection string) string {
+	return projection + "_v1"
 }
 
 func (s *SQLiteStore) ForumAppendIntegrationEvent(envelope model.ForumEnvelope, payload map[string]any) error {
@@ -1257,54 +1607,102 @@ func (s *SQLiteStore) ForumAppendIntegrationEvent(envelope model.ForumEnvelope,
 	if err != nil {
 		return fmt.Errorf("marshal forum integration payload: %w", err)
 	}
-	sql := fmt.Sprintf(
+	return s.execSQL(
 		`INSERT INTO forum_events
   (event_id, event_type, event_version, occurred_at, thread_id, run_id, ticket, agent_name, actor_type, actor_name, correlation_id, causation_id, payload_json)
 VALUES
-  (%s, %s, %d, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s);`,
-		quote(envelope.EventID),
-		quote(envelope.EventType),
+  (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`,
+		envelope.EventID,
+		envelope.EventType,
 		envelope.EventVersion,
-		quote(occurredAt.Format(time.RFC3339)),
-		quote(envelope.ThreadID),
-		quote(envelope.RunID),
-		quote(envelope.Ticket),
-		quote(envelope.AgentName),
-		quote(string(envelope.ActorType)),
-		quote(envelope.ActorName),
-		quote(envelope.CorrelationID),
-		quote(envelope.CausationID),
-		quote(string(payloadJSON)),
+		occurredAt.Format(time.RFC3339),
+		envelope.ThreadID,
+		envelope.RunID,
+		envelope.Ticket,
+		envelope.AgentName,
+		string(envelope.ActorType),
+		envelope.ActorName,
+		envelope.CorrelationID,
+		envelope.CausationID,
+		string(payloadJSON),
+	)
+}
+
+// GetIntegrationRuleReceipt returns the receipt for rule of an integration
+// delivery, or nil when the rule has not been applied.
+func (s *SQLiteStore) GetIntegrationRuleReceipt(eventID string, rule string) (*model.IntegrationRuleReceipt, error) {
+	rows, err := s.queryJSON(
+		`SELECT event_id, rule, action, thread_id, state, applied_at FROM integration_rule_receipts WHERE event_id=? AND rule=?;`,
+		eventID,
+		rule,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	row := rows[0]
+	appliedAt, err := time.Parse(time.RFC3339, asString(row["applied_at"]))
+	if err != nil {
+		return nil, err
+	}
+	return &model.IntegrationRuleReceipt{
+		EventID:   asString(row["event_id"]),
+		Rule:      asString(row["rule"]),
+		Action:    asString(row["action"]),
+		ThreadID:  asString(row["thread_id"]),
+		State:     model.ForumThreadState(asString(row["state"])),
+		AppliedAt: appliedAt,
+	}, nil
+}
+
+func (s *SQLiteStore) RecordIntegrationRuleReceipt(receipt model.IntegrationRuleReceipt) error {
+	appliedAt := receipt.AppliedAt
+	if appliedAt.IsZero() {
+		appliedAt = time.Now()
+	}
+	return s.execSQL(
+		`INSERT OR IGNORE INTO integration_rule_receipts (event_id, rule, action, thread_id, state, applied_at)
+VALUES (?, ?, ?, ?, ?, ?);`,
+		receipt.EventID,
+		receipt.Rule,
+		receipt.Action,
+		receipt.ThreadID,
+		string(receipt.State),
+		appliedAt.Format(time.RFC3339),
 	)
-	return s.execSQL(sql)
 }
 
 func (s *SQLiteStore) refreshForumThreadStats(ticket string) error {
-	now := time.Now().Format(time.RFC3339)
-	sql := fmt.Sprintf(
-		`BEGIN IMMEDIATE;
-DELETE FROM forum_thread_stats WHERE ticket=%s;
-INSERT INTO forum_thread_stats (ticket, run_id, state, priority, thread_count, updated_at)
-SELECT ticket, run_id, state, priority, COUNT(*), %s
+	return s.execTx(forumThreadStatsRefresh("=?", ticket)...)
+}
+
+// forumThreadStatsRefresh recounts the stats of every ticket matching
+// "ticket <ticketMatch>" from forum_thread_views. matchArgs bind the
+// placeholders in ticketMatch.
+func forumThreadStatsRefresh(ticketMatch string, matchArgs ...any) []sqlStatement {
+	return []sqlStatement{
+		statement(`DELETE FROM forum_thread_stats WHERE ticket `+ticketMatch+`;`, matchArgs...),
+		statement(
+			`INSERT INTO forum_thread_stats (ticket, run_id, state, priority, thread_count, updated_at)
+SELECT ticket, run_id, state, priority, COUNT(*), ?
 FROM forum_thread_views
-WHERE ticket=%s
-GROUP BY ticket, run_id, state, priority;
-COMMIT;`,
-		quote(ticket),
-		quote(now),
-		quote(ticket),
-	)
-	return s.execSQL(sql)
+WHERE ticket `+ticketMatch+`
+GROUP BY ticket, run_id, state, priority;`,
+			append([]any{time.Now().Format(time.RFC3339)}, matchArgs...)...,
+		),
+	}
 }
 
 func (s *SQLiteStore) forumEventExists(eventID string) (bool, error) {
 	if strings.TrimSpace(eventID) == "" {
 		return false, fmt.Errorf("forum event id is required")
 	}
-	rows, err := s.queryJSON(fmt.Sprintf(
-		`SELECT event_id FROM forum_events WHERE event_id=%s LIMIT 1;`,
-		quote(eventID),
-	))
+	rows, err := s.queryJSON(
+		`SELECT event_id FROM forum_events WHERE event_id=? LIMIT 1;`,
+		eventID,
+	)
 	if err != nil {
 		return false, err
 	}
@@ -1334,14 +1732,14 @@ func (s *SQLiteStore) applyForumProjectionEvent(projectionName string, eventID s
 }
 
 func (s *SQLiteStore) forumProjectionEventExists(projectionName string, eventID string) (bool, error) {
-	rows, err := s.queryJSON(fmt.Sprintf(
+	rows, err := s.queryJSON(
 		`SELECT event_id
 FROM forum_projection_events
-WHERE projection_name=%s AND event_id=%s
+WHERE projection_name=? AND event_id=?
 LIMIT 1;`,
-		quote(projectionName),
-		quote(eventID),
-	))
+		projectionName,
+		eventID,
+	)
 	if err != nil {
 		return false, err
 	}
@@ -1350,16 +1748,15 @@ LIMIT 1;`,
 
 func (s *SQLiteStore) insertForumProjectionEvent(projectionName string, eventID string) error {
 	now := time.Now().Format(time.RFC3339)
-	sql := fmt.Sprintf(
+	return s.execSQL(
 		`INSERT OR IGNORE INTO forum_projection_events
   (projection_name, event_id, applied_at)
 VALUES
-  (%s, %s, %s);`,
-		quote(projectionName),
-		quote(eventID),
-		quote(now),
+  (?, ?, ?);`,
+		projectionName,
+		eventID,
+		now,
 	)
-	return s.execSQL(sql)
 }
 
 func (s *SQLiteStore) refreshForumThreadView(threadID string) error {
@@ -1367,7 +1764,15 @@ func (s *SQLiteStore) refreshForumThreadView(threadID string) error {
 	if threadID == "" {
 		return fmt.Errorf("forum thread id is required")
 	}
-	sql := fmt.Sprintf(
+	upsert := forumThreadViewUpsert("=?", threadID)
+	return s.execSQL(upsert.query, upsert.args...)
+}
+
+// forumThreadViewUpsert rebuilds the thread views of every thread matching
+// "thread_id <threadMatch>", e.g. "=?" or "IN (SELECT ...)". matchArgs bind
+// the placeholders in threadMatch.
+func forumThreadViewUpsert(threadMatch string, matchArgs ...any) sqlStatement {
+	return statement(fmt.Sprintf(
 		`INSERT INTO forum_thread_views
   (thread_id, ticket, run_id, agent_name, title, state, priority, assignee_type, assignee_name, opened_by_type, opened_by_name, posts_count, last_post_at, last_post_by_type, last_post_by_name, opened_at, updated_at, closed_at)
 SELECT
@@ -1390,7 +1795,7 @@ SELECT
   t.updated_at,
   t.closed_at
 FROM forum_threads t
-WHERE t.thread_id=%s
+WHERE t.thread_id %s
 ON CONFLICT(thread_id) DO UPDATE SET
   ticket=excluded.ticket,
   run_id=excluded.run_id,
@@ -1409,9 +1814,8 @@ ON CONFLICT(thread_id) DO UPDATE SET
   opened_at=excluded.opened_at,
   updated_at=excluded.updated_at,
   closed_at=excluded.closed_at;`,
-		quote(threadID),
-	)
-	return s.execSQL(sql)
+		threadMatch,
+	), matchArgs...)
 }
 
 func (s *SQLiteStore) refreshForumThreadQueueView(threadID string) error {
@@ -1419,7 +1823,14 @@ func (s *SQLiteStore) refreshForumThreadQueueView(threadID string) error {
 	if threadID == "" {
 		return fmt.Errorf("forum thread id is required")
 	}
-	sql := fmt.Sprintf(
+	upsert := forumThreadQueueViewUpsert("=?", threadID)
+	return s.execSQL(upsert.query, upsert.args...)
+}
+
+// forumThreadQueueViewUpsert rebuilds the queue rows of every thread
+// matching "thread_id <threadMatch>".
+func forumThreadQueueViewUpsert(threadMatch string, matchArgs ...any) sqlStatement {
+	return statement(fmt.Sprintf(
 		`INSERT INTO forum_thread_queue_view
   (thread_id, ticket, run_id, state, priority, assignee_name, last_event_sequence, last_actor_type, last_non_system_actor_type, last_human_or_operator_sequence, last_agent_sequence, updated_at)
 SELECT
@@ -1436,7 +1847,7 @@ SELECT
   COALESCE((SELECT MAX(e.sequence) FROM forum_events e WHERE e.thread_id=t.thread_id AND e.actor_type='agent'), 0),
   t.updated_at
 FROM forum_threads t
-WHERE t.thread_id=%s
+WHERE t.thread_id %s
 ON CONFLICT(thread_id) DO UPDATE SET
   ticket=excluded.ticket,
   run_id=excluded.run_id,
@@ -1449,9 +1860,8 @@ ON CONFLICT(thread_id) DO UPDATE SET
   last_human_or_operator_sequence=excluded.last_human_or_operator_sequence,
   last_agent_sequence=excluded.last_agent_sequence,
   updated_at=excluded.updated_at;`,
-		quote(threadID),
-	)
-	return s.execSQL(sql)
+		threadMatch,
+	), matchArgs...)
 }
 
 func (s *SQLiteStore) UpsertForumControlThread(mapping model.ForumControlThread) error {
@@ -1472,23 +1882,22 @@ func (s *SQLiteStore) UpsertForumControlThread(mapping model.ForumControlThread)
 		return fmt.Errorf("forum control thread ticket is required")
 	}
 	now := time.Now().Format(time.RFC3339)
-	sql := fmt.Sprintf(
+	return s.execSQL(
 		`INSERT INTO forum_control_threads
   (run_id, agent_name, ticket, thread_id, created_at, updated_at)
 VALUES
-  (%s, %s, %s, %s, %s, %s)
+  (?, ?, ?, ?, ?, ?)
 ON CONFLICT(run_id, agent_name) DO UPDATE SET
   ticket=excluded.ticket,
   thread_id=excluded.thread_id,
   updated_at=excluded.updated_at;`,
-		quote(runID),
-		quote(agentName),
-		quote(ticket),
-		quote(threadID),
-		quote(now),
-		quote(now),
+		runID,
+		agentName,
+		ticket,
+		threadID,
+		now,
+		now,
 	)
-	return s.execSQL(sql)
 }
 
 func (s *SQLiteStore) GetForumControlThread(runID string, agentName string) (*model.ForumControlThread, error) {
@@ -1497,15 +1906,14 @@ func (s *SQLiteStore) GetForumControlThread(runID string, agentName string) (*mo
 	if runID == "" || agentName == "" {
 		return nil, nil
 	}
-	sql := fmt.Sprintf(
+	rows, err := s.queryJSON(
 		`SELECT run_id, agent_name, ticket, thread_id, created_at, updated_at
 FROM forum_control_threads
-WHERE run_id=%s AND agent_name=%s
+WHERE run_id=? AND agent_name=?
 LIMIT 1;`,
-		quote(runID),
-		quote(agentName),
+		runID,
+		agentName,
 	)
-	rows, err := s.queryJSON(sql)
 	if err != nil {
 		return nil, err
 	}
@@ -1533,18 +1941,13 @@ LIMIT 1;`,
 
 func (s *SQLiteStore) ListForumControlThreads(runID string) ([]model.ForumControlThread, error) {
 	runID = strings.TrimSpace(runID)
-	clauses := []string{"1=1"}
-	if runID != "" {
-		clauses = append(clauses, fmt.Sprintf("run_id=%s", quote(runID)))
-	}
-	sql := fmt.Sprintf(
+	rows, err := s.queryJSON(
 		`SELECT run_id, agent_name, ticket, thread_id, created_at, updated_at
 FROM forum_control_threads
-WHERE %s
+WHERE (?='' OR run_id=?)
 ORDER BY run_id, agent_name;`,
-		strings.Join(clauses, " AND "),
+		runID, runID,
 	)
-	rows, err := s.queryJSON(sql)
 	if err != nil {
 		return nil, err
 	}
@@ -1588,196 +1991,276 @@ func (s *SQLiteStore) EnqueueForumOutbox(message model.ForumOutboxMessage) error
 		status = model.ForumOutboxStatusPending
 	}
 	now := time.Now().Format(time.RFC3339)
-	sql := fmt.Sprintf(
+	return s.execSQL(
 		`INSERT OR IGNORE INTO forum_outbox
   (message_id, topic, message_key, payload_json, status, attempt_count, last_error, created_at, updated_at, sent_at)
 VALUES
-  (%s, %s, %s, %s, %s, %d, %s, %s, %s, '');`,
-		quote(messageID),
-		quote(topic),
-		quote(strings.TrimSpace(message.MessageKey)),
-		quote(payload),
-		quote(string(status)),
+  (?, ?, ?, ?, ?, ?, ?, ?, ?, '');`,
+		messageID,
+		topic,
+		strings.TrimSpace(message.MessageKey),
+		payload,
+		string(status),
 		message.AttemptCount,
-		quote(strings.TrimSpace(message.LastError)),
-		quote(now),
-		quote(now),
+		strings.TrimSpace(message.LastError),
+		now,
+		now,
 	)
-	return s.execSQL(sql)
 }
 
+// ClaimForumOutboxPending moves up to limit deliverable messages to processing
+// under a fresh lease. Deliverable means pending, failed with its backoff
+// elapsed, or processing with an expired lease (the claimant died). Expired
+// claims that already used every attempt go to dead_letter instead.
 func (s *SQLiteStore) ClaimForumOutboxPending(limit int) ([]model.ForumOutboxMessage, error) {
 	if limit <= 0 {
 		limit = 20
 	}
-	marker := time.Now().UTC().Format(time.RFC3339Nano)
-	sql := fmt.Sprintf(
-		`BEGIN IMMEDIATE;
-UPDATE forum_outbox
-SET status=%s,
+	now := time.Now().UTC()
+	marker := now.Format(time.RFC3339Nano)
+	leaseExpiresAt := now.Add(s.outboxLease()).Format(time.RFC3339Nano)
+	if err := s.execTx(
+		statement(
+			`UPDATE forum_outbox
+SET status=?,
+    last_error='claim lease expired after final attempt',
+    lease_expires_at='',
+    updated_at=?
+WHERE status=?
+  AND lease_expires_at <> ''
+  AND julianday(lease_expires_at) <= julianday(?)
+  AND attempt_count >= ?;`,
+			string(model.ForumOutboxStatusDeadLetter),
+			marker,
+			string(model.ForumOutboxStatusProcessing),
+			marker,
+			s.outboxMaxAttempts(),
+		),
+		statement(
+			`UPDATE forum_outbox
+SET status=?,
     attempt_count=attempt_count+1,
-    updated_at=%s
+    next_attempt_at='',
+    lease_expires_at=?,
+    updated_at=?
 WHERE id IN (
   SELECT id
   FROM forum_outbox
-  WHERE status IN (%s, %s)
+  WHERE status=?
+     OR (status=? AND (next_attempt_at='' OR julianday(next_attempt_at) <= julianday(?)))
+     OR (status=? AND lease_expires_at <> '' AND julianday(lease_expires_at) <= julianday(?))
   ORDER BY created_at, id
-  LIMIT %d
-);
-COMMIT;`,
-		quote(string(model.ForumOutboxStatusProcessing)),
-		quote(marker),
-		quote(string(model.ForumOutboxStatusPending)),
-		quote(string(model.ForumOutboxStatusFailed)),
-		limit,
-	)
-	if err := s.execSQL(sql); err != nil {
+  LIMIT ?
+);`,
+			string(model.ForumOutboxStatusProcessing),
+			leaseExpiresAt,
+			marker,
+			string(model.ForumOutboxStatusPending),
+			string(model.ForumOutboxStatusFailed),
+			marker,
+			string(model.ForumOutboxStatusProcessing),
+			marker,
+			limit,
+		),
+	); err != nil {
 		return nil, err
 	}
 	return s.listForumOutboxByStatusAndUpdatedAt(model.ForumOutboxStatusProcessing, marker)
 }
 
+// ReleaseForumOutboxClaim hands a claimed message back to pending without
+// counting the claim as an attempt, for workers that stop before delivering.
+func (s *SQLiteStore) ReleaseForumOutboxClaim(messageID string) error {
+	messageID = strings.TrimSpace(messageID)
+	if messageID == "" {
+		return fmt.Errorf("forum outbox message_id is required")
+	}
+	return s.execSQL(
+		`UPDATE forum_outbox
+SET status=?,
+    attempt_count=max(attempt_count-1, 0),
+    lease_expires_at='',
+    updated_at=?
+WHERE message_id=? AND status=?;`,
+		string(model.ForumOutboxStatusPending),
+		time.Now().Format(time.RFC3339),
+		messageID,
+		string(model.ForumOutboxStatusProcessing),
+	)
+}
+
 func (s *SQLiteStore) MarkForumOutboxSent(messageID string) error {
 	messageID = strings.TrimSpace(messageID)
 	if messageID == "" {
 		return fmt.Errorf("forum outbox message_id is required")
 	}
 	now := time.Now().Format(time.RFC3339)
-	sql := fmt.Sprintf(
+	return s.execSQL(
 		`UPDATE forum_outbox
-SET status=%s,
+SET status=?,
     last_error='',
-    sent_at=%s,
-    updated_at=%s
-WHERE message_id=%s;`,
-		quote(string(model.ForumOutboxStatusSent)),
-		quote(now),
-		quote(now),
-		quote(messageID),
+    next_attempt_at='',
+    lease_expires_at='',
+    sent_at=?,
+    updated_at=?
+WHERE message_id=?;`,
+		string(model.ForumOutboxStatusSent),
+		now,
+		now,
+		messageID,
 	)
-	return s.execSQL(sql)
 }
 
+// MarkForumOutboxFailed records a delivery failure. The message is scheduled
+// for another attempt after an exponential backoff, or moved to dead_letter
+// once it has used OutboxMaxAttempts attempts.
 func (s *SQLiteStore) MarkForumOutboxFailed(messageID string, lastError string) error {
 	messageID = strings.TrimSpace(messageID)
 	if messageID == "" {
 		return fmt.Errorf("forum outbox message_id is required")
 	}
-	now := time.Now().Format(time.RFC3339)
-	sql := fmt.Sprintf(
+	rows, err := s.queryJSON(`SELECT attempt_count FROM forum_outbox WHERE message_id=?;`, messageID)
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	attempts := asInt(rows[0]["attempt_count"])
+	status := model.ForumOutboxStatusFailed
+	nextAttemptAt := time.Now().UTC().Add(s.outboxBackoff(attempts)).Format(time.RFC3339Nano)
+	if attempts >= s.outboxMaxAttempts() {
+		status = model.ForumOutboxStatusDeadLetter
+		nextAttemptAt = ""
+	}
+	return s.execSQL(
 		`UPDATE forum_outbox
-SET status=%s,
-    last_error=%s,
-    updated_at=%s
-WHERE message_id=%s;`,
-		quote(string(model.ForumOutboxStatusFailed)),
-		quote(strings.TrimSpace(lastError)),
-		quote(now),
-		quote(messageID),
+SET status=?,
+    last_error=?,
+    next_attempt_at=?,
+    lease_expires_at='',
+    updated_at=?
+WHERE message_id=?;`,
+		string(status),
+		strings.TrimSpace(lastError),
+		nextAttemptAt,
+		time.Now().Format(time.RFC3339),
+		messageID,
 	)
-	return s.execSQL(sql)
+}
+
+// RetryForumOutbox requeues failed or dead-lettered messages as fresh pending
+// deliveries with their attempt count reset. With no message IDs it requeues
+// every dead letter. It returns how many messages were requeued.
+func (s *SQLiteStore) RetryForumOutbox(messageIDs []string) (int, error) {
+	where := `status=?`
+	args := []any{string(model.ForumOutboxStatusDeadLetter)}
+	ids := make([]string, 0, len(messageIDs))
+	for _, messageID := range messageIDs {
+		if messageID = strings.TrimSpace(messageID); messageID != "" {
+			ids = append(ids, messageID)
+		}
+	}
+	if len(ids) > 0 {
+		where = `status IN (?, ?) AND message_id IN (` + placeholders(len(ids)) + `)`
+		args = []any{string(model.ForumOutboxStatusDeadLetter), string(model.ForumOutboxStatusFailed)}
+		for _, messageID := range ids {
+			args = append(args, messageID)
+		}
+	}
+	rows, err := s.queryJSON(`SELECT count(*) AS count FROM forum_outbox WHERE `+where+`;`, args...)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	if len(rows) > 0 {
+		count = asInt(rows[0]["count"])
+	}
+	if count == 0 {
+		return 0, nil
+	}
+	updateArgs := append([]any{string(model.ForumOutboxStatusPending), time.Now().Format(time.RFC3339)}, args...)
+	if err := s.execSQL(
+		`UPDATE forum_outbox
+SET status=?,
+    attempt_count=0,
+    next_attempt_at='',
+    lease_expires_at='',
+    updated_at=?
+WHERE `+where+`;`,
+		updateArgs...,
+	); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// PurgeForumOutbox deletes sent or dead-lettered messages last updated more
+// than olderThan ago (all of them when olderThan is zero) and returns how many
+// were deleted.
+func (s *SQLiteStore) PurgeForumOutbox(status model.ForumOutboxStatus, olderThan time.Duration) (int, error) {
+	switch status {
+	case model.ForumOutboxStatusSent, model.ForumOutboxStatusDeadLetter:
+	default:
+		return 0, fmt.Errorf("forum outbox purge status must be sent|dead_letter")
+	}
+	if olderThan < 0 {
+		return 0, fmt.Errorf("forum outbox purge age must be >= 0")
+	}
+	cutoff := time.Now().UTC().Add(-olderThan).Format(time.RFC3339Nano)
+	where := `status=? AND julianday(updated_at) <= julianday(?)`
+	rows, err := s.queryJSON(`SELECT count(*) AS count FROM forum_outbox WHERE `+where+`;`, string(status), cutoff)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	if len(rows) > 0 {
+		count = asInt(rows[0]["count"])
+	}
+	if count == 0 {
+		return 0, nil
+	}
+	if err := s.execSQL(`DELETE FROM forum_outbox WHERE `+where+`;`, string(status), cutoff); err != nil {
+		return 0, err
+	}
+	return count, nil
 }
 
 func (s *SQLiteStore) ListForumOutboxByStatus(status model.ForumOutboxStatus, limit int) ([]model.ForumOutboxMessage, error) {
 	if limit <= 0 {
 		limit = 100
 	}
-	sql := fmt.Sprintf(
-		`SELECT id, message_id, topic, message_key, payload_json, status, attempt_count, last_error, created_at, updated_at, sent_at
+	return s.queryForumOutbox(
+		`SELECT `+forumOutboxColumns+`
 FROM forum_outbox
-WHERE status=%s
+WHERE status=?
 ORDER BY id
-LIMIT %d;`,
-		quote(string(status)),
+LIMIT ?;`,
+		string(status),
 		limit,
 	)
-	rows, err := s.queryJSON(sql)
-	if err != nil {
-		return nil, err
-	}
-	out := make([]model.ForumOutboxMessage, 0, len(rows))
-	for _, row := range rows {
-		createdAt, err := time.Parse(time.RFC3339, asString(row["created_at"]))
-		if err != nil {
-			return nil, fmt.Errorf("parse forum_outbox created_at: %w", err)
-		}
-		updatedAtParsed, err := time.Parse(time.RFC3339Nano, asString(row["updated_at"]))
-		if err != nil {
-			updatedAtParsed, err = time.Parse(time.RFC3339, asString(row["updated_at"]))
-			if err != nil {
-				return nil, fmt.Errorf("parse forum_outbox updated_at: %w", err)
-			}
-		}
-		out = append(out, model.ForumOutboxMessage{
-			ID:           int64(asInt(row["id"])),
-			MessageID:    asString(row["message_id"]),
-			Topic:        asString(row["topic"]),
-			MessageKey:   asString(row["message_key"]),
-			PayloadJSON:  asString(row["payload_json"]),
-			Status:       model.ForumOutboxStatus(asString(row["status"])),
-			AttemptCount: asInt(row["attempt_count"]),
-			LastError:    asString(row["last_error"]),
-			CreatedAt:    createdAt,
-			UpdatedAt:    updatedAtParsed,
-			SentAt:       parseTimePtr(asString(row["sent_at"])),
-		})
-	}
-	return out, nil
 }
 
 func (s *SQLiteStore) ListRecentForumOutbox(limit int) ([]model.ForumOutboxMessage, error) {
 	if limit <= 0 {
 		limit = 100
 	}
-	sql := fmt.Sprintf(
-		`SELECT id, message_id, topic, message_key, payload_json, status, attempt_count, last_error, created_at, updated_at, sent_at
+	return s.queryForumOutbox(
+		`SELECT `+forumOutboxColumns+`
 FROM forum_outbox
 ORDER BY id DESC
-LIMIT %d;`,
+LIMIT ?;`,
 		limit,
 	)
-	rows, err := s.queryJSON(sql)
-	if err != nil {
-		return nil, err
-	}
-	out := make([]model.ForumOutboxMessage, 0, len(rows))
-	for _, row := range rows {
-		createdAt, err := time.Parse(time.RFC3339, asString(row["created_at"]))
-		if err != nil {
-			return nil, fmt.Errorf("parse forum_outbox created_at: %w", err)
-		}
-		updatedAtParsed, err := time.Parse(time.RFC3339Nano, asString(row["updated_at"]))
-		if err != nil {
-			updatedAtParsed, err = time.Parse(time.RFC3339, asString(row["updated_at"]))
-			if err != nil {
-				return nil, fmt.Errorf("parse forum_outbox updated_at: %w", err)
-			}
-		}
-		out = append(out, model.ForumOutboxMessage{
-			ID:           int64(asInt(row["id"])),
-			MessageID:    asString(row["message_id"]),
-			Topic:        asString(row["topic"]),
-			MessageKey:   asString(row["message_key"]),
-			PayloadJSON:  asString(row["payload_json"]),
-			Status:       model.ForumOutboxStatus(asString(row["status"])),
-			AttemptCount: asInt(row["attempt_count"]),
-			LastError:    asString(row["last_error"]),
-			CreatedAt:    createdAt,
-			UpdatedAt:    updatedAtParsed,
-			SentAt:       parseTimePtr(asString(row["sent_at"])),
-		})
-	}
-	return out, nil
 }
 
 func (s *SQLiteStore) CountForumOutboxByStatus(status model.ForumOutboxStatus) (int, error) {
-	sql := fmt.Sprintf(
+	rows, err := s.queryJSON(
 		`SELECT count(*) AS count
 FROM forum_outbox
-WHERE status=%s;`,
-		quote(string(status)),
+WHERE status=?;`,
+		string(status),
 	)
-	rows, err := s.queryJSON(sql)
 	if err != nil {
 		return 0, err
 	}
@@ -1788,15 +2271,14 @@ WHERE status=%s;`,
 }
 
 func (s *SQLiteStore) OldestForumOutboxCreatedAt(status model.ForumOutboxStatus) (*time.Time, error) {
-	sql := fmt.Sprintf(
+	rows, err := s.queryJSON(
 		`SELECT created_at
 FROM forum_outbox
-WHERE status=%s
+WHERE status=?
 ORDER BY created_at, id
 LIMIT 1;`,
-		quote(string(status)),
+		string(status),
 	)
-	rows, err := s.queryJSON(sql)
 	if err != nil {
 		return nil, err
 	}
@@ -1815,15 +2297,20 @@ LIMIT 1;`,
 }
 
 func (s *SQLiteStore) listForumOutboxByStatusAndUpdatedAt(status model.ForumOutboxStatus, updatedAt string) ([]model.ForumOutboxMessage, error) {
-	sql := fmt.Sprintf(
-		`SELECT id, message_id, topic, message_key, payload_json, status, attempt_count, last_error, created_at, updated_at, sent_at
+	return s.queryForumOutbox(
+		`SELECT `+forumOutboxColumns+`
 FROM forum_outbox
-WHERE status=%s AND updated_at=%s
+WHERE status=? AND updated_at=?
 ORDER BY id;`,
-		quote(string(status)),
-		quote(updatedAt),
+		string(status),
+		updatedAt,
 	)
-	rows, err := s.queryJSON(sql)
+}
+
+const forumOutboxColumns = `id, message_id, topic, message_key, payload_json, status, attempt_count, last_error, created_at, updated_at, sent_at, next_attempt_at`
+
+func (s *SQLiteStore) queryForumOutbox(sql string, args ...any) ([]model.ForumOutboxMessage, error) {
+	rows, err := s.queryJSON(sql, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -1841,22 +2328,59 @@ ORDER BY id;`,
 			}
 		}
 		out = append(out, model.ForumOutboxMessage{
-			ID:           int64(asInt(row["id"])),
-			MessageID:    asString(row["message_id"]),
-			Topic:        asString(row["topic"]),
-			MessageKey:   asString(row["message_key"]),
-			PayloadJSON:  asString(row["payload_json"]),
-			Status:       model.ForumOutboxStatus(asString(row["status"])),
-			AttemptCount: asInt(row["attempt_count"]),
-			LastError:    asString(row["last_error"]),
-			CreatedAt:    createdAt,
-			UpdatedAt:    updatedAtParsed,
-			SentAt:       parseTimePtr(asString(row["sent_at"])),
+			ID:            int64(asInt(row["id"])),
+			MessageID:     asString(row["message_id"]),
+			Topic:         asString(row["topic"]),
+			MessageKey:    asString(row["message_key"]),
+			PayloadJSON:   asString(row["payload_json"]),
+			Status:        model.ForumOutboxStatus(asString(row["status"])),
+			AttemptCount:  asInt(row["attempt_count"]),
+			LastError:     asString(row["last_error"]),
+			CreatedAt:     createdAt,
+			UpdatedAt:     updatedAtParsed,
+			SentAt:        parseTimePtr(asString(row["sent_at"])),
+			NextAttemptAt: parseTimePtr(asString(row["next_attempt_at"])),
 		})
 	}
 	return out, nil
 }
 
+func (s *SQLiteStore) outboxMaxAttempts() int {
+	if s.OutboxMaxAttempts <= 0 {
+		return 8
+	}
+	return s.OutboxMaxAttempts
+}
+
+func (s *SQLiteStore) outboxLease() time.Duration {
+	if s.OutboxLeaseMS <= 0 {
+		return 5 * time.Minute
+	}
+	return time.Duration(s.OutboxLeaseMS) * time.Millisecond
+}
+
+// outboxBackoff is the delay before retrying a message that failed on the
+// given attempt: OutboxBackoffBaseMS doubled per attempt, capped at
+// OutboxBackoffMaxMS.
+func (s *SQLiteStore) outboxBackoff(attempt int) time.Duration {
+	base := time.Duration(s.OutboxBackoffBaseMS) * time.Millisecond
+	if base <= 0 {
+		base = 2 * time.Second
+	}
+	maxDelay := time.Duration(s.OutboxBackoffMaxMS) * time.Millisecond
+	if maxDelay < base {
+		maxDelay = base
+	}
+	delay := base
+	for i := 1; i < attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	return delay
+}
+
 func parseForumThreadView(row map[string]any) (model.ForumThreadView, error) {
 	openedAt, err := time.Parse(time.RFC3339, asString(row["opened_at"]))
 	if err != nil {
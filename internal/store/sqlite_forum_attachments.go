@@ -0,0 +1,148 @@
+package store
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"metawsm/internal/model"
+)
+
+const forumAttachmentDigestPrefix = "sha256:"
+
+// ForumAttachmentsDir is where attachment content lives: beside the database,
+// one file per sha256 digest, fanned out by the first two hex characters.
+func (s *SQLiteStore) ForumAttachmentsDir() string {
+	return filepath.Join(filepath.Dir(s.DBPath), "attachments")
+}
+
+// PutForumAttachmentContent writes content under its digest and returns the
+// digest. Identical content is stored once.
+func (s *SQLiteStore) PutForumAttachmentContent(content []byte) (string, error) {
+	sum := sha256.Sum256(content)
+	hexDigest := hex.EncodeToString(sum[:])
+	path := s.forumAttachmentPath(hexDigest)
+	if _, err := os.Stat(path); err == nil {
+		return forumAttachmentDigestPrefix + hexDigest, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("create forum attachment dir: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), hexDigest+".*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("create forum attachment: %w", err)
+	}
+	if _, err := tmp.Write(content); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return "", fmt.Errorf("write forum attachment: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return "", fmt.Errorf("write forum attachment: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		_ = os.Remove(tmp.Name())
+		return "", fmt.Errorf("store forum attachment: %w", err)
+	}
+	return forumAttachmentDigestPrefix + hexDigest, nil
+}
+
+func (s *SQLiteStore) ReadForumAttachmentContent(digest string) ([]byte, error) {
+	hexDigest, err := parseForumAttachmentDigest(digest)
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(s.forumAttachmentPath(hexDigest))
+	if err != nil {
+		return nil, fmt.Errorf("read forum attachment %s: %w", digest, err)
+	}
+	return content, nil
+}
+
+func (s *SQLiteStore) forumAttachmentPath(hexDigest string) string {
+	return filepath.Join(s.ForumAttachmentsDir(), hexDigest[:2], hexDigest)
+}
+
+func parseForumAttachmentDigest(digest string) (string, error) {
+	hexDigest := strings.TrimPrefix(strings.TrimSpace(digest), forumAttachmentDigestPrefix)
+	if len(hexDigest) != sha256.Size*2 {
+		return "", fmt.Errorf("invalid forum attachment digest %q", digest)
+	}
+	if _, err := hex.DecodeString(hexDigest); err != nil {
+		return "", fmt.Errorf("invalid forum attachment digest %q", digest)
+	}
+	return hexDigest, nil
+}
+
+// forumAttachmentInserts returns the inserts for a post's attachments so
+// they commit in the same transaction as the post.
+func forumAttachmentInserts(postID string, threadID string, attachments []model.ForumAttachment) ([]sqlStatement, error) {
+	statements := make([]sqlStatement, 0, len(attachments))
+	for i, attachment := range attachments {
+		if _, err := parseForumAttachmentDigest(attachment.Digest); err != nil {
+			return nil, err
+		}
+		var exitCode any
+		if attachment.ExitCode != nil {
+			exitCode = *attachment.ExitCode
+		}
+		statements = append(statements, statement(
+			`INSERT INTO forum_post_attachments
+  (post_id, position, thread_id, kind, digest, size_bytes, title, repo, path, start_line, end_line, command, exit_code)
+VALUES
+  (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`,
+			postID,
+			i,
+			threadID,
+			string(attachment.Kind),
+			attachment.Digest,
+			attachment.Size,
+			attachment.Title,
+			attachment.Repo,
+			attachment.Path,
+			attachment.StartLine,
+			attachment.EndLine,
+			attachment.Command,
+			exitCode,
+		))
+	}
+	return statements, nil
+}
+
+func (s *SQLiteStore) listForumPostAttachments(threadID string) (map[string][]model.ForumAttachment, error) {
+	rows, err := s.queryJSON(
+		`SELECT post_id, kind, digest, size_bytes, title, repo, path, start_line, end_line, command, exit_code
+FROM forum_post_attachments
+WHERE thread_id=?
+ORDER BY post_id, position;`,
+		threadID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	out := map[string][]model.ForumAttachment{}
+	for _, row := range rows {
+		attachment := model.ForumAttachment{
+			Kind:      model.ForumAttachmentKind(asString(row["kind"])),
+			Digest:    asString(row["digest"]),
+			Size:      int64(asInt(row["size_bytes"])),
+			Title:     asString(row["title"]),
+			Repo:      asString(row["repo"]),
+			Path:      asString(row["path"]),
+			StartLine: asInt(row["start_line"]),
+			EndLine:   asInt(row["end_line"]),
+			Command:   asString(row["command"]),
+		}
+		if row["exit_code"] != nil {
+			exitCode := asInt(row["exit_code"])
+			attachment.ExitCode = &exitCode
+		}
+		postID := asString(row["post_id"])
+		out[postID] = append(out[postID], attachment)
+	}
+	return out, nil
+}
@@ -0,0 +1,256 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"metawsm/internal/model"
+)
+
+// forumProjectionKeys are the columns that identify a row in each projection
+// table; forumProjectionVolatile columns are rewritten on every refresh and
+// so are left out of the rebuild comparison.
+var (
+	forumProjectionKeys = map[string][]string{
+		model.ForumProjectionThreadViews:     {"thread_id"},
+		model.ForumProjectionQueueView:       {"thread_id"},
+		model.ForumProjectionThreadStats:     {"ticket", "run_id", "state", "priority"},
+		model.ForumProjectionSearchDocuments: {"doc_id"},
+	}
+	forumProjectionVolatile = map[string][]string{
+		model.ForumProjectionThreadStats:     {"updated_at"},
+		model.ForumProjectionSearchDocuments: {"doc_rowid"},
+	}
+)
+
+// RebuildForumProjections rebuilds the chosen projections (all of them when
+// projections is empty), optionally scoped to one ticket, from every thread
+// that forum_events touched. The truncate and replay run as one transaction,
+// so readers see either the old projections or the rebuilt ones, never an
+// empty table. The report lists every row where the rebuilt table disagrees
+// with the live table it replaced.
+func (s *SQLiteStore) RebuildForumProjections(ticket string, projections []string) (model.ForumProjectionRebuildReport, error) {
+	ticket = strings.TrimSpace(ticket)
+	selected, err := normalizeForumProjections(projections)
+	if err != nil {
+		return model.ForumProjectionRebuildReport{}, err
+	}
+
+	live := make(map[string]map[string]map[string]string, len(selected))
+	for _, projection := range selected {
+		rows, err := s.snapshotForumProjection(projection, ticket)
+		if err != nil {
+			return model.ForumProjectionRebuildReport{}, err
+		}
+		live[projection] = rows
+	}
+
+	events, eventArgs := forumProjectedEventsClause(ticket)
+	countRows, err := s.queryJSON("SELECT COUNT(*) AS replayed FROM forum_events e WHERE "+events+";", eventArgs...)
+	if err != nil {
+		return model.ForumProjectionRebuildReport{}, err
+	}
+	replayed := 0
+	if len(countRows) > 0 {
+		replayed = asInt(countRows[0]["replayed"])
+	}
+	if err := s.execTx(forumProjectionRebuildStatements(selected, ticket, events, eventArgs)...); err != nil {
+		return model.ForumProjectionRebuildReport{}, fmt.Errorf("rebuild forum projections: %w", err)
+	}
+
+	report := model.ForumProjectionRebuildReport{
+		Ticket:         ticket,
+		Projections:    selected,
+		EventsReplayed: replayed,
+		Counts:         []model.ForumProjectionCount{},
+		Diffs:          []model.ForumProjectionDiff{},
+		RebuiltAt:      time.Now(),
+	}
+	for _, projection := range selected {
+		rebuilt, err := s.snapshotForumProjection(projection, ticket)
+		if err != nil {
+			return model.ForumProjectionRebuildReport{}, err
+		}
+		report.Counts = append(report.Counts, model.ForumProjectionCount{
+			Projection:  projection,
+			LiveRows:    len(live[projection]),
+			RebuiltRows: len(rebuilt),
+		})
+		report.Diffs = append(report.Diffs, diffForumProjection(projection, live[projection], rebuilt)...)
+	}
+	return report, nil
+}
+
+// forumProjectedEventsClause is the WHERE clause, over forum_events aliased e,
+// selecting the events a rebuild replays, and the args it binds.
+func forumProjectedEventsClause(ticket string) (string, []any) {
+	args := make([]any, 0, len(model.ForumProjectedEventTypes)+1)
+	for _, eventType := range model.ForumProjectedEventTypes {
+		args = append(args, eventType)
+	}
+	clause := fmt.Sprintf("e.event_type IN (%s) AND TRIM(e.thread_id) != ''", placeholders(len(args)))
+	if ticket != "" {
+		clause += " AND e.ticket=?"
+		args = append(args, ticket)
+	}
+	return clause, args
+}
+
+// forumProjectionRebuildStatements truncates the selected projections,
+// refreshes them for every thread and ticket the replayed events touched, and
+// records those events as applied; the caller runs them as one transaction.
+// The refreshes derive each row from the forum base tables, so refreshing
+// once per thread lands on the same rows as applying the events one at a time.
+func forumProjectionRebuildStatements(projections []string, ticket string, events string, eventArgs []any) []sqlStatement {
+	threadMatch := "IN (SELECT DISTINCT e.thread_id FROM forum_events e WHERE " + events + ")"
+	ticketMatch := "IN (SELECT DISTINCT e.ticket FROM forum_events e WHERE " + events + ")"
+	statements := forumProjectionTruncate(projections, ticket)
+	for _, projection := range projections {
+		switch projection {
+		case model.ForumProjectionThreadViews:
+			statements = append(statements, forumThreadViewUpsert(threadMatch, eventArgs...))
+		case model.ForumProjectionQueueView:
+			statements = append(statements, forumThreadQueueViewUpsert(threadMatch, eventArgs...))
+		case model.ForumProjectionThreadStats:
+			statements = append(statements, forumThreadStatsRefresh(ticketMatch, eventArgs...)...)
+		case model.ForumProjectionSearchDocuments:
+			statements = append(statements, forumSearchDocumentsRefresh(threadMatch, eventArgs...)...)
+		}
+		statements = append(statements, statement(
+			"INSERT OR IGNORE INTO forum_projection_events (projection_name, event_id, applied_at) SELECT ?, e.event_id, ? FROM forum_events e WHERE "+events+";",
+			append([]any{forumProjectionEventName(projection), time.Now().Format(time.RFC3339)}, eventArgs...)...,
+		))
+	}
+	return statements
+}
+
+func normalizeForumProjections(projections []string) ([]string, error) {
+	requested := map[string]bool{}
+	for _, projection := range projections {
+		projection = strings.TrimSpace(strings.ToLower(projection))
+		if projection == "" {
+			continue
+		}
+		if _, ok := forumProjectionKeys[projection]; !ok {
+			return nil, fmt.Errorf("unknown forum projection %q (expected %s)", projection, strings.Join(model.ForumProjections(), "|"))
+		}
+		requested[projection] = true
+	}
+	out := []string{}
+	for _, projection := range model.ForumProjections() {
+		if len(requested) == 0 || requested[projection] {
+			out = append(out, projection)
+		}
+	}
+	return out, nil
+}
+
+// forumProjectionTruncate clears the selected projections and their
+// applied-event records, scoped to ticket when one is given.
+func forumProjectionTruncate(projections []string, ticket string) []sqlStatement {
+	statements := make([]sqlStatement, 0, 2*len(projections))
+	for _, projection := range projections {
+		if ticket == "" {
+			statements = append(statements,
+				statement(fmt.Sprintf("DELETE FROM %s;", projection)),
+				statement("DELETE FROM forum_projection_events WHERE projection_name=?;", forumProjectionEventName(projection)),
+			)
+			continue
+		}
+		statements = append(statements,
+			statement(fmt.Sprintf("DELETE FROM %s WHERE ticket=?;", projection), ticket),
+			statement(
+				"DELETE FROM forum_projection_events WHERE projection_name=? AND event_id IN (SELECT event_id FROM forum_events WHERE ticket=?);",
+				forumProjectionEventName(projection),
+				ticket,
+			),
+		)
+	}
+	return statements
+}
+
+// snapshotForumProjection reads a projection table keyed by its identifying
+// columns, with every compared column rendered as a string.
+func (s *SQLiteStore) snapshotForumProjection(projection string, ticket string) (map[string]map[string]string, error) {
+	sql := fmt.Sprintf("SELECT * FROM %s;", projection)
+	args := []any{}
+	if ticket != "" {
+		sql = fmt.Sprintf("SELECT * FROM %s WHERE ticket=?;", projection)
+		args = append(args, ticket)
+	}
+	rows, err := s.queryJSON(sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	volatile := map[string]bool{}
+	for _, column := range forumProjectionVolatile[projection] {
+		volatile[column] = true
+	}
+	out := make(map[string]map[string]string, len(rows))
+	for _, row := range rows {
+		values := make(map[string]string, len(row))
+		for column, value := range row {
+			if volatile[column] {
+				continue
+			}
+			if value == nil {
+				values[column] = ""
+				continue
+			}
+			values[column] = fmt.Sprint(value)
+		}
+		keyParts := make([]string, 0, len(forumProjectionKeys[projection]))
+		for _, column := range forumProjectionKeys[projection] {
+			keyParts = append(keyParts, values[column])
+		}
+		out[strings.Join(keyParts, "/")] = values
+	}
+	return out, nil
+}
+
+func diffForumProjection(projection string, live map[string]map[string]string, rebuilt map[string]map[string]string) []model.ForumProjectionDiff {
+	keys := make([]string, 0, len(live)+len(rebuilt))
+	for key := range live {
+		keys = append(keys, key)
+	}
+	for key := range rebuilt {
+		if _, ok := live[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	out := []model.ForumProjectionDiff{}
+	for _, key := range keys {
+		liveRow, inLive := live[key]
+		rebuiltRow, inRebuilt := rebuilt[key]
+		switch {
+		case !inLive:
+			out = append(out, model.ForumProjectionDiff{Projection: projection, Key: key, Kind: model.ForumProjectionDiffAdded})
+		case !inRebuilt:
+			out = append(out, model.ForumProjectionDiff{Projection: projection, Key: key, Kind: model.ForumProjectionDiffRemoved})
+		default:
+			columns := make([]string, 0, len(liveRow))
+			for column := range liveRow {
+				columns = append(columns, column)
+			}
+			sort.Strings(columns)
+			fields := []model.ForumProjectionFieldDiff{}
+			for _, column := range columns {
+				if liveRow[column] != rebuiltRow[column] {
+					fields = append(fields, model.ForumProjectionFieldDiff{
+						Field:   column,
+						Live:    liveRow[column],
+						Rebuilt: rebuiltRow[column],
+					})
+				}
+			}
+			if len(fields) > 0 {
+				out = append(out, model.ForumProjectionDiff{Projection: projection, Key: key, Kind: model.ForumProjectionDiffChanged, Fields: fields})
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,153 @@
+package store
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"metawsm/internal/model"
+)
+
+// forumSearchControlText pulls the human-readable fields out of a control
+// payload post so the index holds questions and answers rather than JSON.
+const forumSearchControlText = `trim(
+      COALESCE(json_extract(p.body_text, '$.question'), '') || char(10) ||
+      COALESCE(json_extract(p.body_text, '$.context'), '') || char(10) ||
+      COALESCE(json_extract(p.body_text, '$.answer'), '') || char(10) ||
+      COALESCE(json_extract(p.body_text, '$.summary'), '') || char(10) ||
+      COALESCE(json_extract(p.body_text, '$.done_criteria'), ''),
+      char(10)
+    )`
+
+const forumSearchIsControl = `json_valid(p.body_text) AND json_extract(p.body_text, '$.control_type') IS NOT NULL`
+
+// refreshForumSearchDocuments rewrites the search documents of one thread:
+// its title plus one document per post. The FTS5 index follows through the
+// forum_search_documents triggers.
+func (s *SQLiteStore) refreshForumSearchDocuments(threadID string) error {
+	return s.execTx(forumSearchDocumentsRefresh("=?", threadID)...)
+}
+
+// forumSearchDocumentsRefresh rewrites the search documents of every thread
+// matching "thread_id <threadMatch>". matchArgs bind the placeholders in
+// threadMatch.
+func forumSearchDocumentsRefresh(threadMatch string, matchArgs ...any) []sqlStatement {
+	return []sqlStatement{
+		statement(`DELETE FROM forum_search_documents WHERE thread_id `+threadMatch+`;`, matchArgs...),
+		statement(
+			`INSERT INTO forum_search_documents (doc_id, thread_id, ticket, run_id, doc_kind, actor_type, actor_name, title, body, created_at)
+SELECT 'thread:' || thread_id, thread_id, ticket, run_id, 'title', opened_by_type, opened_by_name, title, '', opened_at
+FROM forum_threads
+WHERE thread_id `+threadMatch+`;`,
+			matchArgs...,
+		),
+		statement(fmt.Sprintf(
+			`INSERT INTO forum_search_documents (doc_id, thread_id, ticket, run_id, doc_kind, actor_type, actor_name, title, body, created_at)
+SELECT
+  'post:' || p.post_id,
+  p.thread_id,
+  t.ticket,
+  t.run_id,
+  CASE WHEN %s THEN 'control' ELSE 'post' END,
+  p.author_type,
+  p.author_name,
+  '',
+  CASE WHEN %s THEN %s ELSE p.body_text END,
+  p.created_at
+FROM forum_posts p
+JOIN forum_threads t ON t.thread_id = p.thread_id
+WHERE p.thread_id %s;`,
+			forumSearchIsControl,
+			forumSearchIsControl,
+			forumSearchControlText,
+			threadMatch,
+		), matchArgs...),
+	}
+}
+
+// forumSearchMatchExpression turns a user query into an FTS5 MATCH
+// expression. Double-quoted text is a phrase, a trailing * makes a term or
+// phrase a prefix query, a bare OR between terms is kept, and every other
+// term is required. Terms are always quoted, so punctuation in the query can
+// never reach FTS5 as syntax.
+func forumSearchMatchExpression(query string) string {
+	terms := []string{}
+	runes := []rune(strings.TrimSpace(query))
+	for i := 0; i < len(runes); {
+		if unicode.IsSpace(runes[i]) {
+			i++
+			continue
+		}
+		var text string
+		if runes[i] == '"' {
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			text = string(runes[i+1 : end])
+			i = end + 1
+		} else {
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && runes[end] != '"' {
+				end++
+			}
+			text = string(runes[i:end])
+			i = end
+			if text == "OR" {
+				if len(terms) > 0 && terms[len(terms)-1] != "OR" {
+					terms = append(terms, "OR")
+				}
+				continue
+			}
+		}
+		prefix := strings.HasSuffix(text, "*")
+		if i < len(runes) && runes[i] == '*' {
+			prefix = true
+			i++
+		}
+		text = strings.TrimRight(text, "*")
+		if !strings.ContainsFunc(text, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) {
+			continue
+		}
+		term := `"` + strings.ReplaceAll(text, `"`, "") + `"`
+		if prefix {
+			term += "*"
+		}
+		terms = append(terms, term)
+	}
+	if len(terms) > 0 && terms[len(terms)-1] == "OR" {
+		terms = terms[:len(terms)-1]
+	}
+	return strings.Join(terms, " ")
+}
+
+// forumSearchActorClauses filters search documents by who wrote them.
+func forumSearchActorClauses(filter model.ForumThreadSearchFilter) ([]string, []any) {
+	clauses := []string{}
+	args := []any{}
+	if v := strings.TrimSpace(string(filter.ActorType)); v != "" {
+		clauses = append(clauses, "d.actor_type=?")
+		args = append(args, v)
+	}
+	if v := strings.TrimSpace(filter.ActorName); v != "" {
+		clauses = append(clauses, "d.actor_name=?")
+		args = append(args, v)
+	}
+	return clauses, args
+}
+
+func parseForumSearchMatch(row map[string]any) *model.ForumSearchMatch {
+	if row["match_doc_kind"] == nil {
+		return nil
+	}
+	rank, _ := strconv.ParseFloat(asString(row["match_rank"]), 64)
+	return &model.ForumSearchMatch{
+		Rank:      rank,
+		Hits:      asInt(row["match_hits"]),
+		DocKind:   asString(row["match_doc_kind"]),
+		ActorType: model.ForumActorType(asString(row["match_actor_type"])),
+		ActorName: asString(row["match_actor_name"]),
+		Snippet:   asString(row["match_snippet"]),
+	}
+}
@@ -0,0 +1,203 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"metawsm/internal/model"
+)
+
+const guidanceAnswerColumns = `answer_id, scope_type, scope, question, context, answer, ticket, run_id, agent_name, thread_id,
+  source_event_id, promoted_from, created_by, use_count, created_at, updated_at, last_used_at`
+
+// AddGuidanceAnswer stores a library entry and reports whether it was new. An
+// entry whose source_event_id is already in the library is skipped, so
+// harvesting the same control thread twice is a no-op.
+func (s *SQLiteStore) AddGuidanceAnswer(answer model.GuidanceAnswer) (bool, error) {
+	if strings.TrimSpace(answer.SourceEventID) != "" {
+		rows, err := s.queryJSON(`SELECT answer_id FROM guidance_answers WHERE source_event_id=?;`, answer.SourceEventID)
+		if err != nil {
+			return false, err
+		}
+		if len(rows) > 0 {
+			return false, nil
+		}
+	}
+	err := s.execSQL(
+		`INSERT OR IGNORE INTO guidance_answers
+  (answer_id, scope_type, scope, question, question_key, context, answer, ticket, run_id, agent_name, thread_id,
+   source_event_id, promoted_from, created_by, use_count, created_at, updated_at, last_used_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`,
+		answer.AnswerID,
+		string(answer.ScopeType),
+		answer.Scope,
+		answer.Question,
+		model.GuidanceQuestionKey(answer.Question),
+		answer.Context,
+		answer.Answer,
+		answer.Ticket,
+		answer.RunID,
+		answer.AgentName,
+		answer.ThreadID,
+		answer.SourceEventID,
+		answer.PromotedFrom,
+		answer.CreatedBy,
+		answer.UseCount,
+		answer.CreatedAt.Format(time.RFC3339),
+		answer.UpdatedAt.Format(time.RFC3339),
+		formatTime(answer.LastUsedAt),
+	)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// UpdateGuidanceAnswer rewrites the text and provenance of an existing entry.
+func (s *SQLiteStore) UpdateGuidanceAnswer(answer model.GuidanceAnswer) error {
+	return s.execSQL(
+		`UPDATE guidance_answers
+SET question=?, question_key=?, context=?, answer=?, promoted_from=?, created_by=?, updated_at=?
+WHERE answer_id=?;`,
+		answer.Question,
+		model.GuidanceQuestionKey(answer.Question),
+		answer.Context,
+		answer.Answer,
+		answer.PromotedFrom,
+		answer.CreatedBy,
+		answer.UpdatedAt.Format(time.RFC3339),
+		answer.AnswerID,
+	)
+}
+
+// GetGuidanceAnswer returns nil when no entry has the given id.
+func (s *SQLiteStore) GetGuidanceAnswer(answerID string) (*model.GuidanceAnswer, error) {
+	rows, err := s.queryJSON(`SELECT `+guidanceAnswerColumns+` FROM guidance_answers WHERE answer_id=?;`, answerID)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	answer, err := parseGuidanceAnswer(rows[0])
+	if err != nil {
+		return nil, err
+	}
+	return &answer, nil
+}
+
+// FindGuidanceAnswer returns the entry in one scope whose normalized question
+// equals question's, or nil.
+func (s *SQLiteStore) FindGuidanceAnswer(scopeType model.GuidanceAnswerScope, scope string, question string) (*model.GuidanceAnswer, error) {
+	rows, err := s.queryJSON(
+		`SELECT `+guidanceAnswerColumns+` FROM guidance_answers
+WHERE scope_type=? AND scope=? AND question_key=?
+ORDER BY updated_at DESC, answer_id
+LIMIT 1;`,
+		string(scopeType),
+		scope,
+		model.GuidanceQuestionKey(question),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	answer, err := parseGuidanceAnswer(rows[0])
+	if err != nil {
+		return nil, err
+	}
+	return &answer, nil
+}
+
+// ListGuidanceAnswers returns library entries, most recently updated first.
+func (s *SQLiteStore) ListGuidanceAnswers(filter model.GuidanceAnswerFilter) ([]model.GuidanceAnswer, error) {
+	clauses := []string{}
+	args := []any{}
+	if v := strings.TrimSpace(string(filter.ScopeType)); v != "" {
+		clauses = append(clauses, "scope_type=?")
+		args = append(args, v)
+	}
+	if v := strings.TrimSpace(filter.Scope); v != "" {
+		clauses = append(clauses, "scope=?")
+		args = append(args, v)
+	}
+	if v := strings.TrimSpace(filter.Ticket); v != "" {
+		clauses = append(clauses, "ticket=?")
+		args = append(args, v)
+	}
+	sql := `SELECT ` + guidanceAnswerColumns + ` FROM guidance_answers`
+	if len(clauses) > 0 {
+		sql += "\nWHERE " + strings.Join(clauses, " AND ")
+	}
+	sql += "\nORDER BY updated_at DESC, answer_id"
+	if filter.Limit > 0 {
+		sql += "\nLIMIT ?"
+		args = append(args, filter.Limit)
+	}
+	rows, err := s.queryJSON(sql+";", args...)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]model.GuidanceAnswer, 0, len(rows))
+	for _, row := range rows {
+		answer, err := parseGuidanceAnswer(row)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, answer)
+	}
+	return out, nil
+}
+
+func (s *SQLiteStore) DeleteGuidanceAnswer(answerID string) error {
+	rows, err := s.queryJSON(`SELECT answer_id FROM guidance_answers WHERE answer_id=?;`, answerID)
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return fmt.Errorf("guidance answer %s not found", answerID)
+	}
+	return s.execSQL(`DELETE FROM guidance_answers WHERE answer_id=?;`, answerID)
+}
+
+// RecordGuidanceAnswerUse counts one reuse of an entry.
+func (s *SQLiteStore) RecordGuidanceAnswerUse(answerID string, usedAt time.Time) error {
+	return s.execSQL(
+		`UPDATE guidance_answers SET use_count=use_count+1, last_used_at=? WHERE answer_id=?;`,
+		usedAt.Format(time.RFC3339),
+		answerID,
+	)
+}
+
+func parseGuidanceAnswer(row map[string]any) (model.GuidanceAnswer, error) {
+	createdAt, err := time.Parse(time.RFC3339, asString(row["created_at"]))
+	if err != nil {
+		return model.GuidanceAnswer{}, fmt.Errorf("parse guidance_answers created_at: %w", err)
+	}
+	updatedAt, err := time.Parse(time.RFC3339, asString(row["updated_at"]))
+	if err != nil {
+		return model.GuidanceAnswer{}, fmt.Errorf("parse guidance_answers updated_at: %w", err)
+	}
+	return model.GuidanceAnswer{
+		AnswerID:      asString(row["answer_id"]),
+		ScopeType:     model.GuidanceAnswerScope(asString(row["scope_type"])),
+		Scope:         asString(row["scope"]),
+		Question:      asString(row["question"]),
+		Context:       asString(row["context"]),
+		Answer:        asString(row["answer"]),
+		Ticket:        asString(row["ticket"]),
+		RunID:         asString(row["run_id"]),
+		AgentName:     asString(row["agent_name"]),
+		ThreadID:      asString(row["thread_id"]),
+		SourceEventID: asString(row["source_event_id"]),
+		PromotedFrom:  asString(row["promoted_from"]),
+		CreatedBy:     asString(row["created_by"]),
+		UseCount:      asInt(row["use_count"]),
+		CreatedAt:     createdAt,
+		UpdatedAt:     updatedAt,
+		LastUsedAt:    parseTimePtr(asString(row["last_used_at"])),
+	}, nil
+}
@@ -0,0 +1,278 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"metawsm/internal/model"
+)
+
+// AcquireOperatorLease takes or renews the supervision lease on runID for
+// holder. It returns the lease as stored and whether holder owns it; a lease
+// held by someone else is only taken over once it has expired. Times are kept
+// in UTC so expiry compares correctly as text.
+func (s *SQLiteStore) AcquireOperatorLease(runID string, holder string, now time.Time, ttl time.Duration) (model.OperatorLease, bool, error) {
+	runID = strings.TrimSpace(runID)
+	holder = strings.TrimSpace(holder)
+	if runID == "" || holder == "" {
+		return model.OperatorLease{}, false, fmt.Errorf("operator lease requires run id and holder")
+	}
+	if ttl <= 0 {
+		return model.OperatorLease{}, false, fmt.Errorf("operator lease ttl must be > 0")
+	}
+	nowText := now.UTC().Format(time.RFC3339)
+	err := s.execSQL(
+		`INSERT INTO operator_leases (run_id, holder, acquired_at, expires_at)
+VALUES (?, ?, ?, ?)
+ON CONFLICT(run_id) DO UPDATE SET
+  acquired_at=CASE WHEN operator_leases.holder=excluded.holder THEN operator_leases.acquired_at ELSE excluded.acquired_at END,
+  holder=excluded.holder,
+  expires_at=excluded.expires_at
+WHERE operator_leases.holder=excluded.holder OR operator_leases.expires_at <= ?;`,
+		runID,
+		holder,
+		nowText,
+		now.Add(ttl).UTC().Format(time.RFC3339),
+		nowText,
+	)
+	if err != nil {
+		return model.OperatorLease{}, false, err
+	}
+	lease, err := s.GetOperatorLease(runID)
+	if err != nil {
+		return model.OperatorLease{}, false, err
+	}
+	if lease == nil {
+		return model.OperatorLease{}, false, fmt.Errorf("operator lease for run %s was not stored", runID)
+	}
+	return *lease, lease.Holder == holder, nil
+}
+
+// GetOperatorLease returns nil when no operator has ever leased runID.
+func (s *SQLiteStore) GetOperatorLease(runID string) (*model.OperatorLease, error) {
+	rows, err := s.queryJSON(
+		`SELECT run_id, holder, acquired_at, expires_at FROM operator_leases WHERE run_id=?;`,
+		strings.TrimSpace(runID),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	lease, err := operatorLeaseFromRow(rows[0])
+	if err != nil {
+		return nil, err
+	}
+	return &lease, nil
+}
+
+// RenewOperatorLeases pushes the expiry of every lease holder still owns to
+// now+ttl, so a long pass does not lose runs it already supervised.
+func (s *SQLiteStore) RenewOperatorLeases(holder string, now time.Time, ttl time.Duration) error {
+	if ttl <= 0 {
+		return fmt.Errorf("operator lease ttl must be > 0")
+	}
+	return s.execSQL(
+		`UPDATE operator_leases SET expires_at=? WHERE holder=?;`,
+		now.Add(ttl).UTC().Format(time.RFC3339),
+		strings.TrimSpace(holder),
+	)
+}
+
+// ReleaseOperatorLeases drops every lease held by holder so another operator
+// can take over without waiting for expiry.
+func (s *SQLiteStore) ReleaseOperatorLeases(holder string) error {
+	return s.execSQL(`DELETE FROM operator_leases WHERE holder=?;`, strings.TrimSpace(holder))
+}
+
+func operatorLeaseFromRow(row map[string]any) (model.OperatorLease, error) {
+	acquiredAt, err := time.Parse(time.RFC3339, asString(row["acquired_at"]))
+	if err != nil {
+		return model.OperatorLease{}, fmt.Errorf("parse operator_leases acquired_at: %w", err)
+	}
+	expiresAt, err := time.Parse(time.RFC3339, asString(row["expires_at"]))
+	if err != nil {
+		return model.OperatorLease{}, fmt.Errorf("parse operator_leases expires_at: %w", err)
+	}
+	return model.OperatorLease{
+		RunID:      asString(row["run_id"]),
+		Holder:     asString(row["holder"]),
+		AcquiredAt: acquiredAt,
+		ExpiresAt:  expiresAt,
+	}, nil
+}
+
+// AddOperatorDecision appends record to the operator decision audit log.
+func (s *SQLiteStore) AddOperatorDecision(record model.OperatorDecisionRecord) error {
+	createdAt := record.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	return s.execSQL(
+		`INSERT INTO operator_decisions
+  (run_id, holder, llm_mode, dry_run, inputs_json, rule_intent, rule_reason, rule_execute,
+   llm_request_json, llm_reply_json, llm_error, intent, reason, source, execute, event, executed, action_error, last_seen_at, created_at)
+VALUES
+  (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`,
+		record.RunID,
+		record.Holder,
+		record.LLMMode,
+		sqlBool(record.DryRun),
+		string(record.Inputs),
+		record.RuleIntent,
+		record.RuleReason,
+		sqlBool(record.RuleExecute),
+		string(record.LLMRequest),
+		string(record.LLMReply),
+		record.LLMError,
+		record.Intent,
+		record.Reason,
+		record.Source,
+		sqlBool(record.Execute),
+		record.Event,
+		sqlBool(record.Executed),
+		record.ActionError,
+		createdAt.Format(time.RFC3339),
+		createdAt.Format(time.RFC3339),
+	)
+}
+
+// RepeatLatestOperatorDecision counts a pass that repeated the outcome of the
+// newest decision for runID against that decision. It reports false when the
+// run has no audited decision to repeat.
+func (s *SQLiteStore) RepeatLatestOperatorDecision(runID string, seenAt time.Time) (bool, error) {
+	rows, err := s.queryJSON(`SELECT COALESCE(MAX(id), 0) AS id FROM operator_decisions WHERE run_id=?;`, strings.TrimSpace(runID))
+	if err != nil {
+		return false, err
+	}
+	if len(rows) == 0 || asInt(rows[0]["id"]) == 0 {
+		return false, nil
+	}
+	if err := s.execSQL(
+		`UPDATE operator_decisions SET repeated_count=repeated_count+1, last_seen_at=? WHERE id=?;`,
+		seenAt.Format(time.RFC3339),
+		asInt(rows[0]["id"]),
+	); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// PruneOperatorDecisions deletes decisions last seen before cutoff and
+// returns how many were removed.
+func (s *SQLiteStore) PruneOperatorDecisions(cutoff time.Time) (int, error) {
+	const stale = `datetime(operator_decisions.last_seen_at) < datetime(?)`
+	cutoffText := cutoff.Format(time.RFC3339)
+	rows, err := s.queryJSON(`SELECT COUNT(*) AS count FROM operator_decisions WHERE `+stale+`;`, cutoffText)
+	if err != nil {
+		return 0, err
+	}
+	if len(rows) == 0 || asInt(rows[0]["count"]) == 0 {
+		return 0, nil
+	}
+	if err := s.execSQL(`DELETE FROM operator_decisions WHERE `+stale+`;`, cutoffText); err != nil {
+		return 0, err
+	}
+	return asInt(rows[0]["count"]), nil
+}
+
+// ListOperatorDecisions returns audited decisions newest first. An empty
+// runID lists every run.
+func (s *SQLiteStore) ListOperatorDecisions(runID string, limit int) ([]model.OperatorDecisionRecord, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	runID = strings.TrimSpace(runID)
+	rows, err := s.queryJSON(
+		`SELECT `+operatorDecisionColumns+`
+FROM operator_decisions
+WHERE (?='' OR run_id=?)
+ORDER BY id DESC
+LIMIT ?;`,
+		runID, runID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]model.OperatorDecisionRecord, 0, len(rows))
+	for _, row := range rows {
+		record, err := operatorDecisionFromRow(row)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// GetOperatorDecision returns nil when no decision has the given id.
+func (s *SQLiteStore) GetOperatorDecision(id int64) (*model.OperatorDecisionRecord, error) {
+	rows, err := s.queryJSON(`SELECT `+operatorDecisionColumns+` FROM operator_decisions WHERE id=?;`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	record, err := operatorDecisionFromRow(rows[0])
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+const operatorDecisionColumns = `id, run_id, holder, llm_mode, dry_run, inputs_json, rule_intent, rule_reason, rule_execute,
+  llm_request_json, llm_reply_json, llm_error, intent, reason, source, execute, event, executed, action_error, repeated_count, last_seen_at, created_at`
+
+func operatorDecisionFromRow(row map[string]any) (model.OperatorDecisionRecord, error) {
+	createdAt, err := time.Parse(time.RFC3339, asString(row["created_at"]))
+	if err != nil {
+		return model.OperatorDecisionRecord{}, fmt.Errorf("parse operator_decisions created_at: %w", err)
+	}
+	lastSeenAt, err := time.Parse(time.RFC3339, asString(row["last_seen_at"]))
+	if err != nil {
+		return model.OperatorDecisionRecord{}, fmt.Errorf("parse operator_decisions last_seen_at: %w", err)
+	}
+	return model.OperatorDecisionRecord{
+		ID:            int64(asInt(row["id"])),
+		RunID:         asString(row["run_id"]),
+		Holder:        asString(row["holder"]),
+		LLMMode:       asString(row["llm_mode"]),
+		DryRun:        asInt(row["dry_run"]) == 1,
+		Inputs:        rawJSONColumn(row["inputs_json"]),
+		RuleIntent:    asString(row["rule_intent"]),
+		RuleReason:    asString(row["rule_reason"]),
+		RuleExecute:   asInt(row["rule_execute"]) == 1,
+		LLMRequest:    rawJSONColumn(row["llm_request_json"]),
+		LLMReply:      rawJSONColumn(row["llm_reply_json"]),
+		LLMError:      asString(row["llm_error"]),
+		Intent:        asString(row["intent"]),
+		Reason:        asString(row["reason"]),
+		Source:        asString(row["source"]),
+		Execute:       asInt(row["execute"]) == 1,
+		Event:         asString(row["event"]),
+		Executed:      asInt(row["executed"]) == 1,
+		ActionError:   asString(row["action_error"]),
+		RepeatedCount: asInt(row["repeated_count"]),
+		LastSeenAt:    lastSeenAt,
+		CreatedAt:     createdAt,
+	}, nil
+}
+
+func rawJSONColumn(v any) json.RawMessage {
+	text := strings.TrimSpace(asString(v))
+	if text == "" {
+		return nil
+	}
+	return json.RawMessage(text)
+}
+
+func sqlBool(v bool) int {
+	if v {
+		return 1
+	}
+	return 0
+}
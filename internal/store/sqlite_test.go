@@ -2,9 +2,12 @@ package store
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -248,11 +251,14 @@ func TestOperatorRunStatePersistsAcrossStoreReopen(t *testing.T) {
 	lastRestart := time.Now().Add(-2 * time.Minute).Truncate(time.Second)
 	cooldownUntil := time.Now().Add(30 * time.Second).Truncate(time.Second)
 	if err := s.UpsertOperatorRunState(model.OperatorRunState{
-		RunID:           "run-operator-state",
-		RestartAttempts: 2,
-		LastRestartAt:   &lastRestart,
-		CooldownUntil:   &cooldownUntil,
-		UpdatedAt:       time.Now(),
+		RunID:              "run-operator-state",
+		RestartAttempts:    2,
+		LastRestartAt:      &lastRestart,
+		CooldownUntil:      &cooldownUntil,
+		UnhealthyIntervals: 1,
+		LastEvent:          "auto_restart_candidate",
+		RuleFirings:        map[string][]time.Time{"restart-stalled": {lastRestart}},
+		UpdatedAt:          time.Now(),
 	}); err != nil {
 		t.Fatalf("upsert operator run state: %v", err)
 	}
@@ -278,6 +284,124 @@ func TestOperatorRunStatePersistsAcrossStoreReopen(t *testing.T) {
 	if state.CooldownUntil == nil || !state.CooldownUntil.Equal(cooldownUntil) {
 		t.Fatalf("expected cooldown until %s, got %v", cooldownUntil.Format(time.RFC3339), state.CooldownUntil)
 	}
+	if state.UnhealthyIntervals != 1 || state.LastEvent != "auto_restart_candidate" {
+		t.Fatalf("expected unhealthy interval memory to persist, got %+v", state)
+	}
+	if firings := state.RuleFirings["restart-stalled"]; len(firings) != 1 || !firings[0].Equal(lastRestart) {
+		t.Fatalf("expected rule firings to persist, got %+v", state.RuleFirings)
+	}
+}
+
+func TestOperatorLeaseExcludesOtherHoldersUntilExpiry(t *testing.T) {
+	s := NewSQLiteStore(filepath.Join(t.TempDir(), "metawsm.db"))
+	if err := s.Init(); err != nil {
+		t.Fatalf("init store: %v", err)
+	}
+	now := time.Now().Truncate(time.Second)
+
+	lease, ok, err := s.AcquireOperatorLease("run-1", "serve-a", now, time.Minute)
+	if err != nil || !ok {
+		t.Fatalf("expected first holder to acquire lease, got ok=%t err=%v", ok, err)
+	}
+	if _, ok, err := s.AcquireOperatorLease("run-1", "serve-b", now.Add(30*time.Second), time.Minute); err != nil || ok {
+		t.Fatalf("expected live lease to exclude another holder, got ok=%t err=%v", ok, err)
+	}
+	renewed, ok, err := s.AcquireOperatorLease("run-1", "serve-a", now.Add(45*time.Second), time.Minute)
+	if err != nil || !ok {
+		t.Fatalf("expected holder to renew lease, got ok=%t err=%v", ok, err)
+	}
+	if !renewed.AcquiredAt.Equal(lease.AcquiredAt) || !renewed.ExpiresAt.Equal(now.Add(105*time.Second)) {
+		t.Fatalf("expected renewal to extend expiry only, got %+v", renewed)
+	}
+
+	taken, ok, err := s.AcquireOperatorLease("run-1", "serve-b", now.Add(2*time.Minute), time.Minute)
+	if err != nil || !ok || taken.Holder != "serve-b" || !taken.AcquiredAt.Equal(now.Add(2*time.Minute)) {
+		t.Fatalf("expected expired lease to be taken over, got %+v ok=%t err=%v", taken, ok, err)
+	}
+
+	if err := s.RenewOperatorLeases("serve-b", now.Add(150*time.Second), time.Minute); err != nil {
+		t.Fatalf("renew leases: %v", err)
+	}
+	if _, ok, err := s.AcquireOperatorLease("run-1", "serve-a", now.Add(190*time.Second), time.Minute); err != nil || ok {
+		t.Fatalf("expected renewed lease to exclude another holder, got ok=%t err=%v", ok, err)
+	}
+
+	if err := s.ReleaseOperatorLeases("serve-b"); err != nil {
+		t.Fatalf("release leases: %v", err)
+	}
+	if _, ok, err := s.AcquireOperatorLease("run-1", "serve-a", now.Add(190*time.Second), time.Minute); err != nil || !ok {
+		t.Fatalf("expected released lease to be free, got ok=%t err=%v", ok, err)
+	}
+}
+
+func TestOperatorDecisionAuditRoundTrip(t *testing.T) {
+	s := NewSQLiteStore(filepath.Join(t.TempDir(), "metawsm.db"))
+	if err := s.Init(); err != nil {
+		t.Fatalf("init store: %v", err)
+	}
+	now := time.Now().Truncate(time.Second)
+	first := model.OperatorDecisionRecord{
+		RunID:       "run-1",
+		Holder:      "serve-a",
+		LLMMode:     "auto",
+		Inputs:      []byte(`{"unhealthy_intervals":2}`),
+		RuleIntent:  "auto_restart",
+		RuleExecute: true,
+		LLMReply:    []byte(`{"intent":"auto_restart","reason":"stalled"}`),
+		Intent:      "auto_restart",
+		Source:      "rule",
+		Execute:     true,
+		Event:       "auto_restart_candidate",
+		Executed:    true,
+		CreatedAt:   now,
+	}
+	if err := s.AddOperatorDecision(first); err != nil {
+		t.Fatalf("add decision: %v", err)
+	}
+	if err := s.AddOperatorDecision(model.OperatorDecisionRecord{RunID: "run-2", Inputs: []byte(`{}`), RuleIntent: "noop", Intent: "noop", CreatedAt: now}); err != nil {
+		t.Fatalf("add second decision: %v", err)
+	}
+	seen := now.Add(time.Minute)
+	for i := 0; i < 2; i++ {
+		if repeated, err := s.RepeatLatestOperatorDecision("run-2", seen); err != nil || !repeated {
+			t.Fatalf("repeat run-2 decision: %t (%v)", repeated, err)
+		}
+	}
+	if repeated, err := s.RepeatLatestOperatorDecision("run-3", seen); err != nil || repeated {
+		t.Fatalf("expected no decision to repeat for run-3, got %t (%v)", repeated, err)
+	}
+
+	all, err := s.ListOperatorDecisions("", 10)
+	if err != nil || len(all) != 2 || all[0].RunID != "run-2" {
+		t.Fatalf("expected newest-first decisions, got %+v (%v)", all, err)
+	}
+	if all[0].RepeatedCount != 2 || !all[0].LastSeenAt.Equal(seen) || all[1].RepeatedCount != 0 || !all[1].LastSeenAt.Equal(now) {
+		t.Fatalf("expected repeats counted on the latest run-2 decision, got %+v", all)
+	}
+	records, err := s.ListOperatorDecisions("run-1", 10)
+	if err != nil || len(records) != 1 {
+		t.Fatalf("expected one run-1 decision, got %+v (%v)", records, err)
+	}
+	got := records[0]
+	if !got.RuleExecute || !got.Executed || got.DryRun || string(got.Inputs) != `{"unhealthy_intervals":2}` || got.LLMRequest != nil || !got.CreatedAt.Equal(now) {
+		t.Fatalf("unexpected decision round trip %+v", got)
+	}
+	loaded, err := s.GetOperatorDecision(got.ID)
+	if err != nil || loaded == nil || string(loaded.LLMReply) != string(first.LLMReply) {
+		t.Fatalf("expected decision by id, got %+v (%v)", loaded, err)
+	}
+	if missing, err := s.GetOperatorDecision(9999); err != nil || missing != nil {
+		t.Fatalf("expected missing decision to be nil, got %+v (%v)", missing, err)
+	}
+
+	pruned, err := s.PruneOperatorDecisions(now.Add(30 * time.Second))
+	if err != nil || pruned != 1 {
+		t.Fatalf("expected the run-1 decision to be pruned, got %d (%v)", pruned, err)
+	}
+	remaining, err := s.ListOperatorDecisions("", 10)
+	if err != nil || len(remaining) != 1 || remaining[0].RunID != "run-2" {
+		t.Fatalf("expected the recently repeated decision to be kept, got %+v (%v)", remaining, err)
+	}
 }
 
 func TestSQLiteStoreRetriesBusyWriteLock(t *testing.T) {
@@ -410,6 +534,159 @@ func TestRunPullRequestStatePersistsAcrossStoreReopen(t *testing.T) {
 	}
 }
 
+func TestAgentTranscriptUpsertUpdatesIndexRow(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "metawsm.db")
+	s := NewSQLiteStore(dbPath)
+	if err := s.Init(); err != nil {
+		t.Fatalf("init store: %v", err)
+	}
+
+	started := time.Now().Truncate(time.Second)
+	transcript := model.AgentTranscript{
+		RunID:         "run-logs-1",
+		AgentName:     "agent",
+		WorkspaceName: "ws-1",
+		Path:          ".metawsm/runs/run-logs-1/agent-ws-1.log",
+		Runtime:       "tmux",
+		StartedAt:     &started,
+		UpdatedAt:     &started,
+	}
+	if err := s.UpsertAgentTranscript(transcript); err != nil {
+		t.Fatalf("upsert transcript: %v", err)
+	}
+	updated := started.Add(time.Minute)
+	transcript.SizeBytes = 4096
+	transcript.Rotations = 2
+	transcript.UpdatedAt = &updated
+	if err := s.UpsertAgentTranscript(transcript); err != nil {
+		t.Fatalf("update transcript: %v", err)
+	}
+
+	rows, err := s.ListAgentTranscripts("run-logs-1")
+	if err != nil {
+		t.Fatalf("list transcripts: %v", err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("expected one transcript row, got %d", len(rows))
+	}
+	row := rows[0]
+	if row.Path != transcript.Path || row.Runtime != "tmux" {
+		t.Fatalf("unexpected transcript location: %+v", row)
+	}
+	if row.SizeBytes != 4096 || row.Rotations != 2 {
+		t.Fatalf("unexpected transcript counters: size=%d rotations=%d", row.SizeBytes, row.Rotations)
+	}
+	if row.StartedAt == nil || !row.StartedAt.Equal(started) || row.UpdatedAt == nil || !row.UpdatedAt.Equal(updated) {
+		t.Fatalf("unexpected transcript timestamps: started=%v updated=%v", row.StartedAt, row.UpdatedAt)
+	}
+}
+
+func TestAPITokenLookupAndRevoke(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "metawsm.db")
+	s := NewSQLiteStore(dbPath)
+	if err := s.Init(); err != nil {
+		t.Fatalf("init store: %v", err)
+	}
+
+	created := time.Now().Truncate(time.Second)
+	expires := created.Add(time.Hour)
+	token := model.APIToken{
+		TokenID:   "tok_1",
+		TokenHash: "hash-1",
+		Role:      model.APIRoleOperator,
+		ActorName: "kball",
+		CreatedAt: created,
+		ExpiresAt: &expires,
+	}
+	if err := s.CreateAPIToken(token); err != nil {
+		t.Fatalf("create token: %v", err)
+	}
+	if err := s.CreateAPIToken(model.APIToken{TokenID: "tok_2", TokenHash: "hash-1", Role: model.APIRoleViewer, ActorName: "dup", CreatedAt: created}); err == nil {
+		t.Fatalf("expected duplicate token hash to be rejected")
+	}
+
+	found, err := s.GetAPITokenByHash("hash-1")
+	if err != nil {
+		t.Fatalf("get token: %v", err)
+	}
+	if found == nil || found.TokenID != "tok_1" || found.Role != model.APIRoleOperator || found.ActorName != "kball" {
+		t.Fatalf("unexpected token: %+v", found)
+	}
+	if found.ExpiresAt == nil || !found.ExpiresAt.Equal(expires) || found.RevokedAt != nil {
+		t.Fatalf("unexpected token lifetime: expires=%v revoked=%v", found.ExpiresAt, found.RevokedAt)
+	}
+	missing, err := s.GetAPITokenByHash("nope")
+	if err != nil || missing != nil {
+		t.Fatalf("expected no token for unknown hash, got %+v (%v)", missing, err)
+	}
+
+	revoked := created.Add(time.Minute)
+	if err := s.RevokeAPIToken("tok_1", revoked); err != nil {
+		t.Fatalf("revoke token: %v", err)
+	}
+	if err := s.RevokeAPIToken("tok_1", revoked.Add(time.Minute)); err != nil {
+		t.Fatalf("revoke token again: %v", err)
+	}
+	if err := s.RevokeAPIToken("tok_missing", revoked); err == nil {
+		t.Fatalf("expected revoking unknown token to fail")
+	}
+	tokens, err := s.ListAPITokens()
+	if err != nil {
+		t.Fatalf("list tokens: %v", err)
+	}
+	if len(tokens) != 1 || tokens[0].RevokedAt == nil || !tokens[0].RevokedAt.Equal(revoked) {
+		t.Fatalf("expected first revocation time to stick, got %+v", tokens)
+	}
+}
+
+func TestRevokeAgentAPITokensMatchesScope(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "metawsm.db")
+	s := NewSQLiteStore(dbPath)
+	if err := s.Init(); err != nil {
+		t.Fatalf("init store: %v", err)
+	}
+
+	created := time.Now().Truncate(time.Second)
+	for _, token := range []model.APIToken{
+		{TokenID: "tok_a1", TokenHash: "hash-a1", Role: model.APIRoleAgent, ActorName: "agent", RunID: "run-1", AgentName: "agent", WorkspaceName: "ws-1", CreatedAt: created},
+		{TokenID: "tok_a2", TokenHash: "hash-a2", Role: model.APIRoleAgent, ActorName: "agent", RunID: "run-1", AgentName: "agent", WorkspaceName: "ws-2", CreatedAt: created},
+		{TokenID: "tok_b1", TokenHash: "hash-b1", Role: model.APIRoleAgent, ActorName: "agent", RunID: "run-2", AgentName: "agent", WorkspaceName: "ws-1", CreatedAt: created},
+		{TokenID: "tok_op", TokenHash: "hash-op", Role: model.APIRoleOperator, ActorName: "kball", CreatedAt: created},
+	} {
+		if err := s.CreateAPIToken(token); err != nil {
+			t.Fatalf("create token %s: %v", token.TokenID, err)
+		}
+	}
+	found, err := s.GetAPITokenByHash("hash-a1")
+	if err != nil || found == nil || found.RunID != "run-1" || found.AgentName != "agent" || found.WorkspaceName != "ws-1" {
+		t.Fatalf("expected scoped token, got %+v (%v)", found, err)
+	}
+
+	revokedIDs := func() map[string]bool {
+		tokens, err := s.ListAPITokens()
+		if err != nil {
+			t.Fatalf("list tokens: %v", err)
+		}
+		out := map[string]bool{}
+		for _, token := range tokens {
+			out[token.TokenID] = token.RevokedAt != nil
+		}
+		return out
+	}
+	if err := s.RevokeAgentAPITokens("run-1", "agent", "ws-1", created); err != nil {
+		t.Fatalf("revoke agent tokens: %v", err)
+	}
+	if got := revokedIDs(); !got["tok_a1"] || got["tok_a2"] || got["tok_b1"] || got["tok_op"] {
+		t.Fatalf("expected only tok_a1 revoked, got %+v", got)
+	}
+	if err := s.RevokeAgentAPITokens("run-1", "", "", created); err != nil {
+		t.Fatalf("revoke run tokens: %v", err)
+	}
+	if got := revokedIDs(); !got["tok_a2"] || got["tok_b1"] || got["tok_op"] {
+		t.Fatalf("expected run-1 tokens revoked only, got %+v", got)
+	}
+}
+
 func TestRunReviewFeedbackPersistsAcrossStoreReopen(t *testing.T) {
 	if _, err := exec.LookPath("sqlite3"); err != nil {
 		t.Skip("sqlite3 not available")
@@ -1047,8 +1324,8 @@ func TestApplyForumEventProjectionsIsIdempotent(t *testing.T) {
 	if err != nil {
 		t.Fatalf("list projection events: %v", err)
 	}
-	if len(projectionRows) != 3 {
-		t.Fatalf("expected three projection markers (views+queue+stats), got %d", len(projectionRows))
+	if len(projectionRows) != len(model.ForumProjections()) {
+		t.Fatalf("expected one projection marker per projection (%d), got %d", len(model.ForumProjections()), len(projectionRows))
 	}
 }
 
@@ -1117,6 +1394,96 @@ func TestForumProjectionReplayRebuildsMissingThreadView(t *testing.T) {
 	}
 }
 
+func TestRebuildForumProjectionsReportsAndRepairsDrift(t *testing.T) {
+	s := NewSQLiteStore(filepath.Join(t.TempDir(), "metawsm.db"))
+	defer s.Close()
+	if err := s.Init(); err != nil {
+		t.Fatalf("init store: %v", err)
+	}
+	for _, item := range []struct{ threadID, ticket string }{
+		{"thread-rebuild-a", "METAWSM-A"},
+		{"thread-rebuild-b", "METAWSM-B"},
+	} {
+		if _, err := s.ForumOpenThread(model.ForumOpenThreadCommand{
+			Envelope: model.ForumEnvelope{
+				EventID:      "evt-open-" + item.threadID,
+				EventType:    "forum.thread.opened",
+				EventVersion: 1,
+				OccurredAt:   time.Now().UTC(),
+				ThreadID:     item.threadID,
+				Ticket:       item.ticket,
+				ActorType:    model.ForumActorAgent,
+				ActorName:    "agent-a",
+			},
+			Title:    "Rebuild thread",
+			Body:     "Initial post",
+			Priority: model.ForumPriorityNormal,
+		}); err != nil {
+			t.Fatalf("open forum thread: %v", err)
+		}
+		event, err := s.GetForumEvent("evt-open-" + item.threadID)
+		if err != nil || event == nil {
+			t.Fatalf("get forum event: %v", err)
+		}
+		if err := s.ApplyForumEventProjections(*event); err != nil {
+			t.Fatalf("apply projections: %v", err)
+		}
+	}
+
+	if err := s.execSQL(`UPDATE forum_thread_views SET posts_count=99;`); err != nil {
+		t.Fatalf("corrupt thread views: %v", err)
+	}
+	if err := s.execSQL(`DELETE FROM forum_thread_queue_view WHERE thread_id='thread-rebuild-a';`); err != nil {
+		t.Fatalf("corrupt queue view: %v", err)
+	}
+
+	report, err := s.RebuildForumProjections("METAWSM-A", nil)
+	if err != nil {
+		t.Fatalf("rebuild projections: %v", err)
+	}
+	if report.EventsReplayed != 1 || len(report.Projections) != len(model.ForumProjections()) {
+		t.Fatalf("unexpected rebuild report: %+v", report)
+	}
+	if len(report.Diffs) != 2 {
+		t.Fatalf("expected two diffs, got %+v", report.Diffs)
+	}
+	viewDiff := report.Diffs[0]
+	if viewDiff.Projection != model.ForumProjectionThreadViews || viewDiff.Kind != model.ForumProjectionDiffChanged ||
+		len(viewDiff.Fields) != 1 || viewDiff.Fields[0].Field != "posts_count" || viewDiff.Fields[0].Live != "99" || viewDiff.Fields[0].Rebuilt != "1" {
+		t.Fatalf("unexpected thread view diff: %+v", viewDiff)
+	}
+	queueDiff := report.Diffs[1]
+	if queueDiff.Projection != model.ForumProjectionQueueView || queueDiff.Kind != model.ForumProjectionDiffAdded || queueDiff.Key != "thread-rebuild-a" {
+		t.Fatalf("unexpected queue view diff: %+v", queueDiff)
+	}
+
+	repaired, err := s.GetForumThread("thread-rebuild-a")
+	if err != nil || repaired == nil || repaired.PostsCount != 1 {
+		t.Fatalf("expected repaired thread view, got %+v err=%v", repaired, err)
+	}
+	untouched, err := s.GetForumThread("thread-rebuild-b")
+	if err != nil || untouched == nil || untouched.PostsCount != 99 {
+		t.Fatalf("expected other ticket to be left alone, got %+v err=%v", untouched, err)
+	}
+	for _, projection := range model.ForumProjections() {
+		applied, err := s.forumProjectionEventExists(forumProjectionEventName(projection), "evt-open-thread-rebuild-a")
+		if err != nil || !applied {
+			t.Fatalf("expected %s to record the replayed event, got %v err=%v", projection, applied, err)
+		}
+	}
+
+	if _, err := s.RebuildForumProjections("", []string{"forum_threads"}); err == nil {
+		t.Fatalf("expected unknown projection to be rejected")
+	}
+	report, err = s.RebuildForumProjections("", []string{model.ForumProjectionThreadViews})
+	if err != nil {
+		t.Fatalf("rebuild thread views: %v", err)
+	}
+	if len(report.Projections) != 1 || len(report.Diffs) != 1 || report.Diffs[0].Key != "thread-rebuild-b" {
+		t.Fatalf("expected only the remaining drift to be reported, got %+v", report)
+	}
+}
+
 func TestForumOutboxLifecycle(t *testing.T) {
 	if _, err := exec.LookPath("sqlite3"); err != nil {
 		t.Skip("sqlite3 not available")
@@ -1187,3 +1554,595 @@ func TestForumOutboxLifecycle(t *testing.T) {
 		t.Fatalf("expected nil oldest pending timestamp after send")
 	}
 }
+
+func TestClaimForumOutboxReclaimsExpiredLeases(t *testing.T) {
+	s := NewSQLiteStore(filepath.Join(t.TempDir(), "metawsm.db"))
+	defer s.Close()
+	if err := s.Init(); err != nil {
+		t.Fatalf("init store: %v", err)
+	}
+	s.OutboxLeaseMS = 50
+	if err := s.EnqueueForumOutbox(model.ForumOutboxMessage{
+		MessageID:   "msg-stale",
+		Topic:       "forum.commands.open_thread",
+		PayloadJSON: `{"ok":true}`,
+	}); err != nil {
+		t.Fatalf("enqueue outbox: %v", err)
+	}
+	if _, err := s.ClaimForumOutboxPending(10); err != nil {
+		t.Fatalf("claim outbox: %v", err)
+	}
+	claimed, err := s.ClaimForumOutboxPending(10)
+	if err != nil {
+		t.Fatalf("claim outbox again: %v", err)
+	}
+	if len(claimed) != 0 {
+		t.Fatalf("expected leased claim to stay with its worker, got %+v", claimed)
+	}
+
+	time.Sleep(80 * time.Millisecond)
+	claimed, err = s.ClaimForumOutboxPending(10)
+	if err != nil {
+		t.Fatalf("reclaim outbox: %v", err)
+	}
+	if len(claimed) != 1 || claimed[0].AttemptCount != 2 {
+		t.Fatalf("expected expired claim to be reclaimed on attempt 2, got %+v", claimed)
+	}
+
+	s.OutboxMaxAttempts = 2
+	time.Sleep(80 * time.Millisecond)
+	claimed, err = s.ClaimForumOutboxPending(10)
+	if err != nil {
+		t.Fatalf("claim exhausted outbox: %v", err)
+	}
+	if len(claimed) != 0 {
+		t.Fatalf("expected exhausted claim not to be redelivered, got %+v", claimed)
+	}
+	if count, _ := s.CountForumOutboxByStatus(model.ForumOutboxStatusDeadLetter); count != 1 {
+		t.Fatalf("expected exhausted claim in dead_letter, got %d", count)
+	}
+}
+
+func TestMarkForumOutboxFailedBacksOffThenDeadLetters(t *testing.T) {
+	s := NewSQLiteStore(filepath.Join(t.TempDir(), "metawsm.db"))
+	defer s.Close()
+	if err := s.Init(); err != nil {
+		t.Fatalf("init store: %v", err)
+	}
+	s.OutboxMaxAttempts = 2
+	s.OutboxBackoffBaseMS = 50
+	if err := s.EnqueueForumOutbox(model.ForumOutboxMessage{
+		MessageID:   "msg-retry",
+		Topic:       "forum.commands.open_thread",
+		PayloadJSON: `{"ok":true}`,
+	}); err != nil {
+		t.Fatalf("enqueue outbox: %v", err)
+	}
+
+	if _, err := s.ClaimForumOutboxPending(10); err != nil {
+		t.Fatalf("claim outbox: %v", err)
+	}
+	if err := s.MarkForumOutboxFailed("msg-retry", "boom"); err != nil {
+		t.Fatalf("mark failed: %v", err)
+	}
+	failed, err := s.ListForumOutboxByStatus(model.ForumOutboxStatusFailed, 10)
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if len(failed) != 1 || failed[0].NextAttemptAt == nil {
+		t.Fatalf("expected failed message with next_attempt_at, got %+v", failed)
+	}
+	claimed, err := s.ClaimForumOutboxPending(10)
+	if err != nil {
+		t.Fatalf("claim during backoff: %v", err)
+	}
+	if len(claimed) != 0 {
+		t.Fatalf("expected backoff to delay retry, got %+v", claimed)
+	}
+
+	time.Sleep(80 * time.Millisecond)
+	claimed, err = s.ClaimForumOutboxPending(10)
+	if err != nil {
+		t.Fatalf("claim after backoff: %v", err)
+	}
+	if len(claimed) != 1 || claimed[0].AttemptCount != 2 {
+		t.Fatalf("expected retry on attempt 2, got %+v", claimed)
+	}
+	if err := s.MarkForumOutboxFailed("msg-retry", "boom again"); err != nil {
+		t.Fatalf("mark failed again: %v", err)
+	}
+	dead, err := s.ListForumOutboxByStatus(model.ForumOutboxStatusDeadLetter, 10)
+	if err != nil {
+		t.Fatalf("list dead letters: %v", err)
+	}
+	if len(dead) != 1 || dead[0].LastError != "boom again" || dead[0].NextAttemptAt != nil {
+		t.Fatalf("expected dead letter after final attempt, got %+v", dead)
+	}
+
+	requeued, err := s.RetryForumOutbox(nil)
+	if err != nil {
+		t.Fatalf("retry dead letters: %v", err)
+	}
+	if requeued != 1 {
+		t.Fatalf("expected one requeued message, got %d", requeued)
+	}
+	claimed, err = s.ClaimForumOutboxPending(10)
+	if err != nil {
+		t.Fatalf("claim requeued: %v", err)
+	}
+	if len(claimed) != 1 || claimed[0].AttemptCount != 1 {
+		t.Fatalf("expected requeued message to restart at attempt 1, got %+v", claimed)
+	}
+}
+
+func TestPurgeForumOutboxDeletesOnlyRequestedStatus(t *testing.T) {
+	s := NewSQLiteStore(filepath.Join(t.TempDir(), "metawsm.db"))
+	defer s.Close()
+	if err := s.Init(); err != nil {
+		t.Fatalf("init store: %v", err)
+	}
+	s.OutboxMaxAttempts = 1
+	for _, id := range []string{"msg-sent", "msg-dead", "msg-pending"} {
+		if err := s.EnqueueForumOutbox(model.ForumOutboxMessage{
+			MessageID:   id,
+			Topic:       "forum.commands.open_thread",
+			PayloadJSON: `{"ok":true}`,
+		}); err != nil {
+			t.Fatalf("enqueue %s: %v", id, err)
+		}
+	}
+	if _, err := s.ClaimForumOutboxPending(2); err != nil {
+		t.Fatalf("claim outbox: %v", err)
+	}
+	if err := s.MarkForumOutboxSent("msg-sent"); err != nil {
+		t.Fatalf("mark sent: %v", err)
+	}
+	if err := s.MarkForumOutboxFailed("msg-dead", "boom"); err != nil {
+		t.Fatalf("mark failed: %v", err)
+	}
+
+	if _, err := s.PurgeForumOutbox(model.ForumOutboxStatusPending, 0); err == nil {
+		t.Fatalf("expected purge of pending messages to be rejected")
+	}
+	purged, err := s.PurgeForumOutbox(model.ForumOutboxStatusSent, time.Hour)
+	if err != nil {
+		t.Fatalf("purge old sent: %v", err)
+	}
+	if purged != 0 {
+		t.Fatalf("expected fresh sent message to survive age filter, purged %d", purged)
+	}
+	purged, err = s.PurgeForumOutbox(model.ForumOutboxStatusDeadLetter, 0)
+	if err != nil {
+		t.Fatalf("purge dead letters: %v", err)
+	}
+	if purged != 1 {
+		t.Fatalf("expected one purged dead letter, got %d", purged)
+	}
+	recent, err := s.ListRecentForumOutbox(10)
+	if err != nil {
+		t.Fatalf("list outbox: %v", err)
+	}
+	if len(recent) != 2 {
+		t.Fatalf("expected sent and pending messages to remain, got %+v", recent)
+	}
+}
+
+func TestSQLiteStoreBackendsAgreeOnAgentAndOutboxWrites(t *testing.T) {
+	for _, backend := range []string{BackendDriver, BackendCLI} {
+		t.Run(backend, func(t *testing.T) {
+			if backend == BackendCLI {
+				if _, err := exec.LookPath("sqlite3"); err != nil {
+					t.Skip("sqlite3 not available")
+				}
+			}
+
+			s := NewSQLiteStore(filepath.Join(t.TempDir(), "metawsm.db"))
+			s.Backend = backend
+			defer s.Close()
+			if err := s.Init(); err != nil {
+				t.Fatalf("init store: %v", err)
+			}
+
+			if err := s.UpsertAgent(model.AgentRecord{
+				RunID:         "run-1",
+				Name:          "agent",
+				WorkspaceName: "ws-1",
+				SessionName:   "agent-ws-1",
+				Status:        model.AgentStatusPending,
+				HealthState:   model.HealthStateHealthy,
+			}); err != nil {
+				t.Fatalf("upsert agent: %v", err)
+			}
+			activity := time.Now().Truncate(time.Second)
+			if err := s.UpdateAgentStatus("run-1", "agent", "ws-1", model.AgentStatusRunning, model.HealthStateIdle, &activity, nil); err != nil {
+				t.Fatalf("update agent status: %v", err)
+			}
+			agents, err := s.GetAgents("run-1")
+			if err != nil {
+				t.Fatalf("get agents: %v", err)
+			}
+			if len(agents) != 1 || agents[0].Status != model.AgentStatusRunning || agents[0].HealthState != model.HealthStateIdle {
+				t.Fatalf("unexpected agents after update: %+v", agents)
+			}
+			if agents[0].LastActivityAt == nil || !agents[0].LastActivityAt.Equal(activity) {
+				t.Fatalf("expected last activity %s, got %v", activity.Format(time.RFC3339), agents[0].LastActivityAt)
+			}
+
+			if err := s.AddEvent("run-1", "agent", "agent@ws-1", "state", "pending", "running", "it's running ?"); err != nil {
+				t.Fatalf("add event: %v", err)
+			}
+			rows, err := s.queryJSON("SELECT message FROM events WHERE run_id=?;", "run-1")
+			if err != nil {
+				t.Fatalf("query events: %v", err)
+			}
+			if len(rows) != 1 || asString(rows[0]["message"]) != "it's running ?" {
+				t.Fatalf("unexpected event rows: %+v", rows)
+			}
+
+			if err := s.EnqueueForumOutbox(model.ForumOutboxMessage{
+				MessageID:   "msg-1",
+				Topic:       "forum.commands.open_thread",
+				PayloadJSON: `{"ok":true}`,
+			}); err != nil {
+				t.Fatalf("enqueue outbox: %v", err)
+			}
+			if err := s.MarkForumOutboxFailed("msg-1", "boom"); err != nil {
+				t.Fatalf("mark outbox failed: %v", err)
+			}
+			failed, err := s.ListForumOutboxByStatus(model.ForumOutboxStatusFailed, 10)
+			if err != nil {
+				t.Fatalf("list failed outbox: %v", err)
+			}
+			if len(failed) != 1 || failed[0].LastError != "boom" {
+				t.Fatalf("unexpected failed outbox rows: %+v", failed)
+			}
+		})
+	}
+}
+
+func TestDriverBackendRollsBackFailedTransactionScript(t *testing.T) {
+	s := NewSQLiteStore(filepath.Join(t.TempDir(), "metawsm.db"))
+	defer s.Close()
+	if err := s.Init(); err != nil {
+		t.Fatalf("init store: %v", err)
+	}
+	if err := s.execSQL("CREATE TABLE tx_test (id INTEGER PRIMARY KEY);"); err != nil {
+		t.Fatalf("create tx_test table: %v", err)
+	}
+
+	err := s.execSQL(`BEGIN IMMEDIATE;
+INSERT INTO tx_test (id) VALUES (1);
+INSERT INTO tx_test (id) VALUES (1);
+COMMIT;`)
+	if err == nil {
+		t.Fatalf("expected duplicate key error")
+	}
+	if err := s.execSQL("BEGIN IMMEDIATE; INSERT INTO tx_test (id) VALUES (2); COMMIT;"); err != nil {
+		t.Fatalf("exec after rollback: %v", err)
+	}
+
+	rows, err := s.queryJSON("SELECT id FROM tx_test ORDER BY id;")
+	if err != nil {
+		t.Fatalf("query tx_test: %v", err)
+	}
+	if len(rows) != 1 || asInt(rows[0]["id"]) != 2 {
+		t.Fatalf("expected only row 2 after rollback, got %+v", rows)
+	}
+}
+
+func TestSQLiteStoreBackendsBindTransactionArgs(t *testing.T) {
+	for _, backend := range []string{BackendDriver, BackendCLI} {
+		t.Run(backend, func(t *testing.T) {
+			if backend == BackendCLI {
+				if _, err := exec.LookPath("sqlite3"); err != nil {
+					t.Skip("sqlite3 not available")
+				}
+			}
+
+			s := NewSQLiteStore(filepath.Join(t.TempDir(), "metawsm.db"))
+			s.Backend = backend
+			defer s.Close()
+			if err := s.Init(); err != nil {
+				t.Fatalf("init store: %v", err)
+			}
+			if err := s.execSQL("CREATE TABLE tx_test (id INTEGER PRIMARY KEY, note TEXT NOT NULL);"); err != nil {
+				t.Fatalf("create tx_test table: %v", err)
+			}
+			err := s.execTx(
+				statement("INSERT INTO tx_test (id, note) VALUES (?, ?);", 1, "first"),
+				statement("INSERT INTO tx_test (id, note) VALUES (?, ?);", 1, "duplicate"),
+			)
+			if err == nil {
+				t.Fatalf("expected duplicate key error")
+			}
+			rows, err := s.queryJSON("SELECT id FROM tx_test;")
+			if err != nil {
+				t.Fatalf("query tx_test: %v", err)
+			}
+			if len(rows) != 0 {
+				t.Fatalf("expected failed transaction to roll back, got %+v", rows)
+			}
+
+			title := "Don't drop ?'); DROP TABLE forum_threads; --"
+			if _, err := s.ForumOpenThread(model.ForumOpenThreadCommand{
+				Envelope: model.ForumEnvelope{
+					EventID:      "evt-open-1",
+					EventType:    "forum.thread.opened",
+					EventVersion: 1,
+					OccurredAt:   time.Now().UTC(),
+					ThreadID:     "thread-1",
+					RunID:        "run-1",
+					Ticket:       "METAWSM-001",
+					AgentName:    "agent-a",
+					ActorType:    model.ForumActorAgent,
+					ActorName:    "agent-a",
+				},
+				Title: title,
+				Body:  "body with 'quotes' and ? marks",
+			}); err != nil {
+				t.Fatalf("open forum thread: %v", err)
+			}
+			threads, err := s.ListForumThreads(model.ForumThreadFilter{Ticket: "METAWSM-001"})
+			if err != nil {
+				t.Fatalf("list forum threads: %v", err)
+			}
+			if len(threads) != 1 || threads[0].Title != title || threads[0].PostsCount != 1 {
+				t.Fatalf("unexpected threads: %+v", threads)
+			}
+			events, err := s.WatchForumEvents("METAWSM-001", 0, 10)
+			if err != nil {
+				t.Fatalf("watch forum events: %v", err)
+			}
+			if len(events) != 1 || events[0].Envelope.EventID != "evt-open-1" {
+				t.Fatalf("unexpected forum events: %+v", events)
+			}
+		})
+	}
+}
+
+func TestBindSQLArgsInlinesLiteralsOutsideStrings(t *testing.T) {
+	bound, err := bindSQLArgs("UPDATE t SET a=?, b='?', c=? WHERE d=?;", []any{"it's", 3, nil})
+	if err != nil {
+		t.Fatalf("bind args: %v", err)
+	}
+	want := "UPDATE t SET a='it''s', b='?', c=3 WHERE d=NULL;"
+	if bound != want {
+		t.Fatalf("expected %q, got %q", want, bound)
+	}
+	if _, err := bindSQLArgs("SELECT ?;", []any{1, 2}); err == nil {
+		t.Fatalf("expected arg count mismatch error")
+	}
+}
+
+func TestForumSearchMatchExpressionQuotesTerms(t *testing.T) {
+	cases := map[string]string{
+		`migration`:                  `"migration"`,
+		`schema migra*`:              `"schema" "migra"*`,
+		`"rebuild after deploy"`:     `"rebuild after deploy"`,
+		`"rebuild after"* OR outbox`: `"rebuild after"* OR "outbox"`,
+		`OR index-migration) AND "`:  `"index-migration)" "AND"`,
+		`-- *`:                       ``,
+	}
+	for query, want := range cases {
+		if got := forumSearchMatchExpression(query); got != want {
+			t.Fatalf("forumSearchMatchExpression(%q) = %q, want %q", query, got, want)
+		}
+	}
+}
+
+func TestMigrateRecordsVersionsAndAdoptsLegacyDatabase(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "metawsm.db")
+	legacy := NewSQLiteStore(dbPath)
+	defer legacy.Close()
+	if err := legacy.execSQL(`CREATE TABLE schema_migrations (version INTEGER PRIMARY KEY, applied_at TEXT NOT NULL);
+CREATE TABLE runs (
+  run_id TEXT PRIMARY KEY,
+  status TEXT NOT NULL,
+  created_at TEXT NOT NULL,
+  updated_at TEXT NOT NULL,
+  spec_json TEXT NOT NULL,
+  policy_json TEXT NOT NULL,
+  error_text TEXT NOT NULL DEFAULT ''
+);`); err != nil {
+		t.Fatalf("seed legacy schema: %v", err)
+	}
+
+	before, err := legacy.SchemaStatus()
+	if err != nil {
+		t.Fatalf("schema status before migrate: %v", err)
+	}
+	if before.CurrentVersion != 0 || len(before.Pending) != len(Migrations()) {
+		t.Fatalf("expected unversioned legacy db with all migrations pending, got %+v", before)
+	}
+
+	dryRun, err := legacy.Migrate(MigrateOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("dry-run migrate: %v", err)
+	}
+	if len(dryRun.Migrations) != len(Migrations()) {
+		t.Fatalf("expected dry run to list %d migrations, got %d", len(Migrations()), len(dryRun.Migrations))
+	}
+	if status, _ := legacy.SchemaStatus(); status.CurrentVersion != 0 {
+		t.Fatalf("expected dry run to leave schema version 0, got %d", status.CurrentVersion)
+	}
+
+	if err := legacy.Init(); err != nil {
+		t.Fatalf("init legacy db: %v", err)
+	}
+	after, err := legacy.SchemaStatus()
+	if err != nil {
+		t.Fatalf("schema status after migrate: %v", err)
+	}
+	if after.CurrentVersion != LatestSchemaVersion() || len(after.Pending) != 0 {
+		t.Fatalf("expected db at latest version with nothing pending, got %+v", after)
+	}
+	if err := legacy.Init(); err != nil {
+		t.Fatalf("re-init should be a no-op: %v", err)
+	}
+	if _, err := legacy.Migrate(MigrateOptions{ToVersion: LatestSchemaVersion() + 1}); err == nil {
+		t.Fatalf("expected unknown target version to fail")
+	}
+}
+
+func TestInitRefusesDatabaseNewerThanBinary(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "metawsm.db")
+	s := NewSQLiteStore(dbPath)
+	defer s.Close()
+	if err := s.Init(); err != nil {
+		t.Fatalf("init store: %v", err)
+	}
+	future := LatestSchemaVersion() + 1
+	if err := s.execSQL(fmt.Sprintf("INSERT INTO schema_migrations (version, applied_at) VALUES (%d, %s);", future, quote(time.Now().Format(time.RFC3339)))); err != nil {
+		t.Fatalf("record future migration: %v", err)
+	}
+
+	err := s.Init()
+	var tooNew *SchemaTooNewError
+	if !errors.As(err, &tooNew) {
+		t.Fatalf("expected SchemaTooNewError, got %v", err)
+	}
+	if tooNew.Version != future || tooNew.SupportedVersion != LatestSchemaVersion() {
+		t.Fatalf("unexpected schema versions in error: %+v", tooNew)
+	}
+}
+
+func TestForumEscalateThreadRecordsHistoryAndResetsLevelWhenAnswered(t *testing.T) {
+	s := NewSQLiteStore(filepath.Join(t.TempDir(), "metawsm.db"))
+	defer s.Close()
+	if err := s.Init(); err != nil {
+		t.Fatalf("init store: %v", err)
+	}
+	envelope := func(eventID string, eventType string) model.ForumEnvelope {
+		return model.ForumEnvelope{
+			EventID:      eventID,
+			EventType:    eventType,
+			EventVersion: 1,
+			OccurredAt:   time.Now().UTC(),
+			ThreadID:     "thread-escalate",
+			Ticket:       "METAWSM-014",
+			ActorType:    model.ForumActorSystem,
+			ActorName:    "sla",
+		}
+	}
+	if _, err := s.ForumOpenThread(model.ForumOpenThreadCommand{
+		Envelope: envelope("evt-open", "forum.thread.opened"),
+		Title:    "Waiting on review",
+		Body:     "Please look",
+		Priority: model.ForumPriorityLow,
+	}); err != nil {
+		t.Fatalf("open forum thread: %v", err)
+	}
+	if _, err := s.ForumChangeState(model.ForumChangeStateCommand{
+		Envelope: envelope("evt-wait", "forum.state.changed"),
+		ToState:  model.ForumThreadStateWaitingOperator,
+	}); err != nil {
+		t.Fatalf("change state: %v", err)
+	}
+
+	thread, err := s.ForumEscalateThread(model.ForumEscalateThreadCommand{
+		Envelope:     envelope("evt-esc-1", "forum.thread_escalated"),
+		Level:        1,
+		Priority:     model.ForumPriorityNormal,
+		AssigneeType: model.ForumActorOperator,
+		AssigneeName: "oncall",
+		Reason:       "overdue",
+	})
+	if err != nil {
+		t.Fatalf("escalate thread: %v", err)
+	}
+	if thread.Priority != model.ForumPriorityNormal || thread.AssigneeName != "oncall" || thread.State != model.ForumThreadStateWaitingOperator {
+		t.Fatalf("unexpected escalated thread: %+v", thread)
+	}
+	if _, err := s.ForumEscalateThread(model.ForumEscalateThreadCommand{
+		Envelope: envelope("evt-esc-2", "forum.thread_escalated"),
+		Level:    2,
+		Priority: model.ForumPriorityHigh,
+	}); err != nil {
+		t.Fatalf("escalate thread again: %v", err)
+	}
+	level, err := s.ForumEscalationLevel("thread-escalate")
+	if err != nil || level != 2 {
+		t.Fatalf("expected escalation level 2, got %d (%v)", level, err)
+	}
+	escalations, err := s.ListForumEscalations("thread-escalate")
+	if err != nil {
+		t.Fatalf("list escalations: %v", err)
+	}
+	if len(escalations) != 2 {
+		t.Fatalf("expected 2 escalations, got %d", len(escalations))
+	}
+	second := escalations[1]
+	if second.FromPriority != model.ForumPriorityNormal || second.ToPriority != model.ForumPriorityHigh ||
+		second.FromAssigneeName != "oncall" || second.ToAssigneeName != "oncall" {
+		t.Fatalf("expected empty assignee to keep current one: %+v", second)
+	}
+	event, err := s.GetForumEvent("evt-esc-1")
+	if err != nil || event == nil || event.Envelope.EventType != "forum.thread_escalated" {
+		t.Fatalf("expected escalation event, got %+v (%v)", event, err)
+	}
+
+	if _, err := s.ForumChangeState(model.ForumChangeStateCommand{
+		Envelope: envelope("evt-handoff", "forum.state.changed"),
+		ToState:  model.ForumThreadStateWaitingHuman,
+	}); err != nil {
+		t.Fatalf("change state: %v", err)
+	}
+	level, err = s.ForumEscalationLevel("thread-escalate")
+	if err != nil || level != 2 {
+		t.Fatalf("expected handoff between waiting states to keep level 2, got %d (%v)", level, err)
+	}
+	if _, err := s.ForumChangeState(model.ForumChangeStateCommand{
+		Envelope: envelope("evt-answered", "forum.state.changed"),
+		ToState:  model.ForumThreadStateAnswered,
+	}); err != nil {
+		t.Fatalf("change state: %v", err)
+	}
+	level, err = s.ForumEscalationLevel("thread-escalate")
+	if err != nil || level != 0 {
+		t.Fatalf("expected escalation level reset after answer, got %d (%v)", level, err)
+	}
+}
+
+func TestListForumThreadsAfterPagesByThreadID(t *testing.T) {
+	s := NewSQLiteStore(filepath.Join(t.TempDir(), "metawsm.db"))
+	defer s.Close()
+	if err := s.Init(); err != nil {
+		t.Fatalf("init store: %v", err)
+	}
+	for _, threadID := range []string{"thread-c", "thread-a", "thread-b"} {
+		if _, err := s.ForumOpenThread(model.ForumOpenThreadCommand{
+			Envelope: model.ForumEnvelope{
+				EventID:      "evt-" + threadID,
+				EventType:    "forum.thread.opened",
+				EventVersion: 1,
+				OccurredAt:   time.Now().UTC(),
+				ThreadID:     threadID,
+				Ticket:       "METAWSM-014",
+				ActorType:    model.ForumActorAgent,
+				ActorName:    "agent",
+			},
+			Title: "Question",
+			Body:  "Please look",
+		}); err != nil {
+			t.Fatalf("open forum thread: %v", err)
+		}
+	}
+	seen := []string{}
+	after := ""
+	for {
+		page, err := s.ListForumThreadsAfter(model.ForumThreadStateNew, after, 2)
+		if err != nil {
+			t.Fatalf("list page: %v", err)
+		}
+		for _, thread := range page {
+			seen = append(seen, thread.ThreadID)
+		}
+		if len(page) < 2 {
+			break
+		}
+		after = page[len(page)-1].ThreadID
+	}
+	if strings.Join(seen, ",") != "thread-a,thread-b,thread-c" {
+		t.Fatalf("expected every thread once in id order, got %v", seen)
+	}
+}
@@ -0,0 +1,120 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"metawsm/internal/model"
+	"metawsm/internal/policy"
+)
+
+const (
+	// SignatureHeader carries "sha256=" plus the hex HMAC-SHA256 of
+	// "<timestamp>.<body>" keyed by the endpoint secret.
+	SignatureHeader = "X-Metawsm-Signature"
+	TimestampHeader = "X-Metawsm-Timestamp"
+	EventHeader     = "X-Metawsm-Event"
+	DeliveryHeader  = "X-Metawsm-Delivery"
+)
+
+// Matches reports whether endpoint wants event. Priority and run status
+// filters only constrain events that carry those attributes.
+func Matches(endpoint policy.WebhookEndpoint, event model.WebhookEvent) bool {
+	if len(endpoint.Tickets) > 0 && !anyMatch(endpoint.Tickets, event.Tickets...) {
+		return false
+	}
+	if len(endpoint.EventTypes) > 0 && !anyMatch(endpoint.EventTypes, event.EventType) {
+		return false
+	}
+	if len(endpoint.Priorities) > 0 && event.Priority != "" && !anyMatch(endpoint.Priorities, string(event.Priority)) {
+		return false
+	}
+	if len(endpoint.RunStatuses) > 0 && event.RunStatus != "" && !anyMatch(endpoint.RunStatuses, string(event.RunStatus)) {
+		return false
+	}
+	return true
+}
+
+func anyMatch(allowed []string, values ...string) bool {
+	for _, value := range values {
+		value = strings.TrimSpace(strings.ToLower(value))
+		for _, candidate := range allowed {
+			if strings.TrimSpace(strings.ToLower(candidate)) == value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Sign returns the SignatureHeader value for body sent at timestamp.
+func Sign(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a SignatureHeader value; receivers can use it as a reference.
+func Verify(secret string, timestamp string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(strings.TrimSpace(signature)))
+}
+
+// VerifyFresh is Verify plus a replay check: timestamp must be Unix seconds
+// within tolerance of now.
+func VerifyFresh(secret string, timestamp string, body []byte, signature string, now time.Time, tolerance time.Duration) bool {
+	sent, err := strconv.ParseInt(strings.TrimSpace(timestamp), 10, 64)
+	if err != nil {
+		return false
+	}
+	skew := now.Sub(time.Unix(sent, 0))
+	if skew < -tolerance || skew > tolerance {
+		return false
+	}
+	return Verify(secret, strings.TrimSpace(timestamp), body, signature)
+}
+
+// Deliver POSTs event to endpoint. Any non-2xx response is an error so the
+// outbox retries the delivery.
+func Deliver(ctx context.Context, client *http.Client, endpoint policy.WebhookEndpoint, secret string, deliveryID string, event model.WebhookEvent, now time.Time) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshal webhook event: %w", err)
+	}
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSpace(endpoint.URL), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build webhook request for %s: %w", endpoint.Name, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "metawsm-webhook")
+	req.Header.Set(EventHeader, event.EventType)
+	req.Header.Set(DeliveryHeader, deliveryID)
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, Sign(secret, timestamp, body))
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("post webhook %s: %w", endpoint.Name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook %s returned %d: %s", endpoint.Name, resp.StatusCode, strings.TrimSpace(string(snippet)))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
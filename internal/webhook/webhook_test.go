@@ -0,0 +1,82 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"metawsm/internal/model"
+	"metawsm/internal/policy"
+)
+
+func TestMatchesAppliesFiltersOnlyToEventsCarryingThem(t *testing.T) {
+	endpoint := policy.WebhookEndpoint{
+		Name:        "relay",
+		Tickets:     []string{"METAWSM-015"},
+		Priorities:  []string{"high", "urgent"},
+		RunStatuses: []string{"failed", "awaiting_guidance"},
+	}
+	cases := []struct {
+		name  string
+		event model.WebhookEvent
+		want  bool
+	}{
+		{"urgent forum event", model.WebhookEvent{EventType: "forum.thread.opened", Tickets: []string{"METAWSM-015"}, Priority: model.ForumPriorityUrgent}, true},
+		{"low forum event", model.WebhookEvent{EventType: "forum.thread.opened", Tickets: []string{"METAWSM-015"}, Priority: model.ForumPriorityLow}, false},
+		{"failed run", model.WebhookEvent{EventType: model.WebhookEventRunStatusChanged, Tickets: []string{"OTHER", "METAWSM-015"}, RunStatus: model.RunStatusFailed}, true},
+		{"running run", model.WebhookEvent{EventType: model.WebhookEventRunStatusChanged, Tickets: []string{"METAWSM-015"}, RunStatus: model.RunStatusRunning}, false},
+		{"other ticket", model.WebhookEvent{EventType: model.WebhookEventRunStatusChanged, Tickets: []string{"OTHER"}, RunStatus: model.RunStatusFailed}, false},
+	}
+	for _, tc := range cases {
+		if got := Matches(endpoint, tc.event); got != tc.want {
+			t.Fatalf("%s: Matches=%t want %t", tc.name, got, tc.want)
+		}
+	}
+
+	endpoint = policy.WebhookEndpoint{Name: "forum-only", EventTypes: []string{"forum.control.signal"}}
+	if Matches(endpoint, model.WebhookEvent{EventType: model.WebhookEventRunStatusChanged}) {
+		t.Fatalf("expected event type filter to reject run events")
+	}
+}
+
+func TestDeliverSignsBodyAndFailsOnNon2xx(t *testing.T) {
+	var received model.WebhookEvent
+	status := http.StatusNoContent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !Verify("s3cret", r.Header.Get(TimestampHeader), body, r.Header.Get(SignatureHeader)) {
+			t.Errorf("signature did not verify: %q", r.Header.Get(SignatureHeader))
+		}
+		if r.Header.Get(EventHeader) != "run.status_changed" || r.Header.Get(DeliveryHeader) != "fmsg-1" {
+			t.Errorf("unexpected headers: %v", r.Header)
+		}
+		_ = json.Unmarshal(body, &received)
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte("relay says no"))
+	}))
+	defer server.Close()
+
+	endpoint := policy.WebhookEndpoint{Name: "relay", URL: server.URL}
+	event := model.WebhookEvent{EventID: "evt-1", EventType: model.WebhookEventRunStatusChanged, RunID: "run-1", RunStatus: model.RunStatusFailed}
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	if err := Deliver(context.Background(), server.Client(), endpoint, "s3cret", "fmsg-1", event, now); err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+	if received.RunID != "run-1" || received.RunStatus != model.RunStatusFailed {
+		t.Fatalf("unexpected delivered event: %+v", received)
+	}
+
+	status = http.StatusBadGateway
+	err := Deliver(context.Background(), server.Client(), endpoint, "s3cret", "fmsg-1", event, now)
+	if err == nil || !strings.Contains(err.Error(), "502") {
+		t.Fatalf("expected 502 delivery error, got %v", err)
+	}
+	if Verify("wrong", "1", []byte("{}"), Sign("s3cret", "1", []byte("{}"))) {
+		t.Fatalf("expected signature with a different secret to fail")
+	}
+}
@@ -126,6 +126,21 @@ describe("App ask-question flow", () => {
   afterEach(() => {
     cleanup();
     vi.unstubAllGlobals();
+    window.localStorage.clear();
+  });
+
+  it("sends the stored API token on requests and the stream socket", async () => {
+    window.localStorage.setItem("metawsm.apiToken", "mwt_test");
+    render(<App />);
+    await screen.findByText("Threads Explorer");
+
+    await waitFor(() => {
+      const runsCall = fetchMock.mock.calls.find((call) => call[0] === "/api/v1/runs");
+      expect(runsCall).toBeDefined();
+      expect(new Headers(runsCall?.[1]?.headers).get("Authorization")).toBe("Bearer mwt_test");
+    });
+    const socketURL = new URL(String(MockWebSocket.instances[MockWebSocket.instances.length - 1]?.url));
+    expect(socketURL.searchParams.get("access_token")).toBe("mwt_test");
   });
 
   it("keeps Ask Question disabled until required fields are populated", async () => {
@@ -28,6 +28,30 @@ type ForumThread = {
   last_actor_type?: string;
   is_unseen?: boolean;
   is_unanswered?: boolean;
+  match?: ForumSearchMatch;
+};
+
+type ForumSearchMatch = {
+  rank: number;
+  hits: number;
+  doc_kind: string;
+  actor_type?: string;
+  actor_name?: string;
+  snippet: string;
+};
+
+type ForumAttachment = {
+  kind: "snippet" | "diff" | "log" | "command_output";
+  digest?: string;
+  size?: number;
+  title?: string;
+  repo?: string;
+  path?: string;
+  start_line?: number;
+  end_line?: number;
+  command?: string;
+  exit_code?: number;
+  content?: string;
 };
 
 type ForumPost = {
@@ -36,6 +60,7 @@ type ForumPost = {
   author_type: string;
   author_name: string;
   body: string;
+  attachments?: ForumAttachment[];
   created_at: string;
 };
 
@@ -54,10 +79,22 @@ type ForumEvent = {
   payload_json?: string;
 };
 
+type ForumEscalation = {
+  event_id: string;
+  level: number;
+  from_priority: string;
+  to_priority: string;
+  from_assignee_name?: string;
+  to_assignee_name?: string;
+  reason?: string;
+  escalated_at: string;
+};
+
 type ForumThreadDetail = {
   thread: ForumThread;
   posts: ForumPost[];
   events: ForumEvent[];
+  escalations?: ForumEscalation[];
 };
 
 type ForumOutboxMessage = {
@@ -90,11 +127,13 @@ type ForumDebugSnapshot = {
     pending_count: number;
     processing_count: number;
     failed_count: number;
+    dead_letter_count: number;
     oldest_pending_age_seconds: number;
   };
   outbox_messages: ForumOutboxMessage[];
   events: ForumEvent[];
   bus: {
+    transport?: string;
     running: boolean;
     healthy: boolean;
     health_error?: string;
@@ -136,6 +175,39 @@ const EMPTY_BUCKETS: BoardBuckets = {
   allThreads: [],
 };
 
+const API_TOKEN_STORAGE_KEY = "metawsm.apiToken";
+
+function readStoredAPIToken(): string {
+  try {
+    return window.localStorage.getItem(API_TOKEN_STORAGE_KEY) ?? "";
+  } catch {
+    return "";
+  }
+}
+
+function storeAPIToken(token: string) {
+  try {
+    if (token) {
+      window.localStorage.setItem(API_TOKEN_STORAGE_KEY, token);
+    } else {
+      window.localStorage.removeItem(API_TOKEN_STORAGE_KEY);
+    }
+  } catch {
+    // storage unavailable; the token only lasts for this page
+  }
+}
+
+// apiFetch adds the bearer token when one is configured for `metawsm serve`.
+function apiFetch(input: string, init?: RequestInit): Promise<Response> {
+  const token = readStoredAPIToken().trim();
+  if (!token) {
+    return init ? fetch(input, init) : fetch(input);
+  }
+  const headers = new Headers(init?.headers);
+  headers.set("Authorization", `Bearer ${token}`);
+  return fetch(input, { ...init, headers });
+}
+
 export function App() {
   const [runs, setRuns] = useState<RunSnapshot[]>([]);
   const [runFilter, setRunFilter] = useState("");
@@ -166,6 +238,7 @@ export function App() {
   const [showDiagnostics, setShowDiagnostics] = useState(false);
   const [debugSnapshot, setDebugSnapshot] = useState<ForumDebugSnapshot | null>(null);
   const [error, setError] = useState("");
+  const [apiToken, setAPIToken] = useState(readStoredAPIToken);
   const streamRefreshTimer = useRef<number | null>(null);
   const selectedThreadRef = useRef("");
 
@@ -232,6 +305,9 @@ export function App() {
     if (!debugSnapshot.bus.healthy) {
       return "Forum bus is unhealthy; queue/search freshness may lag.";
     }
+    if (debugSnapshot.outbox.dead_letter_count > 0) {
+      return `Forum outbox has ${debugSnapshot.outbox.dead_letter_count} dead-lettered message(s); replay with metawsm forum outbox retry.`;
+    }
     if (debugSnapshot.outbox.failed_count > 0) {
       return `Forum outbox has ${debugSnapshot.outbox.failed_count} failed message(s).`;
     }
@@ -321,6 +397,9 @@ export function App() {
     if (runFilter.trim()) {
       socketURL.searchParams.set("run_id", runFilter.trim());
     }
+    if (apiToken.trim()) {
+      socketURL.searchParams.set("access_token", apiToken.trim());
+    }
 
     const socket = new WebSocket(socketURL);
     socket.onmessage = (event) => {
@@ -349,12 +428,12 @@ export function App() {
       }
       socket.close();
     };
-  }, [ticketFilter, runFilter]);
+  }, [ticketFilter, runFilter, apiToken]);
 
   async function refreshRuns() {
     try {
       setError("");
-      const response = await fetch("/api/v1/runs");
+      const response = await apiFetch("/api/v1/runs");
       if (!response.ok) {
         throw new Error(`runs request failed (${response.status})`);
       }
@@ -491,7 +570,7 @@ export function App() {
     }
     query.set("limit", "300");
 
-    const response = await fetch(`/api/v1/forum/search?${query.toString()}`);
+    const response = await apiFetch(`/api/v1/forum/search?${query.toString()}`);
     if (!response.ok) {
       throw new Error(`forum search request failed (${response.status})`);
     }
@@ -529,7 +608,7 @@ export function App() {
     }
     query.set("limit", "300");
 
-    const response = await fetch(`/api/v1/forum/queues?${query.toString()}`);
+    const response = await apiFetch(`/api/v1/forum/queues?${query.toString()}`);
     if (!response.ok) {
       throw new Error(`forum queue request failed (${response.status})`);
     }
@@ -541,7 +620,7 @@ export function App() {
 
   async function refreshThreadDetail(threadID: string) {
     try {
-      const response = await fetch(`/api/v1/forum/threads/${encodeURIComponent(threadID)}`);
+      const response = await apiFetch(`/api/v1/forum/threads/${encodeURIComponent(threadID)}`);
       if (!response.ok) {
         throw new Error(`thread detail request failed (${response.status})`);
       }
@@ -557,7 +636,7 @@ export function App() {
       return;
     }
     try {
-      await fetch(`/api/v1/forum/threads/${encodeURIComponent(threadID)}/seen`, {
+      await apiFetch(`/api/v1/forum/threads/${encodeURIComponent(threadID)}/seen`, {
         method: "POST",
         headers: { "Content-Type": "application/json" },
         body: JSON.stringify({
@@ -579,7 +658,7 @@ export function App() {
     setSavingReply(true);
     try {
       setError("");
-      const response = await fetch(`/api/v1/forum/threads/${encodeURIComponent(selectedThreadID)}/posts`, {
+      const response = await apiFetch(`/api/v1/forum/threads/${encodeURIComponent(selectedThreadID)}/posts`, {
         method: "POST",
         headers: { "Content-Type": "application/json" },
         body: JSON.stringify({
@@ -608,7 +687,7 @@ export function App() {
     setSavingQuestion(true);
     try {
       setError("");
-      const response = await fetch("/api/v1/forum/threads", {
+      const response = await apiFetch("/api/v1/forum/threads", {
         method: "POST",
         headers: { "Content-Type": "application/json" },
         body: JSON.stringify({
@@ -657,7 +736,7 @@ export function App() {
         query.set("run_id", runID);
       }
       query.set("limit", "40");
-      const response = await fetch(`/api/v1/forum/debug?${query.toString()}`);
+      const response = await apiFetch(`/api/v1/forum/debug?${query.toString()}`);
       if (!response.ok) {
         throw new Error(`debug request failed (${response.status})`);
       }
@@ -685,6 +764,7 @@ export function App() {
           actorName: event.envelope.actor_name || matchedPost?.author_name || "-",
           occurredAt: event.envelope.occurred_at,
           body: matchedPost?.body || summarizePayload(event.payload_json),
+          attachments: matchedPost?.attachments ?? [],
         };
       });
   }, [selectedDetail]);
@@ -712,6 +792,16 @@ export function App() {
           <button type="button" onClick={() => setShowDiagnostics((value) => !value)}>
             {showDiagnostics ? "Hide System Health" : "Show System Health"}
           </button>
+          <input
+            type="password"
+            aria-label="API token"
+            placeholder="API token"
+            value={apiToken}
+            onChange={(event) => {
+              setAPIToken(event.target.value);
+              storeAPIToken(event.target.value.trim());
+            }}
+          />
         </div>
       </header>
 
@@ -982,6 +1072,26 @@ export function App() {
                 </small>
               </div>
 
+              {selectedDetail.escalations && selectedDetail.escalations.length > 0 ? (
+                <div className="escalations">
+                  <h3>Escalation History</h3>
+                  {selectedDetail.escalations.map((escalation) => (
+                    <div key={escalation.event_id} className="timeline-row">
+                      <div className="timeline-head">
+                        <span>
+                          level {escalation.level} · {escalation.from_priority} → {escalation.to_priority}
+                        </span>
+                        <small>{formatShortTime(escalation.escalated_at)}</small>
+                      </div>
+                      <small>
+                        assignee {escalation.from_assignee_name || "-"} → {escalation.to_assignee_name || "-"}
+                      </small>
+                      {escalation.reason ? <p>{escalation.reason}</p> : null}
+                    </div>
+                  ))}
+                </div>
+              ) : null}
+
               <div className="timeline">
                 {timelineRows.map((row) => (
                   <div key={row.id} className="timeline-row">
@@ -995,6 +1105,9 @@ export function App() {
                       {row.actorType}:{row.actorName}
                     </small>
                     {row.body ? <p>{row.body}</p> : <p className="muted">No payload preview</p>}
+                    {row.attachments.map((attachment, index) => (
+                      <AttachmentView key={`${row.id}-${index}`} attachment={attachment} />
+                    ))}
                   </div>
                 ))}
               </div>
@@ -1050,7 +1163,7 @@ export function App() {
                     <span>
                       pending={debugSnapshot.outbox.pending_count} processing={debugSnapshot.outbox.processing_count} failed={
                         debugSnapshot.outbox.failed_count
-                      }
+                      } dead_letter={debugSnapshot.outbox.dead_letter_count}
                     </span>
                     <small>oldest_pending_age={debugSnapshot.outbox.oldest_pending_age_seconds}s</small>
                   </div>
@@ -1116,6 +1229,16 @@ function BoardLane({ title, rows, selectedThreadID, onSelectThread, emptyLabel }
                 {thread.is_unanswered ? <span className="badge unanswered">unanswered</span> : null}
                 {thread.last_actor_type ? <span className="badge actor">last={thread.last_actor_type}</span> : null}
               </div>
+              {thread.match ? (
+                <span className="search-snippet">
+                  <HighlightedSnippet snippet={thread.match.snippet} />
+                  <small>
+                    {" "}
+                    {thread.match.doc_kind} by {thread.match.actor_name || thread.match.actor_type || "-"} · hits=
+                    {thread.match.hits}
+                  </small>
+                </span>
+              ) : null}
               <small>
                 thread={thread.thread_id} assignee={thread.assignee_name || "-"} updated={formatShortTime(thread.updated_at)}
               </small>
@@ -1127,6 +1250,71 @@ function BoardLane({ title, rows, selectedThreadID, onSelectThread, emptyLabel }
   );
 }
 
+function HighlightedSnippet({ snippet }: { snippet: string }) {
+  const parts = snippet.split(/<mark>|<\/mark>/);
+  return (
+    <span>
+      {parts.map((part, index) => (index % 2 === 1 ? <mark key={index}>{part}</mark> : <span key={index}>{part}</span>))}
+    </span>
+  );
+}
+
+function AttachmentView({ attachment }: { attachment: ForumAttachment }) {
+  const lines = (attachment.content ?? "").replace(/\n$/, "").split("\n");
+  return (
+    <div className={`attachment attachment-${attachment.kind}`}>
+      <div className="attachment-head">
+        <span className="badge">{attachment.kind}</span>
+        <span>{attachmentLabel(attachment)}</span>
+      </div>
+      {attachment.content ? (
+        <pre>
+          {lines.map((line, index) => (
+            <span key={index} className={attachment.kind === "diff" ? diffLineClass(line) : undefined}>
+              {attachment.kind === "snippet" && attachment.start_line ? (
+                <span className="line-number">{attachment.start_line + index}</span>
+              ) : null}
+              {line}
+              {"\n"}
+            </span>
+          ))}
+        </pre>
+      ) : (
+        <p className="muted">Content unavailable ({attachment.digest || "no digest"})</p>
+      )}
+    </div>
+  );
+}
+
+function attachmentLabel(attachment: ForumAttachment): string {
+  switch (attachment.kind) {
+    case "snippet": {
+      const path = attachment.repo ? `${attachment.repo}/${attachment.path ?? ""}` : attachment.path ?? "";
+      return attachment.start_line ? `${path}:${attachment.start_line}-${attachment.end_line ?? attachment.start_line}` : path;
+    }
+    case "command_output":
+      return `$ ${attachment.command ?? ""}${attachment.exit_code !== undefined ? ` (exit ${attachment.exit_code})` : ""}`;
+    default:
+      return attachment.title || `${attachment.size ?? 0} bytes`;
+  }
+}
+
+function diffLineClass(line: string): string | undefined {
+  if (line.startsWith("+++") || line.startsWith("---")) {
+    return "diff-file";
+  }
+  if (line.startsWith("@@")) {
+    return "diff-hunk";
+  }
+  if (line.startsWith("+")) {
+    return "diff-add";
+  }
+  if (line.startsWith("-")) {
+    return "diff-del";
+  }
+  return undefined;
+}
+
 function resolveScope(ticketFilter: string, runFilter: string) {
   const ticket = ticketFilter.trim();
   const runID = runFilter.trim();
@@ -1256,6 +1444,22 @@ function normalizeForumThread(value: unknown): ForumThread | null {
     last_actor_type: pickString(raw.last_actor_type, raw.LastActorType) ?? "",
     is_unseen: toOptionalBool(raw.is_unseen ?? raw.IsUnseen),
     is_unanswered: toOptionalBool(raw.is_unanswered ?? raw.IsUnanswered),
+    match: normalizeSearchMatch(raw.match),
+  };
+}
+
+function normalizeSearchMatch(value: unknown): ForumSearchMatch | undefined {
+  if (!value || typeof value !== "object") {
+    return undefined;
+  }
+  const raw = value as Record<string, unknown>;
+  return {
+    rank: toNumber(raw.rank),
+    hits: toNumber(raw.hits),
+    doc_kind: pickString(raw.doc_kind) ?? "",
+    actor_type: pickString(raw.actor_type) ?? "",
+    actor_name: pickString(raw.actor_name) ?? "",
+    snippet: pickString(raw.snippet) ?? "",
   };
 }
 